@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/batch"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/profile"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/render"
+)
+
+func runBatch(args []string, e *env) error {
+	fs := newFlagSet("batch", "[-base file] [-profile name] [-png dir] [-zip file] [-sheet file] [flags] [merchants.csv]", e)
+	baseFile := fs.String("base", "", "JSON `file` with the fields shared by every row (\"-\" for standard input)")
+	profileName := fs.String("profile", profile.Default, "scheme `profile` each row is checked against: "+strings.Join(profile.Names(), ", "))
+	spec := fs.String("spec", "1.0", "EMV QRCPS MPM `version` to encode against")
+	pngDir := fs.String("png", "", "also write a PNG sticker per row into `dir`, named after the id column")
+	zipFile := fs.String("zip", "", "also write a PNG sticker per row and a manifest.csv into the ZIP `file`")
+	sheetFile := fs.String("sheet", "", "also lay the stickers out, captioned with the merchant name, in the printable PDF `file`")
+	page := fs.String("page", "a4", "paper size for -sheet: a4 or letter")
+	module := fs.Int("module", 0, "module size in `pixels` for -png, -zip and -sheet (default 8)")
+	ec := fs.String("ec", "M", "error correction `level` for -png, -zip and -sheet: L, M, Q or H")
+	caption := fs.Bool("caption", false, "print the merchant name below each -png and -zip symbol")
+	scheme := fs.String("scheme", "none", "scheme strip for -png, -zip and -sheet: none, bharatqr, upi or pix")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if _, ok := profile.Lookup(*profileName); !ok {
+		return usageErrorf("unknown profile %q (want one of %s)", *profileName, strings.Join(profile.Names(), ", "))
+	}
+	version, err := parseSpec(*spec)
+	if err != nil {
+		return err
+	}
+	opts := render.Options{ModuleSize: *module}
+	var ok bool
+	if opts.ErrorCorrection, ok = ecLevels[strings.ToUpper(*ec)]; !ok {
+		return usageErrorf("unknown error correction level %q (want L, M, Q or H)", *ec)
+	}
+	if opts.Scheme, ok = schemes[strings.ToLower(*scheme)]; !ok {
+		return usageErrorf("unknown scheme %q (want none, bharatqr, upi or pix)", *scheme)
+	}
+	sheet := render.SheetOptions{}
+	if sheet.Page, ok = pageSizes[strings.ToLower(*page)]; !ok {
+		return usageErrorf("unknown page size %q (want a4 or letter)", *page)
+	}
+	if fs.NArg() > 1 {
+		return usageErrorf("expected one CSV file argument, got %d", fs.NArg())
+	}
+	in := e.stdin
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	} else if *baseFile == "-" {
+		return usageErrorf("-base and the CSV cannot both be read from standard input")
+	}
+	var base *emvqr.Payload
+	if *baseFile != "" {
+		if base, err = readPayloadJSON(*baseFile, e.stdin, version); err != nil {
+			return err
+		}
+	}
+
+	rows, err := batch.Encode(in, batch.Options{Base: base, Profile: *profileName, SpecVersion: version})
+	if err != nil {
+		return err
+	}
+	if *pngDir != "" {
+		if err := os.MkdirAll(*pngDir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	w := csv.NewWriter(e.stdout)
+	w.Write([]string{"line", "id", "payload"})
+	failed := 0
+	for _, row := range rows {
+		if row.Err == nil && *pngDir != "" {
+			row.Err = writeSticker(*pngDir, row, opts, *caption)
+		}
+		if row.Err != nil {
+			failed++
+			reportRow(e.stderr, row)
+			continue
+		}
+		w.Write([]string{strconv.Itoa(row.Line), row.ID, row.Raw})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	if *zipFile != "" {
+		n, err := writeStickerZIP(*zipFile, rows, opts, *caption)
+		if err != nil {
+			return err
+		}
+		// Rows that failed to encode are already counted.
+		for _, row := range rows {
+			if row.Err != nil {
+				n--
+			}
+		}
+		if n > 0 {
+			fmt.Fprintf(e.stderr, "emvqr batch: %d row(s) could not be rendered into %s; see its manifest\n", n, *zipFile)
+			failed += n
+		}
+	}
+	if *sheetFile != "" {
+		if err := writeStickerSheet(*sheetFile, rows, opts, sheet); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(e.stderr, "emvqr batch: %d of %d row(s) failed\n", failed, len(rows))
+		return errInvalid
+	}
+	return nil
+}
+
+// reportRow writes the failure of one row to w.
+func reportRow(w io.Writer, row batch.Row) {
+	label := fmt.Sprintf("line %d", row.Line)
+	if row.ID != "" {
+		label += " (" + row.ID + ")"
+	}
+	var pe *batch.ProfileError
+	if !errors.As(row.Err, &pe) {
+		fmt.Fprintf(w, "%s: %v\n", label, row.Err)
+		return
+	}
+	fmt.Fprintf(w, "%s: %d finding(s) for profile %s\n", label, len(pe.Findings), pe.Profile)
+	for _, f := range pe.Findings {
+		fmt.Fprintf(w, "  - %s\n", f)
+	}
+}
+
+// writeSticker renders row as dir/<id>.png, or dir/line-<n>.png when the
+// row has no id.
+func writeSticker(dir string, row batch.Row, opts render.Options, caption bool) error {
+	name := "line-" + strconv.Itoa(row.Line)
+	if row.ID != "" {
+		if row.ID != filepath.Base(row.ID) || row.ID == "." || row.ID == ".." {
+			return fmt.Errorf("id %q cannot be used as a file name", row.ID)
+		}
+		name = row.ID
+	}
+	if caption {
+		opts.Caption = row.Payload.MerchantName
+	}
+	s, err := render.New(row.Raw, opts)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(dir, name+".png"))
+	if err != nil {
+		return err
+	}
+	if err := s.WritePNG(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeStickerZIP writes rows to the ZIP file name with batch.WriteZIP and
+// returns the number of rows without a sticker.
+func writeStickerZIP(name string, rows []batch.Row, opts render.Options, caption bool) (int, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	failed, err := batch.WriteZIP(context.Background(), f, rows, batch.ZIPOptions{Render: opts, Captions: caption})
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	return failed, f.Close()
+}
+
+// pageSizes maps the -page flag values to paper sizes.
+var pageSizes = map[string]render.PageSize{
+	"a4":     render.PageA4,
+	"letter": render.PageLetter,
+}
+
+// writeStickerSheet lays the rows that encoded out on the PDF file name with
+// render.WriteSheetPayloads.
+func writeStickerSheet(name string, rows []batch.Row, opts render.Options, sheet render.SheetOptions) error {
+	var payloads []*emvqr.Payload
+	for _, row := range rows {
+		if row.Err == nil {
+			payloads = append(payloads, row.Payload)
+		}
+	}
+	if len(payloads) == 0 {
+		return fmt.Errorf("no rows to lay out in %s", name)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := render.WriteSheetPayloads(f, payloads, opts, sheet); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// crcField is the ID and length prefix of the trailing CRC data object.
+const crcField = emvqr.IDCRC + "04"
+
+func runCRC(args []string, e *env) error {
+	fs := newFlagSet("crc", "[-fix] [payload]", e)
+	fix := fs.Bool("fix", false, "print the payload with a correct CRC instead of checking it")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	raw, err := readPayload(fs, e)
+	if err != nil {
+		return err
+	}
+
+	// A payload may be given with its CRC, with an empty "6304" field, or
+	// with no CRC field at all.
+	data, got := raw, ""
+	switch {
+	case len(raw) >= 8 && raw[len(raw)-8:len(raw)-4] == crcField:
+		data, got = raw[:len(raw)-8], raw[len(raw)-4:]
+	case strings.HasSuffix(raw, crcField):
+		data = raw[:len(raw)-4]
+	}
+	want := emvqr.ComputeCRC(data + crcField)
+
+	switch {
+	case *fix:
+		fmt.Fprintln(e.stdout, data+crcField+want)
+	case got == "":
+		fmt.Fprintln(e.stdout, want)
+	case strings.EqualFold(got, want):
+		fmt.Fprintf(e.stdout, "%s valid\n", want)
+	default:
+		fmt.Fprintf(e.stdout, "%s invalid, want %s\n", strings.ToUpper(got), want)
+		var crcErr *emvqr.CRCError
+		if errors.As(emvqr.ValidateCRC(raw), &crcErr) {
+			fmt.Fprintf(e.stderr, "emvqr crc: %s\n", crcErr.Hint())
+		}
+		return errInvalid
+	}
+	return nil
+}
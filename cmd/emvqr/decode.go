@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func runDecode(args []string, e *env) error {
+	fs := newFlagSet("decode", "[-json] [-schema struct|emv] [-image file] [-skip-crc] [-repair] [-spec 1.0|1.1] [payload]", e)
+	asJSON := fs.Bool("json", false, "print the decoded Payload as JSON instead of the TLV tree")
+	schema := fs.String("schema", "struct", "JSON `schema`: struct (Go field names) or emv (tag keys)")
+	image := fs.String("image", "", "read the payload from a QR Code in a PNG, JPEG or GIF `file`")
+	skipCRC := fs.Bool("skip-crc", false, "do not validate the CRC")
+	repair := fs.Bool("repair", false, "correct common scan errors, such as 'O' for '0' in numeric fields, reporting each on standard error")
+	spec := fs.String("spec", "1.0", "EMV QRCPS MPM `version` used to interpret the payload")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	version, err := parseSpec(*spec)
+	if err != nil {
+		return err
+	}
+	jsonSchema := emvqr.JSONSchema(*schema)
+	if jsonSchema != emvqr.JSONSchemaStruct && jsonSchema != emvqr.JSONSchemaEMV {
+		return usageErrorf("unknown JSON schema %q", *schema)
+	}
+	raw, err := readPayloadOrImage(fs, *image, e)
+	if err != nil {
+		return err
+	}
+	if *repair {
+		var corrections []emvqr.Correction
+		raw, corrections = emvqr.Repair(raw)
+		for _, c := range corrections {
+			fmt.Fprintf(e.stderr, "emvqr decode: repaired %v\n", c)
+		}
+	}
+
+	p, decodeErr := emvqr.DecodeWithOptions(raw, emvqr.DecodeOptions{
+		SkipCRCValidation: *skipCRC,
+		SpecVersion:       version,
+	})
+	if *asJSON {
+		if decodeErr != nil {
+			return decodeErr
+		}
+		data, err := emvqr.MarshalJSONWithOptions(p, emvqr.JSONOptions{Schema: jsonSchema})
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, data, "", "  "); err != nil {
+			return err
+		}
+		out.WriteByte('\n')
+		_, err = out.WriteTo(e.stdout)
+		return err
+	}
+
+	// The annotated tree is printed even when full decoding fails, so a
+	// payload with a bad CRC or an invalid field can still be inspected.
+	tree, err := emvqr.Explain(raw)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(e.stdout, tree)
+	if decodeErr != nil {
+		fmt.Fprintf(e.stderr, "emvqr decode: %v\n", decodeErr)
+		return errInvalid
+	}
+	return nil
+}
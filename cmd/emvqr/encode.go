@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// accountFlags collects repeated -account ID=VALUE flags.
+type accountFlags []emvqr.MerchantIdentifier
+
+func (a *accountFlags) String() string {
+	parts := make([]string, len(*a))
+	for i, mi := range *a {
+		parts[i] = mi.ID + "=" + mi.Value
+	}
+	return strings.Join(parts, ",")
+}
+
+func (a *accountFlags) Set(s string) error {
+	id, value, ok := strings.Cut(s, "=")
+	if !ok || id == "" || value == "" {
+		return fmt.Errorf("want ID=VALUE, got %q", s)
+	}
+	*a = append(*a, emvqr.MerchantIdentifier{ID: id, Value: value})
+	return nil
+}
+
+func runEncode(args []string, e *env) error {
+	fs := newFlagSet("encode", "[-json file] [field flags]", e)
+	jsonFile := fs.String("json", "", "read a Payload from a JSON `file` (\"-\" for standard input); field flags override it")
+	spec := fs.String("spec", "1.0", "EMV QRCPS MPM `version` to encode against")
+	var accounts accountFlags
+	fs.Var(&accounts, "account", "merchant account information `ID=VALUE` (IDs 02–25, repeatable)")
+	fields := []struct {
+		name, usage string
+		set         func(p *emvqr.Payload, v string)
+	}{
+		{"poi", "point of initiation method (11 static, 12 dynamic)", func(p *emvqr.Payload, v string) { p.PointOfInitiationMethod = emvqr.POI(v) }},
+		{"mcc", "merchant category code", func(p *emvqr.Payload, v string) { p.MerchantCategoryCode = v }},
+		{"currency", "ISO 4217 numeric transaction currency", func(p *emvqr.Payload, v string) { p.TransactionCurrency = v }},
+		{"amount", "transaction amount", func(p *emvqr.Payload, v string) { p.TransactionAmount = v }},
+		{"country", "ISO 3166-1 alpha-2 country code", func(p *emvqr.Payload, v string) { p.CountryCode = v }},
+		{"name", "merchant name", func(p *emvqr.Payload, v string) { p.MerchantName = v }},
+		{"city", "merchant city", func(p *emvqr.Payload, v string) { p.MerchantCity = v }},
+		{"postal", "postal code", func(p *emvqr.Payload, v string) { p.PostalCode = v }},
+		{"vpa", "UPI VPA for the tag 26 template", func(p *emvqr.Payload, v string) {
+			p.UPIVPAInfo = &emvqr.UPIVPATemplate{RuPayRID: emvqr.RuPayRIDValue, VPA: v}
+		}},
+		{"bill", "bill number (62-01)", func(p *emvqr.Payload, v string) {
+			p.SetAdditionalData(func(a *emvqr.AdditionalDataField) { a.BillNumber = v })
+		}},
+		{"store", "store label (62-03)", func(p *emvqr.Payload, v string) {
+			p.SetAdditionalData(func(a *emvqr.AdditionalDataField) { a.StoreLabel = v })
+		}},
+		{"reference", "reference label (62-05)", func(p *emvqr.Payload, v string) {
+			p.SetAdditionalData(func(a *emvqr.AdditionalDataField) { a.ReferenceLabel = v })
+		}},
+		{"terminal", "terminal label (62-07)", func(p *emvqr.Payload, v string) {
+			p.SetAdditionalData(func(a *emvqr.AdditionalDataField) { a.TerminalLabel = v })
+		}},
+		{"purpose", "purpose of transaction (62-08)", func(p *emvqr.Payload, v string) {
+			p.SetAdditionalData(func(a *emvqr.AdditionalDataField) { a.PurposeOfTransaction = v })
+		}},
+	}
+	values := make([]*string, len(fields))
+	for i, f := range fields {
+		values[i] = fs.String(f.name, "", f.usage)
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("unexpected argument %q", fs.Arg(0))
+	}
+	version, err := parseSpec(*spec)
+	if err != nil {
+		return err
+	}
+
+	p := emvqr.NewPayload()
+	if *jsonFile != "" {
+		if p, err = readPayloadJSON(*jsonFile, e.stdin, version); err != nil {
+			return err
+		}
+	}
+	for _, mi := range accounts {
+		if err := p.AddMerchantIdentifier(mi.ID, mi.Value); err != nil {
+			return usageErrorf("%v", err)
+		}
+	}
+	for i, f := range fields {
+		if *values[i] != "" {
+			f.set(p, *values[i])
+		}
+	}
+
+	raw, err := emvqr.EncodeWithOptions(p, emvqr.EncodeOptions{SpecVersion: version})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(e.stdout, raw)
+	return err
+}
+
+// readPayloadJSON decodes a Payload from a JSON file, or from stdin when
+// name is "-". Either schema printed by "emvqr decode -json" is accepted;
+// unknown struct fields are rejected.
+func readPayloadJSON(name string, stdin io.Reader, version emvqr.SpecVersion) (*emvqr.Payload, error) {
+	r := stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := emvqr.NewPayload()
+	opts := emvqr.JSONOptions{SpecVersion: version, DisallowUnknownFields: true}
+	if err := emvqr.UnmarshalJSONWithOptions(data, p, opts); err != nil {
+		return nil, fmt.Errorf("reading payload JSON: %w", err)
+	}
+	return p, nil
+}
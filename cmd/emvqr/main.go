@@ -0,0 +1,195 @@
+// Command emvqr inspects, builds, validates and renders EMV merchant-presented
+// QR Code payloads from the shell.
+//
+// Usage:
+//
+//	emvqr decode   [-json] [-schema struct|emv] [-image file] [-skip-crc] [-spec 1.0|1.1] [payload]
+//	emvqr encode   [-json file] [field flags]
+//	emvqr validate [-profile name] [-image file] [payload]
+//	emvqr crc      [-fix] [payload]
+//	emvqr render   -o file [-module n] [-ec L|M|Q|H] [-caption text] [payload]
+//	emvqr batch    [-base file] [-profile name] [-png dir] [merchants.csv]
+//
+// Commands that take a payload read it from the argument or, when the
+// argument is omitted or "-", from standard input, so they compose in
+// pipelines:
+//
+//	emvqr encode -json merchant.json | emvqr render -o sticker.png
+//
+// The exit status is 0 on success, 1 when the payload is invalid and 2 for
+// usage errors.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/scan"
+)
+
+// command is a subcommand entry point.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, env *env) error
+}
+
+var commands = []command{
+	{"decode", "print the annotated TLV tree or JSON of a payload", runDecode},
+	{"encode", "build a payload from JSON or flags", runEncode},
+	{"validate", "check a payload against a scheme profile", runValidate},
+	{"crc", "compute, check or fix the CRC of a payload", runCRC},
+	{"render", "write a payload as a PNG, SVG or PDF QR Code", runRender},
+	{"batch", "encode merchant rows from CSV, optionally as PNG stickers", runBatch},
+}
+
+// env carries the process streams so commands can be tested in-process.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// usageError is a command-line mistake, reported with exit status 2.
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{fmt.Sprintf(format, args...)}
+}
+
+// errInvalid marks a payload that was read but did not pass, reported with
+// exit status 1 once the command has printed its findings.
+var errInvalid = errors.New("invalid payload")
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{os.Stdin, os.Stdout, os.Stderr}))
+}
+
+// run executes the command line and returns the process exit status.
+func run(args []string, e *env) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(e.stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+		err := c.run(args[1:], e)
+		var ue *usageError
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.As(err, &ue):
+			fmt.Fprintf(e.stderr, "emvqr %s: %v\n", c.name, err)
+			return 2
+		case errors.Is(err, errInvalid):
+			return 1
+		default:
+			fmt.Fprintf(e.stderr, "emvqr %s: %v\n", c.name, err)
+			return 1
+		}
+	}
+	fmt.Fprintf(e.stderr, "emvqr: unknown command %q\n\n", args[0])
+	usage(e.stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: emvqr <command> [flags] [payload]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'emvqr <command> -h' for the flags of a command.")
+}
+
+// newFlagSet returns a flag set that reports errors instead of exiting.
+func newFlagSet(name, args string, e *env) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: emvqr %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args into fs, reporting parse failures as usage
+// errors.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageErrorf("%v", err)
+	}
+	return nil
+}
+
+// readPayload returns the payload named by the single positional argument,
+// or read from standard input when it is absent or "-". Surrounding
+// whitespace, such as a trailing newline, is removed.
+func readPayload(fs *flag.FlagSet, e *env) (string, error) {
+	if fs.NArg() > 1 {
+		return "", usageErrorf("expected one payload argument, got %d", fs.NArg())
+	}
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		return strings.TrimSpace(fs.Arg(0)), nil
+	}
+	data, err := io.ReadAll(e.stdin)
+	if err != nil {
+		return "", fmt.Errorf("reading standard input: %w", err)
+	}
+	raw := strings.TrimSpace(string(data))
+	if raw == "" {
+		return "", usageErrorf("no payload given")
+	}
+	return raw, nil
+}
+
+// readPayloadOrImage is readPayload, except that a non-empty image path
+// makes the payload come from the QR Code in that image file instead.
+func readPayloadOrImage(fs *flag.FlagSet, image string, e *env) (string, error) {
+	if image == "" {
+		return readPayload(fs, e)
+	}
+	if fs.NArg() > 0 {
+		return "", usageErrorf("-image and a payload argument are mutually exclusive")
+	}
+	data, err := os.ReadFile(image)
+	if err != nil {
+		return "", err
+	}
+	raw, _, err := scan.DecodeImageBytes(data)
+	if raw == "" && err != nil {
+		return "", err
+	}
+	// Payload errors are reported by the command itself.
+	return raw, nil
+}
+
+// parseSpec maps the -spec flag value onto a spec version.
+func parseSpec(s string) (emvqr.SpecVersion, error) {
+	switch s {
+	case "", "1.0":
+		return emvqr.SpecVersion10, nil
+	case "1.1":
+		return emvqr.SpecVersion11, nil
+	}
+	return 0, usageErrorf("unknown spec version %q (want 1.0 or 1.1)", s)
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/scan"
+)
+
+// staticQR is the EMVCo base example (static QR, single Visa MAI).
+const staticQR = "000201021640001234567890125204525153038405802US5911ABC Hammers6008New York63047222"
+
+// emvqrRun runs the command line with stdin and returns the exit status and
+// both output streams.
+func emvqrRun(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &env{strings.NewReader(stdin), &stdout, &stderr})
+	return code, stdout.String(), stderr.String()
+}
+
+func TestDecode_Tree(t *testing.T) {
+	code, out, errOut := emvqrRun(t, "", "decode", staticQR)
+	if code != 0 {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+	for _, want := range []string{
+		"00 Payload Format Indicator",
+		"02 Merchant Account Information (Visa)",
+		"59 Merchant Name",
+		"7222 (valid)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDecode_Templates(t *testing.T) {
+	p, _ := emvqr.Decode(staticQR)
+	p.SetAdditionalData(func(a *emvqr.AdditionalDataField) { a.BillNumber = "INV-1" })
+	raw, err := emvqr.Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Payloads are also read from standard input.
+	code, out, _ := emvqrRun(t, raw+"\n", "decode")
+	if code != 0 {
+		t.Fatalf("exit %d", code)
+	}
+	if !strings.Contains(out, "len 09\n   01 Bill Number") {
+		t.Errorf("template not printed as a tree:\n%s", out)
+	}
+}
+
+func TestDecode_BadCRC(t *testing.T) {
+	bad := staticQR[:len(staticQR)-4] + "0000"
+	code, out, errOut := emvqrRun(t, "", "decode", bad)
+	if code != 1 {
+		t.Errorf("exit %d, want 1", code)
+	}
+	if !strings.Contains(out, "0000 (invalid, want 7222)") || !strings.Contains(errOut, "CRC mismatch") {
+		t.Errorf("stdout %q, stderr %q", out, errOut)
+	}
+}
+
+func TestDecode_Repair(t *testing.T) {
+	damaged := strings.Replace(staticQR, "5303840", "53O3840", 1)
+	if code, _, _ := emvqrRun(t, "", "decode", damaged); code != 1 {
+		t.Errorf("exit %d without -repair, want 1", code)
+	}
+	code, out, errOut := emvqrRun(t, "", "decode", "-repair", damaged)
+	if code != 0 {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+	if !strings.Contains(errOut, `repaired digit at tag 53: "53O3" -> "5303"`) || !strings.Contains(out, "ABC Hammers") {
+		t.Errorf("stdout %q, stderr %q", out, errOut)
+	}
+}
+
+func TestDecode_JSON(t *testing.T) {
+	code, out, _ := emvqrRun(t, "", "decode", "-json", staticQR)
+	if code != 0 {
+		t.Fatalf("exit %d", code)
+	}
+	var p emvqr.Payload
+	if err := json.Unmarshal([]byte(out), &p); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if p.MerchantName != "ABC Hammers" || p.CRC != "7222" {
+		t.Errorf("decoded %+v", p)
+	}
+}
+
+func TestDecode_JSONSchemaEMV(t *testing.T) {
+	code, out, _ := emvqrRun(t, "", "decode", "-json", "-schema", "emv", staticQR)
+	if code != 0 || !strings.Contains(out, `"59": "ABC Hammers"`) {
+		t.Fatalf("exit %d, output:\n%s", code, out)
+	}
+	code, raw, errOut := emvqrRun(t, out, "encode", "-json", "-")
+	if code != 0 || strings.TrimSpace(raw) != staticQR {
+		t.Errorf("encode: exit %d, output %q, stderr %q", code, raw, errOut)
+	}
+}
+
+func TestEncode_Flags(t *testing.T) {
+	code, out, errOut := emvqrRun(t, "", "encode",
+		"-account", "02=4000123456789012", "-mcc", "5251", "-currency", "840",
+		"-country", "US", "-name", "ABC Hammers", "-city", "New York")
+	if code != 0 {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+	if got := strings.TrimSpace(out); got != staticQR {
+		t.Errorf("got %q, want %q", got, staticQR)
+	}
+}
+
+func TestEncode_JSONRoundTrip(t *testing.T) {
+	_, js, _ := emvqrRun(t, "", "decode", "-json", staticQR)
+	code, out, errOut := emvqrRun(t, js, "encode", "-json", "-")
+	if code != 0 {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+	if got := strings.TrimSpace(out); got != staticQR {
+		t.Errorf("got %q, want %q", got, staticQR)
+	}
+
+	// Flags override JSON fields.
+	_, out, _ = emvqrRun(t, js, "encode", "-json", "-", "-amount", "9.99")
+	p, err := emvqr.Decode(strings.TrimSpace(out))
+	if err != nil || p.TransactionAmount != "9.99" {
+		t.Errorf("override not applied: %v %+v", err, p)
+	}
+}
+
+func TestEncode_MissingField(t *testing.T) {
+	code, _, errOut := emvqrRun(t, "", "encode", "-account", "02=4000123456789012")
+	if code != 1 || !strings.Contains(errOut, "missing required field") {
+		t.Errorf("exit %d, stderr %q", code, errOut)
+	}
+}
+
+func TestValidate_Profiles(t *testing.T) {
+	code, out, _ := emvqrRun(t, "", "validate", staticQR)
+	if code != 0 || !strings.HasPrefix(out, "OK") {
+		t.Errorf("emvco: exit %d, output %q", code, out)
+	}
+
+	code, out, _ = emvqrRun(t, "", "validate", "-profile", "bharatqr", staticQR)
+	if code != 1 {
+		t.Errorf("bharatqr: exit %d, want 1", code)
+	}
+	for _, want := range []string{"tag 58 (Country Code) must be IN", "tag 61 (Postal Code) is mandatory"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("bharatqr output missing %q:\n%s", want, out)
+		}
+	}
+
+	p := emvqr.NewPayload()
+	_ = p.AddMerchantIdentifier("06", "6100010031755635")
+	_ = p.SetUPIVPATemplate(emvqr.RuPayRIDValue, "shop@upi", "")
+	p.PointOfInitiationMethod = emvqr.POIStaticQR
+	p.MerchantCategoryCode = "5411"
+	p.TransactionCurrency = "356"
+	p.CountryCode = "IN"
+	p.MerchantName = "Sharma Stores"
+	p.MerchantCity = "Mumbai"
+	p.PostalCode = "400001"
+	raw, err := emvqr.Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, out, _ := emvqrRun(t, "", "validate", "-profile", "bharatqr", raw); code != 0 {
+		t.Errorf("conforming Bharat QR: exit %d\n%s", code, out)
+	}
+}
+
+func TestValidate_Formats(t *testing.T) {
+	p, _ := emvqr.Decode(staticQR)
+	p.MerchantCategoryCode = "52A1"
+	p.TransactionAmount = "1.2.3"
+	raw, _ := emvqr.Encode(p)
+	code, out, _ := emvqrRun(t, "", "validate", raw)
+	if code != 1 || !strings.Contains(out, "tag 52") || !strings.Contains(out, "tag 54") {
+		t.Errorf("exit %d, output:\n%s", code, out)
+	}
+}
+
+func TestCRC(t *testing.T) {
+	body := staticQR[:len(staticQR)-8]
+	tests := []struct {
+		name string
+		args []string
+		code int
+		out  string
+	}{
+		{"valid", []string{staticQR}, 0, "7222 valid"},
+		{"invalid", []string{body + "6304ABCD"}, 1, "ABCD invalid, want 7222"},
+		{"compute", []string{body}, 0, "7222"},
+		{"compute empty field", []string{body + "6304"}, 0, "7222"},
+		{"fix", []string{"-fix", body + "6304ABCD"}, 0, staticQR},
+		{"fix missing", []string{"-fix", body}, 0, staticQR},
+	}
+	for _, tc := range tests {
+		code, out, _ := emvqrRun(t, "", append([]string{"crc"}, tc.args...)...)
+		if code != tc.code || strings.TrimSpace(out) != tc.out {
+			t.Errorf("%s: exit %d, output %q; want %d, %q", tc.name, code, out, tc.code, tc.out)
+		}
+	}
+	if _, _, errOut := emvqrRun(t, "", "crc", body+"6304ABCD"); !strings.Contains(errOut, "a value was altered or misread") {
+		t.Errorf("invalid CRC: stderr %q, want a hint", errOut)
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	png := filepath.Join(dir, "sticker.png")
+	code, _, errOut := emvqrRun(t, "", "render", "-o", png, "-ec", "Q", "-caption", "ABC Hammers", staticQR)
+	if code != 0 {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+	data, err := os.ReadFile(png)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _, err := scan.DecodeImageBytes(data)
+	if err != nil || raw != staticQR {
+		t.Errorf("rendered PNG decodes to %q, %v", raw, err)
+	}
+
+	// The image can be fed back into decode and validate.
+	if code, out, _ := emvqrRun(t, "", "decode", "-image", png); code != 0 || !strings.Contains(out, "ABC Hammers") {
+		t.Errorf("decode -image: exit %d\n%s", code, out)
+	}
+
+	for format, magic := range map[string]string{"svg": "<svg", "pdf": "%PDF-", "text": "█"} {
+		code, out, _ := emvqrRun(t, "", "render", "-o", "-", "-format", format, staticQR)
+		if code != 0 || !strings.Contains(out, magic) {
+			t.Errorf("%s: exit %d, output starts %.20q", format, code, out)
+		}
+	}
+}
+
+func TestBatch(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.json")
+	if err := os.WriteFile(base, []byte(`{"CountryCode": "US", "TransactionCurrency": "840"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	const merchants = "id,name,city,mcc,02\n" +
+		"abc,ABC Hammers,New York,5251,4000123456789012\n" +
+		"bad,No Account,Boston,5251,\n"
+	stickers := filepath.Join(dir, "stickers")
+	code, out, errOut := emvqrRun(t, merchants, "batch", "-base", base, "-png", stickers)
+	if code != 1 {
+		t.Errorf("exit %d, want 1", code)
+	}
+	if want := "line,id,payload\n2,abc," + staticQR + "\n"; out != want {
+		t.Errorf("stdout %q, want %q", out, want)
+	}
+	if !strings.Contains(errOut, "line 3 (bad):") || !strings.Contains(errOut, "1 of 2 row(s) failed") {
+		t.Errorf("stderr %q", errOut)
+	}
+	data, err := os.ReadFile(filepath.Join(stickers, "abc.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, _, err := scan.DecodeImageBytes(data); err != nil || raw != staticQR {
+		t.Errorf("sticker decodes to %q, %v", raw, err)
+	}
+}
+
+func TestBatch_ZIP(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "stickers.zip")
+	const merchants = "id,name,city,mcc,currency,country,02\n" +
+		"abc,ABC Hammers,New York,5251,840,US,4000123456789012\n"
+	if code, _, errOut := emvqrRun(t, merchants, "batch", "-zip", archive); code != 0 {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, " "); got != "abc.png manifest.csv" {
+		t.Errorf("archive files = %q", got)
+	}
+}
+
+func TestBatch_Sheet(t *testing.T) {
+	sheet := filepath.Join(t.TempDir(), "stickers.pdf")
+	const merchants = "id,name,city,mcc,currency,country,02\n" +
+		"abc,ABC Hammers,New York,5251,840,US,4000123456789012\n" +
+		"xyz,XYZ Nails,Boston,5251,840,US,4000123456789013\n"
+	if code, _, errOut := emvqrRun(t, merchants, "batch", "-sheet", sheet, "-page", "letter"); code != 0 {
+		t.Fatalf("exit %d, stderr %q", code, errOut)
+	}
+	out, err := os.ReadFile(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-")) || !bytes.Contains(out, []byte("/MediaBox [0 0 612 792]")) {
+		t.Errorf("sheet is not a Letter-size PDF: %.40q", out)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"frobnicate"},
+		{"render", staticQR},
+		{"render", "-o", "x.png", "-ec", "Z", staticQR},
+		{"validate", "-profile", "nope", staticQR},
+		{"decode", "-spec", "2.0", staticQR},
+		{"decode", staticQR, staticQR},
+		{"decode", "-json", "-schema", "yaml", staticQR},
+		{"encode", "-account", "02"},
+		{"crc"},
+		{"batch", "-profile", "nope"},
+		{"batch", "-base", "-", "-"},
+		{"batch", "-page", "a5"},
+	} {
+		if code, _, _ := emvqrRun(t, "", args...); code != 2 {
+			t.Errorf("%q: exit %d, want 2", args, code)
+		}
+	}
+}
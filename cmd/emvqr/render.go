@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/render"
+)
+
+var ecLevels = map[string]render.ErrorCorrection{
+	"L": render.ECLow, "M": render.ECMedium, "Q": render.ECQuartile, "H": render.ECHigh,
+}
+
+var schemes = map[string]render.Scheme{
+	"none": render.SchemeNone, "bharatqr": render.SchemeBharatQR, "upi": render.SchemeUPI, "pix": render.SchemePIX,
+}
+
+func runRender(args []string, e *env) error {
+	fs := newFlagSet("render", "-o file [flags] [payload]", e)
+	out := fs.String("o", "", "output `file` (\"-\" for standard output)")
+	format := fs.String("format", "", "output `format`: png, svg, pdf or text (default from the -o extension, else png)")
+	module := fs.Int("module", 0, "module size in `pixels` (default 8)")
+	quiet := fs.Int("quiet", 0, "quiet zone in `modules` (default 4, negative for none)")
+	ec := fs.String("ec", "M", "error correction `level`: L, M, Q or H")
+	maxVersion := fs.Int("max-version", 0, "largest QR Code `version` to use (1–40)")
+	caption := fs.String("caption", "", "caption `text` printed below the symbol")
+	scheme := fs.String("scheme", "none", "scheme strip: none, bharatqr, upi or pix")
+	logo := fs.String("logo", "", "PNG, JPEG or GIF `file` drawn over the centre of the symbol")
+	skipValidation := fs.Bool("skip-validation", false, "render the input even if it is not a valid EMV payload")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return usageErrorf("-o is required")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*out)), ".")
+		if _, ok := writers[*format]; !ok {
+			*format = "png"
+		}
+	}
+	write, ok := writers[*format]
+	if !ok {
+		return usageErrorf("unknown format %q (want png, svg, pdf or text)", *format)
+	}
+	opts := render.Options{
+		ModuleSize:            *module,
+		QuietZone:             *quiet,
+		MaxVersion:            *maxVersion,
+		Caption:               *caption,
+		SkipPayloadValidation: *skipValidation,
+	}
+	if opts.ErrorCorrection, ok = ecLevels[strings.ToUpper(*ec)]; !ok {
+		return usageErrorf("unknown error correction level %q (want L, M, Q or H)", *ec)
+	}
+	if opts.Scheme, ok = schemes[strings.ToLower(*scheme)]; !ok {
+		return usageErrorf("unknown scheme %q (want none, bharatqr, upi or pix)", *scheme)
+	}
+	if *logo != "" {
+		img, err := readImage(*logo)
+		if err != nil {
+			return err
+		}
+		opts.Logo = img
+	}
+	raw, err := readPayload(fs, e)
+	if err != nil {
+		return err
+	}
+
+	s, err := render.New(raw, opts)
+	if err != nil {
+		return err
+	}
+	if *out == "-" {
+		return write(s, e.stdout)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := write(s, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "wrote %s (version %d-%s, %d×%d modules)\n", *out, s.Version, s.ErrorCorrection, s.Size(), s.Size())
+	return nil
+}
+
+// writers maps output formats onto Symbol writers.
+var writers = map[string]func(*render.Symbol, io.Writer) error{
+	"png": (*render.Symbol).WritePNG,
+	"svg": (*render.Symbol).WriteSVG,
+	"pdf": (*render.Symbol).WritePDF,
+	"text": func(s *render.Symbol, w io.Writer) error {
+		return s.WriteText(w, render.TextOptions{})
+	},
+}
+
+func readImage(name string) (image.Image, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", name, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/profile"
+)
+
+func runValidate(args []string, e *env) error {
+	names := profile.Names()
+	fs := newFlagSet("validate", "[-profile name] [-image file] [-spec 1.0|1.1] [payload]", e)
+	profileName := fs.String("profile", profile.Default, "scheme `profile`: "+strings.Join(names, ", "))
+	image := fs.String("image", "", "read the payload from a QR Code in a PNG, JPEG or GIF `file`")
+	spec := fs.String("spec", "1.0", "EMV QRCPS MPM `version` used to interpret the payload")
+	list := fs.Bool("list", false, "list the available profiles and exit")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *list {
+		for _, pr := range profile.All() {
+			fmt.Fprintf(e.stdout, "%-9s %s\n", pr.Name, pr.Summary)
+		}
+		return nil
+	}
+	pr, ok := profile.Lookup(*profileName)
+	if !ok {
+		return usageErrorf("unknown profile %q (want one of %s)", *profileName, strings.Join(names, ", "))
+	}
+	version, err := parseSpec(*spec)
+	if err != nil {
+		return err
+	}
+	raw, err := readPayloadOrImage(fs, *image, e)
+	if err != nil {
+		return err
+	}
+
+	p, err := emvqr.DecodeWithOptions(raw, emvqr.DecodeOptions{SpecVersion: version})
+	if err != nil {
+		report(e.stdout, pr, []string{err.Error()})
+		return errInvalid
+	}
+	findings := pr.Check(p, raw)
+	report(e.stdout, pr, findings)
+	if len(findings) > 0 {
+		return errInvalid
+	}
+	return nil
+}
+
+func report(w io.Writer, pr profile.Profile, findings []string) {
+	if len(findings) == 0 {
+		fmt.Fprintf(w, "OK: payload conforms to profile %s\n", pr.Name)
+		return
+	}
+	fmt.Fprintf(w, "FAIL: %d finding(s) for profile %s\n", len(findings), pr.Name)
+	for _, f := range findings {
+		fmt.Fprintf(w, "  - %s\n", f)
+	}
+}
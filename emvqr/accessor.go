@@ -0,0 +1,237 @@
+package emvqr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Field describes one populated data object of a payload, as returned by
+// Payload.Fields.
+type Field struct {
+	Name        string // name accepted by GetField and SetField, e.g. "AdditionalData.BillNumber"; "" if the field has none
+	Path        string // dotted tag path as in Flatten, e.g. "62.01"
+	Description string // specification name, e.g. "Bill Number"
+	Value       string
+	Format      string // "ans", "n" or "amount"
+	MinLength   int
+	MaxLength   int
+}
+
+// namedField maps a field name to its tag path and storage. ref returns the
+// string holding the value, allocating the enclosing template when create
+// is set; otherwise it returns nil if the template is absent.
+type namedField struct {
+	name, path string
+	ref        func(p *Payload, create bool) *string
+}
+
+// namedFields lists the fields GetField and SetField accept, named after
+// their Payload struct fields.
+var namedFields = []namedField{
+	topField("PayloadFormatIndicator", IDPayloadFormatIndicator, func(p *Payload) *string { return &p.PayloadFormatIndicator }),
+	topField("PointOfInitiationMethod", IDPointOfInitiationMethod, func(p *Payload) *string { return (*string)(&p.PointOfInitiationMethod) }),
+	upiVPAField("RuPayRID", MAIGloballyUniqueID, func(t *UPIVPATemplate) *string { return &t.RuPayRID }),
+	upiVPAField("VPA", "01", func(t *UPIVPATemplate) *string { return &t.VPA }),
+	upiVPAField("MinimumAmount", "02", func(t *UPIVPATemplate) *string { return &t.MinimumAmount }),
+	upiRefField("RuPayRID", UPIVPARefRuPayRID, func(r *UPIVPAReference) *string { return &r.RuPayRID }),
+	upiRefField("TransactionRef", UPIVPARefTransactionRef, func(r *UPIVPAReference) *string { return &r.TransactionRef }),
+	upiRefField("ReferenceURL", UPIVPARefURL, func(r *UPIVPAReference) *string { return &r.ReferenceURL }),
+	aadhaarField("RuPayRID", AadhaarRuPayRID, func(a *AadhaarInfo) *string { return &a.RuPayRID }),
+	aadhaarField("AadhaarNumber", AadhaarAadhaarNum, func(a *AadhaarInfo) *string { return &a.AadhaarNumber }),
+	topField("MerchantCategoryCode", IDMerchantCategoryCode, func(p *Payload) *string { return &p.MerchantCategoryCode }),
+	topField("TransactionCurrency", IDTransactionCurrency, func(p *Payload) *string { return &p.TransactionCurrency }),
+	topField("TransactionAmount", IDTransactionAmount, func(p *Payload) *string { return &p.TransactionAmount }),
+	topField("TipOrConvenienceIndicator", IDTipOrConvenienceIndicator, func(p *Payload) *string { return &p.TipOrConvenienceIndicator }),
+	topField("ValueConvenienceFeeFixed", IDValueConvenienceFeeFixed, func(p *Payload) *string { return &p.ValueConvenienceFeeFixed }),
+	topField("ValueConvenienceFeePercent", IDValueConvenienceFeePercent, func(p *Payload) *string { return &p.ValueConvenienceFeePercent }),
+	topField("CountryCode", IDCountryCode, func(p *Payload) *string { return &p.CountryCode }),
+	topField("MerchantName", IDMerchantName, func(p *Payload) *string { return &p.MerchantName }),
+	topField("MerchantCity", IDMerchantCity, func(p *Payload) *string { return &p.MerchantCity }),
+	topField("PostalCode", IDPostalCode, func(p *Payload) *string { return &p.PostalCode }),
+	adfField("BillNumber", ADFBillNumber, func(a *AdditionalDataField) *string { return &a.BillNumber }),
+	adfField("MobileNumber", ADFMobileNumber, func(a *AdditionalDataField) *string { return &a.MobileNumber }),
+	adfField("StoreLabel", ADFStoreLabel, func(a *AdditionalDataField) *string { return &a.StoreLabel }),
+	adfField("LoyaltyNumber", ADFLoyaltyNumber, func(a *AdditionalDataField) *string { return &a.LoyaltyNumber }),
+	adfField("ReferenceLabel", ADFReferenceLabel, func(a *AdditionalDataField) *string { return &a.ReferenceLabel }),
+	adfField("CustomerLabel", ADFCustomerLabel, func(a *AdditionalDataField) *string { return &a.CustomerLabel }),
+	adfField("TerminalLabel", ADFTerminalLabel, func(a *AdditionalDataField) *string { return &a.TerminalLabel }),
+	adfField("PurposeOfTransaction", ADFPurposeOfTransaction, func(a *AdditionalDataField) *string { return &a.PurposeOfTransaction }),
+	adfField("AdditionalConsumerDataRequest", ADFAdditionalConsumerDataRequest, func(a *AdditionalDataField) *string { return &a.AdditionalConsumerDataRequest }),
+	adfField("MerchantTaxID", ADFMerchantTaxID, func(a *AdditionalDataField) *string { return &a.MerchantTaxID }),
+	adfField("MerchantChannel", ADFMerchantChannel, func(a *AdditionalDataField) *string { return &a.MerchantChannel }),
+	langField("LanguagePreference", LangPreference, func(lt *LanguageTemplate) *string { return &lt.LanguagePreference }),
+	langField("MerchantName", LangMerchantName, func(lt *LanguageTemplate) *string { return &lt.MerchantName }),
+	langField("MerchantCity", LangMerchantCity, func(lt *LanguageTemplate) *string { return &lt.MerchantCity }),
+}
+
+// topField and the functions below build namedFields entries for a
+// top-level field and for the sub-fields of each typed template.
+func topField(name, id string, ref func(*Payload) *string) namedField {
+	return namedField{name, id, func(p *Payload, _ bool) *string { return ref(p) }}
+}
+
+func adfField(name, id string, ref func(*AdditionalDataField) *string) namedField {
+	return namedField{"AdditionalData." + name, IDAdditionalDataFieldTemplate + "." + id,
+		func(p *Payload, create bool) *string {
+			if p.AdditionalData == nil && create {
+				p.AdditionalData = &AdditionalDataField{}
+			}
+			if p.AdditionalData == nil {
+				return nil
+			}
+			return ref(p.AdditionalData)
+		}}
+}
+
+func langField(name, id string, ref func(*LanguageTemplate) *string) namedField {
+	return namedField{"LanguageTemplate." + name, IDMerchantInfoLanguageTemplate + "." + id,
+		func(p *Payload, create bool) *string {
+			if p.LanguageTemplate == nil && create {
+				p.LanguageTemplate = &LanguageTemplate{}
+			}
+			if p.LanguageTemplate == nil {
+				return nil
+			}
+			return ref(p.LanguageTemplate)
+		}}
+}
+
+func upiVPAField(name, id string, ref func(*UPIVPATemplate) *string) namedField {
+	return namedField{"UPIVPAInfo." + name, IDUPIVPATemplate + "." + id,
+		func(p *Payload, create bool) *string {
+			if p.UPIVPAInfo == nil && create {
+				p.UPIVPAInfo = &UPIVPATemplate{RuPayRID: RuPayRIDValue}
+			}
+			if p.UPIVPAInfo == nil {
+				return nil
+			}
+			return ref(p.UPIVPAInfo)
+		}}
+}
+
+func upiRefField(name, id string, ref func(*UPIVPAReference) *string) namedField {
+	return namedField{"UPITransactionRef." + name, IDUPIVPAReference + "." + id,
+		func(p *Payload, create bool) *string {
+			if p.UPITransactionRef == nil && create {
+				p.UPITransactionRef = &UPIVPAReference{RuPayRID: RuPayRIDValue}
+			}
+			if p.UPITransactionRef == nil {
+				return nil
+			}
+			return ref(p.UPITransactionRef)
+		}}
+}
+
+func aadhaarField(name, id string, ref func(*AadhaarInfo) *string) namedField {
+	return namedField{"MerchantAadhaar." + name, IDAadhaarTemplate + "." + id,
+		func(p *Payload, create bool) *string {
+			if p.MerchantAadhaar == nil && create {
+				p.MerchantAadhaar = &AadhaarInfo{RuPayRID: RuPayRIDValue}
+			}
+			if p.MerchantAadhaar == nil {
+				return nil
+			}
+			return ref(p.MerchantAadhaar)
+		}}
+}
+
+// lookupField returns the named field with the given name or tag path.
+func lookupField(name string) (namedField, error) {
+	for _, f := range namedFields {
+		if f.name == name || f.path == name {
+			return f, nil
+		}
+	}
+	return namedField{}, newError(CodeInvalidFormat,
+		fmt.Errorf("%w: unknown field %q", ErrInvalidFormat, name), "tag", name)
+}
+
+// GetField returns the value of a field given its name, e.g. "MerchantName"
+// or "AdditionalData.BillNumber", or its tag path, e.g. "59" or "62.01".
+// Absent fields and templates read as "". Names are those of the Payload
+// struct fields; FieldNames lists them all.
+func (p *Payload) GetField(name string) (string, error) {
+	f, err := lookupField(name)
+	if err != nil {
+		return "", err
+	}
+	if s := f.ref(p, false); s != nil {
+		return *s, nil
+	}
+	return "", nil
+}
+
+// SetField sets a field given its name or tag path, as accepted by GetField,
+// creating the enclosing template if needed. The value is checked against
+// the field's format and length; on failure SetField returns an error
+// wrapping ErrInvalidFormat and leaves the payload unchanged. An empty value
+// clears the field. Fields of Tags 26–28 are also updated in the matching
+// MerchantIdentifiers entry, if any.
+func (p *Payload) SetField(name, value string) error {
+	f, err := lookupField(name)
+	if err != nil {
+		return err
+	}
+	if value != "" {
+		if warnings := pathSpec(f.path).check("Tag "+f.path, value); len(warnings) > 0 {
+			return newError(CodeInvalidFormat,
+				fmt.Errorf("%w: %s", ErrInvalidFormat, warnings[0]), "tag", f.path)
+		}
+	}
+	s := f.ref(p, value != "")
+	if s == nil {
+		return nil
+	}
+	*s = value
+	if tag, sub, ok := strings.Cut(f.path, "."); ok {
+		for i := range p.MerchantIdentifiers {
+			if mi := &p.MerchantIdentifiers[i]; mi.ID == tag {
+				setSubField(&mi.SubFields, sub, value)
+			}
+		}
+	}
+	return nil
+}
+
+// FieldNames returns the names accepted by GetField and SetField, in
+// encoding order.
+func FieldNames() []string {
+	names := make([]string, len(namedFields))
+	for i, f := range namedFields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// Fields returns every populated field of the payload, ordered by tag path,
+// with its specification metadata. Fields without a name, such as
+// merchant account information, unreserved templates and RFU fields, are
+// included with an empty Name; their values can be changed through the
+// corresponding Payload fields.
+func (p *Payload) Fields() []Field {
+	names := make(map[string]string, len(namedFields))
+	for _, f := range namedFields {
+		names[f.path] = f.name
+	}
+	flat := p.Flatten()
+	paths := make([]string, 0, len(flat))
+	for path := range flat {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fields := make([]Field, 0, len(paths))
+	for _, path := range paths {
+		spec := pathSpec(path)
+		fields = append(fields, Field{
+			Name:        names[path],
+			Path:        path,
+			Description: spec.name,
+			Value:       flat[path],
+			Format:      spec.format.String(),
+			MinLength:   spec.minLen,
+			MaxLength:   spec.maxLen,
+		})
+	}
+	return fields
+}
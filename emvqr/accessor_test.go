@@ -0,0 +1,93 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetSetField(t *testing.T) {
+	p := basePayload()
+	for _, tt := range []struct{ name, value string }{
+		{"TransactionAmount", "150"},
+		{"62.05", "ORDER-42"},
+		{"LanguageTemplate.MerchantName", "ABC Martillos"},
+		{"UPIVPAInfo.VPA", "shop@okaxis"},
+	} {
+		if err := p.SetField(tt.name, tt.value); err != nil {
+			t.Fatalf("SetField(%q): %v", tt.name, err)
+		}
+		got, err := p.GetField(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, tt.name, tt.value, got)
+	}
+	assertEqual(t, "TransactionAmount", "150", p.TransactionAmount)
+	assertEqual(t, "ReferenceLabel", "ORDER-42", p.AdditionalData.ReferenceLabel)
+	assertEqual(t, "RuPay RID", RuPayRIDValue, p.UPIVPAInfo.RuPayRID)
+	if got, _ := p.GetField("MerchantName"); got != "ABC Hammers" {
+		t.Errorf("MerchantName = %q", got)
+	}
+	if got, _ := p.GetField("MerchantAadhaar.AadhaarNumber"); got != "" {
+		t.Errorf("absent template read as %q", got)
+	}
+
+	if err := p.SetField("TransactionAmount", "1.5.0"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("invalid amount: want ErrInvalidFormat, got %v", err)
+	}
+	if err := p.SetField("MerchantCity", "Much Too Long A City Name"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("long city: want ErrInvalidFormat, got %v", err)
+	}
+	assertEqual(t, "MerchantCity", "New York", p.MerchantCity)
+	if _, err := p.GetField("Nickname"); ErrorCode(err) != CodeInvalidFormat {
+		t.Errorf("unknown field: got %v", err)
+	}
+
+	if err := p.SetField("TransactionAmount", ""); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "cleared amount", "", p.TransactionAmount)
+}
+
+func TestSetFieldSyncsMerchantIdentifier(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetField("UPIVPAInfo.VPA", "new@sbi"); err != nil {
+		t.Fatal(err)
+	}
+	for _, mi := range p.MerchantIdentifiers {
+		if mi.ID == IDUPIVPATemplate {
+			assertEqual(t, "MI 26.01", "new@sbi", mi.SubField("01"))
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	p := basePayload()
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.BillNumber = "INV-1" })
+	fields := p.Fields()
+	var paths []string
+	for _, f := range fields {
+		paths = append(paths, f.Path)
+	}
+	want := []string{"00", "02", "52", "53", "58", "59", "60", "62.01"}
+	if len(paths) != len(want) {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+	for i := range want {
+		assertEqual(t, "path", want[i], paths[i])
+	}
+	last := fields[len(fields)-1]
+	if last.Name != "AdditionalData.BillNumber" || last.Description != "Bill Number" ||
+		last.Format != "ans" || last.MaxLength != 25 || last.Value != "INV-1" {
+		t.Errorf("62.01 = %+v", last)
+	}
+	if fields[1].Name != "" || fields[3].Format != "n" {
+		t.Errorf("unexpected metadata: %+v, %+v", fields[1], fields[3])
+	}
+	if got := FieldNames(); len(got) != len(namedFields) || got[0] != "PayloadFormatIndicator" {
+		t.Errorf("FieldNames() = %v", got)
+	}
+}
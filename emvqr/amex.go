@@ -0,0 +1,62 @@
+package emvqr
+
+import "fmt"
+
+// amexIDs are the primitive Merchant Account Information IDs EMVCo assigns
+// to American Express, in order of preference.
+var amexIDs = [...]string{"11", "12"}
+
+// AmexInfo is the American Express merchant identifier of a payload, as
+// carried alongside Visa, Mastercard and RuPay on many Bharat QRs.
+type AmexInfo struct {
+	ID     string // tag ID, "11" or "12"
+	Number string // 10-digit Service Establishment (SE) number or 15-digit merchant number
+}
+
+// IsSENumber reports whether Number is a 10-digit Service Establishment
+// number rather than a 15-digit merchant number.
+func (a AmexInfo) IsSENumber() bool {
+	return len(a.Number) == 10
+}
+
+// ValidateAmexNumber checks that n is an American Express Service
+// Establishment number (10 digits) or merchant number (15 digits). It
+// returns an error wrapping ErrInvalidFormat.
+func ValidateAmexNumber(n string) error {
+	if !isNumeric(n) || len(n) != 10 && len(n) != 15 {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: Amex SE or merchant number %q must be 10 or 15 digits", ErrInvalidFormat, n),
+			"tag", amexIDs[0], "value", n)
+	}
+	return nil
+}
+
+// GetAmexInfo returns the American Express identifier of p, from Tag 11 or
+// else Tag 12, or nil if p has neither. The number is returned as found;
+// ValidateAmexNumber checks it.
+func (p *Payload) GetAmexInfo() *AmexInfo {
+	for _, id := range amexIDs {
+		for _, mi := range p.MerchantIdentifiers {
+			if mi.ID == id {
+				return &AmexInfo{ID: id, Number: mi.Value}
+			}
+		}
+	}
+	return nil
+}
+
+// SetAmexInfo sets the American Express SE or merchant number of p,
+// replacing the value of Tag 11 if present or adding it. It returns an
+// error, leaving p unchanged, if the number fails ValidateAmexNumber.
+func (p *Payload) SetAmexInfo(number string) error {
+	if err := ValidateAmexNumber(number); err != nil {
+		return err
+	}
+	for i := range p.MerchantIdentifiers {
+		if p.MerchantIdentifiers[i].ID == amexIDs[0] {
+			p.MerchantIdentifiers[i].Value = number
+			return nil
+		}
+	}
+	return p.AddMerchantIdentifier(amexIDs[0], number)
+}
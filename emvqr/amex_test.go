@@ -0,0 +1,52 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetAmexInfo_RealWorld(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	a := p.GetAmexInfo()
+	if a == nil {
+		t.Fatal("GetAmexInfo() = nil")
+	}
+	assertEqual(t, "ID", "11", a.ID)
+	assertEqual(t, "Number", "310900031273986", a.Number)
+	if a.IsSENumber() {
+		t.Error("IsSENumber() = true for a 15-digit merchant number")
+	}
+	if err := ValidateAmexNumber(a.Number); err != nil {
+		t.Errorf("ValidateAmexNumber() error: %v", err)
+	}
+}
+
+func TestSetAmexInfo(t *testing.T) {
+	p := basePayload()
+	if p.GetAmexInfo() != nil {
+		t.Fatal("GetAmexInfo() of a Visa-only payload is not nil")
+	}
+	if err := p.SetAmexInfo("9876543210"); err != nil {
+		t.Fatalf("SetAmexInfo() error: %v", err)
+	}
+	if err := p.SetAmexInfo("1234567890"); err != nil {
+		t.Fatalf("second SetAmexInfo() error: %v", err)
+	}
+	a := p.GetAmexInfo()
+	if a == nil || a.ID != "11" || a.Number != "1234567890" || !a.IsSENumber() {
+		t.Errorf("GetAmexInfo() = %+v", a)
+	}
+	if n := len(p.MerchantIdentifiers); n != 2 {
+		t.Errorf("got %d merchant identifiers, want 2", n)
+	}
+
+	for _, bad := range []string{"", "123456789", "12345678901", "3109000312739X6"} {
+		if err := p.SetAmexInfo(bad); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("SetAmexInfo(%q) error = %v, want ErrInvalidFormat", bad, err)
+		}
+	}
+	assertEqual(t, "Number after rejected set", "1234567890", p.GetAmexInfo().Number)
+}
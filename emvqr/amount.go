@@ -0,0 +1,66 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateAmountAgainstMinimum checks the transaction amount (Tag 54)
+// against the minimum amount of the UPI VPA template (Tag 26.02). The
+// comparison is exact on the decimal strings, so "100" and "100.00" are
+// equal and no floating-point rounding is involved.
+//
+// It returns nil when either amount is absent, an error wrapping
+// ErrInvalidFormat when either is not a decimal amount, and an *Error with
+// CodeBelowMinimum wrapping ErrBelowMinimum, with "amount" and "minimum"
+// params, when the amount is less than the minimum.
+func (p *Payload) ValidateAmountAgainstMinimum() error {
+	amount, minimum := p.TransactionAmount, p.GetMinimumAmount()
+	if amount == "" || minimum == "" {
+		return nil
+	}
+	cmp, err := compareAmounts(amount, minimum)
+	if err != nil {
+		return err
+	}
+	if cmp < 0 {
+		return newError(CodeBelowMinimum,
+			fmt.Errorf("%w: Tag 54 amount %s is less than Tag 26.02 minimum %s", ErrBelowMinimum, amount, minimum),
+			"tag", IDTransactionAmount, "amount", amount, "minimum", minimum)
+	}
+	return nil
+}
+
+// compareAmounts compares two decimal amounts exactly, returning -1, 0 or
+// +1. It returns an error wrapping ErrInvalidFormat if either is not an
+// amount.
+func compareAmounts(a, b string) (int, error) {
+	for _, s := range []string{a, b} {
+		if !isAmount(s) {
+			return 0, fmt.Errorf("%w: %q is not an amount", ErrInvalidFormat, s)
+		}
+	}
+	aw, af, _ := strings.Cut(a, ".")
+	bw, bf, _ := strings.Cut(b, ".")
+	aw, bw = strings.TrimLeft(aw, "0"), strings.TrimLeft(bw, "0")
+	if len(aw) != len(bw) {
+		return sign(len(aw) - len(bw)), nil
+	}
+	for len(af) < len(bf) {
+		af += "0"
+	}
+	for len(bf) < len(af) {
+		bf += "0"
+	}
+	return strings.Compare(aw+af, bw+bf), nil
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompareAmounts(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"100", "100.00", 0},
+		{"0100.5", "100.50", 0},
+		{"99.99", "100", -1},
+		{"100.01", "100", 1},
+		{"1000", "999.999", 1},
+		{".5", "0.49", 1},
+		{"0.1", "0.10000000000000001", -1},
+	}
+	for _, tc := range tests {
+		got, err := compareAmounts(tc.a, tc.b)
+		if err != nil || got != tc.want {
+			t.Errorf("compareAmounts(%q, %q) = %d, %v; want %d", tc.a, tc.b, got, err, tc.want)
+		}
+	}
+	if _, err := compareAmounts("1,000", "1"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("compareAmounts(1,000) error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestValidateAmountAgainstMinimum(t *testing.T) {
+	p := basePayload()
+	if err := p.SetUPIVPATemplate(RuPayRIDValue, "shop@upi", "100.00"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ValidateAmountAgainstMinimum(); err != nil {
+		t.Errorf("no amount: %v", err)
+	}
+	p.TransactionAmount = "100"
+	if err := p.ValidateAmountAgainstMinimum(); err != nil {
+		t.Errorf("amount equal to minimum: %v", err)
+	}
+	p.TransactionAmount = "99.99"
+	err := p.ValidateAmountAgainstMinimum()
+	if !errors.Is(err, ErrBelowMinimum) || ErrorCode(err) != CodeBelowMinimum {
+		t.Fatalf("amount below minimum: err = %v, want ErrBelowMinimum", err)
+	}
+	assertEqual(t, "minimum param", "100.00", ErrorParams(err)["minimum"])
+	assertEqual(t, "message", "The amount must be at least 100.00.", LocalizedMessage(err, "en"))
+}
@@ -0,0 +1,116 @@
+package emvqr
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ItemizedAmountsGUID is the Globally Unique Identifier of the Unreserved
+// Template in which SetItemizedAmounts stores the breakdown of a bill.
+const ItemizedAmountsGUID = "COM.GITHUB.EMVQR.AMOUNTS"
+
+// Sub-field IDs of the itemized amounts template.
+const (
+	ItemizedBase     = "01" // item subtotal
+	ItemizedTax      = "02" // taxes
+	ItemizedDelivery = "03" // delivery or service charge
+)
+
+// ItemizedAmounts is the breakdown of a bill that a wallet may display, as
+// decimal amounts in the Tag 53 currency, e.g. {"450.00", "22.50", "30.00"}
+// for a food delivery order. Empty amounts are absent.
+type ItemizedAmounts struct {
+	Base     string
+	Tax      string
+	Delivery string
+}
+
+// IsZero reports whether a has no amounts.
+func (a ItemizedAmounts) IsZero() bool {
+	return a == ItemizedAmounts{}
+}
+
+// Total returns the sum of the amounts of a, formatted with exp decimal
+// places; see CurrencyExponent. It returns an error wrapping
+// ErrInvalidFormat if an amount is not a decimal number.
+func (a ItemizedAmounts) Total(exp int) (string, error) {
+	total, err := a.total()
+	if err != nil {
+		return "", err
+	}
+	return total.FloatString(exp), nil
+}
+
+// fields returns the sub-fields of a, in ID order.
+func (a ItemizedAmounts) fields() []DataObject {
+	var subs []DataObject
+	for _, sf := range []DataObject{{ID: ItemizedBase, Value: a.Base}, {ID: ItemizedTax, Value: a.Tax}, {ID: ItemizedDelivery, Value: a.Delivery}} {
+		if sf.Value != "" {
+			subs = append(subs, sf)
+		}
+	}
+	return subs
+}
+
+// total returns the exact sum of the amounts of a.
+func (a ItemizedAmounts) total() (*big.Rat, error) {
+	total := new(big.Rat)
+	for _, sf := range a.fields() {
+		r, ok := new(big.Rat).SetString(sf.Value)
+		if !ok || !isStrictAmount(sf.Value) || len(sf.Value) > 13 {
+			return nil, fmt.Errorf("%w: itemized amount %s %q must be a decimal amount of at most 13 characters, such as \"10.50\"",
+				ErrInvalidFormat, sf.ID, sf.Value)
+		}
+		total.Add(total, r)
+	}
+	return total, nil
+}
+
+// SetItemizedAmounts stores the breakdown a of the bill in an Unreserved
+// Template with ItemizedAmountsGUID, replacing any earlier one; the zero
+// ItemizedAmounts removes it. The breakdown is informational: wallets that
+// do not know the template ignore it, and the Transaction Amount (Tag 54)
+// remains the amount charged. If Tag 54 is present, the amounts must add
+// up to it, so that a wallet never shows a breakdown that disagrees with
+// the charge. Errors wrap ErrInvalidFormat and leave the payload unchanged.
+func (p *Payload) SetItemizedAmounts(a ItemizedAmounts) error {
+	total, err := a.total()
+	if err != nil {
+		return err
+	}
+	if !a.IsZero() && p.TransactionAmount != "" {
+		charged, err := parseAmount(IDTransactionAmount, p.TransactionAmount)
+		if err != nil {
+			return err
+		}
+		if total.Cmp(charged) != 0 {
+			exp := CurrencyExponent(p.TransactionCurrency)
+			return newError(CodeInvalidFormat,
+				fmt.Errorf("%w: itemized amounts add up to %s, but Tag 54 amount is %s",
+					ErrInvalidFormat, total.FloatString(exp), p.TransactionAmount),
+				"tag", IDTransactionAmount, "value", p.TransactionAmount)
+		}
+	}
+	return p.setExtension(ItemizedAmountsGUID, a.fields())
+}
+
+// ItemizedAmounts returns the breakdown stored by SetItemizedAmounts, and
+// whether the payload has one.
+func (p *Payload) ItemizedAmounts() (ItemizedAmounts, bool) {
+	var a ItemizedAmounts
+	i := p.extensionIndex(ItemizedAmountsGUID)
+	if i < 0 {
+		return a, false
+	}
+	for _, sf := range p.UnreservedTemplates[i].SubFields {
+		switch sf.ID {
+		case ItemizedBase:
+			a.Base = sf.Value
+		case ItemizedTax:
+			a.Tax = sf.Value
+		case ItemizedDelivery:
+			a.Delivery = sf.Value
+		}
+	}
+	return a, true
+}
@@ -0,0 +1,70 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestItemizedAmounts(t *testing.T) {
+	p := basePayload()
+	p.TransactionAmount = "502.50"
+	a := ItemizedAmounts{Base: "450.00", Tax: "22.50", Delivery: "30"}
+	if err := p.SetItemizedAmounts(a); err != nil {
+		t.Fatalf("SetItemizedAmounts() error: %v", err)
+	}
+
+	raw := mustEncode(t, p)
+	if !strings.Contains(raw, "0024"+ItemizedAmountsGUID+"0106450.00"+"020522.50"+"030230") {
+		t.Errorf("Encode() = %q, want the itemized amounts template", raw)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	got, ok := decoded.ItemizedAmounts()
+	if !ok || got != a {
+		t.Errorf("ItemizedAmounts() = %+v, %v; want %+v, true", got, ok, a)
+	}
+	total, err := got.Total(CurrencyExponent(decoded.TransactionCurrency))
+	if err != nil {
+		t.Fatalf("Total() error: %v", err)
+	}
+	assertEqual(t, "Total", "502.50", total)
+	assertEqual(t, "TransactionAmount", "502.50", decoded.TransactionAmount)
+
+	if err := decoded.SetItemizedAmounts(ItemizedAmounts{}); err != nil {
+		t.Fatalf("SetItemizedAmounts(zero) error: %v", err)
+	}
+	if _, ok := decoded.ItemizedAmounts(); ok {
+		t.Error("ItemizedAmounts() after removal reported a breakdown")
+	}
+}
+
+func TestItemizedAmounts_Static(t *testing.T) {
+	p := basePayload()
+	if err := p.SetItemizedAmounts(ItemizedAmounts{Base: "100", Tax: "18"}); err != nil {
+		t.Fatalf("SetItemizedAmounts() without Tag 54 error: %v", err)
+	}
+	got, _ := p.ItemizedAmounts()
+	assertEqual(t, "Delivery", "", got.Delivery)
+	assertEqual(t, "Tax", "18", got.Tax)
+}
+
+func TestSetItemizedAmounts_Errors(t *testing.T) {
+	p := basePayload()
+	p.TransactionAmount = "100.00"
+	for _, a := range []ItemizedAmounts{
+		{Base: "90", Tax: "5"},
+		{Base: "100", Tax: "-0"},
+		{Base: "1,00"},
+		{Base: "12345678901234"},
+	} {
+		if err := p.SetItemizedAmounts(a); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("SetItemizedAmounts(%+v) error = %v, want ErrInvalidFormat", a, err)
+		}
+	}
+	if len(p.UnreservedTemplates) != 0 {
+		t.Errorf("failed SetItemizedAmounts() added %v", p.UnreservedTemplates)
+	}
+}
@@ -0,0 +1,252 @@
+// Package batch builds EMV QR Code payloads in bulk from CSV, for printing
+// merchant stickers, and decodes stored payloads in bulk for audits (see
+// Decode).
+//
+// Each CSV row describes one merchant. Its cells are applied on top of a base
+// payload holding the values shared by every row (country, currency,
+// acquirer account), the result is encoded and validated against a scheme
+// profile, and problems are reported per row without stopping the batch.
+//
+// The header row names the columns. A column is either a tag path, as used
+// by emvqr.Payload.Flatten ("59", "62.05", "04"), or one of the aliases in
+// Columns ("name", "city", "mcc", "vpa", ...). The optional "id" column
+// identifies the row in results, e.g. to name the sticker file. Empty cells
+// keep the base value.
+//
+// Example:
+//
+//	base := emvqr.NewPayload()
+//	base.CountryCode = "IN"
+//	base.TransactionCurrency = "356"
+//	rows, err := batch.Encode(f, batch.Options{Base: base, Profile: "bharatqr"})
+//	if err != nil {
+//	    log.Fatal(err) // unreadable CSV or unknown column
+//	}
+//	for _, row := range rows {
+//	    if row.Err != nil {
+//	        log.Printf("line %d: %v", row.Line, row.Err)
+//	        continue
+//	    }
+//	    fmt.Println(row.ID, row.Raw)
+//	}
+package batch
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/profile"
+)
+
+// Columns maps the column aliases accepted in the CSV header onto tag paths.
+var Columns = map[string]string{
+	"poi":       emvqr.IDPointOfInitiationMethod,
+	"mcc":       emvqr.IDMerchantCategoryCode,
+	"currency":  emvqr.IDTransactionCurrency,
+	"amount":    emvqr.IDTransactionAmount,
+	"country":   emvqr.IDCountryCode,
+	"name":      emvqr.IDMerchantName,
+	"city":      emvqr.IDMerchantCity,
+	"postal":    emvqr.IDPostalCode,
+	"vpa":       emvqr.IDUPIVPATemplate + ".01",
+	"bill":      emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFBillNumber,
+	"mobile":    emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFMobileNumber,
+	"store":     emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFStoreLabel,
+	"reference": emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFReferenceLabel,
+	"terminal":  emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFTerminalLabel,
+	"purpose":   emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFPurposeOfTransaction,
+}
+
+// Options controls Encode.
+type Options struct {
+	// Base holds the values shared by every row. Nil means
+	// emvqr.NewPayload().
+	Base *emvqr.Payload
+
+	// Profile names the profile.Profile each encoded row is checked
+	// against. The zero value is profile.Default.
+	Profile string
+
+	// SpecVersion selects the EMV QRCPS MPM revision used to encode and
+	// check rows.
+	SpecVersion emvqr.SpecVersion
+}
+
+// Row is the outcome for one CSV row.
+type Row struct {
+	// Line is the CSV line number; the header is line 1.
+	Line int
+	// ID is the value of the "id" column, or "".
+	ID string
+	// Payload is the merchant's payload, or nil if the row could not be
+	// turned into one.
+	Payload *emvqr.Payload
+	// Raw is the encoded payload, or "" if encoding failed.
+	Raw string
+	// Err is the reason the row failed: an invalid cell, an encoding error
+	// or a *ProfileError.
+	Err error
+}
+
+// ProfileError reports a row that encoded but did not pass its profile.
+type ProfileError struct {
+	Profile  string
+	Findings []string
+}
+
+func (e *ProfileError) Error() string {
+	return fmt.Sprintf("batch: %d finding(s) for profile %s: %s", len(e.Findings), e.Profile, strings.Join(e.Findings, "; "))
+}
+
+// Encode reads merchant rows from r and returns one Row per data row, in
+// order. Problems with individual rows are reported in Row.Err; the error
+// result is reserved for an unknown profile, an unreadable CSV or a header
+// with an unknown or duplicate column.
+func Encode(r io.Reader, opts Options) ([]Row, error) {
+	return EncodeContext(context.Background(), r, opts)
+}
+
+// EncodeContext is like Encode but stops reading rows once ctx is done,
+// returning the rows encoded so far with ctx's error.
+func EncodeContext(ctx context.Context, r io.Reader, opts Options) ([]Row, error) {
+	name := opts.Profile
+	if name == "" {
+		name = profile.Default
+	}
+	pr, ok := profile.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("batch: unknown profile %q (want one of %s)", name, strings.Join(profile.Names(), ", "))
+	}
+	base := opts.Base
+	if base == nil {
+		base = emvqr.NewPayload()
+	}
+	shared := base.Flatten()
+	delete(shared, emvqr.IDCRC)
+
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("batch: reading CSV header: %w", err)
+	}
+	paths, idCol, err := columnPaths(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return rows, err
+		}
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("batch: reading CSV line %d: %w", line, err)
+		}
+		row := Row{Line: line}
+		if idCol >= 0 && idCol < len(rec) {
+			row.ID = rec[idCol]
+		}
+		fields := make(map[string]string, len(shared)+len(rec))
+		for path, value := range shared {
+			fields[path] = value
+		}
+		for i, path := range paths {
+			if path != "" && i < len(rec) && rec[i] != "" {
+				fields[path] = rec[i]
+			}
+		}
+		encodeRow(&row, fields, pr, opts.SpecVersion)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// columnPaths maps each header cell onto a tag path. The "id" column maps
+// to "" and its index is returned separately, or -1 if absent.
+func columnPaths(header []string) ([]string, int, error) {
+	paths := make([]string, len(header))
+	idCol := -1
+	seen := make(map[string]string, len(header))
+	for i, h := range header {
+		col := strings.ToLower(strings.TrimSpace(h))
+		if col == "id" {
+			idCol = i
+			continue
+		}
+		path, ok := Columns[col]
+		if !ok {
+			if !isTagPath(col) {
+				return nil, 0, fmt.Errorf("batch: unknown CSV column %q", h)
+			}
+			path = col
+		}
+		if prev, dup := seen[path]; dup {
+			return nil, 0, fmt.Errorf("batch: CSV columns %q and %q both set tag %s", prev, h, path)
+		}
+		seen[path] = h
+		paths[i] = path
+	}
+	return paths, idCol, nil
+}
+
+// encodeRow builds, encodes and checks the payload described by fields.
+func encodeRow(row *Row, fields map[string]string, pr profile.Profile, v emvqr.SpecVersion) {
+	// The Bharat QR templates 26–28 always start with the RuPay RID, so a
+	// "vpa" column alone is enough to produce Tag 26.
+	for _, id := range []string{emvqr.IDUPIVPATemplate, emvqr.IDUPIVPAReference, emvqr.IDAadhaarTemplate} {
+		rid := id + "." + emvqr.MAIGloballyUniqueID
+		if _, ok := fields[rid]; ok {
+			continue
+		}
+		for path := range fields {
+			if strings.HasPrefix(path, id+".") {
+				fields[rid] = emvqr.RuPayRIDValue
+				break
+			}
+		}
+	}
+
+	p, err := emvqr.FromFlatWithOptions(fields, emvqr.DecodeOptions{SpecVersion: v})
+	if err != nil {
+		row.Err = err
+		return
+	}
+	if pr.Normalize != nil {
+		p.Normalize(*pr.Normalize)
+	}
+	row.Payload = p
+	raw, err := emvqr.EncodeWithOptions(p, emvqr.EncodeOptions{SpecVersion: v})
+	if err != nil {
+		row.Err = err
+		return
+	}
+	row.Raw = raw
+	decoded, err := emvqr.DecodeWithOptions(raw, emvqr.DecodeOptions{SpecVersion: v})
+	if err != nil {
+		row.Err = err
+		return
+	}
+	if findings := pr.Check(decoded, raw); len(findings) > 0 {
+		row.Err = &ProfileError{Profile: pr.Name, Findings: findings}
+	}
+}
+
+// isTagPath reports whether s looks like "59" or "62.05".
+func isTagPath(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if len(part) != 2 || part[0] < '0' || part[0] > '9' || part[1] < '0' || part[1] > '9' {
+			return false
+		}
+	}
+	return true
+}
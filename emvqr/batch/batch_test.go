@@ -0,0 +1,116 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func bharatBase() *emvqr.Payload {
+	p := emvqr.NewPayload()
+	p.PointOfInitiationMethod = emvqr.POIStaticQR
+	p.TransactionCurrency = "356"
+	p.CountryCode = "IN"
+	p.MerchantCity = "Mumbai"
+	return p
+}
+
+func TestEncode_BharatQR(t *testing.T) {
+	const input = `id,name,city,mcc,vpa,postal,62.03
+M1,Sharma Stores,,5411,sharma@upi,400001,S1
+M2,Gupta Sweets,Pune,5441,gupta@upi,411001,
+M3,Missing Postal,,5411,x@upi,,
+`
+	rows, err := Encode(strings.NewReader(input), Options{Base: bharatBase(), Profile: "bharatqr"})
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	r := rows[0]
+	if r.Err != nil || r.ID != "M1" || r.Line != 2 {
+		t.Fatalf("row 1 = %+v", r)
+	}
+	p, err := emvqr.Decode(r.Raw)
+	if err != nil {
+		t.Fatalf("Decode(row 1) error: %v", err)
+	}
+	if p.MerchantName != "Sharma Stores" || p.MerchantCity != "Mumbai" || p.UPIVPAInfo == nil ||
+		p.UPIVPAInfo.VPA != "sharma@upi" || p.UPIVPAInfo.RuPayRID != emvqr.RuPayRIDValue ||
+		p.AdditionalData == nil || p.AdditionalData.StoreLabel != "S1" {
+		t.Errorf("row 1 decoded = %+v", p)
+	}
+
+	if rows[1].Err != nil || rows[1].Payload.MerchantCity != "Pune" {
+		t.Errorf("row 2 = %+v", rows[1])
+	}
+
+	var pe *ProfileError
+	if !errors.As(rows[2].Err, &pe) || !strings.Contains(strings.Join(pe.Findings, "\n"), "tag 61") {
+		t.Errorf("row 3 error = %v, want a tag 61 profile finding", rows[2].Err)
+	}
+	if rows[2].Raw == "" {
+		t.Error("row 3 Raw is empty; profile failures should keep the encoded payload")
+	}
+}
+
+func TestEncode_Normalize(t *testing.T) {
+	const input = "id,name,city,mcc,vpa,postal\nM1,\"  Sharma   Stores \",Navi  Mumbai,5411,sharma@upi, 400001\n"
+	rows, err := Encode(strings.NewReader(input), Options{Base: bharatBase(), Profile: "bharatqr"})
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	p := rows[0].Payload
+	if rows[0].Err != nil || p.MerchantName != "Sharma Stores" || p.MerchantCity != "Navi Mumbai" || p.PostalCode != "400001" {
+		t.Errorf("row 1 = %+v, payload %+v", rows[0], p)
+	}
+}
+
+func TestEncode_RowErrors(t *testing.T) {
+	const input = `name,city,mcc,02
+ABC Hammers,New York,5251,4000123456789012
+Bad Tag,New York,5251,
+`
+	base := emvqr.NewPayload()
+	base.TransactionCurrency = "840"
+	base.CountryCode = "US"
+	rows, err := Encode(strings.NewReader(input), Options{Base: base})
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	if rows[0].Err != nil {
+		t.Errorf("row 1 error: %v", rows[0].Err)
+	}
+	if rows[1].Err == nil || rows[1].Raw != "" {
+		t.Errorf("row 2 = %+v, want an encoding error (no merchant account)", rows[1])
+	}
+}
+
+func TestEncode_HeaderErrors(t *testing.T) {
+	for _, input := range []string{
+		"name,colour\nA,red\n",
+		"name,59\nA,B\n",
+		"",
+	} {
+		if _, err := Encode(strings.NewReader(input), Options{}); err == nil {
+			t.Errorf("Encode(%q) expected error, got nil", input)
+		}
+	}
+	if _, err := Encode(strings.NewReader("name\nA\n"), Options{Profile: "nope"}); err == nil {
+		t.Error("Encode() expected error for unknown profile, got nil")
+	}
+}
+
+func TestEncodeContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rows, err := EncodeContext(ctx, strings.NewReader("id,name\nM1,A\n"), Options{Base: bharatBase()})
+	if !errors.Is(err, context.Canceled) || len(rows) != 0 {
+		t.Errorf("EncodeContext() = %d rows, %v; want none and context.Canceled", len(rows), err)
+	}
+}
@@ -0,0 +1,120 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/profile"
+)
+
+// DecodeOptions controls Decode.
+type DecodeOptions struct {
+	// Decode is passed to emvqr.DecodeContext for every input, so its
+	// VerifyMerchant hook, if any, runs concurrently.
+	Decode emvqr.DecodeOptions
+
+	// Profile names the profile.Profile each decoded payload is checked
+	// against. The zero value is profile.Default.
+	Profile string
+
+	// Workers bounds the number of inputs processed at once. Zero or less
+	// means runtime.GOMAXPROCS(0).
+	Workers int
+}
+
+// Result is the outcome for one input of Decode.
+type Result struct {
+	// Index is the position of the input; for DecodeStream, the zero-based
+	// line number.
+	Index int
+	// Input is the QR string that was decoded.
+	Input string
+	// Payload is the decoded payload, or nil if decoding failed. It is also
+	// set when only the profile check or merchant verification failed.
+	Payload *emvqr.Payload
+	// Err is the reason the input failed: a decode error, a *ProfileError,
+	// or the context's error for inputs not processed before cancellation.
+	Err error
+}
+
+// Decode decodes and validates stored QR strings concurrently, e.g. to
+// re-check every QR of an acquirer during an audit. It returns one Result
+// per input, in input order. Problems with individual inputs are reported in
+// Result.Err; the error result is reserved for an unknown profile and for
+// the context's error when ctx is cancelled.
+func Decode(ctx context.Context, inputs []string, opts DecodeOptions) ([]Result, error) {
+	pr, err := lookupProfile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	workers := min(opts.workers(), len(inputs))
+
+	results := make([]Result, len(inputs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = decodeOne(ctx, i, inputs[i], opts.Decode, pr)
+			}
+		}()
+	}
+	next := 0
+feed:
+	for ; next < len(inputs); next++ {
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	for i := next; i < len(inputs); i++ {
+		results[i] = Result{Index: i, Input: inputs[i], Err: ctx.Err()}
+	}
+	return results, ctx.Err()
+}
+
+// decodeOne decodes and checks a single input.
+func decodeOne(ctx context.Context, i int, raw string, opts emvqr.DecodeOptions, pr profile.Profile) Result {
+	res := Result{Index: i, Input: raw}
+	if err := ctx.Err(); err != nil {
+		res.Err = err
+		return res
+	}
+	res.Payload, res.Err = emvqr.DecodeContext(ctx, raw, opts)
+	if res.Err != nil {
+		return res
+	}
+	if findings := pr.Check(res.Payload, raw); len(findings) > 0 {
+		res.Err = &ProfileError{Profile: pr.Name, Findings: findings}
+	}
+	return res
+}
+
+// lookupProfile returns the named profile, or profile.Default for "".
+func lookupProfile(name string) (profile.Profile, error) {
+	if name == "" {
+		name = profile.Default
+	}
+	pr, ok := profile.Lookup(name)
+	if !ok {
+		return pr, fmt.Errorf("batch: unknown profile %q (want one of %s)", name, strings.Join(profile.Names(), ", "))
+	}
+	return pr, nil
+}
+
+// workers returns the worker count to use.
+func (opts DecodeOptions) workers() int {
+	if opts.Workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return opts.Workers
+}
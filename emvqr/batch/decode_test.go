@@ -0,0 +1,83 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func TestDecode(t *testing.T) {
+	base := bharatBase()
+	base.MerchantName = "Sharma Chai Stall"
+	base.MerchantCategoryCode = "5812"
+	base.MerchantIdentifiers = []emvqr.MerchantIdentifier{{ID: "02", Value: "4000123456789012"}}
+	good, err := emvqr.Encode(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := []string{good, good[:len(good)-1] + "0", "garbage", good}
+	for range 50 {
+		inputs = append(inputs, good)
+	}
+
+	var calls atomic.Int32
+	opts := DecodeOptions{Workers: 4}
+	opts.Decode.VerifyMerchant = func(context.Context, *emvqr.Payload) error {
+		calls.Add(1)
+		return nil
+	}
+	results, err := Decode(context.Background(), inputs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(inputs) {
+		t.Fatalf("got %d results for %d inputs", len(results), len(inputs))
+	}
+	for i, r := range results {
+		if r.Index != i {
+			t.Errorf("result %d has index %d", i, r.Index)
+		}
+	}
+	if results[0].Err != nil || results[0].Payload.MerchantName != "Sharma Chai Stall" {
+		t.Errorf("result 0 = %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, emvqr.ErrCRCMismatch) {
+		t.Errorf("result 1: want ErrCRCMismatch, got %v", results[1].Err)
+	}
+	if results[2].Err == nil || results[2].Payload != nil {
+		t.Errorf("result 2 = %+v", results[2])
+	}
+	if got := int(calls.Load()); got != len(inputs)-2 {
+		t.Errorf("VerifyMerchant called %d times, want %d", got, len(inputs)-2)
+	}
+
+	results, err = Decode(context.Background(), inputs[:1], DecodeOptions{Profile: "bharatqr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pe *ProfileError
+	if !errors.As(results[0].Err, &pe) || pe.Profile != "bharatqr" {
+		t.Errorf("want bharatqr ProfileError, got %v", results[0].Err)
+	}
+
+	if _, err := Decode(context.Background(), inputs, DecodeOptions{Profile: "nope"}); err == nil {
+		t.Error("unknown profile accepted")
+	}
+}
+
+func TestDecode_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := Decode(ctx, []string{"a", "b", "c"}, DecodeOptions{Workers: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+	for i, r := range results {
+		if !errors.Is(r.Err, context.Canceled) || r.Index != i {
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+}
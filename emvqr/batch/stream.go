@@ -0,0 +1,96 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// maxLineLength bounds the lines DecodeStream accepts. EMV QR payloads are
+// at most 512 characters; anything far longer is not a payload file.
+const maxLineLength = 64 * 1024
+
+// DecodeStream reads newline-delimited QR strings from r, such as a
+// multi-gigabyte export, and decodes and checks them like Decode, calling fn
+// with each result in line order. Only a window of lines proportional to
+// opts.Workers is held in memory at a time. Blank lines are skipped and
+// surrounding whitespace, including a "\r" line ending, is trimmed.
+//
+// DecodeStream stops and returns the error when fn returns one, when ctx is
+// cancelled, or when r cannot be read; results already delivered stand. It
+// returns nil once every line has been delivered.
+func DecodeStream(ctx context.Context, r io.Reader, opts DecodeOptions, fn func(Result) error) error {
+	pr, err := lookupProfile(opts.Profile)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type job struct {
+		index int
+		raw   string
+		out   chan Result
+	}
+	jobs := make(chan job)
+	// pending holds each line's result channel in line order, bounding the
+	// lines in flight.
+	pending := make(chan chan Result, 2*opts.workers())
+	var readErr error
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 0, 1024), maxLineLength)
+		for i := 0; sc.Scan(); i++ {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" {
+				continue
+			}
+			out := make(chan Result, 1)
+			select {
+			case pending <- out:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job{i, line, out}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := sc.Err(); err != nil {
+			readErr = fmt.Errorf("batch: reading input: %w", err)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range opts.workers() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.out <- decodeOne(ctx, j.index, j.raw, opts.Decode, pr)
+			}
+		}()
+	}
+
+	for out := range pending {
+		select {
+		case res := <-out:
+			if err := fn(res); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	wg.Wait()
+	if readErr != nil {
+		return readErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,99 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func streamInput(t *testing.T, n int) (string, string) {
+	t.Helper()
+	base := bharatBase()
+	base.MerchantName = "Sharma Chai Stall"
+	base.MerchantCategoryCode = "5812"
+	base.MerchantIdentifiers = []emvqr.MerchantIdentifier{{ID: "02", Value: "4000123456789012"}}
+	good, err := emvqr.Encode(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	for i := range n {
+		switch i % 10 {
+		case 3:
+			sb.WriteString("not a qr\r\n")
+		case 7:
+			sb.WriteString("\n")
+		default:
+			sb.WriteString(good + "\n")
+		}
+	}
+	return sb.String(), good
+}
+
+func TestDecodeStream(t *testing.T) {
+	input, good := streamInput(t, 1000)
+	var got []Result
+	err := DecodeStream(context.Background(), strings.NewReader(input), DecodeOptions{Workers: 8}, func(r Result) error {
+		got = append(got, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 900 {
+		t.Fatalf("got %d results, want 900", len(got))
+	}
+	last := -1
+	for _, r := range got {
+		if r.Index <= last {
+			t.Fatalf("result for line %d delivered after line %d", r.Index, last)
+		}
+		last = r.Index
+		switch r.Index % 10 {
+		case 3:
+			if r.Err == nil || r.Input != "not a qr" {
+				t.Errorf("line %d: %+v", r.Index, r)
+			}
+		default:
+			if r.Err != nil || r.Input != good {
+				t.Errorf("line %d: %v", r.Index, r.Err)
+			}
+		}
+	}
+}
+
+func TestDecodeStream_Stop(t *testing.T) {
+	input, _ := streamInput(t, 1000)
+	stop := errors.New("stop")
+	n := 0
+	err := DecodeStream(context.Background(), strings.NewReader(input), DecodeOptions{}, func(r Result) error {
+		if n++; n == 5 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || n != 5 {
+		t.Errorf("err = %v after %d results", err, n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n = 0
+	err = DecodeStream(ctx, strings.NewReader(input), DecodeOptions{}, func(r Result) error {
+		if n++; n == 5 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+
+	err = DecodeStream(context.Background(), strings.NewReader(strings.Repeat("x", maxLineLength+1)), DecodeOptions{},
+		func(Result) error { return nil })
+	if err == nil {
+		t.Error("overlong line accepted")
+	}
+}
@@ -0,0 +1,195 @@
+package batch
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/render"
+)
+
+// Image formats of WriteZIP.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// ManifestName is the name of the manifest in the archives WriteZIP writes.
+const ManifestName = "manifest.csv"
+
+// manifestHeader is the header row of the manifest.
+var manifestHeader = []string{"line", "id", "file", "store_label", "merchant_name", "fingerprint", "payload", "error"}
+
+// ZIPOptions controls WriteZIP.
+type ZIPOptions struct {
+	// Format is the image format, FormatPNG (the default) or FormatSVG.
+	Format string
+
+	// Render controls the symbols, e.g. their module size, error correction
+	// level and scheme strip.
+	Render render.Options
+
+	// Captions prints each merchant's name below its symbol, overriding
+	// Render.Caption.
+	Captions bool
+}
+
+// WriteZIP writes rows to w as one ZIP archive, the single artifact a
+// printing bureau receives for a merchant batch: an image per row, and a
+// manifest, ManifestName, listing every row with its line, ID, file, Store
+// Label (Tag 62.03), merchant name, fingerprint, payload and error. Rows
+// may come from Encode, or wrap payloads built otherwise as Row{Payload: p};
+// a row without Raw is encoded with emvqr.Encode.
+//
+// Each image is named after the row's ID, or else its Store Label, or else
+// the first 16 hex digits of its fingerprint, with characters other than
+// letters, digits, '.', '-' and '_' replaced by '_'; a name already taken
+// gets a suffix such as "-2". Rows with an Err, or that fail to encode or
+// render, get no image; the manifest records why, and they are counted in
+// the number returned.
+//
+// The archive is streamed, so w need not be seekable, e.g. an HTTP
+// response. WriteZIP stops with ctx's error once ctx is done; the archive
+// is then incomplete.
+func WriteZIP(ctx context.Context, w io.Writer, rows []Row, opts ZIPOptions) (failed int, err error) {
+	ext := opts.Format
+	switch ext {
+	case "":
+		ext = FormatPNG
+	case FormatPNG, FormatSVG:
+	default:
+		return 0, fmt.Errorf("batch: unknown image format %q (want %s or %s)", opts.Format, FormatPNG, FormatSVG)
+	}
+	zw := zip.NewWriter(w)
+	var manifest strings.Builder
+	mw := csv.NewWriter(&manifest)
+	mw.Write(manifestHeader)
+	used := make(map[string]bool)
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return failed, err
+		}
+		entry, rerr := writeImage(ctx, zw, row, ext, used, opts)
+		if rerr != nil {
+			if werr, ok := rerr.(*zipWriteError); ok {
+				return failed, werr.err
+			}
+			failed++
+			entry.err = rerr.Error()
+		}
+		mw.Write([]string{strconv.Itoa(row.Line), row.ID, entry.file, entry.storeLabel,
+			entry.merchantName, entry.fingerprint, entry.raw, entry.err})
+	}
+	mw.Flush()
+	f, err := zw.Create(ManifestName)
+	if err != nil {
+		return failed, err
+	}
+	if _, err := io.WriteString(f, manifest.String()); err != nil {
+		return failed, err
+	}
+	return failed, zw.Close()
+}
+
+// manifestEntry is a row of the manifest.
+type manifestEntry struct {
+	file, storeLabel, merchantName, fingerprint, raw, err string
+}
+
+// zipWriteError marks a failure to write the archive itself, which ends
+// WriteZIP, as opposed to a row that cannot be rendered.
+type zipWriteError struct{ err error }
+
+func (e *zipWriteError) Error() string { return e.err.Error() }
+
+// writeImage renders row into zw and returns its manifest entry. Names in
+// used are taken; the name chosen is added.
+func writeImage(ctx context.Context, zw *zip.Writer, row Row, ext string, used map[string]bool, opts ZIPOptions) (manifestEntry, error) {
+	var entry manifestEntry
+	p := row.Payload
+	if p != nil {
+		entry.merchantName = p.MerchantName
+		entry.fingerprint = p.Fingerprint()
+		if p.AdditionalData != nil {
+			entry.storeLabel = p.AdditionalData.StoreLabel
+		}
+	}
+	entry.raw = row.Raw
+	switch {
+	case row.Err != nil:
+		return entry, row.Err
+	case p == nil:
+		return entry, fmt.Errorf("batch: line %d has no payload", row.Line)
+	case entry.raw == "":
+		raw, err := emvqr.Encode(p)
+		if err != nil {
+			return entry, err
+		}
+		entry.raw = raw
+	}
+	ropts := opts.Render
+	if opts.Captions {
+		ropts.Caption = p.MerchantName
+	}
+	s, err := render.NewContext(ctx, entry.raw, ropts)
+	if err != nil {
+		return entry, err
+	}
+	name := imageName(row.ID, entry.storeLabel, entry.fingerprint, used) + "." + ext
+	f, err := zw.Create(name)
+	if err != nil {
+		return entry, &zipWriteError{err}
+	}
+	if ext == FormatSVG {
+		err = s.WriteSVG(f)
+	} else {
+		err = s.WritePNG(f)
+	}
+	if err != nil {
+		return entry, &zipWriteError{err}
+	}
+	entry.file = name
+	return entry, nil
+}
+
+// imageName returns the first of id, storeLabel and the first 16 hex
+// digits of fingerprint that is not empty once made safe as a file name,
+// suffixed to differ from the names in used, and adds it to used.
+func imageName(id, storeLabel, fingerprint string, used map[string]bool) string {
+	name := ""
+	for _, s := range []string{id, storeLabel, fingerprint[:min(16, len(fingerprint))]} {
+		if name = safeFileName(s); name != "" {
+			break
+		}
+	}
+	if name == "" {
+		name = "payload"
+	}
+	unique := name
+	for n := 2; used[unique]; n++ {
+		unique = name + "-" + strconv.Itoa(n)
+	}
+	used[unique] = true
+	return unique
+}
+
+// safeFileName returns s with characters other than ASCII letters, digits,
+// '.', '-' and '_' replaced by '_', or "" if nothing but dots and
+// underscores would remain.
+func safeFileName(s string) string {
+	b := []byte(strings.TrimSpace(s))
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+			b[i] = '_'
+		}
+	}
+	if strings.Trim(string(b), "._") == "" {
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,120 @@
+package batch
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteZIP(t *testing.T) {
+	const input = `id,name,mcc,vpa,postal,62.03
+M1,Sharma Stores,5411,sharma@upi,400001,S1
+,Gupta Sweets,5441,gupta@upi,411001,Pune/Camp
+,Gupta Sweets,5441,gupta@upi,411001,Pune/Camp
+M4,Missing Postal,5411,x@upi,,
+`
+	rows, err := Encode(strings.NewReader(input), Options{Base: bharatBase(), Profile: "bharatqr"})
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	extra := bharatBase()
+	extra.MerchantName, extra.MerchantCategoryCode = "Kiosk", "5499"
+	_ = extra.AddMerchantIdentifier("02", "4000123456789012")
+	rows = append(rows, Row{Payload: extra})
+
+	var buf bytes.Buffer
+	failed, err := WriteZIP(context.Background(), &buf, rows, ZIPOptions{Captions: true})
+	if err != nil {
+		t.Fatalf("WriteZIP() error: %v", err)
+	}
+	if failed != 1 {
+		t.Errorf("WriteZIP() failed = %d, want 1", failed)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error: %v", err)
+	}
+	var names []string
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		files[f.Name] = f
+	}
+	fp := extra.Fingerprint()[:16]
+	want := "M1.png Pune_Camp.png Pune_Camp-2.png " + fp + ".png " + ManifestName
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("archive files = %q, want %q", got, want)
+	}
+	if png := readZIPFile(t, files["M1.png"]); !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Error("M1.png is not a PNG image")
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(readZIPFile(t, files[ManifestName]))).ReadAll()
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	if len(records) != 6 || strings.Join(records[0], ",") != strings.Join(manifestHeader, ",") {
+		t.Fatalf("manifest = %q", records)
+	}
+	assertRecord := func(i int, file, storeLabel, name string) {
+		t.Helper()
+		r := records[i]
+		if r[2] != file || r[3] != storeLabel || r[4] != name {
+			t.Errorf("manifest row %d = %q, want file %q, store label %q, name %q", i, r, file, storeLabel, name)
+		}
+	}
+	assertRecord(1, "M1.png", "S1", "Sharma Stores")
+	assertRecord(2, "Pune_Camp.png", "Pune/Camp", "Gupta Sweets")
+	assertRecord(5, fp+".png", "", "Kiosk")
+	if r := records[4]; r[1] != "M4" || r[2] != "" || !strings.Contains(r[7], "tag 61") {
+		t.Errorf("manifest row of the failed row = %q", r)
+	}
+	if records[1][6] != rows[0].Raw || records[5][6] == "" {
+		t.Errorf("manifest payloads = %q, %q", records[1][6], records[5][6])
+	}
+}
+
+func TestWriteZIP_SVG(t *testing.T) {
+	rows, _ := Encode(strings.NewReader("id,name,mcc,vpa,postal\nM1,Sharma Stores,5411,sharma@upi,400001\n"),
+		Options{Base: bharatBase()})
+	var buf bytes.Buffer
+	if _, err := WriteZIP(context.Background(), &buf, rows, ZIPOptions{Format: FormatSVG}); err != nil {
+		t.Fatalf("WriteZIP() error: %v", err)
+	}
+	zr, _ := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if len(zr.File) != 2 || zr.File[0].Name != "M1.svg" || !bytes.Contains(readZIPFile(t, zr.File[0]), []byte("<svg")) {
+		t.Errorf("archive = %v, want M1.svg and the manifest", zr.File)
+	}
+
+	if _, err := WriteZIP(context.Background(), io.Discard, rows, ZIPOptions{Format: "gif"}); err == nil {
+		t.Error("WriteZIP() with an unknown format expected error, got nil")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := WriteZIP(ctx, io.Discard, rows, ZIPOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("WriteZIP() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func readZIPFile(t *testing.T, f *zip.File) []byte {
+	t.Helper()
+	if f == nil {
+		t.Fatal("file missing from archive")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		t.Fatalf("opening %s: %v", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading %s: %v", f.Name, err)
+	}
+	return data
+}
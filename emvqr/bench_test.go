@@ -0,0 +1,128 @@
+package emvqr
+
+import "testing"
+
+// budget is the performance budget of the hot path: the most allocations
+// each operation may make per payload. TestAllocationBudget enforces it on
+// every test run, and BenchmarkBudget reports timings for the same cases:
+//
+//	go test -run '^$' -bench Budget -benchmem ./emvqr
+//
+// Raise a limit only with a justification in the commit message.
+var budget = []struct {
+	name   string
+	allocs float64
+	// setup returns the operation to measure.
+	setup func(tb testing.TB) func() error
+}{
+	{"Encode/Base", 2, encodeOp(func(testing.TB) *Payload { return basePayload() })},
+	{"Encode/Hitachi", 2, encodeOp(hitachiPayload)},
+	{"Decode/Base", 2, decodeOp(func(tb testing.TB) string { return mustEncode(tb, basePayload()) })},
+	{"Decode/Hitachi", 9, decodeOp(func(testing.TB) string { return realWorldBharatQRPayload })},
+	{"RoundTrip/Hitachi", 11, func(tb testing.TB) func() error {
+		return func() error {
+			p, err := Decode(realWorldBharatQRPayload)
+			if err != nil {
+				return err
+			}
+			_, err = Encode(p)
+			return err
+		}
+	}},
+	{"Encoder.Encode", 1, func(tb testing.TB) func() error {
+		p, enc := hitachiPayload(tb), NewEncoder(EncodeOptions{})
+		return func() error {
+			_, err := enc.Encode(p)
+			return err
+		}
+	}},
+	{"Encoder.AppendEncode", 0, func(tb testing.TB) func() error {
+		p, enc := hitachiPayload(tb), NewEncoder(EncodeOptions{})
+		dst := make([]byte, 0, 512)
+		return func() error {
+			_, err := enc.AppendEncode(dst[:0], p)
+			return err
+		}
+	}},
+	{"Decoder.Decode", 9, func(tb testing.TB) func() error {
+		dec := NewDecoder(DecodeOptions{})
+		return func() error {
+			_, err := dec.Decode(realWorldBharatQRPayload)
+			return err
+		}
+	}},
+	{"ValidateCRC", 0, func(tb testing.TB) func() error {
+		return func() error { return ValidateCRC(realWorldBharatQRPayload) }
+	}},
+	{"ComputeCRC", 1, func(tb testing.TB) func() error {
+		data := realWorldBharatQRPayload[:len(realWorldBharatQRPayload)-4]
+		return func() error {
+			_ = ComputeCRC(data)
+			return nil
+		}
+	}},
+}
+
+func TestAllocationBudget(t *testing.T) {
+	for _, c := range budget {
+		t.Run(c.name, func(t *testing.T) {
+			op := c.setup(t)
+			got := testing.AllocsPerRun(100, func() {
+				if err := op(); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if got > c.allocs {
+				t.Errorf("%.0f allocations per run, budget is %.0f", got, c.allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkBudget(b *testing.B) {
+	for _, c := range budget {
+		b.Run(c.name, func(b *testing.B) {
+			op := c.setup(b)
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := op(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCRC16(b *testing.B) {
+	data := []byte(realWorldBharatQRPayload)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		crc16CCITT(data)
+	}
+}
+
+func encodeOp(payload func(testing.TB) *Payload) func(testing.TB) func() error {
+	return func(tb testing.TB) func() error {
+		p := payload(tb)
+		return func() error {
+			_, err := Encode(p)
+			return err
+		}
+	}
+}
+
+func decodeOp(raw func(testing.TB) string) func(testing.TB) func() error {
+	return func(tb testing.TB) func() error {
+		s := raw(tb)
+		return func() error {
+			_, err := Decode(s)
+			return err
+		}
+	}
+}
+
+// hitachiPayload decodes the real-world Bharat QR sample.
+func hitachiPayload(tb testing.TB) *Payload {
+	return mustDecode(tb, realWorldBharatQRPayload)
+}
@@ -0,0 +1,333 @@
+package emvqr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// -------------------------------------------------------------------------
+// BER-TLV codec
+//
+// EMV Consumer-Presented Mode and several proprietary templates use the
+// binary BER-TLV encoding from ISO/IEC 8825-1 (EMV Book 3, Annex B) rather
+// than the fixed "2-digit ID + 2-digit length" format of Merchant-Presented
+// Mode. The helpers below parse and build BER-TLV trees and convert between
+// them and the MPM tree returned by ParseTLVTree.
+// -------------------------------------------------------------------------
+
+// maxBERDepth bounds the nesting of constructed objects accepted by ParseBER.
+const maxBERDepth = 16
+
+// BERTag is a BER-TLV tag of one to four bytes stored big-endian, e.g.
+// 0x4F (AID), 0x61 (Application Template) or 0x9F26 (Application Cryptogram).
+type BERTag uint32
+
+// Bytes returns the encoded tag bytes.
+func (t BERTag) Bytes() []byte {
+	switch {
+	case t > 0xFFFFFF:
+		return []byte{byte(t >> 24), byte(t >> 16), byte(t >> 8), byte(t)}
+	case t > 0xFFFF:
+		return []byte{byte(t >> 16), byte(t >> 8), byte(t)}
+	case t > 0xFF:
+		return []byte{byte(t >> 8), byte(t)}
+	default:
+		return []byte{byte(t)}
+	}
+}
+
+// IsConstructed reports whether the tag denotes a constructed object, i.e.
+// bit 6 of the first tag byte is set.
+func (t BERTag) IsConstructed() bool {
+	return t.Bytes()[0]&0x20 != 0
+}
+
+// String returns the tag as upper-case hex, e.g. "9F26".
+func (t BERTag) String() string {
+	return fmt.Sprintf("%X", t.Bytes())
+}
+
+// BERObject is a single BER-TLV data object. For constructed objects Children
+// holds the nested objects; Value always holds the raw content bytes.
+type BERObject struct {
+	Tag      BERTag
+	Value    []byte
+	Children []BERObject
+}
+
+// ParseBER parses a sequence of BER-TLV data objects, recursing into
+// constructed objects. Padding bytes ('00') between objects are skipped as
+// permitted by EMV Book 3, Annex B. Indefinite lengths are rejected.
+func ParseBER(data []byte) ([]BERObject, error) {
+	return parseBER(data, 0)
+}
+
+func parseBER(data []byte, depth int) ([]BERObject, error) {
+	if depth > maxBERDepth {
+		return nil, fmt.Errorf("%w: BER nesting exceeds %d levels", ErrInvalidTLV, maxBERDepth)
+	}
+	var objects []BERObject
+	for len(data) > 0 {
+		if data[0] == 0x00 {
+			data = data[1:]
+			continue
+		}
+		tag, n, err := readBERTag(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+		length, n, err := readBERLength(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w (tag %s)", err, tag)
+		}
+		data = data[n:]
+		if length > len(data) {
+			return nil, fmt.Errorf("%w: declared length %d for tag %s exceeds remaining data (%d bytes)", ErrInvalidTLV, length, tag, len(data))
+		}
+		obj := BERObject{Tag: tag, Value: data[:length]}
+		if tag.IsConstructed() {
+			children, err := parseBER(obj.Value, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			obj.Children = children
+		}
+		objects = append(objects, obj)
+		data = data[length:]
+	}
+	return objects, nil
+}
+
+// readBERTag reads a tag from the start of data and returns it together with
+// the number of bytes consumed.
+func readBERTag(data []byte) (BERTag, int, error) {
+	tag := BERTag(data[0])
+	if data[0]&0x1F != 0x1F {
+		return tag, 1, nil
+	}
+	for i := 1; ; i++ {
+		if i >= len(data) {
+			return 0, 0, fmt.Errorf("%w: truncated multi-byte tag", ErrInvalidTLV)
+		}
+		if i > 3 {
+			return 0, 0, fmt.Errorf("%w: tag longer than 4 bytes", ErrInvalidTLV)
+		}
+		tag = tag<<8 | BERTag(data[i])
+		if data[i]&0x80 == 0 {
+			return tag, i + 1, nil
+		}
+	}
+}
+
+// readBERLength reads a short- or long-form length from the start of data and
+// returns it together with the number of bytes consumed.
+func readBERLength(data []byte) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, fmt.Errorf("%w: missing length", ErrInvalidTLV)
+	}
+	b := data[0]
+	if b < 0x80 {
+		return int(b), 1, nil
+	}
+	if b == 0x80 {
+		return 0, 0, fmt.Errorf("%w: indefinite length is not allowed", ErrInvalidTLV)
+	}
+	n := int(b & 0x7F)
+	if n > 3 {
+		return 0, 0, fmt.Errorf("%w: length field of %d bytes is not supported", ErrInvalidTLV, n)
+	}
+	if len(data) < 1+n {
+		return 0, 0, fmt.Errorf("%w: truncated length field", ErrInvalidTLV)
+	}
+	length := 0
+	for _, lb := range data[1 : 1+n] {
+		length = length<<8 | int(lb)
+	}
+	return length, 1 + n, nil
+}
+
+// EncodeBER serialises a sequence of BER-TLV objects. Constructed objects with
+// Children are encoded from their children; otherwise Value is written as-is.
+// Lengths use the shortest definite form.
+func EncodeBER(objects []BERObject) ([]byte, error) {
+	var out []byte
+	for _, obj := range objects {
+		if obj.Tag == 0 {
+			return nil, fmt.Errorf("%w: zero BER tag", ErrInvalidTLV)
+		}
+		value := obj.Value
+		if obj.Tag.IsConstructed() && len(obj.Children) > 0 {
+			inner, err := EncodeBER(obj.Children)
+			if err != nil {
+				return nil, err
+			}
+			value = inner
+		}
+		out = append(out, obj.Tag.Bytes()...)
+		out = appendBERLength(out, len(value))
+		out = append(out, value...)
+	}
+	return out, nil
+}
+
+// appendBERLength appends the shortest definite-form encoding of n.
+func appendBERLength(out []byte, n int) []byte {
+	switch {
+	case n < 0x80:
+		return append(out, byte(n))
+	case n <= 0xFF:
+		return append(out, 0x81, byte(n))
+	case n <= 0xFFFF:
+		return append(out, 0x82, byte(n>>8), byte(n))
+	default:
+		return append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// -------------------------------------------------------------------------
+// MPM TLV tree and conversion helpers
+// -------------------------------------------------------------------------
+
+// TLVNode is one data object of a Merchant-Presented Mode payload viewed as a
+// tree. Template objects (IDs "26"–"51", "62", "64", "80"–"99") carry their
+// parsed sub-fields in Children; primitive objects carry Value only.
+type TLVNode struct {
+	ID       string
+	Value    string
+	Children []TLVNode
+}
+
+// IsTemplate reports whether the node was parsed as a template.
+func (n TLVNode) IsTemplate() bool {
+	return n.Children != nil
+}
+
+// ParseTLVTree parses a raw MPM string into a tree, descending one level into
+// every template object. No CRC validation is performed.
+func ParseTLVTree(raw string) ([]TLVNode, error) {
+	objects, err := parseTLV(raw)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]TLVNode, 0, len(objects))
+	for _, obj := range objects {
+		node := TLVNode{ID: obj.id, Value: obj.value}
+		if isTemplateID(obj.id) {
+			subs, err := parseTLV(obj.value)
+			if err != nil {
+				return nil, &ParseError{ID: obj.id, Err: err}
+			}
+			node.Children = make([]TLVNode, 0, len(subs))
+			for _, s := range subs {
+				node.Children = append(node.Children, TLVNode{ID: s.id, Value: s.value})
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// EncodeTLVTree serialises an MPM tree. Templates are encoded from their
+// Children; primitives from Value. No CRC is appended.
+func EncodeTLVTree(nodes []TLVNode) (string, error) {
+	var out []byte
+	for _, n := range nodes {
+		value := n.Value
+		if n.IsTemplate() {
+			inner, err := EncodeTLVTree(n.Children)
+			if err != nil {
+				return "", err
+			}
+			value = inner
+		}
+		chunk, err := encodeTLV(n.ID, value)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, chunk...)
+	}
+	return string(out), nil
+}
+
+// isTemplateID reports whether an MPM top-level ID denotes a template.
+func isTemplateID(id string) bool {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return false
+	}
+	return (n >= 26 && n <= 51) || n == 62 || n == 64 || (n >= 80 && n <= 99)
+}
+
+// TLVTreeToBER converts an MPM tree into BER-TLV objects. Each two-digit ID n
+// becomes a context-specific tag with tag number n (single byte below 31,
+// two-byte high-tag-number form otherwise); templates become constructed tags.
+func TLVTreeToBER(nodes []TLVNode) ([]BERObject, error) {
+	objects := make([]BERObject, 0, len(nodes))
+	for _, n := range nodes {
+		num, err := strconv.Atoi(n.ID)
+		if err != nil || num < 0 || num > 99 {
+			return nil, fmt.Errorf("%w: ID %q cannot be mapped to a BER tag", ErrInvalidTLV, n.ID)
+		}
+		class := BERTag(0x80)
+		if n.IsTemplate() {
+			class |= 0x20
+		}
+		var tag BERTag
+		if num < 31 {
+			tag = class | BERTag(num)
+		} else {
+			tag = (class|0x1F)<<8 | BERTag(num)
+		}
+		obj := BERObject{Tag: tag, Value: []byte(n.Value)}
+		if n.IsTemplate() {
+			children, err := TLVTreeToBER(n.Children)
+			if err != nil {
+				return nil, err
+			}
+			obj.Children = children
+			if obj.Value, err = EncodeBER(children); err != nil {
+				return nil, err
+			}
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// BERToTLVTree is the inverse of TLVTreeToBER. Only context-specific tags with
+// tag numbers 0–99 can be represented in the MPM format.
+func BERToTLVTree(objects []BERObject) ([]TLVNode, error) {
+	nodes := make([]TLVNode, 0, len(objects))
+	for _, obj := range objects {
+		b := obj.Tag.Bytes()
+		if b[0]&0xC0 != 0x80 {
+			return nil, fmt.Errorf("%w: BER tag %s is not context-specific", ErrInvalidTLV, obj.Tag)
+		}
+		num := int(b[0] & 0x1F)
+		if num == 0x1F {
+			if len(b) != 2 || b[1]&0x80 != 0 {
+				return nil, fmt.Errorf("%w: BER tag %s has no MPM equivalent", ErrInvalidTLV, obj.Tag)
+			}
+			num = int(b[1])
+		}
+		if num > 99 {
+			return nil, fmt.Errorf("%w: BER tag %s has no MPM equivalent", ErrInvalidTLV, obj.Tag)
+		}
+		node := TLVNode{ID: fmt.Sprintf("%02d", num)}
+		if obj.Tag.IsConstructed() {
+			children, err := BERToTLVTree(obj.Children)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = children
+			if node.Value, err = EncodeTLVTree(children); err != nil {
+				return nil, err
+			}
+		} else {
+			node.Value = string(obj.Value)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
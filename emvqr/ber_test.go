@@ -0,0 +1,122 @@
+package emvqr
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// -------------------------------------------------------------------------
+// BER-TLV Tests
+// -------------------------------------------------------------------------
+
+func TestParseBER_ConstructedTemplate(t *testing.T) {
+	// 61 (Application Template) containing 4F (AID) and 9F26 (two-byte tag).
+	data, _ := hex.DecodeString("610E4F07A00000000310109F26020102")
+	objs, err := ParseBER(data)
+	if err != nil {
+		t.Fatalf("ParseBER error: %v", err)
+	}
+	if len(objs) != 1 {
+		t.Fatalf("expected 1 object, got %d", len(objs))
+	}
+	tmpl := objs[0]
+	if !tmpl.Tag.IsConstructed() {
+		t.Error("expected tag 61 to be constructed")
+	}
+	if len(tmpl.Children) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(tmpl.Children))
+	}
+	assertEqual(t, "child[0].Tag", "4F", tmpl.Children[0].Tag.String())
+	assertEqual(t, "child[0].Value", "a0000000031010", hex.EncodeToString(tmpl.Children[0].Value))
+	assertEqual(t, "child[1].Tag", "9F26", tmpl.Children[1].Tag.String())
+}
+
+func TestParseBER_LongFormLength(t *testing.T) {
+	value := bytes.Repeat([]byte{0xAB}, 200)
+	data := append([]byte{0x5A, 0x81, 0xC8}, value...)
+	objs, err := ParseBER(data)
+	if err != nil {
+		t.Fatalf("ParseBER error: %v", err)
+	}
+	if len(objs[0].Value) != 200 {
+		t.Errorf("Value length = %d, want 200", len(objs[0].Value))
+	}
+}
+
+func TestParseBER_Errors(t *testing.T) {
+	cases := map[string]string{
+		"Truncated":       "5A0512",
+		"Indefinite":      "61800000",
+		"TruncatedTag":    "9F",
+		"MissingLength":   "5A",
+		"TruncatedLength": "5A82",
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			data, _ := hex.DecodeString(in)
+			if _, err := ParseBER(data); !errors.Is(err, ErrInvalidTLV) {
+				t.Errorf("expected ErrInvalidTLV, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEncodeBER_RoundTrip(t *testing.T) {
+	in, _ := hex.DecodeString("00610E4F07A00000000310109F2602010200")
+	objs, err := ParseBER(in)
+	if err != nil {
+		t.Fatalf("ParseBER error: %v", err)
+	}
+	out, err := EncodeBER(objs)
+	if err != nil {
+		t.Fatalf("EncodeBER error: %v", err)
+	}
+	// Padding bytes are not reproduced.
+	assertEqual(t, "encoded", "610e4f07a00000000310109f26020102", hex.EncodeToString(out))
+}
+
+func TestTLVTree_BERConversionRoundTrip(t *testing.T) {
+	encoded, err := Encode(NPCIBhartQRBasePayload())
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	tree, err := ParseTLVTree(encoded)
+	if err != nil {
+		t.Fatalf("ParseTLVTree error: %v", err)
+	}
+	ber, err := TLVTreeToBER(tree)
+	if err != nil {
+		t.Fatalf("TLVTreeToBER error: %v", err)
+	}
+	back, err := BERToTLVTree(ber)
+	if err != nil {
+		t.Fatalf("BERToTLVTree error: %v", err)
+	}
+	raw, err := EncodeTLVTree(back)
+	if err != nil {
+		t.Fatalf("EncodeTLVTree error: %v", err)
+	}
+	assertEqual(t, "round trip", encoded, raw)
+}
+
+func TestTLVTreeToBER_TagMapping(t *testing.T) {
+	objs, err := TLVTreeToBER([]TLVNode{
+		{ID: "02", Value: "4000"},
+		{ID: "59", Value: "ABC"},
+		{ID: "26", Children: []TLVNode{{ID: "00", Value: "A000000524"}}},
+	})
+	if err != nil {
+		t.Fatalf("TLVTreeToBER error: %v", err)
+	}
+	assertEqual(t, "ID 02", "82", objs[0].Tag.String())
+	assertEqual(t, "ID 59", "9F3B", objs[1].Tag.String())
+	assertEqual(t, "ID 26", "BA", objs[2].Tag.String())
+}
+
+func TestBERToTLVTree_UnsupportedTag(t *testing.T) {
+	if _, err := BERToTLVTree([]BERObject{{Tag: 0x4F}}); err == nil {
+		t.Fatal("expected error for application-class tag, got nil")
+	}
+}
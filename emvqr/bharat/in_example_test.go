@@ -367,9 +367,9 @@ func ExamplePayload_SetPointOfInitiationMethod() {
 	raw, _ := emvqr.Encode(p)
 	decoded, _ := emvqr.Decode(raw)
 
-	fmt.Println(decoded.PointOfInitiationMethod)
+	fmt.Println(string(decoded.PointOfInitiationMethod), decoded.PointOfInitiationMethod)
 	// Output:
-	// 12
+	// 12 dynamic QR
 }
 
 // ---------------------------------------------------------------------------
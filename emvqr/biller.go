@@ -0,0 +1,123 @@
+package emvqr
+
+import "fmt"
+
+// BillerGUID is the Globally Unique Identifier of the Unreserved Template
+// in which SetBiller stores the biller a QR pays.
+const BillerGUID = "COM.GITHUB.EMVQR.BILLER"
+
+// Sub-field IDs of the biller template.
+const (
+	BillerID       = "01" // BBPS-style biller ID, e.g. "BESCOM000KAR01"
+	BillerCategory = "02" // e.g. "Electricity"
+)
+
+// Biller identifies a government or utility biller, such as an
+// electricity board, in the Bharat Bill Payment System style.
+type Biller struct {
+	ID       string // 14 upper-case letters and digits
+	Category string // optional, e.g. "Electricity" or "Water"
+}
+
+// ValidateBillerID checks that id is a BBPS-style biller ID: 14 upper-case
+// letters and digits, such as "BESCOM000KAR01". It returns an error
+// wrapping ErrInvalidFormat.
+func ValidateBillerID(id string) error {
+	ok := len(id) == 14
+	for i := 0; ok && i < len(id); i++ {
+		c := id[i]
+		ok = c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+	}
+	if !ok {
+		return fmt.Errorf("%w: biller ID %q must be 14 upper-case letters and digits", ErrInvalidFormat, id)
+	}
+	return nil
+}
+
+// SetBiller stores b in an Unreserved Template with BillerGUID, replacing
+// any earlier biller. It returns an error wrapping ErrInvalidFormat, and
+// leaves the payload unchanged, if the ID fails ValidateBillerID or the
+// category does not fit in the template.
+func (p *Payload) SetBiller(b Biller) error {
+	if err := ValidateBillerID(b.ID); err != nil {
+		return err
+	}
+	subs := []DataObject{{ID: BillerID, Value: b.ID}}
+	if b.Category != "" {
+		subs = append(subs, DataObject{ID: BillerCategory, Value: b.Category})
+	}
+	return p.setExtension(BillerGUID, subs)
+}
+
+// GetBiller returns the biller stored by SetBiller, or nil if the payload
+// has none.
+func (p *Payload) GetBiller() *Biller {
+	i := p.extensionIndex(BillerGUID)
+	if i < 0 {
+		return nil
+	}
+	b := &Biller{}
+	for _, sf := range p.UnreservedTemplates[i].SubFields {
+		switch sf.ID {
+		case BillerID:
+			b.ID = sf.Value
+		case BillerCategory:
+			b.Category = sf.Value
+		}
+	}
+	return b
+}
+
+// NewBillerPayload returns a static bill-fetch QR for the biller b, built
+// from base, which holds the biller's merchant account, name, MCC and
+// location; base is not modified. The QR carries no amount, since the
+// consumer's app fetches the bill due, and prompts the consumer for the
+// Reference Label (Tag 62.05), their consumer or account number with the
+// biller. The result passes ValidateBillFetch, or an error is returned.
+func NewBillerPayload(base *Payload, b Biller) (*Payload, error) {
+	p, err := Merge(base, &Payload{})
+	if err != nil {
+		return nil, err
+	}
+	p.PointOfInitiationMethod = POIStaticQR
+	p.TransactionAmount = ""
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.ReferenceLabel = PromptValue })
+	if err := p.SetBiller(b); err != nil {
+		return nil, err
+	}
+	if err := ValidateBillFetch(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ValidateBillFetch checks the rules of a bill-fetch QR, in which the
+// consumer's app looks up the amount due from the biller: the payload must
+// name a biller (see SetBiller), be static (Tag 01 "11"), carry no
+// Transaction Amount (Tag 54) and prompt for the Reference Label (Tag
+// 62.05 "***"). It returns every violation, joined with errors.Join, each
+// wrapping ErrSchemeViolation with its tag in ErrorParams; nil if there
+// are none.
+func ValidateBillFetch(p *Payload) error {
+	var errs []error
+	fail := func(tag, format string, args ...any) {
+		errs = append(errs, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: bill fetch: "+format, append([]any{ErrSchemeViolation}, args...)...), "tag", tag))
+	}
+	if b := p.GetBiller(); b == nil {
+		fail(BillerGUID, "no biller template %s", BillerGUID)
+	} else if err := ValidateBillerID(b.ID); err != nil {
+		fail(BillerGUID, "biller ID %q must be 14 upper-case letters and digits", b.ID)
+	}
+	if p.PointOfInitiationMethod != POIStaticQR {
+		fail(IDPointOfInitiationMethod, "Tag 01 must be %q (static QR), got %q",
+			string(POIStaticQR), string(p.PointOfInitiationMethod))
+	}
+	if p.TransactionAmount != "" {
+		fail(IDTransactionAmount, "Tag 54 amount must be absent, got %q", p.TransactionAmount)
+	}
+	if a := p.AdditionalData; a == nil || a.ReferenceLabel != PromptValue {
+		fail(IDAdditionalDataFieldTemplate+"."+ADFReferenceLabel, "Tag 62.05 Reference Label must be %q", PromptValue)
+	}
+	return joinErrors(errs)
+}
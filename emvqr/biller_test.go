@@ -0,0 +1,79 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewBillerPayload(t *testing.T) {
+	base := basePayload()
+	base.TransactionAmount = "120.00"
+	p, err := NewBillerPayload(base, Biller{ID: "BESCOM000KAR01", Category: "Electricity"})
+	if err != nil {
+		t.Fatalf("NewBillerPayload() error: %v", err)
+	}
+	assertEqual(t, "base amount", "120.00", base.TransactionAmount)
+	if base.GetBiller() != nil {
+		t.Error("NewBillerPayload() modified base")
+	}
+
+	raw := mustEncode(t, p)
+	if !strings.Contains(raw, "0023"+BillerGUID+"0114BESCOM000KAR01"+"0211Electricity") {
+		t.Errorf("Encode() = %q, want the biller template", raw)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if err := ValidateBillFetch(decoded); err != nil {
+		t.Errorf("ValidateBillFetch() error: %v", err)
+	}
+	b := decoded.GetBiller()
+	if b == nil {
+		t.Fatal("GetBiller() = nil")
+	}
+	assertEqual(t, "ID", "BESCOM000KAR01", b.ID)
+	assertEqual(t, "Category", "Electricity", b.Category)
+	assertEqual(t, "POI", string(POIStaticQR), string(decoded.PointOfInitiationMethod))
+	assertEqual(t, "ReferenceLabel", PromptValue, decoded.AdditionalData.ReferenceLabel)
+	assertEqual(t, "TransactionAmount", "", decoded.TransactionAmount)
+}
+
+func TestNewBillerPayload_InvalidID(t *testing.T) {
+	for _, id := range []string{"", "BESCOM000KAR1", "bescom000kar01", "BESCOM-00KAR01"} {
+		if _, err := NewBillerPayload(basePayload(), Biller{ID: id}); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("NewBillerPayload(%q) error = %v, want ErrInvalidFormat", id, err)
+		}
+	}
+}
+
+func TestValidateBillFetch(t *testing.T) {
+	p := basePayload()
+	p.PointOfInitiationMethod = POIDynamicQR
+	p.TransactionAmount = "10"
+	err := ValidateBillFetch(p)
+	if !errors.Is(err, ErrSchemeViolation) {
+		t.Fatalf("ValidateBillFetch() error = %v, want ErrSchemeViolation", err)
+	}
+	for _, tag := range []string{BillerGUID, IDPointOfInitiationMethod, IDTransactionAmount, "62.05"} {
+		if !hasErrorTag(err, tag) {
+			t.Errorf("ValidateBillFetch() error %v has no violation for %s", err, tag)
+		}
+	}
+}
+
+// hasErrorTag reports whether err, or an error it joins, has tag in its
+// ErrorParams.
+func hasErrorTag(err error, tag string) bool {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, e := range errs {
+		if ErrorParams(e)["tag"] == tag {
+			return true
+		}
+	}
+	return false
+}
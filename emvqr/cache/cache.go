@@ -0,0 +1,178 @@
+// Package cache keeps encoded payloads and rendered QR images in memory,
+// keyed by payload fingerprint, so that a checkout service serving the same
+// static QR on every request encodes and renders it only once.
+//
+// The cache holds at most Options.MaxEntries entries, evicting the least
+// recently used, and entries expire Options.TTL after they were stored:
+//
+//	c := cache.New(cache.Options{MaxEntries: 1024, TTL: time.Hour})
+//	png, err := c.Image(p, "png-10", func(w io.Writer, raw string) error {
+//	    return render.PNG(w, raw, render.Options{ModuleSize: 10})
+//	})
+//
+// A Cache is safe for concurrent use. Two goroutines that miss on the same
+// key at once may both do the work; the last to finish is kept.
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"sync"
+	"time"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// DefaultMaxEntries is the size bound used when Options.MaxEntries is zero.
+const DefaultMaxEntries = 1024
+
+// Options configures a Cache.
+type Options struct {
+	// MaxEntries bounds the number of entries, encoded payloads and images
+	// counted alike. Zero means DefaultMaxEntries.
+	MaxEntries int
+
+	// TTL is how long an entry is served after it was stored. Zero means
+	// entries never expire and are only evicted for space.
+	TTL time.Duration
+
+	// Now returns the current time; nil means time.Now. Tests set it to
+	// control expiry.
+	Now func() time.Time
+}
+
+// Cache is a size-bounded, expiring in-memory cache of encoded payloads and
+// rendered images.
+type Cache struct {
+	opts Options
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently used
+	hits    uint64
+	misses  uint64
+}
+
+// entry is a cached value; exactly one of qr and image is set.
+type entry struct {
+	key     string
+	qr      emvqr.EncodedQR
+	image   []byte
+	expires time.Time // zero if the entry does not expire
+}
+
+// Stats reports the use of a Cache.
+type Stats struct {
+	Entries int
+	Hits    uint64
+	Misses  uint64
+}
+
+// New returns an empty Cache.
+func New(opts Options) *Cache {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Cache{opts: opts, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// Encode returns p encoded with the default options, from the cache if a
+// payload with the same fingerprint was encoded before. Errors are not
+// cached.
+func (c *Cache) Encode(p *emvqr.Payload) (emvqr.EncodedQR, error) {
+	key := "qr:" + p.Fingerprint()
+	if e, ok := c.get(key); ok {
+		return e.qr, nil
+	}
+	q, err := emvqr.EncodeQR(p, emvqr.EncodeOptions{})
+	if err != nil {
+		return emvqr.EncodedQR{}, err
+	}
+	c.put(&entry{key: key, qr: q})
+	return q, nil
+}
+
+// Image returns the image render writes for p, from the cache if one was
+// rendered before for a payload with the same fingerprint and the same
+// variant. variant names the format and render options, e.g. "png-10" or
+// "svg-branded", since they too determine the image. render is given the
+// encoded payload, itself taken from the cache when possible. Errors are
+// not cached.
+//
+// The returned slice is shared with the cache and must not be modified.
+func (c *Cache) Image(p *emvqr.Payload, variant string, render func(w io.Writer, raw string) error) ([]byte, error) {
+	key := "image:" + variant + ":" + p.Fingerprint()
+	if e, ok := c.get(key); ok {
+		return e.image, nil
+	}
+	q, err := c.Encode(p)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := render(&buf, q.String()); err != nil {
+		return nil, err
+	}
+	c.put(&entry{key: key, image: buf.Bytes()})
+	return buf.Bytes(), nil
+}
+
+// Purge removes all entries.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.lru.Init()
+}
+
+// Stats returns the number of entries and the hits and misses so far.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{Entries: c.lru.Len(), Hits: c.hits, Misses: c.misses}
+}
+
+// get returns the live entry for key, marking it recently used.
+func (c *Cache) get(key string) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if ok {
+		e := el.Value.(*entry)
+		if e.expires.IsZero() || c.opts.Now().Before(e.expires) {
+			c.lru.MoveToFront(el)
+			c.hits++
+			return e, true
+		}
+		c.remove(el)
+	}
+	c.misses++
+	return nil, false
+}
+
+// put stores e, replacing any entry with its key and evicting the least
+// recently used entries beyond MaxEntries.
+func (c *Cache) put(e *entry) {
+	if c.opts.TTL > 0 {
+		e.expires = c.opts.Now().Add(c.opts.TTL)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[e.key]; ok {
+		c.remove(el)
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.opts.MaxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove deletes el; c.mu must be held.
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*entry).key)
+}
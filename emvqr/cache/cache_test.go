@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func testPayload(name string) *emvqr.Payload {
+	p := emvqr.NewPayload()
+	p.MerchantIdentifiers = []emvqr.MerchantIdentifier{{ID: "02", Value: "4111111111111111"}}
+	p.MerchantCategoryCode = "5411"
+	p.TransactionCurrency = "356"
+	p.CountryCode = "IN"
+	p.MerchantName = name
+	p.MerchantCity = "Mumbai"
+	return p
+}
+
+// countingRenderer writes the encoded payload and counts its calls.
+type countingRenderer struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (r *countingRenderer) render(w io.Writer, raw string) error {
+	r.mu.Lock()
+	r.calls++
+	r.mu.Unlock()
+	_, err := io.WriteString(w, "IMG:"+raw)
+	return err
+}
+
+func TestCache_Encode(t *testing.T) {
+	c := New(Options{})
+	p := testPayload("Sharma Stores")
+	want, err := emvqr.Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		q, err := c.Encode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.String() != want {
+			t.Fatalf("Encode() = %q, want %q", q.String(), want)
+		}
+	}
+	if s := c.Stats(); s.Entries != 1 || s.Hits != 2 || s.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 1 entry, 2 hits, 1 miss", s)
+	}
+
+	bad := testPayload("")
+	if _, err := c.Encode(bad); !errors.Is(err, emvqr.ErrMissingRequired) {
+		t.Errorf("Encode(invalid) error = %v", err)
+	}
+	if s := c.Stats(); s.Entries != 1 {
+		t.Errorf("errors are cached: %+v", s)
+	}
+}
+
+func TestCache_Image(t *testing.T) {
+	c := New(Options{})
+	var r countingRenderer
+	p := testPayload("Sharma Stores")
+	for range 3 {
+		img, err := c.Image(p, "png", r.render)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(img[:4]) != "IMG:" {
+			t.Fatalf("Image() = %q", img)
+		}
+	}
+	if _, err := c.Image(p, "svg", r.render); err != nil {
+		t.Fatal(err)
+	}
+	if r.calls != 2 {
+		t.Errorf("render called %d times, want once per variant", r.calls)
+	}
+
+	failing := func(io.Writer, string) error { return errors.New("boom") }
+	if _, err := c.Image(testPayload("Other"), "png", failing); err == nil {
+		t.Error("Image() with a failing renderer returned no error")
+	}
+}
+
+func TestCache_Eviction(t *testing.T) {
+	c := New(Options{MaxEntries: 2})
+	a, b, d := testPayload("A"), testPayload("B"), testPayload("D")
+	for _, p := range []*emvqr.Payload{a, b, a, d} {
+		if _, err := c.Encode(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// b was least recently used when d was added.
+	before := c.Stats().Misses
+	c.Encode(a)
+	c.Encode(d)
+	if got := c.Stats().Misses; got != before {
+		t.Errorf("a or d was evicted")
+	}
+	c.Encode(b)
+	if got := c.Stats().Misses; got != before+1 {
+		t.Errorf("b was not evicted")
+	}
+	if n := c.Stats().Entries; n != 2 {
+		t.Errorf("Entries = %d, want 2", n)
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(Options{TTL: time.Minute, Now: func() time.Time { return now }})
+	p := testPayload("Sharma Stores")
+	c.Encode(p)
+	now = now.Add(59 * time.Second)
+	c.Encode(p)
+	if s := c.Stats(); s.Hits != 1 {
+		t.Fatalf("entry expired early: %+v", s)
+	}
+	now = now.Add(time.Second)
+	c.Encode(p)
+	if s := c.Stats(); s.Misses != 2 || s.Entries != 1 {
+		t.Errorf("entry did not expire: %+v", s)
+	}
+
+	c.Purge()
+	if s := c.Stats(); s.Entries != 0 {
+		t.Errorf("Purge left %d entries", s.Entries)
+	}
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	c := New(Options{MaxEntries: 4})
+	var r countingRenderer
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := testPayload([]string{"A", "B", "C", "D", "E"}[i%5])
+			if _, err := c.Image(p, "png", r.render); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := c.Stats().Entries; n > 4 {
+		t.Errorf("Entries = %d, want at most 4", n)
+	}
+}
@@ -0,0 +1,72 @@
+package emvqr
+
+import (
+	"slices"
+	"strings"
+)
+
+// CanonicalVersion identifies the canonical order used by
+// EncodeOptions.Canonical. It will change only if that order does, which
+// would also change the strings and CRCs of canonically encoded payloads.
+//
+// Canonical order, version 1:
+//
+//   - Top-level fields in ascending ID order: "00", "01", Merchant Account
+//     Information "02"–"51" (the typed UPI VPA, UPI VPA Reference and Aadhaar
+//     templates at "26"–"28"), "52"–"62", "64", RFU fields "65"–"79",
+//     Unreserved Templates "80"–"99", then the CRC "63" last.
+//   - Sub-fields of the Additional Data Field, Language and Unreserved
+//     templates in ascending ID order, the Globally Unique Identifier first.
+//   - Fields sharing an ID keep their relative order.
+//
+// The values of Merchant Account Information templates other than "26"–"28"
+// are written as given.
+const CanonicalVersion = 1
+
+// canonicalPayload returns a shallow copy of p whose slices are sorted into
+// canonical order. p is not modified.
+func canonicalPayload(p *Payload) *Payload {
+	c := *p
+	if len(p.MerchantIdentifiers) > 1 {
+		c.MerchantIdentifiers = slices.Clone(p.MerchantIdentifiers)
+		slices.SortStableFunc(c.MerchantIdentifiers, func(a, b MerchantIdentifier) int { return strings.Compare(a.ID, b.ID) })
+	}
+	c.RFUFields = sortedObjects(p.RFUFields)
+	c.UnreservedTemplates = sortedTemplates(p.UnreservedTemplates)
+	if p.AdditionalData != nil {
+		adf := *p.AdditionalData
+		adf.RFUFields = sortedObjects(adf.RFUFields)
+		adf.PaymentSystemTemplates = sortedTemplates(adf.PaymentSystemTemplates)
+		c.AdditionalData = &adf
+	}
+	if p.LanguageTemplate != nil {
+		lt := *p.LanguageTemplate
+		lt.RFUFields = sortedObjects(lt.RFUFields)
+		c.LanguageTemplate = &lt
+	}
+	return &c
+}
+
+// sortedObjects returns a copy of objects stably sorted by ID.
+func sortedObjects(objects []DataObject) []DataObject {
+	if len(objects) < 2 {
+		return objects
+	}
+	objects = slices.Clone(objects)
+	slices.SortStableFunc(objects, func(a, b DataObject) int { return strings.Compare(a.ID, b.ID) })
+	return objects
+}
+
+// sortedTemplates returns a copy of templates stably sorted by ID, with
+// their sub-fields sorted too.
+func sortedTemplates(templates []UnreservedTemplate) []UnreservedTemplate {
+	if len(templates) == 0 {
+		return templates
+	}
+	templates = slices.Clone(templates)
+	for i := range templates {
+		templates[i].SubFields = sortedObjects(templates[i].SubFields)
+	}
+	slices.SortStableFunc(templates, func(a, b UnreservedTemplate) int { return strings.Compare(a.ID, b.ID) })
+	return templates
+}
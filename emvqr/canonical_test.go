@@ -0,0 +1,111 @@
+package emvqr
+
+import (
+	"slices"
+	"testing"
+)
+
+// canonicalTestPayload returns a payload whose slices are out of canonical
+// order, with the given order of its repeated fields reversed when reverse
+// is set.
+func canonicalTestPayload(reverse bool) *Payload {
+	p := basePayload()
+	p.MerchantIdentifiers = []MerchantIdentifier{
+		{ID: "30", Value: "X"},
+		{ID: "02", Value: "4000123456789012"},
+		{ID: "04", Value: "5100123456789012"},
+	}
+	p.UPIVPAInfo = &UPIVPATemplate{RuPayRID: "A000000524", VPA: "shop@upi"}
+	p.AdditionalData = &AdditionalDataField{
+		BillNumber: "B1",
+		RFUFields:  []DataObject{{ID: "20", Value: "b"}, {ID: "12", Value: "a"}},
+	}
+	p.UnreservedTemplates = []UnreservedTemplate{
+		{ID: "91", GloballyUniqueID: "G", SubFields: []DataObject{{ID: "02", Value: "y"}, {ID: "01", Value: "x"}}},
+		{ID: "80", GloballyUniqueID: "H"},
+	}
+	p.RFUFields = []DataObject{{ID: "70", Value: "r"}, {ID: "65", Value: "q"}}
+	if reverse {
+		slices.Reverse(p.MerchantIdentifiers)
+		slices.Reverse(p.AdditionalData.RFUFields)
+		slices.Reverse(p.UnreservedTemplates)
+		slices.Reverse(p.UnreservedTemplates[1].SubFields)
+		slices.Reverse(p.RFUFields)
+	}
+	return p
+}
+
+func TestEncode_Canonical(t *testing.T) {
+	opts := EncodeOptions{Canonical: true}
+	a, err := EncodeWithOptions(canonicalTestPayload(false), opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions: %v", err)
+	}
+	b, err := EncodeWithOptions(canonicalTestPayload(true), opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions: %v", err)
+	}
+	assertEqual(t, "reordered payload", a, b)
+
+	// Golden output: changing it requires bumping CanonicalVersion.
+	const want = "000201" +
+		"02164000123456789012" + "04165100123456789012" +
+		"26260010A0000005240108shop@upi" + "3001X" +
+		"52045251" + "5303840" + "5802US" + "5911ABC Hammers" + "6008New York" +
+		"62160102B11201a2001b" +
+		"6501q" + "7001r" +
+		"80050001H" + "91150001G0101x0201y" +
+		"6304DB41"
+	assertEqual(t, "canonical encoding", want, a)
+
+	t.Run("leaves payload unmodified", func(t *testing.T) {
+		p := canonicalTestPayload(false)
+		if _, err := EncodeWithOptions(p, opts); err != nil {
+			t.Fatal(err)
+		}
+		if p.MerchantIdentifiers[0].ID != "30" || p.RFUFields[0].ID != "70" || p.UnreservedTemplates[0].SubFields[0].ID != "02" {
+			t.Error("Canonical sorted the caller's slices")
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		p := mustDecode(t, a)
+		got, err := EncodeWithOptions(p, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, "re-encoded", a, got)
+	})
+}
+
+func TestEncode_CanonicalPaymentSystemTemplates(t *testing.T) {
+	p := basePayload()
+	p.MerchantIdentifiers = []MerchantIdentifier{{ID: "02", Value: "4000123456789012"}}
+	p.AdditionalData = &AdditionalDataField{
+		StoreLabel:             "S1",
+		RFUFields:              []DataObject{{ID: "20", Value: "b"}},
+		PaymentSystemTemplates: []UnreservedTemplate{{ID: "50", GloballyUniqueID: "G"}},
+	}
+	opts := EncodeOptions{Canonical: true, SpecVersion: SpecVersion11}
+	got, err := EncodeWithOptions(p, opts)
+	if err != nil {
+		t.Fatalf("EncodeWithOptions: %v", err)
+	}
+	const want = "000201" + "02164000123456789012" +
+		"52045251" + "5303840" + "5802US" + "5911ABC Hammers" + "6008New York" +
+		"6220" + "0302S1" + "2001b" + "50050001G" +
+		"6304B4C5"
+	assertEqual(t, "canonical encoding", want, got)
+
+	t.Run("round trip", func(t *testing.T) {
+		back, err := DecodeWithOptions(got, DecodeOptions{SpecVersion: SpecVersion11})
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := EncodeWithOptions(back, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, "re-encoded", got, again)
+	})
+}
@@ -0,0 +1,93 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateCommonCharset checks that s uses only the EMV Common Character
+// Set, the printable ASCII characters 0x20–0x7E, which every field of
+// format "ans" must use except the local-language name and city of the
+// Merchant Information Language Template. Emoji, control characters and
+// other non-ASCII characters break some terminal scanners. The error wraps
+// ErrInvalidFormat and names the first offending character.
+func ValidateCommonCharset(s string) error {
+	if problem := charsetProblem(s); problem != "" {
+		return fmt.Errorf("%w: %q contains %s", ErrInvalidFormat, s, problem)
+	}
+	return nil
+}
+
+// charsetProblem describes the first character of s outside the Common
+// Character Set, e.g. "an emoji (U+1F389)", or returns "".
+func charsetProblem(s string) string {
+	for i := 0; i < len(s); i++ {
+		if isCommonChar(s[i]) {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			return fmt.Sprintf("invalid UTF-8 at byte %d", i)
+		case r < 0x20 || r == 0x7f || r >= 0x80 && r < 0xa0:
+			return fmt.Sprintf("a control character (%U)", r)
+		case isEmoji(r):
+			return fmt.Sprintf("an emoji (%U)", r)
+		}
+		return fmt.Sprintf("the non-ASCII character %q (%U)", r, r)
+	}
+	return ""
+}
+
+// isEmoji reports whether r is a pictograph, dingbat or emoji modifier
+// rather than a letter of some script.
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1f000 && r <= 0x1faff: // emoticons, pictographs, transport, flags
+		return true
+	case r >= 0x2600 && r <= 0x27bf: // miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2b00 && r <= 0x2bff: // arrows and stars such as U+2B50
+		return true
+	}
+	return r == 0x200d || r == 0xfe0f || r == 0x20e3 // joiner, emoji presentation, keycap
+}
+
+// allowsLocalScript reports whether the field at path may hold characters
+// outside the Common Character Set: the Language Template's merchant name
+// and city, and its RFU sub-fields.
+func allowsLocalScript(path string) bool {
+	sub, ok := strings.CutPrefix(path, IDMerchantInfoLanguageTemplate+".")
+	return ok && sub != LangPreference
+}
+
+// charsetErrors returns an error for each field of p, Language Template
+// name and city aside, with characters outside the Common Character Set.
+func charsetErrors(p *Payload) []error {
+	var errs []error
+	eachCharsetProblem(p.tlvNodes(), "", func(path, value, problem string) {
+		errs = append(errs, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: tag %s contains %s", ErrInvalidFormat, path, problem), "tag", path, "value", value))
+	})
+	return errs
+}
+
+// eachCharsetProblem calls fn for each leaf of nodes, under prefix, whose
+// value has a character outside the Common Character Set where the field
+// does not allow one.
+func eachCharsetProblem(nodes []TLVNode, prefix string, fn func(path, value, problem string)) {
+	for _, n := range nodes {
+		path := prefix + n.ID
+		if n.IsTemplate() {
+			eachCharsetProblem(n.Children, path+".", fn)
+			continue
+		}
+		if allowsLocalScript(path) {
+			continue
+		}
+		if problem := charsetProblem(n.Value); problem != "" {
+			fn(path, n.Value, problem)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateCommonCharset(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // part of the error, "" for none
+	}{
+		{"ABC Hammers #12 (Main St.) ~50%", ""},
+		{"Chai Point 🎉", "an emoji (U+1F389)"},
+		{"Sun ☀ Stores", "an emoji (U+2600)"},
+		{"Line\nbreak", "a control character (U+000A)"},
+		{"Tab\t", "a control character (U+0009)"},
+		{"Café", "the non-ASCII character 'é' (U+00E9)"},
+		{"दुकान", "the non-ASCII character 'द' (U+0926)"},
+		{"bad\xff", "invalid UTF-8 at byte 3"},
+	}
+	for _, tt := range tests {
+		err := ValidateCommonCharset(tt.in)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("ValidateCommonCharset(%q) = %v", tt.in, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalidFormat) || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ValidateCommonCharset(%q) = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestEncode_StrictCharset(t *testing.T) {
+	p := basePayload()
+	p.SetLanguageTemplate("hi", "राम स्टोर", "मुंबई")
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.StoreLabel = "Store ✨" })
+
+	// Without the option only Lint objects.
+	if _, err := Encode(p); err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	if got := lintChecks(Lint(p)); !strings.Contains(got, LintCommonCharset) {
+		t.Errorf("Lint() checks = %s, want %s", got, LintCommonCharset)
+	}
+
+	_, err := EncodeWithOptions(p, EncodeOptions{StrictCharset: true})
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("EncodeWithOptions(StrictCharset) error = %v, want ErrInvalidFormat", err)
+	}
+	assertEqual(t, "tag", "62.03", ErrorParams(err)["tag"])
+
+	// The Language Template name and city may use their own script.
+	p.AdditionalData.StoreLabel = "Store 1"
+	if _, err := EncodeWithOptions(p, EncodeOptions{StrictCharset: true}); err != nil {
+		t.Errorf("EncodeWithOptions(StrictCharset) with a Hindi template: %v", err)
+	}
+	for _, w := range Lint(p) {
+		if w.Check == LintCommonCharset {
+			t.Errorf("unexpected warning %s", w)
+		}
+	}
+
+	p.LanguageTemplate.LanguagePreference = "hé"
+	if _, err := EncodeWithOptions(p, EncodeOptions{StrictCharset: true}); ErrorParams(err)["tag"] != "64.00" {
+		t.Errorf("non-ASCII language preference: error = %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package emvqr
+
+import "context"
+
+// Encoder encodes payloads into an internal buffer that it reuses from one
+// call to the next, so that a service encoding many payloads does not
+// allocate a buffer for each.
+//
+// An Encoder is not safe for concurrent use. Give each goroutine its own, or
+// share them through a sync.Pool:
+//
+//	var encoders = sync.Pool{New: func() any { return emvqr.NewEncoder(emvqr.EncodeOptions{}) }}
+//
+//	enc := encoders.Get().(*emvqr.Encoder)
+//	raw, err := enc.Encode(p)
+//	encoders.Put(enc)
+type Encoder struct {
+	opts EncodeOptions
+	buf  []byte
+}
+
+// NewEncoder returns an Encoder that encodes with opts.
+func NewEncoder(opts EncodeOptions) *Encoder {
+	return &Encoder{opts: opts}
+}
+
+// Encode is EncodeWithOptions using the Encoder's options. Once the buffer
+// has grown to fit, the returned string is its only allocation.
+func (e *Encoder) Encode(p *Payload) (string, error) {
+	buf, err := e.encode(p)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// AppendEncode appends the encoding of p to dst, like Encode, and returns
+// the extended slice. It does not allocate when dst has room.
+func (e *Encoder) AppendEncode(dst []byte, p *Payload) ([]byte, error) {
+	buf, err := appendPayload(dst, p, e.opts)
+	if err != nil {
+		return dst, err
+	}
+	return buf, nil
+}
+
+// encode encodes p into the internal buffer and returns it; the result is
+// only valid until the next call.
+func (e *Encoder) encode(p *Payload) ([]byte, error) {
+	if n := EstimateEncodedLength(p); cap(e.buf) < n {
+		e.buf = make([]byte, 0, n)
+	}
+	buf, err := appendPayload(e.buf[:0], p, e.opts)
+	if err != nil {
+		return nil, err
+	}
+	e.buf = buf
+	return buf, nil
+}
+
+// Decoder decodes payloads, reusing its scratch space for the top-level
+// objects from one call to the next. Decode already parses payloads of up to
+// 32 top-level objects without allocating scratch space; a Decoder also
+// avoids it for larger ones.
+//
+// A Decoder is not safe for concurrent use. Give each goroutine its own, or
+// share them through a sync.Pool like Encoder. The payloads it returns do not
+// share memory with it, so they remain valid after the Decoder is reused.
+type Decoder struct {
+	opts    DecodeOptions
+	scratch []tlvObject
+}
+
+// NewDecoder returns a Decoder that decodes with opts.
+func NewDecoder(opts DecodeOptions) *Decoder {
+	return &Decoder{opts: opts, scratch: make([]tlvObject, 0, maxTopLevelObjects)}
+}
+
+// Decode is DecodeWithOptions using the Decoder's options.
+func (d *Decoder) Decode(raw string) (*Payload, error) {
+	return d.DecodeContext(context.Background(), raw)
+}
+
+// DecodeContext is the package-level DecodeContext using the Decoder's
+// options.
+func (d *Decoder) DecodeContext(ctx context.Context, raw string) (*Payload, error) {
+	p, scratch, err := decode(ctx, raw, d.opts, d.scratch)
+	// Drop the references to raw, so that a pooled Decoder does not keep
+	// the last payload string alive.
+	clear(scratch[:cap(scratch)])
+	d.scratch = scratch[:0]
+	return p, err
+}
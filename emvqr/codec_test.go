@@ -0,0 +1,97 @@
+package emvqr
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEncoder_MatchesEncode(t *testing.T) {
+	enc := NewEncoder(EncodeOptions{})
+	for _, p := range []*Payload{basePayload(), mustDecode(t, realWorldBharatQRPayload), basePayload()} {
+		want := mustEncode(t, p)
+		got, err := enc.Encode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, "Encode", want, got)
+		buf, err := enc.AppendEncode([]byte("x"), p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, "AppendEncode", "x"+want, string(buf))
+	}
+}
+
+func TestEncoder_Error(t *testing.T) {
+	p := basePayload()
+	p.MerchantName = ""
+	enc := NewEncoder(EncodeOptions{})
+	if _, err := enc.Encode(p); err == nil {
+		t.Fatal("expected an error for a payload without a merchant name")
+	}
+	dst := []byte("x")
+	got, err := enc.AppendEncode(dst, p)
+	if err == nil {
+		t.Fatal("expected an error from AppendEncode")
+	}
+	assertEqual(t, "dst", "x", string(got))
+}
+
+func TestDecoder_ManyObjects(t *testing.T) {
+	// 40 RFU fields exceed the stack space of Decode.
+	p := basePayload()
+	for i := range 40 {
+		p.RFUFields = append(p.RFUFields, DataObject{ID: strconv.Itoa(65 + i%5), Value: "X"})
+	}
+	raw := mustEncode(t, p)
+	dec := NewDecoder(DecodeOptions{})
+	got, err := dec.Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "RFU fields", "40", strconv.Itoa(len(got.RFUFields)))
+	if cap(dec.scratch) < 40 {
+		t.Errorf("scratch capacity %d, want it grown to hold 40 objects", cap(dec.scratch))
+	}
+	for _, obj := range dec.scratch[:cap(dec.scratch)] {
+		if obj.value != "" {
+			t.Fatal("Decoder retains references to the decoded string")
+		}
+	}
+}
+
+func TestDecoder_Pool(t *testing.T) {
+	pool := sync.Pool{New: func() any { return NewDecoder(DecodeOptions{}) }}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				dec := pool.Get().(*Decoder)
+				p, err := dec.Decode(realWorldBharatQRPayload)
+				pool.Put(dec)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !strings.HasPrefix(p.MerchantName, "APRIL MOON") {
+					t.Errorf("MerchantName = %q", p.MerchantName)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func mustDecode(tb testing.TB, raw string) *Payload {
+	tb.Helper()
+	p, err := Decode(raw)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return p
+}
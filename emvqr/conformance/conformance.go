@@ -0,0 +1,371 @@
+// Package conformance runs EMV QR Code test vectors against this library.
+//
+// Vectors published by EMVCo, NPCI or individual payment schemes are loaded at
+// runtime from JSON or CSV files and checked for three properties:
+//
+//   - the payload decodes (or fails to decode, for negative vectors);
+//   - the embedded CRC is correct;
+//   - re-encoding the decoded Payload reproduces the original string.
+//
+// Expected field values are addressed by tag path, e.g. "59" for the merchant
+// name or "62.05" for the reference label inside the Additional Data Field
+// Template.
+//
+// Example:
+//
+//	vectors, err := conformance.LoadFile("npci_vectors.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	report := conformance.Run(vectors, conformance.Options{})
+//	report.WriteText(os.Stdout)
+package conformance
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// Vector is a single conformance test vector.
+type Vector struct {
+	// ID uniquely identifies the vector within its source, e.g. "MPM-001".
+	ID string `json:"id"`
+	// Source names the publisher, e.g. "EMVCo", "NPCI", "Visa".
+	Source string `json:"source,omitempty"`
+	// Description is free text shown in reports.
+	Description string `json:"description,omitempty"`
+	// Payload is the raw EMV QR Code string under test.
+	Payload string `json:"payload"`
+	// ExpectError marks a negative vector that must fail to decode.
+	ExpectError bool `json:"expect_error,omitempty"`
+	// Fields maps tag paths ("59", "62.05") to their expected values.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Options controls how vectors are evaluated.
+type Options struct {
+	// Decode is passed to emvqr.DecodeWithOptions for every vector.
+	Decode emvqr.DecodeOptions
+	// Encode is passed to emvqr.EncodeWithOptions when re-encoding.
+	Encode emvqr.EncodeOptions
+	// IgnoreReencodeOrder compares the re-encoded payload field-by-field
+	// instead of byte-for-byte, tolerating differences in the order of the
+	// top-level tags and of the sub-fields within each template, such as
+	// Tag 62, Tag 64 or a Tag 80–99 template.
+	IgnoreReencodeOrder bool
+}
+
+// Result is the outcome of running a single Vector.
+type Result struct {
+	Vector Vector
+
+	// DecodeErr is the error returned by the decoder, if any.
+	DecodeErr error
+	// CRCValid reports whether the embedded CRC matched.
+	CRCValid bool
+	// ReencodeEqual reports whether re-encoding reproduced the payload.
+	ReencodeEqual bool
+	// Failures lists every check that did not hold.
+	Failures []string
+}
+
+// Pass reports whether every check for the vector held.
+func (r Result) Pass() bool {
+	return len(r.Failures) == 0
+}
+
+// Report aggregates the results of a run in input order.
+type Report struct {
+	Results []Result
+}
+
+// Passed returns the number of passing vectors.
+func (r *Report) Passed() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Pass() {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the number of failing vectors.
+func (r *Report) Failed() int {
+	return len(r.Results) - r.Passed()
+}
+
+// WriteText writes a human-readable pass/fail line per vector followed by a
+// summary line.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, res := range r.Results {
+		status := "PASS"
+		if !res.Pass() {
+			status = "FAIL"
+		}
+		name := res.Vector.ID
+		if res.Vector.Source != "" {
+			name = res.Vector.Source + "/" + name
+		}
+		if _, err := fmt.Fprintf(w, "%s %s\n", status, name); err != nil {
+			return err
+		}
+		for _, f := range res.Failures {
+			if _, err := fmt.Fprintf(w, "    - %s\n", f); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d passed, %d failed, %d total\n", r.Passed(), r.Failed(), len(r.Results))
+	return err
+}
+
+// Run evaluates every vector and returns the report.
+func Run(vectors []Vector, opts Options) *Report {
+	report := &Report{Results: make([]Result, 0, len(vectors))}
+	for _, v := range vectors {
+		report.Results = append(report.Results, runVector(v, opts))
+	}
+	return report
+}
+
+// runVector evaluates a single vector.
+func runVector(v Vector, opts Options) Result {
+	res := Result{Vector: v}
+	res.CRCValid = emvqr.ValidateCRC(v.Payload) == nil
+
+	p, err := emvqr.DecodeWithOptions(v.Payload, opts.Decode)
+	res.DecodeErr = err
+	if v.ExpectError {
+		if err == nil {
+			res.Failures = append(res.Failures, "expected decode error, got none")
+		}
+		return res
+	}
+	if err != nil {
+		res.Failures = append(res.Failures, fmt.Sprintf("decode: %v", err))
+		return res
+	}
+	if !res.CRCValid {
+		res.Failures = append(res.Failures, "CRC mismatch")
+	}
+
+	if len(v.Fields) > 0 {
+		tree, err := emvqr.ParseTLVTree(v.Payload)
+		if err != nil {
+			res.Failures = append(res.Failures, fmt.Sprintf("tree: %v", err))
+		} else {
+			res.Failures = append(res.Failures, checkFields(tree, v.Fields)...)
+		}
+	}
+
+	reencoded, err := emvqr.EncodeWithOptions(p, opts.Encode)
+	switch {
+	case err != nil:
+		res.Failures = append(res.Failures, fmt.Sprintf("re-encode: %v", err))
+	case opts.IgnoreReencodeOrder:
+		res.ReencodeEqual = sameFields(v.Payload, reencoded)
+	default:
+		res.ReencodeEqual = reencoded == v.Payload
+	}
+	if err == nil && !res.ReencodeEqual {
+		res.Failures = append(res.Failures, fmt.Sprintf("re-encode mismatch: got %s", reencoded))
+	}
+	return res
+}
+
+// checkFields compares expected tag-path values against the parsed tree.
+func checkFields(tree []emvqr.TLVNode, want map[string]string) []string {
+	paths := make([]string, 0, len(want))
+	for path := range want {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var failures []string
+	for _, path := range paths {
+		got, ok := lookup(tree, path)
+		switch {
+		case !ok:
+			failures = append(failures, fmt.Sprintf("field %s: not present, want %q", path, want[path]))
+		case got != want[path]:
+			failures = append(failures, fmt.Sprintf("field %s: got %q, want %q", path, got, want[path]))
+		}
+	}
+	return failures
+}
+
+// lookup resolves a dotted tag path ("62.05") against the tree.
+func lookup(nodes []emvqr.TLVNode, path string) (string, bool) {
+	head, rest, nested := strings.Cut(path, ".")
+	for _, n := range nodes {
+		if n.ID != head {
+			continue
+		}
+		if !nested {
+			return n.Value, true
+		}
+		return lookup(n.Children, rest)
+	}
+	return "", false
+}
+
+// sameFields reports whether a and b contain the same fields, ignoring the
+// order of the top-level fields and of the sub-fields within each template,
+// and the CRC value.
+func sameFields(a, b string) bool {
+	ta, errA := emvqr.ParseTLVTree(a)
+	tb, errB := emvqr.ParseTLVTree(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return fieldsKey(ta) == fieldsKey(tb)
+}
+
+// fieldsKey returns a string identifying nodes whatever their order: the
+// sorted "ID=Value" of each primitive and "ID{...}" of each template, with
+// the keys of its sub-fields, skipping the CRC.
+func fieldsKey(nodes []emvqr.TLVNode) string {
+	keys := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		switch {
+		case n.ID == emvqr.IDCRC:
+			continue
+		case n.IsTemplate():
+			keys = append(keys, n.ID+"{"+fieldsKey(n.Children)+"}")
+		default:
+			keys = append(keys, n.ID+"="+strconv.Itoa(len(n.Value))+":"+n.Value)
+		}
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ";")
+}
+
+// -------------------------------------------------------------------------
+// Loading
+// -------------------------------------------------------------------------
+
+// LoadFile loads vectors from a .json or .csv file, chosen by extension.
+func LoadFile(path string) ([]Vector, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return LoadJSON(f)
+	case ".csv":
+		return LoadCSV(f)
+	default:
+		return nil, fmt.Errorf("conformance: unsupported vector file type %q", filepath.Ext(path))
+	}
+}
+
+// LoadJSON reads a JSON array of Vector objects.
+func LoadJSON(r io.Reader) ([]Vector, error) {
+	var vectors []Vector
+	if err := json.NewDecoder(r).Decode(&vectors); err != nil {
+		return nil, fmt.Errorf("conformance: decoding JSON vectors: %w", err)
+	}
+	return vectors, validateVectors(vectors)
+}
+
+// LoadCSV reads vectors from CSV. The header row must contain "id" and
+// "payload"; "source", "description" and "expect_error" are optional. Any other
+// column whose header is a tag path (e.g. "59", "62.05") is treated as an
+// expected field value; empty cells are ignored.
+func LoadCSV(r io.Reader) ([]Vector, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("conformance: reading CSV header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := col["id"]; !ok {
+		return nil, errors.New("conformance: CSV header is missing the \"id\" column")
+	}
+	if _, ok := col["payload"]; !ok {
+		return nil, errors.New("conformance: CSV header is missing the \"payload\" column")
+	}
+
+	var vectors []Vector
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("conformance: reading CSV line %d: %w", line, err)
+		}
+		v := Vector{
+			ID:          cell(rec, col, "id"),
+			Source:      cell(rec, col, "source"),
+			Description: cell(rec, col, "description"),
+			Payload:     cell(rec, col, "payload"),
+		}
+		if s := cell(rec, col, "expect_error"); s != "" {
+			if v.ExpectError, err = strconv.ParseBool(s); err != nil {
+				return nil, fmt.Errorf("conformance: CSV line %d: invalid expect_error %q", line, s)
+			}
+		}
+		for name, i := range col {
+			if !isTagPath(name) || i >= len(rec) || rec[i] == "" {
+				continue
+			}
+			if v.Fields == nil {
+				v.Fields = make(map[string]string)
+			}
+			v.Fields[name] = rec[i]
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, validateVectors(vectors)
+}
+
+// cell returns the named column of rec, or "" if absent.
+func cell(rec []string, col map[string]int, name string) string {
+	i, ok := col[name]
+	if !ok || i >= len(rec) {
+		return ""
+	}
+	return rec[i]
+}
+
+// isTagPath reports whether s looks like "59" or "62.05".
+func isTagPath(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if len(part) != 2 || part[0] < '0' || part[0] > '9' || part[1] < '0' || part[1] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateVectors checks that every vector has an ID and payload.
+func validateVectors(vectors []Vector) error {
+	for i, v := range vectors {
+		if v.ID == "" {
+			return fmt.Errorf("conformance: vector %d has no id", i)
+		}
+		if v.Payload == "" {
+			return fmt.Errorf("conformance: vector %s has no payload", v.ID)
+		}
+	}
+	return nil
+}
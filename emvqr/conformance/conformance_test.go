@@ -0,0 +1,105 @@
+package conformance
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func TestRun_JSONVectors(t *testing.T) {
+	vectors, err := LoadFile("testdata/vectors.json")
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	report := Run(vectors, Options{})
+	for _, res := range report.Results {
+		if !res.Pass() {
+			t.Errorf("vector %s failed: %v", res.Vector.ID, res.Failures)
+		}
+	}
+	if report.Passed() != 3 {
+		t.Errorf("Passed() = %d, want 3", report.Passed())
+	}
+}
+
+func TestRun_CSVVectors(t *testing.T) {
+	vectors, err := LoadFile("testdata/vectors.csv")
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	if len(vectors) != 3 {
+		t.Fatalf("expected 3 vectors, got %d", len(vectors))
+	}
+	if got := vectors[1].Fields["62.05"]; got != "R555" {
+		t.Errorf("Fields[62.05] = %q, want %q", got, "R555")
+	}
+	if _, ok := vectors[0].Fields["62.05"]; ok {
+		t.Error("empty CSV cell should not produce an expected field")
+	}
+	report := Run(vectors, Options{})
+	if report.Failed() != 0 {
+		var buf bytes.Buffer
+		_ = report.WriteText(&buf)
+		t.Errorf("unexpected failures:\n%s", buf.String())
+	}
+}
+
+func TestRun_ReportsFieldMismatch(t *testing.T) {
+	vectors := []Vector{{
+		ID:      "BAD-FIELD",
+		Payload: "000201021640001234567890125204525153038405802US5911ABC Hammers6008New York63047222",
+		Fields:  map[string]string{"59": "XYZ Hammers", "62.05": "R555"},
+	}}
+	res := Run(vectors, Options{}).Results[0]
+	if res.Pass() {
+		t.Fatal("expected vector to fail")
+	}
+	if len(res.Failures) != 2 {
+		t.Errorf("expected 2 failures, got %v", res.Failures)
+	}
+	if !res.CRCValid || !res.ReencodeEqual {
+		t.Errorf("CRCValid=%v ReencodeEqual=%v, want both true", res.CRCValid, res.ReencodeEqual)
+	}
+}
+
+func TestRun_IgnoreReencodeOrder(t *testing.T) {
+	// Tag 62 holds its Terminal Label (07) before its Store Label (03);
+	// Encode writes them in ID order.
+	body := "000201021640001234567890125204525153038405802US5911ABC Hammers6008New York" +
+		"62170704T0010305S0001" + "6304"
+	vectors := []Vector{{ID: "ORDER-62", Payload: body + emvqr.ComputeCRC(body)}}
+	if res := Run(vectors, Options{}).Results[0]; res.ReencodeEqual {
+		t.Error("byte-for-byte comparison accepted reordered Tag 62 sub-fields")
+	}
+	if res := Run(vectors, Options{IgnoreReencodeOrder: true}).Results[0]; !res.Pass() {
+		t.Errorf("vector failed: %v", res.Failures)
+	}
+
+	// A changed sub-field value is still a mismatch.
+	if sameFields(vectors[0].Payload, strings.Replace(vectors[0].Payload, "T001", "T002", 1)) {
+		t.Error("sameFields ignored a changed Tag 62.07")
+	}
+}
+
+func TestReport_WriteText(t *testing.T) {
+	vectors, err := LoadJSON(strings.NewReader(`[{"id":"X","source":"NPCI","payload":"0002","expect_error":false}]`))
+	if err != nil {
+		t.Fatalf("LoadJSON error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Run(vectors, Options{}).WriteText(&buf); err != nil {
+		t.Fatalf("WriteText error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "FAIL NPCI/X") || !strings.Contains(out, "0 passed, 1 failed, 1 total") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+}
+
+func TestLoadCSV_MissingColumns(t *testing.T) {
+	if _, err := LoadCSV(strings.NewReader("id,description\nX,Y\n")); err == nil {
+		t.Fatal("expected error for CSV without payload column, got nil")
+	}
+}
@@ -0,0 +1,119 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Consistency check names, as reported in Warning.Check by
+// CheckConsistency.
+const (
+	ConsistencyCurrency       = "country-currency"
+	ConsistencySchemeCountry  = "scheme-country"
+	ConsistencySchemeCurrency = "scheme-currency"
+)
+
+// SchemeProfile is the country and currency a domestic payment scheme
+// operates in, and the globally unique identifiers (sub-field "00") by
+// which its merchant account templates are recognised.
+type SchemeProfile struct {
+	Name     string   // e.g. "Bharat QR"
+	Country  string   // ISO 3166-1 alpha-2, e.g. "IN"
+	Currency string   // ISO 4217 numeric, e.g. "356"
+	GUIDs    []string // compared without regard to case
+}
+
+// schemeProfiles are the schemes CheckConsistency recognises.
+var schemeProfiles = []SchemeProfile{
+	{"Bharat QR", "IN", "356", []string{RuPayRIDValue}},
+	{"PIX", "BR", "986", []string{"br.gov.bcb.pix"}},
+	{"PayNow", "SG", "702", []string{"SG.PAYNOW", "SG.SGQR"}},
+	{"QRIS", "ID", "360", []string{"ID.CO.QRIS.WWW"}},
+}
+
+// SchemeProfiles returns the schemes CheckConsistency recognises.
+func SchemeProfiles() []SchemeProfile {
+	return append([]SchemeProfile(nil), schemeProfiles...)
+}
+
+// Schemes returns the profiles of the domestic schemes whose merchant
+// account templates p carries, in the order of SchemeProfiles.
+func (p *Payload) Schemes() []SchemeProfile {
+	guids := p.merchantGUIDs()
+	var found []SchemeProfile
+	for _, s := range schemeProfiles {
+		for _, g := range s.GUIDs {
+			if guids[strings.ToUpper(g)] {
+				found = append(found, s)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// merchantGUIDs returns the globally unique identifiers of the merchant
+// account templates of p, upper-cased.
+func (p *Payload) merchantGUIDs() map[string]bool {
+	guids := make(map[string]bool)
+	add := func(g string) {
+		if g != "" {
+			guids[strings.ToUpper(g)] = true
+		}
+	}
+	if v := p.UPIVPAInfo; v != nil {
+		add(v.RuPayRID)
+	}
+	if r := p.UPITransactionRef; r != nil {
+		add(r.RuPayRID)
+	}
+	if a := p.MerchantAadhaar; a != nil {
+		add(a.RuPayRID)
+	}
+	for _, mi := range p.MerchantIdentifiers {
+		subs := mi.SubFields
+		if len(subs) == 0 && isTemplateID(mi.ID) {
+			if objects, err := parseTLV(mi.Value); err == nil {
+				subs = convertTLVToDataObjects(objects, true)
+			}
+		}
+		for _, sf := range subs {
+			if sf.ID == MAIGloballyUniqueID {
+				add(sf.Value)
+			}
+		}
+	}
+	return guids
+}
+
+// CheckConsistency returns warnings for combinations of country, currency
+// and scheme that are legal but usually copy-paste mistakes of merchant
+// onboarding tools:
+//
+//   - a Tag 53 currency other than that of the Tag 58 country, such as
+//     840 (USD) for IN;
+//   - a domestic scheme, such as Bharat QR or PIX, in a payload for
+//     another country or currency, e.g. a PIX key with Tag 58 "IN".
+//
+// Fields that are absent are not compared.
+func CheckConsistency(p *Payload) []Warning {
+	var warnings []Warning
+	warn := func(check, path, format string, args ...any) {
+		warnings = append(warnings, Warning{check, path, fmt.Sprintf(format, args...)})
+	}
+	if want, ok := countryCurrencies[p.CountryCode]; ok && p.TransactionCurrency != "" && p.TransactionCurrency != want {
+		warn(ConsistencyCurrency, IDTransactionCurrency,
+			"Tag 53 currency %s is not %s, the currency of %s (Tag 58)", p.TransactionCurrency, want, p.CountryCode)
+	}
+	for _, s := range p.Schemes() {
+		if p.CountryCode != "" && p.CountryCode != s.Country {
+			warn(ConsistencySchemeCountry, IDCountryCode,
+				"%s is a scheme of %s, but Tag 58 country is %s", s.Name, s.Country, p.CountryCode)
+		}
+		if p.TransactionCurrency != "" && p.TransactionCurrency != s.Currency {
+			warn(ConsistencySchemeCurrency, IDTransactionCurrency,
+				"%s payments are in %s, but Tag 53 currency is %s", s.Name, s.Currency, p.TransactionCurrency)
+		}
+	}
+	return warnings
+}
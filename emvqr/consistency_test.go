@@ -0,0 +1,61 @@
+package emvqr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	pix := func(p *Payload) {
+		p.MerchantIdentifiers = []MerchantIdentifier{{ID: "26", SubFields: []DataObject{
+			{ID: "00", Value: "BR.GOV.BCB.PIX"}, {ID: "01", Value: "loja@example.com"},
+		}}}
+	}
+	tests := []struct {
+		name string
+		edit func(p *Payload)
+		want string // lintChecks of the warnings
+	}{
+		{"consistent", func(*Payload) {}, ""},
+		{"currency", func(p *Payload) { p.CountryCode = "IN" }, "country-currency@53"},
+		{"Bharat QR abroad", func(p *Payload) {
+			_ = p.SetUPIVPATemplate(RuPayRIDValue, "shop@upi", "")
+		}, "scheme-country@58 scheme-currency@53"},
+		{"PIX in Brazil", func(p *Payload) {
+			pix(p)
+			p.CountryCode, p.TransactionCurrency = "BR", "986"
+		}, ""},
+		{"PIX with rupees", func(p *Payload) {
+			pix(p)
+			p.CountryCode, p.TransactionCurrency = "IN", "356"
+		}, "scheme-country@58 scheme-currency@53"},
+		{"unknown country", func(p *Payload) { p.CountryCode = "ZZ" }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := basePayload()
+			tt.edit(p)
+			assertEqual(t, "checks", tt.want, lintChecks(CheckConsistency(p)))
+		})
+	}
+
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := p.Schemes(); len(s) != 1 || s[0].Name != "Bharat QR" {
+		t.Errorf("Schemes() = %v, want Bharat QR", s)
+	}
+	if w := CheckConsistency(p); len(w) != 0 {
+		t.Errorf("CheckConsistency(real-world Bharat QR) = %v", w)
+	}
+	p.CountryCode = "SG"
+	const want = "Bharat QR is a scheme of IN, but Tag 58 country is SG"
+	var messages []string
+	for _, w := range CheckConsistency(p) {
+		messages = append(messages, w.Message)
+	}
+	if !slices.Contains(messages, want) {
+		t.Errorf("CheckConsistency() = %q, want %q", messages, want)
+	}
+}
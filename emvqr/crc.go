@@ -1,6 +1,9 @@
 package emvqr
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // crc16CCITT computes the CRC-16/CCITT-FALSE checksum used by EMV QR codes.
 //
@@ -24,7 +27,91 @@ func crc16CCITT(data []byte) uint16 {
 	return crc
 }
 
+// ComputeCRC returns the four-character CRC16-CCITT value for data, which
+// should contain the payload up to and including the "6304" CRC prefix.
+func ComputeCRC(data string) string {
+	return crcString(crc16CCITT([]byte(data)))
+}
+
+// ValidateCRC checks the CRC embedded at the end of raw and returns an error
+// wrapping ErrCRCMismatch or ErrInvalidTLV if it is wrong or absent.
+func ValidateCRC(raw string) error {
+	return validateCRC(raw)
+}
+
 // crcString encodes a uint16 as a 4-character upper-case hex string.
 func crcString(v uint16) string {
-	return fmt.Sprintf("%04X", v)
+	return string(appendCRC(make([]byte, 0, 4), v))
+}
+
+// appendCRC appends v to buf as 4 upper-case hex digits.
+func appendCRC(buf []byte, v uint16) []byte {
+	const hex = "0123456789ABCDEF"
+	return append(buf, hex[v>>12], hex[v>>8&0xF], hex[v>>4&0xF], hex[v&0xF])
+}
+
+// CRCError is the error of a CRC mismatch, with diagnostics for support teams
+// triaging a damaged QR code. It wraps ErrCRCMismatch; retrieve it with
+// errors.As:
+//
+//	var crcErr *emvqr.CRCError
+//	if errors.As(err, &crcErr) {
+//	    log.Print(crcErr.Hint())
+//	}
+type CRCError struct {
+	Got  string // the CRC value of the payload, upper-cased
+	Want string // the CRC computed over the payload
+
+	// Truncated reports that the payload ends within the CRC value, e.g.
+	// because a character was lost when it was copied. Want is then
+	// computed over the intact data, and Got is a prefix of it if nothing
+	// else was damaged.
+	Truncated bool
+
+	// Parseable reports that the TLV structure of the payload, templates
+	// included, parses when the CRC is not validated, so the damage, if
+	// any, is in field values.
+	Parseable bool
+}
+
+// newCRCError returns the error of a CRC mismatch in raw, annotated with
+// the code CodeCRCMismatch.
+func newCRCError(raw, got, want string) *Error {
+	e := &CRCError{Got: got, Want: want, Truncated: len(got) < 4, Parseable: tlvParses(raw)}
+	return newError(CodeCRCMismatch, e, "tag", IDCRC, "got", got, "want", want)
+}
+
+func (e *CRCError) Error() string {
+	return fmt.Sprintf("%v: got %s, want %s", ErrCRCMismatch, e.Got, e.Want)
+}
+
+func (e *CRCError) Unwrap() error { return ErrCRCMismatch }
+
+// Hint suggests how to recover from the mismatch.
+func (e *CRCError) Hint() string {
+	switch {
+	case e.Truncated && strings.HasPrefix(e.Want, e.Got):
+		return fmt.Sprintf("the payload was cut off within its CRC; the data is intact and its CRC is %s", e.Want)
+	case e.Truncated:
+		return "the payload was cut off within its CRC and the data does not match it; rescan the QR code"
+	case e.Parseable:
+		return "the structure is intact but a value was altered or misread; compare the fields with the merchant's records, or try Repair"
+	}
+	return "the structure is damaged; rescan the QR code or ask the merchant for a new one"
+}
+
+// tlvParses reports whether raw and the templates in it parse as TLV.
+func tlvParses(raw string) bool {
+	objects, err := parseTLV(raw)
+	if err != nil {
+		return false
+	}
+	for _, obj := range objects {
+		if isTemplateID(obj.id) {
+			if _, err := parseTLV(obj.value); err != nil {
+				return false
+			}
+		}
+	}
+	return true
 }
@@ -0,0 +1,45 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCRCError_Diagnostics(t *testing.T) {
+	raw := realWorldBharatQRPayload
+	tests := []struct {
+		name                 string
+		raw                  string
+		got                  string
+		truncated, parseable bool
+		hint                 string
+	}{
+		{"altered value", strings.Replace(raw, "250.00", "950.00", 1), "51DD", false, true, "value was altered"},
+		{"cut off", raw[:len(raw)-1], "51D", true, false, "data is intact and its CRC is 51DD"},
+		{"cut off and altered", strings.Replace(raw[:len(raw)-2], "250.00", "950.00", 1), "51", true, false, "does not match"},
+		{"damaged structure", strings.Replace(raw, "5923APRIL", "5999APRIL", 1), "51DD", false, false, "structure is damaged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.raw)
+			var crcErr *CRCError
+			if !errors.As(err, &crcErr) {
+				t.Fatalf("Decode() error = %v, want a *CRCError", err)
+			}
+			if !errors.Is(err, ErrCRCMismatch) || ErrorCode(err) != CodeCRCMismatch {
+				t.Errorf("error %v does not wrap ErrCRCMismatch with its code", err)
+			}
+			assertEqual(t, "Got", tt.got, crcErr.Got)
+			if tt.truncated != crcErr.Truncated || tt.parseable != crcErr.Parseable {
+				t.Errorf("Truncated, Parseable = %v, %v; want %v, %v", crcErr.Truncated, crcErr.Parseable, tt.truncated, tt.parseable)
+			}
+			if !strings.Contains(crcErr.Hint(), tt.hint) {
+				t.Errorf("Hint() = %q, want it to mention %q", crcErr.Hint(), tt.hint)
+			}
+		})
+	}
+	if err := ValidateCRC(raw[:len(raw)-3]); !errors.Is(err, ErrCRCMismatch) {
+		t.Errorf("ValidateCRC() of a truncated payload = %v, want ErrCRCMismatch", err)
+	}
+}
@@ -1,6 +1,7 @@
 package emvqr
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,6 +12,59 @@ type DecodeOptions struct {
 	// SkipCRCValidation disables CRC checking. Useful when the CRC field is
 	// absent (e.g., during unit tests with partial payloads).
 	SkipCRCValidation bool
+
+	// SpecVersion selects the EMV QRCPS MPM revision used to interpret the
+	// payload. Payloads whose Payload Format Indicator is not defined by the
+	// selected version are rejected with ErrUnsupportedVersion, and
+	// payloads without one with ErrMissingRequired.
+	SpecVersion SpecVersion
+
+	// AcceptFormatIndicators lists Payload Format Indicator values accepted
+	// in addition to those SpecVersion defines, for forward compatibility
+	// with a later revision. Such payloads are still interpreted as
+	// SpecVersion.
+	AcceptFormatIndicators []string
+
+	// VerifyMerchant, if set, is called by DecodeContext after a successful
+	// parse, e.g. to look the merchant up in an acquirer database or the
+	// NPCI merchant list. A non-nil error is returned wrapped in
+	// ErrMerchantUnverified, with code CodeMerchantUnverified, so that the
+	// app handles verification like any other decode failure.
+	VerifyMerchant func(ctx context.Context, p *Payload) error
+
+	// CaptureRaw records the raw substring and offsets of every data
+	// object, templates' sub-fields included, in Payload.RawSegments, as
+	// byte-exact evidence for dispute and chargeback investigations.
+	CaptureRaw bool
+
+	// Limits bounds the size and nesting of the payloads accepted. The zero
+	// value applies the defaults, such as the 512 character maximum of
+	// EMV QRCPS.
+	Limits Limits
+
+	// Hooks, if set, observe every decode; see Hooks.
+	Hooks *Hooks
+
+	// DropEmptySubFields discards zero-length sub-fields of the Bharat QR
+	// templates (Tags 26–28), such as the empty Aadhaar number some
+	// acquirers print, instead of keeping them in MerchantIdentifiers so
+	// that Encode reproduces the payload. EMV QRCPS requires values of at
+	// least one character; Lint reports empty ones either way. Flatten, and
+	// the JSON and XML forms built on it, omit empty sub-fields.
+	DropEmptySubFields bool
+
+	// Expander, if set, replaces the Tag 27 Reference URL with the link it
+	// stands for, undoing EncodeOptions.Shortener, before VerifyMerchant is
+	// called. DecodeContext passes its context to it. On failure the payload
+	// is returned, URL unexpanded, with an error wrapping ErrInvalidFormat.
+	Expander URLExpander
+
+	// StrictFormats rejects payloads whose typed fields break their EMV
+	// QRCPS type, such as an MCC of "ABCD" or an amount of "1,50", and
+	// whose postal code does not match the country; see
+	// Payload.ValidateFieldFormats. By default such values are decoded
+	// as they are, and Explain reports them.
+	StrictFormats bool
 }
 
 // Decode parses a raw EMV QR Code string and returns the structured Payload.
@@ -19,33 +73,119 @@ func Decode(raw string) (*Payload, error) {
 	return DecodeWithOptions(raw, DecodeOptions{})
 }
 
-// DecodeWithOptions parses the raw string using the given options.
+// DecodeWithOptions parses the raw string using the given options. It is
+// DecodeContext with context.Background().
 func DecodeWithOptions(raw string, opts DecodeOptions) (*Payload, error) {
+	return DecodeContext(context.Background(), raw, opts)
+}
+
+// DecodeContext parses the raw string using the given options and passes
+// ctx to opts.Expander and opts.VerifyMerchant. When verification fails it returns the decoded
+// payload together with the error, so that the app can still show whom the
+// QR code claims to pay.
+func DecodeContext(ctx context.Context, raw string, opts DecodeOptions) (*Payload, error) {
+	var buf [maxTopLevelObjects]tlvObject
+	p, _, err := decode(ctx, raw, opts, buf[:0])
+	return p, err
+}
+
+// decode is DecodeContext, parsing the top-level objects into scratch. It
+// returns scratch, grown if need be, for reuse by the next call.
+func decode(ctx context.Context, raw string, opts DecodeOptions, scratch []tlvObject) (*Payload, []tlvObject, error) {
+	if opts.Hooks != nil {
+		return decodeWithHooks(ctx, opts.Hooks, raw, opts, scratch)
+	}
+	return decodePayload(ctx, raw, opts, scratch)
+}
+
+// decodePayload is decode without the hooks.
+func decodePayload(ctx context.Context, raw string, opts DecodeOptions, scratch []tlvObject) (*Payload, []tlvObject, error) {
+	p, scratch, err := parsePayload(raw, opts, scratch)
+	if err != nil {
+		return nil, scratch, err
+	}
+	if opts.Expander != nil {
+		if err := expandReferenceURL(ctx, p, opts.Expander); err != nil {
+			return p, scratch, err
+		}
+	}
+	if opts.VerifyMerchant != nil {
+		if err := opts.VerifyMerchant(ctx, p); err != nil {
+			return p, scratch, newError(CodeMerchantUnverified, fmt.Errorf("%w: %w", ErrMerchantUnverified, err))
+		}
+	}
+	return p, scratch, nil
+}
+
+// parsePayload validates and parses raw into a Payload. Any input yields a
+// payload or an error with a Code: every length is checked before it is
+// used, so the parser does not panic, which FuzzDecode checks. A panic is a
+// bug and is deliberately not recovered, so that fuzzers see it.
+func parsePayload(raw string, opts DecodeOptions, scratch []tlvObject) (p *Payload, objects []tlvObject, err error) {
+	objects = scratch
 	if len(raw) < 4 {
-		return nil, ErrInvalidLength
+		return nil, objects, ErrInvalidLength
+	}
+	if err := opts.Limits.check(raw); err != nil {
+		return nil, objects, err
 	}
 
 	// Validate and strip CRC before parsing
 	if !opts.SkipCRCValidation {
 		if err := validateCRC(raw); err != nil {
-			return nil, err
+			return nil, objects, err
 		}
 	}
 
-	objects, err := parseTLV(raw)
+	parsed, err := appendTLVObjects(scratch[:0], raw)
 	if err != nil {
-		return nil, err
+		return nil, objects, err
 	}
+	objects = parsed
 
-	p := &Payload{}
+	p = &Payload{}
+	mis := 0
 	for _, obj := range objects {
-		if err := p.applyObject(obj); err != nil {
-			return nil, err
+		if isMerchantAccountInfo(obj.id) {
+			mis++
+		}
+	}
+	if mis > 0 {
+		p.MerchantIdentifiers = make([]MerchantIdentifier, 0, mis)
+	}
+	prev := ""
+	for _, obj := range objects {
+		rfus := len(p.RFUFields)
+		if err := p.applyObject(obj, opts); err != nil {
+			return nil, objects, err
+		}
+		if len(p.RFUFields) > rfus {
+			p.RFUAfter = append(p.RFUAfter, prev)
 		}
+		prev = obj.id
 	}
-	return p, nil
+	if err := checkFormatIndicator(p, opts.SpecVersion, opts.AcceptFormatIndicators); err != nil {
+		return nil, objects, err
+	}
+	if opts.StrictFormats {
+		if errs := fieldFormatErrors(p); len(errs) > 0 {
+			return nil, objects, errs[0]
+		}
+	}
+	if opts.CaptureRaw {
+		p.RawSegments = appendRawSegments(nil, raw, 0, "")
+	}
+	return p, objects, nil
 }
 
+// maxTopLevelObjects is the number of top-level objects DecodeContext parses
+// without allocating. A payload of at most 512 characters rarely holds more.
+const maxTopLevelObjects = 32
+
+// maxTemplateObjects is the number of template sub-fields parsed without
+// allocating.
+const maxTemplateObjects = 16
+
 // validateCRC checks the CRC16-CCITT checksum embedded in the raw string.
 // Per the spec, the CRC covers the entire payload including the "6304" prefix
 // of the CRC field but not the 4-char CRC value itself.
@@ -59,18 +199,18 @@ func validateCRC(raw string) error {
 		return fmt.Errorf("%w: CRC field (ID 63) not found", ErrInvalidTLV)
 	}
 	dataPart := raw[:crcFieldStart+4] // up to and including "6304"
-	crcValue := raw[crcFieldStart+4 : crcFieldStart+8]
+	crcValue := raw[crcFieldStart+4 : min(crcFieldStart+8, len(raw))]
 
-	computed := crc16CCITT([]byte(dataPart))
-	expected := crcString(computed)
-	if !strings.EqualFold(crcValue, expected) {
-		return fmt.Errorf("%w: got %s, want %s", ErrCRCMismatch, strings.ToUpper(crcValue), expected)
+	var hex [4]byte
+	computed := appendCRC(hex[:0], crc16CCITT([]byte(dataPart)))
+	if !strings.EqualFold(crcValue, string(computed)) {
+		return newCRCError(raw, strings.ToUpper(crcValue), string(computed))
 	}
 	return nil
 }
 
 // applyObject maps a single top-level TLV object onto the Payload.
-func (p *Payload) applyObject(obj tlvObject) error {
+func (p *Payload) applyObject(obj tlvObject, opts DecodeOptions) error {
 	id := obj.id
 	val := obj.value
 
@@ -79,7 +219,11 @@ func (p *Payload) applyObject(obj tlvObject) error {
 		p.PayloadFormatIndicator = val
 
 	case id == IDPointOfInitiationMethod:
-		p.PointOfInitiationMethod = val
+		poi, err := ParsePOI(val)
+		if err != nil {
+			return &ParseError{ID: id, Err: err}
+		}
+		p.PointOfInitiationMethod = poi
 
 	case id == IDUPIVPATemplate:
 		uvt, err := decodeUPIVPATemplate(val)
@@ -88,14 +232,7 @@ func (p *Payload) applyObject(obj tlvObject) error {
 		}
 		p.UPIVPAInfo = uvt
 		// Also add to MerchantIdentifiers with SubFields
-		subFields, err := parseTLV(val)
-		if err == nil {
-			mi := MerchantIdentifier{
-				ID:        id,
-				SubFields: convertTLVToDataObjects(subFields),
-			}
-			p.MerchantIdentifiers = append(p.MerchantIdentifiers, mi)
-		}
+		p.appendTemplateIdentifier(id, val, opts.DropEmptySubFields)
 
 	case id == IDUPIVPAReference:
 		uvr, err := decodeUPIVPAReference(val)
@@ -104,14 +241,7 @@ func (p *Payload) applyObject(obj tlvObject) error {
 		}
 		p.UPITransactionRef = uvr
 		// Also add to MerchantIdentifiers with SubFields
-		subFields, err := parseTLV(val)
-		if err == nil {
-			mi := MerchantIdentifier{
-				ID:        id,
-				SubFields: convertTLVToDataObjects(subFields),
-			}
-			p.MerchantIdentifiers = append(p.MerchantIdentifiers, mi)
-		}
+		p.appendTemplateIdentifier(id, val, opts.DropEmptySubFields)
 
 	case id == IDAadhaarTemplate:
 		ai, err := decodeAadhaarInfo(val)
@@ -120,14 +250,7 @@ func (p *Payload) applyObject(obj tlvObject) error {
 		}
 		p.MerchantAadhaar = ai
 		// Also add to MerchantIdentifiers with SubFields
-		subFields, err := parseTLV(val)
-		if err == nil {
-			mi := MerchantIdentifier{
-				ID:        id,
-				SubFields: convertTLVToDataObjects(subFields),
-			}
-			p.MerchantIdentifiers = append(p.MerchantIdentifiers, mi)
-		}
+		p.appendTemplateIdentifier(id, val, opts.DropEmptySubFields)
 
 	case isMerchantAccountInfo(id):
 		// Add merchant identifier for this payment network
@@ -170,7 +293,7 @@ func (p *Payload) applyObject(obj tlvObject) error {
 		p.PostalCode = val
 
 	case id == IDAdditionalDataFieldTemplate:
-		adf, err := decodeAdditionalDataField(val)
+		adf, err := decodeAdditionalDataField(val, opts.SpecVersion)
 		if err != nil {
 			return &ParseError{ID: id, Err: err}
 		}
@@ -199,6 +322,18 @@ func (p *Payload) applyObject(obj tlvObject) error {
 	return nil
 }
 
+// appendTemplateIdentifier adds the Bharat QR template id, whose typed field
+// has been decoded from val, to MerchantIdentifiers with its sub-fields.
+func (p *Payload) appendTemplateIdentifier(id, val string, dropEmpty bool) {
+	var buf [maxTemplateObjects]tlvObject
+	if subs, err := appendTLVObjects(buf[:0], val); err == nil {
+		p.MerchantIdentifiers = append(p.MerchantIdentifiers, MerchantIdentifier{
+			ID:        id,
+			SubFields: convertTLVToDataObjects(subs, dropEmpty),
+		})
+	}
+}
+
 // isMerchantAccountInfo reports whether id falls in "02"–"51".
 func isMerchantAccountInfo(id string) bool {
 	n, err := strconv.Atoi(id)
@@ -217,14 +352,20 @@ func isUnreservedTemplate(id string) bool {
 	return n >= 80 && n <= 99
 }
 
-// decodeAdditionalDataField parses the contents of ID "62".
-func decodeAdditionalDataField(val string) (*AdditionalDataField, error) {
-	subs, err := parseTLV(val)
+// decodeAdditionalDataField parses the contents of ID "62". Sub-fields added
+// in v1.1 are only recognised when version is SpecVersion11.
+func decodeAdditionalDataField(val string, version SpecVersion) (*AdditionalDataField, error) {
+	var buf [maxTemplateObjects]tlvObject
+	subs, err := appendTLVObjects(buf[:0], val)
 	if err != nil {
 		return nil, err
 	}
 	adf := &AdditionalDataField{}
 	for _, s := range subs {
+		if version < SpecVersion11 && s.id >= ADFMerchantTaxID {
+			adf.RFUFields = append(adf.RFUFields, DataObject{ID: s.id, Value: s.value})
+			continue
+		}
 		switch s.id {
 		case ADFBillNumber:
 			adf.BillNumber = s.value
@@ -244,8 +385,20 @@ func decodeAdditionalDataField(val string) (*AdditionalDataField, error) {
 			adf.PurposeOfTransaction = s.value
 		case ADFAdditionalConsumerDataRequest:
 			adf.AdditionalConsumerDataRequest = s.value
+		case ADFMerchantTaxID:
+			adf.MerchantTaxID = s.value
+		case ADFMerchantChannel:
+			adf.MerchantChannel = s.value
 		default:
-			adf.RFUFields = append(adf.RFUFields, DataObject{ID: s.id, Value: s.value})
+			if isPaymentSystemTemplateID(s.id) {
+				pst, err := decodeUnreservedTemplate(s.id, s.value)
+				if err != nil {
+					return nil, err
+				}
+				adf.PaymentSystemTemplates = append(adf.PaymentSystemTemplates, *pst)
+			} else {
+				adf.RFUFields = append(adf.RFUFields, DataObject{ID: s.id, Value: s.value})
+			}
 		}
 	}
 	return adf, nil
@@ -253,7 +406,8 @@ func decodeAdditionalDataField(val string) (*AdditionalDataField, error) {
 
 // decodeLanguageTemplate parses the contents of ID "64".
 func decodeLanguageTemplate(val string) (*LanguageTemplate, error) {
-	subs, err := parseTLV(val)
+	var buf [maxTemplateObjects]tlvObject
+	subs, err := appendTLVObjects(buf[:0], val)
 	if err != nil {
 		return nil, err
 	}
@@ -275,7 +429,8 @@ func decodeLanguageTemplate(val string) (*LanguageTemplate, error) {
 
 // decodeUnreservedTemplate parses an Unreserved Template (IDs "80"–"99").
 func decodeUnreservedTemplate(id, val string) (*UnreservedTemplate, error) {
-	subs, err := parseTLV(val)
+	var buf [maxTemplateObjects]tlvObject
+	subs, err := appendTLVObjects(buf[:0], val)
 	if err != nil {
 		return nil, &ParseError{ID: id, Err: err}
 	}
@@ -290,10 +445,10 @@ func decodeUnreservedTemplate(id, val string) (*UnreservedTemplate, error) {
 	return ut, nil
 }
 
-// decodeUPIVPAReference parses the UPI VPA Reference template (ID "27").
 // decodeUPIVPATemplate parses the UPI VPA template (ID "26").
 func decodeUPIVPATemplate(val string) (*UPIVPATemplate, error) {
-	subs, err := parseTLV(val)
+	var buf [maxTemplateObjects]tlvObject
+	subs, err := appendTLVObjects(buf[:0], val)
 	if err != nil {
 		return nil, err
 	}
@@ -313,7 +468,8 @@ func decodeUPIVPATemplate(val string) (*UPIVPATemplate, error) {
 
 // decodeUPIVPAReference parses the UPI VPA Reference template (ID "27").
 func decodeUPIVPAReference(val string) (*UPIVPAReference, error) {
-	subs, err := parseTLV(val)
+	var buf [maxTemplateObjects]tlvObject
+	subs, err := appendTLVObjects(buf[:0], val)
 	if err != nil {
 		return nil, err
 	}
@@ -333,7 +489,8 @@ func decodeUPIVPAReference(val string) (*UPIVPAReference, error) {
 
 // decodeAadhaarInfo parses the Aadhaar template (ID "28").
 func decodeAadhaarInfo(val string) (*AadhaarInfo, error) {
-	subs, err := parseTLV(val)
+	var buf [maxTemplateObjects]tlvObject
+	subs, err := appendTLVObjects(buf[:0], val)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,65 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseTLV_SignedLength(t *testing.T) {
+	for _, raw := range []string{"00-1", "00+2AB"} {
+		if _, err := parseTLV(raw); !errors.Is(err, ErrInvalidTLV) {
+			t.Errorf("parseTLV(%q) error = %v, want ErrInvalidTLV", raw, err)
+		}
+	}
+}
+
+func mustEncode(tb testing.TB, p *Payload) string {
+	tb.Helper()
+	raw, err := Encode(p)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return raw
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add(realWorldBharatQRPayload)
+	f.Add(baseRaw)
+	f.Fuzz(func(t *testing.T, raw string) {
+		_, err := DecodeWithOptions(raw, DecodeOptions{SkipCRCValidation: true, SpecVersion: SpecVersion11})
+		if err != nil && ErrorCode(err) == CodeUnknown {
+			t.Fatalf("Decode(%q) error has no code: %v", raw, err)
+		}
+	})
+}
+
+func TestDecode_EmptySubFields(t *testing.T) {
+	p := mustDecode(t, realWorldBharatQRPayload)
+	var aadhaar MerchantIdentifier
+	for _, mi := range p.MerchantIdentifiers {
+		if mi.ID == IDAadhaarTemplate {
+			aadhaar = mi
+		}
+	}
+	if len(aadhaar.SubFields) != 2 || aadhaar.SubFields[1] != (DataObject{ID: AadhaarAadhaarNum}) {
+		t.Fatalf("Tag 28 sub-fields = %v, want the empty 28.01 kept", aadhaar.SubFields)
+	}
+	if got := mustEncode(t, p); !strings.Contains(got, "28180010A0000005240100") {
+		t.Errorf("Encode() = %s, want Tag 28 with the empty 28.01", got)
+	}
+
+	// Setting the typed field replaces the empty sub-field.
+	p.MerchantAadhaar.AadhaarNumber = "123456789012"
+	if got := mustEncode(t, p); !strings.Contains(got, "28300010A0000005240112123456789012") {
+		t.Errorf("Encode() = %s, want Tag 28 with the Aadhaar number only", got)
+	}
+
+	dropped, err := DecodeWithOptions(realWorldBharatQRPayload, DecodeOptions{DropEmptySubFields: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mustEncode(t, dropped); strings.Contains(got, "28180010A0000005240100") {
+		t.Errorf("Encode() kept the dropped sub-field: %s", got)
+	}
+}
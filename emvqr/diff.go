@@ -0,0 +1,54 @@
+package emvqr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FieldChange is one difference between two payloads.
+type FieldChange struct {
+	Path string // dotted tag path, as produced by Flatten
+	Old  string // "" when the field was added
+	New  string // "" when the field was removed
+}
+
+// String formats the change as `59: "OLD" → "NEW"`.
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s: %q → %q", c.Path, c.Old, c.New)
+}
+
+// Diff lists the fields that differ between a and b, sorted by tag path,
+// e.g. to compare the QR registered at onboarding with the one found at the
+// merchant premises. Fields are compared by their Flatten paths, so the
+// order in which they were encoded does not matter. The CRC is not compared;
+// it differs whenever any other field does. A nil payload has no fields.
+func Diff(a, b *Payload) []FieldChange {
+	return diffFields(diffable(a), diffable(b))
+}
+
+// diffable returns the Flatten map of p without the CRC, or nil for a nil p.
+func diffable(p *Payload) map[string]string {
+	if p == nil {
+		return nil
+	}
+	m := p.Flatten()
+	delete(m, IDCRC)
+	return m
+}
+
+// diffFields lists the differences between two Flatten maps, sorted by path.
+func diffFields(a, b map[string]string) []FieldChange {
+	var changes []FieldChange
+	for path, v := range b {
+		if old := a[path]; old != v {
+			changes = append(changes, FieldChange{Path: path, Old: old, New: v})
+		}
+	}
+	for path, old := range a {
+		if _, ok := b[path]; !ok {
+			changes = append(changes, FieldChange{Path: path, Old: old})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
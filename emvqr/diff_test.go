@@ -0,0 +1,59 @@
+package emvqr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	registered := mustDecode(t, realWorldBharatQRPayload)
+
+	t.Run("identical", func(t *testing.T) {
+		found := mustDecode(t, realWorldBharatQRPayload)
+		found.CRC = "FFFF"
+		if got := Diff(registered, found); len(got) != 0 {
+			t.Errorf("Diff = %v, want none", got)
+		}
+	})
+
+	t.Run("changed, added and removed", func(t *testing.T) {
+		a, b := basePayload(), basePayload()
+		a.PostalCode = "10001"
+		b.MerchantName = "XYZ Hammers"
+		b.TransactionAmount = "5.00"
+		want := []FieldChange{
+			{Path: IDTransactionAmount, New: "5.00"},
+			{Path: IDMerchantName, Old: "ABC Hammers", New: "XYZ Hammers"},
+			{Path: IDPostalCode, Old: "10001"},
+		}
+		if diff := cmp.Diff(want, Diff(a, b)); diff != "" {
+			t.Errorf("Diff mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		got := Diff(nil, basePayload())
+		if len(got) == 0 || got[0].Old != "" {
+			t.Errorf("Diff(nil, p) = %v, want only additions", got)
+		}
+		if got := Diff(nil, nil); len(got) != 0 {
+			t.Errorf("Diff(nil, nil) = %v, want none", got)
+		}
+	})
+}
+
+func TestFieldChange_String(t *testing.T) {
+	c := FieldChange{Path: "59", Old: "A", New: "B"}
+	assertEqual(t, "String", `59: "A" → "B"`, c.String())
+}
+
+func TestTracker_Changes(t *testing.T) {
+	p := basePayload()
+	tr := Tracked(p)
+	p.MerchantCity = "Boston"
+	want := []FieldChange{{Path: IDMerchantCity, Old: "New York", New: "Boston"}}
+	if diff := cmp.Diff(want, tr.Changes()); diff != "" {
+		t.Errorf("Changes mismatch (-want +got):\n%s", diff)
+	}
+}
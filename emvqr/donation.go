@@ -0,0 +1,164 @@
+package emvqr
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// Merchant Category Codes of organisations that collect donations.
+const (
+	MCCCharitable = "8398" // charitable and social service organisations
+	MCCReligious  = "8661" // religious organisations
+)
+
+// donationMCCs are the Merchant Category Codes ValidateDonation accepts.
+var donationMCCs = []string{MCCCharitable, MCCReligious}
+
+// SuggestedAmountsGUID is the Globally Unique Identifier of the Unreserved
+// Template in which SetSuggestedAmounts stores the amounts a wallet may
+// offer a consumer who enters the amount, one per sub-field from "01".
+const SuggestedAmountsGUID = "COM.GITHUB.EMVQR.SUGGESTED"
+
+// maxSuggestedAmounts is the most suggested amounts SetSuggestedAmounts
+// stores.
+const maxSuggestedAmounts = 5
+
+// donationPurpose is the Tag 62.08 purpose of donation QRs outside India.
+const donationPurpose = "Donation"
+
+// SetSuggestedAmounts stores amounts a wallet may offer as one-tap
+// choices, such as "100", "500" and "1000", for a payload without a
+// Transaction Amount (Tag 54), whose consumer enters the amount. Each must
+// be a decimal amount above 0 of at most 13 characters; up to 5 are
+// stored, in the order given. No amounts removes the suggestions. Errors
+// wrap ErrInvalidFormat and leave the payload unchanged.
+func (p *Payload) SetSuggestedAmounts(amounts ...string) error {
+	if len(amounts) > 0 && p.TransactionAmount != "" {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: suggested amounts need the consumer to enter the amount, but Tag 54 is %q",
+				ErrInvalidFormat, p.TransactionAmount),
+			"tag", IDTransactionAmount, "value", p.TransactionAmount)
+	}
+	if len(amounts) > maxSuggestedAmounts {
+		return fmt.Errorf("%w: at most %d suggested amounts, got %d", ErrInvalidFormat, maxSuggestedAmounts, len(amounts))
+	}
+	subs := make([]DataObject, 0, len(amounts))
+	for i, amount := range amounts {
+		if !validSuggestedAmount(amount) {
+			return fmt.Errorf("%w: suggested amount %q must be a decimal amount above 0 of at most 13 characters",
+				ErrInvalidFormat, amount)
+		}
+		subs = append(subs, DataObject{ID: fmt.Sprintf("%02d", i+1), Value: amount})
+	}
+	return p.setExtension(SuggestedAmountsGUID, subs)
+}
+
+// SuggestedAmounts returns the amounts stored by SetSuggestedAmounts, or
+// nil if there are none or the payload has a Transaction Amount. Malformed
+// entries are skipped.
+func (p *Payload) SuggestedAmounts() []string {
+	if p.TransactionAmount != "" {
+		return nil
+	}
+	var amounts []string
+	for _, sf := range p.extensionFields(SuggestedAmountsGUID) {
+		if validSuggestedAmount(sf.Value) {
+			amounts = append(amounts, sf.Value)
+		}
+	}
+	return amounts
+}
+
+// validSuggestedAmount reports whether s is a decimal amount above 0 of at
+// most 13 characters.
+func validSuggestedAmount(s string) bool {
+	r, ok := new(big.Rat).SetString(s)
+	return ok && isStrictAmount(s) && len(s) <= 13 && r.Sign() > 0
+}
+
+// DonationOptions controls NewDonationPayload.
+type DonationOptions struct {
+	// Purpose is the Purpose of Transaction (Tag 62.08): an NPCI purpose
+	// code or name, such as "others", or, outside India, free text. The
+	// default is UPIPurposeOthers in India and "Donation" elsewhere.
+	Purpose string
+
+	// SuggestedAmounts are offered by wallets as one-tap choices; see
+	// SetSuggestedAmounts.
+	SuggestedAmounts []string
+}
+
+// NewDonationPayload returns a static collection QR for a charity, built
+// from base, which holds its merchant account, name, MCC and location;
+// base is not modified. The QR carries no amount, so the donor enters one,
+// optionally choosing one of the suggested amounts, and names the purpose
+// of the payment in Tag 62.08. The result passes ValidateDonation, or an
+// error is returned.
+func NewDonationPayload(base *Payload, opts DonationOptions) (*Payload, error) {
+	p, err := Merge(base, &Payload{})
+	if err != nil {
+		return nil, err
+	}
+	p.PointOfInitiationMethod = POIStaticQR
+	p.TransactionAmount = ""
+	purpose := opts.Purpose
+	if purpose == "" {
+		purpose = donationPurpose
+		if p.CountryCode == "IN" {
+			purpose = string(UPIPurposeOthers)
+		}
+	}
+	if c, err := ParseUPIPurpose(purpose); err == nil {
+		purpose = string(c)
+	}
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.PurposeOfTransaction = purpose })
+	if err := p.SetSuggestedAmounts(opts.SuggestedAmounts...); err != nil {
+		return nil, err
+	}
+	if err := ValidateDonation(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ValidateDonation checks the rules of a donation QR: the payload must be
+// static (Tag 01 "11"), carry a Merchant Category Code (Tag 52) of a
+// charitable or religious organisation, MCCCharitable or MCCReligious,
+// leave the amount to the donor (no Tag 54) and name the purpose of the
+// payment (Tag 62.08), as an NPCI purpose code in India. Suggested
+// amounts, if any, must be valid. It returns every violation, joined with
+// errors.Join, each wrapping ErrSchemeViolation with its tag in
+// ErrorParams; nil if there are none.
+func ValidateDonation(p *Payload) error {
+	var errs []error
+	fail := func(tag, format string, args ...any) {
+		errs = append(errs, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: donation: "+format, append([]any{ErrSchemeViolation}, args...)...), "tag", tag))
+	}
+	if p.PointOfInitiationMethod != POIStaticQR {
+		fail(IDPointOfInitiationMethod, "Tag 01 must be %q (static QR), got %q",
+			string(POIStaticQR), string(p.PointOfInitiationMethod))
+	}
+	if !slices.Contains(donationMCCs, p.MerchantCategoryCode) {
+		fail(IDMerchantCategoryCode, "Tag 52 MCC must be one of %v, got %q", donationMCCs, p.MerchantCategoryCode)
+	}
+	if p.TransactionAmount != "" {
+		fail(IDTransactionAmount, "Tag 54 amount must be absent, got %q", p.TransactionAmount)
+	}
+	purposeTag := IDAdditionalDataFieldTemplate + "." + ADFPurposeOfTransaction
+	switch {
+	case p.AdditionalData == nil || p.AdditionalData.PurposeOfTransaction == "":
+		fail(purposeTag, "Tag 62.08 must name the purpose of the payment")
+	case p.CountryCode == "IN":
+		if _, ok := p.PurposeCode(); !ok {
+			fail(purposeTag, "Tag 62.08 %q must be an NPCI purpose code in India", p.AdditionalData.PurposeOfTransaction)
+		}
+	}
+	for _, sf := range p.extensionFields(SuggestedAmountsGUID) {
+		if !validSuggestedAmount(sf.Value) {
+			fail(SuggestedAmountsGUID, "suggested amount %q must be a decimal amount above 0", sf.Value)
+		}
+	}
+	return joinErrors(errs)
+}
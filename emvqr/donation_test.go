@@ -0,0 +1,95 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSuggestedAmounts(t *testing.T) {
+	p := basePayload()
+	if err := p.SetSuggestedAmounts("100", "500", "1000.50"); err != nil {
+		t.Fatalf("SetSuggestedAmounts() error: %v", err)
+	}
+	raw := mustEncode(t, p)
+	if !strings.Contains(raw, "0026"+SuggestedAmountsGUID+"0103100"+"0203500"+"03071000.50") {
+		t.Errorf("Encode() = %q, want the suggested amounts template", raw)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	assertEqual(t, "SuggestedAmounts", "100 500 1000.50", strings.Join(decoded.SuggestedAmounts(), " "))
+
+	decoded.TransactionAmount = "10"
+	if got := decoded.SuggestedAmounts(); got != nil {
+		t.Errorf("SuggestedAmounts() with Tag 54 = %q, want nil", got)
+	}
+	if err := decoded.SetSuggestedAmounts("20"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("SetSuggestedAmounts() with Tag 54 error = %v, want ErrInvalidFormat", err)
+	}
+	for _, amounts := range [][]string{{"0"}, {"-1"}, {"1e3"}, {"1", "2", "3", "4", "5", "6"}} {
+		p := basePayload()
+		if err := p.SetSuggestedAmounts(amounts...); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("SetSuggestedAmounts(%q) error = %v, want ErrInvalidFormat", amounts, err)
+		}
+	}
+}
+
+func TestNewDonationPayload(t *testing.T) {
+	base := basePayload()
+	base.MerchantCategoryCode = MCCCharitable
+	base.TransactionAmount = "25.00"
+	p, err := NewDonationPayload(base, DonationOptions{SuggestedAmounts: []string{"10", "25", "50"}})
+	if err != nil {
+		t.Fatalf("NewDonationPayload() error: %v", err)
+	}
+	assertEqual(t, "base amount", "25.00", base.TransactionAmount)
+
+	decoded, err := Decode(mustEncode(t, p))
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if err := ValidateDonation(decoded); err != nil {
+		t.Errorf("ValidateDonation() error: %v", err)
+	}
+	assertEqual(t, "TransactionAmount", "", decoded.TransactionAmount)
+	assertEqual(t, "purpose", "Donation", decoded.AdditionalData.PurposeOfTransaction)
+	assertEqual(t, "SuggestedAmounts", "10 25 50", strings.Join(decoded.SuggestedAmounts(), " "))
+}
+
+func TestNewDonationPayload_India(t *testing.T) {
+	base := basePayload()
+	base.MerchantCategoryCode = MCCReligious
+	base.CountryCode, base.TransactionCurrency = "IN", "356"
+	p, err := NewDonationPayload(base, DonationOptions{})
+	if err != nil {
+		t.Fatalf("NewDonationPayload() error: %v", err)
+	}
+	assertEqual(t, "purpose", string(UPIPurposeOthers), p.AdditionalData.PurposeOfTransaction)
+
+	p, err = NewDonationPayload(base, DonationOptions{Purpose: "education"})
+	if err != nil {
+		t.Fatalf("NewDonationPayload(education) error: %v", err)
+	}
+	assertEqual(t, "purpose", string(UPIPurposeEducation), p.AdditionalData.PurposeOfTransaction)
+
+	if _, err := NewDonationPayload(base, DonationOptions{Purpose: "Temple fund"}); !errors.Is(err, ErrSchemeViolation) {
+		t.Errorf("NewDonationPayload() with free text in India error = %v, want ErrSchemeViolation", err)
+	}
+}
+
+func TestValidateDonation(t *testing.T) {
+	p := basePayload()
+	p.PointOfInitiationMethod = POIDynamicQR
+	p.TransactionAmount = "10"
+	err := ValidateDonation(p)
+	if !errors.Is(err, ErrSchemeViolation) {
+		t.Fatalf("ValidateDonation() error = %v, want ErrSchemeViolation", err)
+	}
+	for _, tag := range []string{IDPointOfInitiationMethod, IDMerchantCategoryCode, IDTransactionAmount, "62.08"} {
+		if !hasErrorTag(err, tag) {
+			t.Errorf("ValidateDonation() error %v has no violation for %s", err, tag)
+		}
+	}
+}
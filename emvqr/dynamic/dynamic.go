@@ -0,0 +1,211 @@
+// Package dynamic models the lifecycle of a dynamic QR: created from a
+// merchant's template payload, issued with an amount, a reference and an
+// expiry, then consumed once paid. Billers keep one QR value per bill
+// instead of mutating payloads ad hoc:
+//
+//	q, _ := dynamic.New(template, dynamic.Options{})
+//	if err := q.Issue("250.00", "INV-2024-0042", time.Now().Add(15*time.Minute)); err != nil {
+//	    return err
+//	}
+//	raw, _ := q.Encode()
+//	state := q.State() // store as JSON until the payment arrives
+//
+//	q, _ = dynamic.Restore(state, dynamic.Options{})
+//	err := q.Consume() // ErrExpired or ErrConsumed if too late
+//
+// A QR is not safe for concurrent use; a biller serving the same bill from
+// several processes must serialize Consume through its own store.
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// Status is the stage of a QR's lifecycle.
+type Status string
+
+// Lifecycle stages.
+const (
+	StatusDraft    Status = "draft"    // created from a template, not yet issued
+	StatusIssued   Status = "issued"   // amount and reference attached; the QR may be shown
+	StatusExpired  Status = "expired"  // issued, but past its expiry
+	StatusConsumed Status = "consumed" // paid
+)
+
+// Lifecycle errors.
+var (
+	ErrNotIssued     = errors.New("dynamic: QR has not been issued")
+	ErrAlreadyIssued = errors.New("dynamic: QR has already been issued")
+	ErrExpired       = errors.New("dynamic: QR has expired")
+	ErrConsumed      = errors.New("dynamic: QR has already been consumed")
+)
+
+// Options configures a QR.
+type Options struct {
+	// Now returns the current time; nil means time.Now. Tests set it to
+	// control expiry.
+	Now func() time.Time
+}
+
+// QR is a dynamic QR and where it is in its lifecycle.
+type QR struct {
+	state State
+	now   func() time.Time
+}
+
+// State is the serialized form of a QR, to store between creating a QR and
+// receiving its payment. It encodes as JSON.
+type State struct {
+	Status     Status         `json:"status"`
+	Payload    *emvqr.Payload `json:"payload"`
+	Raw        string         `json:"raw,omitempty"`
+	Amount     string         `json:"amount,omitempty"`
+	Reference  string         `json:"reference,omitempty"`
+	ExpiresAt  time.Time      `json:"expires_at,omitzero"`
+	ConsumedAt time.Time      `json:"consumed_at,omitzero"`
+}
+
+// New returns a draft QR built from a copy of template, typically the
+// merchant's static payload; template is not modified.
+func New(template *emvqr.Payload, opts Options) (*QR, error) {
+	if template == nil {
+		return nil, errors.New("dynamic: nil template")
+	}
+	p, err := emvqr.Merge(template, &emvqr.Payload{})
+	if err != nil {
+		return nil, fmt.Errorf("dynamic: copying template: %w", err)
+	}
+	return &QR{state: State{Status: StatusDraft, Payload: p}, now: opts.nowFunc()}, nil
+}
+
+// Restore returns the QR whose state was saved by State.
+func Restore(s State, opts Options) (*QR, error) {
+	switch s.Status {
+	case StatusDraft, StatusIssued, StatusConsumed:
+	default:
+		return nil, fmt.Errorf("dynamic: unknown status %q", s.Status)
+	}
+	if s.Payload == nil {
+		return nil, errors.New("dynamic: state has no payload")
+	}
+	if s.Status != StatusDraft && s.Raw == "" {
+		return nil, fmt.Errorf("dynamic: %s state has no encoded QR", s.Status)
+	}
+	return &QR{state: s, now: opts.nowFunc()}, nil
+}
+
+func (o Options) nowFunc() func() time.Time {
+	if o.Now != nil {
+		return o.Now
+	}
+	return time.Now
+}
+
+// Issue attaches the amount, reference and expiry to a draft QR and encodes
+// it. The Point of Initiation Method becomes dynamic; the reference goes
+// into Tags 27.01 and 62.05 of a Bharat QR (see
+// emvqr.Payload.SetTransactionReference) and into Tag 62.05 otherwise. A
+// zero expiresAt means the QR does not expire. On error, including any
+// encoding error, the QR stays a draft.
+func (q *QR) Issue(amount, reference string, expiresAt time.Time) error {
+	if q.state.Status != StatusDraft {
+		return ErrAlreadyIssued
+	}
+	if !expiresAt.IsZero() && !q.now().Before(expiresAt) {
+		return fmt.Errorf("dynamic: expiry %s is not in the future", expiresAt.Format(time.RFC3339))
+	}
+	p, err := emvqr.Merge(q.state.Payload, &emvqr.Payload{})
+	if err != nil {
+		return fmt.Errorf("dynamic: %w", err)
+	}
+	p.TransactionAmount = amount
+	method := p.PointOfInitiationMethod.Method()
+	if method == "" {
+		method = emvqr.POIMethodQR
+	}
+	if err := p.SetPOI(emvqr.POI(string(method) + emvqr.POIDataTypeDynamic)); err != nil {
+		return err
+	}
+	if p.UPIVPAInfo != nil || p.UPITransactionRef != nil {
+		if err := p.SetTransactionReference(reference); err != nil {
+			return err
+		}
+	} else {
+		p.SetAdditionalData(func(a *emvqr.AdditionalDataField) { a.ReferenceLabel = reference })
+	}
+	raw, err := emvqr.EncodeWithOptions(p, emvqr.EncodeOptions{StrictFormats: true})
+	if err != nil {
+		return err
+	}
+	q.state = State{
+		Status:    StatusIssued,
+		Payload:   p,
+		Raw:       raw,
+		Amount:    amount,
+		Reference: reference,
+		ExpiresAt: expiresAt,
+	}
+	return nil
+}
+
+// Status returns the stage of q, StatusExpired once an issued QR is past
+// its expiry.
+func (q *QR) Status() Status {
+	if q.state.Status == StatusIssued && q.expired() {
+		return StatusExpired
+	}
+	return q.state.Status
+}
+
+func (q *QR) expired() bool {
+	return !q.state.ExpiresAt.IsZero() && !q.now().Before(q.state.ExpiresAt)
+}
+
+// check returns the error for a QR that may not be shown or paid.
+func (q *QR) check() error {
+	switch q.Status() {
+	case StatusDraft:
+		return ErrNotIssued
+	case StatusExpired:
+		return ErrExpired
+	case StatusConsumed:
+		return ErrConsumed
+	}
+	return nil
+}
+
+// Encode returns the QR string of an issued QR, failing with ErrNotIssued,
+// ErrExpired or ErrConsumed if it may not be shown.
+func (q *QR) Encode() (string, error) {
+	if err := q.check(); err != nil {
+		return "", err
+	}
+	return q.state.Raw, nil
+}
+
+// Consume marks an issued QR as paid, failing with ErrNotIssued, ErrExpired
+// or ErrConsumed otherwise, so that a second payment against the same QR
+// is caught.
+func (q *QR) Consume() error {
+	if err := q.check(); err != nil {
+		return err
+	}
+	q.state.Status = StatusConsumed
+	q.state.ConsumedAt = q.now()
+	return nil
+}
+
+// Payload returns the payload of q: the template for a draft, the issued
+// payload otherwise. It must not be modified.
+func (q *QR) Payload() *emvqr.Payload {
+	return q.state.Payload
+}
+
+// State returns the serialized form of q; Restore reverses it.
+func (q *QR) State() State {
+	return q.state
+}
@@ -0,0 +1,143 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func bharatTemplate(t *testing.T) *emvqr.Payload {
+	t.Helper()
+	p := emvqr.NewPayload()
+	p.PointOfInitiationMethod = emvqr.POIStaticQR
+	if err := p.SetUPIVPATemplate(emvqr.RuPayRIDValue, "sharma@upi", ""); err != nil {
+		t.Fatal(err)
+	}
+	p.MerchantCategoryCode = "5411"
+	p.TransactionCurrency = "356"
+	p.CountryCode = "IN"
+	p.MerchantName = "Sharma Stores"
+	p.MerchantCity = "Mumbai"
+	return p
+}
+
+func TestLifecycle(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	opts := Options{Now: func() time.Time { return now }}
+	template := bharatTemplate(t)
+
+	q, err := New(template, opts)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := q.Encode(); !errors.Is(err, ErrNotIssued) {
+		t.Errorf("Encode() of a draft error = %v, want ErrNotIssued", err)
+	}
+	if err := q.Issue("250.00", "INV-0042", now.Add(15*time.Minute)); err != nil {
+		t.Fatalf("Issue() error: %v", err)
+	}
+	if template.TransactionAmount != "" || template.PointOfInitiationMethod != emvqr.POIStaticQR {
+		t.Errorf("Issue() modified the template: %+v", template)
+	}
+	raw, err := q.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	p, err := emvqr.Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if p.TransactionAmount != "250.00" || p.PointOfInitiationMethod != emvqr.POIDynamicQR ||
+		p.GetTransactionReference() != "INV-0042" || p.AdditionalData.ReferenceLabel != "INV-0042" {
+		t.Errorf("issued payload = %+v", p)
+	}
+	if err := q.Issue("1.00", "INV-0043", time.Time{}); !errors.Is(err, ErrAlreadyIssued) {
+		t.Errorf("second Issue() error = %v, want ErrAlreadyIssued", err)
+	}
+
+	data, err := json.Marshal(q.State())
+	if err != nil {
+		t.Fatalf("Marshal(State) error: %v", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("Unmarshal(State) error: %v", err)
+	}
+	restored, err := Restore(s, opts)
+	if err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if got, _ := restored.Encode(); got != raw {
+		t.Errorf("restored Encode() = %q, want %q", got, raw)
+	}
+
+	if err := restored.Consume(); err != nil {
+		t.Fatalf("Consume() error: %v", err)
+	}
+	if err := restored.Consume(); !errors.Is(err, ErrConsumed) {
+		t.Errorf("second Consume() error = %v, want ErrConsumed", err)
+	}
+	if restored.Status() != StatusConsumed || !restored.State().ConsumedAt.Equal(now) {
+		t.Errorf("consumed state = %+v", restored.State())
+	}
+
+	now = now.Add(time.Hour)
+	if q.Status() != StatusExpired {
+		t.Errorf("Status() after expiry = %s", q.Status())
+	}
+	if err := q.Consume(); !errors.Is(err, ErrExpired) {
+		t.Errorf("Consume() after expiry error = %v, want ErrExpired", err)
+	}
+}
+
+func TestIssue_NonBharat(t *testing.T) {
+	p := emvqr.NewPayload()
+	p.AddMerchantIdentifier("02", "4000123456789012")
+	p.MerchantCategoryCode = "5251"
+	p.TransactionCurrency = "840"
+	p.CountryCode = "US"
+	p.MerchantName = "ABC Hammers"
+	p.MerchantCity = "New York"
+
+	q, _ := New(p, Options{})
+	if err := q.Issue("10", "ORDER-7", time.Time{}); err != nil {
+		t.Fatalf("Issue() error: %v", err)
+	}
+	issued := q.Payload()
+	if issued.UPITransactionRef != nil || issued.AdditionalData.ReferenceLabel != "ORDER-7" {
+		t.Errorf("issued payload = %+v", issued)
+	}
+	if q.Status() != StatusIssued {
+		t.Errorf("Status() = %s, want issued (no expiry)", q.Status())
+	}
+}
+
+func TestIssue_Errors(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	q, _ := New(bharatTemplate(t), Options{Now: func() time.Time { return now }})
+	for _, tt := range []struct {
+		amount, ref string
+		expires     time.Time
+	}{
+		{"12,50", "INV-0042", time.Time{}},
+		{"12.50", "X", time.Time{}},
+		{"12.50", "INV-0042", now},
+	} {
+		if err := q.Issue(tt.amount, tt.ref, tt.expires); err == nil {
+			t.Errorf("Issue(%q, %q, %v) expected error, got nil", tt.amount, tt.ref, tt.expires)
+		}
+		if q.Status() != StatusDraft || q.Payload().TransactionAmount != "" {
+			t.Errorf("failed Issue() left state %+v", q.State())
+		}
+	}
+
+	if _, err := Restore(State{Status: "paid"}, Options{}); err == nil {
+		t.Error("Restore() of an unknown status expected error, got nil")
+	}
+	if _, err := Restore(State{Status: StatusIssued, Payload: emvqr.NewPayload()}, Options{}); err == nil {
+		t.Error("Restore() of an issued state without a QR expected error, got nil")
+	}
+}
@@ -77,12 +77,12 @@ const (
 	POIDataTypeDynamic = "2" // Dynamic QR code (per-transaction)
 
 	// Common POI combined values
-	POIStaticQR   = "11" // Static QR code
-	POIDynamicQR  = "12" // Dynamic QR code
-	POIStaticBLE  = "21" // Static BLE
-	POIDynamicBLE = "22" // Dynamic BLE
-	POIStaticNFC  = "31" // Static NFC
-	POIDynamicNFC = "32" // Dynamic NFC
+	POIStaticQR   POI = "11" // Static QR code
+	POIDynamicQR  POI = "12" // Dynamic QR code
+	POIStaticBLE  POI = "21" // Static BLE
+	POIDynamicBLE POI = "22" // Dynamic BLE
+	POIStaticNFC  POI = "31" // Static NFC
+	POIDynamicNFC POI = "32" // Dynamic NFC
 )
 
 // RuPay Application Provider Identifier (AID) constants
@@ -102,6 +102,8 @@ const (
 	ADFTerminalLabel                 = "07"
 	ADFPurposeOfTransaction          = "08"
 	ADFAdditionalConsumerDataRequest = "09"
+	ADFMerchantTaxID                 = "10" // EMV QRCPS MPM v1.1
+	ADFMerchantChannel               = "11" // EMV QRCPS MPM v1.1
 )
 
 // Subfield IDs for Merchant Information – Language Template (ID "64")
@@ -160,6 +162,16 @@ type AdditionalDataField struct {
 	PurposeOfTransaction          string
 	AdditionalConsumerDataRequest string
 
+	// MerchantTaxID and MerchantChannel (sub-IDs "10" and "11") are defined
+	// by EMV QRCPS MPM v1.1 and are only populated when decoding with
+	// SpecVersion11; under v1.0 they are kept in RFUFields.
+	MerchantTaxID   string
+	MerchantChannel string // 3 chars: media, transaction location, merchant presence
+
+	// PaymentSystemTemplates holds payment system specific templates
+	// (sub-IDs "50"–"99", EMV QRCPS MPM v1.1).
+	PaymentSystemTemplates []UnreservedTemplate
+
 	// RFUFields holds any unrecognised sub-fields for forward compatibility.
 	RFUFields []DataObject
 }
@@ -219,7 +231,7 @@ type Payload struct {
 
 	// PointOfInitiationMethod (ID "01", Bharat QR) indicates how the QR was initiated.
 	// Format: "XY" where X is method (1=QR, 2=BLE, 3=NFC), Y is data type (1=static, 2=dynamic).
-	PointOfInitiationMethod string
+	PointOfInitiationMethod POI
 
 	// MerchantIdentifiers contains merchant identifiers for supported payment networks (IDs "02"–"25").
 	// Per EMV QRCPS spec, at least one merchant identifier is mandatory.
@@ -264,6 +276,18 @@ type Payload struct {
 
 	// RFUFields holds any unrecognised top-level fields.
 	RFUFields []DataObject
+
+	// RFUAfter holds, for each of RFUFields by index, the ID of the
+	// top-level field it followed in the decoded string, e.g. "58", so that
+	// Encode writes it back in place and the CRC is unchanged. An RFU field
+	// without one, or whose preceding field is no longer encoded, is written
+	// after the Unreserved Templates. Canonical encoding ignores it.
+	RFUAfter []string `json:",omitempty"`
+
+	// RawSegments holds, when decoded with DecodeOptions.CaptureRaw, where
+	// each data object came from in the raw string, in input order. Encode
+	// ignores it; see RawSegment.
+	RawSegments []RawSegment `json:"-"`
 }
 
 // -------------------------------------------------------------------------
@@ -280,8 +304,66 @@ var (
 	ErrCRCMismatch = errors.New("emvqr: CRC mismatch")
 	// ErrMissingRequired is returned when a required field is missing.
 	ErrMissingRequired = errors.New("emvqr: missing required field")
+	// ErrUnsupportedVersion is returned when a payload or field is not defined
+	// by the selected EMV QRCPS spec version.
+	ErrUnsupportedVersion = errors.New("emvqr: unsupported spec version")
+	// ErrInvalidFormat is returned when a field value violates the format or
+	// length rules of its data object.
+	ErrInvalidFormat = errors.New("emvqr: invalid field format")
+	// ErrMergeConflict is returned by MergeWithOptions when base and overlay
+	// set a field to different values under MergeRejectConflict.
+	ErrMergeConflict = errors.New("emvqr: conflicting field values")
+	// ErrBelowMinimum is returned by ValidateAmountAgainstMinimum when the
+	// transaction amount is less than the UPI minimum amount.
+	ErrBelowMinimum = errors.New("emvqr: amount below minimum")
+	// ErrMerchantUnverified is returned by DecodeContext when the
+	// DecodeOptions.VerifyMerchant hook rejects the merchant.
+	ErrMerchantUnverified = errors.New("emvqr: merchant verification failed")
+	// ErrLimitExceeded is returned by Decode when a payload exceeds one of
+	// the DecodeOptions.Limits.
+	ErrLimitExceeded = errors.New("emvqr: decoder limit exceeded")
+	// ErrRoundTrip is returned by CheckRoundTrip when a payload does not
+	// survive being encoded and decoded again.
+	ErrRoundTrip = errors.New("emvqr: payload does not round-trip")
+	// ErrNoPaymentOption is returned by SelectNetwork when none of the
+	// payload's merchant accounts suits the consumer.
+	ErrNoPaymentOption = errors.New("emvqr: no payment option suits the consumer")
+	// ErrTagNotFound is returned when an operation names a data object, such
+	// as a merchant identifier, that the payload does not hold.
+	ErrTagNotFound = errors.New("emvqr: tag not found")
+
+	// The errors below refine a broader error, which errors.Is also
+	// matches and whose Code ErrorCode reports.
+
+	// ErrFieldTooLong is returned when a value does not fit the two-digit
+	// length of its data object. It refines ErrInvalidFormat.
+	ErrFieldTooLong error = &refinedError{"emvqr: field value too long", ErrInvalidFormat}
+	// ErrInvalidTagID is returned when a data object, template or
+	// sub-field is given an ID outside the range its kind allows. It
+	// refines ErrInvalidFormat.
+	ErrInvalidTagID error = &refinedError{"emvqr: invalid tag ID", ErrInvalidFormat}
+	// ErrDuplicateTag is returned when a tag ID that may appear only once
+	// is added again. It refines ErrInvalidFormat.
+	ErrDuplicateTag error = &refinedError{"emvqr: duplicate tag ID", ErrInvalidFormat}
+	// ErrSchemeViolation is returned when a payload is well formed EMV
+	// QRCPS but breaks a rule of a payment scheme, such as the Bharat QR
+	// and NPCI rules for Tags 01 and 26–28. It refines ErrInvalidFormat.
+	ErrSchemeViolation error = &refinedError{"emvqr: payment scheme rule violated", ErrInvalidFormat}
+	// ErrFeeCapExceeded is returned when a convenience fee is over the cap
+	// a FeePolicy sets. It refines ErrSchemeViolation.
+	ErrFeeCapExceeded error = &refinedError{"emvqr: convenience fee over cap", ErrSchemeViolation}
 )
 
+// refinedError is a sentinel error that is a more specific kind of parent.
+type refinedError struct {
+	msg    string
+	parent error
+}
+
+func (e *refinedError) Error() string { return e.msg }
+
+func (e *refinedError) Unwrap() error { return e.parent }
+
 // ParseError is returned when a specific field cannot be parsed.
 type ParseError struct {
 	ID  string
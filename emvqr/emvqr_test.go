@@ -1,6 +1,8 @@
 package emvqr
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -550,3 +552,38 @@ func assertEqual(t *testing.T, field, want, got string) {
 		t.Errorf("%s: want %q, got %q", field, want, got)
 	}
 }
+
+func TestDecodeContextVerifyMerchant(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "registry")
+	blocked := errors.New("merchant not in registry")
+	opts := DecodeOptions{VerifyMerchant: func(ctx context.Context, p *Payload) error {
+		if ctx.Value(ctxKey{}) != "registry" {
+			t.Error("context not passed to VerifyMerchant")
+		}
+		if p.MerchantName == "ABC Hammers" {
+			return blocked
+		}
+		return nil
+	}}
+
+	p, err := DecodeContext(ctx, baseRaw, opts)
+	if !errors.Is(err, ErrMerchantUnverified) || !errors.Is(err, blocked) {
+		t.Fatalf("want ErrMerchantUnverified wrapping the hook error, got %v", err)
+	}
+	if ErrorCode(err) != CodeMerchantUnverified {
+		t.Errorf("code = %s", ErrorCode(err))
+	}
+	if p == nil || p.MerchantName != "ABC Hammers" {
+		t.Errorf("payload not returned with the verification error: %+v", p)
+	}
+
+	// The hook is not reached when parsing fails.
+	if _, err := DecodeContext(ctx, baseRaw[:len(baseRaw)-1]+"0", opts); !errors.Is(err, ErrCRCMismatch) {
+		t.Errorf("want ErrCRCMismatch, got %v", err)
+	}
+	opts.VerifyMerchant = func(context.Context, *Payload) error { return nil }
+	if _, err := DecodeWithOptions(baseRaw, opts); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,192 +1,520 @@
 package emvqr
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
-	"strings"
 )
 
 // EncodeOptions controls optional encoder behaviour.
 type EncodeOptions struct {
-	// PayloadFormatIndicator overrides the default "01".
+	// PayloadFormatIndicator overrides the default "01". Like a value set on
+	// the Payload, it is rejected with ErrUnsupportedVersion unless
+	// SpecVersion defines it or AllowFutureFormatIndicator is set.
 	PayloadFormatIndicator string
+
+	// AllowFutureFormatIndicator permits a Payload Format Indicator that
+	// SpecVersion does not define, to deliberately emit payloads for a
+	// later EMV QRCPS revision, e.g. for certification of a pilot.
+	AllowFutureFormatIndicator bool
+
+	// SpecVersion selects the EMV QRCPS MPM revision to encode against.
+	// Fields introduced in v1.1 are rejected with ErrUnsupportedVersion when
+	// encoding for v1.0.
+	SpecVersion SpecVersion
+
+	// BillNumberFormat and ReferenceLabelFormat, if set, validate the
+	// Tag 62.01 Bill Number and Tag 62.05 Reference Label, e.g. against an
+	// acquirer's reconciliation format; see ReferenceFormat. A violation is
+	// returned wrapping ErrInvalidFormat, with the tag in ErrorParams.
+	BillNumberFormat     FormatValidator
+	ReferenceLabelFormat FormatValidator
+
+	// Canonical encodes in canonical order (see CanonicalVersion), so that
+	// the same Payload yields the same string and CRC whatever the order of
+	// its slices.
+	Canonical bool
+
+	// AllErrors reports every reason a payload cannot be encoded, joined
+	// with errors.Join, instead of only the first, so that a form can mark
+	// all of its bad inputs at once. Each field and template is checked on
+	// its own, in the order Encode writes them. errors.Is, errors.As and
+	// ErrorCode see each of the joined errors.
+	AllErrors bool
+
+	// Shortener, if set, shortens a Tag 27 Reference URL longer than the 26
+	// characters of Bharat QR, so that full invoice links can be set on the
+	// payload. The payload itself is not modified. EncodeContext passes its
+	// context to it. A failure, or a result still over 26 characters, is
+	// returned wrapping ErrSchemeViolation.
+	Shortener URLShortener
+
+	// StrictCharset rejects, with ErrInvalidFormat, fields with characters
+	// outside the EMV Common Character Set, such as emoji or accented
+	// letters, which some terminal scanners cannot read. The Language
+	// Template's merchant name and city are exempt. Lint reports such
+	// fields either way.
+	StrictCharset bool
+
+	// StrictFormats rejects typed fields that EMV QRCPS defines as numeric
+	// or as amounts but that hold anything else, such as an MCC of "ABCD",
+	// and postal codes not matching their country; see
+	// Payload.ValidateFieldFormats.
+	StrictFormats bool
+
+	// FeePolicy, if set, rejects a convenience fee over the cap it sets
+	// for the payload's Merchant Category Code; see CheckFeeCap.
+	FeePolicy *FeePolicy
 }
 
 // Encode serialises a Payload into a raw EMV QR Code string, computing and
 // appending the CRC automatically.
 //
 // Required fields: at least one MerchantAccountInfo, MerchantCategoryCode,
-// TransactionCurrency, CountryCode, MerchantName, and MerchantCity.
+// TransactionCurrency, CountryCode, MerchantName, and MerchantCity. A
+// person-to-person QR (see Payload.IsPersonToPerson) needs only a UPI VPA,
+// TransactionCurrency, CountryCode and MerchantName.
 func Encode(p *Payload) (string, error) {
 	return EncodeWithOptions(p, EncodeOptions{})
 }
 
-// EncodeWithOptions serialises a Payload using the given options.
+// EncodeWithOptions serialises a Payload using the given options. It is
+// EncodeContext with context.Background().
+//
+// The payload is written into a single buffer of EstimateEncodedLength
+// bytes, so a successful encode allocates only that buffer and the returned
+// string.
 func EncodeWithOptions(p *Payload, opts EncodeOptions) (string, error) {
-	if err := validatePayload(p); err != nil {
+	return EncodeContext(context.Background(), p, opts)
+}
+
+// encode is EncodeWithOptions once the Reference URL is shortened.
+func encode(p *Payload, opts EncodeOptions) (string, error) {
+	buf, err := appendPayload(make([]byte, 0, EstimateEncodedLength(p)), p, opts)
+	if err != nil {
+		if opts.AllErrors {
+			if errs := encodeErrors(p, opts); len(errs) > 1 {
+				return "", errors.Join(errs...)
+			}
+		}
 		return "", err
 	}
+	return string(buf), nil
+}
 
-	var sb strings.Builder
+// CanEncode reports whether Encode accepts p, returning the error Encode
+// would return. Use it as a pre-flight check on a payload assembled or
+// edited from user input, where a merchant name over 99 bytes or template
+// sub-fields that together exceed 99 bytes are rejected.
+func CanEncode(p *Payload) error {
+	_, err := Encode(p)
+	return err
+}
 
-	// --- Payload Format Indicator (ID "00") --- always first
-	pfi := p.PayloadFormatIndicator
-	if pfi == "" {
-		pfi = "01"
+// EstimateEncodedLength returns an upper bound on the length of the encoded
+// payload, including the CRC. It is exact for payloads that encode, except
+// that fields Encode omits, such as a convenience fee value that does not
+// match the indicator, are still counted. It returns 0 for a nil payload.
+func EstimateEncodedLength(p *Payload) int {
+	if p == nil {
+		return 0
 	}
-	if opts.PayloadFormatIndicator != "" {
-		pfi = opts.PayloadFormatIndicator
+	n := 0
+	field := func(value string) {
+		if value != "" {
+			n += 4 + len(value)
+		}
 	}
-	write(&sb, IDPayloadFormatIndicator, pfi)
+	objects := func(list []DataObject) {
+		for _, d := range list {
+			n += 4 + len(d.Value)
+		}
+	}
+	n += 4 + max(len(p.PayloadFormatIndicator), 2)
+	field(string(p.PointOfInitiationMethod))
+	for _, mi := range p.MerchantIdentifiers {
+		if mi.ID != "26" && mi.ID != "27" && mi.ID != "28" {
+			n += 4 + len(mi.Value)
+			continue
+		}
+		// Empty sub-fields kept from decoding are re-encoded.
+		for _, sf := range mi.SubFields {
+			if sf.Value == "" {
+				n += 4
+			}
+		}
+	}
+	for _, v := range []string{
+		p.MerchantCategoryCode, p.TransactionCurrency, p.TransactionAmount,
+		p.TipOrConvenienceIndicator, p.ValueConvenienceFeeFixed, p.ValueConvenienceFeePercent,
+		p.CountryCode, p.MerchantName, p.MerchantCity, p.PostalCode,
+	} {
+		field(v)
+	}
+	if v := p.UPIVPAInfo; v != nil {
+		n += 4
+		field(v.RuPayRID)
+		field(v.VPA)
+		field(v.MinimumAmount)
+	}
+	if r := p.UPITransactionRef; r != nil {
+		n += 4
+		field(r.RuPayRID)
+		field(r.TransactionRef)
+		field(r.ReferenceURL)
+	}
+	if a := p.MerchantAadhaar; a != nil {
+		n += 4
+		field(a.RuPayRID)
+		field(a.AadhaarNumber)
+	}
+	if a := p.AdditionalData; a != nil {
+		n += 4
+		for _, v := range []string{
+			a.BillNumber, a.MobileNumber, a.StoreLabel, a.LoyaltyNumber, a.ReferenceLabel,
+			a.CustomerLabel, a.TerminalLabel, a.PurposeOfTransaction,
+			a.AdditionalConsumerDataRequest, a.MerchantTaxID, a.MerchantChannel,
+		} {
+			field(v)
+		}
+		for _, pst := range a.PaymentSystemTemplates {
+			n += 4
+			field(pst.GloballyUniqueID)
+			objects(pst.SubFields)
+		}
+		objects(a.RFUFields)
+	}
+	if lt := p.LanguageTemplate; lt != nil {
+		n += 4
+		field(lt.LanguagePreference)
+		field(lt.MerchantName)
+		field(lt.MerchantCity)
+		objects(lt.RFUFields)
+	}
+	for _, ut := range p.UnreservedTemplates {
+		n += 4
+		field(ut.GloballyUniqueID)
+		objects(ut.SubFields)
+	}
+	objects(p.RFUFields)
+	return n + 8 // CRC
+}
+
+// appendPayload validates p and appends the encoded payload, CRC included,
+// to buf.
+func appendPayload(buf []byte, p *Payload, opts EncodeOptions) ([]byte, error) {
+	if err := validatePayload(p); err != nil {
+		return nil, err
+	}
+	if !opts.SpecVersion.valid() {
+		return nil, unknownSpecVersion(opts.SpecVersion)
+	}
+	if err := checkReferenceFormats(p, opts); err != nil {
+		return nil, err
+	}
+	if errs := urlErrors(p); len(errs) > 0 {
+		return nil, errs[0]
+	}
+	if opts.StrictCharset {
+		if errs := charsetErrors(p); len(errs) > 0 {
+			return nil, errs[0]
+		}
+	}
+	if opts.StrictFormats {
+		if errs := fieldFormatErrors(p); len(errs) > 0 {
+			return nil, errs[0]
+		}
+	}
+	if err := CheckFeeCap(p, opts.FeePolicy); err != nil {
+		return nil, err
+	}
+	if opts.Canonical {
+		p = canonicalPayload(p)
+	}
+	start := len(buf)
+	var err error
+	typedDone := false
+	// write appends a primitive top-level field, keeping the first error in
+	// werr so that the fields read as a list; werr is checked after each
+	// run of writes.
+	var werr error
+	write := func(id, value string) {
+		if werr != nil {
+			return
+		}
+		if buf, werr = appendTLV(buf, id, value); werr != nil {
+			werr = fmt.Errorf("emvqr: encoding field %s: %w", id, werr)
+		}
+	}
+
+	// --- Payload Format Indicator (ID "00") --- always first
+	pfi, err := encodeFormatIndicator(p, opts)
+	if err != nil {
+		return nil, err
+	}
+	write(IDPayloadFormatIndicator, pfi)
 
 	// --- Point of Initiation Method (ID "01") — optional (Bharat QR) ---
 	if p.PointOfInitiationMethod != "" {
-		write(&sb, IDPointOfInitiationMethod, p.PointOfInitiationMethod)
+		write(IDPointOfInitiationMethod, string(p.PointOfInitiationMethod))
+	}
+
+	if werr != nil {
+		return nil, werr
 	}
 
 	// --- Merchant Identifiers (IDs "02"–"25") ---
 	// Skip Tags 26, 27, 28 as they are encoded separately from typed fields
 	for _, mi := range p.MerchantIdentifiers {
 		if mi.ID == "26" || mi.ID == "27" || mi.ID == "28" {
-			continue // These are encoded from typed fields below
+			continue // These are encoded from the typed fields below
 		}
-		chunk, err := encodeTLV(mi.ID, mi.Value)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: encoding merchant identifier %s: %w", mi.ID, err)
+		if opts.Canonical && mi.ID > "28" && !typedDone {
+			if buf, err = appendTypedMerchantTemplates(buf, p); err != nil {
+				return nil, err
+			}
+			typedDone = true
+		}
+		if buf, err = appendTLV(buf, mi.ID, mi.Value); err != nil {
+			return nil, fmt.Errorf("emvqr: encoding merchant identifier %s: %w", mi.ID, err)
 		}
-		sb.WriteString(chunk)
+	}
+	if opts.Canonical && !typedDone {
+		if buf, err = appendTypedMerchantTemplates(buf, p); err != nil {
+			return nil, err
+		}
+		typedDone = true
 	}
 
-	// --- Merchant Category Code (ID "52") ---
-	write(&sb, IDMerchantCategoryCode, p.MerchantCategoryCode)
+	// --- Merchant Category Code (ID "52") — absent only in P2P QRs ---
+	if p.MerchantCategoryCode != "" {
+		write(IDMerchantCategoryCode, p.MerchantCategoryCode)
+	}
 
 	// --- Transaction Currency (ID "53") ---
-	write(&sb, IDTransactionCurrency, p.TransactionCurrency)
+	write(IDTransactionCurrency, p.TransactionCurrency)
 
 	// --- Transaction Amount (ID "54") — optional ---
 	if p.TransactionAmount != "" {
-		write(&sb, IDTransactionAmount, p.TransactionAmount)
+		write(IDTransactionAmount, p.TransactionAmount)
 	}
 
 	// --- Tip or Convenience Indicator (ID "55") — optional ---
 	if p.TipOrConvenienceIndicator != "" {
-		write(&sb, IDTipOrConvenienceIndicator, p.TipOrConvenienceIndicator)
+		write(IDTipOrConvenienceIndicator, p.TipOrConvenienceIndicator)
 		switch p.TipOrConvenienceIndicator {
 		case TipIndicatorFixedConvenienceFee:
 			if p.ValueConvenienceFeeFixed != "" {
-				write(&sb, IDValueConvenienceFeeFixed, p.ValueConvenienceFeeFixed)
+				write(IDValueConvenienceFeeFixed, p.ValueConvenienceFeeFixed)
 			}
 		case TipIndicatorPercentageFee:
 			if p.ValueConvenienceFeePercent != "" {
-				write(&sb, IDValueConvenienceFeePercent, p.ValueConvenienceFeePercent)
+				write(IDValueConvenienceFeePercent, p.ValueConvenienceFeePercent)
 			}
 		}
 	}
 
 	// --- Country Code (ID "58") ---
-	write(&sb, IDCountryCode, p.CountryCode)
+	write(IDCountryCode, p.CountryCode)
 
 	// --- Merchant Name (ID "59") ---
-	write(&sb, IDMerchantName, p.MerchantName)
+	write(IDMerchantName, p.MerchantName)
 
-	// --- Merchant City (ID "60") ---
-	write(&sb, IDMerchantCity, p.MerchantCity)
+	// --- Merchant City (ID "60") — absent only in P2P QRs ---
+	if p.MerchantCity != "" {
+		write(IDMerchantCity, p.MerchantCity)
+	}
 
 	// --- Postal Code (ID "61") — optional ---
 	if p.PostalCode != "" {
-		write(&sb, IDPostalCode, p.PostalCode)
-	}
-
-	// --- UPI VPA Template (ID "26") — optional (Bharat QR) ---
-	if p.UPIVPAInfo != nil {
-		chunk, err := encodeUPIVPATemplate(p.UPIVPAInfo)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: encoding UPI VPA template: %w", err)
-		}
-		sb.WriteString(chunk)
+		write(IDPostalCode, p.PostalCode)
 	}
 
-	// --- UPI VPA Reference Template (ID "27") — optional (Bharat QR dynamic) ---
-	if p.UPITransactionRef != nil {
-		chunk, err := encodeUPIVPAReference(p.UPITransactionRef)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: encoding UPI VPA reference: %w", err)
-		}
-		sb.WriteString(chunk)
+	if werr != nil {
+		return nil, werr
 	}
 
-	// --- Aadhaar Template (ID "28") — optional (Bharat QR) ---
-	if p.MerchantAadhaar != nil {
-		chunk, err := encodeAadhaarInfo(p.MerchantAadhaar)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: encoding Aadhaar info: %w", err)
+	// --- UPI VPA, UPI VPA Reference and Aadhaar Templates (IDs "26"–"28") ---
+	// Optional (Bharat QR); canonical encoding has already written them.
+	if !typedDone {
+		if buf, err = appendTypedMerchantTemplates(buf, p); err != nil {
+			return nil, err
 		}
-		sb.WriteString(chunk)
 	}
 
 	// --- Additional Data Field Template (ID "62") — optional ---
 	if p.AdditionalData != nil {
-		chunk, err := encodeAdditionalDataField(p.AdditionalData)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: encoding additional data field: %w", err)
+		if buf, err = appendAdditionalDataField(buf, p.AdditionalData, opts.SpecVersion); err != nil {
+			return nil, fmt.Errorf("emvqr: encoding additional data field: %w", err)
 		}
-		sb.WriteString(chunk)
 	}
 
 	// --- Merchant Information Language Template (ID "64") — optional ---
 	if p.LanguageTemplate != nil {
-		chunk, err := encodeLanguageTemplate(p.LanguageTemplate)
+		start := len(buf)
+		buf = beginTemplate(buf, IDMerchantInfoLanguageTemplate)
+		if buf, err = appendLanguageFields(buf, p.LanguageTemplate); err == nil {
+			buf, err = endTemplate(buf, start)
+		}
 		if err != nil {
-			return "", fmt.Errorf("emvqr: encoding language template: %w", err)
+			return nil, fmt.Errorf("emvqr: encoding language template: %w", err)
 		}
-		sb.WriteString(chunk)
 	}
 
-	// --- Unreserved Templates (IDs "80"–"99") — optional ---
+	// --- Unreserved Templates (IDs "80"–"99") and RFU fields ---
+	// RFU fields (IDs "65"–"79") come first only in canonical order.
+	if opts.Canonical {
+		for _, rfu := range p.RFUFields {
+			write(rfu.ID, rfu.Value)
+		}
+		if werr != nil {
+			return nil, werr
+		}
+	}
 	for _, ut := range p.UnreservedTemplates {
-		chunk, err := encodeUnreservedTemplate(ut)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: encoding unreserved template %s: %w", ut.ID, err)
+		if buf, err = appendUnreservedTemplate(buf, ut); err != nil {
+			return nil, fmt.Errorf("emvqr: encoding unreserved template %s: %w", ut.ID, err)
 		}
-		sb.WriteString(chunk)
 	}
-
-	// --- RFU fields ---
-	for _, rfu := range p.RFUFields {
-		write(&sb, rfu.ID, rfu.Value)
+	placed := false
+	if !opts.Canonical {
+		for i, rfu := range p.RFUFields {
+			if p.rfuAfter(i) != "" {
+				placed = true
+				continue
+			}
+			write(rfu.ID, rfu.Value)
+		}
+		if werr != nil {
+			return nil, werr
+		}
+	}
+	if placed {
+		if buf, err = placeRFUFields(buf, start, p); err != nil {
+			return nil, err
+		}
 	}
 
 	// --- CRC (ID "63") — computed last, always appended ---
 	// The CRC covers everything up to and including the "6304" prefix.
-	crcPrefix := sb.String() + "6304"
-	crcVal := crc16CCITT([]byte(crcPrefix))
-	sb.WriteString("6304")
-	sb.WriteString(crcString(crcVal))
+	buf = append(buf, "6304"...)
+	return appendCRC(buf, crc16CCITT(buf[start:])), nil
+}
+
+// rfuAfter returns the ID of the field the i-th RFU field of p follows, or
+// "" if it has no recorded position.
+func (p *Payload) rfuAfter(i int) string {
+	if i < len(p.RFUAfter) {
+		return p.RFUAfter[i]
+	}
+	return ""
+}
+
+// placeRFUFields inserts into the top-level objects of buf[start:] each RFU
+// field of p with a recorded position right after the field it followed,
+// appending those whose preceding field is not there.
+func placeRFUFields(buf []byte, start int, p *Payload) ([]byte, error) {
+	body := slices.Clone(buf[start:])
+	buf = buf[:start]
+	done := make([]bool, len(p.RFUFields))
+	var err error
+	var follow func(id string)
+	follow = func(id string) {
+		for i, rfu := range p.RFUFields {
+			if done[i] || err != nil || p.rfuAfter(i) != id {
+				continue
+			}
+			done[i] = true
+			if buf, err = appendTLV(buf, rfu.ID, rfu.Value); err != nil {
+				err = fmt.Errorf("emvqr: encoding field %s: %w", rfu.ID, err)
+				return
+			}
+			follow(rfu.ID)
+		}
+	}
+	for off := 0; off+4 <= len(body); {
+		id := string(body[off : off+2])
+		end := off + 4 + int(body[off+2]-'0')*10 + int(body[off+3]-'0')
+		buf = append(buf, body[off:end]...)
+		follow(id)
+		off = end
+	}
+	for i := range done {
+		if !done[i] && err == nil {
+			follow(p.rfuAfter(i))
+		}
+	}
+	return buf, err
+}
+
+// appendTypedMerchantTemplates appends the templates encoded from the typed
+// UPIVPAInfo, UPITransactionRef and MerchantAadhaar fields, in ID order.
+func appendTypedMerchantTemplates(buf []byte, p *Payload) ([]byte, error) {
+	var err error
+	if p.UPIVPAInfo != nil {
+		if buf, err = appendUPIVPATemplate(buf, p.UPIVPAInfo, p.decodedSubFields(IDUPIVPATemplate)); err != nil {
+			return nil, fmt.Errorf("emvqr: encoding UPI VPA template: %w", err)
+		}
+	}
+	if p.UPITransactionRef != nil {
+		if buf, err = appendUPIVPAReference(buf, p.UPITransactionRef, p.decodedSubFields(IDUPIVPAReference)); err != nil {
+			return nil, fmt.Errorf("emvqr: encoding UPI VPA reference: %w", err)
+		}
+	}
+	if p.MerchantAadhaar != nil {
+		if buf, err = appendAadhaarInfo(buf, p.MerchantAadhaar, p.decodedSubFields(IDAadhaarTemplate)); err != nil {
+			return nil, fmt.Errorf("emvqr: encoding Aadhaar info: %w", err)
+		}
+	}
+	return buf, nil
+}
 
-	return sb.String(), nil
+// beginTemplate appends the ID of a template and a placeholder length, to be
+// filled in by endTemplate once the sub-fields have been appended.
+func beginTemplate(buf []byte, id string) []byte {
+	return append(buf, id[0], id[1], '0', '0')
 }
 
-// write appends a TLV-encoded field to the string builder.
-// Panics on values > 99 chars (programming error; callers validate first).
-func write(sb *strings.Builder, id, value string) {
-	sb.WriteString(mustEncodeTLV(id, value))
+// endTemplate sets the length of the template begun at buf[start:], failing
+// like encodeTLV if its contents exceed 99 bytes.
+func endTemplate(buf []byte, start int) ([]byte, error) {
+	n := len(buf) - start - 4
+	if n > 99 {
+		return nil, tlvLengthError(string(buf[start:start+2]), n)
+	}
+	buf[start+2] = byte('0' + n/10)
+	buf[start+3] = byte('0' + n%10)
+	return buf, nil
 }
 
-// encodeAdditionalDataField encodes the Additional Data Field Template.
-func encodeAdditionalDataField(adf *AdditionalDataField) (string, error) {
-	var inner strings.Builder
+// appendAdditionalDataField appends the Additional Data Field Template.
+func appendAdditionalDataField(buf []byte, adf *AdditionalDataField, version SpecVersion) ([]byte, error) {
+	if version < SpecVersion11 && (adf.MerchantTaxID != "" || adf.MerchantChannel != "" || len(adf.PaymentSystemTemplates) > 0) {
+		return nil, newError(CodeUnsupportedVersion,
+			fmt.Errorf("%w: merchant tax ID, merchant channel and payment system templates require v%s",
+				ErrUnsupportedVersion, SpecVersion11),
+			"tag", IDAdditionalDataFieldTemplate, "version", SpecVersion11.String())
+	}
+	if adf.MerchantChannel != "" && len(adf.MerchantChannel) != 3 {
+		return nil, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: merchant channel must be 3 characters, got %d", ErrInvalidFormat, len(adf.MerchantChannel)),
+			"tag", IDAdditionalDataFieldTemplate+"."+ADFMerchantChannel, "value", adf.MerchantChannel)
+	}
+	start := len(buf)
+	buf = beginTemplate(buf, IDAdditionalDataFieldTemplate)
+	var err error
 	appendIf := func(id, val string) error {
 		if val == "" {
 			return nil
 		}
-		chunk, err := encodeTLV(id, val)
-		if err != nil {
+		if buf, err = appendTLV(buf, id, val); err != nil {
 			return fmt.Errorf("field %s: %w", id, err)
 		}
-		inner.WriteString(chunk)
 		return nil
 	}
-	for _, pair := range []struct{ id, val string }{
+	for _, pair := range [...]struct{ id, val string }{
 		{ADFBillNumber, adf.BillNumber},
 		{ADFMobileNumber, adf.MobileNumber},
 		{ADFStoreLabel, adf.StoreLabel},
@@ -196,177 +524,331 @@ func encodeAdditionalDataField(adf *AdditionalDataField) (string, error) {
 		{ADFTerminalLabel, adf.TerminalLabel},
 		{ADFPurposeOfTransaction, adf.PurposeOfTransaction},
 		{ADFAdditionalConsumerDataRequest, adf.AdditionalConsumerDataRequest},
+		{ADFMerchantTaxID, adf.MerchantTaxID},
+		{ADFMerchantChannel, adf.MerchantChannel},
 	} {
 		if err := appendIf(pair.id, pair.val); err != nil {
-			return "", err
+			return nil, err
 		}
 	}
-	for _, rfu := range adf.RFUFields {
-		if err := appendIf(rfu.ID, rfu.Value); err != nil {
-			return "", err
+	appendPST := func(pst UnreservedTemplate) error {
+		if !isPaymentSystemTemplateID(pst.ID) {
+			return newError(CodeInvalidFormat,
+				fmt.Errorf("%w: payment system template ID %q must be 50–99", ErrInvalidTagID, pst.ID),
+				"tag", IDAdditionalDataFieldTemplate+"."+pst.ID)
 		}
-	}
-	return encodeTLV(IDAdditionalDataFieldTemplate, inner.String())
-}
-
-// encodeLanguageTemplate encodes the Merchant Information Language Template.
-func encodeLanguageTemplate(lt *LanguageTemplate) (string, error) {
-	var inner strings.Builder
-	if lt.LanguagePreference != "" {
-		chunk, err := encodeTLV(LangPreference, lt.LanguagePreference)
-		if err != nil {
-			return "", err
+		if pst.GloballyUniqueID == "" && len(pst.SubFields) == 0 {
+			return nil // appendIf skips empty values
+		}
+		tmpl := len(buf)
+		buf = beginTemplate(buf, pst.ID)
+		if pst.GloballyUniqueID != "" {
+			if buf, err = appendTLV(buf, MAIGloballyUniqueID, pst.GloballyUniqueID); err != nil {
+				return fmt.Errorf("field %s: %w", pst.ID, err)
+			}
+		}
+		for _, sf := range pst.SubFields {
+			if buf, err = appendTLV(buf, sf.ID, sf.Value); err != nil {
+				return fmt.Errorf("field %s: %w", pst.ID, err)
+			}
+		}
+		if buf, err = endTemplate(buf, tmpl); err != nil {
+			return fmt.Errorf("field %s: %w", pst.ID, err)
 		}
-		inner.WriteString(chunk)
+		return nil
 	}
-	if lt.MerchantName != "" {
-		chunk, err := encodeTLV(LangMerchantName, lt.MerchantName)
+	// RFU fields and payment system templates are merged by ID, so that
+	// sorted lists give sub-fields in ascending ID order.
+	rfus, psts := adf.RFUFields, adf.PaymentSystemTemplates
+	for len(rfus) > 0 || len(psts) > 0 {
+		if len(psts) == 0 || len(rfus) > 0 && rfus[0].ID <= psts[0].ID {
+			err = appendIf(rfus[0].ID, rfus[0].Value)
+			rfus = rfus[1:]
+		} else {
+			err = appendPST(psts[0])
+			psts = psts[1:]
+		}
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		inner.WriteString(chunk)
 	}
-	if lt.MerchantCity != "" {
-		chunk, err := encodeTLV(LangMerchantCity, lt.MerchantCity)
-		if err != nil {
-			return "", err
+	return endTemplate(buf, start)
+}
+
+// encodeLanguageFields encodes the sub-fields of a Language Template.
+func encodeLanguageFields(lt *LanguageTemplate) (string, error) {
+	buf, err := appendLanguageFields(nil, lt)
+	return string(buf), err
+}
+
+// appendLanguageFields appends the sub-fields of a Language Template.
+func appendLanguageFields(buf []byte, lt *LanguageTemplate) ([]byte, error) {
+	var err error
+	for _, f := range [...]struct{ id, val string }{
+		{LangPreference, lt.LanguagePreference},
+		{LangMerchantName, lt.MerchantName},
+		{LangMerchantCity, lt.MerchantCity},
+	} {
+		if f.val == "" {
+			continue
+		}
+		if buf, err = appendTLV(buf, f.id, f.val); err != nil {
+			return nil, err
 		}
-		inner.WriteString(chunk)
 	}
 	for _, rfu := range lt.RFUFields {
-		chunk, err := encodeTLV(rfu.ID, rfu.Value)
-		if err != nil {
-			return "", err
+		if buf, err = appendTLV(buf, rfu.ID, rfu.Value); err != nil {
+			return nil, err
 		}
-		inner.WriteString(chunk)
 	}
-	return encodeTLV(IDMerchantInfoLanguageTemplate, inner.String())
+	return buf, nil
 }
 
 // encodeUnreservedTemplate encodes an Unreserved Template.
 func encodeUnreservedTemplate(ut UnreservedTemplate) (string, error) {
+	buf, err := appendUnreservedTemplate(nil, ut)
+	return string(buf), err
+}
+
+// appendUnreservedTemplate appends an Unreserved Template.
+func appendUnreservedTemplate(buf []byte, ut UnreservedTemplate) ([]byte, error) {
 	n, err := strconv.Atoi(ut.ID)
 	if err != nil || n < 80 || n > 99 {
-		return "", fmt.Errorf("emvqr: unreserved template ID %q must be 80–99", ut.ID)
+		return nil, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: unreserved template ID %q must be 80–99", ErrInvalidTagID, ut.ID), "tag", ut.ID)
 	}
-	var inner strings.Builder
+	start := len(buf)
+	buf = beginTemplate(buf, ut.ID)
 	if ut.GloballyUniqueID != "" {
-		chunk, err := encodeTLV(MAIGloballyUniqueID, ut.GloballyUniqueID)
-		if err != nil {
-			return "", err
+		if buf, err = appendTLV(buf, MAIGloballyUniqueID, ut.GloballyUniqueID); err != nil {
+			return nil, err
 		}
-		inner.WriteString(chunk)
 	}
 	for _, sf := range ut.SubFields {
-		chunk, err := encodeTLV(sf.ID, sf.Value)
-		if err != nil {
-			return "", err
+		if buf, err = appendTLV(buf, sf.ID, sf.Value); err != nil {
+			return nil, err
 		}
-		inner.WriteString(chunk)
 	}
-	return encodeTLV(ut.ID, inner.String())
+	return endTemplate(buf, start)
 }
 
-// encodeUPIVPATemplate encodes the UPI VPA template (ID "26").
-func encodeUPIVPATemplate(uvt *UPIVPATemplate) (string, error) {
-	var inner strings.Builder
-	if uvt.RuPayRID != "" {
-		chunk, err := encodeTLV(MAIGloballyUniqueID, uvt.RuPayRID)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: UPI VPA template RuPayRID: %w", err)
-		}
-		inner.WriteString(chunk)
-	}
-	if uvt.VPA != "" {
-		chunk, err := encodeTLV("01", uvt.VPA)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: UPI VPA template VPA: %w", err)
-		}
-		inner.WriteString(chunk)
-	}
-	if uvt.MinimumAmount != "" {
-		chunk, err := encodeTLV("02", uvt.MinimumAmount)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: UPI VPA template minimum amount: %w", err)
-		}
-		inner.WriteString(chunk)
-	}
-	return encodeTLV("26", inner.String())
+// typedSubField is a sub-field of a template encoded from a typed field.
+type typedSubField struct {
+	id, name, value string
 }
 
-// encodeUPIVPAReference encodes the UPI VPA Reference template (ID "27").
-func encodeUPIVPAReference(uvr *UPIVPAReference) (string, error) {
-	var inner strings.Builder
-	if uvr.RuPayRID != "" {
-		chunk, err := encodeTLV(UPIVPARefRuPayRID, uvr.RuPayRID)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: UPI VPA reference RuPayRID: %w", err)
+// appendTypedTemplate appends template id with the non-empty fields, which
+// are in ascending ID order. Empty sub-fields of decoded, the template's
+// sub-fields as kept in MerchantIdentifiers, are written too where the
+// typed field is also empty, in their position by ID, so that a payload
+// decoded with empty sub-fields re-encodes byte for byte.
+func appendTypedTemplate(buf []byte, id string, fields []typedSubField, decoded []DataObject) ([]byte, error) {
+	start := len(buf)
+	buf = beginTemplate(buf, id)
+	j := 0
+	for _, f := range fields {
+		for ; j < len(decoded) && decoded[j].ID < f.id; j++ {
+			if decoded[j].Value == "" {
+				buf = append(buf, decoded[j].ID...)
+				buf = append(buf, "00"...)
+			}
 		}
-		inner.WriteString(chunk)
-	}
-	if uvr.TransactionRef != "" {
-		chunk, err := encodeTLV(UPIVPARefTransactionRef, uvr.TransactionRef)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: UPI VPA reference transaction reference: %w", err)
+		if j < len(decoded) && decoded[j].ID == f.id {
+			if decoded[j].Value == "" && f.value == "" {
+				buf = append(buf, f.id...)
+				buf = append(buf, "00"...)
+			}
+			j++
+		}
+		if f.value == "" {
+			continue
+		}
+		var err error
+		if buf, err = appendTLV(buf, f.id, f.value); err != nil {
+			return nil, fmt.Errorf("emvqr: %s: %w", f.name, err)
 		}
-		inner.WriteString(chunk)
 	}
-	if uvr.ReferenceURL != "" {
-		chunk, err := encodeTLV(UPIVPARefURL, uvr.ReferenceURL)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: UPI VPA reference URL: %w", err)
+	for ; j < len(decoded); j++ {
+		if decoded[j].Value == "" {
+			buf = append(buf, decoded[j].ID...)
+			buf = append(buf, "00"...)
 		}
-		inner.WriteString(chunk)
 	}
-	return encodeTLV(IDUPIVPAReference, inner.String())
+	return endTemplate(buf, start)
 }
 
-// encodeAadhaarInfo encodes the Aadhaar template (ID "28").
-func encodeAadhaarInfo(ai *AadhaarInfo) (string, error) {
-	var inner strings.Builder
-	if ai.RuPayRID != "" {
-		chunk, err := encodeTLV(AadhaarRuPayRID, ai.RuPayRID)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: Aadhaar RuPayRID: %w", err)
-		}
-		inner.WriteString(chunk)
-	}
-	if ai.AadhaarNumber != "" {
-		chunk, err := encodeTLV(AadhaarAadhaarNum, ai.AadhaarNumber)
-		if err != nil {
-			return "", fmt.Errorf("emvqr: Aadhaar number: %w", err)
+// appendUPIVPATemplate appends the UPI VPA template (ID "26").
+func appendUPIVPATemplate(buf []byte, uvt *UPIVPATemplate, decoded []DataObject) ([]byte, error) {
+	return appendTypedTemplate(buf, IDUPIVPATemplate, []typedSubField{
+		{MAIGloballyUniqueID, "UPI VPA template RuPayRID", uvt.RuPayRID},
+		{"01", "UPI VPA template VPA", uvt.VPA},
+		{"02", "UPI VPA template minimum amount", uvt.MinimumAmount},
+	}, decoded)
+}
+
+// appendUPIVPAReference appends the UPI VPA Reference template (ID "27").
+func appendUPIVPAReference(buf []byte, uvr *UPIVPAReference, decoded []DataObject) ([]byte, error) {
+	return appendTypedTemplate(buf, IDUPIVPAReference, []typedSubField{
+		{UPIVPARefRuPayRID, "UPI VPA reference RuPayRID", uvr.RuPayRID},
+		{UPIVPARefTransactionRef, "UPI VPA reference transaction reference", uvr.TransactionRef},
+		{UPIVPARefURL, "UPI VPA reference URL", uvr.ReferenceURL},
+	}, decoded)
+}
+
+// appendAadhaarInfo appends the Aadhaar template (ID "28").
+func appendAadhaarInfo(buf []byte, ai *AadhaarInfo, decoded []DataObject) ([]byte, error) {
+	return appendTypedTemplate(buf, IDAadhaarTemplate, []typedSubField{
+		{AadhaarRuPayRID, "Aadhaar RuPayRID", ai.RuPayRID},
+		{AadhaarAadhaarNum, "Aadhaar number", ai.AadhaarNumber},
+	}, decoded)
+}
+
+// decodedSubFields returns the sub-fields of merchant identifier id, or nil.
+func (p *Payload) decodedSubFields(id string) []DataObject {
+	for i := range p.MerchantIdentifiers {
+		if p.MerchantIdentifiers[i].ID == id {
+			return p.MerchantIdentifiers[i].SubFields
 		}
-		inner.WriteString(chunk)
 	}
-	return encodeTLV(IDAadhaarTemplate, inner.String())
+	return nil
 }
 
 // validatePayload ensures required fields are present.
 func validatePayload(p *Payload) error {
-	if p == nil {
-		return fmt.Errorf("%w: nil payload", ErrMissingRequired)
+	if errs := payloadErrors(p); len(errs) > 0 {
+		return errs[0]
 	}
-	required := []struct{ name, val string }{
-		{"MerchantCategoryCode", p.MerchantCategoryCode},
-		{"TransactionCurrency", p.TransactionCurrency},
-		{"CountryCode", p.CountryCode},
-		{"MerchantName", p.MerchantName},
-		{"MerchantCity", p.MerchantCity},
+	return nil
+}
+
+// payloadErrors returns the failures of validatePayload, all of them.
+func payloadErrors(p *Payload) []error {
+	if p == nil {
+		return []error{fmt.Errorf("%w: nil payload", ErrMissingRequired)}
+	}
+	var errs []error
+	// P2P QRs may leave out the MCC and city, and identify the payee by
+	// the Tag 26 VPA alone.
+	p2p := p.IsPersonToPerson()
+	required := []struct {
+		id, name, val string
+		p2pOptional   bool
+	}{
+		{IDMerchantCategoryCode, "MerchantCategoryCode", p.MerchantCategoryCode, true},
+		{IDTransactionCurrency, "TransactionCurrency", p.TransactionCurrency, false},
+		{IDCountryCode, "CountryCode", p.CountryCode, false},
+		{IDMerchantName, "MerchantName", p.MerchantName, false},
+		{IDMerchantCity, "MerchantCity", p.MerchantCity, true},
 	}
 	for _, r := range required {
-		if r.val == "" {
-			return fmt.Errorf("%w: %s", ErrMissingRequired, r.name)
+		if r.val == "" && !(p2p && r.p2pOptional) {
+			errs = append(errs, newError(CodeMissingField, fmt.Errorf("%w: %s", ErrMissingRequired, r.name), "tag", r.id, "field", r.name))
 		}
 	}
-	if len(p.MerchantIdentifiers) == 0 {
-		return fmt.Errorf("%w: at least one MerchantIdentifier is required", ErrMissingRequired)
+	if len(p.MerchantIdentifiers) == 0 && !(p2p && p.UPIVPAInfo != nil) {
+		errs = append(errs, newError(CodeMissingField, fmt.Errorf("%w: at least one MerchantIdentifier is required", ErrMissingRequired),
+			"tag", "02-51", "field", "MerchantIdentifiers"))
 	}
 	// Validate tip/fee consistency
 	switch p.TipOrConvenienceIndicator {
 	case "", TipIndicatorPromptConsumer, TipIndicatorFixedConvenienceFee, TipIndicatorPercentageFee:
 		// valid
 	default:
-		return fmt.Errorf("emvqr: invalid TipOrConvenienceIndicator %q (must be 01, 02, or 03)", p.TipOrConvenienceIndicator)
+		errs = append(errs, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: TipOrConvenienceIndicator %q must be 01, 02, or 03", ErrInvalidFormat, p.TipOrConvenienceIndicator),
+			"tag", IDTipOrConvenienceIndicator, "value", p.TipOrConvenienceIndicator))
 	}
-	return nil
+	if _, err := ParsePOI(string(p.PointOfInitiationMethod)); err != nil {
+		errs = append(errs, newError(CodeInvalidFormat, err, "tag", IDPointOfInitiationMethod, "value", string(p.PointOfInitiationMethod)))
+	}
+	return errs
+}
+
+// encodeErrors returns every reason appendPayload rejects p, checking each
+// field and template on its own, for EncodeOptions.AllErrors.
+func encodeErrors(p *Payload, opts EncodeOptions) []error {
+	errs := payloadErrors(p)
+	if p == nil {
+		return errs
+	}
+	if !opts.SpecVersion.valid() {
+		errs = append(errs, unknownSpecVersion(opts.SpecVersion))
+	}
+	errs = append(errs, referenceFormatErrors(p, opts)...)
+	errs = append(errs, urlErrors(p)...)
+	if opts.StrictCharset {
+		errs = append(errs, charsetErrors(p)...)
+	}
+	if opts.StrictFormats {
+		errs = append(errs, fieldFormatErrors(p)...)
+	}
+	if err := CheckFeeCap(p, opts.FeePolicy); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := encodeFormatIndicator(p, opts); err != nil {
+		errs = append(errs, err)
+	}
+	check := func(err error, format string, args ...any) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf(format+": %w", append(args, err)...))
+		}
+	}
+	field := func(id, value string) {
+		_, err := appendTLV(nil, id, value)
+		check(err, "emvqr: encoding field %s", id)
+	}
+	for _, mi := range p.MerchantIdentifiers {
+		if mi.ID != IDUPIVPATemplate && mi.ID != IDUPIVPAReference && mi.ID != IDAadhaarTemplate {
+			_, err := appendTLV(nil, mi.ID, mi.Value)
+			check(err, "emvqr: encoding merchant identifier %s", mi.ID)
+		}
+	}
+	for _, f := range [...]struct{ id, value string }{
+		{IDMerchantCategoryCode, p.MerchantCategoryCode},
+		{IDTransactionCurrency, p.TransactionCurrency},
+		{IDTransactionAmount, p.TransactionAmount},
+		{IDCountryCode, p.CountryCode},
+		{IDMerchantName, p.MerchantName},
+		{IDMerchantCity, p.MerchantCity},
+		{IDPostalCode, p.PostalCode},
+	} {
+		field(f.id, f.value)
+	}
+	switch p.TipOrConvenienceIndicator {
+	case TipIndicatorFixedConvenienceFee:
+		field(IDValueConvenienceFeeFixed, p.ValueConvenienceFeeFixed)
+	case TipIndicatorPercentageFee:
+		field(IDValueConvenienceFeePercent, p.ValueConvenienceFeePercent)
+	}
+	if p.UPIVPAInfo != nil {
+		_, err := appendUPIVPATemplate(nil, p.UPIVPAInfo, p.decodedSubFields(IDUPIVPATemplate))
+		check(err, "emvqr: encoding UPI VPA template")
+	}
+	if p.UPITransactionRef != nil {
+		_, err := appendUPIVPAReference(nil, p.UPITransactionRef, p.decodedSubFields(IDUPIVPAReference))
+		check(err, "emvqr: encoding UPI VPA reference")
+	}
+	if p.MerchantAadhaar != nil {
+		_, err := appendAadhaarInfo(nil, p.MerchantAadhaar, p.decodedSubFields(IDAadhaarTemplate))
+		check(err, "emvqr: encoding Aadhaar info")
+	}
+	if p.AdditionalData != nil {
+		_, err := appendAdditionalDataField(nil, p.AdditionalData, opts.SpecVersion)
+		check(err, "emvqr: encoding additional data field")
+	}
+	if p.LanguageTemplate != nil {
+		buf, err := appendLanguageFields(beginTemplate(nil, IDMerchantInfoLanguageTemplate), p.LanguageTemplate)
+		if err == nil {
+			_, err = endTemplate(buf, 0)
+		}
+		check(err, "emvqr: encoding language template")
+	}
+	for _, ut := range p.UnreservedTemplates {
+		_, err := appendUnreservedTemplate(nil, ut)
+		check(err, "emvqr: encoding unreserved template %s", ut.ID)
+	}
+	for _, rfu := range p.RFUFields {
+		field(rfu.ID, rfu.Value)
+	}
+	return errs
 }
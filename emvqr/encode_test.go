@@ -0,0 +1,113 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEstimateEncodedLength(t *testing.T) {
+	bharat, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*Payload{basePayload(), bharat} {
+		got, err := Encode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := EstimateEncodedLength(p); n != len(got) {
+			t.Errorf("EstimateEncodedLength = %d, encoded length %d", n, len(got))
+		}
+	}
+}
+
+func TestEncode_TemplateTooLong(t *testing.T) {
+	p := basePayload()
+	p.AdditionalData = &AdditionalDataField{
+		BillNumber:     "B0000000000000000000000000000000000000000",
+		ReferenceLabel: "R0000000000000000000000000000000000000000",
+		TerminalLabel:  "T0000000000000000000000000000000000000000",
+	}
+	if _, err := Encode(p); err == nil {
+		t.Fatal("expected an error for a Tag 62 template over 99 bytes")
+	}
+}
+
+func TestEncode_TooLongReturnsError(t *testing.T) {
+	long := strings.Repeat("X", 100)
+	tests := []struct {
+		name string
+		edit func(p *Payload)
+		opts EncodeOptions
+		tag  string
+	}{
+		{"merchant name", func(p *Payload) { p.MerchantName = long }, EncodeOptions{}, IDMerchantName},
+		{"postal code", func(p *Payload) { p.PostalCode = long }, EncodeOptions{}, IDPostalCode},
+		{"RFU field", func(p *Payload) { p.RFUFields = []DataObject{{ID: "65", Value: long}} }, EncodeOptions{}, "65"},
+		{"format indicator", func(*Payload) {}, EncodeOptions{PayloadFormatIndicator: long}, IDPayloadFormatIndicator},
+		{"edited template", func(p *Payload) {
+			p.UPITransactionRef.ReferenceURL = "https://example.com/" + strings.Repeat("r", 60)
+		}, EncodeOptions{}, IDUPIVPAReference},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Decode(realWorldBharatQRPayload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(p)
+			_, err = EncodeWithOptions(p, tt.opts)
+			if !errors.Is(err, ErrInvalidFormat) {
+				t.Fatalf("Encode() error = %v, want ErrInvalidFormat", err)
+			}
+			assertEqual(t, "tag", tt.tag, ErrorParams(err)["tag"])
+			if tt.opts == (EncodeOptions{}) {
+				if err := CanEncode(p); !errors.Is(err, ErrInvalidFormat) {
+					t.Errorf("CanEncode() = %v, want ErrInvalidFormat", err)
+				}
+			}
+		})
+	}
+	if err := CanEncode(basePayload()); err != nil {
+		t.Errorf("CanEncode(basePayload()) = %v", err)
+	}
+}
+
+func TestEncode_AllErrors(t *testing.T) {
+	p := basePayload()
+	p.MerchantName = ""
+	p.MerchantCity = ""
+	p.PostalCode = strings.Repeat("9", 100)
+	p.UnreservedTemplates = []UnreservedTemplate{{ID: "79", GloballyUniqueID: "com.example"}}
+
+	_, first := Encode(p)
+	if !errors.Is(first, ErrMissingRequired) || errors.Is(first, ErrFieldTooLong) {
+		t.Fatalf("Encode() error = %v, want only the first violation", first)
+	}
+
+	_, err := EncodeWithOptions(p, EncodeOptions{AllErrors: true})
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("EncodeWithOptions(AllErrors) error = %v, want a joined error", err)
+	}
+	var tags []string
+	for _, e := range joined.Unwrap() {
+		tags = append(tags, ErrorParams(e)["tag"])
+	}
+	assertEqual(t, "tags", "59,60,61,79", strings.Join(tags, ","))
+	for _, want := range []error{ErrMissingRequired, ErrFieldTooLong, ErrInvalidTagID} {
+		if !errors.Is(err, want) {
+			t.Errorf("joined error does not match %v", want)
+		}
+	}
+	assertEqual(t, "code", string(CodeMissingField), string(ErrorCode(err)))
+
+	// A single violation is returned as Encode returns it.
+	p = basePayload()
+	p.MerchantName = ""
+	_, err = EncodeWithOptions(p, EncodeOptions{AllErrors: true})
+	if _, ok := err.(interface{ Unwrap() []error }); ok || !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("single violation: error = %v", err)
+	}
+}
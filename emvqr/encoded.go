@@ -0,0 +1,57 @@
+package emvqr
+
+// EncodedQR is an encoded payload together with its CRC, fingerprint and
+// the options it was encoded with. It is immutable: its fields are
+// unexported and its methods only read them, so a value can be shared
+// between goroutines and kept in caches without copying or locking, unlike
+// a *Payload, which callers may edit.
+//
+// EncodedQR values are not comparable with ==, as EncodeOptions may hold
+// validator functions; compare String or Fingerprint instead.
+type EncodedQR struct {
+	raw         string
+	fingerprint string
+	opts        EncodeOptions
+}
+
+// EncodeQR encodes p like EncodeWithOptions and returns the result as an
+// EncodedQR. Later changes to p do not affect it.
+func EncodeQR(p *Payload, opts EncodeOptions) (EncodedQR, error) {
+	raw, err := EncodeWithOptions(p, opts)
+	if err != nil {
+		return EncodedQR{}, err
+	}
+	return EncodedQR{raw: raw, fingerprint: p.Fingerprint(), opts: opts}, nil
+}
+
+// String returns the encoded payload, CRC included, ready to be rendered
+// as a QR code.
+func (q EncodedQR) String() string { return q.raw }
+
+// CRC returns the value of the CRC (Tag 63), e.g. "51DD", or "" for the
+// zero EncodedQR.
+func (q EncodedQR) CRC() string {
+	if len(q.raw) < 4 {
+		return ""
+	}
+	return q.raw[len(q.raw)-4:]
+}
+
+// Fingerprint returns the Fingerprint of the payload that was encoded, a
+// key for caches and deduplication that does not depend on field order.
+func (q EncodedQR) Fingerprint() string { return q.fingerprint }
+
+// Options returns the options the payload was encoded with.
+func (q EncodedQR) Options() EncodeOptions { return q.opts }
+
+// IsZero reports whether q is the zero EncodedQR, as returned with an
+// error.
+func (q EncodedQR) IsZero() bool { return q.raw == "" }
+
+// Decode returns a new Payload decoded from q, which the caller may edit
+// freely.
+func (q EncodedQR) Decode() (*Payload, error) { return Decode(q.raw) }
+
+// MarshalText implements encoding.TextMarshaler, so that an EncodedQR is
+// written to JSON and XML as its encoded string.
+func (q EncodedQR) MarshalText() ([]byte, error) { return []byte(q.raw), nil }
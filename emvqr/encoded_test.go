@@ -0,0 +1,71 @@
+package emvqr
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestEncodeQR(t *testing.T) {
+	p := basePayload()
+	raw := mustEncode(t, p)
+	q, err := EncodeQR(p, EncodeOptions{Canonical: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := EncodeWithOptions(p, EncodeOptions{Canonical: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "String", canonical, q.String())
+	assertEqual(t, "CRC", canonical[len(canonical)-4:], q.CRC())
+	assertEqual(t, "Fingerprint", p.Fingerprint(), q.Fingerprint())
+	if !q.Options().Canonical || q.IsZero() {
+		t.Errorf("Options() = %+v, IsZero() = %v", q.Options(), q.IsZero())
+	}
+
+	// Editing the payload afterwards does not change the encoded value.
+	p.MerchantName = "CHANGED"
+	assertEqual(t, "String after edit", canonical, q.String())
+	decoded, err := q.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "decoded MerchantName", "ABC Hammers", decoded.MerchantName)
+	if mustEncode(t, decoded) != raw {
+		t.Error("decoded payload does not encode as the original")
+	}
+
+	b, err := json.Marshal(struct{ QR EncodedQR }{q})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "JSON", `{"QR":"`+canonical+`"}`, string(b))
+}
+
+func TestEncodeQR_Error(t *testing.T) {
+	p := basePayload()
+	p.MerchantName = ""
+	q, err := EncodeQR(p, EncodeOptions{})
+	if err == nil || !q.IsZero() || q.CRC() != "" {
+		t.Errorf("EncodeQR() = %q, %v; want the zero EncodedQR and an error", q.String(), err)
+	}
+}
+
+func TestEncodedQR_ConcurrentUse(t *testing.T) {
+	q, err := EncodeQR(basePayload(), EncodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := q.Decode(); err != nil || q.CRC() == "" || q.Fingerprint() == "" {
+				t.Error("concurrent use of an EncodedQR failed")
+			}
+		}()
+	}
+	wg.Wait()
+}
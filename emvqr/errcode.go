@@ -0,0 +1,214 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Code is a stable, machine-readable identifier for a failure, for consumer
+// apps that show their own message instead of the Go error string. Codes
+// never change once released; new failures get new codes.
+type Code string
+
+// Failure codes returned by ErrorCode.
+const (
+	CodeUnknown            Code = "unknown"
+	CodeInvalidLength      Code = "invalid_length"
+	CodeMalformed          Code = "malformed"
+	CodeCRCMismatch        Code = "crc_mismatch"
+	CodeMissingField       Code = "missing_field"
+	CodeUnsupportedVersion Code = "unsupported_version"
+	CodeInvalidFormat      Code = "invalid_format"
+	CodeMergeConflict      Code = "merge_conflict"
+	CodeBelowMinimum       Code = "below_minimum"
+	CodeMerchantUnverified Code = "merchant_unverified"
+	CodeLimitExceeded      Code = "limit_exceeded"
+	CodeTagNotFound        Code = "tag_not_found"
+	CodeRoundTrip          Code = "round_trip"
+	CodeNoPaymentOption    Code = "no_payment_option"
+)
+
+// Error is a failure with a Code and named parameters, such as the tag of
+// the offending field. Its message and errors.Is behaviour are those of Err.
+type Error struct {
+	Code Code
+	// Params holds details for messages and logs, e.g. {"tag": "59"}.
+	Params map[string]string
+	Err    error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// newError returns err annotated with code and params, given as key/value
+// pairs.
+func newError(code Code, err error, params ...string) *Error {
+	e := &Error{Code: code, Err: err}
+	if len(params) > 0 {
+		e.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			e.Params[params[i]] = params[i+1]
+		}
+	}
+	return e
+}
+
+// sentinelCodes maps the sentinel errors onto their codes, for errors that
+// are not an *Error.
+var sentinelCodes = []struct {
+	err  error
+	code Code
+}{
+	{ErrCRCMismatch, CodeCRCMismatch},
+	{ErrMissingRequired, CodeMissingField},
+	{ErrUnsupportedVersion, CodeUnsupportedVersion},
+	{ErrInvalidFormat, CodeInvalidFormat},
+	{ErrMergeConflict, CodeMergeConflict},
+	{ErrBelowMinimum, CodeBelowMinimum},
+	{ErrMerchantUnverified, CodeMerchantUnverified},
+	{ErrLimitExceeded, CodeLimitExceeded},
+	{ErrInvalidTLV, CodeMalformed},
+	{ErrInvalidLength, CodeInvalidLength},
+	{ErrTagNotFound, CodeTagNotFound},
+	{ErrRoundTrip, CodeRoundTrip},
+	{ErrNoPaymentOption, CodeNoPaymentOption},
+}
+
+// ErrorCode returns the Code of err: that of the first *Error in its chain,
+// or else the code of the sentinel error it wraps. It returns "" for a nil
+// error and CodeUnknown when no code applies.
+func ErrorCode(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	for _, s := range sentinelCodes {
+		if errors.Is(err, s.err) {
+			return s.code
+		}
+	}
+	return CodeUnknown
+}
+
+// ErrorParams returns the parameters of the first *Error in err's chain.
+// The ID of a *ParseError is reported as "tag" when no *Error gives one.
+// It returns nil when there are none.
+func ErrorParams(err error) map[string]string {
+	params := make(map[string]string)
+	var e *Error
+	if errors.As(err, &e) {
+		for k, v := range e.Params {
+			params[k] = v
+		}
+	}
+	var pe *ParseError
+	if _, ok := params["tag"]; !ok && errors.As(err, &pe) {
+		params["tag"] = pe.ID
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// messages is the message catalog, keyed by language then code.
+var (
+	messagesMu sync.RWMutex
+	messages   = map[string]map[Code]string{
+		"en": {
+			CodeUnknown:            "This QR code cannot be used for payment.",
+			CodeInvalidLength:      "This QR code could not be read. It may not be a payment QR code.",
+			CodeMalformed:          "This QR code could not be read. It may not be a payment QR code.",
+			CodeCRCMismatch:        "This QR code is damaged or has been altered. Please ask the merchant for a new one.",
+			CodeMissingField:       "This QR code is incomplete. Please ask the merchant for a new one.",
+			CodeUnsupportedVersion: "This QR code uses a version that is not supported.",
+			CodeInvalidFormat:      "This QR code contains invalid merchant details.",
+			CodeMergeConflict:      "The merchant settings conflict with each other.",
+			CodeBelowMinimum:       "The amount must be at least {minimum}.",
+			CodeMerchantUnverified: "This merchant could not be verified. Do not pay unless you trust them.",
+			CodeLimitExceeded:      "This QR code is too large to be a payment QR code.",
+			CodeTagNotFound:        "This QR code does not contain the requested merchant details.",
+			CodeRoundTrip:          "This QR code could not be read reliably. Please ask the merchant for a new one.",
+			CodeNoPaymentOption:    "This merchant does not accept any of your payment methods.",
+		},
+		"hi": {
+			CodeUnknown:            "इस QR कोड से भुगतान नहीं किया जा सकता।",
+			CodeInvalidLength:      "यह QR कोड पढ़ा नहीं जा सका। हो सकता है यह भुगतान QR कोड न हो।",
+			CodeMalformed:          "यह QR कोड पढ़ा नहीं जा सका। हो सकता है यह भुगतान QR कोड न हो।",
+			CodeCRCMismatch:        "यह QR कोड क्षतिग्रस्त है या इसमें बदलाव किया गया है। कृपया व्यापारी से नया QR कोड माँगें।",
+			CodeMissingField:       "यह QR कोड अधूरा है। कृपया व्यापारी से नया QR कोड माँगें।",
+			CodeUnsupportedVersion: "यह QR कोड ऐसे संस्करण का है जो समर्थित नहीं है।",
+			CodeInvalidFormat:      "इस QR कोड में व्यापारी का विवरण अमान्य है।",
+			CodeMergeConflict:      "व्यापारी की सेटिंग्स आपस में मेल नहीं खातीं।",
+			CodeBelowMinimum:       "राशि कम से कम {minimum} होनी चाहिए।",
+			CodeMerchantUnverified: "इस व्यापारी का सत्यापन नहीं हो सका। भरोसा न हो तो भुगतान न करें।",
+			CodeLimitExceeded:      "यह QR कोड भुगतान QR कोड होने के लिए बहुत बड़ा है।",
+			CodeTagNotFound:        "इस QR कोड में माँगा गया व्यापारी विवरण नहीं है।",
+			CodeRoundTrip:          "यह QR कोड ठीक से पढ़ा नहीं जा सका। कृपया व्यापारी से नया QR कोड माँगें।",
+			CodeNoPaymentOption:    "यह व्यापारी आपके किसी भी भुगतान तरीके को स्वीकार नहीं करता।",
+		},
+	}
+)
+
+// RegisterMessages adds or replaces the messages for a language, e.g. "ta"
+// or "en-GB". Messages may refer to the parameters of an error as
+// "{name}", e.g. "Field {tag} is missing". It is safe for concurrent use.
+func RegisterMessages(lang string, catalog map[Code]string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	m := messages[lang]
+	if m == nil {
+		m = make(map[Code]string, len(catalog))
+		messages[lang] = m
+	}
+	for code, msg := range catalog {
+		m[code] = msg
+	}
+}
+
+// LocalizedMessage returns a message for err suitable for showing to a
+// consumer, in the language lang (a BCP 47 tag such as "hi-IN"). It falls
+// back from "hi-IN" to "hi", then to English, and from the specific code to
+// CodeUnknown. It returns "" for a nil error.
+func LocalizedMessage(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+	code := ErrorCode(err)
+	messagesMu.RLock()
+	msg := lookupMessage(lang, code)
+	if msg == "" {
+		msg = lookupMessage(lang, CodeUnknown)
+	}
+	messagesMu.RUnlock()
+
+	params := ErrorParams(err)
+	if len(params) == 0 {
+		return msg
+	}
+	pairs := make([]string, 0, 2*len(params))
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// lookupMessage returns the message for code in lang or its fallbacks.
+func lookupMessage(lang string, code Code) string {
+	for lang != "" {
+		if msg, ok := messages[lang][code]; ok {
+			return msg
+		}
+		i := strings.LastIndexAny(lang, "-_")
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+	return messages["en"][code]
+}
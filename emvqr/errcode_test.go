@@ -0,0 +1,162 @@
+package emvqr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	bad := realWorldBharatQRPayload[:len(realWorldBharatQRPayload)-4] + "0000"
+	_, crcErr := Decode(bad)
+	p := basePayload()
+	p.MerchantName = ""
+	_, missingErr := Encode(p)
+	_, versionErr := EncodeWithOptions(basePayload(), EncodeOptions{SpecVersion: 9})
+	v11 := basePayload()
+	v11.AdditionalData = &AdditionalDataField{MerchantChannel: "APP"}
+	_, v11Err := EncodeWithOptions(v11, EncodeOptions{SpecVersion: SpecVersion10})
+	_, noOptionErr := SelectNetwork(&Payload{}, Capabilities{Networks: []string{"Visa"}})
+	_, visaOnlyErr := SelectNetwork(basePayload(), Capabilities{Networks: []string{"RuPay"}})
+
+	tests := []struct {
+		err    error
+		code   Code
+		params map[string]string
+	}{
+		{nil, "", nil},
+		{crcErr, CodeCRCMismatch, map[string]string{"tag": "63", "got": "0000", "want": "51DD"}},
+		{missingErr, CodeMissingField, map[string]string{"tag": "59", "field": "MerchantName"}},
+		{fmt.Errorf("wrapped: %w", ErrInvalidTLV), CodeMalformed, nil},
+		{&ParseError{ID: "62", Err: ErrInvalidTLV}, CodeMalformed, map[string]string{"tag": "62"}},
+		{errors.New("boom"), CodeUnknown, nil},
+		{fmt.Errorf("wrapped: %w", ErrRoundTrip), CodeRoundTrip, nil},
+		{fmt.Errorf("wrapped: %w", ErrNoPaymentOption), CodeNoPaymentOption, nil},
+		{noOptionErr, CodeNoPaymentOption, nil},
+		{visaOnlyErr, CodeNoPaymentOption, map[string]string{"networks": "Visa"}},
+		{basePayload().AddMerchantIdentifier("", "4111"), CodeInvalidFormat, nil},
+		{versionErr, CodeUnsupportedVersion, map[string]string{"version": "SpecVersion(9)"}},
+		{v11Err, CodeUnsupportedVersion, map[string]string{"tag": "62", "version": "1.1"}},
+		{checkFormatIndicator(&Payload{}, SpecVersion10, nil), CodeMissingField, map[string]string{"tag": "00"}},
+		{checkFormatIndicator(&Payload{PayloadFormatIndicator: "02"}, SpecVersion11, nil), CodeUnsupportedVersion,
+			map[string]string{"tag": "00", "value": "02"}},
+	}
+	for _, tc := range tests {
+		if got := ErrorCode(tc.err); got != tc.code {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tc.err, got, tc.code)
+		}
+		got := ErrorParams(tc.err)
+		if len(got) != len(tc.params) {
+			t.Errorf("ErrorParams(%v) = %v, want %v", tc.err, got, tc.params)
+			continue
+		}
+		for k, v := range tc.params {
+			if got[k] != v {
+				t.Errorf("ErrorParams(%v)[%q] = %q, want %q", tc.err, k, got[k], v)
+			}
+		}
+	}
+	if !errors.Is(crcErr, ErrCRCMismatch) || !errors.Is(missingErr, ErrMissingRequired) {
+		t.Error("coded errors no longer match their sentinels")
+	}
+}
+
+func TestErrorTaxonomy(t *testing.T) {
+	long := basePayload()
+	long.MerchantName = strings.Repeat("A", 100)
+	_, tooLongErr := Encode(long)
+	ut := basePayload()
+	ut.UnreservedTemplates = []UnreservedTemplate{{ID: "79", GloballyUniqueID: "com.example"}}
+	_, tagIDErr := Encode(ut)
+	dup := basePayload()
+	dupErr := dup.AddMerchantIdentifier(dup.MerchantIdentifiers[0].ID, "4111")
+	schemeErr := dup.SetUPIVPAReference("ab", "")
+	aadhaarErr := dup.SetAadhaarNumber("12345678901x")
+	methodErr := dup.SetPointOfInitiationMethod("4", "1")
+	dataTypeErr := dup.SetPointOfInitiationMethod("1", "3")
+	mandateErr := ValidateUPIParams(UPIModeQRMandate, UPIPurposeDefault, POIStaticQR)
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+		tag  string
+	}{
+		{"field too long", tooLongErr, ErrFieldTooLong, "59"},
+		{"invalid tag ID", tagIDErr, ErrInvalidTagID, "79"},
+		{"duplicate tag", dupErr, ErrDuplicateTag, dup.MerchantIdentifiers[0].ID},
+		{"scheme violation", schemeErr, ErrSchemeViolation, "27.01"},
+		{"Aadhaar number", aadhaarErr, ErrSchemeViolation, "28.01"},
+		{"POI method", methodErr, ErrInvalidFormat, "01"},
+		{"POI data type", dataTypeErr, ErrInvalidFormat, "01"},
+		{"UPI mandate", mandateErr, ErrSchemeViolation, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.want) {
+				t.Fatalf("error %v does not match %v", tt.err, tt.want)
+			}
+			if !errors.Is(tt.err, ErrInvalidFormat) {
+				t.Errorf("error %v does not match ErrInvalidFormat", tt.err)
+			}
+			if got := ErrorCode(tt.err); got != CodeInvalidFormat {
+				t.Errorf("ErrorCode = %q, want %q", got, CodeInvalidFormat)
+			}
+			var e *Error
+			if tt.tag != "" && (!errors.As(tt.err, &e) || e.Params["tag"] != tt.tag) {
+				t.Errorf("errors.As(*Error) tag = %v, want %q", e, tt.tag)
+			}
+		})
+	}
+	for _, other := range []error{ErrInvalidTagID, ErrDuplicateTag, ErrSchemeViolation} {
+		if errors.Is(tooLongErr, other) {
+			t.Errorf("field too long error also matches %v", other)
+		}
+	}
+	if errors.Is(methodErr, ErrSchemeViolation) {
+		t.Error("invalid POI method matches ErrSchemeViolation")
+	}
+}
+
+func TestErrorTaxonomy_TagNotFound(t *testing.T) {
+	p := basePayload()
+	for name, err := range map[string]error{
+		"remove":  p.RemoveMerchantIdentifier("05"),
+		"replace": p.ReplaceMerchantIdentifier("05", MerchantIdentifier{ID: "05", Value: "5100"}),
+	} {
+		if !errors.Is(err, ErrTagNotFound) {
+			t.Errorf("%s: error %v does not match ErrTagNotFound", name, err)
+		}
+		if got := ErrorCode(err); got != CodeTagNotFound {
+			t.Errorf("%s: ErrorCode = %q, want %q", name, got, CodeTagNotFound)
+		}
+		if got := ErrorParams(err)["tag"]; got != "05" {
+			t.Errorf("%s: tag param = %q, want 05", name, got)
+		}
+	}
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	_, err := Decode(realWorldBharatQRPayload[:len(realWorldBharatQRPayload)-4] + "0000")
+	if got := LocalizedMessage(err, "en"); got != "This QR code is damaged or has been altered. Please ask the merchant for a new one." {
+		t.Errorf("LocalizedMessage(en) = %q", got)
+	}
+	if got, want := LocalizedMessage(err, "hi-IN"), messages["hi"][CodeCRCMismatch]; got != want {
+		t.Errorf("LocalizedMessage(hi-IN) = %q, want %q", got, want)
+	}
+	if got, want := LocalizedMessage(err, "xx"), messages["en"][CodeCRCMismatch]; got != want {
+		t.Errorf("LocalizedMessage(xx) = %q, want the English message", got)
+	}
+	if got := LocalizedMessage(nil, "en"); got != "" {
+		t.Errorf("LocalizedMessage(nil) = %q", got)
+	}
+
+	RegisterMessages("en-XA", map[Code]string{CodeCRCMismatch: "[CRC {got} != {want}]"})
+	if got := LocalizedMessage(err, "en-XA"); got != "[CRC 0000 != 51DD]" {
+		t.Errorf("LocalizedMessage(en-XA) = %q", got)
+	}
+	if got, want := LocalizedMessage(ErrInvalidTLV, "en-XA"), messages["en"][CodeMalformed]; got != want {
+		t.Errorf("LocalizedMessage(en-XA, malformed) = %q, want the English fallback", got)
+	}
+}
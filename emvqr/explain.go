@@ -0,0 +1,256 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// explainNameWidth is the column width of field names in Explain output.
+const explainNameWidth = 46
+
+// Explain renders raw as an indented, annotated tree for support tickets and
+// certification reviews. Each data object is printed with its tag, spec
+// name, length and value, template sub-fields are nested beneath their
+// parent, and coded values are decoded (e.g. "356 (INR)"):
+//
+//	00 Payload Format Indicator                       len 02  01
+//	01 Point of Initiation Method                     len 02  12 (QR, dynamic)
+//	53 Transaction Currency                           len 03  356 (INR)
+//	59 Merchant Name                                  len 27  ABC Hammers and Tools Co Ltd
+//	   WARNING: Tag 59 exceeds 25 chars (got 27)
+//	62 Additional Data Field Template                 len 09
+//	   01 Bill Number                                 len 05  INV-1
+//
+// Format, length, ordering, CRC and mandatory-field problems are reported as
+// warnings rather than errors; an error is returned only when the top-level
+// TLV structure cannot be parsed.
+func Explain(raw string) (string, error) {
+	objects, err := parseTLV(raw)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	seen := make(map[string]bool, len(objects))
+	for i, obj := range objects {
+		spec := topLevelSpec(obj.id)
+		label := "Tag " + obj.id
+		var warnings []string
+		if seen[obj.id] {
+			warnings = append(warnings, label+" appears more than once")
+		}
+		seen[obj.id] = true
+
+		if !isTemplateID(obj.id) {
+			warnings = append(warnings, spec.check(label, obj.value)...)
+			note := describeValue(obj.id, obj.value)
+			switch {
+			case obj.id == IDPayloadFormatIndicator && i != 0:
+				warnings = append(warnings, label+" must be the first data object")
+			case obj.id == IDCRC && i != len(objects)-1:
+				warnings = append(warnings, label+" must be the last data object")
+			case obj.id == IDCRC:
+				if want := ComputeCRC(raw[:len(raw)-len(obj.value)]); strings.EqualFold(obj.value, want) {
+					note = "valid"
+				} else {
+					note = "invalid, want " + want
+					warnings = append(warnings, fmt.Sprintf("%s CRC mismatch: got %s, want %s", label, strings.ToUpper(obj.value), want))
+				}
+			}
+			explainLine(&b, 0, obj.id, spec.name, len(obj.value), obj.value, note)
+			explainWarnings(&b, 0, warnings)
+			continue
+		}
+
+		if len(obj.value) > spec.maxLen {
+			warnings = append(warnings, fmt.Sprintf("%s exceeds %d chars (got %d)", label, spec.maxLen, len(obj.value)))
+		}
+		subs, err := parseTLV(obj.value)
+		if err != nil {
+			explainLine(&b, 0, obj.id, spec.name, len(obj.value), obj.value, "")
+			explainWarnings(&b, 0, append(warnings, fmt.Sprintf("%s is not a valid template: %v", label, err)))
+			continue
+		}
+		explainTemplate(&b, obj.id, spec.name, obj.value, subs, warnings)
+	}
+
+	global := append(missingFieldWarnings(seen), conditionalFieldWarnings(objects)...)
+	if len(global) > 0 {
+		b.WriteString("\nWarnings:\n")
+		for _, w := range global {
+			b.WriteString("  - " + w + "\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// explainTemplate writes a template line followed by its sub-fields.
+func explainTemplate(b *strings.Builder, id, name, value string, subs []tlvObject, warnings []string) {
+	explainLine(b, 0, id, name, len(value), "", "")
+	explainWarnings(b, 0, warnings)
+	seen := make(map[string]bool, len(subs))
+	for _, s := range subs {
+		spec := subFieldSpec(id, s.id)
+		label := "Tag " + id + "." + s.id
+		var subWarnings []string
+		if seen[s.id] {
+			subWarnings = append(subWarnings, label+" appears more than once")
+		}
+		seen[s.id] = true
+		if id == IDMerchantInfoLanguageTemplate && (s.id == LangMerchantName || s.id == LangMerchantCity) {
+			subWarnings = append(subWarnings, checkLanguageText(label, s.value, spec.maxLen)...)
+		} else {
+			subWarnings = append(subWarnings, spec.check(label, s.value)...)
+		}
+		explainLine(b, 1, s.id, spec.name, len(s.value), s.value, describeValue(id+"."+s.id, s.value))
+		explainWarnings(b, 1, subWarnings)
+	}
+}
+
+// explainLine writes one data object of the given length. Templates pass
+// an empty value: their sub-fields follow on separate lines.
+func explainLine(b *strings.Builder, depth int, id, name string, length int, value, note string) {
+	indent := strings.Repeat("   ", depth)
+	fmt.Fprintf(b, "%s%s %-*s len %02d", indent, id, explainNameWidth-len(indent), name, length)
+	if value != "" {
+		b.WriteString("  " + value)
+	}
+	if note != "" {
+		b.WriteString(" (" + note + ")")
+	}
+	b.WriteByte('\n')
+}
+
+func explainWarnings(b *strings.Builder, depth int, warnings []string) {
+	indent := strings.Repeat("   ", depth+1)
+	for _, w := range warnings {
+		b.WriteString(indent + "WARNING: " + w + "\n")
+	}
+}
+
+// missingFieldWarnings reports mandatory data objects that are absent.
+func missingFieldWarnings(seen map[string]bool) []string {
+	var warnings []string
+	for _, id := range []string{
+		IDPayloadFormatIndicator, IDMerchantCategoryCode, IDTransactionCurrency,
+		IDCountryCode, IDMerchantName, IDMerchantCity, IDCRC,
+	} {
+		if !seen[id] {
+			warnings = append(warnings, fmt.Sprintf("Tag %s (%s) is missing", id, topLevelSpecs[id].name))
+		}
+	}
+	for id := range seen {
+		if isMerchantAccountInfo(id) {
+			return warnings
+		}
+	}
+	return append(warnings, "no Merchant Account Information (Tags 02-51) present")
+}
+
+// conditionalFieldWarnings checks the convenience fee fields against the
+// Tip or Convenience Indicator, and the Tag 27 transaction reference against
+// the Tag 62 reference label.
+func conditionalFieldWarnings(objects []tlvObject) []string {
+	values := make(map[string]string, len(objects))
+	for _, obj := range objects {
+		values[obj.id] = obj.value
+	}
+	indicator, hasIndicator := values[IDTipOrConvenienceIndicator]
+	_, hasFixed := values[IDValueConvenienceFeeFixed]
+	_, hasPercent := values[IDValueConvenienceFeePercent]
+	var warnings []string
+	switch {
+	case hasIndicator && indicator != TipIndicatorPromptConsumer &&
+		indicator != TipIndicatorFixedConvenienceFee && indicator != TipIndicatorPercentageFee:
+		warnings = append(warnings, fmt.Sprintf("Tag 55 value %q is not 01, 02 or 03", indicator))
+	case indicator == TipIndicatorFixedConvenienceFee && !hasFixed:
+		warnings = append(warnings, "Tag 56 is required when Tag 55 is 02")
+	case indicator == TipIndicatorPercentageFee && !hasPercent:
+		warnings = append(warnings, "Tag 57 is required when Tag 55 is 03")
+	}
+	if hasFixed && indicator != TipIndicatorFixedConvenienceFee {
+		warnings = append(warnings, "Tag 56 is present but Tag 55 is not 02")
+	}
+	if hasPercent && indicator != TipIndicatorPercentageFee {
+		warnings = append(warnings, "Tag 57 is present but Tag 55 is not 03")
+	}
+	ref := subFieldValue(values[IDUPIVPAReference], UPIVPARefTransactionRef)
+	label := subFieldValue(values[IDAdditionalDataFieldTemplate], ADFReferenceLabel)
+	if ref != "" && label != "" && label != PromptValue && ref != label {
+		warnings = append(warnings, fmt.Sprintf("Tag 27.01 reference %q differs from Tag 62.05 reference label %q", ref, label))
+	}
+	return warnings
+}
+
+// subFieldValue returns the value of sub-field id in a template value, or
+// "" if it is absent or the template cannot be parsed.
+func subFieldValue(template, id string) string {
+	subs, err := parseTLV(template)
+	if err != nil {
+		return ""
+	}
+	for _, s := range subs {
+		if s.id == id {
+			return s.value
+		}
+	}
+	return ""
+}
+
+// describeValue returns a human-readable reading of a coded value, or "".
+// path is a top-level ID or "template.sub".
+func describeValue(path, value string) string {
+	switch path {
+	case IDPointOfInitiationMethod:
+		if len(value) != 2 {
+			return ""
+		}
+		method := map[byte]string{'1': "QR", '2': "BLE", '3': "NFC"}[value[0]]
+		kind := map[byte]string{'1': "static", '2': "dynamic"}[value[1]]
+		if method == "" || kind == "" {
+			return ""
+		}
+		return method + ", " + kind
+	case IDTransactionCurrency:
+		return currencyCodes[value]
+	case IDTipOrConvenienceIndicator:
+		switch value {
+		case TipIndicatorPromptConsumer:
+			return "consumer prompted for tip"
+		case TipIndicatorFixedConvenienceFee:
+			return "fixed convenience fee"
+		case TipIndicatorPercentageFee:
+			return "percentage convenience fee"
+		}
+	case IDValueConvenienceFeePercent:
+		return value + "%"
+	case IDMerchantInfoLanguageTemplate + "." + LangPreference:
+		lt := &LanguageTemplate{LanguagePreference: value}
+		if lt.RightToLeft() {
+			return lt.Script() + ", right-to-left"
+		}
+		return lt.Script()
+	case IDAdditionalDataFieldTemplate + "." + ADFAdditionalConsumerDataRequest:
+		var parts []string
+		for _, c := range value {
+			if name, ok := map[rune]string{'A': "address", 'M': "mobile", 'E': "email"}[c]; ok {
+				parts = append(parts, name)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	if strings.HasPrefix(path, IDAdditionalDataFieldTemplate+".") && value == PromptValue {
+		return "consumer prompted"
+	}
+	return ""
+}
+
+// currencyCodes maps common ISO 4217 numeric codes to alphabetic codes.
+var currencyCodes = map[string]string{
+	"036": "AUD", "050": "BDT", "124": "CAD", "144": "LKR", "156": "CNY",
+	"208": "DKK", "344": "HKD", "356": "INR", "360": "IDR", "392": "JPY",
+	"404": "KES", "410": "KRW", "458": "MYR", "484": "MXN", "524": "NPR",
+	"554": "NZD", "566": "NGN", "578": "NOK", "586": "PKR", "608": "PHP",
+	"643": "RUB", "682": "SAR", "702": "SGD", "704": "VND", "710": "ZAR",
+	"752": "SEK", "756": "CHF", "764": "THB", "784": "AED", "826": "GBP",
+	"840": "USD", "949": "TRY", "978": "EUR", "985": "PLN", "986": "BRL",
+}
@@ -0,0 +1,92 @@
+package emvqr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain_RealWorldBharatQR(t *testing.T) {
+	out, err := Explain(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatalf("Explain() error: %v", err)
+	}
+	for _, want := range []string{
+		"01 Point of Initiation Method",
+		"12 (QR, dynamic)",
+		"26 UPI VPA Template                               len 59\n   00 RuPay RID",
+		"356 (INR)",
+		"WARNING: Tag 27.02 exceeds 26 chars (got 32)",
+		"WARNING: Tag 28.01 must be 12 chars (got 0)",
+		"51DD (valid)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Explain() output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\nWarnings:") {
+		t.Errorf("unexpected payload-level warnings:\n%s", out)
+	}
+}
+
+func TestExplain_Warnings(t *testing.T) {
+	p := basePayload()
+	p.MerchantName = "ABC Hammers and Tools Co Ltd"
+	p.TransactionAmount = "1O.00"
+	p.TipOrConvenienceIndicator = TipIndicatorFixedConvenienceFee
+	raw, err := Encode(p)
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	// Corrupt the CRC and move the Payload Format Indicator.
+	raw = raw[6:len(raw)-8] + "000201" + "6304FFFF"
+
+	out, err := Explain(raw)
+	if err != nil {
+		t.Fatalf("Explain() error: %v", err)
+	}
+	for _, want := range []string{
+		"WARNING: Tag 59 exceeds 25 chars (got 28)",
+		"WARNING: Tag 54 is not a valid amount",
+		"WARNING: Tag 00 must be the first data object",
+		"WARNING: Tag 63 CRC mismatch: got FFFF",
+		"Tag 56 is required when Tag 55 is 02",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Explain() output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExplain_CRCMismatchAndMissingFields(t *testing.T) {
+	out, err := Explain("000201" + "5802US" + "6304ABCD")
+	if err != nil {
+		t.Fatalf("Explain() error: %v", err)
+	}
+	for _, want := range []string{
+		"ABCD (invalid, want ",
+		"WARNING: Tag 63 CRC mismatch",
+		"Tag 52 (Merchant Category Code) is missing",
+		"Tag 59 (Merchant Name) is missing",
+		"no Merchant Account Information",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Explain() output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExplain_InvalidTemplate(t *testing.T) {
+	out, err := Explain("000201" + "6203XYZ")
+	if err != nil {
+		t.Fatalf("Explain() error: %v", err)
+	}
+	if !strings.Contains(out, "62 Additional Data Field Template") || !strings.Contains(out, "Tag 62 is not a valid template") {
+		t.Errorf("Explain() output:\n%s", out)
+	}
+}
+
+func TestExplain_MalformedTLV(t *testing.T) {
+	if _, err := Explain("00020"); err == nil {
+		t.Error("Explain() expected error for truncated TLV, got nil")
+	}
+}
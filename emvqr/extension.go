@@ -0,0 +1,50 @@
+package emvqr
+
+import (
+	"fmt"
+	"slices"
+)
+
+// extensionIndex returns the index of the Unreserved Template with the
+// Globally Unique Identifier guid, or -1.
+func (p *Payload) extensionIndex(guid string) int {
+	return slices.IndexFunc(p.UnreservedTemplates, func(ut UnreservedTemplate) bool { return ut.GloballyUniqueID == guid })
+}
+
+// extensionFields returns the sub-fields of the Unreserved Template with
+// the Globally Unique Identifier guid, or nil.
+func (p *Payload) extensionFields(guid string) []DataObject {
+	if i := p.extensionIndex(guid); i >= 0 {
+		return p.UnreservedTemplates[i].SubFields
+	}
+	return nil
+}
+
+// setExtension replaces the sub-fields of the Unreserved Template with the
+// Globally Unique Identifier guid, creating it in the lowest free ID from
+// "80" to "99", or removes the template if subs is empty. It fails, leaving
+// p unchanged, if the template would not fit in 99 bytes or no ID is free.
+func (p *Payload) setExtension(guid string, subs []DataObject) error {
+	i := p.extensionIndex(guid)
+	if len(subs) == 0 {
+		if i >= 0 {
+			p.UnreservedTemplates = slices.Delete(p.UnreservedTemplates, i, i+1)
+		}
+		return nil
+	}
+	ut := UnreservedTemplate{GloballyUniqueID: guid, SubFields: subs}
+	if i >= 0 {
+		ut.ID = p.UnreservedTemplates[i].ID
+	} else if ut.ID = p.freeUnreservedID(); ut.ID == "" {
+		return fmt.Errorf("emvqr: no free unreserved template ID for %s", guid)
+	}
+	if _, err := encodeUnreservedTemplate(ut); err != nil {
+		return fmt.Errorf("emvqr: template %s: %w", guid, err)
+	}
+	if i >= 0 {
+		p.UnreservedTemplates[i] = ut
+	} else {
+		p.UnreservedTemplates = append(p.UnreservedTemplates, ut)
+	}
+	return nil
+}
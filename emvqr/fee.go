@@ -0,0 +1,133 @@
+package emvqr
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Fee types, as reported in FeeBreakdown.Type.
+const (
+	FeeNone       = "none"       // no Tag 55, or a fee indicator without a value
+	FeeTip        = "tip"        // the consumer is prompted for a tip (Tag 55 "01")
+	FeeFixed      = "fixed"      // fixed convenience fee (Tag 55 "02", Tag 56)
+	FeePercentage = "percentage" // percentage convenience fee (Tag 55 "03", Tag 57)
+)
+
+// currencyExponents lists the ISO 4217 numeric currencies whose minor unit
+// is not hundredths.
+var currencyExponents = map[string]int{
+	"048": 3, // BHD
+	"152": 0, // CLP
+	"368": 3, // IQD
+	"392": 0, // JPY
+	"400": 3, // JOD
+	"410": 0, // KRW
+	"414": 3, // KWD
+	"434": 3, // LYD
+	"512": 3, // OMR
+	"704": 0, // VND
+	"788": 3, // TND
+}
+
+// CurrencyExponent returns the number of minor-unit digits of an ISO 4217
+// numeric currency, e.g. 2 for "356" (INR) and 0 for "392" (JPY).
+func CurrencyExponent(currency string) int {
+	if e, ok := currencyExponents[currency]; ok {
+		return e
+	}
+	return 2
+}
+
+// FeeBreakdown discloses the convenience fee of a payment, as RBI requires
+// dynamic QR payment screens to: the base amount, the fee and the total,
+// both exactly and formatted to the minor unit of the Tag 53 currency.
+type FeeBreakdown struct {
+	Type     string // FeeNone, FeeTip, FeeFixed or FeePercentage
+	FeeValue string // Tag 56 or 57 as given, e.g. "10.00" or "3.5"
+
+	// Base, Fee and Total are exact. A percentage fee is rounded half up
+	// to the minor unit; a tip is zero until the consumer enters one.
+	Base, Fee, Total *big.Rat
+
+	// BaseDisplay, FeeDisplay and TotalDisplay format Base, Fee and Total
+	// with the currency's minor-unit digits, e.g. "257.50".
+	BaseDisplay, FeeDisplay, TotalDisplay string
+}
+
+// String describes b for a payment screen, e.g.
+// "250.00 + 7.50 convenience fee (3%) = 257.50".
+func (b FeeBreakdown) String() string {
+	switch b.Type {
+	case FeeFixed:
+		return fmt.Sprintf("%s + %s convenience fee = %s", b.BaseDisplay, b.FeeDisplay, b.TotalDisplay)
+	case FeePercentage:
+		return fmt.Sprintf("%s + %s convenience fee (%s%%) = %s", b.BaseDisplay, b.FeeDisplay, b.FeeValue, b.TotalDisplay)
+	case FeeTip:
+		return b.BaseDisplay + " + tip"
+	}
+	return b.TotalDisplay
+}
+
+// FeeBreakdown returns the breakdown of the transaction amount (Tag 54)
+// and the convenience fee set by Tags 55–57. Unlike TotalAmount it computes
+// exactly, without floating point. It returns an error if Tag 54 is absent
+// or any of the amounts is not a decimal amount.
+func (p *Payload) FeeBreakdown() (*FeeBreakdown, error) {
+	if p.TransactionAmount == "" {
+		return nil, fmt.Errorf("%w: TransactionAmount not present in payload", ErrMissingRequired)
+	}
+	base, err := parseAmount(IDTransactionAmount, p.TransactionAmount)
+	if err != nil {
+		return nil, err
+	}
+	exp := CurrencyExponent(p.TransactionCurrency)
+	b := &FeeBreakdown{Type: FeeNone, Base: base, Fee: new(big.Rat)}
+	switch p.TipOrConvenienceIndicator {
+	case TipIndicatorPromptConsumer:
+		b.Type = FeeTip
+	case TipIndicatorFixedConvenienceFee:
+		if v := p.ValueConvenienceFeeFixed; v != "" {
+			if b.Fee, err = parseAmount(IDValueConvenienceFeeFixed, v); err != nil {
+				return nil, err
+			}
+			b.Type, b.FeeValue = FeeFixed, v
+		}
+	case TipIndicatorPercentageFee:
+		if v := p.ValueConvenienceFeePercent; v != "" {
+			pct, err := parseAmount(IDValueConvenienceFeePercent, v)
+			if err != nil {
+				return nil, err
+			}
+			fee := new(big.Rat).Mul(base, pct)
+			b.Fee = roundHalfUp(fee.Quo(fee, big.NewRat(100, 1)), exp)
+			b.Type, b.FeeValue = FeePercentage, v
+		}
+	}
+	b.Total = new(big.Rat).Add(b.Base, b.Fee)
+	b.BaseDisplay = b.Base.FloatString(exp)
+	b.FeeDisplay = b.Fee.FloatString(exp)
+	b.TotalDisplay = b.Total.FloatString(exp)
+	return b, nil
+}
+
+// parseAmount parses the decimal amount s of the field id exactly.
+func parseAmount(id, s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !isAmount(s) || !ok {
+		return nil, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: %s (Tag %s) %q is not an amount", ErrInvalidFormat, topLevelSpec(id).name, id, s),
+			"tag", id, "value", s)
+	}
+	return r, nil
+}
+
+// roundHalfUp rounds the non-negative x to exp decimal places, halves
+// rounding up.
+func roundHalfUp(x *big.Rat, exp int) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+	n := new(big.Int).Mul(x.Num(), scale)
+	n.Mul(n, big.NewInt(2))
+	n.Add(n, x.Denom())
+	n.Quo(n, new(big.Int).Mul(x.Denom(), big.NewInt(2)))
+	return new(big.Rat).SetFrac(n, scale)
+}
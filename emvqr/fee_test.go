@@ -0,0 +1,65 @@
+package emvqr
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestFeeBreakdown(t *testing.T) {
+	tests := []struct {
+		name                      string
+		amount, currency, ind     string
+		fixed, percent            string
+		wantType, wantFee, wantTo string
+		wantString                string
+	}{
+		{"no fee", "250", "356", "", "", "", FeeNone, "0.00", "250.00", "250.00"},
+		{"fixed", "250.00", "356", "02", "10.5", "", FeeFixed, "10.50", "260.50", "250.00 + 10.50 convenience fee = 260.50"},
+		{"percentage", "250.00", "356", "03", "", "3", FeePercentage, "7.50", "257.50", "250.00 + 7.50 convenience fee (3%) = 257.50"},
+		{"percentage rounds half up", "10.10", "840", "03", "", "2.5", FeePercentage, "0.25", "10.35", "10.10 + 0.25 convenience fee (2.5%) = 10.35"},
+		{"JPY has no minor unit", "1005", "392", "03", "", "1.5", FeePercentage, "15", "1020", "1005 + 15 convenience fee (1.5%) = 1020"},
+		{"tip", "99.99", "356", "01", "", "", FeeTip, "0.00", "99.99", "99.99 + tip"},
+		{"fixed without value", "5", "356", "02", "", "", FeeNone, "0.00", "5.00", "5.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := basePayload()
+			p.TransactionAmount, p.TransactionCurrency = tt.amount, tt.currency
+			p.TipOrConvenienceIndicator = tt.ind
+			p.ValueConvenienceFeeFixed, p.ValueConvenienceFeePercent = tt.fixed, tt.percent
+			b, err := p.FeeBreakdown()
+			if err != nil {
+				t.Fatalf("FeeBreakdown() error: %v", err)
+			}
+			assertEqual(t, "Type", tt.wantType, b.Type)
+			assertEqual(t, "FeeDisplay", tt.wantFee, b.FeeDisplay)
+			assertEqual(t, "TotalDisplay", tt.wantTo, b.TotalDisplay)
+			assertEqual(t, "String", tt.wantString, b.String())
+		})
+	}
+}
+
+func TestFeeBreakdown_Exact(t *testing.T) {
+	p := basePayload()
+	p.TransactionAmount = "0.10"
+	p.SetFixedConvenienceFee("0.20")
+	b, err := p.FeeBreakdown()
+	if err != nil {
+		t.Fatalf("FeeBreakdown() error: %v", err)
+	}
+	assertEqual(t, "Total", "3/10", b.Total.String())
+}
+
+func TestFeeBreakdown_Errors(t *testing.T) {
+	p := basePayload()
+	if _, err := p.FeeBreakdown(); !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("FeeBreakdown() without amount error = %v, want ErrMissingRequired", err)
+	}
+	p.TransactionAmount = "10"
+	p.SetPercentageConvenienceFee("3%")
+	if _, err := p.FeeBreakdown(); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("FeeBreakdown() with a bad percentage error = %v, want ErrInvalidFormat", err)
+	}
+	assertEqual(t, "CurrencyExponent(414)", "3", strconv.Itoa(CurrencyExponent("414")))
+}
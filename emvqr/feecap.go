@@ -0,0 +1,159 @@
+package emvqr
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// FeeCap is the largest convenience fee a scheme or bank allows. A fee
+// breaks the cap if either limit is exceeded.
+type FeeCap struct {
+	// Fixed is the largest fee, e.g. "50.00": the Tag 56 fee, or the fee a
+	// Tag 57 percentage yields on the Tag 54 amount. "0" forbids a fee;
+	// "" sets no limit.
+	Fixed string
+
+	// Percent is the largest fee as a percentage of the amount, e.g.
+	// "2.00": the Tag 57 percentage, or the share of the Tag 54 amount a
+	// Tag 56 fee makes up. "" sets no limit.
+	Percent string
+}
+
+// FeePolicy holds the convenience fee caps of a scheme or bank, per
+// Merchant Category Code, e.g. a ban on surcharges for fuel or utilities.
+// A FeePolicy is safe for concurrent use.
+type FeePolicy struct {
+	mu   sync.RWMutex
+	caps map[string]FeeCap
+	def  *FeeCap
+}
+
+// NewFeePolicy returns a FeePolicy without caps, which allows every fee.
+func NewFeePolicy() *FeePolicy {
+	return &FeePolicy{caps: make(map[string]FeeCap)}
+}
+
+// SetCap sets the cap for the merchant category codes mccs, replacing any
+// set before. It returns an error wrapping ErrInvalidFormat if a limit is
+// not an amount or an MCC is not 4 digits.
+func (fp *FeePolicy) SetCap(c FeeCap, mccs ...string) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	for _, mcc := range mccs {
+		if len(mcc) != 4 || !isNumeric(mcc) {
+			return fmt.Errorf("%w: fee cap MCC %q must be 4 digits", ErrInvalidFormat, mcc)
+		}
+	}
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	for _, mcc := range mccs {
+		fp.caps[mcc] = c
+	}
+	return nil
+}
+
+// SetDefaultCap sets the cap for merchant categories without one of their
+// own.
+func (fp *FeePolicy) SetDefaultCap(c FeeCap) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	fp.def = &c
+	return nil
+}
+
+// Cap returns the cap for a merchant category code, and whether there is
+// one.
+func (fp *FeePolicy) Cap(mcc string) (FeeCap, bool) {
+	fp.mu.RLock()
+	defer fp.mu.RUnlock()
+	if c, ok := fp.caps[mcc]; ok {
+		return c, true
+	}
+	if fp.def != nil {
+		return *fp.def, true
+	}
+	return FeeCap{}, false
+}
+
+func (c FeeCap) validate() error {
+	for _, v := range []string{c.Fixed, c.Percent} {
+		if v != "" && !isAmount(v) {
+			return fmt.Errorf("%w: fee cap %q is not an amount", ErrInvalidFormat, v)
+		}
+	}
+	return nil
+}
+
+// CheckFeeCap checks the convenience fee of p (Tags 55–57) against the cap
+// fp sets for its Merchant Category Code (Tag 52). Tips, which the
+// consumer chooses, are not capped; a fee relative to the amount is only
+// checked when Tag 54 is present. It returns an *Error wrapping
+// ErrFeeCapExceeded, with the "tag", "mcc" and "cap" params, for a fee over
+// the cap; an error wrapping ErrInvalidFormat if a fee is not an amount;
+// and nil otherwise, or if fp is nil. EncodeOptions.FeePolicy applies it.
+func CheckFeeCap(p *Payload, fp *FeePolicy) error {
+	if fp == nil {
+		return nil
+	}
+	c, ok := fp.Cap(p.MerchantCategoryCode)
+	if !ok {
+		return nil
+	}
+	var fee, pct *big.Rat // the fee as an amount and as a percentage, nil if unknown
+	var err error
+	var id string
+	switch p.TipOrConvenienceIndicator {
+	case TipIndicatorFixedConvenienceFee:
+		if id = IDValueConvenienceFeeFixed; p.ValueConvenienceFeeFixed == "" {
+			return nil
+		}
+		if fee, err = parseAmount(id, p.ValueConvenienceFeeFixed); err != nil {
+			return err
+		}
+	case TipIndicatorPercentageFee:
+		if id = IDValueConvenienceFeePercent; p.ValueConvenienceFeePercent == "" {
+			return nil
+		}
+		if pct, err = parseAmount(id, p.ValueConvenienceFeePercent); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	if p.TransactionAmount != "" {
+		base, err := parseAmount(IDTransactionAmount, p.TransactionAmount)
+		if err != nil {
+			return err
+		}
+		switch {
+		case fee == nil:
+			fee = new(big.Rat).Mul(base, pct)
+			fee.Quo(fee, big.NewRat(100, 1))
+		case base.Sign() > 0:
+			pct = new(big.Rat).Mul(fee, big.NewRat(100, 1))
+			pct.Quo(pct, base)
+		}
+	}
+	exceeds := func(value *big.Rat, limit, unit string) error {
+		if value == nil || limit == "" {
+			return nil
+		}
+		ceiling, _ := new(big.Rat).SetString(limit)
+		if value.Cmp(ceiling) <= 0 {
+			return nil
+		}
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: fee of %s%s for MCC %s is over the cap of %s%s",
+				ErrFeeCapExceeded, value.FloatString(2), unit, p.MerchantCategoryCode, limit, unit),
+			"tag", id, "mcc", p.MerchantCategoryCode, "cap", limit)
+	}
+	if err := exceeds(fee, c.Fixed, ""); err != nil {
+		return err
+	}
+	return exceeds(pct, c.Percent, "%")
+}
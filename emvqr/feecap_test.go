@@ -0,0 +1,79 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckFeeCap(t *testing.T) {
+	fp := NewFeePolicy()
+	if err := fp.SetCap(FeeCap{Fixed: "0"}, "5541", "5542"); err != nil { // no surcharge on fuel
+		t.Fatal(err)
+	}
+	if err := fp.SetDefaultCap(FeeCap{Fixed: "50", Percent: "2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, mcc, amount, indicator, fee string
+		wantErr                           bool
+	}{
+		{"no fee", "5541", "100", "", "", false},
+		{"tip is not capped", "5541", "100", TipIndicatorPromptConsumer, "", false},
+		{"fuel fixed fee", "5541", "100", TipIndicatorFixedConvenienceFee, "1", true},
+		{"fixed fee within cap", "5251", "1000", TipIndicatorFixedConvenienceFee, "20", false},
+		{"fixed fee over percent cap", "5251", "100", TipIndicatorFixedConvenienceFee, "2.50", true},
+		{"fixed fee over fixed cap", "5251", "", TipIndicatorFixedConvenienceFee, "50.01", true},
+		{"percent within cap", "5251", "1000", TipIndicatorPercentageFee, "2.00", false},
+		{"percent over cap", "5251", "", TipIndicatorPercentageFee, "2.5", true},
+		{"percent over fixed cap", "5251", "5000", TipIndicatorPercentageFee, "1.5", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := basePayload()
+			p.MerchantCategoryCode, p.TransactionAmount = tt.mcc, tt.amount
+			switch tt.indicator {
+			case TipIndicatorPromptConsumer:
+				p.SetPromptForTip()
+			case TipIndicatorFixedConvenienceFee:
+				p.SetFixedConvenienceFee(tt.fee)
+			case TipIndicatorPercentageFee:
+				p.SetPercentageConvenienceFee(tt.fee)
+			}
+			err := CheckFeeCap(p, fp)
+			if got := errors.Is(err, ErrFeeCapExceeded); got != tt.wantErr {
+				t.Fatalf("CheckFeeCap() error = %v, want cap exceeded %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrSchemeViolation) || ErrorParams(err)["mcc"] != tt.mcc {
+					t.Errorf("CheckFeeCap() error = %v, params %v", err, ErrorParams(err))
+				}
+				if _, err := EncodeWithOptions(p, EncodeOptions{FeePolicy: fp}); !errors.Is(err, ErrFeeCapExceeded) {
+					t.Errorf("Encode(FeePolicy) error = %v, want ErrFeeCapExceeded", err)
+				}
+			}
+		})
+	}
+}
+
+func TestFeePolicy_Errors(t *testing.T) {
+	fp := NewFeePolicy()
+	if err := fp.SetCap(FeeCap{Percent: "2%"}, "5411"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("SetCap(2%%) error = %v, want ErrInvalidFormat", err)
+	}
+	if err := fp.SetCap(FeeCap{Fixed: "1"}, "541"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("SetCap(MCC 541) error = %v, want ErrInvalidFormat", err)
+	}
+	if _, ok := fp.Cap("5411"); ok {
+		t.Error("Cap() found a cap in an empty policy")
+	}
+
+	p := basePayload()
+	p.SetFixedConvenienceFee("1")
+	if err := CheckFeeCap(p, nil); err != nil {
+		t.Errorf("CheckFeeCap(nil) error: %v", err)
+	}
+	if err := CheckFeeCap(p, fp); err != nil {
+		t.Errorf("CheckFeeCap() without caps error: %v", err)
+	}
+}
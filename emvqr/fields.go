@@ -0,0 +1,194 @@
+package emvqr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fieldFormat is the EMV QRCPS data format of a field value.
+type fieldFormat int
+
+const (
+	formatANS    fieldFormat = iota // any character from the Common Character Set
+	formatN                         // digits only
+	formatAmount                    // digits with an optional single '.'
+)
+
+// String returns the short name of the format: "ans", "n" or "amount".
+func (f fieldFormat) String() string {
+	switch f {
+	case formatN:
+		return "n"
+	case formatAmount:
+		return "amount"
+	}
+	return "ans"
+}
+
+// fieldSpec is the name, format and length range of a data object.
+type fieldSpec struct {
+	name           string
+	format         fieldFormat
+	minLen, maxLen int
+}
+
+// topLevelSpecs describes the fixed top-level data objects (EMV QRCPS MPM
+// v1.1 Table 3.6 plus the Bharat QR templates "26"–"28").
+var topLevelSpecs = map[string]fieldSpec{
+	IDPayloadFormatIndicator:       {"Payload Format Indicator", formatN, 2, 2},
+	IDPointOfInitiationMethod:      {"Point of Initiation Method", formatN, 2, 2},
+	IDUPIVPATemplate:               {"UPI VPA Template", formatANS, 1, 99},
+	IDUPIVPAReference:              {"UPI VPA Reference", formatANS, 1, 99},
+	IDAadhaarTemplate:              {"Aadhaar Template", formatANS, 1, 99},
+	IDMerchantCategoryCode:         {"Merchant Category Code", formatN, 4, 4},
+	IDTransactionCurrency:          {"Transaction Currency", formatN, 3, 3},
+	IDTransactionAmount:            {"Transaction Amount", formatAmount, 1, 13},
+	IDTipOrConvenienceIndicator:    {"Tip or Convenience Indicator", formatN, 2, 2},
+	IDValueConvenienceFeeFixed:     {"Value of Convenience Fee Fixed", formatAmount, 1, 13},
+	IDValueConvenienceFeePercent:   {"Value of Convenience Fee Percentage", formatAmount, 1, 5},
+	IDCountryCode:                  {"Country Code", formatANS, 2, 2},
+	IDMerchantName:                 {"Merchant Name", formatANS, 1, 25},
+	IDMerchantCity:                 {"Merchant City", formatANS, 1, 15},
+	IDPostalCode:                   {"Postal Code", formatANS, 1, 10},
+	IDAdditionalDataFieldTemplate:  {"Additional Data Field Template", formatANS, 1, 99},
+	IDCRC:                          {"CRC", formatANS, 4, 4},
+	IDMerchantInfoLanguageTemplate: {"Merchant Information - Language Template", formatANS, 1, 99},
+}
+
+// subFieldSpecs describes template sub-fields, keyed by template ID.
+var subFieldSpecs = map[string]map[string]fieldSpec{
+	IDAdditionalDataFieldTemplate: {
+		ADFBillNumber:                    {"Bill Number", formatANS, 1, 25},
+		ADFMobileNumber:                  {"Mobile Number", formatANS, 1, 25},
+		ADFStoreLabel:                    {"Store Label", formatANS, 1, 25},
+		ADFLoyaltyNumber:                 {"Loyalty Number", formatANS, 1, 25},
+		ADFReferenceLabel:                {"Reference Label", formatANS, 1, 25},
+		ADFCustomerLabel:                 {"Customer Label", formatANS, 1, 25},
+		ADFTerminalLabel:                 {"Terminal Label", formatANS, 1, 25},
+		ADFPurposeOfTransaction:          {"Purpose of Transaction", formatANS, 1, 25},
+		ADFAdditionalConsumerDataRequest: {"Additional Consumer Data Request", formatANS, 1, 3},
+		ADFMerchantTaxID:                 {"Merchant Tax ID", formatANS, 1, 20},
+		ADFMerchantChannel:               {"Merchant Channel", formatANS, 3, 3},
+	},
+	IDMerchantInfoLanguageTemplate: {
+		LangPreference:   {"Language Preference", formatANS, 2, 2},
+		LangMerchantName: {"Alternate Merchant Name", formatANS, 1, 25},
+		LangMerchantCity: {"Alternate Merchant City", formatANS, 1, 15},
+	},
+	IDUPIVPATemplate: {
+		MAIGloballyUniqueID: {"RuPay RID", formatANS, 10, 10},
+		"01":                {"VPA", formatANS, 1, 99},
+		"02":                {"Minimum Amount", formatAmount, 1, 13},
+	},
+	IDUPIVPAReference: {
+		UPIVPARefRuPayRID:       {"RuPay RID", formatANS, 10, 10},
+		UPIVPARefTransactionRef: {"Transaction Reference", formatANS, 4, 35},
+		UPIVPARefURL:            {"Reference URL", formatANS, 1, 26},
+	},
+	IDAadhaarTemplate: {
+		AadhaarRuPayRID:   {"RuPay RID", formatANS, 10, 10},
+		AadhaarAadhaarNum: {"Aadhaar Number", formatN, 12, 12},
+	},
+}
+
+// topLevelSpec returns the spec of a top-level ID, naming the ranges the
+// specification reserves rather than defines individually.
+func topLevelSpec(id string) fieldSpec {
+	if s, ok := topLevelSpecs[id]; ok {
+		return s
+	}
+	n, err := strconv.Atoi(id)
+	switch {
+	case err != nil:
+		return fieldSpec{"Unknown", formatANS, 0, 99}
+	case n >= 2 && n <= 25:
+		if network, ok := NetworkName(id); ok {
+			return fieldSpec{"Merchant Account Information (" + network + ")", formatANS, 1, 99}
+		}
+		return fieldSpec{"Merchant Account Information", formatANS, 1, 99}
+	case n >= 29 && n <= 51:
+		return fieldSpec{"Merchant Account Information Template", formatANS, 1, 99}
+	case n >= 80 && n <= 99:
+		return fieldSpec{"Unreserved Template", formatANS, 1, 99}
+	}
+	return fieldSpec{"RFU for EMVCo", formatANS, 0, 99}
+}
+
+// subFieldSpec returns the spec of sub-field id within template parent.
+func subFieldSpec(parent, id string) fieldSpec {
+	if s, ok := subFieldSpecs[parent][id]; ok {
+		return s
+	}
+	switch {
+	case parent == IDAdditionalDataFieldTemplate && isPaymentSystemTemplateID(id):
+		return fieldSpec{"Payment System Specific Template", formatANS, 1, 99}
+	case parent == IDAdditionalDataFieldTemplate || parent == IDMerchantInfoLanguageTemplate:
+		return fieldSpec{"RFU for EMVCo", formatANS, 0, 99}
+	case id == MAIGloballyUniqueID:
+		return fieldSpec{"Globally Unique Identifier", formatANS, 1, 32}
+	}
+	return fieldSpec{"Context Specific Data", formatANS, 1, 99}
+}
+
+// pathSpec returns the spec of a dotted tag path as produced by Flatten.
+func pathSpec(path string) fieldSpec {
+	ids := strings.Split(path, ".")
+	if len(ids) == 1 {
+		return topLevelSpec(path)
+	}
+	return subFieldSpec(ids[len(ids)-2], ids[len(ids)-1])
+}
+
+// check returns a warning for each way value violates s. label identifies
+// the field in messages, e.g. "Tag 59" or "Tag 62.05".
+func (s fieldSpec) check(label, value string) []string {
+	var warnings []string
+	switch n := len(value); {
+	case s.minLen == s.maxLen && n != s.minLen:
+		warnings = append(warnings, fmt.Sprintf("%s must be %d chars (got %d)", label, s.minLen, n))
+	case n > s.maxLen:
+		warnings = append(warnings, fmt.Sprintf("%s exceeds %d chars (got %d)", label, s.maxLen, n))
+	case n < s.minLen:
+		warnings = append(warnings, fmt.Sprintf("%s is shorter than %d chars (got %d)", label, s.minLen, n))
+	}
+	switch s.format {
+	case formatN:
+		if !isNumeric(value) {
+			warnings = append(warnings, fmt.Sprintf("%s must be numeric", label))
+		}
+	case formatAmount:
+		if !isAmount(value) {
+			warnings = append(warnings, fmt.Sprintf("%s is not a valid amount", label))
+		}
+	}
+	return warnings
+}
+
+// isNumeric reports whether s consists only of ASCII digits.
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAmount reports whether s is digits with at most one '.', and at least
+// one digit.
+func isAmount(s string) bool {
+	dot := false
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '.' && !dot:
+			dot = true
+		case c >= '0' && c <= '9':
+			digits++
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
@@ -0,0 +1,38 @@
+package emvqr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+)
+
+// Fingerprint returns a hex-encoded SHA-256 hash of the payload's semantic
+// content: its Flatten paths and values, sorted by path. The CRC, the order
+// in which fields were encoded and empty fields do not contribute, so two
+// stickers that carry the same data share a fingerprint, and any change to
+// a field's value changes it. The fingerprint is stable across library
+// versions for the fields Flatten reports.
+func (p *Payload) Fingerprint() string {
+	m := p.Flatten()
+	delete(m, IDCRC)
+	paths := make([]string, 0, len(m))
+	for path := range m {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	var buf []byte
+	for _, path := range paths {
+		// Length-prefix both parts so no value can forge a boundary.
+		buf = strconv.AppendInt(buf[:0], int64(len(path)), 10)
+		buf = append(buf, ':')
+		buf = append(buf, path...)
+		buf = strconv.AppendInt(buf, int64(len(m[path])), 10)
+		buf = append(buf, ':')
+		buf = append(buf, m[path]...)
+		h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package emvqr
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	p := mustDecode(t, realWorldBharatQRPayload)
+	fp := p.Fingerprint()
+	if len(fp) != 64 {
+		t.Fatalf("Fingerprint() = %q, want 64 hex digits", fp)
+	}
+
+	t.Run("ignores CRC", func(t *testing.T) {
+		q := mustDecode(t, realWorldBharatQRPayload)
+		q.CRC = "0000"
+		assertEqual(t, "fingerprint", fp, q.Fingerprint())
+	})
+
+	t.Run("ignores ordering", func(t *testing.T) {
+		q := mustDecode(t, realWorldBharatQRPayload)
+		mi := q.MerchantIdentifiers
+		for i, j := 0, len(mi)-1; i < j; i, j = i+1, j-1 {
+			mi[i], mi[j] = mi[j], mi[i]
+		}
+		assertEqual(t, "fingerprint", fp, q.Fingerprint())
+	})
+
+	t.Run("survives round trip", func(t *testing.T) {
+		q := mustDecode(t, mustEncode(t, p))
+		assertEqual(t, "fingerprint", fp, q.Fingerprint())
+	})
+
+	t.Run("detects changes", func(t *testing.T) {
+		q := mustDecode(t, realWorldBharatQRPayload)
+		q.MerchantCity += "X"
+		if q.Fingerprint() == fp {
+			t.Error("fingerprint unchanged after editing merchant city")
+		}
+	})
+
+	t.Run("no boundary collisions", func(t *testing.T) {
+		a, b := basePayload(), basePayload()
+		a.MerchantName, a.MerchantCity = "AB", "C"
+		b.MerchantName, b.MerchantCity = "A", "BC"
+		if a.Fingerprint() == b.Fingerprint() {
+			t.Error("different name/city split share a fingerprint")
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package emvqr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxFlatDepth is the deepest tag path Flatten produces: a payment system
+// template inside the Additional Data Field Template, e.g. "62.50.01".
+const maxFlatDepth = 3
+
+// Flatten returns the payload as a map from dotted tag paths to values, e.g.
+// "59" → merchant name, "62.05" → reference label, "26.01" → UPI VPA. It is
+// intended for loading decoded QRs into columnar stores. Empty fields are
+// omitted; templates appear only through their sub-fields.
+//
+// Merchant Account Information templates ("29"–"51") are split into
+// sub-fields when their values parse as TLV; otherwise the raw value is kept
+// under the template ID. A nil payload flattens to an empty map.
+func (p *Payload) Flatten() map[string]string {
+	m := make(map[string]string)
+	if p == nil {
+		return m
+	}
+	put := func(path, value string) {
+		if value != "" {
+			m[path] = value
+		}
+	}
+	putObjects := func(prefix string, objects []DataObject) {
+		for _, d := range objects {
+			put(prefix+d.ID, d.Value)
+		}
+	}
+	putTemplate := func(prefix string, ut UnreservedTemplate) {
+		put(prefix+ut.ID+"."+MAIGloballyUniqueID, ut.GloballyUniqueID)
+		putObjects(prefix+ut.ID+".", ut.SubFields)
+	}
+
+	put(IDPayloadFormatIndicator, p.PayloadFormatIndicator)
+	put(IDPointOfInitiationMethod, string(p.PointOfInitiationMethod))
+	for _, mi := range p.MerchantIdentifiers {
+		switch mi.ID {
+		case IDUPIVPATemplate, IDUPIVPAReference, IDAadhaarTemplate:
+			// Encoded from the typed fields below.
+			continue
+		}
+		if subs, err := parseTLV(mi.Value); err == nil && isTemplateID(mi.ID) && len(subs) > 0 {
+			for _, s := range subs {
+				put(mi.ID+"."+s.id, s.value)
+			}
+			continue
+		}
+		put(mi.ID, mi.Value)
+	}
+	if v := p.UPIVPAInfo; v != nil {
+		put(IDUPIVPATemplate+"."+MAIGloballyUniqueID, v.RuPayRID)
+		put(IDUPIVPATemplate+".01", v.VPA)
+		put(IDUPIVPATemplate+".02", v.MinimumAmount)
+	}
+	if r := p.UPITransactionRef; r != nil {
+		put(IDUPIVPAReference+"."+UPIVPARefRuPayRID, r.RuPayRID)
+		put(IDUPIVPAReference+"."+UPIVPARefTransactionRef, r.TransactionRef)
+		put(IDUPIVPAReference+"."+UPIVPARefURL, r.ReferenceURL)
+	}
+	if a := p.MerchantAadhaar; a != nil {
+		put(IDAadhaarTemplate+"."+AadhaarRuPayRID, a.RuPayRID)
+		put(IDAadhaarTemplate+"."+AadhaarAadhaarNum, a.AadhaarNumber)
+	}
+
+	put(IDMerchantCategoryCode, p.MerchantCategoryCode)
+	put(IDTransactionCurrency, p.TransactionCurrency)
+	put(IDTransactionAmount, p.TransactionAmount)
+	put(IDTipOrConvenienceIndicator, p.TipOrConvenienceIndicator)
+	put(IDValueConvenienceFeeFixed, p.ValueConvenienceFeeFixed)
+	put(IDValueConvenienceFeePercent, p.ValueConvenienceFeePercent)
+	put(IDCountryCode, p.CountryCode)
+	put(IDMerchantName, p.MerchantName)
+	put(IDMerchantCity, p.MerchantCity)
+	put(IDPostalCode, p.PostalCode)
+
+	if a := p.AdditionalData; a != nil {
+		prefix := IDAdditionalDataFieldTemplate + "."
+		put(prefix+ADFBillNumber, a.BillNumber)
+		put(prefix+ADFMobileNumber, a.MobileNumber)
+		put(prefix+ADFStoreLabel, a.StoreLabel)
+		put(prefix+ADFLoyaltyNumber, a.LoyaltyNumber)
+		put(prefix+ADFReferenceLabel, a.ReferenceLabel)
+		put(prefix+ADFCustomerLabel, a.CustomerLabel)
+		put(prefix+ADFTerminalLabel, a.TerminalLabel)
+		put(prefix+ADFPurposeOfTransaction, a.PurposeOfTransaction)
+		put(prefix+ADFAdditionalConsumerDataRequest, a.AdditionalConsumerDataRequest)
+		put(prefix+ADFMerchantTaxID, a.MerchantTaxID)
+		put(prefix+ADFMerchantChannel, a.MerchantChannel)
+		for _, pst := range a.PaymentSystemTemplates {
+			putTemplate(prefix, pst)
+		}
+		putObjects(prefix, a.RFUFields)
+	}
+	if lt := p.LanguageTemplate; lt != nil {
+		prefix := IDMerchantInfoLanguageTemplate + "."
+		put(prefix+LangPreference, lt.LanguagePreference)
+		put(prefix+LangMerchantName, lt.MerchantName)
+		put(prefix+LangMerchantCity, lt.MerchantCity)
+		putObjects(prefix, lt.RFUFields)
+	}
+	for _, ut := range p.UnreservedTemplates {
+		putTemplate("", ut)
+	}
+	putObjects("", p.RFUFields)
+	put(IDCRC, p.CRC)
+	return m
+}
+
+// FromFlat builds a Payload from a map produced by Flatten. It is equivalent
+// to FromFlatWithOptions with zero options.
+func FromFlat(m map[string]string) (*Payload, error) {
+	return FromFlatWithOptions(m, DecodeOptions{})
+}
+
+// FromFlatWithOptions builds a Payload from dotted tag paths. The paths are
+// assembled into TLV data objects, with sub-fields in ascending tag order,
+// and interpreted exactly as DecodeWithOptions would interpret them; for
+// example opts.SpecVersion decides whether "62.10" becomes MerchantTaxID or
+// an RFU field. The CRC is not validated, no fields are mandatory and empty
+// values are ignored.
+func FromFlatWithOptions(m map[string]string, opts DecodeOptions) (*Payload, error) {
+	if !opts.SpecVersion.valid() {
+		return nil, unknownSpecVersion(opts.SpecVersion)
+	}
+	root := &flatNode{}
+	for path, value := range m {
+		if value == "" {
+			continue
+		}
+		if err := root.insert(path, value); err != nil {
+			return nil, err
+		}
+	}
+	p := &Payload{}
+	for _, id := range root.ids() {
+		value, err := root.children[id].encode(id)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.applyObject(tlvObject{id: id, value: value}, opts); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// flatNode is a tag path trie: a leaf carries a value, an inner node carries
+// the sub-fields of a template.
+type flatNode struct {
+	value    string
+	children map[string]*flatNode
+}
+
+// flatTree returns the paths of p.Flatten as a trie.
+func (p *Payload) flatTree() (*flatNode, error) {
+	root := &flatNode{}
+	for path, value := range p.Flatten() {
+		if err := root.insert(path, value); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// insert adds value at the dotted path.
+func (n *flatNode) insert(path, value string) error {
+	segments := strings.Split(path, ".")
+	if len(segments) > maxFlatDepth {
+		return fmt.Errorf("%w: flat key %q is nested deeper than %d levels", ErrInvalidTLV, path, maxFlatDepth)
+	}
+	for i, seg := range segments {
+		if len(seg) != 2 || !isNumeric(seg) {
+			return fmt.Errorf("%w: flat key %q is not a dotted path of two-digit tags", ErrInvalidTLV, path)
+		}
+		if n.value != "" {
+			return fmt.Errorf("%w: flat key %q is inside primitive %q", ErrInvalidTLV, path, strings.Join(segments[:i], "."))
+		}
+		if n.children == nil {
+			n.children = make(map[string]*flatNode)
+		}
+		child, ok := n.children[seg]
+		if !ok {
+			child = &flatNode{}
+			n.children[seg] = child
+		}
+		n = child
+	}
+	if n.children != nil {
+		return fmt.Errorf("%w: flat key %q has both a value and sub-fields", ErrInvalidTLV, path)
+	}
+	n.value = value
+	return nil
+}
+
+// ids returns the child tags in ascending order.
+func (n *flatNode) ids() []string {
+	ids := make([]string, 0, len(n.children))
+	for id := range n.children {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// encode returns the TLV value of the node: its own value for a leaf, or its
+// sub-fields encoded in tag order for a template.
+func (n *flatNode) encode(path string) (string, error) {
+	if n.children == nil {
+		return n.value, nil
+	}
+	var sb strings.Builder
+	for _, id := range n.ids() {
+		value, err := n.children[id].encode(path + "." + id)
+		if err != nil {
+			return "", err
+		}
+		chunk, err := encodeTLV(id, value)
+		if err != nil {
+			return "", fmt.Errorf("emvqr: flat key %s.%s: %w", path, id, err)
+		}
+		sb.WriteString(chunk)
+	}
+	return sb.String(), nil
+}
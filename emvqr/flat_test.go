@@ -0,0 +1,126 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFlatten_RealWorldBharatQR(t *testing.T) {
+	// Flat maps cannot hold the empty Tag 28.01, so compare without it.
+	p, err := DecodeWithOptions(realWorldBharatQRPayload, DecodeOptions{DropEmptySubFields: true})
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	flat := p.Flatten()
+	for path, want := range map[string]string{
+		"00":    "01",
+		"01":    "12",
+		"02":    "4585191041044894",
+		"26.00": RuPayRIDValue,
+		"26.01": "SBIPMOPAD.02PL00000644432-21503961@SBIPAY",
+		"27.01": "52602091445452087569609",
+		"59":    "APRIL MOON RETAIL PRIVA",
+		"62.05": "52602091445452087569609",
+		"62.07": "21503961",
+		"63":    "51DD",
+	} {
+		if got := flat[path]; got != want {
+			t.Errorf("Flatten()[%q] = %q, want %q", path, got, want)
+		}
+	}
+	for _, path := range []string{"26", "62", "28.01"} {
+		if v, ok := flat[path]; ok {
+			t.Errorf("Flatten() has unexpected key %q = %q", path, v)
+		}
+	}
+
+	back, err := FromFlat(flat)
+	if err != nil {
+		t.Fatalf("FromFlat() error: %v", err)
+	}
+	if diff := cmp.Diff(p, back); diff != "" {
+		t.Errorf("FromFlat(Flatten()) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFlatten_TemplatesRoundTrip(t *testing.T) {
+	p := basePayload()
+	p.MerchantIdentifiers = append(p.MerchantIdentifiers, MerchantIdentifier{ID: "29", Value: "0004test0105ABCDE"})
+	p.SetLanguageTemplate("zh", "北京", "北京")
+	p.SetAdditionalData(func(a *AdditionalDataField) {
+		a.ReferenceLabel = "R555"
+		a.MerchantTaxID = "TAX-1"
+		a.PaymentSystemTemplates = []UnreservedTemplate{{ID: "50", GloballyUniqueID: "com.example", SubFields: []DataObject{{ID: "01", Value: "X"}}}}
+	})
+	p.UnreservedTemplates = []UnreservedTemplate{{ID: "80", GloballyUniqueID: "A0000001", SubFields: []DataObject{{ID: "01", Value: "abc"}}}}
+	raw, err := EncodeWithOptions(p, EncodeOptions{SpecVersion: SpecVersion11})
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	opts := DecodeOptions{SpecVersion: SpecVersion11}
+	decoded, err := DecodeWithOptions(raw, opts)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+
+	flat := decoded.Flatten()
+	for path, want := range map[string]string{
+		"29.01":    "ABCDE",
+		"62.05":    "R555",
+		"62.10":    "TAX-1",
+		"62.50.00": "com.example",
+		"62.50.01": "X",
+		"64.01":    "北京",
+		"80.00":    "A0000001",
+		"80.01":    "abc",
+	} {
+		if got := flat[path]; got != want {
+			t.Errorf("Flatten()[%q] = %q, want %q", path, got, want)
+		}
+	}
+
+	back, err := FromFlatWithOptions(flat, opts)
+	if err != nil {
+		t.Fatalf("FromFlatWithOptions() error: %v", err)
+	}
+	again, err := EncodeWithOptions(back, EncodeOptions{SpecVersion: SpecVersion11})
+	if err != nil {
+		t.Fatalf("Encode() of FromFlat payload error: %v", err)
+	}
+	if again != raw {
+		t.Errorf("re-encoded = %q, want %q", again, raw)
+	}
+}
+
+func TestFlatten_Nil(t *testing.T) {
+	var p *Payload
+	if m := p.Flatten(); m == nil || len(m) != 0 {
+		t.Errorf("nil Flatten() = %v, want an empty map", m)
+	}
+	if got, want := p.Fingerprint(), (&Payload{}).Fingerprint(); got != want {
+		t.Errorf("nil Fingerprint() = %s, want %s", got, want)
+	}
+}
+
+func TestFromFlat_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		m    map[string]string
+	}{
+		{"bad segment", map[string]string{"5": "x"}},
+		{"non-numeric", map[string]string{"62.AB": "x"}},
+		{"too deep", map[string]string{"62.50.01.01": "x"}},
+		{"value and sub-fields", map[string]string{"62": "0103abc", "62.05": "R555"}},
+		{"value too long", map[string]string{"62.05": string(make([]byte, 100))}},
+	}
+	for _, tc := range tests {
+		if _, err := FromFlat(tc.m); err == nil {
+			t.Errorf("%s: FromFlat() expected error, got nil", tc.name)
+		}
+	}
+	if _, err := FromFlat(map[string]string{"5": "x"}); !errors.Is(err, ErrInvalidTLV) {
+		t.Errorf("FromFlat() error = %v, want ErrInvalidTLV", err)
+	}
+}
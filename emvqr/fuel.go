@@ -0,0 +1,127 @@
+package emvqr
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// Merchant Category Codes of fuel retailers.
+const (
+	MCCServiceStation = "5541" // service stations, with or without ancillary services
+	MCCFuelDispenser  = "5542" // automated fuel dispensers
+)
+
+// fuelMCCs are the Merchant Category Codes ValidateFuel accepts.
+var fuelMCCs = []string{MCCServiceStation, MCCFuelDispenser}
+
+// FuelGUID is the Globally Unique Identifier of the Unreserved Template in
+// which SetFuelInfo stores the pump-side details of a fuel QR.
+const FuelGUID = "COM.GITHUB.EMVQR.FUEL"
+
+// Sub-field IDs of the fuel template.
+const (
+	FuelPump          = "01" // pump or nozzle number, e.g. "07"
+	FuelPreAuthAmount = "02" // amount held before fuelling, e.g. "2000.00"
+)
+
+// FuelInfo holds the pump-side details of a fuel station's dynamic QR.
+type FuelInfo struct {
+	Pump          string // 1–8 letters and digits
+	PreAuthAmount string // optional decimal amount above 0, in the Tag 53 currency
+}
+
+// validateFuelInfo checks the fields of f, returning an error wrapping
+// ErrInvalidFormat.
+func validateFuelInfo(f FuelInfo) error {
+	ok := len(f.Pump) >= 1 && len(f.Pump) <= 8
+	for i := 0; ok && i < len(f.Pump); i++ {
+		c := f.Pump[i]
+		ok = c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+	}
+	if !ok {
+		return fmt.Errorf("%w: fuel pump %q must be 1-8 letters and digits", ErrInvalidFormat, f.Pump)
+	}
+	if f.PreAuthAmount != "" && !validSuggestedAmount(f.PreAuthAmount) {
+		return fmt.Errorf("%w: fuel pre-auth amount %q must be a decimal amount above 0 of at most 13 characters",
+			ErrInvalidFormat, f.PreAuthAmount)
+	}
+	return nil
+}
+
+// SetFuelInfo stores f in an Unreserved Template with FuelGUID, replacing
+// any earlier details; nil removes them. It returns an error wrapping
+// ErrInvalidFormat, and leaves the payload unchanged, if the pump is not
+// 1–8 letters and digits or the pre-auth amount is not a positive amount.
+// ValidateFuel checks the payload as a whole.
+func (p *Payload) SetFuelInfo(f *FuelInfo) error {
+	if f == nil {
+		return p.setExtension(FuelGUID, nil)
+	}
+	if err := validateFuelInfo(*f); err != nil {
+		return err
+	}
+	subs := []DataObject{{ID: FuelPump, Value: f.Pump}}
+	if f.PreAuthAmount != "" {
+		subs = append(subs, DataObject{ID: FuelPreAuthAmount, Value: f.PreAuthAmount})
+	}
+	return p.setExtension(FuelGUID, subs)
+}
+
+// GetFuelInfo returns the details stored by SetFuelInfo, or nil if the
+// payload has none.
+func (p *Payload) GetFuelInfo() *FuelInfo {
+	i := p.extensionIndex(FuelGUID)
+	if i < 0 {
+		return nil
+	}
+	f := &FuelInfo{}
+	for _, sf := range p.UnreservedTemplates[i].SubFields {
+		switch sf.ID {
+		case FuelPump:
+			f.Pump = sf.Value
+		case FuelPreAuthAmount:
+			f.PreAuthAmount = sf.Value
+		}
+	}
+	return f
+}
+
+// ValidateFuel checks the rules of a pump-side fuel QR: the payload must
+// carry the Merchant Category Code (Tag 52) of a fuel retailer,
+// MCCServiceStation or MCCFuelDispenser, and valid fuel details (see
+// SetFuelInfo), and a Transaction Amount (Tag 54), if present, must not
+// exceed the pre-auth amount. It returns every violation, joined with
+// errors.Join, each wrapping ErrSchemeViolation with its tag in
+// ErrorParams; nil if there are none.
+func ValidateFuel(p *Payload) error {
+	var errs []error
+	fail := func(tag, format string, args ...any) {
+		errs = append(errs, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: fuel: "+format, append([]any{ErrSchemeViolation}, args...)...), "tag", tag))
+	}
+	if !slices.Contains(fuelMCCs, p.MerchantCategoryCode) {
+		fail(IDMerchantCategoryCode, "Tag 52 MCC must be one of %v, got %q", fuelMCCs, p.MerchantCategoryCode)
+	}
+	f := p.GetFuelInfo()
+	var ferr error
+	if f != nil {
+		ferr = validateFuelInfo(*f)
+	}
+	switch {
+	case f == nil:
+		fail(FuelGUID, "no fuel template %s", FuelGUID)
+	case ferr != nil:
+		fail(FuelGUID, "%v", ferr)
+	case f.PreAuthAmount != "" && p.TransactionAmount != "":
+		amount, err := parseAmount(IDTransactionAmount, p.TransactionAmount)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if preAuth, _ := new(big.Rat).SetString(f.PreAuthAmount); amount.Cmp(preAuth) > 0 {
+			fail(IDTransactionAmount, "Tag 54 amount %s exceeds the pre-auth amount %s", p.TransactionAmount, f.PreAuthAmount)
+		}
+	}
+	return joinErrors(errs)
+}
@@ -0,0 +1,65 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFuelInfo(t *testing.T) {
+	p := basePayload()
+	p.MerchantCategoryCode = MCCServiceStation
+	p.PointOfInitiationMethod = POIDynamicQR
+	p.TransactionAmount = "1500.00"
+	if err := p.SetFuelInfo(&FuelInfo{Pump: "07", PreAuthAmount: "2000.00"}); err != nil {
+		t.Fatalf("SetFuelInfo() error: %v", err)
+	}
+
+	raw := mustEncode(t, p)
+	if !strings.Contains(raw, "0021"+FuelGUID+"010207"+"02072000.00") {
+		t.Errorf("Encode() = %q, want the fuel template", raw)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if err := ValidateFuel(decoded); err != nil {
+		t.Errorf("ValidateFuel() error: %v", err)
+	}
+	f := decoded.GetFuelInfo()
+	if f == nil {
+		t.Fatal("GetFuelInfo() = nil")
+	}
+	assertEqual(t, "Pump", "07", f.Pump)
+	assertEqual(t, "PreAuthAmount", "2000.00", f.PreAuthAmount)
+
+	if err := decoded.SetFuelInfo(nil); err != nil || decoded.GetFuelInfo() != nil {
+		t.Errorf("SetFuelInfo(nil): error %v, info %+v", err, decoded.GetFuelInfo())
+	}
+}
+
+func TestSetFuelInfo_Errors(t *testing.T) {
+	p := basePayload()
+	for _, f := range []FuelInfo{{}, {Pump: "PUMP-7"}, {Pump: "123456789"}, {Pump: "7", PreAuthAmount: "0"}, {Pump: "7", PreAuthAmount: "2,000"}} {
+		if err := p.SetFuelInfo(&f); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("SetFuelInfo(%+v) error = %v, want ErrInvalidFormat", f, err)
+		}
+	}
+	if p.GetFuelInfo() != nil {
+		t.Error("failed SetFuelInfo() stored fuel details")
+	}
+}
+
+func TestValidateFuel(t *testing.T) {
+	p := basePayload()
+	if err := ValidateFuel(p); !hasErrorTag(err, IDMerchantCategoryCode) || !hasErrorTag(err, FuelGUID) {
+		t.Errorf("ValidateFuel() error = %v, want MCC and template violations", err)
+	}
+	p.MerchantCategoryCode = MCCFuelDispenser
+	p.TransactionAmount = "2500"
+	_ = p.SetFuelInfo(&FuelInfo{Pump: "3", PreAuthAmount: "2000"})
+	err := ValidateFuel(p)
+	if !errors.Is(err, ErrSchemeViolation) || !hasErrorTag(err, IDTransactionAmount) {
+		t.Errorf("ValidateFuel() error = %v, want a Tag 54 violation", err)
+	}
+}
@@ -201,3 +201,30 @@ func ExampleDecode_errorHandling() {
 	// Output:
 	// true
 }
+
+// ExampleExplain prints an annotated TLV tree, as attached to support
+// tickets.
+func ExampleExplain() {
+	raw := "000201010212021640001234567890125204525153038405406199.995802US5911ABC Hammers6008New York62090105INV-16304"
+	raw += emvqr.ComputeCRC(raw)
+
+	out, err := emvqr.Explain(raw)
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Print(out)
+	// Output:
+	// 00 Payload Format Indicator                       len 02  01
+	// 01 Point of Initiation Method                     len 02  12 (QR, dynamic)
+	// 02 Merchant Account Information (Visa)            len 16  4000123456789012
+	// 52 Merchant Category Code                         len 04  5251
+	// 53 Transaction Currency                           len 03  840 (USD)
+	// 54 Transaction Amount                             len 06  199.99
+	// 58 Country Code                                   len 02  US
+	// 59 Merchant Name                                  len 11  ABC Hammers
+	// 60 Merchant City                                  len 08  New York
+	// 62 Additional Data Field Template                 len 09
+	//    01 Bill Number                                 len 05  INV-1
+	// 63 CRC                                            len 04  1E7B (valid)
+}
@@ -0,0 +1,119 @@
+package emvqr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GSTInvoice is the invoice metadata required alongside the amount on
+// dynamic QRs of large Indian merchants.
+type GSTInvoice struct {
+	Number string    // invoice number, up to 25 characters
+	Date   time.Time // invoice date and time
+	GSTIN  string    // 15-character Goods and Services Tax Identification Number
+}
+
+// SetGSTInvoice records the invoice metadata of a dynamic Bharat QR. The
+// number goes into the Bill Number (Tag 62.01), the GSTIN into the Merchant
+// Tax ID (Tag 62.10) and the date into the NPCI template in Tag 62 (see
+// NPCIInvoiceDate). UPIURI carries them as the invoiceNo, gstIn and
+// invoiceDate parameters.
+//
+// Tag 62.10 and the payment system templates are defined by EMV QRCPS MPM
+// v1.1, so the payload must be encoded with SpecVersion11. SetGSTInvoice
+// returns ErrInvalidFormat if the GSTIN fails ValidateGSTIN or the number
+// is empty or too long; the payload is then unchanged.
+func (p *Payload) SetGSTInvoice(inv GSTInvoice) error {
+	if err := ValidateGSTIN(inv.GSTIN); err != nil {
+		return err
+	}
+	if inv.Number == "" || len(inv.Number) > 25 {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: invoice number must be 1-25 characters, got %d", ErrInvalidFormat, len(inv.Number)),
+			"tag", IDAdditionalDataFieldTemplate+"."+ADFBillNumber)
+	}
+	p.SetAdditionalData(func(a *AdditionalDataField) {
+		a.BillNumber = inv.Number
+		a.MerchantTaxID = inv.GSTIN
+	})
+	date := ""
+	if !inv.Date.IsZero() {
+		date = inv.Date.Format(time.RFC3339)
+	}
+	p.setNPCIField(NPCIInvoiceDate, date)
+	return nil
+}
+
+// GetGSTInvoice returns the invoice metadata set by SetGSTInvoice, or nil if
+// the payload has no GSTIN. It also reads payloads decoded under v1.0, in
+// which the v1.1 sub-fields are kept in RFUFields. A date that cannot be
+// parsed is left zero.
+func (p *Payload) GetGSTInvoice() *GSTInvoice {
+	a := p.AdditionalData
+	if a == nil {
+		return nil
+	}
+	inv := &GSTInvoice{Number: a.BillNumber, GSTIN: a.MerchantTaxID}
+	for _, rfu := range a.RFUFields {
+		if rfu.ID == ADFMerchantTaxID && inv.GSTIN == "" {
+			inv.GSTIN = rfu.Value
+		}
+	}
+	if inv.GSTIN == "" {
+		return nil
+	}
+	inv.Date, _ = time.Parse(time.RFC3339, p.npciField(NPCIInvoiceDate))
+	return inv
+}
+
+// ValidateGSTIN checks the format and check character of a GSTIN: a
+// two-digit state code, the holder's PAN (five letters, four digits, a
+// letter), an entity code, the letter Z and a base-36 check character. It
+// returns an error wrapping ErrInvalidFormat.
+func ValidateGSTIN(gstin string) error {
+	fail := func(reason string) error {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: GSTIN %q %s", ErrInvalidFormat, gstin, reason),
+			"tag", IDAdditionalDataFieldTemplate+"."+ADFMerchantTaxID)
+	}
+	if len(gstin) != 15 {
+		return fail("must be 15 characters")
+	}
+	if state, err := strconv.Atoi(gstin[:2]); err != nil || state < 1 || state > 38 && state != 97 && state != 99 {
+		return fail("has an invalid state code")
+	}
+	pan := gstin[2:12]
+	if !isUpperAlpha(pan[:5]) || !isNumeric(pan[5:9]) || !isUpperAlpha(pan[9:]) {
+		return fail("does not contain a valid PAN")
+	}
+	if gstin[13] != 'Z' {
+		return fail("must have Z as its 14th character")
+	}
+	sum := 0
+	for i := 0; i < 14; i++ {
+		v := strings.IndexByte(gstinAlphabet, gstin[i])
+		if v < 0 {
+			return fail("contains an invalid character")
+		}
+		product := v * (i%2 + 1)
+		sum += product/36 + product%36
+	}
+	if want := gstinAlphabet[(36-sum%36)%36]; gstin[14] != want {
+		return fail(fmt.Sprintf("has check character %c, want %c", gstin[14], want))
+	}
+	return nil
+}
+
+const gstinAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// isUpperAlpha reports whether s consists only of ASCII uppercase letters.
+func isUpperAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateGSTIN(t *testing.T) {
+	for _, valid := range []string{"27AAPFU0939F1ZV", "29AAGCB7383J1Z4"} {
+		if err := ValidateGSTIN(valid); err != nil {
+			t.Errorf("ValidateGSTIN(%q) = %v, want nil", valid, err)
+		}
+	}
+	tests := map[string]string{
+		"27AAPFU0939F1Z":  "must be 15 characters",
+		"45AAPFU0939F1ZV": "invalid state code",
+		"27AAPF10939F1ZV": "valid PAN",
+		"27AAPFU0939F1XV": "Z as its 14th",
+		"27AAPFU0939F1Z1": "has check character 1, want V",
+		"27AAPFU0939F#ZV": "invalid character",
+	}
+	for gstin, want := range tests {
+		err := ValidateGSTIN(gstin)
+		if !errors.Is(err, ErrInvalidFormat) || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateGSTIN(%q) = %v, want ErrInvalidFormat containing %q", gstin, err, want)
+		}
+	}
+}
+
+func TestGSTInvoice(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	inv := GSTInvoice{
+		Number: "INV-2024-0042",
+		Date:   time.Date(2024, 3, 15, 14, 30, 0, 0, ist),
+		GSTIN:  "27AAPFU0939F1ZV",
+	}
+	p := basePayload()
+	p.TransactionCurrency = "356"
+	p.TransactionAmount = "1180.00"
+	if err := p.SetUPIVPATemplate(RuPayRIDValue, "shop@upi", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetGSTInvoice(inv); err != nil {
+		t.Fatalf("SetGSTInvoice() error: %v", err)
+	}
+
+	raw, err := EncodeWithOptions(p, EncodeOptions{SpecVersion: SpecVersion11})
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	for _, version := range []SpecVersion{SpecVersion11, SpecVersion10} {
+		decoded, err := DecodeWithOptions(raw, DecodeOptions{SpecVersion: version})
+		if err != nil {
+			t.Fatalf("Decode(v%s) error: %v", version, err)
+		}
+		got := decoded.GetGSTInvoice()
+		if got == nil || got.Number != inv.Number || got.GSTIN != inv.GSTIN || !got.Date.Equal(inv.Date) {
+			t.Errorf("GetGSTInvoice(v%s) = %+v, want %+v", version, got, inv)
+		}
+	}
+
+	uri, err := p.UPIURI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "&gstIn=27AAPFU0939F1ZV&invoiceNo=INV-2024-0042&invoiceDate=2024-03-15T14%3A30%3A00%2B05%3A30"
+	if !strings.HasSuffix(uri, want) {
+		t.Errorf("UPIURI() = %s, want suffix %s", uri, want)
+	}
+
+	// Replacing the invoice without a date removes the old date.
+	if err := p.SetGSTInvoice(GSTInvoice{Number: "INV-2", GSTIN: inv.GSTIN}); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.GetGSTInvoice(); got.Number != "INV-2" || !got.Date.IsZero() {
+		t.Errorf("GetGSTInvoice() = %+v after replacing", got)
+	}
+
+	before := p.AdditionalData.BillNumber
+	if err := p.SetGSTInvoice(GSTInvoice{Number: "X", GSTIN: "27AAPFU0939F1Z1"}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("SetGSTInvoice() with bad GSTIN error = %v, want ErrInvalidFormat", err)
+	}
+	if p.AdditionalData.BillNumber != before {
+		t.Error("failed SetGSTInvoice() changed the payload")
+	}
+	if basePayload().GetGSTInvoice() != nil {
+		t.Error("GetGSTInvoice() without GSTIN: want nil")
+	}
+}
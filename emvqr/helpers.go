@@ -22,16 +22,19 @@ func NewPayload() *Payload {
 // Each tag ID can appear at most once per QR code.
 func (p *Payload) AddMerchantIdentifier(tagID, value string) error {
 	if tagID == "" {
-		return fmt.Errorf("emvqr: merchant identifier tag ID cannot be empty")
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: merchant identifier tag ID cannot be empty", ErrInvalidTagID))
 	}
 	n, err := strconv.Atoi(tagID)
 	if err != nil || n < 2 || n > 25 {
-		return fmt.Errorf("emvqr: merchant identifier tag ID must be 02–25, got %q", tagID)
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: merchant identifier tag ID must be 02–25, got %q", ErrInvalidTagID, tagID), "tag", tagID)
 	}
 	// Check for duplicate tag ID
 	for _, mi := range p.MerchantIdentifiers {
 		if mi.ID == tagID {
-			return fmt.Errorf("emvqr: merchant identifier tag ID %s already exists", tagID)
+			return newError(CodeInvalidFormat,
+				fmt.Errorf("%w: merchant identifier tag ID %s already exists", ErrDuplicateTag, tagID), "tag", tagID)
 		}
 	}
 	p.MerchantIdentifiers = append(p.MerchantIdentifiers, MerchantIdentifier{
@@ -89,6 +92,7 @@ func (p *Payload) SetLanguageTemplate(lang, name, city string) {
 // TotalAmount returns the total amount including any fixed or percentage-based
 // convenience fee, given the base transaction amount as a float64.
 // Returns 0 and an error if the TransactionAmount field cannot be parsed.
+// FeeBreakdown computes the same total exactly, for display.
 func (p *Payload) TotalAmount() (float64, error) {
 	if p.TransactionAmount == "" {
 		return 0, fmt.Errorf("emvqr: TransactionAmount not present in payload")
@@ -133,25 +137,27 @@ func (p *Payload) MobileNumberRequired() bool {
 	return p.AdditionalData != nil && p.AdditionalData.MobileNumber == PromptValue
 }
 
-// PreferredMerchantName returns the merchant name in the given BCP-47 language
-// tag (e.g. "es"). Falls back to the primary MerchantName field if no
-// alternate language template is present or if the language does not match.
+// PreferredMerchantName returns the merchant name in the given BCP-47
+// language tag (e.g. "es" or "hi-IN"), from the Language Template or the
+// alternate languages added with AddLanguage. Tags are compared without
+// regard to case; an exact match is preferred, then one on the primary
+// language, so "hi-IN" finds a "hi" template and "zh-TW" a "zh" one.
+// Falls back to the primary MerchantName field if no alternate language
+// matches.
 func (p *Payload) PreferredMerchantName(lang string) string {
-	if p.LanguageTemplate != nil && p.LanguageTemplate.MerchantName != "" {
-		if p.LanguageTemplate.LanguagePreference == lang {
-			return p.LanguageTemplate.MerchantName
-		}
+	if name, ok := p.preferredLanguageValue(lang, func(lt *LanguageTemplate) string { return lt.MerchantName }); ok {
+		return name
 	}
 	return p.MerchantName
 }
 
-// PreferredMerchantCity returns the merchant city in the given language,
-// falling back to the primary MerchantCity field.
+// PreferredMerchantCity returns the merchant city in the given BCP-47
+// language tag, matched case-insensitively and falling back to the primary
+// language, so that "hi-IN" finds a "hi" template. Falls back to the
+// primary MerchantCity field if no alternate language matches.
 func (p *Payload) PreferredMerchantCity(lang string) string {
-	if p.LanguageTemplate != nil && p.LanguageTemplate.MerchantCity != "" {
-		if p.LanguageTemplate.LanguagePreference == lang {
-			return p.LanguageTemplate.MerchantCity
-		}
+	if city, ok := p.preferredLanguageValue(lang, func(lt *LanguageTemplate) string { return lt.MerchantCity }); ok {
+		return city
 	}
 	return p.MerchantCity
 }
@@ -174,17 +180,22 @@ func (p *Payload) HasMultipleNetworks() bool {
 // method: "1"=QR, "2"=BLE, "3"=NFC
 // dataType: "1"=static, "2"=dynamic
 // Combined as "XY", e.g. "11" for static QR, "12" for dynamic QR.
+//
+// SetPOI with a constant such as POIDynamicQR is harder to misuse.
 func (p *Payload) SetPointOfInitiationMethod(method, dataType string) error {
 	if method == "" || dataType == "" {
-		return fmt.Errorf("emvqr: method and dataType must not be empty")
+		return newError(CodeMissingField,
+			fmt.Errorf("%w: method and dataType must not be empty", ErrMissingRequired), "tag", IDPointOfInitiationMethod)
 	}
 	if method != "1" && method != "2" && method != "3" {
-		return fmt.Errorf("emvqr: method must be 1 (QR), 2 (BLE), or 3 (NFC), got %q", method)
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: method must be 1 (QR), 2 (BLE), or 3 (NFC), got %q", ErrInvalidFormat, method), "tag", IDPointOfInitiationMethod)
 	}
 	if dataType != "1" && dataType != "2" {
-		return fmt.Errorf("emvqr: dataType must be 1 (static) or 2 (dynamic), got %q", dataType)
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: dataType must be 1 (static) or 2 (dynamic), got %q", ErrInvalidFormat, dataType), "tag", IDPointOfInitiationMethod)
 	}
-	p.PointOfInitiationMethod = method + dataType
+	p.PointOfInitiationMethod = POI(method + dataType)
 	return nil
 }
 
@@ -194,7 +205,8 @@ func (p *Payload) SetPointOfInitiationMethod(method, dataType string) error {
 // minimumAmount: optional minimum amount for dynamic QRs
 func (p *Payload) SetUPIVPATemplate(ruPayRID, vpa, minimumAmount string) error {
 	if vpa == "" {
-		return fmt.Errorf("emvqr: VPA must not be empty")
+		return newError(CodeMissingField,
+			fmt.Errorf("%w: VPA must not be empty", ErrMissingRequired), "tag", IDUPIVPATemplate+".01")
 	}
 	p.UPIVPAInfo = &UPIVPATemplate{
 		RuPayRID:      ruPayRID,
@@ -209,13 +221,18 @@ func (p *Payload) SetUPIVPATemplate(ruPayRID, vpa, minimumAmount string) error {
 // url: optional reference URL (max 26 chars)
 func (p *Payload) SetUPIVPAReference(transactionRef, url string) error {
 	if transactionRef == "" {
-		return fmt.Errorf("emvqr: transaction reference must not be empty")
+		return newError(CodeMissingField,
+			fmt.Errorf("%w: transaction reference must not be empty", ErrMissingRequired), "tag", IDUPIVPAReference+"."+UPIVPARefTransactionRef)
 	}
 	if len(transactionRef) < 4 || len(transactionRef) > 35 {
-		return fmt.Errorf("emvqr: transaction reference must be 4-35 characters, got %d", len(transactionRef))
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: transaction reference must be 4-35 characters, got %d", ErrSchemeViolation, len(transactionRef)),
+			"tag", IDUPIVPAReference+"."+UPIVPARefTransactionRef)
 	}
 	if url != "" && len(url) > 26 {
-		return fmt.Errorf("emvqr: reference URL must be max 26 characters, got %d", len(url))
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: reference URL must be max 26 characters, got %d", ErrSchemeViolation, len(url)),
+			"tag", IDUPIVPAReference+"."+UPIVPARefURL)
 	}
 	p.UPITransactionRef = &UPIVPAReference{
 		RuPayRID:       RuPayRIDValue,
@@ -229,15 +246,20 @@ func (p *Payload) SetUPIVPAReference(transactionRef, url string) error {
 // aadhaarNum: 12-digit Aadhaar number.
 func (p *Payload) SetAadhaarNumber(aadhaarNum string) error {
 	if aadhaarNum == "" {
-		return fmt.Errorf("emvqr: Aadhaar number must not be empty")
+		return newError(CodeMissingField,
+			fmt.Errorf("%w: Aadhaar number must not be empty", ErrMissingRequired), "tag", IDAadhaarTemplate+"."+AadhaarAadhaarNum)
 	}
 	if len(aadhaarNum) != 12 {
-		return fmt.Errorf("emvqr: Aadhaar number must be exactly 12 digits, got %d", len(aadhaarNum))
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: Aadhaar number must be exactly 12 digits, got %d", ErrSchemeViolation, len(aadhaarNum)),
+			"tag", IDAadhaarTemplate+"."+AadhaarAadhaarNum)
 	}
 	// Validate that it contains only digits
 	for _, ch := range aadhaarNum {
 		if ch < '0' || ch > '9' {
-			return fmt.Errorf("emvqr: Aadhaar number must contain only digits, got %q", aadhaarNum)
+			return newError(CodeInvalidFormat,
+				fmt.Errorf("%w: Aadhaar number must contain only digits, got %q", ErrSchemeViolation, aadhaarNum),
+				"tag", IDAadhaarTemplate+"."+AadhaarAadhaarNum)
 		}
 	}
 	p.MerchantAadhaar = &AadhaarInfo{
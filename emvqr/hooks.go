@@ -0,0 +1,69 @@
+package emvqr
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks are optional callbacks run by DecodeContext and the Decoder, for
+// operators of QR gateways to wire logging, tracing or metrics without
+// forking the package. Any of them may be nil. They are called
+// synchronously, so they must be quick and, when decodes run concurrently,
+// safe for concurrent use.
+type Hooks struct {
+	// OnDecodeStart is called before raw is parsed.
+	OnDecodeStart func(ctx context.Context, raw string)
+
+	// OnDecodeError is called with the error of a failed decode, including
+	// a failed VerifyMerchant.
+	OnDecodeError func(ctx context.Context, raw string, err error)
+
+	// OnValidateWarning is called with each Lint warning of a successfully
+	// decoded payload. Lint runs only when this hook is set.
+	OnValidateWarning func(ctx context.Context, p *Payload, w Warning)
+
+	// Metrics, if set, receives decode counters and latencies.
+	Metrics Metrics
+}
+
+// Metrics receives decode measurements, e.g. to back Prometheus or
+// OpenTelemetry instruments: a counter labelled by code and a latency
+// histogram.
+type Metrics interface {
+	// IncDecodeError counts a failed decode by the code of its error, as
+	// returned by ErrorCode.
+	IncDecodeError(code Code)
+
+	// ObserveDecodeLatency records how long a decode took, successful or
+	// not, VerifyMerchant included.
+	ObserveDecodeLatency(d time.Duration)
+}
+
+// decodeWithHooks is decode reporting to h.
+func decodeWithHooks(ctx context.Context, h *Hooks, raw string, opts DecodeOptions, scratch []tlvObject) (*Payload, []tlvObject, error) {
+	var start time.Time
+	if h.Metrics != nil {
+		start = time.Now()
+	}
+	if h.OnDecodeStart != nil {
+		h.OnDecodeStart(ctx, raw)
+	}
+	p, scratch, err := decodePayload(ctx, raw, opts, scratch)
+	if h.Metrics != nil {
+		h.Metrics.ObserveDecodeLatency(time.Since(start))
+		if err != nil {
+			h.Metrics.IncDecodeError(ErrorCode(err))
+		}
+	}
+	switch {
+	case err != nil:
+		if h.OnDecodeError != nil {
+			h.OnDecodeError(ctx, raw, err)
+		}
+	case h.OnValidateWarning != nil:
+		for _, w := range Lint(p) {
+			h.OnValidateWarning(ctx, p, w)
+		}
+	}
+	return p, scratch, err
+}
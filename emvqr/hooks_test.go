@@ -0,0 +1,84 @@
+package emvqr
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMetrics struct {
+	mu        sync.Mutex
+	errors    map[Code]int
+	latencies int
+}
+
+func (m *testMetrics) IncDecodeError(code Code) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.errors == nil {
+		m.errors = make(map[Code]int)
+	}
+	m.errors[code]++
+}
+
+func (m *testMetrics) ObserveDecodeLatency(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d >= 0 {
+		m.latencies++
+	}
+}
+
+func TestDecodeHooks(t *testing.T) {
+	var started, failed int
+	var warnings []Warning
+	metrics := &testMetrics{}
+	opts := DecodeOptions{Hooks: &Hooks{
+		OnDecodeStart: func(context.Context, string) { started++ },
+		OnDecodeError: func(_ context.Context, _ string, err error) {
+			failed++
+			if ErrorCode(err) != CodeCRCMismatch {
+				t.Errorf("OnDecodeError code = %s, want %s", ErrorCode(err), CodeCRCMismatch)
+			}
+		},
+		OnValidateWarning: func(_ context.Context, _ *Payload, w Warning) { warnings = append(warnings, w) },
+		Metrics:           metrics,
+	}}
+
+	// A static Indian QR without a postal code draws a lint warning.
+	p := basePayload()
+	p.CountryCode = "IN"
+	raw := mustEncode(t, p)
+	if _, err := DecodeWithOptions(raw, opts); err != nil {
+		t.Fatalf("DecodeWithOptions: %v", err)
+	}
+	broken := raw[:len(raw)-4] + "0000"
+	if _, err := NewDecoder(opts).Decode(broken); err == nil {
+		t.Fatal("Decoder.Decode accepted a bad CRC")
+	}
+
+	if started != 2 || failed != 1 {
+		t.Errorf("started %d, failed %d; want 2, 1", started, failed)
+	}
+	if len(warnings) != 1 || warnings[0].Check != LintMissingPostalCode {
+		t.Errorf("warnings = %v, want one %s", warnings, LintMissingPostalCode)
+	}
+	if metrics.latencies != 2 || metrics.errors[CodeCRCMismatch] != 1 || len(metrics.errors) != 1 {
+		t.Errorf("metrics = %d latencies, errors %v", metrics.latencies, metrics.errors)
+	}
+}
+
+func TestDecodeHooks_VerifyMerchant(t *testing.T) {
+	metrics := &testMetrics{}
+	opts := DecodeOptions{
+		VerifyMerchant: func(context.Context, *Payload) error { return ErrMissingRequired },
+		Hooks:          &Hooks{Metrics: metrics},
+	}
+	if _, err := DecodeWithOptions(mustEncode(t, basePayload()), opts); err == nil {
+		t.Fatal("want a verification error")
+	}
+	if metrics.errors[CodeMerchantUnverified] != 1 {
+		t.Errorf("errors = %v, want one %s", metrics.errors, CodeMerchantUnverified)
+	}
+}
@@ -0,0 +1,245 @@
+// Package httpapi provides net/http handlers for an internal QR service:
+//
+//	POST /decode    {"qr": "000201..."}           → {"payload": {...}}
+//	POST /encode    {"payload": {...}}            → {"qr": "000201..."}
+//	POST /validate  {"qr": "...", "profile": ""}  → {"valid": true, ...}
+//
+// Payloads are JSON in the schema selected by Options.JSON; see
+// emvqr.JSONSchema. Request bodies are limited to Options.MaxBodyBytes.
+// Failures are reported with a 4xx status and a structured body whose code
+// is that of emvqr.ErrorCode:
+//
+//	{"error": {"code": "crc_mismatch", "message": "emvqr: CRC mismatch: ...",
+//	           "localized": "...", "params": {"tag": "63", ...}}}
+//
+// A service needs only:
+//
+//	http.ListenAndServe(":8080", httpapi.NewHandler(httpapi.Options{}))
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/profile"
+)
+
+// DefaultMaxBodyBytes is the request body limit used when
+// Options.MaxBodyBytes is zero: ample for any payload, which EMV limits to
+// 512 characters, and for its JSON.
+const DefaultMaxBodyBytes = 16 << 10
+
+// Codes of the failures detected by the handlers rather than by emvqr.
+const (
+	CodeBadRequest       emvqr.Code = "bad_request"
+	CodeBodyTooLarge     emvqr.Code = "body_too_large"
+	CodeMethodNotAllowed emvqr.Code = "method_not_allowed"
+	CodeUnknownProfile   emvqr.Code = "unknown_profile"
+)
+
+// Options configures the handlers.
+type Options struct {
+	// MaxBodyBytes limits request bodies; 0 means DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+
+	// Decode is used by /decode and /validate.
+	Decode emvqr.DecodeOptions
+
+	// Encode is used by /encode.
+	Encode emvqr.EncodeOptions
+
+	// JSON selects the schema of payloads in requests and responses.
+	JSON emvqr.JSONOptions
+
+	// Profile is the scheme profile used by /validate when the request
+	// names none; "" means profile.Default.
+	Profile string
+}
+
+// NewHandler returns a handler serving /decode, /encode and /validate.
+func NewHandler(opts Options) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/decode", DecodeHandler(opts))
+	mux.Handle("/encode", EncodeHandler(opts))
+	mux.Handle("/validate", ValidateHandler(opts))
+	return mux
+}
+
+// DecodeRequest is the body of a /decode or /validate request.
+type DecodeRequest struct {
+	QR string `json:"qr"`
+	// Profile names the scheme profile for /validate, e.g. "bharatqr".
+	Profile string `json:"profile,omitempty"`
+}
+
+// DecodeResponse is the body of a successful /decode response.
+type DecodeResponse struct {
+	Payload json.RawMessage `json:"payload"`
+}
+
+// EncodeRequest is the body of an /encode request.
+type EncodeRequest struct {
+	Payload json.RawMessage `json:"payload"`
+}
+
+// EncodeResponse is the body of a successful /encode response.
+type EncodeResponse struct {
+	QR string `json:"qr"`
+}
+
+// ValidateResponse is the body of a /validate response. Valid is false
+// when the payload does not decode or the profile reports findings; lint
+// warnings do not affect it.
+type ValidateResponse struct {
+	Valid    bool            `json:"valid"`
+	Profile  string          `json:"profile"`
+	Error    *ErrorBody      `json:"error,omitempty"`
+	Findings []string        `json:"findings,omitempty"`
+	Warnings []emvqr.Warning `json:"warnings,omitempty"`
+}
+
+// ErrorBody describes a failure.
+type ErrorBody struct {
+	Code    emvqr.Code `json:"code"`
+	Message string     `json:"message"`
+	// Localized is a message for consumers in the language of the
+	// request's Accept-Language header; see emvqr.LocalizedMessage.
+	Localized string            `json:"localized,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+}
+
+// ErrorResponse is the body of a failed request.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// DecodeHandler serves /decode.
+func DecodeHandler(opts Options) http.Handler {
+	return post(opts, func(w http.ResponseWriter, r *http.Request) {
+		var req DecodeRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		p, err := emvqr.DecodeContext(r.Context(), req.QR, opts.Decode)
+		if err != nil {
+			writeError(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+		data, err := emvqr.MarshalJSONWithOptions(p, opts.JSON)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, DecodeResponse{Payload: data})
+	})
+}
+
+// EncodeHandler serves /encode.
+func EncodeHandler(opts Options) http.Handler {
+	return post(opts, func(w http.ResponseWriter, r *http.Request) {
+		var req EncodeRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if len(req.Payload) == 0 {
+			writeError(w, r, http.StatusBadRequest, codedError(CodeBadRequest, "httpapi: request has no payload"))
+			return
+		}
+		var p emvqr.Payload
+		if err := emvqr.UnmarshalJSONWithOptions(req.Payload, &p, opts.JSON); err != nil {
+			writeError(w, r, http.StatusBadRequest, &emvqr.Error{Code: CodeBadRequest, Err: err})
+			return
+		}
+		qr, err := emvqr.EncodeWithOptions(&p, opts.Encode)
+		if err != nil {
+			writeError(w, r, http.StatusUnprocessableEntity, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, EncodeResponse{QR: qr})
+	})
+}
+
+// ValidateHandler serves /validate. It answers 200 whether or not the
+// payload is valid; see ValidateResponse.
+func ValidateHandler(opts Options) http.Handler {
+	return post(opts, func(w http.ResponseWriter, r *http.Request) {
+		var req DecodeRequest
+		if !readJSON(w, r, &req) {
+			return
+		}
+		name := req.Profile
+		if name == "" {
+			name = opts.Profile
+		}
+		if name == "" {
+			name = profile.Default
+		}
+		pr, ok := profile.Lookup(name)
+		if !ok {
+			err := &emvqr.Error{
+				Code:   CodeUnknownProfile,
+				Params: map[string]string{"profile": name},
+				Err:    fmt.Errorf("httpapi: unknown profile %q (want one of %s)", name, strings.Join(profile.Names(), ", ")),
+			}
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		resp := ValidateResponse{Profile: pr.Name}
+		p, err := emvqr.DecodeContext(r.Context(), req.QR, opts.Decode)
+		if err != nil {
+			body := errorBody(r, err)
+			resp.Error = &body
+		} else {
+			resp.Findings = pr.Check(p, req.QR)
+			resp.Warnings = emvqr.Lint(p)
+			resp.Valid = len(resp.Findings) == 0
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// post wraps h to accept only POST requests with bodies limited as opts
+// says.
+func post(opts Options, h http.HandlerFunc) http.Handler {
+	limit := opts.MaxBodyBytes
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, r, http.StatusMethodNotAllowed,
+				codedError(CodeMethodNotAllowed, fmt.Sprintf("httpapi: method %s not allowed", r.Method)))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		h(w, r)
+	})
+}
+
+// readJSON decodes the request body into v, answering the request with an
+// error and returning false when it cannot.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, r, http.StatusRequestEntityTooLarge, &emvqr.Error{
+			Code:   CodeBodyTooLarge,
+			Params: map[string]string{"limit": strconv.FormatInt(tooLarge.Limit, 10)},
+			Err:    fmt.Errorf("httpapi: request body exceeds %d bytes", tooLarge.Limit),
+		})
+		return false
+	}
+	writeError(w, r, http.StatusBadRequest, &emvqr.Error{Code: CodeBadRequest, Err: fmt.Errorf("httpapi: invalid request body: %w", err)})
+	return false
+}
@@ -0,0 +1,120 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func testQR(t *testing.T) string {
+	t.Helper()
+	p := emvqr.NewPayload()
+	p.MerchantIdentifiers = []emvqr.MerchantIdentifier{{ID: "02", Value: "4000123456789012"}}
+	p.MerchantCategoryCode = "5251"
+	p.TransactionCurrency = "840"
+	p.CountryCode = "US"
+	p.MerchantName = "ABC Hammers"
+	p.MerchantCity = "New York"
+	qr, err := emvqr.Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return qr
+}
+
+// serve sends a request to a handler built from opts and decodes the JSON
+// response into v.
+func serve(t *testing.T, opts Options, method, path, body string, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Accept-Language", "hi-IN,hi;q=0.9")
+	rec := httptest.NewRecorder()
+	NewHandler(opts).ServeHTTP(rec, req)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s %s: Content-Type %q", method, path, ct)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("%s %s: response %q: %v", method, path, rec.Body, err)
+	}
+	return rec
+}
+
+func TestDecodeEncode(t *testing.T) {
+	qr := testQR(t)
+	opts := Options{JSON: emvqr.JSONOptions{Schema: emvqr.JSONSchemaEMV}}
+
+	var dec DecodeResponse
+	rec := serve(t, opts, http.MethodPost, "/decode", `{"qr":"`+qr+`"}`, &dec)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/decode status %d: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(string(dec.Payload), `"59":"ABC Hammers"`) {
+		t.Errorf("/decode payload %s lacks merchant name under 59", dec.Payload)
+	}
+
+	var enc EncodeResponse
+	body, _ := json.Marshal(EncodeRequest{Payload: dec.Payload})
+	rec = serve(t, opts, http.MethodPost, "/encode", string(body), &enc)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/encode status %d: %s", rec.Code, rec.Body)
+	}
+	if enc.QR != qr {
+		t.Errorf("/encode qr = %q, want %q", enc.QR, qr)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var resp ValidateResponse
+	rec := serve(t, Options{}, http.MethodPost, "/validate", `{"qr":"`+testQR(t)+`"}`, &resp)
+	if rec.Code != http.StatusOK || !resp.Valid || resp.Profile != "emvco" {
+		t.Errorf("/validate = %d %+v, want 200 valid under emvco", rec.Code, resp)
+	}
+
+	resp = ValidateResponse{}
+	serve(t, Options{}, http.MethodPost, "/validate", `{"qr":"000201"}`, &resp)
+	if resp.Valid || resp.Error == nil || resp.Error.Code == "" {
+		t.Errorf("/validate of a broken QR = %+v, want invalid with an error", resp)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	qr := testQR(t)
+	broken := qr[:len(qr)-4] + "0000"
+	for _, tt := range []struct {
+		name, method, path, body string
+		opts                     Options
+		status                   int
+		code                     emvqr.Code
+	}{
+		{"CRC mismatch", http.MethodPost, "/decode", `{"qr":"` + broken + `"}`, Options{}, http.StatusUnprocessableEntity, emvqr.CodeCRCMismatch},
+		{"GET", http.MethodGet, "/decode", "", Options{}, http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+		{"bad JSON", http.MethodPost, "/decode", `{"qr":`, Options{}, http.StatusBadRequest, CodeBadRequest},
+		{"unknown field", http.MethodPost, "/decode", `{"raw":"x"}`, Options{}, http.StatusBadRequest, CodeBadRequest},
+		{"too large", http.MethodPost, "/decode", `{"qr":"` + qr + `"}`, Options{MaxBodyBytes: 16}, http.StatusRequestEntityTooLarge, CodeBodyTooLarge},
+		{"no payload", http.MethodPost, "/encode", `{}`, Options{}, http.StatusBadRequest, CodeBadRequest},
+		{"missing fields", http.MethodPost, "/encode", `{"payload":{"MerchantName":"X"}}`, Options{}, http.StatusUnprocessableEntity, emvqr.CodeMissingField},
+		{"unknown profile", http.MethodPost, "/validate", `{"qr":"x","profile":"nope"}`, Options{}, http.StatusBadRequest, CodeUnknownProfile},
+	} {
+		var resp ErrorResponse
+		rec := serve(t, tt.opts, tt.method, tt.path, tt.body, &resp)
+		if rec.Code != tt.status || resp.Error.Code != tt.code {
+			t.Errorf("%s: %d %q, want %d %q (%s)", tt.name, rec.Code, resp.Error.Code, tt.status, tt.code, rec.Body)
+		}
+		if resp.Error.Message == "" {
+			t.Errorf("%s: empty message", tt.name)
+		}
+	}
+
+	var resp ErrorResponse
+	serve(t, Options{}, http.MethodPost, "/decode", `{"qr":"`+broken+`"}`, &resp)
+	if want := emvqr.LocalizedMessage(emvqr.ErrCRCMismatch, "hi"); resp.Error.Localized != want {
+		t.Errorf("localized = %q, want %q", resp.Error.Localized, want)
+	}
+	if resp.Error.Params["tag"] != emvqr.IDCRC {
+		t.Errorf("params = %v, want tag 63", resp.Error.Params)
+	}
+}
@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// codedError returns an *emvqr.Error with code and message.
+func codedError(code emvqr.Code, message string) error {
+	return &emvqr.Error{Code: code, Err: errors.New(message)}
+}
+
+// errorBody describes err for a response to r.
+func errorBody(r *http.Request, err error) ErrorBody {
+	return ErrorBody{
+		Code:      emvqr.ErrorCode(err),
+		Message:   err.Error(),
+		Localized: emvqr.LocalizedMessage(err, language(r)),
+		Params:    emvqr.ErrorParams(err),
+	}
+}
+
+// language returns the first language of r's Accept-Language header, e.g.
+// "hi-IN" for "hi-IN,hi;q=0.9,en;q=0.8", or "" when there is none.
+func language(r *http.Request) string {
+	lang, _, _ := strings.Cut(r.Header.Get("Accept-Language"), ",")
+	lang, _, _ = strings.Cut(lang, ";")
+	lang = strings.TrimSpace(lang)
+	if lang == "*" {
+		return ""
+	}
+	return lang
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: errorBody(r, err)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v) // fails only if the client has gone
+}
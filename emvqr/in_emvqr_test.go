@@ -122,7 +122,7 @@ func TestRoundTrip_BaseExample_NPCIBhartQR(t *testing.T) {
 	}
 
 	assertEqual(t, "PayloadFormatIndicator", "01", decoded.PayloadFormatIndicator)
-	assertEqual(t, "PointOfInitiationMethod", poiStatic, decoded.PointOfInitiationMethod)
+	assertEqual(t, "PointOfInitiationMethod", poiStatic, string(decoded.PointOfInitiationMethod))
 	assertEqual(t, "TransactionCurrency", inrCurrency, decoded.TransactionCurrency)
 	assertEqual(t, "CountryCode", inCountryCode, decoded.CountryCode)
 	assertEqual(t, "MerchantName", merchantName, decoded.MerchantName)
@@ -778,7 +778,7 @@ func TestDecode_RealWorldBharatQRExample(t *testing.T) {
 	// =========================================================================
 	// Tag 01: Point of Initiation Method
 	// =========================================================================
-	assertEqual(t, "PointOfInitiationMethod", "12", decoded.PointOfInitiationMethod)
+	assertEqual(t, "PointOfInitiationMethod", "12", string(decoded.PointOfInitiationMethod))
 
 	// =========================================================================
 	// Tag 02-11: Merchant Account Information (multiple networks)
@@ -957,7 +957,7 @@ func TestDecode_RealWorldBharatQR_ObjectComparison(t *testing.T) {
 			{ID: "11", Value: "310900031273986"},
 			{ID: "26", SubFields: []DataObject{{ID: "00", Value: "A000000524"}, {ID: "01", Value: "SBIPMOPAD.02PL00000644432-21503961@SBIPAY"}}},
 			{ID: "27", SubFields: []DataObject{{ID: "00", Value: "A000000524"}, {ID: "01", Value: "52602091445452087569609"}, {ID: "02", Value: "https://www.hitachi-payments.com"}}},
-			{ID: "28", SubFields: []DataObject{{ID: "00", Value: "A000000524"}}},
+			{ID: "28", SubFields: []DataObject{{ID: "00", Value: "A000000524"}, {ID: "01", Value: ""}}},
 		},
 		UPIVPAInfo: &UPIVPATemplate{
 			RuPayRID:      "A000000524",
@@ -0,0 +1,304 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrUnreadable is returned when a sampled module grid is not a decodable
+// QR Code symbol.
+var ErrUnreadable = errors.New("qr: unreadable symbol")
+
+var errTooManyErrors = fmt.Errorf("%w: too many errors to correct", ErrUnreadable)
+
+// maxFormatDistance is the number of bit errors tolerated in the format and
+// version information; both BCH codes correct up to three.
+const maxFormatDistance = 3
+
+// Decoded is the content of a successfully decoded symbol.
+type Decoded struct {
+	Version int
+	Level   Level
+	Mask    int
+	Data    []byte
+	// Corrected is the number of codewords repaired by error correction.
+	Corrected int
+}
+
+// Decode reads the data carried by a sampled symbol of size×size modules.
+// dark reports the colour of the module at column x, row y. Mirrored symbols
+// are not supported.
+func Decode(size int, dark func(x, y int) bool) (*Decoded, error) {
+	version := (size - 17) / 4
+	if size%4 != 1 || version < MinVersion || version > MaxVersion {
+		return nil, fmt.Errorf("%w: invalid size %d", ErrUnreadable, size)
+	}
+	level, mask, err := readFormat(size, dark)
+	if err != nil {
+		return nil, err
+	}
+	if version >= 7 {
+		if v, ok := readVersion(size, dark); ok && v != version {
+			return nil, fmt.Errorf("%w: version information %d disagrees with size %d", ErrUnreadable, v, size)
+		}
+	}
+
+	c := newCode(version, level)
+	c.drawFunctionPatterns()
+	raw := make([]byte, numRawDataModules(version)/8)
+	i := 0
+	c.forEachDataModule(func(x, y int) {
+		if i < len(raw)*8 {
+			if dark(x, y) != MaskBit(mask, x, y) {
+				raw[i>>3] |= 1 << uint(7-i&7)
+			}
+			i++
+		}
+	})
+
+	data, corrected, err := deinterleaveAndCorrect(raw, version, level)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := parseSegments(data, version)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoded{Version: version, Level: level, Mask: mask, Data: payload, Corrected: corrected}, nil
+}
+
+// readFormat reads both copies of the format information and returns the
+// closest valid level and mask.
+func readFormat(size int, dark func(x, y int) bool) (Level, int, error) {
+	var a, b int
+	bit := func(v *int, i int, x, y int) {
+		if dark(x, y) {
+			*v |= 1 << uint(i)
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		bit(&a, i, 8, i)
+	}
+	bit(&a, 6, 8, 7)
+	bit(&a, 7, 8, 8)
+	bit(&a, 8, 7, 8)
+	for i := 9; i < 15; i++ {
+		bit(&a, i, 14-i, 8)
+	}
+	for i := 0; i < 8; i++ {
+		bit(&b, i, size-1-i, 8)
+	}
+	for i := 8; i < 15; i++ {
+		bit(&b, i, 8, size-15+i)
+	}
+
+	best, bestLevel, bestMask := maxFormatDistance+1, Low, 0
+	for level := Low; level <= High; level++ {
+		for mask := 0; mask < 8; mask++ {
+			want := FormatBits(level, mask)
+			d := min(bits.OnesCount(uint(a^want)), bits.OnesCount(uint(b^want)))
+			if d < best {
+				best, bestLevel, bestMask = d, level, mask
+			}
+		}
+	}
+	if best > maxFormatDistance {
+		return 0, 0, fmt.Errorf("%w: format information damaged", ErrUnreadable)
+	}
+	return bestLevel, bestMask, nil
+}
+
+// readVersion reads both copies of the version information (version ≥ 7).
+func readVersion(size int, dark func(x, y int) bool) (int, bool) {
+	var a, b int
+	for i := 0; i < 18; i++ {
+		p, q := size-11+i%3, i/3
+		if dark(p, q) {
+			a |= 1 << uint(i)
+		}
+		if dark(q, p) {
+			b |= 1 << uint(i)
+		}
+	}
+	best, bestVersion := maxFormatDistance+1, 0
+	for v := 7; v <= MaxVersion; v++ {
+		want := VersionBits(v)
+		if d := min(bits.OnesCount(uint(a^want)), bits.OnesCount(uint(b^want))); d < best {
+			best, bestVersion = d, v
+		}
+	}
+	return bestVersion, best <= maxFormatDistance
+}
+
+// deinterleaveAndCorrect reverses addECCAndInterleave, corrects each block
+// and returns the concatenated data codewords.
+func deinterleaveAndCorrect(raw []byte, version int, level Level) ([]byte, int, error) {
+	l := layoutFor(version, level)
+	blocks := make([][]byte, l.numBlocks)
+	for i := range blocks {
+		blocks[i] = make([]byte, l.shortBlockLen+1)
+	}
+	k := 0
+	for i := 0; i <= l.shortBlockLen; i++ {
+		for j := range blocks {
+			if i != l.shortBlockLen-l.eccLen || j >= l.numShortBlocks {
+				blocks[j][i] = raw[k]
+				k++
+			}
+		}
+	}
+
+	var data []byte
+	corrected := 0
+	for j, block := range blocks {
+		datLen := l.shortBlockLen - l.eccLen
+		if j < l.numShortBlocks {
+			// Drop the padding slot that short blocks skip when interleaved.
+			block = append(block[:datLen], block[datLen+1:]...)
+		} else {
+			datLen++
+		}
+		n, err := rsCorrect(block, l.eccLen)
+		if err != nil {
+			return nil, 0, err
+		}
+		corrected += n
+		data = append(data, block[:datLen]...)
+	}
+	return data, corrected, nil
+}
+
+// bitReader reads big-endian bit fields from a byte slice.
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) remaining() int {
+	return len(r.data)*8 - r.pos
+}
+
+func (r *bitReader) read(n int) (int, error) {
+	if n > r.remaining() {
+		return 0, fmt.Errorf("%w: truncated data segment", ErrUnreadable)
+	}
+	v := 0
+	for i := 0; i < n; i++ {
+		v = v<<1 | int(r.data[r.pos>>3]>>uint(7-r.pos&7)&1)
+		r.pos++
+	}
+	return v, nil
+}
+
+// Mode indicators understood by the decoder.
+const (
+	indicatorTerminator       = 0x0
+	indicatorNumeric          = 0x1
+	indicatorAlphanumeric     = 0x2
+	indicatorStructuredAppend = 0x3
+	indicatorByte             = 0x4
+	indicatorECI              = 0x7
+)
+
+// parseSegments decodes the numeric, alphanumeric and byte segments of the
+// data bit stream. ECI and structured append headers are skipped; Kanji and
+// FNC1 segments are reported as unreadable.
+func parseSegments(data []byte, version int) ([]byte, error) {
+	r := &bitReader{data: data}
+	var out []byte
+	for r.remaining() >= 4 {
+		mode, _ := r.read(4)
+		switch mode {
+		case indicatorTerminator:
+			return out, nil
+		case indicatorStructuredAppend:
+			if _, err := r.read(16); err != nil {
+				return nil, err
+			}
+		case indicatorECI:
+			first, err := r.read(8)
+			if err != nil {
+				return nil, err
+			}
+			extra := 0
+			switch {
+			case first&0xC0 == 0x80:
+				extra = 8
+			case first&0xE0 == 0xC0:
+				extra = 16
+			}
+			if _, err := r.read(extra); err != nil {
+				return nil, err
+			}
+		case indicatorNumeric:
+			n, err := r.read(numericCountBits(version))
+			if err != nil {
+				return nil, err
+			}
+			for ; n > 0; n -= 3 {
+				digits, width := min(n, 3), [...]int{0, 4, 7, 10}[min(n, 3)]
+				v, err := r.read(width)
+				if err != nil {
+					return nil, err
+				}
+				s := fmt.Sprintf("%0*d", digits, v)
+				if len(s) != digits {
+					return nil, fmt.Errorf("%w: invalid numeric segment", ErrUnreadable)
+				}
+				out = append(out, s...)
+			}
+		case indicatorAlphanumeric:
+			n, err := r.read(ModeAlphanumeric.charCountBits(version))
+			if err != nil {
+				return nil, err
+			}
+			for ; n > 1; n -= 2 {
+				v, err := r.read(11)
+				if err != nil {
+					return nil, err
+				}
+				if v >= 45*45 {
+					return nil, fmt.Errorf("%w: invalid alphanumeric segment", ErrUnreadable)
+				}
+				out = append(out, alphanumericCharset[v/45], alphanumericCharset[v%45])
+			}
+			if n == 1 {
+				v, err := r.read(6)
+				if err != nil {
+					return nil, err
+				}
+				if v >= 45 {
+					return nil, fmt.Errorf("%w: invalid alphanumeric segment", ErrUnreadable)
+				}
+				out = append(out, alphanumericCharset[v])
+			}
+		case indicatorByte:
+			n, err := r.read(ModeByte.charCountBits(version))
+			if err != nil {
+				return nil, err
+			}
+			for ; n > 0; n-- {
+				v, err := r.read(8)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, byte(v))
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported segment mode %#x", ErrUnreadable, mode)
+		}
+	}
+	return out, nil
+}
+
+// numericCountBits returns the width of a numeric segment's character count.
+func numericCountBits(version int) int {
+	switch {
+	case version >= 27:
+		return 14
+	case version >= 10:
+		return 12
+	}
+	return 10
+}
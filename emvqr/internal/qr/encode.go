@@ -0,0 +1,291 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDataTooLong is returned when the data does not fit in any permitted
+// version at the requested error correction level.
+var ErrDataTooLong = errors.New("qr: data too long")
+
+// Mode is a QR Code segment data mode.
+type Mode int
+
+// Supported data modes.
+const (
+	ModeByte Mode = iota
+	ModeAlphanumeric
+)
+
+// alphanumericCharset is the 45-character set of alphanumeric mode.
+const alphanumericCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// IsAlphanumeric reports whether every byte of data can be encoded in
+// alphanumeric mode.
+func IsAlphanumeric(data []byte) bool {
+	for _, b := range data {
+		if alphanumericIndex(b) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// alphanumericIndex returns the alphanumeric-mode value of b, or -1.
+func alphanumericIndex(b byte) int {
+	for i := 0; i < len(alphanumericCharset); i++ {
+		if alphanumericCharset[i] == b {
+			return i
+		}
+	}
+	return -1
+}
+
+// modeIndicator returns the 4-bit mode indicator.
+func (m Mode) modeIndicator() int {
+	if m == ModeAlphanumeric {
+		return 0x2
+	}
+	return 0x4
+}
+
+// charCountBits returns the width of the character count field.
+func (m Mode) charCountBits(version int) int {
+	i := 0
+	switch {
+	case version >= 27:
+		i = 2
+	case version >= 10:
+		i = 1
+	}
+	if m == ModeAlphanumeric {
+		return [...]int{9, 11, 13}[i]
+	}
+	return [...]int{8, 16, 16}[i]
+}
+
+// Code is an encoded QR Code symbol.
+type Code struct {
+	Version int
+	Level   Level
+	Mask    int
+	Mode    Mode
+	Size    int
+
+	modules    []bool
+	isFunction []bool
+}
+
+// Dark reports whether the module at column x, row y is dark. Coordinates
+// outside the symbol are light.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+
+// Options constrains symbol construction.
+type Options struct {
+	Level      Level
+	MinVersion int // 0 means 1
+	MaxVersion int // 0 means 40
+	// FixedMask forces mask pattern Mask (0–7); otherwise the mask with the
+	// lowest penalty score is selected.
+	FixedMask bool
+	Mask      int
+}
+
+// Capacity returns the number of data bytes a symbol of the given version and
+// level can hold in the given mode.
+func Capacity(version int, level Level, mode Mode) int {
+	bits := numDataCodewords(version, level)*8 - 4 - mode.charCountBits(version)
+	if mode == ModeAlphanumeric {
+		n := bits / 11 * 2
+		if bits%11 >= 6 {
+			n++
+		}
+		return n
+	}
+	return bits / 8
+}
+
+// Encode builds the smallest symbol that holds data. Alphanumeric mode is
+// used when every byte allows it, byte mode otherwise.
+func Encode(data []byte, opts Options) (*Code, error) {
+	if opts.Level < Low || opts.Level > High {
+		return nil, fmt.Errorf("qr: invalid error correction level %d", opts.Level)
+	}
+	minV, maxV := opts.MinVersion, opts.MaxVersion
+	if minV == 0 {
+		minV = MinVersion
+	}
+	if maxV == 0 {
+		maxV = MaxVersion
+	}
+	if minV < MinVersion || maxV > MaxVersion || minV > maxV {
+		return nil, fmt.Errorf("qr: invalid version range %d–%d", minV, maxV)
+	}
+	mode := ModeByte
+	if IsAlphanumeric(data) {
+		mode = ModeAlphanumeric
+	}
+
+	version := 0
+	for v := minV; v <= maxV; v++ {
+		if len(data) <= Capacity(v, opts.Level, mode) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%w: %d bytes exceed the capacity of version %d-%s",
+			ErrDataTooLong, len(data), maxV, opts.Level)
+	}
+
+	codewords := encodeData(data, mode, version, opts.Level)
+	all := addECCAndInterleave(codewords, version, opts.Level)
+
+	c := newCode(version, opts.Level)
+	c.Mode = mode
+	c.drawFunctionPatterns()
+	c.drawCodewords(all)
+
+	if opts.FixedMask && (opts.Mask < 0 || opts.Mask > 7) {
+		return nil, fmt.Errorf("qr: invalid mask pattern %d", opts.Mask)
+	}
+	mask := opts.Mask
+	if !opts.FixedMask {
+		mask = 0
+		best := -1
+		for m := 0; m < 8; m++ {
+			c.applyMask(m)
+			c.drawFormatBits(m)
+			if p := c.penaltyScore(); best < 0 || p < best {
+				best, mask = p, m
+			}
+			c.applyMask(m) // XOR undoes the mask
+		}
+	}
+	c.Mask = mask
+	c.applyMask(mask)
+	c.drawFormatBits(mask)
+	return c, nil
+}
+
+// newCode allocates an empty symbol.
+func newCode(version int, level Level) *Code {
+	size := sizeForVersion(version)
+	return &Code{
+		Version:    version,
+		Level:      level,
+		Size:       size,
+		modules:    make([]bool, size*size),
+		isFunction: make([]bool, size*size),
+	}
+}
+
+// bitBuffer accumulates bits most-significant first.
+type bitBuffer []bool
+
+func (b *bitBuffer) append(val, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (val>>uint(i))&1 != 0)
+	}
+}
+
+// encodeData produces the padded data codewords for a single segment.
+func encodeData(data []byte, mode Mode, version int, level Level) []byte {
+	var bb bitBuffer
+	bb.append(mode.modeIndicator(), 4)
+	bb.append(len(data), mode.charCountBits(version))
+	if mode == ModeAlphanumeric {
+		i := 0
+		for ; i+1 < len(data); i += 2 {
+			bb.append(alphanumericIndex(data[i])*45+alphanumericIndex(data[i+1]), 11)
+		}
+		if i < len(data) {
+			bb.append(alphanumericIndex(data[i]), 6)
+		}
+	} else {
+		for _, b := range data {
+			bb.append(int(b), 8)
+		}
+	}
+
+	capacityBits := numDataCodewords(version, level) * 8
+	terminator := capacityBits - len(bb)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bb.append(0, terminator)
+	bb.append(0, (8-len(bb)%8)%8)
+
+	out := make([]byte, 0, capacityBits/8)
+	for i := 0; i < len(bb); i += 8 {
+		var v byte
+		for j := 0; j < 8; j++ {
+			if bb[i+j] {
+				v |= 1 << uint(7-j)
+			}
+		}
+		out = append(out, v)
+	}
+	for pad := byte(0xEC); len(out) < capacityBits/8; pad ^= 0xEC ^ 0x11 {
+		out = append(out, pad)
+	}
+	return out
+}
+
+// blockLayout describes how codewords are split into error correction blocks.
+type blockLayout struct {
+	numBlocks      int
+	eccLen         int
+	numShortBlocks int
+	shortBlockLen  int // total codewords (data + ECC) of a short block
+}
+
+func layoutFor(version int, level Level) blockLayout {
+	numBlocks := numErrorCorrectionBlocks[level][version]
+	raw := numRawDataModules(version) / 8
+	return blockLayout{
+		numBlocks:      numBlocks,
+		eccLen:         eccCodewordsPerBlock[level][version],
+		numShortBlocks: numBlocks - raw%numBlocks,
+		shortBlockLen:  raw / numBlocks,
+	}
+}
+
+// addECCAndInterleave splits data into blocks, appends Reed–Solomon codewords
+// to each and interleaves the result.
+func addECCAndInterleave(data []byte, version int, level Level) []byte {
+	l := layoutFor(version, level)
+	divisor := rsDivisor(l.eccLen)
+	blocks := make([][]byte, l.numBlocks)
+	k := 0
+	for i := range blocks {
+		datLen := l.shortBlockLen - l.eccLen
+		if i >= l.numShortBlocks {
+			datLen++
+		}
+		dat := data[k : k+datLen]
+		k += datLen
+		block := make([]byte, 0, l.shortBlockLen+1)
+		block = append(block, dat...)
+		if i < l.numShortBlocks {
+			block = append(block, 0)
+		}
+		blocks[i] = append(block, rsRemainder(dat, divisor)...)
+	}
+
+	out := make([]byte, 0, numRawDataModules(version)/8)
+	for i := 0; i < len(blocks[0]); i++ {
+		for j, block := range blocks {
+			if i != l.shortBlockLen-l.eccLen || j >= l.numShortBlocks {
+				out = append(out, block[i])
+			}
+		}
+	}
+	return out
+}
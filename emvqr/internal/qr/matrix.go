@@ -0,0 +1,285 @@
+package qr
+
+// set writes a function module and marks it as reserved.
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y*c.Size+x] = dark
+	c.isFunction[y*c.Size+x] = true
+}
+
+// drawFunctionPatterns draws timing, finder, alignment and version patterns
+// and reserves the format information area.
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	pos := alignmentPatternPositions(c.Version)
+	last := len(pos) - 1
+	for i := range pos {
+		for j := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignment(pos[i], pos[j])
+		}
+	}
+
+	c.drawFormatBits(0) // reserve; overwritten once the mask is chosen
+	c.drawVersion()
+}
+
+// drawFinder draws a finder pattern and its separator centred on (x, y).
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= c.Size || yy >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.set(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern centred on (x, y).
+func (c *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// FormatBits returns the 15-bit BCH-protected format information for a level
+// and mask, already XORed with the standard mask 0x5412.
+func FormatBits(level Level, mask int) int {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawFormatBits draws both copies of the format information.
+func (c *Code) drawFormatBits(mask int) {
+	bits := FormatBits(c.Level, mask)
+	bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	c.set(8, c.Size-8, true) // dark module
+}
+
+// VersionBits returns the 18-bit BCH-protected version information.
+func VersionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+// drawVersion draws both copies of the version information (version ≥ 7).
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	bits := VersionBits(c.Version)
+	for i := 0; i < 18; i++ {
+		dark := (bits>>uint(i))&1 != 0
+		a, b := c.Size-11+i%3, i/3
+		c.set(a, b, dark)
+		c.set(b, a, dark)
+	}
+}
+
+// forEachDataModule calls fn for every non-function module in placement
+// order: two-column strips from the right, alternating upward and downward,
+// skipping the vertical timing pattern.
+func (c *Code) forEachDataModule(fn func(x, y int)) {
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !c.isFunction[y*c.Size+x] {
+					fn(x, y)
+				}
+			}
+		}
+	}
+}
+
+// drawCodewords places the interleaved codewords into the data area.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	c.forEachDataModule(func(x, y int) {
+		if i < len(data)*8 {
+			c.modules[y*c.Size+x] = (data[i>>3]>>uint(7-i&7))&1 != 0
+			i++
+		}
+	})
+}
+
+// MaskBit reports whether mask pattern m inverts the module at (x, y).
+func MaskBit(m, x, y int) bool {
+	switch m {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// applyMask XORs mask pattern m over the data area. Applying the same mask
+// twice restores the original modules.
+func (c *Code) applyMask(m int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			i := y*c.Size + x
+			if !c.isFunction[i] && MaskBit(m, x, y) {
+				c.modules[i] = !c.modules[i]
+			}
+		}
+	}
+}
+
+// Penalty weights from ISO/IEC 18004 section 7.8.3.
+const (
+	penaltyN1 = 3
+	penaltyN2 = 3
+	penaltyN3 = 40
+	penaltyN4 = 10
+)
+
+// penaltyScore evaluates the current module layout; lower is better.
+func (c *Code) penaltyScore() int {
+	score := 0
+	n := c.Size
+
+	line := make([]bool, n)
+	for horizontal := 0; horizontal < 2; horizontal++ {
+		for a := 0; a < n; a++ {
+			for b := 0; b < n; b++ {
+				if horizontal == 0 {
+					line[b] = c.modules[a*n+b]
+				} else {
+					line[b] = c.modules[b*n+a]
+				}
+			}
+			score += runPenalty(line) + finderLikePenalty(line)
+		}
+	}
+
+	for y := 0; y < n-1; y++ {
+		for x := 0; x < n-1; x++ {
+			d := c.modules[y*n+x]
+			if d == c.modules[y*n+x+1] && d == c.modules[(y+1)*n+x] && d == c.modules[(y+1)*n+x+1] {
+				score += penaltyN2
+			}
+		}
+	}
+
+	dark := 0
+	for _, m := range c.modules {
+		if m {
+			dark++
+		}
+	}
+	total := n * n
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	score += k * penaltyN4
+	return score
+}
+
+// runPenalty scores runs of five or more same-coloured modules.
+func runPenalty(line []bool) int {
+	score, run := 0, 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			score += penaltyN1 + run - 5
+		}
+		run = 1
+	}
+	return score
+}
+
+// finderLikePenalty scores 1:1:3:1:1 patterns preceded or followed by four
+// light modules; positions outside the symbol count as light.
+func finderLikePenalty(line []bool) int {
+	pattern := [...]bool{true, false, true, true, true, false, true}
+	at := func(i int) bool { return i >= 0 && i < len(line) && line[i] }
+	score := 0
+	for i := 0; i+len(pattern) <= len(line); i++ {
+		match := true
+		for j, p := range pattern {
+			if line[i+j] != p {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		lightBefore, lightAfter := true, true
+		for j := 1; j <= 4; j++ {
+			lightBefore = lightBefore && !at(i-j)
+			lightAfter = lightAfter && !at(i+len(pattern)-1+j)
+		}
+		if lightBefore || lightAfter {
+			score += penaltyN3
+		}
+	}
+	return score
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
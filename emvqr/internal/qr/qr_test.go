@@ -0,0 +1,158 @@
+package qr
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestFormatBits_KnownValues(t *testing.T) {
+	// Values from ISO/IEC 18004 Annex C.
+	cases := []struct {
+		level Level
+		mask  int
+		want  int
+	}{
+		{Medium, 0, 0x5412},
+		{Low, 0, 0x77C4},
+		{High, 7, 0x083B},
+	}
+	for _, tc := range cases {
+		if got := FormatBits(tc.level, tc.mask); got != tc.want {
+			t.Errorf("FormatBits(%s, %d) = %#04x, want %#04x", tc.level, tc.mask, got, tc.want)
+		}
+	}
+}
+
+func TestVersionBits_KnownValue(t *testing.T) {
+	if got := VersionBits(7); got != 0x07C94 {
+		t.Errorf("VersionBits(7) = %#05x, want 0x07c94", got)
+	}
+}
+
+func TestCapacity_KnownValues(t *testing.T) {
+	// Byte-mode capacities from ISO/IEC 18004 Table 7.
+	cases := []struct {
+		version int
+		level   Level
+		mode    Mode
+		want    int
+	}{
+		{1, Low, ModeByte, 17},
+		{1, High, ModeByte, 7},
+		{10, Medium, ModeByte, 213},
+		{40, Low, ModeByte, 2953},
+		{1, Low, ModeAlphanumeric, 25},
+		{40, High, ModeAlphanumeric, 1852},
+	}
+	for _, tc := range cases {
+		if got := Capacity(tc.version, tc.level, tc.mode); got != tc.want {
+			t.Errorf("Capacity(%d, %s, %d) = %d, want %d", tc.version, tc.level, tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestEncode_SelectsSmallestVersion(t *testing.T) {
+	c, err := Encode([]byte("HELLO WORLD"), Options{Level: Quartile})
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if c.Version != 1 || c.Mode != ModeAlphanumeric || c.Size != 21 {
+		t.Errorf("got version %d mode %d size %d, want 1/alphanumeric/21", c.Version, c.Mode, c.Size)
+	}
+}
+
+func TestEncode_FinderPatterns(t *testing.T) {
+	c, err := Encode([]byte("emv payload"), Options{Level: Medium})
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	for _, origin := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		for i := 0; i < 7; i++ {
+			if !c.Dark(origin[0]+i, origin[1]) || !c.Dark(origin[0], origin[1]+i) {
+				t.Fatalf("finder pattern at %v has a light edge module", origin)
+			}
+		}
+		if c.Dark(origin[0]+1, origin[1]+1) || !c.Dark(origin[0]+3, origin[1]+3) {
+			t.Errorf("finder pattern at %v has the wrong ring structure", origin)
+		}
+	}
+}
+
+func TestEncode_DataTooLong(t *testing.T) {
+	_, err := Encode([]byte(strings.Repeat("a", 300)), Options{Level: High, MaxVersion: 10})
+	if !errors.Is(err, ErrDataTooLong) {
+		t.Fatalf("expected ErrDataTooLong, got %v", err)
+	}
+}
+
+func TestEncode_FixedMask(t *testing.T) {
+	c, err := Encode([]byte("123"), Options{Level: Low, FixedMask: true, Mask: 5})
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if c.Mask != 5 {
+		t.Errorf("Mask = %d, want 5", c.Mask)
+	}
+	if _, err := Encode([]byte("123"), Options{FixedMask: true, Mask: 8}); err == nil {
+		t.Error("expected error for mask 8, got nil")
+	}
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for _, data := range []string{"HELLO WORLD", "000201010211", strings.Repeat("emvqr payload ", 40)} {
+		for level := Low; level <= High; level++ {
+			c, err := Encode([]byte(data), Options{Level: level})
+			if err != nil {
+				t.Fatalf("Encode error: %v", err)
+			}
+			// Damage a few data codewords; every level corrects at least
+			// one codeword per block.
+			flipped := map[[2]int]bool{}
+			c.forEachDataModule(func(x, y int) {
+				if len(flipped) < 3 && r.Intn(200) == 0 {
+					flipped[[2]int{x, y}] = true
+				}
+			})
+			d, err := Decode(c.Size, func(x, y int) bool { return c.Dark(x, y) != flipped[[2]int{x, y}] })
+			if err != nil {
+				t.Fatalf("%q at %s: Decode error: %v", data, level, err)
+			}
+			if string(d.Data) != data || d.Version != c.Version || d.Level != level || d.Mask != c.Mask {
+				t.Errorf("%q at %s: decoded %q v%d-%s mask %d", data, level, d.Data, d.Version, d.Level, d.Mask)
+			}
+		}
+	}
+}
+
+func TestRSCorrect_UpToCapacity(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	const eccLen = 18
+	for trial := 0; trial < 200; trial++ {
+		data := make([]byte, 40)
+		r.Read(data)
+		block := append(append([]byte(nil), data...), rsRemainder(data, rsDivisor(eccLen))...)
+		errs := r.Intn(eccLen/2 + 1)
+		for _, p := range r.Perm(len(block))[:errs] {
+			block[p] ^= byte(1 + r.Intn(255))
+		}
+		n, err := rsCorrect(block, eccLen)
+		if err != nil {
+			t.Fatalf("trial %d: %d errors: %v", trial, errs, err)
+		}
+		if n != errs || string(block[:len(data)]) != string(data) {
+			t.Fatalf("trial %d: corrected %d of %d errors, data restored = %v", trial, n, errs, string(block[:len(data)]) == string(data))
+		}
+	}
+}
+
+func TestDecode_Unreadable(t *testing.T) {
+	if _, err := Decode(22, func(x, y int) bool { return false }); !errors.Is(err, ErrUnreadable) {
+		t.Errorf("size 22: expected ErrUnreadable, got %v", err)
+	}
+	if _, err := Decode(21, func(x, y int) bool { return (x+y)%2 == 0 }); !errors.Is(err, ErrUnreadable) {
+		t.Errorf("checkerboard: expected ErrUnreadable, got %v", err)
+	}
+}
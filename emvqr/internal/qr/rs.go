@@ -0,0 +1,159 @@
+package qr
+
+// gfMul multiplies two elements of GF(2^8) modulo the QR Code polynomial
+// x^8 + x^4 + x^3 + x^2 + 1 (0x11D).
+func gfMul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+// rsDivisor returns the Reed–Solomon generator polynomial of the given
+// degree, highest coefficient first and the leading 1 omitted.
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the error correction codewords for data.
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= gfMul(d, factor)
+		}
+	}
+	return result
+}
+
+// gfExp and gfLog are exponent and logarithm tables for GF(2^8) with
+// generator 0x02; gfExp is doubled so products need no modular reduction.
+var gfExp, gfLog = func() (exp [510]byte, log [256]int) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = x, x
+		log[x] = i
+		x = gfMul(x, 0x02)
+	}
+	return
+}()
+
+// gfDiv divides x by a non-zero y.
+func gfDiv(x, y byte) byte {
+	if x == 0 {
+		return 0
+	}
+	return gfExp[gfLog[x]+255-gfLog[y]]
+}
+
+// gfPolyEval evaluates a polynomial stored lowest coefficient first at x.
+func gfPolyEval(p []byte, x byte) byte {
+	var y byte
+	for i := len(p) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ p[i]
+	}
+	return y
+}
+
+// rsCorrect corrects block (data followed by eccLen error correction
+// codewords) in place and returns the number of codewords repaired. It uses
+// Berlekamp–Massey to find the error locator, a Chien search for the error
+// positions and Forney's formula for their values.
+func rsCorrect(block []byte, eccLen int) (int, error) {
+	n := len(block)
+	// Syndromes S_i = r(α^i); codeword j carries degree n-1-j.
+	synd := make([]byte, eccLen)
+	clean := true
+	for i := range synd {
+		var s byte
+		x := gfExp[i]
+		for _, b := range block {
+			s = gfMul(s, x) ^ b
+		}
+		synd[i] = s
+		clean = clean && s == 0
+	}
+	if clean {
+		return 0, nil
+	}
+
+	// Berlekamp–Massey; polynomials are lowest coefficient first.
+	locator, prev := []byte{1}, []byte{1}
+	l, m, b := 0, 1, byte(1)
+	for k := 0; k < eccLen; k++ {
+		d := synd[k]
+		for i := 1; i <= l && i < len(locator); i++ {
+			d ^= gfMul(locator[i], synd[k-i])
+		}
+		if d == 0 {
+			m++
+			continue
+		}
+		coef := gfDiv(d, b)
+		next := make([]byte, max(len(locator), len(prev)+m))
+		copy(next, locator)
+		for i, p := range prev {
+			next[i+m] ^= gfMul(coef, p)
+		}
+		if 2*l <= k {
+			prev, l, b, m = locator, k+1-l, d, 1
+		} else {
+			m++
+		}
+		locator = next
+	}
+	if 2*l > eccLen {
+		return 0, errTooManyErrors
+	}
+
+	// Chien search: an error at degree e makes α^-e a root of the locator.
+	var positions []int
+	for e := 0; e < n; e++ {
+		if gfPolyEval(locator, gfExp[(255-e%255)%255]) == 0 {
+			positions = append(positions, e)
+		}
+	}
+	if len(positions) != l {
+		return 0, errTooManyErrors
+	}
+
+	// Forney: Ω = S·Λ mod x^eccLen, Y = X·Ω(X⁻¹)/Λ'(X⁻¹).
+	omega := make([]byte, eccLen)
+	for i, s := range synd {
+		for j, c := range locator {
+			if i+j < eccLen {
+				omega[i+j] ^= gfMul(s, c)
+			}
+		}
+	}
+	deriv := make([]byte, len(locator))
+	for i := 1; i < len(locator); i += 2 {
+		deriv[i-1] = locator[i]
+	}
+	for _, e := range positions {
+		xInv := gfExp[(255-e%255)%255]
+		den := gfPolyEval(deriv, xInv)
+		if den == 0 {
+			return 0, errTooManyErrors
+		}
+		block[n-1-e] ^= gfMul(gfExp[e%255], gfDiv(gfPolyEval(omega, xInv), den))
+	}
+	return len(positions), nil
+}
@@ -0,0 +1,110 @@
+// Package qr implements the QR Code model 2 symbology (ISO/IEC 18004) used by
+// the render and scan entry points of the emvqr module. It is internal so the
+// public API can evolve independently of the symbol construction details.
+package qr
+
+// Level is a QR Code error correction level.
+type Level int
+
+// Error correction levels, in increasing order of redundancy.
+const (
+	Low      Level = iota // recovers ~7% of codewords
+	Medium                // recovers ~15% of codewords
+	Quartile              // recovers ~25% of codewords
+	High                  // recovers ~30% of codewords
+)
+
+// String returns the single-letter level name used by the standard.
+func (l Level) String() string {
+	switch l {
+	case Low:
+		return "L"
+	case Medium:
+		return "M"
+	case Quartile:
+		return "Q"
+	case High:
+		return "H"
+	default:
+		return "?"
+	}
+}
+
+// formatBits returns the two-bit level indicator stored in the format info.
+func (l Level) formatBits() int {
+	return [...]int{1, 0, 3, 2}[l]
+}
+
+// levelFromFormatBits is the inverse of Level.formatBits.
+func levelFromFormatBits(b int) Level {
+	return [...]Level{Medium, Low, High, Quartile}[b&3]
+}
+
+// Version bounds.
+const (
+	MinVersion = 1
+	MaxVersion = 40
+)
+
+// eccCodewordsPerBlock is indexed by [Level][version]; index 0 is unused.
+var eccCodewordsPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// numErrorCorrectionBlocks is indexed by [Level][version]; index 0 is unused.
+var numErrorCorrectionBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// sizeForVersion returns the side length in modules of a symbol.
+func sizeForVersion(version int) int {
+	return version*4 + 17
+}
+
+// numRawDataModules returns the number of modules available for data and
+// error correction codewords, after removing all function patterns.
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+// numDataCodewords returns the number of 8-bit data codewords a symbol of the
+// given version and level can hold.
+func numDataCodewords(version int, level Level) int {
+	return numRawDataModules(version)/8 -
+		eccCodewordsPerBlock[level][version]*numErrorCorrectionBlocks[level][version]
+}
+
+// alignmentPatternPositions returns the centre coordinates used for the
+// alignment patterns of a version, in ascending order.
+func alignmentPatternPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	if version == 32 {
+		step = 26
+	}
+	result := make([]int, numAlign)
+	result[0] = 6
+	pos := sizeForVersion(version) - 7
+	for i := numAlign - 1; i >= 1; i-- {
+		result[i] = pos
+		pos -= step
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+// Package interop converts between emvqr payloads and the JSON payloads of
+// the card networks' merchant-presented QR REST APIs, so PSPs integrating
+// those APIs need not hand-map each field:
+//
+//   - MastercardPayload follows the PushPaymentData schema of Mastercard's
+//     Merchant Presented QR API and SDK.
+//   - VisaPayload follows the merchant payload of Visa's mVisa API.
+//
+// Both schemas are flat and know nothing of Bharat QR templates, unreserved
+// templates or RFU fields; converting a payload that uses them fails with
+// ErrNotRepresentable rather than dropping data. Values are copied as is,
+// without validation: Encode the converted payload to check it.
+package interop
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// ErrNotRepresentable is returned when a payload holds fields that the
+// target JSON schema has no place for.
+var ErrNotRepresentable = errors.New("interop: field not representable")
+
+// AdditionalData is the Additional Data Field Template (Tag 62) in both
+// schemas.
+type AdditionalData struct {
+	BillNumber                    string `json:"billNumber,omitempty"`
+	MobileNumber                  string `json:"mobileNumber,omitempty"`
+	StoreID                       string `json:"storeId,omitempty"`
+	LoyaltyNumber                 string `json:"loyaltyNumber,omitempty"`
+	ReferenceID                   string `json:"referenceId,omitempty"`
+	ConsumerID                    string `json:"consumerId,omitempty"`
+	TerminalID                    string `json:"terminalId,omitempty"`
+	Purpose                       string `json:"purpose,omitempty"`
+	AdditionalConsumerDataRequest string `json:"additionalConsumerDataRequest,omitempty"`
+}
+
+// LanguageData is the Merchant Information – Language Template (Tag 64) in
+// both schemas.
+type LanguageData struct {
+	LanguagePreference    string `json:"languagePreference,omitempty"`
+	AlternateMerchantName string `json:"alternateMerchantName,omitempty"`
+	AlternateMerchantCity string `json:"alternateMerchantCity,omitempty"`
+}
+
+// checkRepresentable returns an error wrapping ErrNotRepresentable naming
+// the fields of p, other than merchant identifiers, that neither schema
+// holds.
+func checkRepresentable(p *emvqr.Payload) error {
+	var fields []string
+	if p.UPIVPAInfo != nil || p.UPITransactionRef != nil || p.MerchantAadhaar != nil {
+		fields = append(fields, "Bharat QR templates 26–28")
+	}
+	if len(p.UnreservedTemplates) > 0 {
+		fields = append(fields, "unreserved templates")
+	}
+	if len(p.RFUFields) > 0 {
+		fields = append(fields, "RFU fields")
+	}
+	if a := p.AdditionalData; a != nil &&
+		(a.MerchantTaxID != "" || a.MerchantChannel != "" || len(a.PaymentSystemTemplates) > 0 || len(a.RFUFields) > 0) {
+		fields = append(fields, "additional data v1.1 and RFU sub-fields")
+	}
+	if lt := p.LanguageTemplate; lt != nil && len(lt.RFUFields) > 0 {
+		fields = append(fields, "language template RFU sub-fields")
+	}
+	if len(fields) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotRepresentable, strings.Join(fields, ", "))
+	}
+	return nil
+}
+
+func fromAdditionalData(a *emvqr.AdditionalDataField) *AdditionalData {
+	if a == nil {
+		return nil
+	}
+	return &AdditionalData{
+		BillNumber:                    a.BillNumber,
+		MobileNumber:                  a.MobileNumber,
+		StoreID:                       a.StoreLabel,
+		LoyaltyNumber:                 a.LoyaltyNumber,
+		ReferenceID:                   a.ReferenceLabel,
+		ConsumerID:                    a.CustomerLabel,
+		TerminalID:                    a.TerminalLabel,
+		Purpose:                       a.PurposeOfTransaction,
+		AdditionalConsumerDataRequest: a.AdditionalConsumerDataRequest,
+	}
+}
+
+func (a *AdditionalData) payload() *emvqr.AdditionalDataField {
+	if a == nil || *a == (AdditionalData{}) {
+		return nil
+	}
+	return &emvqr.AdditionalDataField{
+		BillNumber:                    a.BillNumber,
+		MobileNumber:                  a.MobileNumber,
+		StoreLabel:                    a.StoreID,
+		LoyaltyNumber:                 a.LoyaltyNumber,
+		ReferenceLabel:                a.ReferenceID,
+		CustomerLabel:                 a.ConsumerID,
+		TerminalLabel:                 a.TerminalID,
+		PurposeOfTransaction:          a.Purpose,
+		AdditionalConsumerDataRequest: a.AdditionalConsumerDataRequest,
+	}
+}
+
+func fromLanguageTemplate(lt *emvqr.LanguageTemplate) *LanguageData {
+	if lt == nil {
+		return nil
+	}
+	return &LanguageData{
+		LanguagePreference:    lt.LanguagePreference,
+		AlternateMerchantName: lt.MerchantName,
+		AlternateMerchantCity: lt.MerchantCity,
+	}
+}
+
+func (l *LanguageData) payload() *emvqr.LanguageTemplate {
+	if l == nil || *l == (LanguageData{}) {
+		return nil
+	}
+	return &emvqr.LanguageTemplate{
+		LanguagePreference: l.LanguagePreference,
+		MerchantName:       l.AlternateMerchantName,
+		MerchantCity:       l.AlternateMerchantCity,
+	}
+}
@@ -0,0 +1,111 @@
+package interop
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func testPayload() *emvqr.Payload {
+	p := emvqr.NewPayload()
+	p.MerchantIdentifiers = []emvqr.MerchantIdentifier{
+		{ID: "02", Value: "4000123456789012"},
+		{ID: "04", Value: "5100123456789012"},
+	}
+	p.MerchantCategoryCode = "5251"
+	p.TransactionCurrency = "840"
+	p.TransactionAmount = "12.50"
+	p.TipOrConvenienceIndicator = emvqr.TipIndicatorFixedConvenienceFee
+	p.ValueConvenienceFeeFixed = "1.00"
+	p.CountryCode = "US"
+	p.MerchantName = "ABC Hammers"
+	p.MerchantCity = "New York"
+	p.AdditionalData = &emvqr.AdditionalDataField{BillNumber: "INV-1", TerminalLabel: "T1"}
+	p.LanguageTemplate = &emvqr.LanguageTemplate{LanguagePreference: "es", MerchantName: "Martillos ABC"}
+	return p
+}
+
+func TestMastercard(t *testing.T) {
+	p := testPayload()
+	m, err := ToMastercard(p)
+	if err != nil {
+		t.Fatalf("ToMastercard() error: %v", err)
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"merchantIdentifierVisa02":"4000123456789012"`,
+		`"merchantIdentifierMastercard04":"5100123456789012"`,
+		`"transactionCurrencyCode":"840"`,
+		`"valueOfConvenienceFeeFixed":"1.00"`,
+		`"additionalData":{"billNumber":"INV-1","terminalId":"T1"}`,
+		`"languageData":{"languagePreference":"es","alternateMerchantName":"Martillos ABC"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON %s\nmissing %s", data, want)
+		}
+	}
+
+	var back MastercardPayload
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(p, FromMastercard(&back)); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestVisa(t *testing.T) {
+	p := testPayload()
+	v, err := ToVisa(p)
+	if err != nil {
+		t.Fatalf("ToVisa() error: %v", err)
+	}
+	if v.MerchantPAN != "4000123456789012" || v.CurrencyCode != "840" || v.ConvenienceFeeFixed != "1.00" {
+		t.Errorf("ToVisa() = %+v", v)
+	}
+
+	want := testPayload()
+	want.MerchantIdentifiers = want.MerchantIdentifiers[:1] // Visa only
+	if diff := cmp.Diff(want, FromVisa(v)); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := emvqr.Encode(FromVisa(v)); err != nil {
+		t.Errorf("Encode(FromVisa()) error: %v", err)
+	}
+
+	p.MerchantIdentifiers = []emvqr.MerchantIdentifier{{ID: "03", Value: "4111"}}
+	if v, _ := ToVisa(p); v.MerchantPAN != "4111" {
+		t.Errorf("MerchantPAN from Tag 03 = %q, want 4111", v.MerchantPAN)
+	}
+}
+
+func TestNotRepresentable(t *testing.T) {
+	for name, edit := range map[string]func(*emvqr.Payload){
+		"UPI VPA":    func(p *emvqr.Payload) { p.UPIVPAInfo = &emvqr.UPIVPATemplate{VPA: "shop@upi"} },
+		"unreserved": func(p *emvqr.Payload) { p.UnreservedTemplates = []emvqr.UnreservedTemplate{{ID: "80"}} },
+		"RFU":        func(p *emvqr.Payload) { p.RFUFields = []emvqr.DataObject{{ID: "70", Value: "x"}} },
+		"tax ID":     func(p *emvqr.Payload) { p.AdditionalData.MerchantTaxID = "X" },
+	} {
+		p := testPayload()
+		edit(p)
+		if _, err := ToMastercard(p); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("%s: ToMastercard error %v, want ErrNotRepresentable", name, err)
+		}
+		if _, err := ToVisa(p); !errors.Is(err, ErrNotRepresentable) {
+			t.Errorf("%s: ToVisa error %v, want ErrNotRepresentable", name, err)
+		}
+	}
+
+	p := testPayload()
+	p.MerchantIdentifiers = append(p.MerchantIdentifiers, emvqr.MerchantIdentifier{ID: "30", Value: "X"})
+	if _, err := ToMastercard(p); !errors.Is(err, ErrNotRepresentable) {
+		t.Errorf("ID 30: ToMastercard error %v, want ErrNotRepresentable", err)
+	}
+}
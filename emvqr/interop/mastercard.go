@@ -0,0 +1,124 @@
+package interop
+
+import (
+	"fmt"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// MastercardPayload is the PushPaymentData JSON of Mastercard's Merchant
+// Presented QR API. Merchant identifiers have one field per network and ID.
+type MastercardPayload struct {
+	PayloadFormatIndicator  string `json:"payloadFormatIndicator,omitempty"`
+	PointOfInitiationMethod string `json:"pointOfInitiationMethod,omitempty"`
+
+	MerchantIdentifierVisa02       string `json:"merchantIdentifierVisa02,omitempty"`
+	MerchantIdentifierVisa03       string `json:"merchantIdentifierVisa03,omitempty"`
+	MerchantIdentifierMastercard04 string `json:"merchantIdentifierMastercard04,omitempty"`
+	MerchantIdentifierMastercard05 string `json:"merchantIdentifierMastercard05,omitempty"`
+	MerchantIdentifierNPCI06       string `json:"merchantIdentifierNPCI06,omitempty"`
+	MerchantIdentifierNPCI07       string `json:"merchantIdentifierNPCI07,omitempty"`
+	MerchantIdentifierIFSCCode08   string `json:"merchantIdentifierIFSCCode08,omitempty"`
+	MerchantIdentifierDiscover09   string `json:"merchantIdentifierDiscover09,omitempty"`
+	MerchantIdentifierDiscover10   string `json:"merchantIdentifierDiscover10,omitempty"`
+	MerchantIdentifierAmex11       string `json:"merchantIdentifierAmex11,omitempty"`
+	MerchantIdentifierAmex12       string `json:"merchantIdentifierAmex12,omitempty"`
+	MerchantIdentifierJCB13        string `json:"merchantIdentifierJCB13,omitempty"`
+	MerchantIdentifierJCB14        string `json:"merchantIdentifierJCB14,omitempty"`
+	MerchantIdentifierUnionPay15   string `json:"merchantIdentifierUnionPay15,omitempty"`
+	MerchantIdentifierUnionPay16   string `json:"merchantIdentifierUnionPay16,omitempty"`
+
+	MerchantCategoryCode            string `json:"merchantCategoryCode,omitempty"`
+	TransactionCurrencyCode         string `json:"transactionCurrencyCode,omitempty"`
+	TransactionAmount               string `json:"transactionAmount,omitempty"`
+	TipOrConvenienceIndicator       string `json:"tipOrConvenienceIndicator,omitempty"`
+	ValueOfConvenienceFeeFixed      string `json:"valueOfConvenienceFeeFixed,omitempty"`
+	ValueOfConvenienceFeePercentage string `json:"valueOfConvenienceFeePercentage,omitempty"`
+	CountryCode                     string `json:"countryCode,omitempty"`
+	MerchantName                    string `json:"merchantName,omitempty"`
+	MerchantCity                    string `json:"merchantCity,omitempty"`
+	PostalCode                      string `json:"postalCode,omitempty"`
+
+	AdditionalData *AdditionalData `json:"additionalData,omitempty"`
+	LanguageData   *LanguageData   `json:"languageData,omitempty"`
+	CRC            string          `json:"crc,omitempty"`
+}
+
+// identifiers returns the merchant identifier fields of m keyed by ID.
+func (m *MastercardPayload) identifiers() map[string]*string {
+	return map[string]*string{
+		"02": &m.MerchantIdentifierVisa02, "03": &m.MerchantIdentifierVisa03,
+		"04": &m.MerchantIdentifierMastercard04, "05": &m.MerchantIdentifierMastercard05,
+		"06": &m.MerchantIdentifierNPCI06, "07": &m.MerchantIdentifierNPCI07,
+		"08": &m.MerchantIdentifierIFSCCode08,
+		"09": &m.MerchantIdentifierDiscover09, "10": &m.MerchantIdentifierDiscover10,
+		"11": &m.MerchantIdentifierAmex11, "12": &m.MerchantIdentifierAmex12,
+		"13": &m.MerchantIdentifierJCB13, "14": &m.MerchantIdentifierJCB14,
+		"15": &m.MerchantIdentifierUnionPay15, "16": &m.MerchantIdentifierUnionPay16,
+	}
+}
+
+// ToMastercard converts p to Mastercard's PushPaymentData JSON. It fails with
+// ErrNotRepresentable when p holds merchant identifiers outside "02"–"16",
+// repeats one, or holds any field listed in the package documentation.
+func ToMastercard(p *emvqr.Payload) (*MastercardPayload, error) {
+	if err := checkRepresentable(p); err != nil {
+		return nil, err
+	}
+	m := &MastercardPayload{
+		PayloadFormatIndicator:          p.PayloadFormatIndicator,
+		PointOfInitiationMethod:         string(p.PointOfInitiationMethod),
+		MerchantCategoryCode:            p.MerchantCategoryCode,
+		TransactionCurrencyCode:         p.TransactionCurrency,
+		TransactionAmount:               p.TransactionAmount,
+		TipOrConvenienceIndicator:       p.TipOrConvenienceIndicator,
+		ValueOfConvenienceFeeFixed:      p.ValueConvenienceFeeFixed,
+		ValueOfConvenienceFeePercentage: p.ValueConvenienceFeePercent,
+		CountryCode:                     p.CountryCode,
+		MerchantName:                    p.MerchantName,
+		MerchantCity:                    p.MerchantCity,
+		PostalCode:                      p.PostalCode,
+		AdditionalData:                  fromAdditionalData(p.AdditionalData),
+		LanguageData:                    fromLanguageTemplate(p.LanguageTemplate),
+		CRC:                             p.CRC,
+	}
+	ids := m.identifiers()
+	for _, mi := range p.MerchantIdentifiers {
+		field, ok := ids[mi.ID]
+		if !ok || *field != "" {
+			return nil, fmt.Errorf("%w: merchant identifier %s", ErrNotRepresentable, mi.ID)
+		}
+		*field = mi.Value
+	}
+	return m, nil
+}
+
+// FromMastercard converts Mastercard's PushPaymentData JSON to a payload,
+// with merchant identifiers in ID order.
+func FromMastercard(m *MastercardPayload) *emvqr.Payload {
+	p := &emvqr.Payload{
+		PayloadFormatIndicator:     m.PayloadFormatIndicator,
+		PointOfInitiationMethod:    emvqr.POI(m.PointOfInitiationMethod),
+		MerchantCategoryCode:       m.MerchantCategoryCode,
+		TransactionCurrency:        m.TransactionCurrencyCode,
+		TransactionAmount:          m.TransactionAmount,
+		TipOrConvenienceIndicator:  m.TipOrConvenienceIndicator,
+		ValueConvenienceFeeFixed:   m.ValueOfConvenienceFeeFixed,
+		ValueConvenienceFeePercent: m.ValueOfConvenienceFeePercentage,
+		CountryCode:                m.CountryCode,
+		MerchantName:               m.MerchantName,
+		MerchantCity:               m.MerchantCity,
+		PostalCode:                 m.PostalCode,
+		AdditionalData:             m.AdditionalData.payload(),
+		LanguageTemplate:           m.LanguageData.payload(),
+		CRC:                        m.CRC,
+	}
+	ids := m.identifiers()
+	for n := 2; n <= 16; n++ {
+		id := fmt.Sprintf("%02d", n)
+		if v := *ids[id]; v != "" {
+			p.MerchantIdentifiers = append(p.MerchantIdentifiers, emvqr.MerchantIdentifier{ID: id, Value: v})
+		}
+	}
+	return p
+}
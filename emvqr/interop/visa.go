@@ -0,0 +1,82 @@
+package interop
+
+import (
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// VisaPayload is the merchant payload JSON of Visa's mVisa API. It describes
+// Visa acceptance only: the merchant PAN is that of Tag "02", or "03" when
+// "02" is absent.
+type VisaPayload struct {
+	MerchantPAN              string `json:"merchantPan,omitempty"`
+	MerchantName             string `json:"merchantName,omitempty"`
+	MerchantCity             string `json:"merchantCity,omitempty"`
+	MerchantCategoryCode     string `json:"merchantCategoryCode,omitempty"`
+	CountryCode              string `json:"countryCode,omitempty"`
+	CurrencyCode             string `json:"currencyCode,omitempty"`
+	PostalCode               string `json:"postalCode,omitempty"`
+	TransactionAmount        string `json:"transactionAmount,omitempty"`
+	PointOfInitiation        string `json:"pointOfInitiation,omitempty"`
+	ConvenienceFeeIndicator  string `json:"convenienceFeeIndicator,omitempty"`
+	ConvenienceFeeFixed      string `json:"convenienceFeeFixed,omitempty"`
+	ConvenienceFeePercentage string `json:"convenienceFeePercentage,omitempty"`
+
+	AdditionalData *AdditionalData `json:"additionalData,omitempty"`
+	LanguageData   *LanguageData   `json:"languageData,omitempty"`
+}
+
+// ToVisa converts p to Visa's mVisa JSON. Merchant identifiers of other
+// networks are left out; fields listed in the package documentation fail
+// with ErrNotRepresentable.
+func ToVisa(p *emvqr.Payload) (*VisaPayload, error) {
+	if err := checkRepresentable(p); err != nil {
+		return nil, err
+	}
+	v := &VisaPayload{
+		MerchantName:             p.MerchantName,
+		MerchantCity:             p.MerchantCity,
+		MerchantCategoryCode:     p.MerchantCategoryCode,
+		CountryCode:              p.CountryCode,
+		CurrencyCode:             p.TransactionCurrency,
+		PostalCode:               p.PostalCode,
+		TransactionAmount:        p.TransactionAmount,
+		PointOfInitiation:        string(p.PointOfInitiationMethod),
+		ConvenienceFeeIndicator:  p.TipOrConvenienceIndicator,
+		ConvenienceFeeFixed:      p.ValueConvenienceFeeFixed,
+		ConvenienceFeePercentage: p.ValueConvenienceFeePercent,
+		AdditionalData:           fromAdditionalData(p.AdditionalData),
+		LanguageData:             fromLanguageTemplate(p.LanguageTemplate),
+	}
+	for _, mi := range p.MerchantIdentifiers {
+		switch {
+		case mi.ID == "02":
+			v.MerchantPAN = mi.Value
+		case mi.ID == "03" && v.MerchantPAN == "":
+			v.MerchantPAN = mi.Value
+		}
+	}
+	return v, nil
+}
+
+// FromVisa converts Visa's mVisa JSON to a payload, with the merchant PAN
+// under Tag "02".
+func FromVisa(v *VisaPayload) *emvqr.Payload {
+	p := emvqr.NewPayload()
+	if v.MerchantPAN != "" {
+		p.MerchantIdentifiers = []emvqr.MerchantIdentifier{{ID: "02", Value: v.MerchantPAN}}
+	}
+	p.PointOfInitiationMethod = emvqr.POI(v.PointOfInitiation)
+	p.MerchantCategoryCode = v.MerchantCategoryCode
+	p.TransactionCurrency = v.CurrencyCode
+	p.TransactionAmount = v.TransactionAmount
+	p.TipOrConvenienceIndicator = v.ConvenienceFeeIndicator
+	p.ValueConvenienceFeeFixed = v.ConvenienceFeeFixed
+	p.ValueConvenienceFeePercent = v.ConvenienceFeePercentage
+	p.CountryCode = v.CountryCode
+	p.MerchantName = v.MerchantName
+	p.MerchantCity = v.MerchantCity
+	p.PostalCode = v.PostalCode
+	p.AdditionalData = v.AdditionalData.payload()
+	p.LanguageTemplate = v.LanguageData.payload()
+	return p
+}
@@ -0,0 +1,43 @@
+package iso8583
+
+import (
+	"fmt"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// exponent returns the number of minor-unit digits of a currency.
+func exponent(currency string) int {
+	return emvqr.CurrencyExponent(currency)
+}
+
+// minorUnits converts a Tag 54 amount such as "10.5" to a zero-padded DE4
+// amount in minor units, "000000001050" for an exponent of 2.
+func minorUnits(amount string, exp int) (string, error) {
+	whole, frac, _ := strings.Cut(amount, ".")
+	if len(frac) > exp || (whole == "" && frac == "") {
+		return "", fmt.Errorf("%w: amount %q has more than %d decimals", ErrInvalidElement, amount, exp)
+	}
+	digits := strings.TrimLeft(whole+frac+strings.Repeat("0", exp-len(frac)), "0")
+	if (digits != "" && !isDigits(digits)) || len(digits) > amountWidth {
+		return "", fmt.Errorf("%w: amount %q does not fit DE4", ErrInvalidElement, amount)
+	}
+	return strings.Repeat("0", amountWidth-len(digits)) + digits, nil
+}
+
+// majorUnits converts a DE4 amount in minor units back to a Tag 54 amount,
+// "000000001050" to "10.50" for an exponent of 2.
+func majorUnits(de4 string, exp int) (string, error) {
+	if len(de4) != amountWidth || !isDigits(de4) {
+		return "", fmt.Errorf("%w: DE4 must be %d digits", ErrInvalidElement, amountWidth)
+	}
+	digits := strings.TrimLeft(de4, "0")
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	if exp == 0 {
+		return digits, nil
+	}
+	return digits[:len(digits)-exp] + "." + digits[len(digits)-exp:], nil
+}
@@ -0,0 +1,165 @@
+// Package iso8583 maps EMV merchant payloads to and from the ISO 8583 data
+// elements that acquiring hosts populate when forwarding Bharat QR and
+// card-network QR transactions:
+//
+//	DE2   Primary Account Number   the merchant PAN (Tags "02"–"16")
+//	DE4   Amount, Transaction      Tag 54, in minor units, 12 digits
+//	DE18  Merchant Type            Tag 52
+//	DE43  Card Acceptor Name/Loc.  Tags 59, 60 and 58, 40 characters
+//	DE49  Currency Code            Tag 53
+//
+// In a merchant-presented QR payment the merchant PAN is the account being
+// credited, so DE2 carries it as in a push payment:
+//
+//	msg, err := iso8583.FromPayload(p)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	de43 := msg[iso8583.DE43] // "ABC HAMMERS              NEW YORK     US"
+package iso8583
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// Data element numbers mapped by FromPayload and ToPayload.
+const (
+	DE2  = 2  // Primary Account Number
+	DE4  = 4  // Amount, Transaction
+	DE18 = 18 // Merchant Type
+	DE43 = 43 // Card Acceptor Name/Location
+	DE49 = 49 // Currency Code, Transaction
+)
+
+// Widths of the fixed-length elements and of the DE43 sub-fields (name,
+// city, country code), in the Visa and Mastercard layout.
+const (
+	amountWidth  = 12
+	de43Width    = 40
+	de43Name     = 25
+	de43City     = 13
+	de43Country  = 2
+	minPANLength = 12
+	maxPANLength = 19
+)
+
+// ErrInvalidElement is returned when a data element cannot be mapped.
+var ErrInvalidElement = errors.New("iso8583: invalid data element")
+
+// Message holds ISO 8583 data element values keyed by element number, in
+// their wire representation but without length prefixes.
+type Message map[int]string
+
+// FromPayload maps p into ISO 8583 data elements. Elements whose fields are
+// absent are omitted. DE2 holds the first card-network merchant PAN, in ID
+// order; DE43 truncates the merchant city to its 13 characters, as acquiring
+// hosts do.
+func FromPayload(p *emvqr.Payload) (Message, error) {
+	m := Message{}
+	if pan := merchantPAN(p); pan != "" {
+		m[DE2] = pan
+	}
+	if p.TransactionAmount != "" {
+		amount, err := minorUnits(p.TransactionAmount, exponent(p.TransactionCurrency))
+		if err != nil {
+			return nil, err
+		}
+		m[DE4] = amount
+	}
+	if p.MerchantCategoryCode != "" {
+		m[DE18] = p.MerchantCategoryCode
+	}
+	if p.MerchantName != "" || p.MerchantCity != "" || p.CountryCode != "" {
+		m[DE43] = pad(strings.ToUpper(p.MerchantName), de43Name) +
+			pad(strings.ToUpper(p.MerchantCity), de43City) +
+			pad(p.CountryCode, de43Country)
+	}
+	if p.TransactionCurrency != "" {
+		m[DE49] = p.TransactionCurrency
+	}
+	return m, nil
+}
+
+// ToPayload maps ISO 8583 data elements back into a payload, the reverse of
+// FromPayload. The DE2 PAN is stored under the merchant account information
+// ID of its network, chosen by its leading digit: "02" (Visa) for 4, "04"
+// (Mastercard) for 2 and 5, and "06" (RuPay) otherwise. Names and cities keep
+// the upper case and truncation of DE43.
+func ToPayload(m Message) (*emvqr.Payload, error) {
+	p := emvqr.NewPayload()
+	if pan, ok := m[DE2]; ok {
+		if !isDigits(pan) || len(pan) < minPANLength || len(pan) > maxPANLength {
+			return nil, fmt.Errorf("%w: DE2 must be %d–%d digits", ErrInvalidElement, minPANLength, maxPANLength)
+		}
+		p.MerchantIdentifiers = append(p.MerchantIdentifiers, emvqr.MerchantIdentifier{ID: panID(pan), Value: pan})
+	}
+	p.TransactionCurrency = m[DE49]
+	if v, ok := m[DE4]; ok {
+		amount, err := majorUnits(v, exponent(p.TransactionCurrency))
+		if err != nil {
+			return nil, err
+		}
+		p.TransactionAmount = amount
+	}
+	p.MerchantCategoryCode = m[DE18]
+	if v, ok := m[DE43]; ok {
+		if len(v) != de43Width {
+			return nil, fmt.Errorf("%w: DE43 must be %d characters, got %d", ErrInvalidElement, de43Width, len(v))
+		}
+		p.MerchantName = strings.TrimSpace(v[:de43Name])
+		p.MerchantCity = strings.TrimSpace(v[de43Name : de43Name+de43City])
+		p.CountryCode = strings.TrimSpace(v[de43Name+de43City:])
+	}
+	return p, nil
+}
+
+// merchantPAN returns the first merchant PAN of a card network (IDs
+// "02"–"16", except the Bharat QR IFSC and account ID "08"), in ID order.
+func merchantPAN(p *emvqr.Payload) string {
+	pan, panID := "", ""
+	for _, mi := range p.MerchantIdentifiers {
+		if mi.ID < "02" || mi.ID > "16" || mi.ID == "08" {
+			continue
+		}
+		if !isDigits(mi.Value) || len(mi.Value) < minPANLength || len(mi.Value) > maxPANLength {
+			continue
+		}
+		if panID == "" || mi.ID < panID {
+			pan, panID = mi.Value, mi.ID
+		}
+	}
+	return pan
+}
+
+// panID returns the merchant account information ID for a PAN's network.
+func panID(pan string) string {
+	switch pan[0] {
+	case '4':
+		return "02"
+	case '2', '5':
+		return "04"
+	default:
+		return "06"
+	}
+}
+
+// pad truncates or right-pads s with spaces to n bytes.
+func pad(s string, n int) string {
+	if len(s) >= n {
+		return s[:n]
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
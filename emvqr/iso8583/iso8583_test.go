@@ -0,0 +1,138 @@
+package iso8583
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func testPayload() *emvqr.Payload {
+	p := emvqr.NewPayload()
+	p.MerchantIdentifiers = []emvqr.MerchantIdentifier{
+		{ID: "08", Value: "HDFC000123400001234567"},
+		{ID: "04", Value: "5100123456789012"},
+		{ID: "02", Value: "4000123456789012"},
+	}
+	p.MerchantCategoryCode = "5251"
+	p.TransactionCurrency = "356"
+	p.TransactionAmount = "10.5"
+	p.CountryCode = "IN"
+	p.MerchantName = "Abc Hammers"
+	p.MerchantCity = "Thiruvananthapuram"
+	return p
+}
+
+func TestFromPayload(t *testing.T) {
+	m, err := FromPayload(testPayload())
+	if err != nil {
+		t.Fatalf("FromPayload() error: %v", err)
+	}
+	want := Message{
+		DE2:  "4000123456789012",
+		DE4:  "000000001050",
+		DE18: "5251",
+		DE43: "ABC HAMMERS              THIRUVANANTHAIN",
+		DE49: "356",
+	}
+	if diff := cmp.Diff(want, m); diff != "" {
+		t.Errorf("FromPayload() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFromPayload_Amounts(t *testing.T) {
+	for _, tt := range []struct {
+		currency, amount, want string
+	}{
+		{"356", "10", "000000001000"},
+		{"356", "0.01", "000000000001"},
+		{"392", "500", "000000000500"},
+		{"414", "1.234", "000000001234"},
+	} {
+		p := testPayload()
+		p.TransactionCurrency, p.TransactionAmount = tt.currency, tt.amount
+		m, err := FromPayload(p)
+		if err != nil {
+			t.Errorf("%s %s: error %v", tt.currency, tt.amount, err)
+			continue
+		}
+		if m[DE4] != tt.want {
+			t.Errorf("%s %s: DE4 = %q, want %q", tt.currency, tt.amount, m[DE4], tt.want)
+		}
+		back, err := majorUnits(m[DE4], exponent(tt.currency))
+		if err != nil {
+			t.Errorf("majorUnits(%q): %v", m[DE4], err)
+		}
+		if a, b := mustMinor(t, back, tt.currency), m[DE4]; a != b {
+			t.Errorf("%s %s: round trip %q → %q", tt.currency, tt.amount, b, a)
+		}
+	}
+
+	p := testPayload()
+	p.TransactionAmount = "1.234"
+	if _, err := FromPayload(p); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("three decimals in INR: error %v, want ErrInvalidElement", err)
+	}
+}
+
+func mustMinor(t *testing.T, amount, currency string) string {
+	t.Helper()
+	v, err := minorUnits(amount, exponent(currency))
+	if err != nil {
+		t.Fatalf("minorUnits(%q): %v", amount, err)
+	}
+	return v
+}
+
+func TestToPayload(t *testing.T) {
+	m, err := FromPayload(testPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ToPayload(m)
+	if err != nil {
+		t.Fatalf("ToPayload() error: %v", err)
+	}
+	want := emvqr.NewPayload()
+	want.MerchantIdentifiers = []emvqr.MerchantIdentifier{{ID: "02", Value: "4000123456789012"}}
+	want.MerchantCategoryCode = "5251"
+	want.TransactionCurrency = "356"
+	want.TransactionAmount = "10.50"
+	want.CountryCode = "IN"
+	want.MerchantName = "ABC HAMMERS"
+	want.MerchantCity = "THIRUVANANTHA"
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Errorf("ToPayload() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := emvqr.Encode(p); err != nil {
+		t.Errorf("Encode(ToPayload()) error: %v", err)
+	}
+
+	for _, pan := range []struct{ pan, id string }{
+		{"5100123456789012", "04"},
+		{"6070123456789012", "06"},
+	} {
+		p, err := ToPayload(Message{DE2: pan.pan})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.MerchantIdentifiers[0].ID; got != pan.id {
+			t.Errorf("PAN %s: ID %s, want %s", pan.pan, got, pan.id)
+		}
+	}
+}
+
+func TestToPayload_Invalid(t *testing.T) {
+	for name, m := range map[string]Message{
+		"short PAN":   {DE2: "1234"},
+		"alpha DE4":   {DE4: "00000000010A"},
+		"short DE4":   {DE4: "100"},
+		"short DE43":  {DE43: "ABC"},
+		"letters PAN": {DE2: "4000X23456789012"},
+	} {
+		if _, err := ToPayload(m); !errors.Is(err, ErrInvalidElement) {
+			t.Errorf("%s: error %v, want ErrInvalidElement", name, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package emvqr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSONSchema selects the JSON representation of a Payload.
+type JSONSchema string
+
+const (
+	// JSONSchemaStruct uses the Go field names of Payload, e.g.
+	// {"MerchantName": "ABC Hammers", "AdditionalData": {"BillNumber": ...}}.
+	JSONSchemaStruct JSONSchema = "struct"
+
+	// JSONSchemaEMV uses the two-digit tags as keys, with templates as
+	// nested objects, e.g. {"59": "ABC Hammers", "62": {"01": "INV-1"}}.
+	// Field names are those of Flatten, so services in other languages can
+	// produce and consume it from the EMV specification alone.
+	JSONSchemaEMV JSONSchema = "emv"
+)
+
+// JSONOptions controls MarshalJSONWithOptions and UnmarshalJSONWithOptions.
+type JSONOptions struct {
+	// Schema selects the representation. When marshaling, the zero value
+	// is JSONSchemaStruct; when unmarshaling, the zero value detects the
+	// schema from the keys of the top-level object.
+	Schema JSONSchema
+
+	// SpecVersion is used to interpret JSONSchemaEMV input; see
+	// FromFlatWithOptions.
+	SpecVersion SpecVersion
+
+	// DisallowUnknownFields rejects JSONSchemaStruct input with keys that
+	// are not Payload fields.
+	DisallowUnknownFields bool
+}
+
+// payloadFields has the fields of Payload but none of its methods, so it
+// encodes with the default struct representation.
+type payloadFields Payload
+
+// MarshalJSON implements json.Marshaler using JSONSchemaStruct.
+func (p *Payload) MarshalJSON() ([]byte, error) {
+	return MarshalJSONWithOptions(p, JSONOptions{})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Either schema is accepted.
+func (p *Payload) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSONWithOptions(data, p, JSONOptions{})
+}
+
+// MarshalJSONWithOptions encodes p as JSON in the selected schema.
+func MarshalJSONWithOptions(p *Payload, opts JSONOptions) ([]byte, error) {
+	switch opts.Schema {
+	case "", JSONSchemaStruct:
+		return json.Marshal((*payloadFields)(p))
+	case JSONSchemaEMV:
+		root := make(map[string]any)
+		for path, value := range p.Flatten() {
+			segments := strings.Split(path, ".")
+			node := root
+			for _, seg := range segments[:len(segments)-1] {
+				child, ok := node[seg].(map[string]any)
+				if !ok {
+					child = make(map[string]any)
+					node[seg] = child
+				}
+				node = child
+			}
+			node[segments[len(segments)-1]] = value
+		}
+		return json.Marshal(root)
+	default:
+		return nil, fmt.Errorf("emvqr: unknown JSON schema %q", opts.Schema)
+	}
+}
+
+// UnmarshalJSONWithOptions decodes data into p. JSONSchemaStruct input is
+// merged into p as encoding/json would; JSONSchemaEMV input replaces p
+// entirely. A JSON null leaves p unchanged.
+func UnmarshalJSONWithOptions(data []byte, p *Payload, opts JSONOptions) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	schema := opts.Schema
+	if schema == "" {
+		var err error
+		if schema, err = detectJSONSchema(data); err != nil {
+			return err
+		}
+	}
+	switch schema {
+	case JSONSchemaStruct:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if opts.DisallowUnknownFields {
+			dec.DisallowUnknownFields()
+		}
+		if err := dec.Decode((*payloadFields)(p)); err != nil {
+			return fmt.Errorf("emvqr: decoding payload JSON: %w", err)
+		}
+		return nil
+	case JSONSchemaEMV:
+		flat := make(map[string]string)
+		if err := flattenJSON(data, "", flat); err != nil {
+			return err
+		}
+		decoded, err := FromFlatWithOptions(flat, DecodeOptions{SpecVersion: opts.SpecVersion})
+		if err != nil {
+			return err
+		}
+		*p = *decoded
+		return nil
+	default:
+		return fmt.Errorf("emvqr: unknown JSON schema %q", schema)
+	}
+}
+
+// detectJSONSchema reports JSONSchemaEMV when every key of the top-level
+// object is a two-digit tag, and JSONSchemaStruct otherwise.
+func detectJSONSchema(data []byte) (JSONSchema, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return "", fmt.Errorf("emvqr: decoding payload JSON: %w", err)
+	}
+	if len(obj) == 0 {
+		return JSONSchemaStruct, nil
+	}
+	for key := range obj {
+		if len(key) != 2 || !isNumeric(key) {
+			return JSONSchemaStruct, nil
+		}
+	}
+	return JSONSchemaEMV, nil
+}
+
+// flattenJSON adds the string values of a JSONSchemaEMV object to flat,
+// keyed by dotted tag path.
+func flattenJSON(data []byte, prefix string, flat map[string]string) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("emvqr: decoding payload JSON at %q: %w", strings.TrimSuffix(prefix, "."), err)
+	}
+	for key, raw := range obj {
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil {
+			flat[prefix+key] = value
+			continue
+		}
+		if err := flattenJSON(raw, prefix+key+".", flat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
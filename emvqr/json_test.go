@@ -0,0 +1,109 @@
+package emvqr
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSON_StructSchema(t *testing.T) {
+	p := basePayload()
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.BillNumber = "INV-1" })
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	if !strings.Contains(string(data), `"MerchantName":"ABC Hammers"`) || !strings.Contains(string(data), `"BillNumber":"INV-1"`) {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	var back Payload
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if diff := cmp.Diff(p, &back); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+
+	err = UnmarshalJSONWithOptions([]byte(`{"MerchantNmae":"x"}`), &back, JSONOptions{DisallowUnknownFields: true})
+	if err == nil {
+		t.Error("UnmarshalJSONWithOptions() expected error for unknown field, got nil")
+	}
+}
+
+func TestJSON_EMVSchema(t *testing.T) {
+	// Like Flatten, the schema cannot hold the empty Tag 28.01.
+	p, err := DecodeWithOptions(realWorldBharatQRPayload, DecodeOptions{DropEmptySubFields: true})
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	data, err := MarshalJSONWithOptions(p, JSONOptions{Schema: JSONSchemaEMV})
+	if err != nil {
+		t.Fatalf("MarshalJSONWithOptions() error: %v", err)
+	}
+	for _, want := range []string{
+		`"00":"01"`,
+		`"26":{"00":"` + RuPayRIDValue + `","01":"SBIPMOPAD.02PL00000644432-21503961@SBIPAY"}`,
+		`"62":{"03":"02PL00000644432","05":"52602091445452087569609","07":"21503961"}`,
+		`"63":"51DD"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("MarshalJSONWithOptions() missing %s:\n%s", want, data)
+		}
+	}
+
+	// The schema is detected from the keys.
+	var back Payload
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if diff := cmp.Diff(p, &back); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestJSON_EMVSchemaErrors(t *testing.T) {
+	for _, input := range []string{
+		`{"59": 12}`,
+		`{"62": {"05": ["R555"]}}`,
+		`{"62": {"50": {"01": {"01": "x"}}}}`,
+	} {
+		var p Payload
+		if err := json.Unmarshal([]byte(input), &p); err == nil {
+			t.Errorf("Unmarshal(%s) expected error, got nil", input)
+		}
+	}
+	if _, err := MarshalJSONWithOptions(basePayload(), JSONOptions{Schema: "xml"}); err == nil {
+		t.Error("MarshalJSONWithOptions() expected error for unknown schema, got nil")
+	}
+}
+
+func TestJSON_EmbeddedPayload(t *testing.T) {
+	type message struct {
+		ID      string
+		Payload *Payload
+	}
+	in := message{ID: "m1", Payload: basePayload()}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	var out message
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if diff := cmp.Diff(in, out); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+
+	out = message{}
+	emv := `{"ID":"m2","Payload":{"59":"ABC Hammers","60":"New York"}}`
+	if err := json.Unmarshal([]byte(emv), &out); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if out.Payload.MerchantName != "ABC Hammers" || out.Payload.MerchantCity != "New York" {
+		t.Errorf("Unmarshal() = %+v", out.Payload)
+	}
+}
@@ -0,0 +1,201 @@
+package emvqr
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AlternateLanguagesGUID is the Globally Unique Identifier of the Unreserved
+// Template in which AddLanguage stores the languages beyond the one in Tag
+// 64. Each of its sub-fields ("01"–"99") holds one language, encoded like
+// the contents of Tag 64 ("00" language, "01" name, "02" city).
+const AlternateLanguagesGUID = "COM.GITHUB.EMVQR.LANG"
+
+// AddLanguage adds the merchant name and city in another language, e.g. for
+// a merchant in Chennai with both a Hindi and a Tamil name. The first
+// language goes into the Language Template (Tag 64), which every consumer
+// app understands; further languages go into an Unreserved Template
+// identified by AlternateLanguagesGUID, created in the lowest free ID from
+// "80" to "99". Adding a language that is already present replaces it.
+// Since that template is limited to 99 bytes, it holds about two short
+// names in Indic scripts; AddLanguage fails rather than overflow it.
+//
+// PreferredMerchantName and PreferredMerchantCity consult both places.
+func (p *Payload) AddLanguage(lang, name, city string) error {
+	if lang == "" {
+		return fmt.Errorf("%w: language is required", ErrInvalidFormat)
+	}
+	if lt := p.LanguageTemplate; lt == nil || lt.LanguagePreference == lang {
+		p.SetLanguageTemplate(lang, name, city)
+		return nil
+	}
+	value, err := encodeLanguageFields(&LanguageTemplate{
+		LanguagePreference: lang,
+		MerchantName:       name,
+		MerchantCity:       city,
+	})
+	if err != nil {
+		return err
+	}
+
+	var ut UnreservedTemplate
+	idx := p.alternateLanguagesIndex()
+	if idx >= 0 {
+		ut = p.UnreservedTemplates[idx]
+		ut.SubFields = append([]DataObject(nil), ut.SubFields...)
+	} else {
+		ut = UnreservedTemplate{ID: p.freeUnreservedID(), GloballyUniqueID: AlternateLanguagesGUID}
+		if ut.ID == "" {
+			return fmt.Errorf("emvqr: no free unreserved template ID for alternate languages")
+		}
+	}
+	replaced, last := false, 0
+	for i, sf := range ut.SubFields {
+		if alt, err := decodeLanguageTemplate(sf.Value); err == nil && alt.LanguagePreference == lang {
+			ut.SubFields[i].Value = value
+			replaced = true
+			break
+		}
+		if n, err := strconv.Atoi(sf.ID); err == nil && n > last {
+			last = n
+		}
+	}
+	if !replaced {
+		if last >= 99 {
+			return fmt.Errorf("emvqr: alternate languages template is full")
+		}
+		ut.SubFields = append(ut.SubFields, DataObject{ID: fmt.Sprintf("%02d", last+1), Value: value})
+	}
+	if _, err := encodeUnreservedTemplate(ut); err != nil {
+		return err
+	}
+	if idx >= 0 {
+		p.UnreservedTemplates[idx] = ut
+	} else {
+		p.UnreservedTemplates = append(p.UnreservedTemplates, ut)
+	}
+	return nil
+}
+
+// Languages returns the merchant name and city in each alternate language:
+// the Language Template (Tag 64) first, followed by those stored by
+// AddLanguage. Entries that cannot be parsed are skipped.
+func (p *Payload) Languages() []LanguageTemplate {
+	var langs []LanguageTemplate
+	if p.LanguageTemplate != nil {
+		langs = append(langs, *p.LanguageTemplate)
+	}
+	if i := p.alternateLanguagesIndex(); i >= 0 {
+		for _, sf := range p.UnreservedTemplates[i].SubFields {
+			if lt, err := decodeLanguageTemplate(sf.Value); err == nil {
+				langs = append(langs, *lt)
+			}
+		}
+	}
+	return langs
+}
+
+// SetLocalizedCity sets the merchant city in language lang, in the
+// Language Template or the alternate language added with AddLanguage. lang
+// may be a BCP-47 tag such as "hi-IN"; its primary subtag is stored.
+//
+// The city must be valid UTF-8 of at most 15 bytes, the Tag 64.02 limit,
+// without control or bidirectional formatting characters, and in the
+// script of a known language. The language must already have a merchant
+// name, since Tag 64.01 is mandatory; use AddLanguage to add both. Errors
+// wrap ErrInvalidFormat or ErrMissingRequired and leave the payload
+// unchanged.
+func (p *Payload) SetLocalizedCity(lang, city string) error {
+	lang = primaryLanguage(lang)
+	label := "Tag " + IDMerchantInfoLanguageTemplate + "." + LangMerchantCity
+	fail := func(format string, args ...any) error {
+		return newError(CodeInvalidFormat, fmt.Errorf("%w: "+format, append([]any{ErrInvalidFormat}, args...)...),
+			"tag", IDMerchantInfoLanguageTemplate+"."+LangMerchantCity)
+	}
+	if len(lang) != 2 || !isLowerAlpha(lang) {
+		return fail("language %q is not an ISO 639-1 code", lang)
+	}
+	if problems := checkLanguageText(label, city, subFieldSpec(IDMerchantInfoLanguageTemplate, LangMerchantCity).maxLen); len(problems) > 0 {
+		return fail("%s", problems[0])
+	}
+	if scripts, ok := languageScripts[lang]; ok {
+		if got := detectScript(city); got != "" && !slices.Contains(scripts, got) {
+			return fail("%s is in %s script but language %q is written in %s", label, got, lang, scripts[0])
+		}
+	}
+	if lt := p.LanguageTemplate; lt != nil && lt.LanguagePreference == lang {
+		lt.MerchantCity = city
+		return nil
+	}
+	for _, lt := range p.Languages() {
+		if lt.LanguagePreference == lang {
+			return p.AddLanguage(lang, lt.MerchantName, city)
+		}
+	}
+	return fmt.Errorf("%w: no merchant name in language %q; add one with AddLanguage", ErrMissingRequired, lang)
+}
+
+// primaryLanguage returns the primary subtag of a BCP-47 language tag in
+// lower case, e.g. "hi" for "hi-IN" or "HI_in".
+func primaryLanguage(tag string) string {
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return strings.ToLower(tag)
+}
+
+// languageMatch scores how well the language preference of a template,
+// have, serves a consumer's BCP-47 tag, want: 2 for the same tag ignoring
+// case, 1 for the same primary language, e.g. "hi" for "hi-IN", and 0 for
+// no match.
+func languageMatch(want, have string) int {
+	switch {
+	case want == "" || have == "":
+		return 0
+	case strings.EqualFold(want, have):
+		return 2
+	case primaryLanguage(want) == primaryLanguage(have):
+		return 1
+	}
+	return 0
+}
+
+// preferredLanguageValue returns the non-empty value of field in the
+// language template that best matches lang, the first one on ties.
+func (p *Payload) preferredLanguageValue(lang string, field func(*LanguageTemplate) string) (string, bool) {
+	best, score := "", 0
+	langs := p.Languages()
+	for i := range langs {
+		v := field(&langs[i])
+		if v == "" {
+			continue
+		}
+		if s := languageMatch(lang, langs[i].LanguagePreference); s > score {
+			best, score = v, s
+		}
+	}
+	return best, score > 0
+}
+
+// alternateLanguagesIndex returns the index of the Unreserved Template with
+// AlternateLanguagesGUID, or -1.
+func (p *Payload) alternateLanguagesIndex() int {
+	return p.extensionIndex(AlternateLanguagesGUID)
+}
+
+// freeUnreservedID returns the lowest Unreserved Template ID not in use, or
+// "" if all are taken.
+func (p *Payload) freeUnreservedID() string {
+	used := make(map[string]bool, len(p.UnreservedTemplates))
+	for _, ut := range p.UnreservedTemplates {
+		used[ut.ID] = true
+	}
+	for n := 80; n <= 99; n++ {
+		if id := strconv.Itoa(n); !used[id] {
+			return id
+		}
+	}
+	return ""
+}
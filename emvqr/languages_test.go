@@ -0,0 +1,114 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddLanguage(t *testing.T) {
+	p := basePayload()
+	p.UnreservedTemplates = []UnreservedTemplate{{ID: "80", GloballyUniqueID: "COM.EXAMPLE.PAY"}}
+	for _, l := range []struct{ lang, name, city string }{
+		{"hi", "राज मेडिकल", "चेन्नई"},
+		{"ta", "ராஜ்", ""},
+		{"te", "రాజ్", ""},
+		{"ta", "ராஜ் கடை", ""},
+	} {
+		if err := p.AddLanguage(l.lang, l.name, l.city); err != nil {
+			t.Fatalf("AddLanguage(%q): %v", l.lang, err)
+		}
+	}
+	if p.LanguageTemplate.LanguagePreference != "hi" {
+		t.Errorf("Tag 64 language = %q, want hi", p.LanguageTemplate.LanguagePreference)
+	}
+	ut := p.UnreservedTemplates[1]
+	if ut.ID != "81" || ut.GloballyUniqueID != AlternateLanguagesGUID || len(ut.SubFields) != 2 {
+		t.Fatalf("alternate languages template = %+v", ut)
+	}
+
+	raw, err := Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, lt := range decoded.Languages() {
+		got = append(got, lt.LanguagePreference)
+	}
+	assertEqual(t, "languages", "hi ta te", strings.Join(got, " "))
+	assertEqual(t, "name (hi)", "राज मेडिकल", decoded.PreferredMerchantName("hi"))
+	assertEqual(t, "name (ta)", "ராஜ் கடை", decoded.PreferredMerchantName("ta"))
+	assertEqual(t, "city (hi)", "चेन्नई", decoded.PreferredMerchantCity("hi"))
+	assertEqual(t, "name (te)", "రాజ్", decoded.PreferredMerchantName("te"))
+	assertEqual(t, "city (te)", "New York", decoded.PreferredMerchantCity("te"))
+	assertEqual(t, "name (kn)", "ABC Hammers", decoded.PreferredMerchantName("kn"))
+
+	if err := p.AddLanguage("kn", "ರಾಜ್ ಮೆಡಿಕಲ್ ಸ್ಟೋರ್", ""); err == nil {
+		t.Error("AddLanguage overflowing the template: want error")
+	}
+	if n := len(p.Languages()); n != 3 {
+		t.Errorf("failed AddLanguage left %d languages, want 3", n)
+	}
+	if err := p.AddLanguage("", "x", ""); err == nil {
+		t.Error("AddLanguage with empty language: want error")
+	}
+}
+
+func TestSetLocalizedCity(t *testing.T) {
+	p := basePayload()
+	if err := p.SetLocalizedCity("hi", "मुंबई"); !errors.Is(err, ErrMissingRequired) {
+		t.Fatalf("SetLocalizedCity without a name = %v, want ErrMissingRequired", err)
+	}
+	p.SetLanguageTemplate("hi", "राज मेडिकल", "")
+	if err := p.AddLanguage("ta", "ராஜ்", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.SetLocalizedCity("hi-IN", "मुंबई"); err != nil {
+		t.Fatalf("SetLocalizedCity(hi-IN) error: %v", err)
+	}
+	assertEqual(t, "64.02", "मुंबई", p.LanguageTemplate.MerchantCity)
+	if err := p.SetLocalizedCity("ta", "கோவை"); err != nil {
+		t.Fatalf("SetLocalizedCity(ta) error: %v", err)
+	}
+	assertEqual(t, "city (ta)", "கோவை", p.PreferredMerchantCity("ta"))
+
+	for _, tt := range []struct{ lang, city string }{
+		{"hi", "चेन्नई महानगर"}, // over 15 bytes
+		{"hi", "Mumbai"}, // Latin script for Hindi
+		{"hi", "मुंबई\u202e"},
+		{"hindi", "मुंबई"},
+	} {
+		if err := p.SetLocalizedCity(tt.lang, tt.city); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("SetLocalizedCity(%q, %q) = %v, want ErrInvalidFormat", tt.lang, tt.city, err)
+		}
+	}
+	assertEqual(t, "unchanged", "मुंबई", p.LanguageTemplate.MerchantCity)
+}
+
+func TestPreferredMerchantCity_BCP47(t *testing.T) {
+	p := basePayload()
+	p.SetLanguageTemplate("hi", "राज मेडिकल", "मुंबई")
+	for _, lang := range []string{"hi", "hi-IN", "HI-in", "hi_IN"} {
+		assertEqual(t, lang, "मुंबई", p.PreferredMerchantCity(lang))
+	}
+	assertEqual(t, "en-IN", "New York", p.PreferredMerchantCity("en-IN"))
+	assertEqual(t, "empty", "New York", p.PreferredMerchantCity(""))
+}
+
+func TestPreferredMerchantName_BCP47(t *testing.T) {
+	p := basePayload()
+	p.SetLanguageTemplate("pt", "Martelos ABC", "")
+	if err := p.AddLanguage("pt-BR", "Martelos do ABC", ""); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "pt-BR", "Martelos do ABC", p.PreferredMerchantName("pt-BR"))
+	assertEqual(t, "PT-br", "Martelos do ABC", p.PreferredMerchantName("PT-br"))
+	assertEqual(t, "pt-PT", "Martelos ABC", p.PreferredMerchantName("pt-PT"))
+	assertEqual(t, "pt", "Martelos ABC", p.PreferredMerchantName("pt"))
+	assertEqual(t, "es-ES", "ABC Hammers", p.PreferredMerchantName("es-ES"))
+}
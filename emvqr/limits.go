@@ -0,0 +1,116 @@
+package emvqr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Default limits applied by Decode. EMV QRCPS caps a payload at 512
+// characters; the other defaults leave ample room within that.
+const (
+	DefaultMaxPayloadLength = 512
+	DefaultMaxObjects       = 64
+	DefaultMaxSubFields     = 32
+	DefaultMaxDepth         = 3
+)
+
+// Limits bounds the input the decoder accepts, so that a malicious QR code
+// cannot make a kiosk or terminal do unbounded work. A zero field takes its
+// default and a negative one disables the limit.
+type Limits struct {
+	// MaxPayloadLength is the longest raw string accepted, in bytes.
+	MaxPayloadLength int
+	// MaxObjects is the most top-level data objects a payload may hold.
+	MaxObjects int
+	// MaxSubFields is the most sub-fields any one template may hold.
+	MaxSubFields int
+	// MaxDepth is the deepest nesting of data objects: 1 for top-level
+	// objects, 2 for template sub-fields such as Tag 62.05, and 3 for the
+	// sub-fields of a payment system template within Tag 62.
+	MaxDepth int
+}
+
+// withDefaults returns l with its zero fields set to the defaults.
+func (l Limits) withDefaults() Limits {
+	def := func(v *int, d int) {
+		if *v == 0 {
+			*v = d
+		}
+	}
+	def(&l.MaxPayloadLength, DefaultMaxPayloadLength)
+	def(&l.MaxObjects, DefaultMaxObjects)
+	def(&l.MaxSubFields, DefaultMaxSubFields)
+	def(&l.MaxDepth, DefaultMaxDepth)
+	return l
+}
+
+// check returns an error wrapping ErrLimitExceeded, with code
+// CodeLimitExceeded, if raw exceeds a limit. Malformed TLV is left for the
+// parser to report.
+func (l Limits) check(raw string) error {
+	l = l.withDefaults()
+	if l.MaxPayloadLength > 0 && len(raw) > l.MaxPayloadLength {
+		return limitError("MaxPayloadLength", l.MaxPayloadLength, "",
+			"payload is %d chars", len(raw))
+	}
+	return l.checkLevel(raw, "", 1)
+}
+
+// checkLevel checks the objects of s, the value of the template at path ("" at
+// the top level), nested depth levels deep.
+func (l Limits) checkLevel(s, path string, depth int) error {
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return limitError("MaxDepth", l.MaxDepth, path,
+			"template %s nests data objects %d levels deep", path, depth)
+	}
+	limit, name := l.MaxObjects, "MaxObjects"
+	if depth > 1 {
+		limit, name = l.MaxSubFields, "MaxSubFields"
+	}
+	for n := 1; len(s) >= 4; n++ {
+		length, ok := parseTLVLength(s[2], s[3])
+		if !ok || len(s) < 4+length {
+			return nil
+		}
+		id, value := s[:2], s[4:4+length]
+		s = s[4+length:]
+		if limit > 0 && n > limit {
+			if path == "" {
+				return limitError(name, limit, "", "payload holds more than %d data objects", limit)
+			}
+			return limitError(name, limit, path, "template %s holds more than %d sub-fields", path, limit)
+		}
+		var nested bool
+		switch path {
+		case "":
+			nested = isTemplateID(id)
+		case IDAdditionalDataFieldTemplate:
+			nested = isPaymentSystemTemplateID(id)
+		}
+		if nested {
+			if err := l.checkLevel(value, joinPath(path, id), depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// joinPath appends id to a dotted tag path.
+func joinPath(path, id string) string {
+	if path == "" {
+		return id
+	}
+	return path + "." + id
+}
+
+// limitError reports that the limit name, of max, was exceeded at path.
+func limitError(name string, max int, path, format string, args ...any) error {
+	params := []string{"limit", name, "max", strconv.Itoa(max)}
+	if path != "" {
+		params = append(params, "tag", path)
+	}
+	return newError(CodeLimitExceeded,
+		fmt.Errorf("%w: "+format+", limit %s is %d", append(append([]any{ErrLimitExceeded}, args...), name, max)...),
+		params...)
+}
@@ -0,0 +1,84 @@
+package emvqr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDecode_Limits(t *testing.T) {
+	manyRFU := basePayload()
+	for i := range 70 {
+		manyRFU.RFUFields = append(manyRFU.RFUFields, DataObject{ID: strconv.Itoa(65 + i%15), Value: "X"})
+	}
+	manySubs := basePayload()
+	ut := UnreservedTemplate{ID: "80", GloballyUniqueID: "A0"}
+	for i := range 20 {
+		// Empty values keep the template within 99 chars.
+		ut.SubFields = append(ut.SubFields, DataObject{ID: strconv.Itoa(10 + i)})
+	}
+	manySubs.UnreservedTemplates = []UnreservedTemplate{ut}
+	pst := basePayload()
+	pst.AdditionalData = &AdditionalDataField{PaymentSystemTemplates: []UnreservedTemplate{
+		{ID: "50", GloballyUniqueID: "A0", SubFields: []DataObject{{ID: "01", Value: "X"}}},
+	}}
+
+	tests := []struct {
+		name   string
+		p      *Payload
+		limits Limits
+		limit  string // name of the limit exceeded; "" for none
+		tag    string
+	}{
+		{"defaults", basePayload(), Limits{}, "", ""},
+		{"payload length", basePayload(), Limits{MaxPayloadLength: 50}, "MaxPayloadLength", ""},
+		{"objects", manyRFU, Limits{}, "MaxObjects", ""},
+		{"objects unlimited", manyRFU, Limits{MaxObjects: -1}, "", ""},
+		{"sub-fields", manySubs, Limits{MaxSubFields: 20}, "MaxSubFields", "80"},
+		{"sub-fields default", manySubs, Limits{}, "", ""},
+		{"depth", pst, Limits{MaxDepth: 2}, "MaxDepth", "62.50"},
+		{"depth default", pst, Limits{}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := EncodeWithOptions(tt.p, EncodeOptions{SpecVersion: SpecVersion11})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = DecodeWithOptions(raw, DecodeOptions{SpecVersion: SpecVersion11, Limits: tt.limits})
+			if tt.limit == "" {
+				if err != nil {
+					t.Fatalf("Decode() error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("Decode() error = %v, want ErrLimitExceeded", err)
+			}
+			assertEqual(t, "code", string(CodeLimitExceeded), string(ErrorCode(err)))
+			params := ErrorParams(err)
+			assertEqual(t, "limit", tt.limit, params["limit"])
+			assertEqual(t, "tag", tt.tag, params["tag"])
+		})
+	}
+}
+
+func TestDecode_DefaultPayloadLength(t *testing.T) {
+	p := basePayload()
+	for i := range 6 {
+		p.UnreservedTemplates = append(p.UnreservedTemplates, UnreservedTemplate{
+			ID: strconv.Itoa(80 + i), GloballyUniqueID: strings.Repeat("A", 80),
+		})
+	}
+	raw := mustEncode(t, p)
+	if len(raw) <= DefaultMaxPayloadLength {
+		t.Fatalf("test payload is only %d chars", len(raw))
+	}
+	if _, err := Decode(raw); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Decode() error = %v, want ErrLimitExceeded", err)
+	}
+	if _, err := DecodeWithOptions(raw, DecodeOptions{Limits: Limits{MaxPayloadLength: -1}}); err != nil {
+		t.Errorf("Decode() with no length limit: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Warning is a lint finding: a condition that is legal, so Decode and
+// Encode accept it, but suspicious enough for a QA pipeline to review.
+type Warning struct {
+	Check   string // name of the lint check, e.g. "static-amount"
+	Path    string // dotted tag path of the field concerned, e.g. "54"
+	Message string
+}
+
+func (w Warning) String() string { return w.Message }
+
+// Lint checks names, as reported in Warning.Check.
+const (
+	LintEmptySubField     = "empty-sub-field"
+	LintStaticAmount      = "static-amount"
+	LintDynamicNoAmount   = "dynamic-without-amount"
+	LintMissingPostalCode = "missing-postal-code"
+	LintPostalCodeFormat  = "postal-code-format"
+	LintLongReferenceURL  = "long-reference-url"
+	LintReferenceMismatch = "reference-mismatch"
+	LintLanguageTemplate  = "language-template"
+	LintCommonCharset     = "common-charset"
+)
+
+// maxTypicalReferenceURL is the Bharat QR limit for Tag 27.02.
+const maxTypicalReferenceURL = 26
+
+// Lint returns warnings for conditions that are legal but suspicious:
+//
+//   - a static QR (Tag 01 "11") carrying an amount, which every scan reuses,
+//     or a dynamic one (Tag 01 "12") without one;
+//   - an Indian merchant without a postal code, which Bharat QR requires,
+//     or a postal code not matching the format of the country;
+//   - a Tag 27 reference URL longer than the 26 characters of Bharat QR;
+//   - a Tag 27.01 reference differing from the Tag 62.05 reference label;
+//   - Language Template problems found by LanguageTemplate.Check;
+//   - characters outside the EMV Common Character Set, such as emoji,
+//     control characters or accented letters, in any field but the
+//     Language Template's merchant name and city.
+//
+// Errors, such as missing mandatory fields, are left to Encode.
+func Lint(p *Payload) []Warning {
+	var warnings []Warning
+	warn := func(check, path, format string, args ...any) {
+		warnings = append(warnings, Warning{check, path, fmt.Sprintf(format, args...)})
+	}
+	switch {
+	case p.PointOfInitiationMethod == "11" && p.TransactionAmount != "":
+		warn(LintStaticAmount, IDTransactionAmount,
+			"Tag 54 amount %s is set on a static QR (Tag 01 is 11); every payer will be asked for it", p.TransactionAmount)
+	case p.PointOfInitiationMethod == "12" && p.TransactionAmount == "":
+		warn(LintDynamicNoAmount, IDTransactionAmount, "dynamic QR (Tag 01 is 12) has no Tag 54 amount")
+	}
+	if p.CountryCode == "IN" && p.PostalCode == "" {
+		warn(LintMissingPostalCode, IDPostalCode, "Tag 61 postal code is missing for an Indian merchant")
+	}
+	if p.ValidatePostalCode() != nil {
+		warn(LintPostalCodeFormat, IDPostalCode, "Tag 61 postal code %q does not match the format for %s (%s)",
+			p.PostalCode, p.CountryCode, strings.Join(postalFormats[p.CountryCode], " or "))
+	}
+	if r := p.UPITransactionRef; r != nil && len(r.ReferenceURL) > maxTypicalReferenceURL {
+		warn(LintLongReferenceURL, IDUPIVPAReference+"."+UPIVPARefURL,
+			"Tag 27.02 reference URL is %d characters, longer than the usual %d", len(r.ReferenceURL), maxTypicalReferenceURL)
+	}
+	if !p.ReferencesConsistent() {
+		warn(LintReferenceMismatch, IDUPIVPAReference+"."+UPIVPARefTransactionRef,
+			"Tag 27.01 reference %q differs from Tag 62.05 reference label %q",
+			p.UPITransactionRef.TransactionRef, p.AdditionalData.ReferenceLabel)
+	}
+	if lt := p.LanguageTemplate; lt != nil {
+		for _, w := range lt.Check() {
+			warn(LintLanguageTemplate, IDMerchantInfoLanguageTemplate, "%s", w)
+		}
+	}
+	eachCharsetProblem(p.tlvNodes(), "", func(path, _, problem string) {
+		warn(LintCommonCharset, path, "Tag %s contains %s, outside the Common Character Set", path, problem)
+	})
+	return warnings
+}
+
+// LintRaw decodes raw, without CRC validation, and returns the warnings of
+// Lint together with those only visible in the raw string: template
+// sub-fields with a zero length, which Decode keeps only outside Tags 62
+// and 64.
+func LintRaw(raw string) ([]Warning, error) {
+	p, err := DecodeWithOptions(raw, DecodeOptions{SkipCRCValidation: true})
+	if err != nil {
+		return nil, err
+	}
+	var warnings []Warning
+	objects, err := parseTLV(raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		if !isTemplateID(obj.id) {
+			continue
+		}
+		subs, err := parseTLV(obj.value)
+		if err != nil {
+			continue
+		}
+		for _, s := range subs {
+			if s.value == "" {
+				path := obj.id + "." + s.id
+				msg := "Tag %s is empty, which EMV QRCPS does not allow"
+				if obj.id == IDAdditionalDataFieldTemplate || obj.id == IDMerchantInfoLanguageTemplate {
+					msg = "Tag %s is empty and is dropped when decoding"
+				}
+				warnings = append(warnings, Warning{LintEmptySubField, path, fmt.Sprintf(msg, path)})
+			}
+		}
+	}
+	return append(warnings, Lint(p)...), nil
+}
@@ -0,0 +1,49 @@
+package emvqr
+
+import (
+	"strings"
+	"testing"
+)
+
+func lintChecks(warnings []Warning) string {
+	var checks []string
+	for _, w := range warnings {
+		checks = append(checks, w.Check+"@"+w.Path)
+	}
+	return strings.Join(checks, " ")
+}
+
+func TestLint(t *testing.T) {
+	p := basePayload()
+	if w := Lint(p); len(w) != 0 {
+		t.Errorf("clean payload: %v", w)
+	}
+
+	p.PointOfInitiationMethod = "11"
+	p.TransactionAmount = "10.00"
+	p.CountryCode = "IN"
+	p.SetLanguageTemplate("hi", "ABC Hammers", "")
+	p.UPITransactionRef = &UPIVPAReference{TransactionRef: "ORDER-1"}
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.ReferenceLabel = "ORDER-2" })
+	want := "static-amount@54 missing-postal-code@61 reference-mismatch@27.01 language-template@64"
+	assertEqual(t, "checks", want, lintChecks(Lint(p)))
+
+	p.PointOfInitiationMethod = "12"
+	p.TransactionAmount = ""
+	if got := lintChecks(Lint(p)); !strings.HasPrefix(got, "dynamic-without-amount@54") {
+		t.Errorf("checks = %s", got)
+	}
+}
+
+func TestLintRaw(t *testing.T) {
+	warnings, err := LintRaw(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "checks", "empty-sub-field@28.01 long-reference-url@27.02", lintChecks(warnings))
+	assertEqual(t, "message", "Tag 28.01 is empty, which EMV QRCPS does not allow", warnings[0].String())
+
+	if _, err := LintRaw("0002"); err == nil {
+		t.Error("malformed input accepted")
+	}
+}
@@ -0,0 +1,121 @@
+package emvqr
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// LogValue implements slog.LogValuer, so that logging a payload emits a
+// group of structured attributes rather than the Go struct:
+//
+//	merchant=ABC Hammers city=New York country=US mcc=5251 currency=840
+//	amount=10.00 poi=12 networks=[Visa UPI] accounts=[02:400012******9012 26:sh***@upi]
+//
+// Personal data is masked: card numbers keep their first six and last four
+// digits and VPAs the first two characters of their user part; mobile
+// numbers, Aadhaar numbers, customer labels and the like are left out.
+func (p *Payload) LogValue() slog.Value {
+	if p == nil {
+		return slog.StringValue("<nil>")
+	}
+	attrs := make([]slog.Attr, 0, 10)
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, slog.String(key, value))
+		}
+	}
+	add("merchant", p.MerchantName)
+	add("city", p.MerchantCity)
+	add("country", p.CountryCode)
+	add("mcc", p.MerchantCategoryCode)
+	add("currency", p.TransactionCurrency)
+	add("amount", p.TransactionAmount)
+	add("poi", string(p.PointOfInitiationMethod))
+
+	var networks, accounts []string
+	addNetwork := func(name string) {
+		for _, n := range networks {
+			if n == name {
+				return
+			}
+		}
+		networks = append(networks, name)
+	}
+	for _, mi := range p.MerchantIdentifiers {
+		if name, ok := NetworkName(mi.ID); ok {
+			addNetwork(name)
+			accounts = append(accounts, mi.ID+":"+maskAccount(mi.Value))
+		}
+	}
+	if v := p.UPIVPAInfo; v != nil {
+		addNetwork("UPI")
+		if v.VPA != "" {
+			accounts = append(accounts, IDUPIVPATemplate+":"+maskVPA(v.VPA))
+		}
+	}
+	if len(networks) > 0 {
+		attrs = append(attrs, slog.Any("networks", networks))
+	}
+	if len(accounts) > 0 {
+		attrs = append(attrs, slog.Any("accounts", accounts))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer, logging the code, message and
+// parameters of the error.
+func (e *Error) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("code", string(e.Code)), slog.String("msg", e.Error())}
+	for _, k := range sortedKeys(e.Params) {
+		attrs = append(attrs, slog.String(k, e.Params[k]))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer, logging the tag and message of the
+// error.
+func (e *ParseError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("code", string(ErrorCode(e))),
+		slog.String("tag", e.ID),
+		slog.String("msg", e.Error()),
+	)
+}
+
+// LogValue implements slog.LogValuer, logging both CRCs and the diagnosis.
+func (e *CRCError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("code", string(CodeCRCMismatch)),
+		slog.String("got", e.Got),
+		slog.String("want", e.Want),
+		slog.Bool("truncated", e.Truncated),
+		slog.Bool("parseable", e.Parseable),
+	)
+}
+
+// maskAccount masks an account number, keeping the first six and last four
+// characters of values long enough to be card numbers and the last four of
+// others.
+func maskAccount(s string) string {
+	switch n := len(s); {
+	case n >= 13:
+		return s[:6] + strings.Repeat("*", n-10) + s[n-4:]
+	case n > 4:
+		return strings.Repeat("*", n-4) + s[n-4:]
+	default:
+		return strings.Repeat("*", n)
+	}
+}
+
+// maskVPA masks the user part of a UPI VPA, keeping its first two characters
+// and the handle: "shop@upi" becomes "sh***@upi".
+func maskVPA(vpa string) string {
+	user, handle, ok := strings.Cut(vpa, "@")
+	if !ok {
+		return maskAccount(vpa)
+	}
+	if len(user) > 2 {
+		user = user[:2]
+	}
+	return user + "***@" + handle
+}
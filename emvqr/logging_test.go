@@ -0,0 +1,92 @@
+package emvqr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// logJSON logs v under key "v" with a JSON handler and returns the decoded
+// attribute.
+func logJSON(t *testing.T, v any) any {
+	t.Helper()
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("test", "v", v)
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("log output %q: %v", buf.String(), err)
+	}
+	return rec["v"]
+}
+
+func TestPayload_LogValue(t *testing.T) {
+	p := basePayload()
+	p.TransactionAmount = "10.00"
+	p.UPIVPAInfo = &UPIVPATemplate{RuPayRID: RuPayRIDValue, VPA: "shopkeeper@okbank"}
+	p.AdditionalData = &AdditionalDataField{MobileNumber: "9876543210"}
+
+	want := map[string]any{
+		"merchant": "ABC Hammers",
+		"city":     "New York",
+		"country":  "US",
+		"mcc":      "5251",
+		"currency": "840",
+		"amount":   "10.00",
+		"networks": []any{"Visa", "UPI"},
+		"accounts": []any{"02:400012******9012", "26:sh***@okbank"},
+	}
+	got := logJSON(t, p)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LogValue mismatch (-want +got):\n%s", diff)
+	}
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("test", "qr", p)
+	for _, secret := range []string{"4000123456789012", "shopkeeper", "9876543210"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("log %q contains %q", buf.String(), secret)
+		}
+	}
+
+	if got := logJSON(t, (*Payload)(nil)); got != "<nil>" {
+		t.Errorf("nil payload logged as %v", got)
+	}
+}
+
+func TestError_LogValue(t *testing.T) {
+	raw := mustEncode(t, basePayload())
+	_, err := Decode(raw[:len(raw)-4] + "0000")
+	got, ok := logJSON(t, err).(map[string]any)
+	if !ok || got["code"] != string(CodeCRCMismatch) || got["tag"] != IDCRC || got["got"] != "0000" {
+		t.Errorf("CRC mismatch logged as %v", got)
+	}
+
+	var crcErr *CRCError
+	if !errors.As(err, &crcErr) {
+		t.Fatalf("error %v has no *CRCError", err)
+	}
+	got, _ = logJSON(t, crcErr).(map[string]any)
+	if got["want"] != crcErr.Want || got["truncated"] != false {
+		t.Errorf("*CRCError logged as %v", got)
+	}
+
+	got, _ = logJSON(t, &ParseError{ID: "59", Err: ErrInvalidLength}).(map[string]any)
+	if got["tag"] != "59" || got["code"] != string(CodeInvalidLength) {
+		t.Errorf("*ParseError logged as %v", got)
+	}
+}
+
+func TestMaskAccount(t *testing.T) {
+	for in, want := range map[string]string{
+		"4000123456789012": "400012******9012",
+		"12345678":         "****5678",
+		"123":              "***",
+	} {
+		assertEqual(t, in, want, maskAccount(in))
+	}
+	assertEqual(t, "short VPA", "a***@upi", maskVPA("a@upi"))
+}
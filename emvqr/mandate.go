@@ -0,0 +1,161 @@
+package emvqr
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+)
+
+// MandateGUID is the Globally Unique Identifier of the Unreserved Template
+// in which SetMandate stores recurring-payment mandate hints.
+const MandateGUID = "COM.GITHUB.EMVQR.MANDATE"
+
+// Sub-field IDs of the mandate template.
+const (
+	MandateFrequency  = "01" // one of the Frequency constants
+	MandateValidFrom  = "02" // YYYYMMDD
+	MandateValidTo    = "03" // YYYYMMDD, absent if open-ended
+	MandateAmountCap  = "04" // decimal amount in the Tag 53 currency
+	MandateAmountRule = "05" // MandateAmountMax or MandateAmountExact
+)
+
+// Mandate frequencies, as UPI Autopay names them.
+const (
+	FrequencyOneTime     = "ONETIME"
+	FrequencyDaily       = "DAILY"
+	FrequencyWeekly      = "WEEKLY"
+	FrequencyFortnightly = "FORTNIGHTLY"
+	FrequencyMonthly     = "MONTHLY"
+	FrequencyBimonthly   = "BIMONTHLY"
+	FrequencyQuarterly   = "QUARTERLY"
+	FrequencyHalfYearly  = "HALFYEARLY"
+	FrequencyYearly      = "YEARLY"
+	FrequencyAsPresented = "ASPRESENTED"
+)
+
+// frequencies are the frequencies ValidateMandate accepts.
+var frequencies = []string{
+	FrequencyOneTime, FrequencyDaily, FrequencyWeekly, FrequencyFortnightly, FrequencyMonthly,
+	FrequencyBimonthly, FrequencyQuarterly, FrequencyHalfYearly, FrequencyYearly, FrequencyAsPresented,
+}
+
+// Mandate amount rules: each debit is at most, or exactly, the cap.
+const (
+	MandateAmountMax   = "MAX"
+	MandateAmountExact = "EXACT"
+)
+
+// mandateDate is the layout of the mandate validity dates.
+const mandateDate = "20060102"
+
+// Mandate holds the hints a compliant app needs to set up a recurring
+// payment mandate, such as UPI Autopay, when the QR is scanned. Only the
+// dates of ValidFrom and ValidTo are kept.
+type Mandate struct {
+	Frequency  string    // e.g. FrequencyMonthly
+	ValidFrom  time.Time // first day a debit may be made
+	ValidTo    time.Time // last day a debit may be made; zero if open-ended
+	AmountCap  string    // largest debit, e.g. "499.00"
+	AmountRule string    // MandateAmountMax (the default) or MandateAmountExact
+}
+
+// ValidateMandate checks that m has a known frequency, a start date, an
+// end date, if any, no earlier than the start, a positive decimal amount
+// cap of at most 13 characters and a known amount rule. It returns an
+// error wrapping ErrInvalidFormat.
+func ValidateMandate(m Mandate) error {
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("%w: mandate "+format, append([]any{ErrInvalidFormat}, args...)...)
+	}
+	switch {
+	case !slices.Contains(frequencies, m.Frequency):
+		return fail("frequency %q is not one of %v", m.Frequency, frequencies)
+	case m.ValidFrom.IsZero():
+		return fail("needs a start date")
+	case !m.ValidTo.IsZero() && m.ValidTo.Format(mandateDate) < m.ValidFrom.Format(mandateDate):
+		return fail("ends on %s, before it starts on %s", m.ValidTo.Format(time.DateOnly), m.ValidFrom.Format(time.DateOnly))
+	case !isStrictAmount(m.AmountCap) || len(m.AmountCap) > 13:
+		return fail("amount cap %q must be a decimal amount of at most 13 characters, such as \"499.00\"", m.AmountCap)
+	case m.AmountRule != "" && m.AmountRule != MandateAmountMax && m.AmountRule != MandateAmountExact:
+		return fail("amount rule %q is neither %s nor %s", m.AmountRule, MandateAmountMax, MandateAmountExact)
+	}
+	if r, _ := new(big.Rat).SetString(m.AmountCap); r.Sign() <= 0 {
+		return fail("amount cap %q must be above 0", m.AmountCap)
+	}
+	return nil
+}
+
+// SetMandate stores the mandate hints m in an Unreserved Template with
+// MandateGUID, replacing any earlier ones; nil removes them. Apps that do
+// not know the template treat the QR as an ordinary payment. m must pass
+// ValidateMandate and, if the payload has a Transaction Amount (Tag 54)
+// for the first debit, that amount must not exceed the cap, or must equal
+// it under MandateAmountExact. Errors wrap ErrInvalidFormat and leave the
+// payload unchanged.
+func (p *Payload) SetMandate(m *Mandate) error {
+	if m == nil {
+		return p.setExtension(MandateGUID, nil)
+	}
+	if err := ValidateMandate(*m); err != nil {
+		return err
+	}
+	if p.TransactionAmount != "" {
+		amount, err := parseAmount(IDTransactionAmount, p.TransactionAmount)
+		if err != nil {
+			return err
+		}
+		limit, _ := new(big.Rat).SetString(m.AmountCap)
+		if c := amount.Cmp(limit); c > 0 || c != 0 && m.AmountRule == MandateAmountExact {
+			return newError(CodeInvalidFormat,
+				fmt.Errorf("%w: Tag 54 amount %s does not fit the mandate amount cap %s (%s)",
+					ErrInvalidFormat, p.TransactionAmount, m.AmountCap, m.amountRule()),
+				"tag", IDTransactionAmount, "value", p.TransactionAmount)
+		}
+	}
+	subs := []DataObject{
+		{ID: MandateFrequency, Value: m.Frequency},
+		{ID: MandateValidFrom, Value: m.ValidFrom.Format(mandateDate)},
+	}
+	if !m.ValidTo.IsZero() {
+		subs = append(subs, DataObject{ID: MandateValidTo, Value: m.ValidTo.Format(mandateDate)})
+	}
+	subs = append(subs,
+		DataObject{ID: MandateAmountCap, Value: m.AmountCap},
+		DataObject{ID: MandateAmountRule, Value: m.amountRule()})
+	return p.setExtension(MandateGUID, subs)
+}
+
+// GetMandate returns the mandate hints stored by SetMandate, or nil if the
+// payload has none. Dates that cannot be parsed are left zero; callers
+// that act on the mandate should check it with ValidateMandate.
+func (p *Payload) GetMandate() *Mandate {
+	i := p.extensionIndex(MandateGUID)
+	if i < 0 {
+		return nil
+	}
+	m := &Mandate{}
+	for _, sf := range p.UnreservedTemplates[i].SubFields {
+		switch sf.ID {
+		case MandateFrequency:
+			m.Frequency = sf.Value
+		case MandateValidFrom:
+			m.ValidFrom, _ = time.Parse(mandateDate, sf.Value)
+		case MandateValidTo:
+			m.ValidTo, _ = time.Parse(mandateDate, sf.Value)
+		case MandateAmountCap:
+			m.AmountCap = sf.Value
+		case MandateAmountRule:
+			m.AmountRule = sf.Value
+		}
+	}
+	return m
+}
+
+// amountRule returns the amount rule of m, MandateAmountMax if unset.
+func (m *Mandate) amountRule() string {
+	if m.AmountRule == "" {
+		return MandateAmountMax
+	}
+	return m.AmountRule
+}
@@ -0,0 +1,92 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMandate(t *testing.T) {
+	p := basePayload()
+	p.TransactionAmount = "199.00"
+	m := &Mandate{
+		Frequency: FrequencyMonthly,
+		ValidFrom: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		ValidTo:   time.Date(2027, 10, 31, 0, 0, 0, 0, time.UTC),
+		AmountCap: "499.00",
+	}
+	if err := p.SetMandate(m); err != nil {
+		t.Fatalf("SetMandate() error: %v", err)
+	}
+
+	raw := mustEncode(t, p)
+	want := "0024" + MandateGUID + "0107MONTHLY" + "020820261101" + "030820271031" + "0406499.00" + "0503MAX"
+	if !strings.Contains(raw, want) {
+		t.Errorf("Encode() = %q, want it to contain %q", raw, want)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	got := decoded.GetMandate()
+	if got == nil {
+		t.Fatal("GetMandate() = nil")
+	}
+	assertEqual(t, "Frequency", FrequencyMonthly, got.Frequency)
+	assertEqual(t, "ValidFrom", "2026-11-01", got.ValidFrom.Format(time.DateOnly))
+	assertEqual(t, "ValidTo", "2027-10-31", got.ValidTo.Format(time.DateOnly))
+	assertEqual(t, "AmountCap", "499.00", got.AmountCap)
+	assertEqual(t, "AmountRule", MandateAmountMax, got.AmountRule)
+	if err := ValidateMandate(*got); err != nil {
+		t.Errorf("ValidateMandate(decoded) error: %v", err)
+	}
+
+	if err := decoded.SetMandate(nil); err != nil || decoded.GetMandate() != nil {
+		t.Errorf("SetMandate(nil): error %v, mandate %+v", err, decoded.GetMandate())
+	}
+}
+
+func TestMandate_OpenEnded(t *testing.T) {
+	p := basePayload()
+	m := &Mandate{Frequency: FrequencyAsPresented, ValidFrom: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), AmountCap: "15000"}
+	if err := p.SetMandate(m); err != nil {
+		t.Fatalf("SetMandate() error: %v", err)
+	}
+	if got := p.GetMandate(); got == nil || !got.ValidTo.IsZero() {
+		t.Errorf("GetMandate() = %+v, want no end date", got)
+	}
+}
+
+func TestSetMandate_Errors(t *testing.T) {
+	from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	valid := Mandate{Frequency: FrequencyMonthly, ValidFrom: from, AmountCap: "499"}
+	tests := []struct {
+		name   string
+		amount string
+		edit   func(*Mandate)
+	}{
+		{"unknown frequency", "", func(m *Mandate) { m.Frequency = "HOURLY" }},
+		{"no start", "", func(m *Mandate) { m.ValidFrom = time.Time{} }},
+		{"ends before start", "", func(m *Mandate) { m.ValidTo = from.AddDate(0, 0, -1) }},
+		{"bad cap", "", func(m *Mandate) { m.AmountCap = "499,00" }},
+		{"zero cap", "", func(m *Mandate) { m.AmountCap = "0.00" }},
+		{"unknown rule", "", func(m *Mandate) { m.AmountRule = "MIN" }},
+		{"amount over cap", "500", func(*Mandate) {}},
+		{"amount not exact", "100", func(m *Mandate) { m.AmountRule = MandateAmountExact }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := basePayload()
+			p.TransactionAmount = tt.amount
+			m := valid
+			tt.edit(&m)
+			if err := p.SetMandate(&m); !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("SetMandate() error = %v, want ErrInvalidFormat", err)
+			}
+			if p.GetMandate() != nil {
+				t.Error("failed SetMandate() stored a mandate")
+			}
+		})
+	}
+}
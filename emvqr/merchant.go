@@ -0,0 +1,230 @@
+package emvqr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IsTemplate reports whether mi is a template (IDs "26"–"51") rather than a
+// primitive (IDs "02"–"25").
+func (mi MerchantIdentifier) IsTemplate() bool {
+	n, err := strconv.Atoi(mi.ID)
+	return err == nil && n >= 26 && n <= 51
+}
+
+// SubField returns the value of the template sub-field with the given ID, or
+// "" if mi is a primitive or has no such sub-field. It reads SubFields, or
+// parses Value when SubFields is empty, so it works for entries built by hand
+// as well as decoded ones.
+func (mi MerchantIdentifier) SubField(id string) string {
+	if !mi.IsTemplate() {
+		return ""
+	}
+	for _, sf := range mi.SubFields {
+		if sf.ID == id {
+			return sf.Value
+		}
+	}
+	if len(mi.SubFields) == 0 {
+		subs, _ := parseTLV(mi.Value)
+		for _, s := range subs {
+			if s.id == id {
+				return s.value
+			}
+		}
+	}
+	return ""
+}
+
+// GloballyUniqueID returns the Globally Unique Identifier (sub-field "00") of
+// a template, e.g. the RuPay RID of Tag 26 or a reverse domain name, or "".
+func (mi MerchantIdentifier) GloballyUniqueID() string {
+	return mi.SubField(MAIGloballyUniqueID)
+}
+
+// RemoveMerchantIdentifier removes the merchant identifier with the given tag
+// ID ("02"–"51"). Removing Tag 26, 27 or 28 also clears UPIVPAInfo,
+// UPITransactionRef or MerchantAadhaar, from which those tags are encoded.
+// It returns an error wrapping ErrTagNotFound if the payload has no such
+// identifier.
+//
+// Removing the last identifier is allowed, e.g. before adding a new one, but
+// Encode rejects a payload without any.
+func (p *Payload) RemoveMerchantIdentifier(tagID string) error {
+	if err := checkMerchantIdentifierID(tagID); err != nil {
+		return err
+	}
+	kept := p.MerchantIdentifiers[:0:0]
+	for _, mi := range p.MerchantIdentifiers {
+		if mi.ID != tagID {
+			kept = append(kept, mi)
+		}
+	}
+	found := len(kept) < len(p.MerchantIdentifiers)
+	switch tagID {
+	case IDUPIVPATemplate:
+		found = found || p.UPIVPAInfo != nil
+		p.UPIVPAInfo = nil
+	case IDUPIVPAReference:
+		found = found || p.UPITransactionRef != nil
+		p.UPITransactionRef = nil
+	case IDAadhaarTemplate:
+		found = found || p.MerchantAadhaar != nil
+		p.MerchantAadhaar = nil
+	}
+	if !found {
+		return newError(CodeTagNotFound,
+			fmt.Errorf("%w: merchant identifier tag ID %s", ErrTagNotFound, tagID), "tag", tagID)
+	}
+	p.MerchantIdentifiers = kept
+	return nil
+}
+
+// ReplaceMerchantIdentifier replaces the merchant identifier with tag ID
+// oldID by mi, keeping its position, e.g. to swap the acquiring bank's
+// Tag 08 when a merchant migrates. mi.ID may differ from oldID, but must not
+// already be used by another identifier.
+//
+// mi is validated as if it were added anew: primitives ("02"–"25") need a
+// Value and no SubFields; templates ("26"–"51") take their sub-fields from
+// SubFields, or from Value when SubFields is empty, and must encode within
+// 99 bytes. For Tags 26, 27 and 28 the typed fields (UPIVPAInfo,
+// UPITransactionRef, MerchantAadhaar) are updated to match. On error the
+// payload is unchanged.
+func (p *Payload) ReplaceMerchantIdentifier(oldID string, mi MerchantIdentifier) error {
+	if err := checkMerchantIdentifierID(oldID); err != nil {
+		return err
+	}
+	mi, err := normalizeMerchantIdentifier(mi)
+	if err != nil {
+		return err
+	}
+	idx := -1
+	for i, existing := range p.MerchantIdentifiers {
+		switch existing.ID {
+		case oldID:
+			if idx < 0 {
+				idx = i
+			}
+		case mi.ID:
+			return newError(CodeInvalidFormat,
+				fmt.Errorf("%w: merchant identifier tag ID %s already exists", ErrDuplicateTag, mi.ID), "tag", mi.ID)
+		}
+	}
+	if mi.ID != oldID && p.hasTypedMerchantIdentifier(mi.ID) {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: merchant identifier tag ID %s already exists", ErrDuplicateTag, mi.ID), "tag", mi.ID)
+	}
+	if idx < 0 && !p.hasTypedMerchantIdentifier(oldID) {
+		return newError(CodeTagNotFound,
+			fmt.Errorf("%w: merchant identifier tag ID %s", ErrTagNotFound, oldID), "tag", oldID)
+	}
+
+	value := mi.Value
+	switch mi.ID {
+	case IDUPIVPATemplate, IDUPIVPAReference, IDAadhaarTemplate:
+		mi.Value = ""
+	}
+	// Drop every entry with oldID, keeping the position of the first.
+	var edited []MerchantIdentifier
+	for i, existing := range p.MerchantIdentifiers {
+		if i == idx {
+			edited = append(edited, mi)
+		} else if existing.ID != oldID {
+			edited = append(edited, existing)
+		}
+	}
+	if idx < 0 {
+		edited = append(edited, mi)
+	}
+	if oldID != mi.ID {
+		p.setTypedMerchantIdentifier(oldID, "")
+	}
+	p.setTypedMerchantIdentifier(mi.ID, value)
+	p.MerchantIdentifiers = edited
+	return nil
+}
+
+// checkMerchantIdentifierID checks that id is a Merchant Account Information
+// tag ID ("02"–"51").
+func checkMerchantIdentifierID(id string) error {
+	if n, err := strconv.Atoi(id); err != nil || len(id) != 2 || n < 2 || n > 51 {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: merchant identifier tag ID must be 02–51, got %q", ErrInvalidTagID, id), "tag", id)
+	}
+	return nil
+}
+
+// normalizeMerchantIdentifier validates mi and fills in whichever of Value
+// and SubFields a template entry lacks, so that it matches what Decode
+// produces.
+func normalizeMerchantIdentifier(mi MerchantIdentifier) (MerchantIdentifier, error) {
+	if err := checkMerchantIdentifierID(mi.ID); err != nil {
+		return mi, err
+	}
+	if !isTemplateID(mi.ID) {
+		if mi.Value == "" || len(mi.SubFields) > 0 {
+			return mi, fmt.Errorf("%w: merchant identifier %s is a primitive and needs a value", ErrInvalidFormat, mi.ID)
+		}
+		_, err := encodeTLV(mi.ID, mi.Value)
+		return mi, err
+	}
+	if len(mi.SubFields) > 0 {
+		var sb strings.Builder
+		for _, sf := range mi.SubFields {
+			chunk, err := encodeTLV(sf.ID, sf.Value)
+			if err != nil {
+				return mi, err
+			}
+			sb.WriteString(chunk)
+		}
+		mi.Value = sb.String()
+	}
+	subs, err := parseTLV(mi.Value)
+	if err != nil || len(subs) == 0 {
+		return mi, fmt.Errorf("%w: merchant identifier %s is a template and needs sub-fields", ErrInvalidFormat, mi.ID)
+	}
+	if _, err := encodeTLV(mi.ID, mi.Value); err != nil {
+		return mi, err
+	}
+	mi.SubFields = convertTLVToDataObjects(subs, false)
+	return mi, nil
+}
+
+// hasTypedMerchantIdentifier reports whether the typed field for Tag 26, 27
+// or 28 is set.
+func (p *Payload) hasTypedMerchantIdentifier(id string) bool {
+	switch id {
+	case IDUPIVPATemplate:
+		return p.UPIVPAInfo != nil
+	case IDUPIVPAReference:
+		return p.UPITransactionRef != nil
+	case IDAadhaarTemplate:
+		return p.MerchantAadhaar != nil
+	}
+	return false
+}
+
+// setTypedMerchantIdentifier sets the typed field for Tag 26, 27 or 28 from
+// a template value, or clears it when value is empty. The MerchantIdentifiers
+// entry of those tags then holds only the SubFields, as after Decode.
+func (p *Payload) setTypedMerchantIdentifier(id, value string) {
+	switch id {
+	case IDUPIVPATemplate:
+		p.UPIVPAInfo = nil
+		if value != "" {
+			p.UPIVPAInfo, _ = decodeUPIVPATemplate(value)
+		}
+	case IDUPIVPAReference:
+		p.UPITransactionRef = nil
+		if value != "" {
+			p.UPITransactionRef, _ = decodeUPIVPAReference(value)
+		}
+	case IDAadhaarTemplate:
+		p.MerchantAadhaar = nil
+		if value != "" {
+			p.MerchantAadhaar, _ = decodeAadhaarInfo(value)
+		}
+	}
+}
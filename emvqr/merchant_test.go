@@ -0,0 +1,156 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReplaceMerchantIdentifier(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, mi := range p.MerchantIdentifiers {
+		ids = append(ids, mi.ID)
+	}
+
+	// Migrate the acquirer in Tag 08 to a new one.
+	if err := p.ReplaceMerchantIdentifier("08", MerchantIdentifier{ID: "08", Value: "HDFC000123456789"}); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, mi := range got.MerchantIdentifiers {
+		if mi.ID != ids[i] {
+			t.Errorf("identifier %d: want ID %s, got %s", i, ids[i], mi.ID)
+		}
+		if mi.ID == "08" {
+			assertEqual(t, "Tag 08", "HDFC000123456789", mi.Value)
+		}
+	}
+	assertEqual(t, "VPA", p.GetMerchantVPA(), got.GetMerchantVPA())
+
+	// Replacing Tag 26 updates the typed field it is encoded from.
+	vpa := MerchantIdentifier{ID: "26", SubFields: []DataObject{
+		{ID: "00", Value: RuPayRIDValue},
+		{ID: "01", Value: "shop@hdfcbank"},
+	}}
+	if err := p.ReplaceMerchantIdentifier("26", vpa); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "VPA", "shop@hdfcbank", p.GetMerchantVPA())
+	if got := p.GetMinimumAmount(); got != "" {
+		t.Errorf("minimum amount kept: %q", got)
+	}
+	if got := p.Flatten()["26.01"]; got != "shop@hdfcbank" {
+		t.Errorf("Flatten 26.01 = %q", got)
+	}
+}
+
+func TestReplaceMerchantIdentifierErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		oldID string
+		mi    MerchantIdentifier
+		want  string
+	}{
+		{"missing", "05", MerchantIdentifier{ID: "05", Value: "5100"}, "not found"},
+		{"old ID out of range", "52", MerchantIdentifier{ID: "04", Value: "5100"}, "02–51"},
+		{"new ID out of range", "02", MerchantIdentifier{ID: "01", Value: "5100"}, "02–51"},
+		{"duplicate", "02", MerchantIdentifier{ID: "04", Value: "5100"}, "already exists"},
+		{"primitive without value", "02", MerchantIdentifier{ID: "02"}, "needs a value"},
+		{"template without sub-fields", "02", MerchantIdentifier{ID: "30", Value: "xyz"}, "needs sub-fields"},
+		{"too long", "02", MerchantIdentifier{ID: "02", Value: strings.Repeat("9", 100)}, "exceeds maximum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := basePayload()
+			p.MerchantIdentifiers = append(p.MerchantIdentifiers, MerchantIdentifier{ID: "04", Value: "5555"})
+			before := len(p.MerchantIdentifiers)
+			err := p.ReplaceMerchantIdentifier(tt.oldID, tt.mi)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("want error containing %q, got %v", tt.want, err)
+			}
+			if len(p.MerchantIdentifiers) != before || p.MerchantIdentifiers[0].Value != "4000123456789012" {
+				t.Errorf("payload changed: %+v", p.MerchantIdentifiers)
+			}
+		})
+	}
+
+	p := basePayload()
+	err := p.ReplaceMerchantIdentifier("02", MerchantIdentifier{ID: "30", Value: "xyz"})
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("want ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestRemoveMerchantIdentifier(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"27", "28", "04"} {
+		if err := p.RemoveMerchantIdentifier(id); err != nil {
+			t.Fatalf("remove %s: %v", id, err)
+		}
+	}
+	if p.UPITransactionRef != nil || p.MerchantAadhaar != nil {
+		t.Error("typed fields for Tags 27 and 28 not cleared")
+	}
+	raw, err := Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mi := range got.MerchantIdentifiers {
+		switch mi.ID {
+		case "04", "27", "28":
+			t.Errorf("Tag %s still encoded", mi.ID)
+		}
+	}
+
+	if err := p.RemoveMerchantIdentifier("04"); err == nil {
+		t.Error("removing an absent identifier succeeded")
+	}
+	if err := p.RemoveMerchantIdentifier("62"); err == nil {
+		t.Error("removing a non-MAI tag succeeded")
+	}
+}
+
+func TestMerchantIdentifierAccessors(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mi := range p.MerchantIdentifiers {
+		switch mi.ID {
+		case "04":
+			if mi.IsTemplate() || mi.GloballyUniqueID() != "" {
+				t.Errorf("Tag 04 treated as a template")
+			}
+		case "26":
+			if !mi.IsTemplate() {
+				t.Error("Tag 26 not a template")
+			}
+			assertEqual(t, "26.00", RuPayRIDValue, mi.GloballyUniqueID())
+			assertEqual(t, "26.01", p.GetMerchantVPA(), mi.SubField("01"))
+			assertEqual(t, "26.99", "", mi.SubField("99"))
+		}
+	}
+
+	// Hand-built template carrying only its encoded value.
+	mi := MerchantIdentifier{ID: "30", Value: "0011com.example0104ABCD"}
+	assertEqual(t, "30.00", "com.example", mi.GloballyUniqueID())
+	assertEqual(t, "30.01", "ABCD", mi.SubField("01"))
+}
@@ -0,0 +1,137 @@
+package emvqr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MergeConflict selects what Merge does when base and overlay both set a
+// field to different values.
+type MergeConflict int
+
+const (
+	// MergeOverlayWins takes the overlay value. This is the default: the
+	// overlay holds the store-specific fields.
+	MergeOverlayWins MergeConflict = iota
+	// MergeKeepBase keeps the base value, so the overlay can only fill in
+	// fields the base leaves empty.
+	MergeKeepBase
+	// MergeRejectConflict fails with ErrMergeConflict.
+	MergeRejectConflict
+)
+
+// MergeOptions controls MergeWithOptions.
+type MergeOptions struct {
+	// Conflict is the rule for fields set in both payloads.
+	Conflict MergeConflict
+
+	// SpecVersion is used to interpret the merged fields; see
+	// FromFlatWithOptions.
+	SpecVersion SpecVersion
+}
+
+// Merge layers overlay over base with MergeOverlayWins and returns a new
+// Payload; neither argument is modified. It lets a franchise chain keep one
+// base payload (name, MCC, currency, acquirer account) and derive each
+// store's QR from a small overlay (store label, terminal label, merchant
+// ID).
+func Merge(base, overlay *Payload) (*Payload, error) {
+	return MergeWithOptions(base, overlay, MergeOptions{})
+}
+
+// MergeWithOptions layers overlay over base. Fields are merged by tag path,
+// as produced by Flatten, so an overlay that sets only "62.03" keeps the
+// base's other Additional Data fields. Empty overlay fields never clear base
+// fields. Two groups of fields are replaced as a whole when the overlay's
+// value wins:
+//
+//   - a template whose Globally Unique Identifier ("00") differs between the
+//     payloads, since sub-fields of different schemes must not be mixed;
+//   - the convenience fee fields 56 and 57, when Tag 55 is set by the
+//     overlay.
+//
+// The merged payload has no CRC; Encode computes it. A nil base or overlay
+// is an error wrapping ErrMissingRequired.
+func MergeWithOptions(base, overlay *Payload, opts MergeOptions) (*Payload, error) {
+	switch {
+	case base == nil:
+		return nil, fmt.Errorf("%w: nil base payload", ErrMissingRequired)
+	case overlay == nil:
+		return nil, fmt.Errorf("%w: nil overlay payload", ErrMissingRequired)
+	}
+	if opts.Conflict < MergeOverlayWins || opts.Conflict > MergeRejectConflict {
+		return nil, fmt.Errorf("emvqr: unknown merge conflict rule %d", opts.Conflict)
+	}
+	merged := base.Flatten()
+	over := overlay.Flatten()
+	delete(merged, IDCRC)
+	delete(over, IDCRC)
+
+	var conflicts []string
+	var skipped []string // templates whose base GUID was kept
+	for _, path := range sortedKeys(over) {
+		value := over[path]
+		if hasAnyPrefix(path, skipped) {
+			continue
+		}
+		old, ok := merged[path]
+		if ok && old == value {
+			continue
+		}
+		if ok && opts.Conflict != MergeOverlayWins {
+			if opts.Conflict == MergeRejectConflict {
+				conflicts = append(conflicts, path)
+			}
+			if strings.HasSuffix(path, "."+MAIGloballyUniqueID) {
+				skipped = append(skipped, strings.TrimSuffix(path, MAIGloballyUniqueID))
+			}
+			continue
+		}
+		if ok || path == IDTipOrConvenienceIndicator {
+			dropReplacedGroup(merged, path)
+		}
+		merged[path] = value
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMergeConflict, strings.Join(conflicts, ", "))
+	}
+	return FromFlatWithOptions(merged, DecodeOptions{SpecVersion: opts.SpecVersion})
+}
+
+// dropReplacedGroup removes the base fields that belong with path when the
+// overlay replaces path's value.
+func dropReplacedGroup(merged map[string]string, path string) {
+	switch {
+	case path == IDTipOrConvenienceIndicator:
+		delete(merged, IDValueConvenienceFeeFixed)
+		delete(merged, IDValueConvenienceFeePercent)
+	case strings.HasSuffix(path, "."+MAIGloballyUniqueID):
+		prefix := strings.TrimSuffix(path, MAIGloballyUniqueID)
+		for p := range merged {
+			if strings.HasPrefix(p, prefix) {
+				delete(merged, p)
+			}
+		}
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of m in ascending order, so a GUID ("xx.00")
+// is merged before the sub-fields it may replace.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func franchiseBase() *Payload {
+	p := basePayload()
+	p.SetAdditionalData(func(a *AdditionalDataField) {
+		a.LoyaltyNumber = PromptValue
+		a.StoreLabel = "HQ"
+	})
+	p.SetFixedConvenienceFee("1.00")
+	return p
+}
+
+func TestMerge_OverlayWins(t *testing.T) {
+	overlay := &Payload{MerchantCity: "Boston"}
+	overlay.SetAdditionalData(func(a *AdditionalDataField) {
+		a.StoreLabel = "Store 42"
+		a.TerminalLabel = "T1"
+	})
+	overlay.SetPercentageConvenienceFee("2.5")
+
+	base := franchiseBase()
+	got, err := Merge(base, overlay)
+	if err != nil {
+		t.Fatalf("Merge() error: %v", err)
+	}
+	if got.MerchantName != "ABC Hammers" || got.MerchantCity != "Boston" {
+		t.Errorf("Merge() name/city = %q/%q", got.MerchantName, got.MerchantCity)
+	}
+	a := got.AdditionalData
+	if a == nil || a.StoreLabel != "Store 42" || a.TerminalLabel != "T1" || a.LoyaltyNumber != PromptValue {
+		t.Errorf("Merge() AdditionalData = %+v", a)
+	}
+	if got.TipOrConvenienceIndicator != TipIndicatorPercentageFee || got.ValueConvenienceFeeFixed != "" ||
+		got.ValueConvenienceFeePercent != "2.5" {
+		t.Errorf("Merge() fee = %q/%q/%q", got.TipOrConvenienceIndicator, got.ValueConvenienceFeeFixed, got.ValueConvenienceFeePercent)
+	}
+	if _, err := Encode(got); err != nil {
+		t.Errorf("Encode(merged) error: %v", err)
+	}
+	if base.AdditionalData.StoreLabel != "HQ" {
+		t.Error("Merge() modified base")
+	}
+}
+
+func TestMerge_TemplateGUIDReplacesTemplate(t *testing.T) {
+	base := basePayload()
+	base.MerchantIdentifiers = append(base.MerchantIdentifiers, MerchantIdentifier{ID: "29", Value: "0007com.abc0102X10202Y1"})
+	overlay := &Payload{MerchantIdentifiers: []MerchantIdentifier{{ID: "29", Value: "0007com.xyz0102Z1"}}}
+
+	got, err := Merge(base, overlay)
+	if err != nil {
+		t.Fatalf("Merge() error: %v", err)
+	}
+	flat := got.Flatten()
+	if flat["29.00"] != "com.xyz" || flat["29.01"] != "Z1" {
+		t.Errorf("Merge() tag 29 = %v", flat)
+	}
+	if v, ok := flat["29.02"]; ok {
+		t.Errorf("Merge() kept base sub-field 29.02 = %q from another scheme", v)
+	}
+
+	got, err = MergeWithOptions(base, overlay, MergeOptions{Conflict: MergeKeepBase})
+	if err != nil {
+		t.Fatalf("MergeWithOptions() error: %v", err)
+	}
+	if flat := got.Flatten(); flat["29.00"] != "com.abc" || flat["29.01"] != "X1" || flat["29.02"] != "Y1" {
+		t.Errorf("MergeKeepBase tag 29 = %v", flat)
+	}
+}
+
+func TestMerge_Conflicts(t *testing.T) {
+	overlay := &Payload{MerchantName: "ABC Hammers", MerchantCity: "Boston", PostalCode: "02110"}
+
+	got, err := MergeWithOptions(basePayload(), overlay, MergeOptions{Conflict: MergeKeepBase})
+	if err != nil {
+		t.Fatalf("MergeWithOptions() error: %v", err)
+	}
+	if got.MerchantCity != "New York" || got.PostalCode != "02110" {
+		t.Errorf("MergeKeepBase city/postal = %q/%q", got.MerchantCity, got.PostalCode)
+	}
+
+	_, err = MergeWithOptions(basePayload(), overlay, MergeOptions{Conflict: MergeRejectConflict})
+	if !errors.Is(err, ErrMergeConflict) || err.Error() != "emvqr: conflicting field values: 60" {
+		t.Errorf("MergeRejectConflict error = %v", err)
+	}
+
+	if _, err := MergeWithOptions(basePayload(), overlay, MergeOptions{Conflict: 7}); err == nil {
+		t.Error("MergeWithOptions() expected error for unknown rule, got nil")
+	}
+}
+
+func TestMerge_NilPayload(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		base, overlay *Payload
+	}{
+		{"nil base", nil, &Payload{}},
+		{"nil overlay", basePayload(), nil},
+	} {
+		if got, err := Merge(tc.base, tc.overlay); !errors.Is(err, ErrMissingRequired) {
+			t.Errorf("%s: Merge() = %v, %v; want ErrMissingRequired", tc.name, got, err)
+		}
+	}
+}
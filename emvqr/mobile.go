@@ -0,0 +1,113 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dialingPlan is the country calling code of a country and the length range
+// of its national significant numbers, i.e. without the trunk prefix.
+type dialingPlan struct {
+	code     string
+	min, max int
+}
+
+// dialingPlans maps ISO 3166-1 alpha-2 country codes to dialing plans, for
+// the countries whose numbers NormalizeE164 can expand from national form.
+var dialingPlans = map[string]dialingPlan{
+	"AE": {"971", 8, 9},
+	"AU": {"61", 9, 9},
+	"BR": {"55", 10, 11},
+	"CA": {"1", 10, 10},
+	"DE": {"49", 7, 11},
+	"FR": {"33", 9, 9},
+	"GB": {"44", 10, 10},
+	"ID": {"62", 9, 12},
+	"IN": {"91", 10, 10},
+	"JP": {"81", 9, 10},
+	"MX": {"52", 10, 10},
+	"MY": {"60", 9, 10},
+	"SG": {"65", 8, 8},
+	"TH": {"66", 8, 9},
+	"US": {"1", 10, 10},
+}
+
+// NormalizeE164 returns a phone number in E.164 form, e.g. "+919876543210".
+// Spaces, hyphens, dots and parentheses are ignored. A number starting with
+// "+" or the international prefix "00" is taken as international;
+// otherwise it is a national number of country (ISO 3166-1 alpha-2), with
+// any leading trunk "0" dropped, such as "098765 43210" in India. The error
+// wraps ErrInvalidFormat.
+func NormalizeE164(number, country string) (string, error) {
+	digits := make([]byte, 0, len(number))
+	international := false
+	for i := 0; i < len(number); i++ {
+		switch c := number[i]; {
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case c == '+' && len(digits) == 0 && !international:
+			international = true
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+		default:
+			return "", fmt.Errorf("%w: mobile number %q contains %q", ErrInvalidFormat, number, c)
+		}
+	}
+	if !international && len(digits) > 2 && digits[0] == '0' && digits[1] == '0' {
+		international = true
+		digits = digits[2:]
+	}
+
+	if !international {
+		plan, ok := dialingPlans[country]
+		if !ok {
+			return "", fmt.Errorf("%w: mobile number %q has no country code and %q has no known dialing plan",
+				ErrInvalidFormat, number, country)
+		}
+		national := strings.TrimLeft(string(digits), "0")
+		// NANP numbers are often written with the country code but no "+".
+		if plan.code == "1" && len(national) == 11 && national[0] == '1' {
+			national = national[1:]
+		}
+		if len(national) < plan.min || len(national) > plan.max {
+			return "", fmt.Errorf("%w: mobile number %q is not a valid %s number", ErrInvalidFormat, number, country)
+		}
+		return "+" + plan.code + national, nil
+	}
+
+	// E.164 allows at most 15 digits, country code included.
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", fmt.Errorf("%w: mobile number %q is not a valid E.164 number", ErrInvalidFormat, number)
+	}
+	return "+" + string(digits), nil
+}
+
+// MobileE164 returns the Mobile Number (ID "02") in E.164 form, expanding a
+// national number with the calling code of country, normally the payload's
+// CountryCode; see NormalizeE164. It fails when the field is empty or holds
+// PromptValue, which asks the consumer for their number instead.
+func (a *AdditionalDataField) MobileE164(country string) (string, error) {
+	if a == nil || a.MobileNumber == "" {
+		return "", fmt.Errorf("%w: mobile number (ID %q)", ErrMissingRequired, ADFMobileNumber)
+	}
+	if a.MobileNumber == PromptValue {
+		return "", fmt.Errorf("%w: mobile number is %q, a prompt for the consumer", ErrInvalidFormat, PromptValue)
+	}
+	return NormalizeE164(a.MobileNumber, country)
+}
+
+// NormalizeMobileNumber rewrites the Tag 62 Mobile Number in E.164 form,
+// given the payload's CountryCode. It leaves an absent number or
+// PromptValue unchanged and returns the error of NormalizeE164 for a number
+// that is not valid.
+func (p *Payload) NormalizeMobileNumber() error {
+	a := p.AdditionalData
+	if a == nil || a.MobileNumber == "" || a.MobileNumber == PromptValue {
+		return nil
+	}
+	e164, err := NormalizeE164(a.MobileNumber, p.CountryCode)
+	if err != nil {
+		return err
+	}
+	a.MobileNumber = e164
+	return nil
+}
@@ -0,0 +1,74 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeE164(t *testing.T) {
+	tests := []struct {
+		number, country, want string
+	}{
+		{"9876543210", "IN", "+919876543210"},
+		{"098765 43210", "IN", "+919876543210"},
+		{"+91 98765-43210", "IN", "+919876543210"},
+		{"0091 9876543210", "US", "+919876543210"},
+		{"(212) 555-0123", "US", "+12125550123"},
+		{"1-212-555-0123", "US", "+12125550123"},
+		{"+44 20 7946 0958", "", "+442079460958"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeE164(tt.number, tt.country)
+		if err != nil {
+			t.Errorf("NormalizeE164(%q, %q) error: %v", tt.number, tt.country, err)
+			continue
+		}
+		assertEqual(t, tt.number, tt.want, got)
+	}
+
+	for _, bad := range []struct{ number, country string }{
+		{"98765", "IN"},
+		{"9876543210", "XX"},
+		{"98765x43210", "IN"},
+		{"+1234567890123456", "IN"},
+		{"+91+9876543210", "IN"},
+	} {
+		if _, err := NormalizeE164(bad.number, bad.country); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("NormalizeE164(%q, %q) = %v, want ErrInvalidFormat", bad.number, bad.country, err)
+		}
+	}
+}
+
+func TestMobileE164(t *testing.T) {
+	var a *AdditionalDataField
+	if _, err := a.MobileE164("IN"); !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("nil template: %v", err)
+	}
+	a = &AdditionalDataField{MobileNumber: PromptValue}
+	if _, err := a.MobileE164("IN"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("prompt: %v", err)
+	}
+	a.MobileNumber = "09876543210"
+	got, err := a.MobileE164("IN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "E.164", "+919876543210", got)
+}
+
+func TestPayload_NormalizeMobileNumber(t *testing.T) {
+	p := basePayload()
+	if err := p.NormalizeMobileNumber(); err != nil {
+		t.Errorf("no additional data: %v", err)
+	}
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.MobileNumber = "212 555 0123" })
+	if err := p.NormalizeMobileNumber(); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "MobileNumber", "+12125550123", p.AdditionalData.MobileNumber)
+
+	p.AdditionalData.MobileNumber = PromptValue
+	if err := p.NormalizeMobileNumber(); err != nil || p.AdditionalData.MobileNumber != PromptValue {
+		t.Errorf("prompt changed: %q, %v", p.AdditionalData.MobileNumber, err)
+	}
+}
@@ -0,0 +1,205 @@
+// Package ndef builds NFC Data Exchange Format (NDEF) messages carrying EMV
+// merchant payloads, for writing to NTAG stickers on combined QR and NFC
+// standees.
+//
+// A merchant message holds up to two records: a URI record with the UPI deep
+// link, so a phone tapping the tag opens a UPI app, followed by a Text record
+// with the EMV payload itself, re-encoded with an NFC Point of Initiation
+// Method (Tag 01 = 31 or 32) so that wallets reading the tag see how it was
+// initiated:
+//
+//	msg, err := ndef.Message(p, ndef.Options{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	data := ndef.TagTLV(msg) // bytes for NTAG user memory from block 4
+//	if len(data) > ndef.NTAG213 {
+//	    // use a larger tag
+//	}
+package ndef
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// TNF is the Type Name Format of a record.
+type TNF byte
+
+// Type Name Formats (NFC Forum NDEF 1.0, section 3.2.6).
+const (
+	TNFEmpty     TNF = 0x00
+	TNFWellKnown TNF = 0x01
+	TNFMIME      TNF = 0x02
+	TNFURI       TNF = 0x03
+	TNFExternal  TNF = 0x04
+	TNFUnknown   TNF = 0x05
+)
+
+// User memory sizes in bytes of common NTAG types, for checking that a
+// TagTLV fits.
+const (
+	NTAG213 = 144
+	NTAG215 = 504
+	NTAG216 = 888
+)
+
+// Record header flags.
+const (
+	flagMB = 0x80 // message begin
+	flagME = 0x40 // message end
+	flagSR = 0x10 // short record: one-byte payload length
+	flagIL = 0x08 // ID length present
+)
+
+// ErrNoRecords is returned when a message would be empty.
+var ErrNoRecords = errors.New("ndef: message has no records")
+
+// Record is a single NDEF record.
+type Record struct {
+	TNF     TNF
+	Type    []byte
+	ID      []byte
+	Payload []byte
+}
+
+// uriPrefixes are the URI identifier codes of the NFC Forum URI RTD, in
+// code order starting at 0x01.
+var uriPrefixes = []string{
+	"http://www.", "https://www.", "http://", "https://", "tel:", "mailto:",
+	"ftp://anonymous:anonymous@", "ftp://ftp.", "ftps://", "sftp://", "smb://",
+	"nfs://", "ftp://", "dav://", "news:", "telnet://", "imap:", "rtsp://",
+	"urn:", "pop:", "sip:", "sips:", "tftp:", "btspp://", "btl2cap://",
+	"btgoep://", "tcpobex://", "irdaobex://", "file://", "urn:epc:id:",
+	"urn:epc:tag:", "urn:epc:pat:", "urn:epc:raw:", "urn:epc:", "urn:nfc:",
+}
+
+// URIRecord returns a well-known URI record ("U"), abbreviating the scheme
+// with its URI identifier code where one is defined.
+func URIRecord(uri string) Record {
+	code, best := 0, ""
+	for i, prefix := range uriPrefixes {
+		if strings.HasPrefix(uri, prefix) && len(prefix) > len(best) {
+			code, best = i+1, prefix
+		}
+	}
+	payload := append([]byte{byte(code)}, uri[len(best):]...)
+	return Record{TNF: TNFWellKnown, Type: []byte("U"), Payload: payload}
+}
+
+// TextRecord returns a well-known UTF-8 Text record ("T") with an IANA
+// language code such as "en".
+func TextRecord(lang, text string) Record {
+	payload := make([]byte, 0, 1+len(lang)+len(text))
+	payload = append(payload, byte(len(lang)&0x3F))
+	payload = append(payload, lang...)
+	payload = append(payload, text...)
+	return Record{TNF: TNFWellKnown, Type: []byte("T"), Payload: payload}
+}
+
+// Marshal encodes records as an NDEF message. Records with payloads of up
+// to 255 bytes use the short record format.
+func Marshal(records ...Record) ([]byte, error) {
+	if len(records) == 0 {
+		return nil, ErrNoRecords
+	}
+	var out []byte
+	for i, r := range records {
+		if len(r.Type) > 255 || len(r.ID) > 255 {
+			return nil, fmt.Errorf("ndef: record %d type or ID exceeds 255 bytes", i)
+		}
+		header := byte(r.TNF & 0x07)
+		if i == 0 {
+			header |= flagMB
+		}
+		if i == len(records)-1 {
+			header |= flagME
+		}
+		short := len(r.Payload) <= 255
+		if short {
+			header |= flagSR
+		}
+		if len(r.ID) > 0 {
+			header |= flagIL
+		}
+		out = append(out, header, byte(len(r.Type)))
+		if short {
+			out = append(out, byte(len(r.Payload)))
+		} else {
+			n := len(r.Payload)
+			out = append(out, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+		}
+		if len(r.ID) > 0 {
+			out = append(out, byte(len(r.ID)))
+		}
+		out = append(out, r.Type...)
+		out = append(out, r.ID...)
+		out = append(out, r.Payload...)
+	}
+	return out, nil
+}
+
+// TagTLV wraps an NDEF message in the NDEF Message TLV and Terminator TLV
+// used by NFC Forum Type 2 tags such as NTAG, ready to be written to user
+// memory.
+func TagTLV(message []byte) []byte {
+	n := len(message)
+	var out []byte
+	if n < 0xFF {
+		out = append(out, 0x03, byte(n))
+	} else {
+		out = append(out, 0x03, 0xFF, byte(n>>8), byte(n))
+	}
+	out = append(out, message...)
+	return append(out, 0xFE)
+}
+
+// Options controls Message.
+type Options struct {
+	// OmitUPIURI leaves out the UPI deep link record even when the payload
+	// has a VPA.
+	OmitUPIURI bool
+
+	// OmitPayload leaves out the EMV payload record.
+	OmitPayload bool
+
+	// KeepPointOfInitiation encodes the EMV payload with its Tag 01 as is,
+	// instead of switching a QR or BLE method to NFC.
+	KeepPointOfInitiation bool
+
+	// Language is the language code of the EMV payload Text record. The
+	// zero value is "en".
+	Language string
+}
+
+// Message returns the NDEF message for a merchant payload: the UPI deep
+// link (if p has a VPA) followed by the EMV payload, subject to opts.
+func Message(p *emvqr.Payload, opts Options) ([]byte, error) {
+	var records []Record
+	if !opts.OmitUPIURI && p.GetMerchantVPA() != "" {
+		uri, err := p.UPIURI()
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, URIRecord(uri))
+	}
+	if !opts.OmitPayload {
+		nfc := *p
+		if poi := nfc.PointOfInitiationMethod; len(poi) == 2 && !opts.KeepPointOfInitiation {
+			nfc.PointOfInitiationMethod = emvqr.POIMethodNFC + poi[1:]
+		}
+		raw, err := emvqr.Encode(&nfc)
+		if err != nil {
+			return nil, err
+		}
+		lang := opts.Language
+		if lang == "" {
+			lang = "en"
+		}
+		records = append(records, TextRecord(lang, raw))
+	}
+	return Marshal(records...)
+}
@@ -0,0 +1,102 @@
+package ndef
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func TestURIRecord(t *testing.T) {
+	r := URIRecord("https://www.example.com/pay")
+	if r.Payload[0] != 0x02 || string(r.Payload[1:]) != "example.com/pay" {
+		t.Errorf("URIRecord(https://www.) payload = %q", r.Payload)
+	}
+	r = URIRecord("upi://pay?pa=shop@upi")
+	if r.Payload[0] != 0x00 || string(r.Payload[1:]) != "upi://pay?pa=shop@upi" {
+		t.Errorf("URIRecord(upi://) payload = %q", r.Payload)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	msg, err := Marshal(TextRecord("en", "hi"), URIRecord("tel:123"))
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	want := []byte{
+		0x91, 0x01, 0x05, 'T', 0x02, 'e', 'n', 'h', 'i', // MB|SR, well-known
+		0x51, 0x01, 0x04, 'U', 0x05, '1', '2', '3', // ME|SR, well-known
+	}
+	if !bytes.Equal(msg, want) {
+		t.Errorf("Marshal() = % X, want % X", msg, want)
+	}
+
+	long, err := Marshal(Record{TNF: TNFMIME, Type: []byte("a/b"), ID: []byte("x"), Payload: make([]byte, 300)})
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	if !bytes.Equal(long[:10], []byte{0xCA, 0x03, 0x00, 0x00, 0x01, 0x2C, 0x01, 'a', '/', 'b'}) {
+		t.Errorf("Marshal(long) header = % X", long[:10])
+	}
+
+	if _, err := Marshal(); !errors.Is(err, ErrNoRecords) {
+		t.Errorf("Marshal() error = %v, want ErrNoRecords", err)
+	}
+}
+
+func TestTagTLV(t *testing.T) {
+	if got := TagTLV([]byte{1, 2}); !bytes.Equal(got, []byte{0x03, 0x02, 1, 2, 0xFE}) {
+		t.Errorf("TagTLV(short) = % X", got)
+	}
+	if got := TagTLV(make([]byte, 300)); !bytes.Equal(got[:4], []byte{0x03, 0xFF, 0x01, 0x2C}) || len(got) != 305 {
+		t.Errorf("TagTLV(long) header = % X, len %d", got[:4], len(got))
+	}
+}
+
+func TestMessage_BharatQR(t *testing.T) {
+	p := emvqr.NewPayload()
+	p.PointOfInitiationMethod = emvqr.POIStaticQR
+	_ = p.AddMerchantIdentifier("06", "6100010031755635")
+	_ = p.SetUPIVPATemplate(emvqr.RuPayRIDValue, "shop@upi", "")
+	p.MerchantCategoryCode = "5411"
+	p.TransactionCurrency = "356"
+	p.CountryCode = "IN"
+	p.MerchantName = "Sharma Stores"
+	p.MerchantCity = "Mumbai"
+
+	msg, err := Message(p, Options{})
+	if err != nil {
+		t.Fatalf("Message() error: %v", err)
+	}
+	uri := "upi://pay?pa=shop@upi&pn=Sharma%20Stores&mc=5411&cu=INR"
+	if !bytes.Contains(msg, []byte(uri)) {
+		t.Errorf("Message() lacks %q: %q", uri, msg)
+	}
+	i := bytes.Index(msg, []byte("000201"))
+	if i < 0 {
+		t.Fatalf("Message() lacks the EMV payload: %q", msg)
+	}
+	decoded, err := emvqr.Decode(string(msg[i:]))
+	if err != nil {
+		t.Fatalf("Decode(EMV record) error: %v", err)
+	}
+	if decoded.PointOfInitiationMethod != emvqr.POIStaticNFC {
+		t.Errorf("EMV record POI = %q, want %q", decoded.PointOfInitiationMethod, emvqr.POIStaticNFC)
+	}
+	if p.PointOfInitiationMethod != emvqr.POIStaticQR {
+		t.Error("Message() modified the payload")
+	}
+	if n := len(TagTLV(msg)); n > NTAG215 {
+		t.Errorf("TagTLV size %d exceeds NTAG215", n)
+	}
+
+	msg, err = Message(p, Options{OmitPayload: true})
+	if err != nil || strings.Contains(string(msg), "000201") || msg[0] != 0xD1 {
+		t.Errorf("Message(OmitPayload) = % X, %v", msg, err)
+	}
+	if _, err := Message(p, Options{OmitPayload: true, OmitUPIURI: true}); !errors.Is(err, ErrNoRecords) {
+		t.Errorf("Message(no records) error = %v", err)
+	}
+}
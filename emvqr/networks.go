@@ -0,0 +1,102 @@
+package emvqr
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	networkMu sync.RWMutex
+
+	// networkNames names the networks behind merchant account information
+	// IDs "02"–"16": the EMVCo assignments, with the Bharat QR use of the
+	// EMVCo reserved IDs "06"–"08". RegisterNetwork adds IDs "17"–"25" as
+	// EMVCo assigns them.
+	networkNames = map[string]string{
+		"02": "Visa", "03": "Visa", "04": "Mastercard", "05": "Mastercard",
+		"06": "RuPay", "07": "RuPay", "08": "IFSC + account",
+		"09": "Discover", "10": "Discover", "11": "Amex", "12": "Amex",
+		"13": "JCB", "14": "JCB", "15": "UnionPay", "16": "UnionPay",
+	}
+)
+
+// RegisterNetwork assigns the primitive Merchant Account Information ID id
+// ("02"–"25") to the payment network name, e.g. a domestic scheme that
+// EMVCo allocates one of IDs "17"–"25" after this release, so that
+// NetworkName, Payload.Networks and AddNetworkIdentifier know it. An
+// existing assignment of id is replaced. It is safe for concurrent use.
+func RegisterNetwork(id, name string) error {
+	if n, err := strconv.Atoi(id); err != nil || len(id) != 2 || n < 2 || n > 25 {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: network tag ID must be 02–25, got %q", ErrInvalidTagID, id), "tag", id)
+	}
+	if name == "" {
+		return fmt.Errorf("%w: network name for tag ID %s", ErrMissingRequired, id)
+	}
+	networkMu.Lock()
+	defer networkMu.Unlock()
+	networkNames[id] = name
+	return nil
+}
+
+// NetworkName returns the payment network assigned the primitive Merchant
+// Account Information ID id, e.g. "Visa" for "02".
+func NetworkName(id string) (string, bool) {
+	networkMu.RLock()
+	defer networkMu.RUnlock()
+	name, ok := networkNames[id]
+	return name, ok
+}
+
+// NetworkIDs returns the IDs assigned to the network name, matched without
+// regard to case, in ascending order, e.g. ["02" "03"] for "Visa".
+func NetworkIDs(name string) []string {
+	networkMu.RLock()
+	defer networkMu.RUnlock()
+	var ids []string
+	for id, n := range networkNames {
+		if strings.EqualFold(n, name) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Networks returns the names of the payment networks p accepts, once each,
+// in the order of MerchantIdentifiers: those of primitive identifiers with
+// an assigned network, then "UPI" if the UPI VPA Template is set.
+func (p *Payload) Networks() []string {
+	var names []string
+	for _, mi := range p.MerchantIdentifiers {
+		if name, ok := NetworkName(mi.ID); ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if p.UPIVPAInfo != nil {
+		names = append(names, "UPI")
+	}
+	return names
+}
+
+// AddNetworkIdentifier adds value as the merchant identifier of the network
+// name, under the first of its IDs (see NetworkIDs) that p does not use yet,
+// e.g. "02" and then "03" for "Visa". It returns an error if the network is
+// unknown or all its IDs are taken.
+func (p *Payload) AddNetworkIdentifier(name, value string) error {
+	ids := NetworkIDs(name)
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: unknown payment network %q", ErrInvalidFormat, name)
+	}
+	for _, id := range ids {
+		if !slices.ContainsFunc(p.MerchantIdentifiers, func(mi MerchantIdentifier) bool { return mi.ID == id }) {
+			return p.AddMerchantIdentifier(id, value)
+		}
+	}
+	return newError(CodeInvalidFormat,
+		fmt.Errorf("%w: every tag ID of network %s (%s) is in use", ErrDuplicateTag, name, strings.Join(ids, ", ")),
+		"tag", ids[0])
+}
@@ -0,0 +1,56 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisterNetwork(t *testing.T) {
+	t.Cleanup(func() {
+		networkMu.Lock()
+		delete(networkNames, "17")
+		networkMu.Unlock()
+	})
+	if _, ok := NetworkName("17"); ok {
+		t.Fatal("NetworkName(\"17\") is assigned before RegisterNetwork")
+	}
+	if err := RegisterNetwork("17", "Elo"); err != nil {
+		t.Fatalf("RegisterNetwork() error: %v", err)
+	}
+	name, _ := NetworkName("17")
+	assertEqual(t, "NetworkName", "Elo", name)
+	assertEqual(t, "NetworkIDs", "17", strings.Join(NetworkIDs("elo"), ","))
+	assertEqual(t, "topLevelSpec", "Merchant Account Information (Elo)", topLevelSpec("17").name)
+
+	p := basePayload()
+	if err := p.AddNetworkIdentifier("Elo", "6363680012345678"); err != nil {
+		t.Fatalf("AddNetworkIdentifier() error: %v", err)
+	}
+	assertEqual(t, "Networks", "Visa,Elo", strings.Join(p.Networks(), ","))
+
+	for _, id := range []string{"01", "26", "7", ""} {
+		if err := RegisterNetwork(id, "X"); !errors.Is(err, ErrInvalidTagID) {
+			t.Errorf("RegisterNetwork(%q) error = %v, want ErrInvalidTagID", id, err)
+		}
+	}
+	if err := RegisterNetwork("18", ""); !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("RegisterNetwork with no name error = %v, want ErrMissingRequired", err)
+	}
+}
+
+func TestAddNetworkIdentifier(t *testing.T) {
+	p := basePayload()
+	if err := p.AddNetworkIdentifier("visa", "4000123456789013"); err != nil {
+		t.Fatalf("AddNetworkIdentifier() error: %v", err)
+	}
+	assertEqual(t, "second Visa ID", "03", p.MerchantIdentifiers[len(p.MerchantIdentifiers)-1].ID)
+	if err := p.AddNetworkIdentifier("Visa", "4000123456789021"); !errors.Is(err, ErrDuplicateTag) {
+		t.Errorf("third Visa identifier error = %v, want ErrDuplicateTag", err)
+	}
+	if err := p.AddNetworkIdentifier("Nope", "1"); err == nil {
+		t.Error("AddNetworkIdentifier() of an unknown network expected error, got nil")
+	}
+	p.UPIVPAInfo = &UPIVPATemplate{RuPayRID: RuPayRIDValue, VPA: "abc@upi"}
+	assertEqual(t, "Networks", "Visa,UPI", strings.Join(p.Networks(), ","))
+}
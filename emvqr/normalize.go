@@ -0,0 +1,49 @@
+package emvqr
+
+import "strings"
+
+// Normalized is the Correction kind reported by Normalize.
+const Normalized = "normalized"
+
+// NormalizeOptions controls Normalize.
+type NormalizeOptions struct {
+	// Uppercase upper-cases the Merchant Name (Tag 59) and Merchant City
+	// (Tag 60), as some acquirers require. The local-language name and city
+	// of the Language Template are never upper-cased.
+	Uppercase bool
+}
+
+// NormalizeText returns s with surrounding whitespace trimmed and each
+// internal run of whitespace, such as a double space or a tab, replaced by
+// a single space, upper-cased if upper is set; e.g. "  Sharma\tStores "
+// becomes "Sharma Stores".
+func NormalizeText(s string, upper bool) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if upper {
+		s = strings.ToUpper(s)
+	}
+	return s
+}
+
+// Normalize applies NormalizeText to the free-text fields merchant
+// onboarding data most often gets wrong: the Merchant Name (Tag 59),
+// Merchant City (Tag 60) and Postal Code (Tag 61), and the name and city of
+// the Language Template (Tags 64.01 and 64.02). It returns the changes made.
+// A profile may apply it before encoding; see the profile package.
+func (p *Payload) Normalize(opts NormalizeOptions) []Correction {
+	var corrections []Correction
+	apply := func(path string, value *string, upper bool) {
+		if out := NormalizeText(*value, upper); out != *value {
+			corrections = append(corrections, Correction{Normalized, path, *value, out})
+			*value = out
+		}
+	}
+	apply(IDMerchantName, &p.MerchantName, opts.Uppercase)
+	apply(IDMerchantCity, &p.MerchantCity, opts.Uppercase)
+	apply(IDPostalCode, &p.PostalCode, false)
+	if lt := p.LanguageTemplate; lt != nil {
+		apply(IDMerchantInfoLanguageTemplate+"."+LangMerchantName, &lt.MerchantName, false)
+		apply(IDMerchantInfoLanguageTemplate+"."+LangMerchantCity, &lt.MerchantCity, false)
+	}
+	return corrections
+}
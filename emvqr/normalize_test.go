@@ -0,0 +1,41 @@
+package emvqr
+
+import "testing"
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		in    string
+		upper bool
+		want  string
+	}{
+		{"ABC Hammers", false, "ABC Hammers"},
+		{"  Sharma\tStores ", false, "Sharma Stores"},
+		{"New   York\n", true, "NEW YORK"},
+		{"   ", false, ""},
+	}
+	for _, tt := range tests {
+		assertEqual(t, "NormalizeText("+tt.in+")", tt.want, NormalizeText(tt.in, tt.upper))
+	}
+}
+
+func TestPayload_Normalize(t *testing.T) {
+	p := basePayload()
+	p.MerchantName = " ABC  Hammers"
+	p.MerchantCity = "New York"
+	p.PostalCode = "10001 "
+	p.LanguageTemplate = &LanguageTemplate{LanguagePreference: "ZH", MerchantName: "最佳  运输", MerchantCity: "北京"}
+
+	corrections := p.Normalize(NormalizeOptions{Uppercase: true})
+	assertEqual(t, "MerchantName", "ABC HAMMERS", p.MerchantName)
+	assertEqual(t, "MerchantCity", "NEW YORK", p.MerchantCity)
+	assertEqual(t, "PostalCode", "10001", p.PostalCode)
+	assertEqual(t, "64.01", "最佳 运输", p.LanguageTemplate.MerchantName)
+	if len(corrections) != 4 {
+		t.Fatalf("Normalize() = %v, want 4 corrections", corrections)
+	}
+	assertEqual(t, "corrections[0]", `normalized at tag 59: " ABC  Hammers" -> "ABC HAMMERS"`, corrections[0].String())
+
+	if again := p.Normalize(NormalizeOptions{Uppercase: true}); len(again) != 0 {
+		t.Errorf("second Normalize() = %v, want none", again)
+	}
+}
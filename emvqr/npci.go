@@ -0,0 +1,103 @@
+package emvqr
+
+import "strconv"
+
+// Sub-fields of the NPCI payment system specific template: the template in
+// Tag 62 (IDs "50"–"99") whose Globally Unique Identifier is RuPayRIDValue.
+// It carries UPI data that has no EMV data object of its own and, like all
+// payment system templates, is defined by EMV QRCPS MPM v1.1.
+const (
+	NPCIInvoiceDate = "01" // invoice date, RFC 3339, as the UPI invoiceDate parameter
+	NPCIMode        = "02" // UPI initiation mode, as the UPI mode parameter
+	NPCIPurpose     = "03" // UPI purpose code, as the UPI purpose parameter
+)
+
+// npciField returns sub-field id of the NPCI template, or "". It also reads
+// payloads decoded under v1.0, in which the template is kept in RFUFields.
+func (p *Payload) npciField(id string) string {
+	a := p.AdditionalData
+	if a == nil {
+		return ""
+	}
+	templates := a.PaymentSystemTemplates
+	for _, rfu := range a.RFUFields {
+		if isPaymentSystemTemplateID(rfu.ID) {
+			if t, err := decodeUnreservedTemplate(rfu.ID, rfu.Value); err == nil {
+				templates = append(templates, *t)
+			}
+		}
+	}
+	if i := npciTemplateIndex(templates); i >= 0 {
+		for _, sf := range templates[i].SubFields {
+			if sf.ID == id {
+				return sf.Value
+			}
+		}
+	}
+	return ""
+}
+
+// setNPCIField sets sub-field id of the NPCI template, creating the
+// template in the lowest free ID from "50" if needed. An empty value
+// removes the sub-field.
+func (p *Payload) setNPCIField(id, value string) {
+	if value == "" && (p.AdditionalData == nil || npciTemplateIndex(p.AdditionalData.PaymentSystemTemplates) < 0) {
+		return
+	}
+	p.SetAdditionalData(func(a *AdditionalDataField) {
+		i := npciTemplateIndex(a.PaymentSystemTemplates)
+		if i < 0 {
+			a.PaymentSystemTemplates = append(a.PaymentSystemTemplates, UnreservedTemplate{
+				ID:               freePaymentSystemTemplateID(a.PaymentSystemTemplates),
+				GloballyUniqueID: RuPayRIDValue,
+			})
+			i = len(a.PaymentSystemTemplates) - 1
+		}
+		setSubField(&a.PaymentSystemTemplates[i].SubFields, id, value)
+	})
+}
+
+// npciTemplateIndex returns the index of the payment system template with
+// the RuPay RID as its GUID, or -1.
+func npciTemplateIndex(templates []UnreservedTemplate) int {
+	for i, t := range templates {
+		if t.GloballyUniqueID == RuPayRIDValue {
+			return i
+		}
+	}
+	return -1
+}
+
+// freePaymentSystemTemplateID returns the lowest ID from "50" to "99" not
+// used by templates, or "" if all are taken; Encode then rejects the new
+// template.
+func freePaymentSystemTemplateID(templates []UnreservedTemplate) string {
+	used := make(map[string]bool, len(templates))
+	for _, t := range templates {
+		used[t.ID] = true
+	}
+	for n := 50; n <= 99; n++ {
+		if id := strconv.Itoa(n); !used[id] {
+			return id
+		}
+	}
+	return ""
+}
+
+// setSubField sets the value of sub-field id, appending it if absent; an
+// empty value removes it.
+func setSubField(fields *[]DataObject, id, value string) {
+	for i, f := range *fields {
+		if f.ID == id {
+			if value == "" {
+				*fields = append((*fields)[:i], (*fields)[i+1:]...)
+			} else {
+				(*fields)[i].Value = value
+			}
+			return
+		}
+	}
+	if value != "" {
+		*fields = append(*fields, DataObject{ID: id, Value: value})
+	}
+}
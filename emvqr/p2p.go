@@ -0,0 +1,73 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MCCPersonToPerson is the Merchant Category Code UPI apps put in
+// person-to-person QRs, which have no merchant category.
+const MCCPersonToPerson = "0000"
+
+// IsPersonToPerson reports whether p is a person-to-person (P2P) QR, which
+// a person presents to be paid into their own account: its Merchant
+// Category Code (Tag 52) is MCCPersonToPerson, or it has none but has a UPI
+// VPA (Tag 26), the shape many UPI apps emit. Encode does not require the
+// MCC, Merchant City or a merchant account of P2P QRs.
+func (p *Payload) IsPersonToPerson() bool {
+	return p.MerchantCategoryCode == MCCPersonToPerson || p.MerchantCategoryCode == "" && p.UPIVPAInfo != nil
+}
+
+// NewP2PPayload returns a static person-to-person UPI QR paying vpa, such
+// as "ravi@okbank", under the payee's name, in Indian rupees. Set
+// TransactionAmount for a collect QR of a given amount. It returns an error
+// wrapping ErrInvalidFormat if vpa is not of the form handle@provider, or
+// the result fails ValidatePersonToPerson.
+func NewP2PPayload(name, vpa string) (*Payload, error) {
+	if handle, provider, ok := strings.Cut(vpa, "@"); !ok || handle == "" || provider == "" || strings.Contains(provider, "@") {
+		return nil, fmt.Errorf("%w: VPA %q must be of the form handle@provider", ErrInvalidFormat, vpa)
+	}
+	p := NewPayload()
+	p.PointOfInitiationMethod = POIStaticQR
+	p.UPIVPAInfo = &UPIVPATemplate{RuPayRID: RuPayRIDValue, VPA: vpa}
+	p.MerchantCategoryCode = MCCPersonToPerson
+	p.TransactionCurrency = "356"
+	p.CountryCode = "IN"
+	p.MerchantName = name
+	if err := ValidatePersonToPerson(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ValidatePersonToPerson checks the rules of a person-to-person QR: the
+// payload must be one (see IsPersonToPerson), name its payee, pay a UPI
+// VPA (Tag 26) and carry neither merchant accounts of card networks (Tags
+// 02–25) nor a tip or convenience fee (Tags 55–57). It returns every
+// violation, joined with errors.Join, each wrapping ErrSchemeViolation
+// with its tag in ErrorParams; nil if there are none.
+func ValidatePersonToPerson(p *Payload) error {
+	var errs []error
+	fail := func(tag, format string, args ...any) {
+		errs = append(errs, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: P2P: "+format, append([]any{ErrSchemeViolation}, args...)...), "tag", tag))
+	}
+	if !p.IsPersonToPerson() {
+		fail(IDMerchantCategoryCode, "Tag 52 MCC must be %q or absent, got %q", MCCPersonToPerson, p.MerchantCategoryCode)
+	}
+	if p.MerchantName == "" {
+		fail(IDMerchantName, "Tag 59 must name the payee")
+	}
+	if p.UPIVPAInfo == nil || p.UPIVPAInfo.VPA == "" {
+		fail(IDUPIVPATemplate, "Tag 26 must hold the payee's VPA")
+	}
+	for _, mi := range p.MerchantIdentifiers {
+		if mi.ID < IDUPIVPATemplate {
+			fail(mi.ID, "Tag %s merchant account is not allowed", mi.ID)
+		}
+	}
+	if p.TipOrConvenienceIndicator != "" {
+		fail(IDTipOrConvenienceIndicator, "Tag 55 tip or convenience fee is not allowed")
+	}
+	return joinErrors(errs)
+}
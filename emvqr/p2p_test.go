@@ -0,0 +1,93 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewP2PPayload(t *testing.T) {
+	p, err := NewP2PPayload("Ravi Kumar", "ravi@okbank")
+	if err != nil {
+		t.Fatalf("NewP2PPayload() error: %v", err)
+	}
+	raw := mustEncode(t, p)
+	if strings.Contains(raw, "6000") || !strings.Contains(raw, "5204"+MCCPersonToPerson) {
+		t.Errorf("Encode() = %q, want Tag 52 %q and no Tag 60", raw, MCCPersonToPerson)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if !decoded.IsPersonToPerson() {
+		t.Error("IsPersonToPerson() = false")
+	}
+	if err := ValidatePersonToPerson(decoded); err != nil {
+		t.Errorf("ValidatePersonToPerson() error: %v", err)
+	}
+	assertEqual(t, "VPA", "ravi@okbank", decoded.GetMerchantVPA())
+	assertEqual(t, "MerchantName", "Ravi Kumar", decoded.MerchantName)
+	assertEqual(t, "re-encoded", raw, mustEncode(t, decoded))
+}
+
+func TestP2P_NoMCC(t *testing.T) {
+	// The P2P shape of UPI apps that leave out Tags 52 and 60.
+	raw := withCRC("000201010211" + "26290010A0000005240111ravi@okbank" + "5303356" + "5802IN" + "5910Ravi Kumar")
+	p, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if !p.IsPersonToPerson() {
+		t.Error("IsPersonToPerson() = false")
+	}
+	if err := ValidatePersonToPerson(p); err != nil {
+		t.Errorf("ValidatePersonToPerson() error: %v", err)
+	}
+	again, err := Decode(mustEncode(t, p))
+	if err != nil {
+		t.Fatalf("Decode() of the re-encoded payload error: %v", err)
+	}
+	assertEqual(t, "MCC", "", again.MerchantCategoryCode)
+	assertEqual(t, "MerchantCity", "", again.MerchantCity)
+	assertEqual(t, "VPA", "ravi@okbank", again.GetMerchantVPA())
+}
+
+func TestEncode_MerchantStillNeedsMCCAndCity(t *testing.T) {
+	p := basePayload()
+	p.MerchantCategoryCode = ""
+	p.MerchantCity = ""
+	err := encodeAllErrors(t, p)
+	for _, tag := range []string{IDMerchantCategoryCode, IDMerchantCity} {
+		if !hasErrorTag(err, tag) {
+			t.Errorf("Encode() error %v does not report tag %s", err, tag)
+		}
+	}
+}
+
+// encodeAllErrors encodes p with AllErrors and returns the error, failing
+// t unless it wraps ErrMissingRequired.
+func encodeAllErrors(t *testing.T, p *Payload) error {
+	t.Helper()
+	_, err := EncodeWithOptions(p, EncodeOptions{AllErrors: true})
+	if !errors.Is(err, ErrMissingRequired) {
+		t.Fatalf("Encode() error = %v, want ErrMissingRequired", err)
+	}
+	return err
+}
+
+func TestValidatePersonToPerson(t *testing.T) {
+	if _, err := NewP2PPayload("Ravi", "ravi"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("NewP2PPayload() with a bad VPA error = %v, want ErrInvalidFormat", err)
+	}
+	p := basePayload()
+	p.SetPromptForTip()
+	err := ValidatePersonToPerson(p)
+	if !errors.Is(err, ErrSchemeViolation) {
+		t.Fatalf("ValidatePersonToPerson() error = %v, want ErrSchemeViolation", err)
+	}
+	for _, tag := range []string{IDMerchantCategoryCode, IDUPIVPATemplate, "02", IDTipOrConvenienceIndicator} {
+		if !hasErrorTag(err, tag) {
+			t.Errorf("ValidatePersonToPerson() error %v has no violation for %s", err, tag)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package emvqr
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// Correction kinds reported by PackAdditionalData.
+const (
+	PackTruncated = "truncated" // sub-field value shortened
+	PackDropped   = "dropped"   // sub-field or template removed
+)
+
+// PackStrategy controls how PackAdditionalData fits the Additional Data
+// Field Template (Tag 62) into its 99 bytes. Sub-fields are named by their
+// sub-ID, e.g. ADFStoreLabel; payment system templates and RFU fields by
+// theirs.
+type PackStrategy struct {
+	// Priority lists sub-IDs from most to least important. Sub-fields not
+	// listed are less important than those listed, and among themselves
+	// the higher the ID the less important.
+	Priority []string
+
+	// Truncate maps the sub-IDs that may be shortened to the minimum length
+	// in bytes to keep. Values are cut at a UTF-8 character boundary, and
+	// PromptValue is never shortened.
+	Truncate map[string]int
+
+	// Keep lists sub-IDs that are never dropped, such as the references a
+	// merchant reconciles payments by.
+	Keep []string
+}
+
+// DefaultPackStrategy keeps the Bill Number, Reference Label and Merchant
+// Tax ID, shortens the free-text labels and purpose to no fewer than 8
+// bytes, and drops the least useful sub-fields first: RFU fields and
+// payment system templates, then the consumer data request, loyalty
+// number, mobile number and labels.
+var DefaultPackStrategy = PackStrategy{
+	Priority: []string{
+		ADFReferenceLabel, ADFBillNumber, ADFMerchantTaxID, ADFTerminalLabel, ADFStoreLabel,
+		ADFPurposeOfTransaction, ADFMerchantChannel, ADFCustomerLabel, ADFMobileNumber,
+		ADFLoyaltyNumber, ADFAdditionalConsumerDataRequest,
+	},
+	Truncate: map[string]int{
+		ADFStoreLabel:           8,
+		ADFTerminalLabel:        8,
+		ADFCustomerLabel:        8,
+		ADFPurposeOfTransaction: 8,
+	},
+	Keep: []string{ADFBillNumber, ADFReferenceLabel, ADFMerchantTaxID},
+}
+
+// maxTemplateValue is the largest value a two-digit TLV length allows.
+const maxTemplateValue = 99
+
+// packUnit is a sub-field or template of Tag 62 as seen by
+// PackAdditionalData.
+type packUnit struct {
+	id    string
+	value *string // nil for templates, which can only be dropped
+	size  int     // encoded size of a template, ID and length included
+	drop  func()
+	old   string // value before packing; a template's GUID
+}
+
+// encodedSize returns the current encoded size of u.
+func (u *packUnit) encodedSize() int {
+	if u.value != nil {
+		return 4 + len(*u.value)
+	}
+	return u.size
+}
+
+// PackAdditionalData shortens and removes sub-fields of the Additional Data
+// Field Template until it fits in 99 bytes, following s, and returns the
+// changes in the order made. It first truncates the sub-fields s allows,
+// least important first, then drops those not kept, least important first,
+// so the result depends only on the payload and s. A template that already
+// fits is left unchanged.
+//
+// If the template cannot be made to fit, PackAdditionalData returns an
+// error wrapping ErrInvalidFormat and leaves the payload unchanged.
+func (p *Payload) PackAdditionalData(s PackStrategy) ([]Correction, error) {
+	a := p.AdditionalData
+	if a == nil {
+		return nil, nil
+	}
+	work := *a
+	work.PaymentSystemTemplates = slices.Clone(a.PaymentSystemTemplates)
+	work.RFUFields = slices.Clone(a.RFUFields)
+	units := packUnits(&work)
+
+	total := 0
+	for _, u := range units {
+		total += u.encodedSize()
+	}
+	if total <= maxTemplateValue {
+		return nil, nil
+	}
+
+	// Least important first.
+	rank := func(id string) int {
+		if i := slices.Index(s.Priority, id); i >= 0 {
+			return i
+		}
+		return len(s.Priority) + 100 + atoi2(id)
+	}
+	slices.SortStableFunc(units, func(x, y *packUnit) int { return rank(y.id) - rank(x.id) })
+
+	var changes []Correction
+	path := func(id string) string { return IDAdditionalDataFieldTemplate + "." + id }
+	for _, u := range units {
+		if total <= maxTemplateValue {
+			break
+		}
+		minLen, ok := s.Truncate[u.id]
+		if !ok || u.value == nil || *u.value == PromptValue || len(*u.value) <= minLen {
+			continue
+		}
+		keep := max(len(*u.value)-(total-maxTemplateValue), minLen)
+		for keep > 0 && !utf8.RuneStart((*u.value)[keep]) {
+			keep--
+		}
+		if keep == 0 {
+			continue
+		}
+		total -= len(*u.value) - keep
+		*u.value = (*u.value)[:keep]
+		changes = append(changes, Correction{PackTruncated, path(u.id), u.old, *u.value})
+	}
+	for _, u := range units {
+		if total <= maxTemplateValue {
+			break
+		}
+		if slices.Contains(s.Keep, u.id) {
+			continue
+		}
+		total -= u.encodedSize()
+		u.drop()
+		changes = append(changes, Correction{PackDropped, path(u.id), u.old, ""})
+	}
+	if total > maxTemplateValue {
+		return nil, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: additional data field template needs %d bytes after packing, more than %d",
+				ErrInvalidFormat, total, maxTemplateValue),
+			"tag", IDAdditionalDataFieldTemplate)
+	}
+	work.PaymentSystemTemplates = slices.DeleteFunc(work.PaymentSystemTemplates, func(t UnreservedTemplate) bool { return t.ID == "" })
+	work.RFUFields = slices.DeleteFunc(work.RFUFields, func(d DataObject) bool { return d.ID == "" })
+	if len(work.PaymentSystemTemplates) == 0 {
+		work.PaymentSystemTemplates = nil
+	}
+	if len(work.RFUFields) == 0 {
+		work.RFUFields = nil
+	}
+	*a = work
+	return changes, nil
+}
+
+// packUnits returns the non-empty sub-fields and templates of a.
+func packUnits(a *AdditionalDataField) []*packUnit {
+	var units []*packUnit
+	for _, f := range []struct {
+		id    string
+		value *string
+	}{
+		{ADFBillNumber, &a.BillNumber},
+		{ADFMobileNumber, &a.MobileNumber},
+		{ADFStoreLabel, &a.StoreLabel},
+		{ADFLoyaltyNumber, &a.LoyaltyNumber},
+		{ADFReferenceLabel, &a.ReferenceLabel},
+		{ADFCustomerLabel, &a.CustomerLabel},
+		{ADFTerminalLabel, &a.TerminalLabel},
+		{ADFPurposeOfTransaction, &a.PurposeOfTransaction},
+		{ADFAdditionalConsumerDataRequest, &a.AdditionalConsumerDataRequest},
+		{ADFMerchantTaxID, &a.MerchantTaxID},
+		{ADFMerchantChannel, &a.MerchantChannel},
+	} {
+		if *f.value == "" {
+			continue
+		}
+		value := f.value
+		units = append(units, &packUnit{
+			id: f.id, value: value, old: *value,
+			drop: func() { *value = "" },
+		})
+	}
+	for i := range a.PaymentSystemTemplates {
+		t := &a.PaymentSystemTemplates[i]
+		if t.GloballyUniqueID == "" && len(t.SubFields) == 0 {
+			continue
+		}
+		size := 4
+		if t.GloballyUniqueID != "" {
+			size += 4 + len(t.GloballyUniqueID)
+		}
+		for _, sf := range t.SubFields {
+			size += 4 + len(sf.Value)
+		}
+		units = append(units, &packUnit{
+			id: t.ID, size: size, old: t.GloballyUniqueID,
+			drop: func() { t.ID = "" },
+		})
+	}
+	for i := range a.RFUFields {
+		d := &a.RFUFields[i]
+		if d.Value == "" {
+			continue
+		}
+		units = append(units, &packUnit{
+			id: d.ID, size: 4 + len(d.Value), old: d.Value,
+			drop: func() { d.ID = "" },
+		})
+	}
+	return units
+}
+
+// atoi2 returns the value of a two-digit ID, or 0.
+func atoi2(id string) int {
+	if len(id) != 2 || !isNumeric(id) {
+		return 0
+	}
+	return int(id[0]-'0')*10 + int(id[1]-'0')
+}
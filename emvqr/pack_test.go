@@ -0,0 +1,96 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func overfullAdditionalData(p *Payload) {
+	p.SetAdditionalData(func(a *AdditionalDataField) {
+		a.BillNumber = "INV-2024-000123"
+		a.ReferenceLabel = "ORDER-9987"
+		a.StoreLabel = "ABC Hammers – Downtown Flagship Store"
+		a.TerminalLabel = "Counter 12 near the exit"
+		a.LoyaltyNumber = PromptValue
+		a.MobileNumber = "+12125550123"
+	})
+}
+
+func TestPackAdditionalData(t *testing.T) {
+	p := basePayload()
+	overfullAdditionalData(p)
+	if _, err := Encode(p); err == nil {
+		t.Fatal("expected the unpacked template to be too long")
+	}
+
+	changes, err := p.PackAdditionalData(DefaultPackStrategy)
+	if err != nil {
+		t.Fatalf("PackAdditionalData() error: %v", err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Kind+"@"+c.Path)
+	}
+	assertEqual(t, "changes", "truncated@62.03", strings.Join(got, " "))
+	assertEqual(t, "store label", "ABC Hammers", p.AdditionalData.StoreLabel)
+	assertEqual(t, "bill number", "INV-2024-000123", p.AdditionalData.BillNumber)
+	assertEqual(t, "loyalty", PromptValue, p.AdditionalData.LoyaltyNumber)
+	if _, err := Encode(p); err != nil {
+		t.Fatalf("Encode() after packing: %v", err)
+	}
+
+	// The least important sub-fields are shortened first.
+	p.AdditionalData.CustomerLabel = strings.Repeat("C", 25)
+	changes, err = p.PackAdditionalData(DefaultPackStrategy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = got[:0]
+	for _, c := range changes {
+		got = append(got, c.Kind+"@"+c.Path)
+	}
+	assertEqual(t, "changes", "truncated@62.06 truncated@62.03 truncated@62.07", strings.Join(got, " "))
+
+	again, err := p.PackAdditionalData(DefaultPackStrategy)
+	if err != nil || again != nil {
+		t.Errorf("packing a fitting template = %v, %v", again, err)
+	}
+}
+
+func TestPackAdditionalData_Deterministic(t *testing.T) {
+	a, b := basePayload(), basePayload()
+	overfullAdditionalData(a)
+	overfullAdditionalData(b)
+	if _, err := a.PackAdditionalData(DefaultPackStrategy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PackAdditionalData(DefaultPackStrategy); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "packed", mustEncode(t, a), mustEncode(t, b))
+}
+
+func TestPackAdditionalData_CannotFit(t *testing.T) {
+	p := basePayload()
+	p.SetAdditionalData(func(a *AdditionalDataField) {
+		a.BillNumber = strings.Repeat("B", 25)
+		a.ReferenceLabel = strings.Repeat("R", 25)
+		a.StoreLabel = strings.Repeat("S", 25)
+		a.CustomerLabel = strings.Repeat("C", 25)
+	})
+	s := PackStrategy{Keep: []string{ADFBillNumber, ADFReferenceLabel, ADFStoreLabel, ADFCustomerLabel}}
+	_, err := p.PackAdditionalData(s)
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("PackAdditionalData() = %v, want ErrInvalidFormat", err)
+	}
+	assertEqual(t, "unchanged", strings.Repeat("S", 25), p.AdditionalData.StoreLabel)
+
+	s.Keep = s.Keep[:2]
+	changes, err := p.PackAdditionalData(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a priority, higher sub-IDs go first.
+	assertEqual(t, "dropped", "62.06", changes[0].Path)
+}
@@ -0,0 +1,111 @@
+package emvqr
+
+import (
+	"slices"
+	"strings"
+)
+
+// PaymentOption is a way to pay the merchant: one merchant account of a
+// payload, as a consumer app offers it in a "Pay via RuPay or UPI?"
+// chooser.
+type PaymentOption struct {
+	Network     string // e.g. "Visa", "RuPay", "UPI" or "PIX"; the GUID for unknown templates
+	ID          string // tag ID of the merchant account, e.g. "06" or "26"
+	GUID        string // Globally Unique Identifier of a template; "" for primitives
+	Identifier  string // card or merchant number, VPA, Aadhaar number, or template sub-field "01"
+	Reference   string // transaction reference to quote: Tag 27.01, else Tag 62.05
+	AmountFixed bool   // Tag 54 sets the amount, which the consumer must not change
+}
+
+// Network names of the Bharat QR templates, as reported in
+// PaymentOption.Network.
+const (
+	NetworkUPI        = "UPI"
+	NetworkAadhaarPay = "Aadhaar Pay"
+)
+
+// PaymentOptions returns the merchant accounts of p a consumer can pay
+// with, in payload order, except that networks named in preference come
+// first, in that order; names are matched without regard to case, e.g.
+//
+//	p.PaymentOptions("UPI", "RuPay")
+//
+// Primitive identifiers are named by NetworkName. The UPI VPA Template
+// (Tag 26) is "UPI" and the Aadhaar Template (Tag 28) "Aadhaar Pay"; other
+// templates take the name of their scheme (see SchemeProfiles) or their
+// GUID; accounts of neither kind are "Merchant Account" and their ID.
+// The UPI VPA Reference (Tag 27) only qualifies a UPI payment and is
+// not an option of its own. Accounts without an identifier, such as an
+// Aadhaar Template whose Tag 28.01 is empty, are left out, since a wallet
+// cannot pay them.
+func (p *Payload) PaymentOptions(preference ...string) []PaymentOption {
+	reference := p.GetTransactionReference()
+	if reference == "" && p.AdditionalData != nil && p.AdditionalData.ReferenceLabel != PromptValue {
+		reference = p.AdditionalData.ReferenceLabel
+	}
+	fixed := p.TransactionAmount != ""
+
+	var options []PaymentOption
+	seen := make(map[string]bool)
+	add := func(o PaymentOption) {
+		if o.Identifier == "" {
+			return
+		}
+		o.Reference, o.AmountFixed = reference, fixed
+		options = append(options, o)
+		seen[o.ID] = true
+	}
+	typed := func(id string) {
+		switch {
+		case seen[id]:
+		case id == IDUPIVPATemplate && p.UPIVPAInfo != nil:
+			add(PaymentOption{Network: NetworkUPI, ID: id, GUID: p.UPIVPAInfo.RuPayRID, Identifier: p.UPIVPAInfo.VPA})
+		case id == IDAadhaarTemplate && p.MerchantAadhaar != nil:
+			add(PaymentOption{Network: NetworkAadhaarPay, ID: id, GUID: p.MerchantAadhaar.RuPayRID, Identifier: p.MerchantAadhaar.AadhaarNumber})
+		}
+	}
+	for _, mi := range p.MerchantIdentifiers {
+		switch {
+		case mi.ID == IDUPIVPATemplate || mi.ID == IDAadhaarTemplate:
+			typed(mi.ID)
+		case mi.ID == IDUPIVPAReference:
+		case mi.IsTemplate():
+			guid := mi.GloballyUniqueID()
+			name := schemeName(guid)
+			if name == "" {
+				name = "Merchant Account " + mi.ID
+			}
+			add(PaymentOption{Network: name, ID: mi.ID, GUID: guid, Identifier: mi.SubField("01")})
+		default:
+			name, ok := NetworkName(mi.ID)
+			if !ok {
+				name = "Merchant Account " + mi.ID
+			}
+			add(PaymentOption{Network: name, ID: mi.ID, Identifier: mi.Value})
+		}
+	}
+	typed(IDUPIVPATemplate)
+	typed(IDAadhaarTemplate)
+
+	rank := func(o PaymentOption) int {
+		if i := slices.IndexFunc(preference, func(n string) bool { return strings.EqualFold(n, o.Network) }); i >= 0 {
+			return i
+		}
+		return len(preference)
+	}
+	slices.SortStableFunc(options, func(a, b PaymentOption) int { return rank(a) - rank(b) })
+	return options
+}
+
+// schemeName returns the name of the scheme with the given Globally Unique
+// Identifier, or guid itself if none is known.
+func schemeName(guid string) string {
+	for _, s := range schemeProfiles {
+		for _, g := range s.GUIDs {
+			if strings.EqualFold(g, guid) {
+				return s.Name
+			}
+		}
+	}
+	return guid
+}
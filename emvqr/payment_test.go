@@ -0,0 +1,60 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func optionSummary(options []PaymentOption) string {
+	var parts []string
+	for _, o := range options {
+		parts = append(parts, o.ID+":"+o.Network)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestPaymentOptions_RealWorld(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	options := p.PaymentOptions()
+	// The Aadhaar Template (Tag 28) carries an empty Aadhaar number.
+	assertEqual(t, "options", "02:Visa 04:Mastercard 06:RuPay 08:IFSC + account 11:Amex 26:UPI",
+		optionSummary(options))
+	for _, o := range options {
+		if o.Identifier == "" {
+			t.Errorf("option %s has no identifier", o.ID)
+		}
+	}
+	upi := options[5]
+	assertEqual(t, "UPI", "{UPI 26 A000000524 SBIPMOPAD.02PL00000644432-21503961@SBIPAY 52602091445452087569609 true}",
+		fmt.Sprint(upi))
+
+	ranked := p.PaymentOptions("upi", "RuPay")
+	assertEqual(t, "ranked", "26:UPI 06:RuPay 02:Visa 04:Mastercard 08:IFSC + account 11:Amex",
+		optionSummary(ranked))
+}
+
+func TestPaymentOptions_Built(t *testing.T) {
+	p := basePayload()
+	if err := p.SetUPIVPATemplate(RuPayRIDValue, "abc@upi", ""); err != nil {
+		t.Fatal(err)
+	}
+	p.MerchantIdentifiers = append(p.MerchantIdentifiers,
+		MerchantIdentifier{ID: "30", SubFields: []DataObject{{ID: "00", Value: "br.gov.bcb.pix"}, {ID: "01", Value: "key@pix"}}},
+		MerchantIdentifier{ID: "31", SubFields: []DataObject{{ID: "01", Value: "x"}}},
+		MerchantIdentifier{ID: "32", SubFields: []DataObject{{ID: "00", Value: "br.gov.bcb.pix"}}},
+		MerchantIdentifier{ID: "24", Value: "12345"})
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.ReferenceLabel = "ORDER-7" })
+
+	options := p.PaymentOptions()
+	assertEqual(t, "options", "02:Visa 30:PIX 31:Merchant Account 31 24:Merchant Account 24 26:UPI", optionSummary(options))
+	for _, o := range options {
+		if o.Reference != "ORDER-7" || o.AmountFixed {
+			t.Errorf("option %+v: want reference ORDER-7 and no fixed amount", o)
+		}
+	}
+	assertEqual(t, "PIX key", "key@pix", options[1].Identifier)
+}
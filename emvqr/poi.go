@@ -0,0 +1,86 @@
+package emvqr
+
+import "fmt"
+
+// POI is a Point of Initiation Method (Tag 01): a method digit (QR, BLE or
+// NFC) followed by a data type digit (static or dynamic), e.g. POIDynamicQR.
+// The empty POI means the tag is absent. Convert with string(poi) for the
+// wire value; String returns a description.
+type POI string
+
+// POIMethod is the method digit of a POI: POIMethodQR, POIMethodBLE or
+// POIMethodNFC.
+type POIMethod string
+
+// String returns "QR", "BLE" or "NFC", or the digit itself if unknown.
+func (m POIMethod) String() string {
+	switch m {
+	case POIMethodQR:
+		return "QR"
+	case POIMethodBLE:
+		return "BLE"
+	case POIMethodNFC:
+		return "NFC"
+	}
+	return string(m)
+}
+
+// ParsePOI parses a Tag 01 value, returning an error wrapping
+// ErrInvalidFormat unless it is a known method followed by a known data
+// type. The empty string parses as the absent POI.
+func ParsePOI(s string) (POI, error) {
+	poi := POI(s)
+	if s != "" && !poi.Valid() {
+		return "", fmt.Errorf("%w: point of initiation method %q must be a method (1–3) and a data type (1–2)", ErrInvalidFormat, s)
+	}
+	return poi, nil
+}
+
+// Valid reports whether poi is a known method followed by a known data
+// type.
+func (poi POI) Valid() bool {
+	return len(poi) == 2 && poi[0] >= '1' && poi[0] <= '3' && poi[1] >= '1' && poi[1] <= '2'
+}
+
+// IsStatic reports whether poi is a Valid POI for a QR code reused across
+// transactions.
+func (poi POI) IsStatic() bool {
+	return poi.Valid() && poi[1:] == POIDataTypeStatic
+}
+
+// IsDynamic reports whether poi is a Valid POI for a QR code generated per
+// transaction.
+func (poi POI) IsDynamic() bool {
+	return poi.Valid() && poi[1:] == POIDataTypeDynamic
+}
+
+// Method returns the method digit of poi, or "" if poi is not Valid.
+func (poi POI) Method() POIMethod {
+	if !poi.Valid() {
+		return ""
+	}
+	return POIMethod(poi[:1])
+}
+
+// String describes poi, e.g. "dynamic QR", or returns its value if it is
+// not Valid.
+func (poi POI) String() string {
+	switch {
+	case !poi.Valid():
+		return string(poi)
+	case poi.IsStatic():
+		return "static " + poi.Method().String()
+	default:
+		return "dynamic " + poi.Method().String()
+	}
+}
+
+// SetPOI sets the Point of Initiation Method, failing with an error
+// wrapping ErrInvalidFormat if poi is neither empty nor Valid.
+func (p *Payload) SetPOI(poi POI) error {
+	if _, err := ParsePOI(string(poi)); err != nil {
+		return err
+	}
+	p.PointOfInitiationMethod = poi
+	return nil
+}
@@ -0,0 +1,79 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPOI(t *testing.T) {
+	for _, tt := range []struct {
+		poi              POI
+		valid            bool
+		static, dynamic  bool
+		method           POIMethod
+		str, methodLabel string
+	}{
+		{POIStaticQR, true, true, false, POIMethodQR, "static QR", "QR"},
+		{POIDynamicQR, true, false, true, POIMethodQR, "dynamic QR", "QR"},
+		{POIStaticBLE, true, true, false, POIMethodBLE, "static BLE", "BLE"},
+		{POIDynamicNFC, true, false, true, POIMethodNFC, "dynamic NFC", "NFC"},
+		{"", false, false, false, "", "", ""},
+		{"13", false, false, false, "", "13", ""},
+		{"41", false, false, false, "", "41", ""},
+		{"1", false, false, false, "", "1", ""},
+	} {
+		if got := tt.poi.Valid(); got != tt.valid {
+			t.Errorf("%q.Valid() = %v", string(tt.poi), got)
+		}
+		if tt.poi.IsStatic() != tt.static || tt.poi.IsDynamic() != tt.dynamic {
+			t.Errorf("%q: IsStatic %v, IsDynamic %v", string(tt.poi), tt.poi.IsStatic(), tt.poi.IsDynamic())
+		}
+		if got := tt.poi.Method(); got != tt.method {
+			t.Errorf("%q.Method() = %q, want %q", string(tt.poi), string(got), string(tt.method))
+		}
+		assertEqual(t, "String", tt.str, tt.poi.String())
+		assertEqual(t, "Method().String", tt.methodLabel, tt.poi.Method().String())
+	}
+}
+
+func TestParsePOI(t *testing.T) {
+	if poi, err := ParsePOI("12"); err != nil || poi != POIDynamicQR {
+		t.Errorf(`ParsePOI("12") = %q, %v`, string(poi), err)
+	}
+	if poi, err := ParsePOI(""); err != nil || poi != "" {
+		t.Errorf(`ParsePOI("") = %q, %v`, string(poi), err)
+	}
+	for _, s := range []string{"10", "42", "1", "123", "AB"} {
+		if _, err := ParsePOI(s); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("ParsePOI(%q) error = %v, want ErrInvalidFormat", s, err)
+		}
+	}
+}
+
+func TestPOI_DecodeEncode(t *testing.T) {
+	p := basePayload()
+	if err := p.SetPOI("13"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("SetPOI(13) error = %v, want ErrInvalidFormat", err)
+	}
+	if err := p.SetPOI(POIDynamicNFC); err != nil {
+		t.Fatalf("SetPOI: %v", err)
+	}
+	got := mustDecode(t, mustEncode(t, p))
+	if got.PointOfInitiationMethod != POIDynamicNFC || got.PointOfInitiationMethod.Method() != POIMethodNFC {
+		t.Errorf("decoded POI = %q", string(got.PointOfInitiationMethod))
+	}
+
+	p.PointOfInitiationMethod = "19"
+	if _, err := Encode(p); ErrorCode(err) != CodeInvalidFormat {
+		t.Errorf("Encode with POI 19: error %v, want %s", err, CodeInvalidFormat)
+	}
+	raw, err := EncodeWithOptions(basePayload(), EncodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := raw[:6] + "010219" + raw[6:len(raw)-8]
+	var pe *ParseError
+	if _, err := DecodeWithOptions(bad, DecodeOptions{SkipCRCValidation: true}); !errors.As(err, &pe) || pe.ID != IDPointOfInitiationMethod {
+		t.Errorf("Decode with POI 19: error %v, want a *ParseError for tag 01", err)
+	}
+}
@@ -0,0 +1,199 @@
+package emvqr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PolicyKind is the kind of identifier a Policy list holds.
+type PolicyKind string
+
+// Identifier kinds checked by CheckPolicy.
+const (
+	// PolicyVPA matches the UPI VPA (Tag 26.01), ignoring case.
+	PolicyVPA PolicyKind = "vpa"
+	// PolicyAccount matches primitive merchant identifiers (Tags 02–25),
+	// such as card scheme merchant PANs, ignoring spaces and hyphens.
+	PolicyAccount PolicyKind = "account"
+	// PolicyGUID matches the Globally Unique Identifiers of merchant
+	// account, payment system and unreserved templates, ignoring case.
+	PolicyGUID PolicyKind = "guid"
+)
+
+// Policy holds blocklists and allowlists of merchant identifiers, e.g. the
+// VPAs of scam QR stickers reported to NPCI. An identifier on a blocklist
+// matches unless it is also allowlisted; when a kind has an allowlist, every
+// identifier of that kind not on it matches too.
+//
+// A Policy is safe for concurrent use, so lists can be reloaded while
+// payments are being checked.
+type Policy struct {
+	mu    sync.RWMutex
+	block map[PolicyKind]map[string]bool
+	allow map[PolicyKind]map[string]bool
+}
+
+// PolicyMatch is an identifier of a payload that a Policy rejects.
+type PolicyMatch struct {
+	Kind    PolicyKind
+	Path    string // dotted tag path, e.g. "26.01"
+	Value   string
+	Blocked bool // on a blocklist, rather than missing from an allowlist
+}
+
+func (m PolicyMatch) String() string {
+	if m.Blocked {
+		return fmt.Sprintf("%s %q (tag %s) is blocklisted", m.Kind, m.Value, m.Path)
+	}
+	return fmt.Sprintf("%s %q (tag %s) is not allowlisted", m.Kind, m.Value, m.Path)
+}
+
+// NewPolicy returns an empty Policy, which matches nothing.
+func NewPolicy() *Policy {
+	return &Policy{
+		block: make(map[PolicyKind]map[string]bool),
+		allow: make(map[PolicyKind]map[string]bool),
+	}
+}
+
+// Block adds values to the blocklist of kind.
+func (pol *Policy) Block(kind PolicyKind, values ...string) {
+	pol.add(pol.block, kind, values)
+}
+
+// Allow adds values to the allowlist of kind.
+func (pol *Policy) Allow(kind PolicyKind, values ...string) {
+	pol.add(pol.allow, kind, values)
+}
+
+// LoadBlocklist reads one value per line into the blocklist of kind. Blank
+// lines and lines starting with '#' are skipped.
+func (pol *Policy) LoadBlocklist(kind PolicyKind, r io.Reader) error {
+	values, err := readPolicyList(r)
+	if err != nil {
+		return err
+	}
+	pol.Block(kind, values...)
+	return nil
+}
+
+// LoadAllowlist reads one value per line into the allowlist of kind, like
+// LoadBlocklist.
+func (pol *Policy) LoadAllowlist(kind PolicyKind, r io.Reader) error {
+	values, err := readPolicyList(r)
+	if err != nil {
+		return err
+	}
+	pol.Allow(kind, values...)
+	return nil
+}
+
+func (pol *Policy) add(lists map[PolicyKind]map[string]bool, kind PolicyKind, values []string) {
+	pol.mu.Lock()
+	defer pol.mu.Unlock()
+	if lists[kind] == nil {
+		lists[kind] = make(map[string]bool, len(values))
+	}
+	for _, v := range values {
+		if v = normalizePolicyValue(kind, v); v != "" {
+			lists[kind][v] = true
+		}
+	}
+}
+
+// readPolicyList reads the values of a list file.
+func readPolicyList(r io.Reader) ([]string, error) {
+	var values []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			values = append(values, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("emvqr: reading policy list: %w", err)
+	}
+	return values, nil
+}
+
+// normalizePolicyValue puts v in the form lists are keyed by.
+func normalizePolicyValue(kind PolicyKind, v string) string {
+	v = strings.TrimSpace(v)
+	switch kind {
+	case PolicyVPA:
+		return strings.ToLower(v)
+	case PolicyAccount:
+		return strings.NewReplacer(" ", "", "-", "").Replace(v)
+	case PolicyGUID:
+		return strings.ToUpper(v)
+	}
+	return v
+}
+
+// CheckPolicy returns the identifiers of p that pol rejects, ordered by tag
+// path. A nil Policy matches nothing.
+func CheckPolicy(p *Payload, pol *Policy) []PolicyMatch {
+	if pol == nil {
+		return nil
+	}
+	pol.mu.RLock()
+	defer pol.mu.RUnlock()
+	var matches []PolicyMatch
+	for _, id := range policyIdentifiers(p) {
+		key := normalizePolicyValue(id.Kind, id.Value)
+		allowList := pol.allow[id.Kind]
+		switch {
+		case allowList[key]:
+		case pol.block[id.Kind][key]:
+			id.Blocked = true
+			matches = append(matches, id)
+		case len(allowList) > 0:
+			matches = append(matches, id)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Path < matches[j].Path })
+	return matches
+}
+
+// policyIdentifiers returns the identifiers of p that policies apply to.
+func policyIdentifiers(p *Payload) []PolicyMatch {
+	var ids []PolicyMatch
+	seen := make(map[string]bool)
+	add := func(kind PolicyKind, path, value string) {
+		if value != "" && !seen[path+"="+value] {
+			seen[path+"="+value] = true
+			ids = append(ids, PolicyMatch{Kind: kind, Path: path, Value: value})
+		}
+	}
+	add(PolicyVPA, IDUPIVPATemplate+".01", p.GetMerchantVPA())
+	if v := p.UPIVPAInfo; v != nil {
+		add(PolicyGUID, IDUPIVPATemplate+"."+MAIGloballyUniqueID, v.RuPayRID)
+	}
+	if r := p.UPITransactionRef; r != nil {
+		add(PolicyGUID, IDUPIVPAReference+"."+UPIVPARefRuPayRID, r.RuPayRID)
+	}
+	if a := p.MerchantAadhaar; a != nil {
+		add(PolicyGUID, IDAadhaarTemplate+"."+AadhaarRuPayRID, a.RuPayRID)
+	}
+	for _, mi := range p.MerchantIdentifiers {
+		if mi.IsTemplate() {
+			add(PolicyGUID, mi.ID+"."+MAIGloballyUniqueID, mi.GloballyUniqueID())
+		} else {
+			add(PolicyAccount, mi.ID, mi.Value)
+		}
+	}
+	if a := p.AdditionalData; a != nil {
+		for _, pst := range a.PaymentSystemTemplates {
+			add(PolicyGUID, IDAdditionalDataFieldTemplate+"."+pst.ID+"."+MAIGloballyUniqueID, pst.GloballyUniqueID)
+		}
+	}
+	for _, ut := range p.UnreservedTemplates {
+		add(PolicyGUID, ut.ID+"."+MAIGloballyUniqueID, ut.GloballyUniqueID)
+	}
+	return ids
+}
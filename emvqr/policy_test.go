@@ -0,0 +1,51 @@
+package emvqr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPolicy(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := CheckPolicy(p, NewPolicy()); len(m) != 0 {
+		t.Errorf("empty policy matched: %v", m)
+	}
+	if m := CheckPolicy(p, nil); m != nil {
+		t.Errorf("nil policy matched: %v", m)
+	}
+
+	pol := NewPolicy()
+	list := "# scam stickers reported 2024-11\n\nsbipmopad.02pl00000644432-21503961@sbipay\n"
+	if err := pol.LoadBlocklist(PolicyVPA, strings.NewReader(list)); err != nil {
+		t.Fatal(err)
+	}
+	pol.Block(PolicyAccount, "4585-1910 4104 4894")
+	matches := CheckPolicy(p, pol)
+	if len(matches) != 2 {
+		t.Fatalf("matches = %v", matches)
+	}
+	assertEqual(t, "first match", `account "4585191041044894" (tag 02) is blocklisted`, matches[0].String())
+	if matches[1].Kind != PolicyVPA || matches[1].Path != "26.01" || !matches[1].Blocked {
+		t.Errorf("second match = %+v", matches[1])
+	}
+
+	// Allowlisting overrides the blocklist; an allowlist rejects everything
+	// else of its kind.
+	pol.Allow(PolicyAccount, "4585191041044894", "545080003175565", "6100010031755635",
+		"SBIN000415243930804448", "310900031273986")
+	pol.Allow(PolicyGUID, "a000000524")
+	matches = CheckPolicy(p, pol)
+	if len(matches) != 1 || matches[0].Kind != PolicyVPA {
+		t.Fatalf("matches = %v", matches)
+	}
+
+	pol.Allow(PolicyGUID) // adds nothing
+	p.UnreservedTemplates = []UnreservedTemplate{{ID: "80", GloballyUniqueID: "com.scam.pay", SubFields: []DataObject{{ID: "01", Value: "x"}}}}
+	matches = CheckPolicy(p, pol)
+	if len(matches) != 2 || matches[1].Path != "80.00" || matches[1].Blocked {
+		t.Errorf("matches = %v", matches)
+	}
+}
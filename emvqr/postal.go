@@ -0,0 +1,95 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// postalFormats maps ISO 3166-1 alpha-2 country codes to the accepted
+// shapes of their postal codes. In a shape '9' stands for a digit, '1' for a
+// digit other than 0, 'A' for an upper-case letter and any other byte for
+// itself.
+var postalFormats = map[string][]string{
+	"AU": {"9999"},
+	"BR": {"99999-999", "99999999"},
+	"CA": {"A9A 9A9", "A9A9A9"},
+	"DE": {"99999"},
+	"FR": {"99999"},
+	"IN": {"199999"}, // PIN codes start with a zone digit, 1–8 (9 is the Army Postal Service)
+	"JP": {"999-9999", "9999999"},
+	"NL": {"9999 AA", "9999AA"},
+	"SG": {"999999"},
+	"US": {"99999", "99999-9999"},
+}
+
+// PostalCodeFormats returns the shapes ValidatePostalCode accepts for
+// country, e.g. ["99999" "99999-9999"] for "US", where '9' stands for a
+// digit, '1' for a digit other than 0 and 'A' for an upper-case letter.
+// Country codes are matched without regard to case. It returns nil for
+// countries without a known format.
+func PostalCodeFormats(country string) []string {
+	return append([]string(nil), postalFormats[strings.ToUpper(country)]...)
+}
+
+// ValidatePostalCode checks a Tag 61 postal code against the format of the
+// Tag 58 country: a 6-digit PIN code not starting with 0 for IN, a 5-digit
+// ZIP or ZIP+4 for US, "A9A 9A9" for CA, and so on. The country is matched
+// without regard to case. Codes of countries without a known format are
+// accepted. The error wraps ErrInvalidFormat.
+//
+// Decode and Encode do not call it, since Tag 61 is free text in EMV
+// QRCPS; Lint reports a mismatch as a warning and the "strict" profile as a
+// finding.
+func ValidatePostalCode(country, code string) error {
+	country = strings.ToUpper(country)
+	shapes, ok := postalFormats[country]
+	if !ok {
+		return nil
+	}
+	for _, shape := range shapes {
+		if matchPostalShape(shape, code) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: postal code %q does not match the format for %s (%s)",
+		ErrInvalidFormat, code, country, strings.Join(shapes, " or "))
+}
+
+// matchPostalShape reports whether code has the given shape.
+func matchPostalShape(shape, code string) bool {
+	if len(code) != len(shape) {
+		return false
+	}
+	for i := 0; i < len(shape); i++ {
+		c := code[i]
+		switch shape[i] {
+		case '9':
+			if c < '0' || c > '9' {
+				return false
+			}
+		case '1':
+			if c < '1' || c > '9' {
+				return false
+			}
+		case 'A':
+			if c < 'A' || c > 'Z' {
+				return false
+			}
+		default:
+			if c != shape[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValidatePostalCode checks p.PostalCode against the format of
+// p.CountryCode; see the package-level ValidatePostalCode. An empty postal
+// code is accepted.
+func (p *Payload) ValidatePostalCode() error {
+	if p.PostalCode == "" {
+		return nil
+	}
+	return ValidatePostalCode(p.CountryCode, p.PostalCode)
+}
@@ -0,0 +1,65 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidatePostalCode(t *testing.T) {
+	tests := []struct {
+		country, code string
+		ok            bool
+	}{
+		{"IN", "560001", true},
+		{"IN", "56001", false},
+		{"IN", "5600O1", false},
+		{"IN", "060001", false},
+		{"in", "560001", true},
+		{"in", "56001", false},
+		{"us", "1000", false},
+		{"US", "10001", true},
+		{"US", "10001-1234", true},
+		{"US", "1000", false},
+		{"CA", "K1A 0B1", true},
+		{"CA", "K1A0B1", true},
+		{"CA", "k1a 0b1", false},
+		{"NL", "1012 AB", true},
+		{"XX", "anything", true},
+		{"", "anything", true},
+	}
+	for _, tt := range tests {
+		err := ValidatePostalCode(tt.country, tt.code)
+		if tt.ok && err != nil {
+			t.Errorf("ValidatePostalCode(%q, %q) = %v", tt.country, tt.code, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("ValidatePostalCode(%q, %q) = %v, want ErrInvalidFormat", tt.country, tt.code, err)
+		}
+	}
+}
+
+func TestPayload_ValidatePostalCode(t *testing.T) {
+	p := basePayload()
+	if err := p.ValidatePostalCode(); err != nil {
+		t.Errorf("empty postal code: %v", err)
+	}
+	p.PostalCode = "ABC"
+	if err := p.ValidatePostalCode(); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ValidatePostalCode() = %v, want ErrInvalidFormat", err)
+	}
+	if got := PostalCodeFormats("US"); len(got) != 2 || got[0] != "99999" {
+		t.Errorf("PostalCodeFormats(US) = %q", got)
+	}
+	if got := PostalCodeFormats("in"); len(got) != 1 || got[0] != "199999" {
+		t.Errorf("PostalCodeFormats(in) = %q", got)
+	}
+}
+
+func TestLint_PostalCodeFormat(t *testing.T) {
+	p := basePayload()
+	p.PostalCode = "1234"
+	assertEqual(t, "checks", "postal-code-format@61", lintChecks(Lint(p)))
+	if _, err := Encode(p); err != nil {
+		t.Errorf("Encode() rejects a malformed postal code: %v", err)
+	}
+}
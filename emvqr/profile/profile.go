@@ -0,0 +1,384 @@
+// Package profile checks EMV QR Code payloads against scheme profiles: named
+// sets of rules layered on top of a successful decode.
+//
+// The "emvco" profile applies the EMV QRCPS MPM field formats and verifies
+// that the payload survives a decode and re-encode unchanged; "bharatqr"
+// adds the Bharat QR v4 requirements (country IN, currency 356, postal code,
+// an Indian merchant identifier and the RuPay RID in Tags 26–28); "strict"
+// adds country-specific formats, such as 6-digit PIN codes in India, that
+// Lint otherwise only reports as warnings.
+//
+// Further profiles check QRs of one use case, so that batch.Encode with
+// them generates only QRs wallets can act on: "biller" adds the bill-fetch
+// rules of government and utility billers (see emvqr.ValidateBillFetch),
+// "donation" those of charities (see emvqr.ValidateDonation) and
+// "transit" those of metro and rail gates (see emvqr.ValidateTransit);
+// "p2p" checks person-to-person QRs, which "emvco" rejects for their
+// missing MCC and city, against emvqr.ValidatePersonToPerson instead.
+//
+// Example:
+//
+//	pr, _ := profile.Lookup("bharatqr")
+//	if findings := pr.Check(p, raw); len(findings) > 0 {
+//	    // report findings
+//	}
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// Check inspects a decoded payload and the raw string it was decoded from,
+// and returns human-readable findings; none means the payload conforms.
+type Check func(p *emvqr.Payload, raw string) []string
+
+// Profile is a named set of checks.
+type Profile struct {
+	Name    string
+	Summary string
+	Checks  []Check
+
+	// Normalize, if set, is applied to payloads before they are encoded
+	// under the profile, e.g. by batch.Encode; see emvqr.Payload.Normalize.
+	Normalize *emvqr.NormalizeOptions
+}
+
+// Check runs every check of pr and returns their findings in order.
+func (pr Profile) Check(p *emvqr.Payload, raw string) []string {
+	var findings []string
+	for _, check := range pr.Checks {
+		findings = append(findings, check(p, raw)...)
+	}
+	return findings
+}
+
+// Default is the name of the profile used when none is given.
+const Default = "emvco"
+
+var profiles = []Profile{
+	{
+		Name:      "emvco",
+		Summary:   "EMV QRCPS MPM field formats and round-trip encoding",
+		Checks:    []Check{checkRoundTrip, checkFormats},
+		Normalize: &emvqr.NormalizeOptions{},
+	},
+	{
+		Name:      "bharatqr",
+		Summary:   "emvco plus the Bharat QR v4 requirements",
+		Checks:    []Check{checkRoundTrip, checkFormats, checkBharatQR},
+		Normalize: &emvqr.NormalizeOptions{},
+	},
+	{
+		Name:      "strict",
+		Summary:   "emvco plus the Common Character Set, country-specific formats such as postal codes, and country, currency and scheme consistency",
+		Checks:    []Check{checkRoundTrip, checkFormats, checkCharset, checkCountryFormats, checkConsistency},
+		Normalize: &emvqr.NormalizeOptions{},
+	},
+	{
+		Name:      "biller",
+		Summary:   "emvco plus the bill-fetch rules for utility billers: a biller ID, a static QR, no amount and a Reference Label prompt",
+		Checks:    []Check{checkRoundTrip, checkFormats, checkBillFetch},
+		Normalize: &emvqr.NormalizeOptions{},
+	},
+	{
+		Name:      "donation",
+		Summary:   "emvco plus the donation rules: a static QR of a charitable or religious MCC, no amount, a purpose and valid suggested amounts",
+		Checks:    []Check{checkRoundTrip, checkFormats, checkDonation},
+		Normalize: &emvqr.NormalizeOptions{},
+	},
+	{
+		Name:      "transit",
+		Summary:   "emvco plus the transit rules: a transit MCC, a gate in the Terminal Label, no amount in static gate QRs and a payload short enough for turnstile scanners",
+		Checks:    []Check{checkRoundTrip, checkFormats, checkTransit},
+		Normalize: &emvqr.NormalizeOptions{},
+	},
+	{
+		Name:      "p2p",
+		Summary:   "round-trip encoding plus the person-to-person rules: a payee name and UPI VPA, MCC 0000 or none, and no merchant accounts or fees",
+		Checks:    []Check{checkRoundTrip, checkPersonToPerson},
+		Normalize: &emvqr.NormalizeOptions{},
+	},
+}
+
+// Register adds pr to the profiles Lookup finds, such as an acquirer's
+// profile that upper-cases merchant names. It returns an error if pr has no
+// name or a profile of that name exists. Register is not safe for use
+// concurrently with Lookup; call it during initialization.
+func Register(pr Profile) error {
+	if pr.Name == "" {
+		return errors.New("profile: empty profile name")
+	}
+	if _, ok := Lookup(pr.Name); ok {
+		return fmt.Errorf("profile: profile %q already registered", pr.Name)
+	}
+	profiles = append(profiles, pr)
+	return nil
+}
+
+// All returns the built-in and registered profiles.
+func All() []Profile {
+	return append([]Profile(nil), profiles...)
+}
+
+// Names returns the names of the built-in and registered profiles.
+func Names() []string {
+	names := make([]string, len(profiles))
+	for i, pr := range profiles {
+		names[i] = pr.Name
+	}
+	return names
+}
+
+// Lookup returns the built-in or registered profile with the given name.
+func Lookup(name string) (Profile, bool) {
+	for _, pr := range profiles {
+		if pr.Name == name {
+			return pr, true
+		}
+	}
+	return Profile{}, false
+}
+
+// checkRoundTrip re-encodes the decoded payload and compares it with the
+// input, catching fields the library would drop or reorder. With an empty
+// raw string it only checks that the payload can be encoded.
+func checkRoundTrip(p *emvqr.Payload, raw string) []string {
+	out, err := emvqr.Encode(p)
+	if err != nil {
+		return []string{fmt.Sprintf("payload cannot be re-encoded: %v", err)}
+	}
+	want, err := emvqr.ParseTLVTree(raw)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	got, err := emvqr.ParseTLVTree(out)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	// Tag order is not significant apart from 00 first and 63 last, so
+	// compare the sets of top-level objects.
+	seen := make(map[string]int)
+	for _, n := range want {
+		seen[n.ID+"="+n.Value]++
+	}
+	for _, n := range got {
+		seen[n.ID+"="+n.Value]--
+	}
+	var findings []string
+	for _, n := range want {
+		if n.ID != emvqr.IDCRC && seen[n.ID+"="+n.Value] > 0 {
+			findings = append(findings, fmt.Sprintf("tag %s is not preserved when re-encoding", n.ID))
+		}
+	}
+	if len(want) > 0 && want[0].ID != emvqr.IDPayloadFormatIndicator {
+		findings = append(findings, "tag 00 (Payload Format Indicator) must be the first data object")
+	}
+	return findings
+}
+
+// checkFormats applies the EMV QRCPS format and length rules to the fields
+// the decoder accepts verbatim.
+func checkFormats(p *emvqr.Payload, _ string) []string {
+	var findings []string
+	add := func(format string, args ...any) { findings = append(findings, fmt.Sprintf(format, args...)) }
+
+	if p.PayloadFormatIndicator == "" {
+		add("tag 00 (Payload Format Indicator) is missing")
+	}
+	switch p.PointOfInitiationMethod {
+	case "", emvqr.POIStaticQR, emvqr.POIDynamicQR, emvqr.POIStaticBLE, emvqr.POIDynamicBLE, emvqr.POIStaticNFC, emvqr.POIDynamicNFC:
+	default:
+		add("tag 01 (Point of Initiation Method) %q is not a known method and data type", p.PointOfInitiationMethod)
+	}
+	if !isDigits(p.MerchantCategoryCode, 4, 4) {
+		add("tag 52 (Merchant Category Code) must be 4 digits, got %q", p.MerchantCategoryCode)
+	}
+	if !isDigits(p.TransactionCurrency, 3, 3) {
+		add("tag 53 (Transaction Currency) must be 3 digits, got %q", p.TransactionCurrency)
+	}
+	if p.TransactionAmount != "" && !isAmount(p.TransactionAmount, 13) {
+		add("tag 54 (Transaction Amount) is not a valid amount: %q", p.TransactionAmount)
+	}
+	switch p.TipOrConvenienceIndicator {
+	case emvqr.TipIndicatorFixedConvenienceFee:
+		if !isAmount(p.ValueConvenienceFeeFixed, 13) {
+			add("tag 56 (Convenience Fee Fixed) is required with tag 55 = 02 and must be an amount")
+		}
+	case emvqr.TipIndicatorPercentageFee:
+		if !isAmount(p.ValueConvenienceFeePercent, 5) {
+			add("tag 57 (Convenience Fee Percentage) is required with tag 55 = 03 and must be a percentage")
+		}
+	}
+	if len(p.CountryCode) != 2 {
+		add("tag 58 (Country Code) must be 2 characters, got %q", p.CountryCode)
+	}
+	if len(p.MerchantName) > 25 {
+		add("tag 59 (Merchant Name) exceeds 25 characters")
+	}
+	if len(p.MerchantCity) > 15 {
+		add("tag 60 (Merchant City) exceeds 15 characters")
+	}
+	if len(p.PostalCode) > 10 {
+		add("tag 61 (Postal Code) exceeds 10 characters")
+	}
+	return findings
+}
+
+// checkCharset reports fields with characters outside the EMV Common
+// Character Set, as Lint does.
+func checkCharset(p *emvqr.Payload, _ string) []string {
+	var findings []string
+	for _, w := range emvqr.Lint(p) {
+		if w.Check == emvqr.LintCommonCharset {
+			findings = append(findings, w.Message)
+		}
+	}
+	return findings
+}
+
+// checkCountryFormats applies the formats that depend on the Tag 58 country.
+func checkCountryFormats(p *emvqr.Payload, _ string) []string {
+	var findings []string
+	if err := p.ValidatePostalCode(); err != nil {
+		findings = append(findings, fmt.Sprintf("tag 61 (Postal Code) %q does not match the format for %s",
+			p.PostalCode, p.CountryCode))
+	}
+	return findings
+}
+
+// checkConsistency reports the country, currency and scheme mismatches of
+// emvqr.CheckConsistency.
+func checkConsistency(p *emvqr.Payload, _ string) []string {
+	var findings []string
+	for _, w := range emvqr.CheckConsistency(p) {
+		findings = append(findings, w.Message)
+	}
+	return findings
+}
+
+// checkBillFetch reports the violations of emvqr.ValidateBillFetch.
+func checkBillFetch(p *emvqr.Payload, _ string) []string {
+	return errorFindings(emvqr.ValidateBillFetch(p))
+}
+
+// checkDonation reports the violations of emvqr.ValidateDonation.
+func checkDonation(p *emvqr.Payload, _ string) []string {
+	return errorFindings(emvqr.ValidateDonation(p))
+}
+
+// checkTransit reports the violations of emvqr.ValidateTransit, with the
+// default payload budget.
+func checkTransit(p *emvqr.Payload, _ string) []string {
+	return errorFindings(emvqr.ValidateTransit(p, emvqr.TransitOptions{}))
+}
+
+// checkPersonToPerson reports the violations of
+// emvqr.ValidatePersonToPerson.
+func checkPersonToPerson(p *emvqr.Payload, _ string) []string {
+	return errorFindings(emvqr.ValidatePersonToPerson(p))
+}
+
+// errorFindings returns the messages of err, one per joined error.
+func errorFindings(err error) []string {
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	findings := make([]string, len(errs))
+	for i, e := range errs {
+		findings[i] = e.Error()
+	}
+	return findings
+}
+
+// checkBharatQR applies the Bharat QR additions described in
+// BHARAT_QR_TAGS.md.
+func checkBharatQR(p *emvqr.Payload, _ string) []string {
+	var findings []string
+	add := func(format string, args ...any) { findings = append(findings, fmt.Sprintf(format, args...)) }
+
+	if p.PointOfInitiationMethod == "" {
+		add("tag 01 (Point of Initiation Method) is mandatory")
+	}
+	if p.CountryCode != "IN" {
+		add("tag 58 (Country Code) must be IN, got %q", p.CountryCode)
+	}
+	if p.TransactionCurrency != "356" {
+		add("tag 53 (Transaction Currency) must be 356 (INR), got %q", p.TransactionCurrency)
+	}
+	if len(p.MerchantName) > 23 {
+		add("tag 59 (Merchant Name) exceeds the Bharat QR limit of 23 characters")
+	}
+	if p.PostalCode == "" {
+		add("tag 61 (Postal Code) is mandatory")
+	}
+	hasIndian := p.UPIVPAInfo != nil || p.MerchantAadhaar != nil
+	for _, mi := range p.MerchantIdentifiers {
+		if mi.ID == "06" || mi.ID == "08" {
+			hasIndian = true
+		}
+	}
+	if !hasIndian {
+		add("one of tag 06 (NPCI merchant PAN), 08 (IFSC + account), 26 (UPI VPA) or 28 (Aadhaar) is required")
+	}
+	if v := p.UPIVPAInfo; v != nil {
+		if v.RuPayRID != emvqr.RuPayRIDValue {
+			add("tag 26-00 (RuPay RID) must be %s, got %q", emvqr.RuPayRIDValue, v.RuPayRID)
+		}
+		if v.MinimumAmount != "" && p.PointOfInitiationMethod != emvqr.POIDynamicQR {
+			add("tag 26-02 (Minimum Amount) is only allowed in dynamic QRs (tag 01 = 12)")
+		}
+		if err := p.ValidateAmountAgainstMinimum(); errors.Is(err, emvqr.ErrBelowMinimum) {
+			add("tag 54 (Transaction Amount) %s is less than the tag 26-02 minimum %s", p.TransactionAmount, v.MinimumAmount)
+		}
+	}
+	if r := p.UPITransactionRef; r != nil {
+		if r.RuPayRID != emvqr.RuPayRIDValue {
+			add("tag 27-00 (RuPay RID) must be %s, got %q", emvqr.RuPayRIDValue, r.RuPayRID)
+		}
+		if n := len(r.TransactionRef); n < 4 || n > 35 {
+			add("tag 27-01 (Transaction Reference) must be 4–35 characters, got %d", n)
+		}
+		if len(r.ReferenceURL) > 26 {
+			add("tag 27-02 (Reference URL) exceeds 26 characters")
+		}
+		if !p.ReferencesConsistent() {
+			add("tag 27-01 (Transaction Reference) %q differs from tag 62-05 (Reference Label) %q",
+				r.TransactionRef, p.AdditionalData.ReferenceLabel)
+		}
+	}
+	if a := p.MerchantAadhaar; a != nil && !isDigits(a.AadhaarNumber, 12, 12) {
+		add("tag 28-01 (Aadhaar Number) must be 12 digits")
+	}
+	return findings
+}
+
+// isDigits reports whether s is between lo and hi ASCII digits long.
+func isDigits(s string, lo, hi int) bool {
+	if len(s) < lo || len(s) > hi {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAmount reports whether s is a decimal amount of at most maxLen
+// characters: digits with an optional single '.'.
+func isAmount(s string, maxLen int) bool {
+	if s == "" || s == "." || len(s) > maxLen {
+		return false
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	return isDigits(whole, 0, maxLen) && isDigits(frac, 0, maxLen)
+}
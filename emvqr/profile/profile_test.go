@@ -0,0 +1,230 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+const staticQR = "000201021640001234567890125204525153038405802US5911ABC Hammers6008New York63047222"
+
+func TestCheck_EMVCo(t *testing.T) {
+	p, err := emvqr.Decode(staticQR)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	pr, ok := Lookup(Default)
+	if !ok {
+		t.Fatalf("Lookup(%q) failed", Default)
+	}
+	if findings := pr.Check(p, staticQR); len(findings) != 0 {
+		t.Errorf("Check() = %q, want no findings", findings)
+	}
+
+	p.MerchantCategoryCode = "52A1"
+	if findings := pr.Check(p, staticQR); len(findings) == 0 || !strings.Contains(findings[0], "tag 52") {
+		t.Errorf("Check() = %q, want a tag 52 finding", findings)
+	}
+}
+
+func TestCheck_BharatQR(t *testing.T) {
+	p, _ := emvqr.Decode(staticQR)
+	pr, _ := Lookup("bharatqr")
+	findings := strings.Join(pr.Check(p, staticQR), "\n")
+	for _, want := range []string{"tag 58 (Country Code) must be IN", "tag 61 (Postal Code) is mandatory", "tag 06"} {
+		if !strings.Contains(findings, want) {
+			t.Errorf("Check() missing %q:\n%s", want, findings)
+		}
+	}
+}
+
+func TestCheck_BharatQRMinimumAmount(t *testing.T) {
+	p, _ := emvqr.Decode(staticQR)
+	p.PointOfInitiationMethod = emvqr.POIDynamicQR
+	p.TransactionAmount = "49.5"
+	_ = p.SetUPIVPATemplate(emvqr.RuPayRIDValue, "shop@upi", "50.00")
+	pr, _ := Lookup("bharatqr")
+	const want = "tag 54 (Transaction Amount) 49.5 is less than the tag 26-02 minimum 50.00"
+	if findings := strings.Join(pr.Check(p, ""), "\n"); !strings.Contains(findings, want) {
+		t.Errorf("Check() missing %q:\n%s", want, findings)
+	}
+	p.TransactionAmount = "50"
+	if findings := strings.Join(pr.Check(p, ""), "\n"); strings.Contains(findings, "tag 54") {
+		t.Errorf("Check() reports an amount equal to the minimum:\n%s", findings)
+	}
+}
+
+func TestCheck_BharatQRReferenceMismatch(t *testing.T) {
+	p, _ := emvqr.Decode(staticQR)
+	_ = p.SetTransactionReference("ORDER-1")
+	p.AdditionalData.ReferenceLabel = "ORDER-2"
+	pr, _ := Lookup("bharatqr")
+	const want = `tag 27-01 (Transaction Reference) "ORDER-1" differs from tag 62-05 (Reference Label) "ORDER-2"`
+	if findings := strings.Join(pr.Check(p, ""), "\n"); !strings.Contains(findings, want) {
+		t.Errorf("Check() missing %q:\n%s", want, findings)
+	}
+}
+
+func TestCheck_StrictPostalCode(t *testing.T) {
+	p, _ := emvqr.Decode(staticQR)
+	pr, _ := Lookup("strict")
+	if findings := pr.Check(p, ""); len(findings) != 0 {
+		t.Errorf("Check() = %q, want no findings", findings)
+	}
+	p.PostalCode = "1234"
+	const want = `tag 61 (Postal Code) "1234" does not match the format for US`
+	if findings := strings.Join(pr.Check(p, ""), "\n"); !strings.Contains(findings, want) {
+		t.Errorf("Check() missing %q:\n%s", want, findings)
+	}
+	emvco, _ := Lookup(Default)
+	if findings := emvco.Check(p, ""); len(findings) != 0 {
+		t.Errorf("emvco Check() = %q, want no findings", findings)
+	}
+}
+
+func TestCheck_StrictCharset(t *testing.T) {
+	p, _ := emvqr.Decode(staticQR)
+	p.MerchantName = "Café 🎉"
+	pr, _ := Lookup("strict")
+	const want = "Tag 59 contains the non-ASCII character 'é' (U+00E9), outside the Common Character Set"
+	if findings := strings.Join(pr.Check(p, ""), "\n"); !strings.Contains(findings, want) {
+		t.Errorf("Check() missing %q:\n%s", want, findings)
+	}
+}
+
+func TestCheck_Biller(t *testing.T) {
+	base, _ := emvqr.Decode(staticQR)
+	p, err := emvqr.NewBillerPayload(base, emvqr.Biller{ID: "BESCOM000KAR01", Category: "Electricity"})
+	if err != nil {
+		t.Fatalf("NewBillerPayload() error: %v", err)
+	}
+	raw, err := emvqr.Encode(p)
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	pr, _ := Lookup("biller")
+	if findings := pr.Check(p, raw); len(findings) != 0 {
+		t.Errorf("Check() = %q, want no findings", findings)
+	}
+
+	p.TransactionAmount = "100"
+	p.AdditionalData.ReferenceLabel = "CA-1234"
+	findings := strings.Join(pr.Check(p, ""), "\n")
+	for _, want := range []string{"Tag 54 amount must be absent", "Tag 62.05 Reference Label"} {
+		if !strings.Contains(findings, want) {
+			t.Errorf("Check() missing %q:\n%s", want, findings)
+		}
+	}
+	if findings := pr.Check(base, staticQR); len(findings) != 3 {
+		t.Errorf("Check() of a payload without a biller = %q, want 3 findings", findings)
+	}
+}
+
+func TestCheck_Donation(t *testing.T) {
+	base, _ := emvqr.Decode(staticQR)
+	base.MerchantCategoryCode = emvqr.MCCCharitable
+	p, err := emvqr.NewDonationPayload(base, emvqr.DonationOptions{SuggestedAmounts: []string{"10", "25"}})
+	if err != nil {
+		t.Fatalf("NewDonationPayload() error: %v", err)
+	}
+	pr, _ := Lookup("donation")
+	if findings := pr.Check(p, ""); len(findings) != 0 {
+		t.Errorf("Check() = %q, want no findings", findings)
+	}
+	p.MerchantCategoryCode = "5411"
+	if findings := strings.Join(pr.Check(p, ""), "\n"); !strings.Contains(findings, "Tag 52 MCC") {
+		t.Errorf("Check() missing the MCC finding:\n%s", findings)
+	}
+}
+
+func TestCheck_Transit(t *testing.T) {
+	base, _ := emvqr.Decode(staticQR)
+	base.MerchantCategoryCode = emvqr.MCCCommuterTransport
+	p, err := emvqr.NewTransitGatePayload(base, "GATE-04")
+	if err != nil {
+		t.Fatalf("NewTransitGatePayload() error: %v", err)
+	}
+	pr, _ := Lookup("transit")
+	if findings := pr.Check(p, ""); len(findings) != 0 {
+		t.Errorf("Check() = %q, want no findings", findings)
+	}
+	p.MerchantName = strings.Repeat("N", 25)
+	p.SetAdditionalData(func(a *emvqr.AdditionalDataField) { a.StoreLabel = strings.Repeat("S", 40) })
+	if findings := strings.Join(pr.Check(p, ""), "\n"); !strings.Contains(findings, "over the budget") {
+		t.Errorf("Check() missing the length finding:\n%s", findings)
+	}
+}
+
+func TestCheck_PersonToPerson(t *testing.T) {
+	// A UPI app's P2P QR without Tags 52 and 60.
+	const p2pQR = "00020101021126290010A0000005240111ravi@okbank53033565802IN5910Ravi Kumar6304AC4D"
+	p, err := emvqr.Decode(p2pQR)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	pr, _ := Lookup("p2p")
+	if findings := pr.Check(p, p2pQR); len(findings) != 0 {
+		t.Errorf("Check() = %q, want no findings", findings)
+	}
+	if findings := pr.Check(p, ""); len(findings) != 0 {
+		t.Errorf("Check() without raw = %q, want no findings", findings)
+	}
+
+	merchant, _ := emvqr.Decode(staticQR)
+	findings := strings.Join(pr.Check(merchant, staticQR), "\n")
+	for _, want := range []string{"Tag 52 MCC", "Tag 26", "Tag 02 merchant account"} {
+		if !strings.Contains(findings, want) {
+			t.Errorf("Check() missing %q:\n%s", want, findings)
+		}
+	}
+}
+
+func TestLookup_Unknown(t *testing.T) {
+	if _, ok := Lookup("nope"); ok {
+		t.Error("Lookup(\"nope\") succeeded")
+	}
+	if got := strings.Join(Names(), ","); got != "emvco,bharatqr,strict,biller,donation,transit,p2p" {
+		t.Errorf("Names() = %q", got)
+	}
+}
+
+// hitachiQR is the real-world Bharat QR sample of the emvqr tests.
+const hitachiQR = "000201010212021645851910410448940415545080003175565061661000100317556350822SBIN000415243930804448111531090003127398626590010A0000005240141SBIPMOPAD.02PL00000644432-21503961@SBIPAY27770010A0000005240123526020914454520875696090232https://www.hitachi-payments.com28180010A00000052401005204544153033565406250.005802IN5923APRIL MOON RETAIL PRIVA6009AHMEDABAD61063800036258031502PL00000644432052352602091445452087569609070821503961630451DD"
+
+func BenchmarkCheck(b *testing.B) {
+	p, err := emvqr.Decode(hitachiQR)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, pr := range All() {
+		b.Run(pr.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				pr.Check(p, hitachiQR)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func(saved []Profile) { profiles = saved }(profiles)
+
+	acme := Profile{
+		Name:      "acme",
+		Checks:    []Check{checkRoundTrip},
+		Normalize: &emvqr.NormalizeOptions{Uppercase: true},
+	}
+	if err := Register(acme); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+	if pr, ok := Lookup("acme"); !ok || !pr.Normalize.Uppercase {
+		t.Errorf("Lookup(\"acme\") = %+v, %v", pr, ok)
+	}
+	if err := Register(acme); err == nil {
+		t.Error("Register() of a duplicate name expected error, got nil")
+	}
+	if err := Register(Profile{}); err == nil {
+		t.Error("Register() of an unnamed profile expected error, got nil")
+	}
+}
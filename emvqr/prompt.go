@@ -0,0 +1,53 @@
+package emvqr
+
+// FieldID identifies a data object by its dotted tag path, as produced by
+// Flatten, e.g. "62.01" for the Bill Number.
+type FieldID string
+
+// Name returns the specification name of the field, e.g. "Bill Number".
+func (f FieldID) Name() string { return pathSpec(string(f)).name }
+
+// promptableADFFields are the Additional Data Field sub-fields that may hold
+// PromptValue, in ID order.
+var promptableADFFields = []struct {
+	id    string
+	value func(*AdditionalDataField) string
+}{
+	{ADFBillNumber, func(a *AdditionalDataField) string { return a.BillNumber }},
+	{ADFMobileNumber, func(a *AdditionalDataField) string { return a.MobileNumber }},
+	{ADFStoreLabel, func(a *AdditionalDataField) string { return a.StoreLabel }},
+	{ADFLoyaltyNumber, func(a *AdditionalDataField) string { return a.LoyaltyNumber }},
+	{ADFReferenceLabel, func(a *AdditionalDataField) string { return a.ReferenceLabel }},
+	{ADFCustomerLabel, func(a *AdditionalDataField) string { return a.CustomerLabel }},
+	{ADFTerminalLabel, func(a *AdditionalDataField) string { return a.TerminalLabel }},
+	{ADFPurposeOfTransaction, func(a *AdditionalDataField) string { return a.PurposeOfTransaction }},
+}
+
+// PromptedFields returns the Additional Data Field sub-fields set to
+// PromptValue, for which the consumer QR application must ask the consumer,
+// in ID order, e.g. ["62.01" "62.06"] for a bill number and customer label.
+// A wallet can render one input per field, labelled with FieldID.Name.
+// LoyaltyNumberRequired and MobileNumberRequired test single fields.
+func (p *Payload) PromptedFields() []FieldID {
+	a := p.AdditionalData
+	if a == nil {
+		return nil
+	}
+	var fields []FieldID
+	for _, f := range promptableADFFields {
+		if f.value(a) == PromptValue {
+			fields = append(fields, FieldID(IDAdditionalDataFieldTemplate+"."+f.id))
+		}
+	}
+	return fields
+}
+
+// IsPrompted reports whether the field is set to PromptValue.
+func (p *Payload) IsPrompted(f FieldID) bool {
+	for _, prompted := range p.PromptedFields() {
+		if prompted == f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package emvqr
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPromptedFields(t *testing.T) {
+	p := basePayload()
+	if got := p.PromptedFields(); got != nil {
+		t.Errorf("no additional data: %v", got)
+	}
+	p.SetAdditionalData(func(a *AdditionalDataField) {
+		a.BillNumber = PromptValue
+		a.StoreLabel = "Store 1"
+		a.CustomerLabel = PromptValue
+		a.PurposeOfTransaction = PromptValue
+	})
+	got := p.PromptedFields()
+	assertEqual(t, "fields", "[62.01 62.06 62.08]", fmt.Sprint(got))
+	assertEqual(t, "name", "Customer Label", got[1].Name())
+	if !p.IsPrompted("62.06") || p.IsPrompted("62.03") {
+		t.Error("IsPrompted disagrees with PromptedFields")
+	}
+}
@@ -0,0 +1,84 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// upiPurposeDescriptions describes the NPCI purpose codes for display.
+var upiPurposeDescriptions = map[UPIPurpose]string{
+	UPIPurposeDefault:     "Default",
+	UPIPurposeSEBI:        "Securities market (SEBI), including IPO applications",
+	UPIPurposeAMC:         "Mutual fund (AMC) subscriptions",
+	UPIPurposeTravel:      "Travel",
+	UPIPurposeHospitality: "Hospitality",
+	UPIPurposeHospital:    "Hospital and medical services",
+	UPIPurposeTelecom:     "Telecom",
+	UPIPurposeInsurance:   "Insurance",
+	UPIPurposeEducation:   "Education",
+	UPIPurposeGifting:     "Gifting",
+	UPIPurposeOthers:      "Others",
+}
+
+// Valid reports whether c is an NPCI purpose code, built in or added with
+// RegisterUPIPurpose.
+func (c UPIPurpose) Valid() bool {
+	upiMu.RLock()
+	defer upiMu.RUnlock()
+	_, ok := upiPurposes[c]
+	return ok
+}
+
+// Description returns a description of the purpose for display, e.g.
+// "Education", falling back to the name passed to RegisterUPIPurpose, or
+// "" for an unknown code.
+func (c UPIPurpose) Description() string {
+	upiMu.RLock()
+	defer upiMu.RUnlock()
+	if d, ok := upiPurposeDescriptions[c]; ok {
+		return d
+	}
+	return upiPurposes[c]
+}
+
+// ParseUPIPurpose returns the purpose code for s, which is either a code,
+// such as "08", or a purpose name as returned by UPIPurpose.String, such
+// as "education", compared without regard to case. The error wraps
+// ErrInvalidFormat.
+func ParseUPIPurpose(s string) (UPIPurpose, error) {
+	upiMu.RLock()
+	defer upiMu.RUnlock()
+	if _, ok := upiPurposes[UPIPurpose(s)]; ok {
+		return UPIPurpose(s), nil
+	}
+	for code, name := range upiPurposes {
+		if strings.EqualFold(name, s) {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown UPI purpose %q", ErrInvalidFormat, s)
+}
+
+// SetPurposeOfTransaction sets the Purpose of Transaction (Tag 62.08) to
+// an NPCI purpose code, as Bharat QR expects, rather than free text. It
+// returns an error wrapping ErrInvalidFormat for an unknown code and
+// leaves the payload unchanged in that case.
+func (p *Payload) SetPurposeOfTransaction(c UPIPurpose) error {
+	if !c.Valid() {
+		return newError(CodeInvalidFormat, fmt.Errorf("%w: unknown UPI purpose %q", ErrInvalidFormat, c),
+			"tag", IDAdditionalDataFieldTemplate+"."+ADFPurposeOfTransaction, "value", string(c))
+	}
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.PurposeOfTransaction = string(c) })
+	return nil
+}
+
+// PurposeCode returns Tag 62.08 as an NPCI purpose code, accepting a code
+// or a purpose name as ParseUPIPurpose does. ok is false when the field is
+// absent, prompts the consumer or holds free text.
+func (p *Payload) PurposeCode() (c UPIPurpose, ok bool) {
+	if p.AdditionalData == nil || p.AdditionalData.PurposeOfTransaction == "" {
+		return "", false
+	}
+	c, err := ParseUPIPurpose(p.AdditionalData.PurposeOfTransaction)
+	return c, err == nil
+}
@@ -0,0 +1,45 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseUPIPurpose(t *testing.T) {
+	for _, s := range []string{"08", "education", "Education"} {
+		c, err := ParseUPIPurpose(s)
+		if err != nil || c != UPIPurposeEducation {
+			t.Errorf("ParseUPIPurpose(%q) = %q, %v", s, c, err)
+		}
+	}
+	if _, err := ParseUPIPurpose("groceries"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ParseUPIPurpose(groceries) = %v, want ErrInvalidFormat", err)
+	}
+	assertEqual(t, "description", "Hospital and medical services", UPIPurposeHospital.Description())
+	assertEqual(t, "unknown description", "", UPIPurpose("98").Description())
+	if UPIPurpose("98").Valid() || !UPIPurposeSEBI.Valid() {
+		t.Error("Valid disagrees with the purpose table")
+	}
+}
+
+func TestSetPurposeOfTransaction(t *testing.T) {
+	p := basePayload()
+	if _, ok := p.PurposeCode(); ok {
+		t.Error("PurposeCode() ok without Tag 62.08")
+	}
+	if err := p.SetPurposeOfTransaction("98"); !errors.Is(err, ErrInvalidFormat) || p.AdditionalData != nil {
+		t.Fatalf("SetPurposeOfTransaction(98) = %v, payload changed: %v", err, p.AdditionalData != nil)
+	}
+	if err := p.SetPurposeOfTransaction(UPIPurposeInsurance); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "62.08", "07", p.AdditionalData.PurposeOfTransaction)
+	if c, ok := p.PurposeCode(); !ok || c != UPIPurposeInsurance {
+		t.Errorf("PurposeCode() = %q, %v", c, ok)
+	}
+
+	p.AdditionalData.PurposeOfTransaction = "Payment for order 42"
+	if _, ok := p.PurposeCode(); ok {
+		t.Error("PurposeCode() ok for free text")
+	}
+}
@@ -0,0 +1,44 @@
+package emvqr
+
+import "fmt"
+
+// SetTransactionReference sets the transaction reference of a dynamic Bharat
+// QR in both places consumer apps read it from: the UPI VPA Reference
+// template (Tag 27.01, created with the RuPay RID if absent) and the
+// Reference Label of the Additional Data Field Template (Tag 62.05). Any
+// Tag 27 Reference URL is kept.
+//
+// ref must be 4–25 characters: at least the Tag 27.01 minimum and at most
+// the Tag 62.05 maximum. Otherwise it returns ErrInvalidFormat and leaves
+// the payload unchanged.
+func (p *Payload) SetTransactionReference(ref string) error {
+	if n := len(ref); n < 4 || n > 25 {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: transaction reference must be 4-25 characters, got %d", ErrInvalidFormat, n),
+			"tag", IDUPIVPAReference+"."+UPIVPARefTransactionRef)
+	}
+	if p.UPITransactionRef == nil {
+		p.UPITransactionRef = &UPIVPAReference{RuPayRID: RuPayRIDValue}
+	}
+	p.UPITransactionRef.TransactionRef = ref
+	for i := range p.MerchantIdentifiers {
+		if mi := &p.MerchantIdentifiers[i]; mi.ID == IDUPIVPAReference {
+			setSubField(&mi.SubFields, UPIVPARefTransactionRef, ref)
+		}
+	}
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.ReferenceLabel = ref })
+	return nil
+}
+
+// ReferencesConsistent reports whether the Tag 27.01 transaction reference
+// and the Tag 62.05 Reference Label agree. It is true when either is absent
+// or the Reference Label prompts the consumer ("***"); when it is false,
+// apps may reconcile the payment against the wrong reference.
+func (p *Payload) ReferencesConsistent() bool {
+	ref := p.GetTransactionReference()
+	if ref == "" || p.AdditionalData == nil {
+		return true
+	}
+	label := p.AdditionalData.ReferenceLabel
+	return label == "" || label == PromptValue || label == ref
+}
@@ -0,0 +1,69 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSetTransactionReference(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.ReferencesConsistent() {
+		t.Error("real-world payload reported inconsistent")
+	}
+
+	p.AdditionalData.ReferenceLabel = "ORDER-9"
+	if p.ReferencesConsistent() {
+		t.Error("differing references reported consistent")
+	}
+	if err := p.SetTransactionReference("ORDER-10"); err != nil {
+		t.Fatal(err)
+	}
+	if !p.ReferencesConsistent() {
+		t.Error("references inconsistent after SetTransactionReference")
+	}
+	raw, err := Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "27.01", "ORDER-10", decoded.GetTransactionReference())
+	assertEqual(t, "27.02", "https://www.hitachi-payments.com", decoded.UPITransactionRef.ReferenceURL)
+	assertEqual(t, "62.05", "ORDER-10", decoded.AdditionalData.ReferenceLabel)
+	assertEqual(t, "flattened 27.01", "ORDER-10", decoded.Flatten()["27.01"])
+
+	fresh := basePayload()
+	if err := fresh.SetTransactionReference("INV-1"); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "new 27.00", RuPayRIDValue, fresh.UPITransactionRef.RuPayRID)
+	for _, bad := range []string{"abc", strings.Repeat("9", 26)} {
+		if err := fresh.SetTransactionReference(bad); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("SetTransactionReference(%q) = %v, want ErrInvalidFormat", bad, err)
+		}
+	}
+	assertEqual(t, "unchanged", "INV-1", fresh.AdditionalData.ReferenceLabel)
+}
+
+func TestExplain_ReferenceMismatch(t *testing.T) {
+	p, _ := Decode(realWorldBharatQRPayload)
+	p.AdditionalData.ReferenceLabel = "ORDER-9"
+	raw, err := Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := Explain(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `Tag 27.01 reference "52602091445452087569609" differs from Tag 62.05 reference label "ORDER-9"`
+	if !strings.Contains(out, want) {
+		t.Errorf("Explain output missing %q:\n%s", want, out)
+	}
+}
@@ -0,0 +1,95 @@
+package emvqr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FormatValidator checks the value of a field against a merchant or
+// acquirer format, such as the reconciliation format of bill numbers.
+// Validate returns nil for an acceptable value.
+type FormatValidator interface {
+	Validate(value string) error
+}
+
+// ValidatorFunc adapts a function to FormatValidator.
+type ValidatorFunc func(value string) error
+
+// Validate calls f(value).
+func (f ValidatorFunc) Validate(value string) error { return f(value) }
+
+// ReferenceFormat is a FormatValidator for reference-like fields such as
+// the Bill Number and Reference Label. Zero fields do not constrain.
+type ReferenceFormat struct {
+	MinLength int            // minimum length in bytes
+	MaxLength int            // maximum length in bytes
+	Charset   string         // bytes allowed, e.g. "0123456789-"
+	Pattern   *regexp.Regexp // matched against the whole value
+}
+
+// Validate checks value against f, returning an error wrapping
+// ErrInvalidFormat for the first constraint it breaks.
+func (f ReferenceFormat) Validate(value string) error {
+	switch n := len(value); {
+	case f.MinLength > 0 && n < f.MinLength:
+		return fmt.Errorf("%w: %q is shorter than %d bytes", ErrInvalidFormat, value, f.MinLength)
+	case f.MaxLength > 0 && n > f.MaxLength:
+		return fmt.Errorf("%w: %q exceeds %d bytes", ErrInvalidFormat, value, f.MaxLength)
+	}
+	if f.Charset != "" {
+		for i := 0; i < len(value); i++ {
+			if strings.IndexByte(f.Charset, value[i]) < 0 {
+				return fmt.Errorf("%w: %q contains %q, outside the allowed characters", ErrInvalidFormat, value, value[i])
+			}
+		}
+	}
+	if f.Pattern != nil {
+		if loc := f.Pattern.FindStringIndex(value); loc == nil || loc[0] != 0 || loc[1] != len(value) {
+			return fmt.Errorf("%w: %q does not match %s", ErrInvalidFormat, value, f.Pattern)
+		}
+	}
+	return nil
+}
+
+// checkReferenceFormats applies the Bill Number and Reference Label
+// validators of opts to p. Absent fields and PromptValue are not checked,
+// since the consumer supplies the value.
+func checkReferenceFormats(p *Payload, opts EncodeOptions) error {
+	if errs := referenceFormatErrors(p, opts); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// referenceFormatErrors returns the failures of checkReferenceFormats, all
+// of them.
+func referenceFormatErrors(p *Payload, opts EncodeOptions) []error {
+	a := p.AdditionalData
+	if a == nil {
+		return nil
+	}
+	var errs []error
+	for _, f := range []struct {
+		id, value string
+		v         FormatValidator
+	}{
+		{ADFBillNumber, a.BillNumber, opts.BillNumberFormat},
+		{ADFReferenceLabel, a.ReferenceLabel, opts.ReferenceLabelFormat},
+	} {
+		if f.v == nil || f.value == "" || f.value == PromptValue {
+			continue
+		}
+		if err := f.v.Validate(f.value); err != nil {
+			path := IDAdditionalDataFieldTemplate + "." + f.id
+			if errors.Is(err, ErrInvalidFormat) {
+				err = fmt.Errorf("emvqr: tag %s: %w", path, err)
+			} else {
+				err = fmt.Errorf("%w: tag %s: %w", ErrInvalidFormat, path, err)
+			}
+			errs = append(errs, newError(CodeInvalidFormat, err, "tag", path, "value", f.value))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,57 @@
+package emvqr
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestReferenceFormat_Validate(t *testing.T) {
+	f := ReferenceFormat{
+		MinLength: 4,
+		MaxLength: 12,
+		Charset:   "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-",
+		Pattern:   regexp.MustCompile(`INV-\d+`),
+	}
+	if err := f.Validate("INV-1234"); err != nil {
+		t.Errorf("Validate(INV-1234) = %v", err)
+	}
+	for _, bad := range []string{"INV", "INV-1234567890", "inv-1234", "INV-12AB", "XINV-123"} {
+		if err := f.Validate(bad); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("Validate(%q) = %v, want ErrInvalidFormat", bad, err)
+		}
+	}
+	if err := (ReferenceFormat{}).Validate("anything"); err != nil {
+		t.Errorf("zero ReferenceFormat: %v", err)
+	}
+}
+
+func TestEncode_ReferenceFormats(t *testing.T) {
+	p := basePayload()
+	p.SetAdditionalData(func(a *AdditionalDataField) {
+		a.BillNumber = "B/001"
+		a.ReferenceLabel = PromptValue
+	})
+	opts := EncodeOptions{
+		BillNumberFormat:     ReferenceFormat{Charset: "0123456789B"},
+		ReferenceLabelFormat: ReferenceFormat{MaxLength: 1},
+	}
+	_, err := EncodeWithOptions(p, opts)
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("EncodeWithOptions() = %v, want ErrInvalidFormat", err)
+	}
+	assertEqual(t, "tag", "62.01", ErrorParams(err)["tag"])
+	assertEqual(t, "code", string(CodeInvalidFormat), string(ErrorCode(err)))
+
+	errReconcile := errors.New("unknown bill series")
+	opts.BillNumberFormat = ValidatorFunc(func(string) error { return errReconcile })
+	if _, err := EncodeWithOptions(p, opts); !errors.Is(err, ErrInvalidFormat) || !errors.Is(err, errReconcile) {
+		t.Fatalf("EncodeWithOptions() = %v, want ErrInvalidFormat wrapping the validator error", err)
+	}
+
+	p.AdditionalData.BillNumber = "B001"
+	opts.BillNumberFormat = ReferenceFormat{Charset: "0123456789B"}
+	if _, err := EncodeWithOptions(p, opts); err != nil {
+		t.Fatalf("EncodeWithOptions() error: %v", err)
+	}
+}
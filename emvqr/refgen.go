@@ -0,0 +1,114 @@
+package emvqr
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultReferenceLength is the length of generated references when
+// ReferenceOptions.MaxLength is zero: the most that fits both the UPI
+// transaction reference (Tag 27.01, 4–35 characters) and the Reference
+// Label (Tag 62.05, 1–25 characters).
+const DefaultReferenceLength = 25
+
+// referenceTimeLayout is the timestamp of generated references, in UTC.
+const referenceTimeLayout = "060102150405"
+
+// minReferenceSuffix is the fewest random or hashed characters, 5 bits
+// each, a generated reference carries.
+const minReferenceSuffix = 6
+
+// referenceEncoding is unpadded upper-case base32, whose characters are
+// safe in every scheme's reference fields.
+var referenceEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ReferenceOptions controls NewReference and ReferenceFromKey.
+type ReferenceOptions struct {
+	// Prefix starts every reference, e.g. "INV" or a store code. It may
+	// hold ASCII letters, digits and '-'.
+	Prefix string
+
+	// MaxLength is the length of the references generated, at most 35;
+	// zero means DefaultReferenceLength.
+	MaxLength int
+
+	// Now returns the current time for NewReference; nil means time.Now.
+	Now func() time.Time
+
+	// Rand is the source of NewReference's random suffix; nil means
+	// crypto/rand.
+	Rand io.Reader
+}
+
+// NewReference returns a new transaction reference for Tag 27.01 or 62.05:
+// opts.Prefix, the UTC time as YYMMDDhhmmss, and a random base32 suffix
+// filling the rest of opts.MaxLength, e.g. "INV240301100000K7Q2M4XA3B".
+// Two references made in the same second differ unless their suffixes
+// collide, which with a 3-character prefix and the default length is a 1
+// in 2^50 chance. It
+// returns an error wrapping ErrInvalidFormat if the prefix leaves room
+// for fewer than 6 suffix characters or has other characters.
+func NewReference(opts ReferenceOptions) (string, error) {
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	head := opts.Prefix + now().UTC().Format(referenceTimeLayout)
+	n, err := referenceSuffixLength(opts, len(head))
+	if err != nil {
+		return "", err
+	}
+	r := opts.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	random := make([]byte, (n*5+7)/8)
+	if _, err := io.ReadFull(r, random); err != nil {
+		return "", fmt.Errorf("emvqr: generating reference: %w", err)
+	}
+	return head + referenceEncoding.EncodeToString(random)[:n], nil
+}
+
+// ReferenceFromKey returns the transaction reference for an idempotency
+// key, such as an order ID: opts.Prefix followed by a base32 hash of key
+// filling opts.MaxLength. The same key and options always give the same
+// reference, so a retried request does not create a second reference for
+// one order. opts.Now and opts.Rand are ignored.
+func ReferenceFromKey(key string, opts ReferenceOptions) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("%w: reference key", ErrMissingRequired)
+	}
+	n, err := referenceSuffixLength(opts, len(opts.Prefix))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(key))
+	return opts.Prefix + referenceEncoding.EncodeToString(sum[:])[:n], nil
+}
+
+// referenceSuffixLength checks opts and returns how many characters follow
+// the first head characters of a reference.
+func referenceSuffixLength(opts ReferenceOptions, head int) (int, error) {
+	for i := 0; i < len(opts.Prefix); i++ {
+		if c := opts.Prefix[i]; !(c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '-') {
+			return 0, fmt.Errorf("%w: reference prefix %q may only hold letters, digits and '-'", ErrInvalidFormat, opts.Prefix)
+		}
+	}
+	limit := opts.MaxLength
+	if limit == 0 {
+		limit = DefaultReferenceLength
+	}
+	if limit < 0 || limit > subFieldSpecs[IDUPIVPAReference][UPIVPARefTransactionRef].maxLen {
+		return 0, fmt.Errorf("%w: reference length %d is not 1–35", ErrInvalidFormat, limit)
+	}
+	n := limit - head
+	if n < minReferenceSuffix {
+		return 0, fmt.Errorf("%w: reference prefix %q leaves %d of %d characters, fewer than %d",
+			ErrInvalidFormat, opts.Prefix, max(n, 0), limit, minReferenceSuffix)
+	}
+	return n, nil
+}
@@ -0,0 +1,72 @@
+package emvqr
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewReference(t *testing.T) {
+	opts := ReferenceOptions{
+		Prefix: "INV",
+		Now:    func() time.Time { return time.Date(2024, 3, 1, 15, 30, 0, 0, time.FixedZone("IST", 19800)) },
+		Rand:   bytes.NewReader(bytes.Repeat([]byte{0xff}, 16)),
+	}
+	ref, err := NewReference(opts)
+	if err != nil {
+		t.Fatalf("NewReference() error: %v", err)
+	}
+	assertEqual(t, "reference", "INV240301100000777777777", ref[:24])
+	assertEqual(t, "length", "25", strconv.Itoa(len(ref)))
+
+	p := basePayload()
+	if err := p.SetTransactionReference(ref); err != nil {
+		t.Errorf("SetTransactionReference(%q) error: %v", ref, err)
+	}
+
+	seen := make(map[string]bool)
+	for range 1000 {
+		ref, err := NewReference(ReferenceOptions{Prefix: "T-", MaxLength: 35})
+		if err != nil {
+			t.Fatalf("NewReference() error: %v", err)
+		}
+		if len(ref) != 35 || seen[ref] {
+			t.Fatalf("NewReference() = %q: wrong length or repeated", ref)
+		}
+		seen[ref] = true
+	}
+}
+
+func TestReferenceFromKey(t *testing.T) {
+	a, err := ReferenceFromKey("order-1001", ReferenceOptions{Prefix: "ORD"})
+	if err != nil {
+		t.Fatalf("ReferenceFromKey() error: %v", err)
+	}
+	b, _ := ReferenceFromKey("order-1001", ReferenceOptions{Prefix: "ORD"})
+	c, _ := ReferenceFromKey("order-1002", ReferenceOptions{Prefix: "ORD"})
+	if a != b || a == c || len(a) != DefaultReferenceLength || !strings.HasPrefix(a, "ORD") {
+		t.Errorf("ReferenceFromKey() = %q, %q, %q", a, b, c)
+	}
+	if _, err := ReferenceFromKey("", ReferenceOptions{}); !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("ReferenceFromKey(\"\") error = %v, want ErrMissingRequired", err)
+	}
+}
+
+func TestReferenceOptions_Errors(t *testing.T) {
+	for _, opts := range []ReferenceOptions{
+		{Prefix: "INV/"},
+		{Prefix: "INV", MaxLength: 36},
+		{Prefix: "INV", MaxLength: -1},
+		{Prefix: "ACME-STORE-0042", MaxLength: 30},
+	} {
+		if _, err := NewReference(opts); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("NewReference(%+v) error = %v, want ErrInvalidFormat", opts, err)
+		}
+	}
+	if _, err := NewReference(ReferenceOptions{Rand: strings.NewReader("")}); err == nil {
+		t.Error("NewReference() with an empty random source expected error, got nil")
+	}
+}
@@ -0,0 +1,349 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/internal/qr"
+)
+
+// Scheme selects the branding strip drawn above the symbol.
+type Scheme int
+
+// Supported scheme layouts.
+const (
+	SchemeNone Scheme = iota
+	SchemeBharatQR
+	SchemeUPI
+	SchemePIX
+)
+
+// String returns the scheme's display name.
+func (s Scheme) String() string {
+	switch s {
+	case SchemeNone:
+		return "none"
+	case SchemeBharatQR:
+		return "BharatQR"
+	case SchemeUPI:
+		return "UPI"
+	case SchemePIX:
+		return "PIX"
+	}
+	return fmt.Sprintf("Scheme(%d)", int(s))
+}
+
+// schemeStyle describes a scheme strip: a coloured band carrying the scheme
+// label and a call-to-action line, with an optional accent bar beneath it.
+type schemeStyle struct {
+	label   string
+	tagline string
+	band    color.RGBA
+	accent  color.Color // nil for no accent bar
+}
+
+var schemeStyles = map[Scheme]schemeStyle{
+	SchemeBharatQR: {
+		label:   "BHARAT QR",
+		tagline: "Scan & pay with any BharatQR app",
+		band:    color.RGBA{0x1B, 0x3F, 0x8B, 0xFF},
+	},
+	SchemeUPI: {
+		label:   "UPI",
+		tagline: "Scan & pay with any UPI app",
+		band:    color.RGBA{0x09, 0x79, 0x39, 0xFF},
+		accent:  color.RGBA{0xED, 0x75, 0x2E, 0xFF},
+	},
+	SchemePIX: {
+		label:   "PIX",
+		tagline: "Pague com Pix",
+		band:    color.RGBA{0x32, 0xBC, 0xAD, 0xFF},
+	},
+}
+
+// Logo placement constraints.
+const (
+	// logoClearance is the number of modules from each edge the logo pad must
+	// keep clear: finder patterns, separators and format information.
+	logoClearance = 9
+	// logoSafetyFactor is how many times the hidden-module fraction must fit
+	// into the level's nominal recovery capacity.
+	logoSafetyFactor = 2
+)
+
+// recovery returns the nominal fraction of the symbol the level can restore.
+func (e ErrorCorrection) recovery() float64 {
+	switch e {
+	case ECLow:
+		return 0.07
+	case ECQuartile:
+		return 0.25
+	case ECHigh:
+		return 0.30
+	default:
+		return 0.15
+	}
+}
+
+// logoModules returns the side, in modules, of the pad cleared for a logo on
+// a symbol of the given size: the logo itself plus one module on each side,
+// rounded to the symbol's parity so the pad is exactly centred.
+func logoModules(size int, scale float64) int {
+	n := int(math.Ceil(scale*float64(size))) + 2
+	if (size-n)%2 != 0 {
+		n++
+	}
+	return n
+}
+
+// encodeWithLogo encodes data at the lowest level not below ec, and the
+// smallest version, for which the logo pad both clears the function patterns
+// and hides no more than 1/logoSafetyFactor of the level's recovery capacity.
+// The level is raised first; once at High the version is raised instead.
+// It stops with ctx's error between attempts once ctx is done.
+func encodeWithLogo(ctx context.Context, data []byte, o Options, ec ErrorCorrection) (*qr.Code, ErrorCorrection, error) {
+	maxVersion := o.MaxVersion
+	if maxVersion == 0 {
+		maxVersion = qr.MaxVersion
+	}
+	minVersion := qr.MinVersion
+	for {
+		if minVersion > maxVersion {
+			return nil, ec, fmt.Errorf("%w: logo does not fit below version %d", qr.ErrDataTooLong, maxVersion)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, ec, err
+		}
+		code, err := qr.Encode(data, qr.Options{Level: ec.level(), MinVersion: minVersion, MaxVersion: maxVersion})
+		if err != nil {
+			return nil, ec, err
+		}
+		pad := logoModules(code.Size, o.LogoScale)
+		hidden := float64(pad*pad) / float64(code.Size*code.Size)
+		switch {
+		case (code.Size-pad)/2 < logoClearance:
+			minVersion = code.Version + 1
+		case hidden*logoSafetyFactor <= ec.recovery():
+			return code, ec, nil
+		case ec < ECHigh:
+			ec++
+		default:
+			minVersion = code.Version + 1
+		}
+	}
+}
+
+// branded reports whether any branding option is set.
+func (s *Symbol) branded() bool {
+	return s.opts.Logo != nil || s.opts.Caption != "" || s.opts.Scheme != SchemeNone
+}
+
+// textLine is a line of text centred horizontally on the artwork.
+type textLine struct {
+	text  string // as supplied; bitmap output substitutes '?' for non-ASCII
+	top   int    // top edge in pixels
+	scale int    // bitmap font scale; the line is glyphHeight*scale tall
+	color color.Color
+}
+
+// layout positions every element of the artwork in pixels, origin top-left.
+type layout struct {
+	width, height int
+
+	band, accent           image.Rectangle // empty without a scheme strip
+	bandColor, accentColor color.Color
+
+	symbol image.Point     // top-left corner of the quiet zone
+	logo   image.Rectangle // logo pad; empty without a logo
+	text   []textLine
+}
+
+// layout computes the artwork geometry. A plain symbol is just the symbol and
+// its quiet zone.
+func (s *Symbol) layout() layout {
+	o := s.opts
+	ms := o.ModuleSize
+	side := (s.code.Size + 2*o.QuietZone) * ms
+	l := layout{width: side}
+	y := 0
+
+	if st, ok := schemeStyles[o.Scheme]; ok {
+		label, labelScale := fitText(st.label, side-2*ms, max(1, 3*ms/glyphHeight))
+		tagline, tagScale := fitText(st.tagline, side-2*ms, max(1, ms/4))
+		l.text = append(l.text,
+			textLine{text: label, top: ms, scale: labelScale, color: color.White},
+			textLine{text: tagline, top: ms + glyphHeight*labelScale + ms/2, scale: tagScale, color: color.White})
+		y = ms + glyphHeight*labelScale + ms/2 + glyphHeight*tagScale + ms
+		l.band, l.bandColor = image.Rect(0, 0, side, y), st.band
+		if st.accent != nil {
+			h := max(1, ms/2)
+			l.accent, l.accentColor = image.Rect(0, y, side, y+h), st.accent
+			y += h
+		}
+	}
+
+	l.symbol = image.Pt(0, y)
+	if o.Logo != nil {
+		pad := logoModules(s.code.Size, o.LogoScale)
+		off := (o.QuietZone + (s.code.Size-pad)/2) * ms
+		l.logo = image.Rect(off, y+off, off+pad*ms, y+off+pad*ms)
+	}
+	y += side
+
+	if o.Caption != "" {
+		caption, scale := fitText(o.Caption, side-2*ms, max(1, 3*ms/glyphHeight))
+		l.text = append(l.text, textLine{text: caption, top: y, scale: scale, color: o.Foreground})
+		y += glyphHeight*scale + ms
+	}
+	l.height = y
+	return l
+}
+
+// logoRect returns where the logo image is drawn: the pad inset by one
+// module, shrunk to the logo's aspect ratio and centred.
+func (s *Symbol) logoRect(l layout) image.Rectangle {
+	ms := s.opts.ModuleSize
+	return fitRect(l.logo.Inset(ms), s.opts.Logo.Bounds())
+}
+
+// fitText returns s, or a truncated form of it, together with the largest
+// scale not above scale at which it fits in width pixels.
+func fitText(s string, width, scale int) (string, int) {
+	runes := []rune(s)
+	for ; scale > 1; scale-- {
+		if (len(runes)*glyphAdvance-1)*scale <= width {
+			return s, scale
+		}
+	}
+	if n := (width + 1) / glyphAdvance; len(runes) > n {
+		if n <= 3 {
+			return string(runes[:max(n, 0)]), 1
+		}
+		return string(runes[:n-3]) + "...", 1
+	}
+	return s, 1
+}
+
+// fitRect returns the largest rectangle with src's aspect ratio centred in r.
+func fitRect(r, src image.Rectangle) image.Rectangle {
+	w, h := r.Dx(), r.Dy()
+	if sw, sh := src.Dx(), src.Dy(); sw > 0 && sh > 0 {
+		if sw*h > sh*w {
+			h = w * sh / sw
+		} else {
+			w = h * sw / sh
+		}
+	}
+	x := r.Min.X + (r.Dx()-w)/2
+	y := r.Min.Y + (r.Dy()-h)/2
+	return image.Rect(x, y, x+w, y+h)
+}
+
+// scaleImage resamples src to w×h pixels using nearest-neighbour sampling.
+func scaleImage(src image.Image, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := src.Bounds()
+	for y := 0; y < h; y++ {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := 0; x < w; x++ {
+			dst.Set(x, y, src.At(b.Min.X+x*b.Dx()/w, sy))
+		}
+	}
+	return dst
+}
+
+// brandedImage draws the full artwork as an RGBA image.
+func (s *Symbol) brandedImage(l layout) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	fillRect(img, img.Bounds(), s.opts.Background)
+	if !l.band.Empty() {
+		fillRect(img, l.band, l.bandColor)
+	}
+	if !l.accent.Empty() {
+		fillRect(img, l.accent, l.accentColor)
+	}
+	sym := s.symbolImage()
+	draw.Draw(img, sym.Bounds().Add(l.symbol), sym, image.Point{}, draw.Src)
+	if !l.logo.Empty() {
+		fillRect(img, l.logo, s.opts.Background)
+		r := s.logoRect(l)
+		if !r.Empty() {
+			draw.Draw(img, r, scaleImage(s.opts.Logo, r.Dx(), r.Dy()), image.Point{}, draw.Over)
+		}
+	}
+	for _, t := range l.text {
+		str := asciiOnly(t.text)
+		drawText(img, (l.width-textWidth(str)*t.scale)/2, t.top, t.scale, str, t.color)
+	}
+	return img
+}
+
+// Vector text is sized so that Helvetica capitals match the bitmap glyphs,
+// which are seven rows tall above the baseline.
+const (
+	glyphAscent     = 7
+	helveticaCapHgt = 0.718
+)
+
+// fontSize returns the vector font size matching a bitmap text scale.
+func fontSize(scale int) float64 {
+	return math.Round(float64(glyphAscent*scale)/helveticaCapHgt*10) / 10
+}
+
+// writeBrandedSVG writes the full artwork in pixel units. Text uses a system
+// sans-serif font and keeps non-ASCII characters.
+func (s *Symbol) writeBrandedSVG(w io.Writer, l layout) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" viewBox="0 0 %d %d" width="%d" height="%d">`+"\n",
+		l.width, l.height, l.width, l.height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`+"\n", l.width, l.height, hexColor(s.opts.Background))
+	for _, r := range []struct {
+		rect image.Rectangle
+		fill color.Color
+	}{{l.band, l.bandColor}, {l.accent, l.accentColor}} {
+		if !r.rect.Empty() {
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+				r.rect.Min.X, r.rect.Min.Y, r.rect.Dx(), r.rect.Dy(), hexColor(r.fill))
+		}
+	}
+
+	fmt.Fprintf(&b, `<path fill="%s" shape-rendering="crispEdges" transform="translate(%d %d) scale(%d)" d="`,
+		hexColor(s.opts.Foreground), l.symbol.X, l.symbol.Y, s.opts.ModuleSize)
+	if err := s.writeModulePath(&b, s.opts.QuietZone); err != nil {
+		return err
+	}
+	b.WriteString("\"/>\n")
+
+	if !l.logo.Empty() {
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			l.logo.Min.X, l.logo.Min.Y, l.logo.Dx(), l.logo.Dy(), hexColor(s.opts.Background))
+		var logo bytes.Buffer
+		if err := png.Encode(&logo, s.opts.Logo); err != nil {
+			return fmt.Errorf("render: encoding logo: %w", err)
+		}
+		r := s.logoRect(l)
+		fmt.Fprintf(&b, `<image x="%d" y="%d" width="%d" height="%d" xlink:href="data:image/png;base64,%s"/>`+"\n",
+			r.Min.X, r.Min.Y, r.Dx(), r.Dy(), base64.StdEncoding.EncodeToString(logo.Bytes()))
+	}
+
+	for _, t := range l.text {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="Helvetica, Arial, sans-serif" font-size="%g" text-anchor="middle" fill="%s">`,
+			l.width/2, t.top+glyphAscent*t.scale, fontSize(t.scale), hexColor(t.color))
+		if err := xml.EscapeText(&b, []byte(t.text)); err != nil {
+			return err
+		}
+		b.WriteString("</text>\n")
+	}
+	b.WriteString("</svg>\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
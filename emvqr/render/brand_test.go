@@ -0,0 +1,161 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testLogo returns a solid red logo of the given size.
+func testLogo(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	fillRect(img, img.Bounds(), color.RGBA{R: 0xC0, A: 0xFF})
+	return img
+}
+
+func TestNew_LogoRaisesErrorCorrection(t *testing.T) {
+	s, err := New(staticQR, Options{ErrorCorrection: ECLow, Logo: testLogo(10, 10), LogoScale: 0.3})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	if s.ErrorCorrection != ECHigh {
+		t.Errorf("ErrorCorrection = %s, want H", s.ErrorCorrection)
+	}
+	pad := logoModules(s.Size(), 0.3)
+	if (s.Size()-pad)/2 < logoClearance {
+		t.Errorf("logo pad of %d modules overlaps function patterns on a %d-module symbol", pad, s.Size())
+	}
+	if hidden := float64(pad*pad) / float64(s.Size()*s.Size()); hidden*logoSafetyFactor > s.ErrorCorrection.recovery() {
+		t.Errorf("logo hides %.3f of the symbol, beyond the %s budget", hidden, s.ErrorCorrection)
+	}
+}
+
+func TestNew_LogoTooLarge(t *testing.T) {
+	_, err := New(staticQR, Options{Logo: testLogo(10, 10), MaxVersion: 4})
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestNew_InvalidBrandingOptions(t *testing.T) {
+	for _, o := range []Options{
+		{Logo: testLogo(4, 4), LogoScale: 0.5},
+		{Logo: testLogo(4, 4), LogoScale: -0.1},
+		{Scheme: Scheme(42)},
+	} {
+		if _, err := New(staticQR, o); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("options %+v: expected ErrInvalidOptions, got %v", o, err)
+		}
+	}
+}
+
+func TestImage_Branded(t *testing.T) {
+	s, err := New(staticQR, Options{Logo: testLogo(30, 20), Caption: "ABC Hammers", Scheme: SchemeUPI})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	l := s.layout()
+	side := (s.Size() + 2*DefaultQuietZone) * DefaultModuleSize
+	if l.width != side || l.height <= side {
+		t.Fatalf("layout is %dx%d, want width %d and extra height", l.width, l.height, side)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WritePNG(&buf); err != nil {
+		t.Fatalf("WritePNG error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode error: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != l.width || b.Dy() != l.height {
+		t.Fatalf("image is %dx%d, want %dx%d", b.Dx(), b.Dy(), l.width, l.height)
+	}
+	if got := color.RGBAModel.Convert(img.At(0, 0)); got != schemeStyles[SchemeUPI].band {
+		t.Errorf("strip pixel = %v, want %v", got, schemeStyles[SchemeUPI].band)
+	}
+	c := s.logoRect(l)
+	mid := image.Pt((c.Min.X+c.Max.X)/2, (c.Min.Y+c.Max.Y)/2)
+	if r, g, _, _ := img.At(mid.X, mid.Y).RGBA(); r>>8 != 0xC0 || g != 0 {
+		t.Errorf("logo centre pixel = %v, want logo colour", img.At(mid.X, mid.Y))
+	}
+	if c.Dx()*20 != c.Dy()*30 {
+		t.Errorf("logo drawn at %v, aspect ratio not preserved", c)
+	}
+}
+
+func TestWriteSVG_Branded(t *testing.T) {
+	var buf bytes.Buffer
+	err := SVG(&buf, staticQR, Options{Logo: testLogo(8, 8), Caption: "Café <Bar>", Scheme: SchemePIX})
+	if err != nil {
+		t.Fatalf("SVG error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<svg", `fill="#32BCAD"`, ">PIX</text>", ">Café &lt;Bar&gt;</text>",
+		`xlink:href="data:image/png;base64,`, "scale(8)", "</svg>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG output missing %q", want)
+		}
+	}
+}
+
+func TestWritePDF_Structure(t *testing.T) {
+	var buf bytes.Buffer
+	err := PDF(&buf, staticQR, Options{Logo: testLogo(8, 8), Caption: "ABC (Hammers)", Scheme: SchemeBharatQR})
+	if err != nil {
+		t.Fatalf("PDF error: %v", err)
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	for _, want := range []string{"/Type /Catalog", "/Count 1", "/BaseFont /Helvetica", "/Subtype /Image", "/Im1 "} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("PDF output missing %q", want)
+		}
+	}
+
+	// Every xref entry must point at the start of its object.
+	i := bytes.LastIndex(out, []byte("startxref\n"))
+	xref, err := strconv.Atoi(strings.Fields(string(out[i+len("startxref\n"):]))[0])
+	if err != nil {
+		t.Fatalf("parsing startxref: %v", err)
+	}
+	lines := strings.Split(string(out[xref:]), "\n")
+	for n := 1; lines[2+n] != "trailer"; n++ {
+		off, err := strconv.Atoi(lines[2+n][:10])
+		if err != nil {
+			t.Fatalf("xref entry %d: %v", n, err)
+		}
+		if prefix := []byte(strconv.Itoa(n) + " 0 obj"); !bytes.HasPrefix(out[off:], prefix) {
+			t.Errorf("xref entry %d points at %q", n, out[off:off+10])
+		}
+	}
+}
+
+func TestFitText(t *testing.T) {
+	tests := []struct {
+		in        string
+		width     int
+		scale     int
+		want      string
+		wantScale int
+	}{
+		{"ABC", 100, 3, "ABC", 3},
+		{"ABCDEFGHIJ", 70, 3, "ABCDEFGHIJ", 1},
+		{"ABCDEFGHIJ", 40, 3, "ABC...", 1},
+	}
+	for _, tt := range tests {
+		got, scale := fitText(tt.in, tt.width, tt.scale)
+		if got != tt.want || scale != tt.wantScale {
+			t.Errorf("fitText(%q, %d, %d) = %q, %d; want %q, %d", tt.in, tt.width, tt.scale, got, scale, tt.want, tt.wantScale)
+		}
+	}
+}
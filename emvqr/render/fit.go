@@ -0,0 +1,280 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/internal/qr"
+)
+
+// ErrUnsupportedByTerminal is returned by FitPayload when no reduction can
+// make the payload readable by the terminal, e.g. because a merchant
+// account holds characters an alphanumeric-only scanner cannot read.
+var ErrUnsupportedByTerminal = errors.New("render: payload unsupported by terminal")
+
+// MinFitLabelLength is the shortest FitPayload shortens a label to, in
+// characters.
+const MinFitLabelLength = 8
+
+// TerminalCaps describes the QR Code symbols a terminal or scanner reads
+// or prints, for FitPayload.
+type TerminalCaps struct {
+	// MaxVersion is the largest QR Code version (1–40) the terminal reads.
+	// Zero means 40.
+	MaxVersion int
+
+	// ErrorCorrection lists the levels the terminal supports. Empty means
+	// all four; ECDefault stands for ECMedium.
+	ErrorCorrection []ErrorCorrection
+
+	// AlphanumericOnly is set for scanners that read only alphanumeric-mode
+	// symbols: digits, upper-case letters, space and "$%*+-./:".
+	AlphanumericOnly bool
+}
+
+// levels returns the supported levels, lowest first, or an error wrapping
+// ErrInvalidOptions.
+func (c TerminalCaps) levels() ([]ErrorCorrection, error) {
+	if c.MaxVersion < 0 || c.MaxVersion > qr.MaxVersion {
+		return nil, fmt.Errorf("%w: max version %d must be 1–%d", ErrInvalidOptions, c.MaxVersion, qr.MaxVersion)
+	}
+	if len(c.ErrorCorrection) == 0 {
+		return []ErrorCorrection{ECLow, ECMedium, ECQuartile, ECHigh}, nil
+	}
+	var levels []ErrorCorrection
+	for _, ec := range c.ErrorCorrection {
+		switch {
+		case ec == ECDefault:
+			ec = ECMedium
+		case ec < ECDefault || ec > ECHigh:
+			return nil, fmt.Errorf("%w: unknown error correction level %d", ErrInvalidOptions, ec)
+		}
+		if !slices.Contains(levels, ec) {
+			levels = append(levels, ec)
+		}
+	}
+	slices.Sort(levels)
+	return levels, nil
+}
+
+// ReductionKind is the way FitPayload changed a field.
+type ReductionKind string
+
+// Reduction kinds.
+const (
+	ReductionDrop      ReductionKind = "drop"      // the field was removed
+	ReductionShorten   ReductionKind = "shorten"   // the value was truncated
+	ReductionUppercase ReductionKind = "uppercase" // the value was upper-cased
+)
+
+// Reduction is one change FitPayload made to fit a payload to a terminal.
+type Reduction struct {
+	Path     string // tag path, as produced by Flatten, e.g. "61" or "62.07"
+	Kind     ReductionKind
+	From, To string // the value before and after; To is empty for a drop
+}
+
+// String describes the reduction, e.g. `shorten 59 "ABC Hammers Ltd" → "ABC Hammers"`.
+func (r Reduction) String() string {
+	if r.Kind == ReductionDrop {
+		return fmt.Sprintf("drop %s %q", r.Path, r.From)
+	}
+	return fmt.Sprintf("%s %s %q → %q", r.Kind, r.Path, r.From, r.To)
+}
+
+// Fit is the result of FitPayload.
+type Fit struct {
+	// Payload is the payload that fits: the one given to FitPayload if
+	// Reductions is empty, a reduced copy otherwise.
+	Payload *emvqr.Payload
+	// Raw is the encoded Payload.
+	Raw string
+	// Version and ErrorCorrection are the smallest version and the highest
+	// supported level of a symbol that holds Raw within the capabilities.
+	Version         int
+	ErrorCorrection ErrorCorrection
+	// Reductions lists the changes made to the payload, in order.
+	Reductions []Reduction
+}
+
+// Options returns opts with the error correction level and maximum version
+// of the fitted symbol, for New.
+func (f *Fit) Options(opts Options) Options {
+	opts.ErrorCorrection = f.ErrorCorrection
+	opts.MaxVersion = f.Version
+	return opts
+}
+
+// fitDrops are the optional fields FitPayload removes, in order, when a
+// payload is too long: the alternate-language merchant details, the postal
+// code and the informational Additional Data fields. Fields that carry
+// payment or reconciliation data are never dropped.
+var fitDrops = []string{
+	emvqr.IDMerchantInfoLanguageTemplate,
+	emvqr.IDPostalCode,
+	emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFMerchantChannel,
+	emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFLoyaltyNumber,
+}
+
+// fitLabels are the labels FitPayload shortens, in order, once every field
+// of fitDrops is gone.
+var fitLabels = []string{
+	emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFTerminalLabel,
+	emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFStoreLabel,
+	emvqr.IDMerchantName,
+}
+
+// fitTextFields are the free-text fields FitPayload upper-cases for
+// alphanumeric-only terminals.
+var fitTextFields = []string{
+	emvqr.IDMerchantName,
+	emvqr.IDMerchantCity,
+	emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFStoreLabel,
+	emvqr.IDAdditionalDataFieldTemplate + "." + emvqr.ADFTerminalLabel,
+}
+
+// FitPayload checks that p can be read by a terminal with the given
+// capabilities and, when it cannot, reduces a copy of it until it can; p is
+// not modified. For alphanumeric-only terminals the merchant name and city
+// and the store and terminal labels are upper-cased first. A payload too
+// long for the largest symbol the terminal reads then loses, one at a time,
+// its alternate-language template (Tag 64), postal code (Tag 61), merchant
+// channel (Tag 62.11) and loyalty number (Tag 62.04), and finally has its
+// terminal label, store label and merchant name shortened, to no less than
+// MinFitLabelLength characters. The changes are listed in the result's
+// Reductions, so callers can also use FitPayload to suggest them.
+//
+// FitPayload returns an error wrapping ErrTooLarge if the payload still does
+// not fit, ErrUnsupportedByTerminal if a field cannot be made alphanumeric,
+// ErrInvalidOptions for invalid capabilities, or the error of Encode.
+func FitPayload(p *emvqr.Payload, caps TerminalCaps) (*Fit, error) {
+	levels, err := caps.levels()
+	if err != nil {
+		return nil, err
+	}
+	maxVersion := caps.MaxVersion
+	if maxVersion == 0 {
+		maxVersion = qr.MaxVersion
+	}
+	raw, err := emvqr.Encode(p)
+	if err != nil {
+		return nil, err
+	}
+	f := &Fit{Payload: p, Raw: raw}
+	if caps.AlphanumericOnly && !qr.IsAlphanumeric([]byte(raw)) {
+		if err := f.uppercase(); err != nil {
+			return nil, err
+		}
+	}
+	// The lowest level holds the most data.
+	capacity := func() int { return qr.Capacity(maxVersion, levels[0].level(), dataMode(f.Raw)) }
+	for _, path := range fitDrops {
+		if len(f.Raw) <= capacity() {
+			break
+		}
+		if err := f.reduce(path, ReductionDrop, func(string) string { return "" }); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range fitLabels {
+		excess := len(f.Raw) - capacity()
+		if excess <= 0 {
+			break
+		}
+		err := f.reduce(path, ReductionShorten, func(v string) string {
+			if v == emvqr.PromptValue {
+				return v
+			}
+			n := max(utf8.RuneCountInString(v)-excess, MinFitLabelLength)
+			return emvqr.TruncateName(v, n)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if n := capacity(); len(f.Raw) > n {
+		return nil, fmt.Errorf("%w: payload is %d chars after %d reduction(s), over the %d a version %d symbol holds",
+			ErrTooLarge, len(f.Raw), len(f.Reductions), n, maxVersion)
+	}
+
+	mode := dataMode(f.Raw)
+	for _, ec := range slices.Backward(levels) {
+		if len(f.Raw) > qr.Capacity(maxVersion, ec.level(), mode) {
+			continue
+		}
+		f.ErrorCorrection = ec
+		f.Version = qr.MinVersion
+		for len(f.Raw) > qr.Capacity(f.Version, ec.level(), mode) {
+			f.Version++
+		}
+		break
+	}
+	return f, nil
+}
+
+// dataMode returns the mode the encoder uses for raw.
+func dataMode(raw string) qr.Mode {
+	if qr.IsAlphanumeric([]byte(raw)) {
+		return qr.ModeAlphanumeric
+	}
+	return qr.ModeByte
+}
+
+// uppercase upper-cases the fitTextFields fields of f's payload and reports, with
+// ErrUnsupportedByTerminal, the first field that is still not alphanumeric.
+func (f *Fit) uppercase() error {
+	for _, path := range fitTextFields {
+		if err := f.reduce(path, ReductionUppercase, strings.ToUpper); err != nil {
+			return err
+		}
+	}
+	m := f.Payload.Flatten()
+	for _, path := range slices.Sorted(maps.Keys(m)) {
+		if !qr.IsAlphanumeric([]byte(m[path])) {
+			return fmt.Errorf("%w: Tag %s %q is not alphanumeric", ErrUnsupportedByTerminal, path, m[path])
+		}
+	}
+	return nil
+}
+
+// reduce replaces the value at path, and for a drop every sub-field under
+// it, with change(value), records the reductions and re-encodes the
+// payload. Paths whose value is absent or unchanged are skipped.
+func (f *Fit) reduce(path string, kind ReductionKind, change func(string) string) error {
+	m := f.Payload.Flatten()
+	var reductions []Reduction
+	for _, p := range slices.Sorted(maps.Keys(m)) {
+		if p != path && (kind != ReductionDrop || !strings.HasPrefix(p, path+".")) {
+			continue
+		}
+		v := change(m[p])
+		if v == m[p] {
+			continue
+		}
+		reductions = append(reductions, Reduction{Path: p, Kind: kind, From: m[p], To: v})
+		if v == "" {
+			delete(m, p)
+		} else {
+			m[p] = v
+		}
+	}
+	if len(reductions) == 0 {
+		return nil
+	}
+	p, err := emvqr.FromFlat(m)
+	if err != nil {
+		return err
+	}
+	raw, err := emvqr.Encode(p)
+	if err != nil {
+		return err
+	}
+	f.Payload, f.Raw = p, raw
+	f.Reductions = append(f.Reductions, reductions...)
+	return nil
+}
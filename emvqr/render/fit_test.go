@@ -0,0 +1,130 @@
+package render
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// fitTestPayload returns staticQR with a postal code and store and
+// terminal labels: 134 characters encoded.
+func fitTestPayload(t *testing.T) *emvqr.Payload {
+	t.Helper()
+	p, err := emvqr.Decode(staticQR)
+	if err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+	p.PostalCode = "10001"
+	p.SetAdditionalData(func(a *emvqr.AdditionalDataField) {
+		a.StoreLabel = "Downtown Hammers Store 42"
+		a.TerminalLabel = "Till 7"
+	})
+	return p
+}
+
+func TestFitPayload_FitsAsIs(t *testing.T) {
+	p := fitTestPayload(t)
+	f, err := FitPayload(p, TerminalCaps{})
+	if err != nil {
+		t.Fatalf("FitPayload error: %v", err)
+	}
+	if f.Payload != p || len(f.Reductions) != 0 {
+		t.Errorf("payload was reduced: %v", f.Reductions)
+	}
+	if f.Version != 11 || f.ErrorCorrection != ECHigh {
+		t.Errorf("symbol = version %d level %v, want 11 H", f.Version, f.ErrorCorrection)
+	}
+	s, err := New(f.Raw, f.Options(Options{}))
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	if s.Version != f.Version || s.ErrorCorrection != f.ErrorCorrection {
+		t.Errorf("New built version %d level %v, want %d %v", s.Version, s.ErrorCorrection, f.Version, f.ErrorCorrection)
+	}
+}
+
+func TestFitPayload_HighestLevel(t *testing.T) {
+	caps := TerminalCaps{MaxVersion: 8, ErrorCorrection: []ErrorCorrection{ECHigh, ECDefault, ECLow}}
+	f, err := FitPayload(fitTestPayload(t), caps)
+	if err != nil {
+		t.Fatalf("FitPayload error: %v", err)
+	}
+	if f.Version != 8 || f.ErrorCorrection != ECMedium || len(f.Reductions) != 0 {
+		t.Errorf("symbol = version %d level %v with %v, want 8 M unreduced", f.Version, f.ErrorCorrection, f.Reductions)
+	}
+}
+
+func TestFitPayload_Reduces(t *testing.T) {
+	p := fitTestPayload(t)
+	f, err := FitPayload(p, TerminalCaps{MaxVersion: 5, ErrorCorrection: []ErrorCorrection{ECLow}})
+	if err != nil {
+		t.Fatalf("FitPayload error: %v", err)
+	}
+	want := []Reduction{
+		{Path: "61", Kind: ReductionDrop, From: "10001"},
+		{Path: "62.03", Kind: ReductionShorten, From: "Downtown Hammers Store 42", To: "Downtown"},
+		{Path: "59", Kind: ReductionShorten, From: "ABC Hammers", To: "ABC Hamme"},
+	}
+	if diff := cmp.Diff(want, f.Reductions); diff != "" {
+		t.Errorf("reductions mismatch (-want +got):\n%s", diff)
+	}
+	if len(f.Raw) != 106 || f.Version != 5 || f.ErrorCorrection != ECLow {
+		t.Errorf("fit = %d chars, version %d level %v; want 106, 5 L", len(f.Raw), f.Version, f.ErrorCorrection)
+	}
+	if p.PostalCode != "10001" || p.MerchantName != "ABC Hammers" {
+		t.Error("FitPayload modified its argument")
+	}
+	if got, err := emvqr.Decode(f.Raw); err != nil || got.AdditionalData.TerminalLabel != "Till 7" {
+		t.Errorf("fitted payload does not decode with its terminal label: %v", err)
+	}
+	if got := want[1].String(); got != `shorten 62.03 "Downtown Hammers Store 42" → "Downtown"` {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestFitPayload_AlphanumericOnly(t *testing.T) {
+	f, err := FitPayload(fitTestPayload(t), TerminalCaps{AlphanumericOnly: true})
+	if err != nil {
+		t.Fatalf("FitPayload error: %v", err)
+	}
+	var paths []string
+	for _, r := range f.Reductions {
+		if r.Kind != ReductionUppercase {
+			t.Errorf("unexpected reduction %v", r)
+		}
+		paths = append(paths, r.Path)
+	}
+	if diff := cmp.Diff([]string{"59", "60", "62.03", "62.07"}, paths); diff != "" {
+		t.Errorf("upper-cased paths mismatch (-want +got):\n%s", diff)
+	}
+	if got := f.Payload.MerchantName; got != "ABC HAMMERS" {
+		t.Errorf("MerchantName = %q", got)
+	}
+
+	p := fitTestPayload(t)
+	p.SetAdditionalData(func(a *emvqr.AdditionalDataField) { a.ReferenceLabel = "inv-1" })
+	if _, err := FitPayload(p, TerminalCaps{AlphanumericOnly: true}); !errors.Is(err, ErrUnsupportedByTerminal) {
+		t.Errorf("err = %v, want ErrUnsupportedByTerminal", err)
+	}
+}
+
+func TestFitPayload_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		caps TerminalCaps
+		want error
+	}{
+		{"too large", TerminalCaps{MaxVersion: 4}, ErrTooLarge},
+		{"max version", TerminalCaps{MaxVersion: 41}, ErrInvalidOptions},
+		{"level", TerminalCaps{ErrorCorrection: []ErrorCorrection{ECHigh + 1}}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FitPayload(fitTestPayload(t), tt.caps); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
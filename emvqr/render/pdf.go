@@ -0,0 +1,248 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WritePDF writes the symbol, including any branding, as a single-page PDF
+// document. One pixel of the PNG layout maps to one PDF point (1/72 inch),
+// so ModuleSize sets the printed module width. Modules are vector
+// rectangles; text uses the standard Helvetica font (ASCII only).
+func (s *Symbol) WritePDF(w io.Writer) error {
+	l := s.layout()
+	doc := newPDFDocument()
+	c := &pdfCanvas{height: float64(l.height)}
+	s.drawPDF(doc, c, l)
+	if err := doc.addPage(float64(l.width), float64(l.height), c.buf.Bytes()); err != nil {
+		return err
+	}
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// drawPDF paints the artwork described by l onto c.
+func (s *Symbol) drawPDF(doc *pdfDocument, c *pdfCanvas, l layout) {
+	c.fillRect(image.Rect(0, 0, l.width, l.height), s.opts.Background)
+	if !l.band.Empty() {
+		c.fillRect(l.band, l.bandColor)
+	}
+	if !l.accent.Empty() {
+		c.fillRect(l.accent, l.accentColor)
+	}
+
+	// One rectangle per horizontal run of dark modules.
+	ms, qz := s.opts.ModuleSize, s.opts.QuietZone
+	c.setFill(s.opts.Foreground)
+	for y := 0; y < s.code.Size; y++ {
+		for x := 0; x < s.code.Size; {
+			if !s.code.Dark(x, y) {
+				x++
+				continue
+			}
+			run := 1
+			for s.code.Dark(x+run, y) {
+				run++
+			}
+			px, py := l.symbol.X+(x+qz)*ms, l.symbol.Y+(y+qz)*ms
+			c.rect(image.Rect(px, py, px+run*ms, py+ms))
+			x += run
+		}
+	}
+	c.fill()
+
+	if !l.logo.Empty() {
+		c.fillRect(l.logo, s.opts.Background)
+		if r := s.logoRect(l); !r.Empty() {
+			c.image(doc.addImage(s.opts.Logo, s.opts.Background), r)
+		}
+	}
+	for _, t := range l.text {
+		c.centredText(asciiOnly(t.text), float64(l.width)/2, float64(t.top+glyphAscent*t.scale), fontSize(t.scale), t.color)
+	}
+}
+
+// pdfCanvas accumulates a page content stream. Callers use top-left pixel
+// coordinates; the canvas flips them into PDF's bottom-left space.
+type pdfCanvas struct {
+	buf    bytes.Buffer
+	height float64
+}
+
+func (c *pdfCanvas) setFill(col color.Color) {
+	r, g, b, _ := col.RGBA()
+	fmt.Fprintf(&c.buf, "%s %s %s rg\n", pdfNum(float64(r)/0xFFFF), pdfNum(float64(g)/0xFFFF), pdfNum(float64(b)/0xFFFF))
+}
+
+func (c *pdfCanvas) rect(r image.Rectangle) {
+	fmt.Fprintf(&c.buf, "%d %s %d %d re\n", r.Min.X, pdfNum(c.height-float64(r.Max.Y)), r.Dx(), r.Dy())
+}
+
+func (c *pdfCanvas) fill() {
+	c.buf.WriteString("f\n")
+}
+
+func (c *pdfCanvas) fillRect(r image.Rectangle, col color.Color) {
+	c.setFill(col)
+	c.rect(r)
+	c.fill()
+}
+
+// image paints the named image XObject into r.
+func (c *pdfCanvas) image(name string, r image.Rectangle) {
+	fmt.Fprintf(&c.buf, "q %d 0 0 %d %d %s cm /%s Do Q\n", r.Dx(), r.Dy(), r.Min.X, pdfNum(c.height-float64(r.Max.Y)), name)
+}
+
+// centredText draws s centred on cx with its baseline at y.
+func (c *pdfCanvas) centredText(s string, cx, y, size float64, col color.Color) {
+	c.setFill(col)
+	x := cx - helveticaWidth(s)*size/1000/2
+	fmt.Fprintf(&c.buf, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n", pdfNum(size), pdfNum(x), pdfNum(c.height-y), pdfEscape(s))
+}
+
+// Fixed object numbers; pages, content streams and images follow.
+const (
+	pdfCatalogObj   = 1
+	pdfPagesObj     = 2
+	pdfFontObj      = 3
+	pdfResourcesObj = 4
+)
+
+// pdfDocument is a minimal PDF 1.4 writer. All pages share one resource
+// dictionary holding Helvetica and every embedded image.
+type pdfDocument struct {
+	objects [][]byte // object n is objects[n-1]
+	pages   []int
+	images  []int
+}
+
+func newPDFDocument() *pdfDocument {
+	d := &pdfDocument{objects: make([][]byte, pdfResourcesObj)}
+	d.objects[pdfFontObj-1] = []byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	return d
+}
+
+// add appends an object and returns its number.
+func (d *pdfDocument) add(obj []byte) int {
+	d.objects = append(d.objects, obj)
+	return len(d.objects)
+}
+
+// addStream appends a Flate-compressed stream object.
+func (d *pdfDocument) addStream(dict string, data []byte) int {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(data) // writes to a bytes.Buffer cannot fail
+	zw.Close()
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<< %s /Filter /FlateDecode /Length %d >>\nstream\n", dict, z.Len())
+	b.Write(z.Bytes())
+	b.WriteString("\nendstream")
+	return d.add(b.Bytes())
+}
+
+// addImage embeds img as an RGB image, compositing any transparency onto bg,
+// and returns its resource name.
+func (d *pdfDocument) addImage(img image.Image, bg color.Color) string {
+	b := img.Bounds()
+	br, bgG, bb, _ := bg.RGBA()
+	raw := make([]byte, 0, b.Dx()*b.Dy()*3)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA() // alpha-premultiplied
+			raw = append(raw,
+				byte((r+br*(0xFFFF-a)/0xFFFF)>>8),
+				byte((g+bgG*(0xFFFF-a)/0xFFFF)>>8),
+				byte((bl+bb*(0xFFFF-a)/0xFFFF)>>8))
+		}
+	}
+	n := d.addStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8",
+		b.Dx(), b.Dy()), raw)
+	d.images = append(d.images, n)
+	return "Im" + strconv.Itoa(len(d.images))
+}
+
+// addPage appends a page of the given size in points.
+func (d *pdfDocument) addPage(width, height float64, content []byte) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("%w: empty PDF page", ErrInvalidOptions)
+	}
+	contents := d.addStream("", content)
+	p := d.add(fmt.Appendf(nil, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %d 0 R /Contents %d 0 R >>",
+		pdfPagesObj, pdfNum(width), pdfNum(height), pdfResourcesObj, contents))
+	d.pages = append(d.pages, p)
+	return nil
+}
+
+// WriteTo serialises the document with its cross-reference table.
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	d.objects[pdfCatalogObj-1] = fmt.Appendf(nil, "<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObj)
+	kids := make([]string, len(d.pages))
+	for i, p := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", p)
+	}
+	d.objects[pdfPagesObj-1] = fmt.Appendf(nil, "<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	var xobjects strings.Builder
+	for i, n := range d.images {
+		fmt.Fprintf(&xobjects, " /Im%d %d 0 R", i+1, n)
+	}
+	d.objects[pdfResourcesObj-1] = fmt.Appendf(nil, "<< /Font << /F1 %d 0 R >> /XObject <<%s >> >>", pdfFontObj, xobjects.String())
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	offsets := make([]int, len(d.objects))
+	for i, obj := range d.objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n", i+1)
+		b.Write(obj)
+		b.WriteString("\nendobj\n")
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, pdfCatalogObj, xref)
+	n, err := w.Write(b.Bytes())
+	return int64(n), err
+}
+
+// pdfNum formats v with at most two decimal places.
+func pdfNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// pdfEscape escapes a PDF literal string.
+func pdfEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return r.Replace(s)
+}
+
+// helveticaWidths holds the Helvetica advance widths, in 1/1000 em, for
+// printable ASCII (0x20–0x7E).
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaWidth returns the width of an ASCII string in 1/1000 em.
+func helveticaWidth(s string) float64 {
+	w := 0
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 0x20 && c <= 0x7E {
+			w += helveticaWidths[c-0x20]
+		}
+	}
+	return float64(w)
+}
@@ -0,0 +1,362 @@
+// Package render produces QR Code images for EMV QR Code payloads.
+//
+// A payload (either a *emvqr.Payload or an already encoded string) is turned
+// into a Symbol, which can then be written as PNG, SVG or PDF. Capacity limits
+// are checked when the Symbol is built, so an oversized payload is reported as
+// ErrTooLarge rather than producing an unreadable image.
+//
+// Options.Logo, Options.Caption and Options.Scheme turn the plain symbol into
+// branded sticker artwork: a centre logo, a merchant-name caption and a scheme
+// strip (BharatQR, UPI or PIX). A logo automatically raises the error
+// correction level so the modules it hides remain recoverable.
+//
+// Example:
+//
+//	f, _ := os.Create("sticker.png")
+//	defer f.Close()
+//	err := render.PNG(f, raw, render.Options{ModuleSize: 10})
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/internal/qr"
+)
+
+// ErrorCorrection is the QR Code error correction level.
+type ErrorCorrection int
+
+// Error correction levels. The zero value selects ECMedium.
+const (
+	ECDefault  ErrorCorrection = iota // same as ECMedium
+	ECLow                             // ~7% recovery
+	ECMedium                          // ~15% recovery
+	ECQuartile                        // ~25% recovery
+	ECHigh                            // ~30% recovery
+)
+
+// String returns the single-letter level name ("L", "M", "Q", "H").
+func (e ErrorCorrection) String() string {
+	return e.level().String()
+}
+
+// level maps the public level onto the internal encoder level.
+func (e ErrorCorrection) level() qr.Level {
+	switch e {
+	case ECLow:
+		return qr.Low
+	case ECQuartile:
+		return qr.Quartile
+	case ECHigh:
+		return qr.High
+	default:
+		return qr.Medium
+	}
+}
+
+// Defaults applied to zero-valued Options fields.
+const (
+	DefaultModuleSize = 8 // pixels per module
+	DefaultQuietZone  = 4 // modules; the minimum required by ISO/IEC 18004
+	DefaultLogoScale  = 0.2
+	maxModuleSize     = 100
+	maxLogoScale      = 0.3
+)
+
+var (
+	// ErrTooLarge is returned when the payload does not fit in a QR Code
+	// symbol at the requested error correction level and maximum version.
+	ErrTooLarge = errors.New("render: payload exceeds QR Code capacity")
+	// ErrInvalidOptions is returned for out-of-range Options values.
+	ErrInvalidOptions = errors.New("render: invalid options")
+)
+
+// Options controls symbol construction and image output.
+type Options struct {
+	// ErrorCorrection selects the error correction level (default Medium).
+	ErrorCorrection ErrorCorrection
+
+	// ModuleSize is the width of one module in pixels for PNG output and in
+	// user units for SVG output. Zero selects DefaultModuleSize.
+	ModuleSize int
+
+	// QuietZone is the light border in modules. Zero selects
+	// DefaultQuietZone; a negative value disables the border.
+	QuietZone int
+
+	// MaxVersion caps the QR Code version (1–40). Zero means 40.
+	MaxVersion int
+
+	// Foreground and Background default to black and white.
+	Foreground color.Color
+	Background color.Color
+
+	// SkipPayloadValidation renders raw strings without first checking
+	// that they decode as EMV QR payloads.
+	SkipPayloadValidation bool
+
+	// Decode is used to check raw strings unless SkipPayloadValidation is
+	// set. Its VerifyMerchant hook receives the context given to NewContext.
+	Decode emvqr.DecodeOptions
+
+	// Logo is drawn centred over the symbol on a Background-coloured pad.
+	// The error correction level is raised as needed (never lowered) so the
+	// hidden modules stay within the recovery budget.
+	Logo image.Image
+
+	// LogoScale is the logo width as a fraction of the symbol width, quiet
+	// zone excluded. Zero selects DefaultLogoScale; the maximum is 0.3.
+	LogoScale float64
+
+	// Caption is printed centred below the symbol, typically the merchant
+	// name. Bitmap (PNG) output draws printable ASCII only and substitutes
+	// '?' for other characters; long captions are shrunk, then truncated.
+	Caption string
+
+	// Scheme adds a branding strip above the symbol.
+	Scheme Scheme
+}
+
+// withDefaults returns a copy of o with zero values replaced by defaults.
+func (o Options) withDefaults() (Options, error) {
+	if o.ModuleSize == 0 {
+		o.ModuleSize = DefaultModuleSize
+	}
+	if o.ModuleSize < 0 || o.ModuleSize > maxModuleSize {
+		return o, fmt.Errorf("%w: module size %d must be 1–%d", ErrInvalidOptions, o.ModuleSize, maxModuleSize)
+	}
+	switch {
+	case o.QuietZone == 0:
+		o.QuietZone = DefaultQuietZone
+	case o.QuietZone < 0:
+		o.QuietZone = 0
+	}
+	if o.MaxVersion < 0 || o.MaxVersion > qr.MaxVersion {
+		return o, fmt.Errorf("%w: max version %d must be 1–%d", ErrInvalidOptions, o.MaxVersion, qr.MaxVersion)
+	}
+	if o.ErrorCorrection < ECDefault || o.ErrorCorrection > ECHigh {
+		return o, fmt.Errorf("%w: unknown error correction level %d", ErrInvalidOptions, o.ErrorCorrection)
+	}
+	if o.Logo != nil {
+		if o.LogoScale == 0 {
+			o.LogoScale = DefaultLogoScale
+		}
+		if o.LogoScale < 0 || o.LogoScale > maxLogoScale {
+			return o, fmt.Errorf("%w: logo scale %g must be in (0, %g]", ErrInvalidOptions, o.LogoScale, maxLogoScale)
+		}
+	}
+	if _, ok := schemeStyles[o.Scheme]; !ok && o.Scheme != SchemeNone {
+		return o, fmt.Errorf("%w: unknown scheme %d", ErrInvalidOptions, o.Scheme)
+	}
+	if o.Foreground == nil {
+		o.Foreground = color.Black
+	}
+	if o.Background == nil {
+		o.Background = color.White
+	}
+	return o, nil
+}
+
+// Symbol is an encoded QR Code ready to be drawn.
+type Symbol struct {
+	// Raw is the EMV payload string carried by the symbol.
+	Raw string
+	// Version is the QR Code version (1–40) chosen for the payload.
+	Version int
+	// ErrorCorrection is the level the symbol was built with.
+	ErrorCorrection ErrorCorrection
+
+	opts Options
+	code *qr.Code
+}
+
+// New builds a Symbol for an encoded EMV payload string. Unless
+// opts.SkipPayloadValidation is set, raw must decode successfully.
+func New(raw string, opts Options) (*Symbol, error) {
+	return NewContext(context.Background(), raw, opts)
+}
+
+// NewContext is like New but gives up with ctx's error once ctx is done,
+// and passes ctx to the payload check, so that request-scoped services can
+// bound rendering, including any merchant verification, by a deadline.
+func NewContext(ctx context.Context, raw string, opts Options) (*Symbol, error) {
+	o, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !o.SkipPayloadValidation {
+		if _, err := emvqr.DecodeContext(ctx, raw, o.Decode); err != nil {
+			return nil, fmt.Errorf("render: invalid payload: %w", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	ec := o.ErrorCorrection
+	if ec == ECDefault {
+		ec = ECMedium
+	}
+	var code *qr.Code
+	if o.Logo != nil {
+		code, ec, err = encodeWithLogo(ctx, []byte(raw), o, ec)
+	} else {
+		code, err = qr.Encode([]byte(raw), qr.Options{Level: ec.level(), MaxVersion: o.MaxVersion})
+	}
+	if errors.Is(err, qr.ErrDataTooLong) {
+		return nil, fmt.Errorf("%w: %d chars at level %s", ErrTooLarge, len(raw), ec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("render: %w", err)
+	}
+	return &Symbol{Raw: raw, Version: code.Version, ErrorCorrection: ec, opts: o, code: code}, nil
+}
+
+// NewFromPayload encodes p with emvqr.Encode and builds a Symbol for it.
+func NewFromPayload(p *emvqr.Payload, opts Options) (*Symbol, error) {
+	return NewFromPayloadContext(context.Background(), p, opts)
+}
+
+// NewFromPayloadContext is like NewFromPayload but gives up with ctx's
+// error once ctx is done.
+func NewFromPayloadContext(ctx context.Context, p *emvqr.Payload, opts Options) (*Symbol, error) {
+	raw, err := emvqr.Encode(p)
+	if err != nil {
+		return nil, err
+	}
+	opts.SkipPayloadValidation = true
+	return NewContext(ctx, raw, opts)
+}
+
+// Size returns the side length of the symbol in modules, excluding the quiet
+// zone.
+func (s *Symbol) Size() int {
+	return s.code.Size
+}
+
+// Dark reports whether the module at column x, row y is dark. Coordinates
+// are relative to the symbol, excluding the quiet zone.
+func (s *Symbol) Dark(x, y int) bool {
+	return s.code.Dark(x, y)
+}
+
+// Options returns the effective options, with defaults applied.
+func (s *Symbol) Options() Options {
+	return s.opts
+}
+
+// Image returns the symbol as an image including the quiet zone. A plain
+// symbol is a two-colour paletted image; branded artwork (logo, caption or
+// scheme strip) is RGBA.
+func (s *Symbol) Image() image.Image {
+	if s.branded() {
+		return s.brandedImage(s.layout())
+	}
+	return s.symbolImage()
+}
+
+// symbolImage draws the bare symbol and quiet zone.
+func (s *Symbol) symbolImage() *image.Paletted {
+	ms, qz := s.opts.ModuleSize, s.opts.QuietZone
+	side := (s.code.Size + 2*qz) * ms
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{s.opts.Background, s.opts.Foreground})
+	for y := 0; y < s.code.Size; y++ {
+		for x := 0; x < s.code.Size; x++ {
+			if !s.code.Dark(x, y) {
+				continue
+			}
+			px, py := (x+qz)*ms, (y+qz)*ms
+			for dy := 0; dy < ms; dy++ {
+				row := img.Pix[(py+dy)*img.Stride+px:]
+				for dx := 0; dx < ms; dx++ {
+					row[dx] = 1
+				}
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG writes the symbol as a PNG image.
+func (s *Symbol) WritePNG(w io.Writer) error {
+	return png.Encode(w, s.Image())
+}
+
+// WriteSVG writes the symbol as an SVG document. Dark modules are drawn as a
+// single path in module units, scaled by ModuleSize.
+func (s *Symbol) WriteSVG(w io.Writer) error {
+	if s.branded() {
+		return s.writeBrandedSVG(w, s.layout())
+	}
+	qz := s.opts.QuietZone
+	side := s.code.Size + 2*qz
+	px := side * s.opts.ModuleSize
+	if _, err := fmt.Fprintf(w,
+		`<svg xmlns="http://www.w3.org/2000/svg" version="1.1" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">`+"\n"+
+			`<rect width="%d" height="%d" fill="%s"/>`+"\n"+`<path fill="%s" d="`,
+		side, side, px, px, side, side, hexColor(s.opts.Background), hexColor(s.opts.Foreground)); err != nil {
+		return err
+	}
+	if err := s.writeModulePath(w, qz); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\"/>\n</svg>\n")
+	return err
+}
+
+// writeModulePath writes the path data for the dark modules in module units,
+// offset by off modules on both axes.
+func (s *Symbol) writeModulePath(w io.Writer, off int) error {
+	for y := 0; y < s.code.Size; y++ {
+		for x := 0; x < s.code.Size; x++ {
+			if s.code.Dark(x, y) {
+				if _, err := fmt.Fprintf(w, "M%d,%dh1v1h-1z", x+off, y+off); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PNG renders raw as a PNG image.
+func PNG(w io.Writer, raw string, opts Options) error {
+	s, err := New(raw, opts)
+	if err != nil {
+		return err
+	}
+	return s.WritePNG(w)
+}
+
+// SVG renders raw as an SVG document.
+func SVG(w io.Writer, raw string, opts Options) error {
+	s, err := New(raw, opts)
+	if err != nil {
+		return err
+	}
+	return s.WriteSVG(w)
+}
+
+// PDF renders raw as a single-page PDF document.
+func PDF(w io.Writer, raw string, opts Options) error {
+	s, err := New(raw, opts)
+	if err != nil {
+		return err
+	}
+	return s.WritePDF(w)
+}
+
+// hexColor formats c as "#RRGGBB".
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02X%02X%02X", r>>8, g>>8, b>>8)
+}
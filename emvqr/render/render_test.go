@@ -0,0 +1,140 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// staticQR is the EMVCo base example (static QR, single Visa MAI).
+const staticQR = "000201021640001234567890125204525153038405802US5911ABC Hammers6008New York63047222"
+
+func TestNew_DefaultOptions(t *testing.T) {
+	s, err := New(staticQR, Options{})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	if s.ErrorCorrection != ECMedium {
+		t.Errorf("ErrorCorrection = %s, want M", s.ErrorCorrection)
+	}
+	if s.Size() != s.Version*4+17 {
+		t.Errorf("Size() = %d, inconsistent with version %d", s.Size(), s.Version)
+	}
+	o := s.Options()
+	if o.ModuleSize != DefaultModuleSize || o.QuietZone != DefaultQuietZone {
+		t.Errorf("defaults not applied: %+v", o)
+	}
+}
+
+func TestWritePNG_Dimensions(t *testing.T) {
+	s, err := New(staticQR, Options{ModuleSize: 5, QuietZone: 2})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := s.WritePNG(&buf); err != nil {
+		t.Fatalf("WritePNG error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode error: %v", err)
+	}
+	want := (s.Size() + 4) * 5
+	if b := img.Bounds(); b.Dx() != want || b.Dy() != want {
+		t.Fatalf("image is %dx%d, want %dx%d", b.Dx(), b.Dy(), want, want)
+	}
+	// Top-left pixel is quiet zone; the first finder module is dark.
+	if r, _, _, _ := img.At(0, 0).RGBA(); r != 0xFFFF {
+		t.Error("quiet zone pixel is not white")
+	}
+	if r, _, _, _ := img.At(10, 10).RGBA(); r != 0 {
+		t.Error("finder pattern pixel is not black")
+	}
+}
+
+func TestWriteSVG_Structure(t *testing.T) {
+	var buf bytes.Buffer
+	err := SVG(&buf, staticQR, Options{Foreground: color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xFF}})
+	if err != nil {
+		t.Fatalf("SVG error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"<svg", `fill="#123456"`, `fill="#FFFFFF"`, "M4,4h1v1h-1z", "</svg>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG output missing %q", want)
+		}
+	}
+}
+
+func TestNew_RejectsInvalidPayload(t *testing.T) {
+	if _, err := New("not an emv payload", Options{}); err == nil {
+		t.Fatal("expected error for invalid payload, got nil")
+	}
+	if _, err := New("not an emv payload", Options{SkipPayloadValidation: true}); err != nil {
+		t.Fatalf("unexpected error with SkipPayloadValidation: %v", err)
+	}
+}
+
+func TestNew_TooLarge(t *testing.T) {
+	_, err := New(staticQR, Options{MaxVersion: 2, ErrorCorrection: ECHigh})
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	for _, o := range []Options{{ModuleSize: -1}, {MaxVersion: 41}, {ErrorCorrection: 9}} {
+		if _, err := New(staticQR, o); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("options %+v: expected ErrInvalidOptions, got %v", o, err)
+		}
+	}
+}
+
+func TestNewFromPayload(t *testing.T) {
+	p := emvqr.NewPayload()
+	_ = p.AddMerchantIdentifier("02", "4000123456789012")
+	p.MerchantCategoryCode = "5251"
+	p.TransactionCurrency = "840"
+	p.CountryCode = "US"
+	p.MerchantName = "ABC Hammers"
+	p.MerchantCity = "New York"
+
+	s, err := NewFromPayload(p, Options{ErrorCorrection: ECQuartile})
+	if err != nil {
+		t.Fatalf("NewFromPayload error: %v", err)
+	}
+	if s.Raw != staticQR {
+		t.Errorf("Raw = %q, want %q", s.Raw, staticQR)
+	}
+	if _, err := NewFromPayload(emvqr.NewPayload(), Options{}); err == nil {
+		t.Error("expected encode error for incomplete payload, got nil")
+	}
+}
+
+func TestNewContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewContext(ctx, staticQR, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled context: error %v, want context.Canceled", err)
+	}
+
+	type key struct{}
+	ctx = context.WithValue(context.Background(), key{}, "req-1")
+	var seen any
+	opts := Options{Decode: emvqr.DecodeOptions{VerifyMerchant: func(ctx context.Context, _ *emvqr.Payload) error {
+		seen = ctx.Value(key{})
+		return nil
+	}}}
+	if _, err := NewContext(ctx, staticQR, opts); err != nil {
+		t.Fatalf("NewContext error: %v", err)
+	}
+	if seen != "req-1" {
+		t.Errorf("VerifyMerchant saw context value %v, want req-1", seen)
+	}
+}
@@ -0,0 +1,184 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+// PageSize is a paper size in PDF points (1/72 inch).
+type PageSize struct {
+	Width, Height float64
+}
+
+// Paper sizes for WriteSheet.
+var (
+	PageA4     = PageSize{595.28, 841.89}
+	PageLetter = PageSize{612, 792}
+)
+
+// Defaults applied to zero-valued SheetOptions fields.
+const (
+	DefaultSheetColumns = 3
+	DefaultSheetRows    = 4
+	DefaultSheetMargin  = 36 // half an inch
+	DefaultSheetGutter  = 24
+)
+
+// Cut mark geometry, in points: each mark starts cutMarkGap outside the
+// corner of its sticker and is cutMarkLength long.
+const (
+	cutMarkGap    = 2
+	cutMarkLength = 8
+)
+
+// SheetOptions controls WriteSheet.
+type SheetOptions struct {
+	// Page is the paper size. The zero value selects PageA4.
+	Page PageSize
+
+	// Columns and Rows set the stickers per page. Zero selects
+	// DefaultSheetColumns and DefaultSheetRows.
+	Columns, Rows int
+
+	// Margin is the blank border of the page and Gutter the space between
+	// stickers, in points. Zero selects DefaultSheetMargin and
+	// DefaultSheetGutter.
+	Margin, Gutter float64
+
+	// NoCutMarks leaves out the marks at the corners of each sticker.
+	NoCutMarks bool
+}
+
+// withDefaults returns a copy of o with zero values replaced by defaults.
+func (o SheetOptions) withDefaults() (SheetOptions, error) {
+	if o.Page == (PageSize{}) {
+		o.Page = PageA4
+	}
+	if o.Columns == 0 {
+		o.Columns = DefaultSheetColumns
+	}
+	if o.Rows == 0 {
+		o.Rows = DefaultSheetRows
+	}
+	if o.Margin == 0 {
+		o.Margin = DefaultSheetMargin
+	}
+	if o.Gutter == 0 {
+		o.Gutter = DefaultSheetGutter
+	}
+	switch {
+	case o.Page.Width <= 0 || o.Page.Height <= 0:
+		return o, fmt.Errorf("%w: page size %gx%g", ErrInvalidOptions, o.Page.Width, o.Page.Height)
+	case o.Columns < 0 || o.Rows < 0 || o.Margin < 0 || o.Gutter < 0:
+		return o, fmt.Errorf("%w: negative sheet layout", ErrInvalidOptions)
+	}
+	if w, h := o.cellSize(); w <= 0 || h <= 0 {
+		return o, fmt.Errorf("%w: %dx%d stickers do not fit on a %gx%g page", ErrInvalidOptions,
+			o.Columns, o.Rows, o.Page.Width, o.Page.Height)
+	}
+	return o, nil
+}
+
+// cellSize returns the width and height of the area of one sticker.
+func (o SheetOptions) cellSize() (float64, float64) {
+	w := (o.Page.Width - 2*o.Margin - float64(o.Columns-1)*o.Gutter) / float64(o.Columns)
+	h := (o.Page.Height - 2*o.Margin - float64(o.Rows-1)*o.Gutter) / float64(o.Rows)
+	return w, h
+}
+
+// WriteSheet lays symbols out on print-ready PDF pages, Columns by Rows
+// per page, for field teams printing stickers in bulk. Each symbol is
+// drawn with its own branding, such as a Caption with the merchant name
+// and a scheme strip, scaled to fit its cell and centred in it, with cut
+// marks at its corners. Pages are filled left to right, top to bottom.
+func WriteSheet(w io.Writer, symbols []*Symbol, opts SheetOptions) error {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return err
+	}
+	if len(symbols) == 0 {
+		return fmt.Errorf("%w: no symbols for the sheet", ErrInvalidOptions)
+	}
+	doc := newPDFDocument()
+	cellW, cellH := opts.cellSize()
+	perPage := opts.Columns * opts.Rows
+	for start := 0; start < len(symbols); start += perPage {
+		page := &pdfCanvas{height: opts.Page.Height}
+		for i, s := range symbols[start:min(start+perPage, len(symbols))] {
+			x := opts.Margin + float64(i%opts.Columns)*(cellW+opts.Gutter)
+			top := opts.Margin + float64(i/opts.Columns)*(cellH+opts.Gutter)
+			l := s.layout()
+			k := min(cellW/float64(l.width), cellH/float64(l.height))
+			sw, sh := k*float64(l.width), k*float64(l.height)
+			x, top = x+(cellW-sw)/2, top+(cellH-sh)/2
+
+			sticker := &pdfCanvas{height: float64(l.height)}
+			s.drawPDF(doc, sticker, l)
+			fmt.Fprintf(&page.buf, "q %s 0 0 %s %s %s cm\n", pdfNum(k), pdfNum(k), pdfNum(x), pdfNum(opts.Page.Height-top-sh))
+			page.buf.Write(sticker.buf.Bytes())
+			page.buf.WriteString("Q\n")
+			if !opts.NoCutMarks {
+				page.cutMarks(x, top, sw, sh)
+			}
+		}
+		if err := doc.addPage(opts.Page.Width, opts.Page.Height, page.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	_, err = doc.WriteTo(w)
+	return err
+}
+
+// WriteSheetPayloads renders payloads with opts, captioning each with its
+// merchant name unless opts.Caption is set, and lays them out with
+// WriteSheet.
+func WriteSheetPayloads(w io.Writer, payloads []*emvqr.Payload, opts Options, sheet SheetOptions) error {
+	symbols := make([]*Symbol, len(payloads))
+	for i, p := range payloads {
+		o := opts
+		if o.Caption == "" {
+			o.Caption = p.MerchantName
+		}
+		s, err := NewFromPayload(p, o)
+		if err != nil {
+			return fmt.Errorf("render: payload %d (%s): %w", i, p.MerchantName, err)
+		}
+		symbols[i] = s
+	}
+	return WriteSheet(w, symbols, sheet)
+}
+
+// cutMarks strokes the corner marks of the sticker whose top-left corner is
+// at x, top, in top-left page coordinates.
+func (c *pdfCanvas) cutMarks(x, top, w, h float64) {
+	fmt.Fprintf(&c.buf, "0.5 w %s\n", pdfStroke(color.Black))
+	for _, cx := range []float64{x, x + w} {
+		dx := float64(cutMarkGap)
+		if cx == x {
+			dx = -dx
+		}
+		for _, cy := range []float64{top, top + h} {
+			dy := float64(cutMarkGap)
+			if cy == top {
+				dy = -dy
+			}
+			// A horizontal and a vertical stroke, pointing away from the sticker.
+			c.line(cx+dx, cy, cx+dx*(1+cutMarkLength/cutMarkGap), cy)
+			c.line(cx, cy+dy, cx, cy+dy*(1+cutMarkLength/cutMarkGap))
+		}
+	}
+}
+
+// line strokes a line between two points in top-left page coordinates.
+func (c *pdfCanvas) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&c.buf, "%s %s m %s %s l S\n", pdfNum(x1), pdfNum(c.height-y1), pdfNum(x2), pdfNum(c.height-y2))
+}
+
+// pdfStroke returns the operator setting the stroke colour to col.
+func pdfStroke(col color.Color) string {
+	r, g, b, _ := col.RGBA()
+	return fmt.Sprintf("%s %s %s RG", pdfNum(float64(r)/0xFFFF), pdfNum(float64(g)/0xFFFF), pdfNum(float64(b)/0xFFFF))
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+)
+
+func testSymbols(t *testing.T, n int) []*Symbol {
+	t.Helper()
+	symbols := make([]*Symbol, n)
+	for i := range symbols {
+		s, err := New(staticQR, Options{Caption: "ABC Hammers", Scheme: SchemeBharatQR})
+		if err != nil {
+			t.Fatalf("New error: %v", err)
+		}
+		symbols[i] = s
+	}
+	return symbols
+}
+
+func TestWriteSheet_Pages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSheet(&buf, testSymbols(t, 5), SheetOptions{Columns: 2, Rows: 2}); err != nil {
+		t.Fatalf("WriteSheet error: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatal("missing PDF header or trailer")
+	}
+	for _, want := range []string{"/Count 2", "/MediaBox [0 0 595.28 841.89]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("PDF output missing %q", want)
+		}
+	}
+	if got := strings.Count(out, "/Type /Page "); got != 2 {
+		t.Errorf("pages = %d, want 2", got)
+	}
+}
+
+func TestWriteSheet_Letter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSheet(&buf, testSymbols(t, 1), SheetOptions{Page: PageLetter, NoCutMarks: true}); err != nil {
+		t.Fatalf("WriteSheet error: %v", err)
+	}
+	for _, want := range []string{"/Count 1", "/MediaBox [0 0 612 792]"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("PDF output missing %q", want)
+		}
+	}
+}
+
+func TestWriteSheet_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		symbols int
+		opts    SheetOptions
+	}{
+		{"no symbols", 0, SheetOptions{}},
+		{"negative columns", 1, SheetOptions{Columns: -1}},
+		{"negative page", 1, SheetOptions{Page: PageSize{-1, 100}}},
+		{"margin too wide", 1, SheetOptions{Margin: 400}},
+		{"too many rows", 1, SheetOptions{Rows: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WriteSheet(&bytes.Buffer{}, testSymbols(t, tt.symbols), tt.opts)
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("err = %v, want ErrInvalidOptions", err)
+			}
+		})
+	}
+}
+
+func TestWriteSheetPayloads(t *testing.T) {
+	p, err := emvqr.Decode(staticQR)
+	if err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteSheetPayloads(&buf, []*emvqr.Payload{p, p}, Options{}, SheetOptions{}); err != nil {
+		t.Fatalf("WriteSheetPayloads error: %v", err)
+	}
+	// The merchant name caption needs the text font.
+	for _, want := range []string{"/Count 1", "/BaseFont /Helvetica"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("PDF output missing %q", want)
+		}
+	}
+
+	bad := &emvqr.Payload{MerchantName: "Broken"}
+	err = WriteSheetPayloads(&bytes.Buffer{}, []*emvqr.Payload{p, bad}, Options{}, SheetOptions{})
+	if err == nil || !strings.Contains(err.Error(), "payload 1 (Broken)") {
+		t.Errorf("err = %v, want failure naming payload 1", err)
+	}
+}
+
+func TestPDFCanvas_CutMarks(t *testing.T) {
+	c := &pdfCanvas{height: 100}
+	c.cutMarks(10, 10, 50, 50)
+	got := c.buf.String()
+	if n := strings.Count(got, " l S\n"); n != 8 {
+		t.Errorf("strokes = %d, want 8", n)
+	}
+	// The top-left horizontal mark runs from 2pt to 10pt left of the corner.
+	if want := "8 90 m 0 90 l S\n"; !strings.Contains(got, want) {
+		t.Errorf("cut marks missing top-left stroke, got:\n%s", got)
+	}
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"bufio"
+	"io"
+)
+
+// TextStyle selects the characters WriteText draws with.
+type TextStyle int
+
+// Text styles.
+const (
+	// TextHalfBlock packs two module rows into each line using the Unicode
+	// half-block characters, keeping the symbol roughly square.
+	TextHalfBlock TextStyle = iota
+	// TextASCII draws each module as two ASCII characters ("##" or "  ")
+	// on its own line, for logs and terminals without Unicode support.
+	TextASCII
+)
+
+// TextOptions controls text output.
+type TextOptions struct {
+	Style TextStyle
+
+	// LightBackground is set when the terminal draws dark text on a light
+	// background. By default the output assumes light-on-dark terminals and
+	// draws the light modules, so the symbol scans the right way round.
+	LightBackground bool
+}
+
+// WriteText writes the symbol, with its quiet zone, as lines of text that can
+// be scanned straight off a terminal or test log. Branding options are
+// ignored.
+func (s *Symbol) WriteText(w io.Writer, opts TextOptions) error {
+	qz := s.opts.QuietZone
+	side := s.code.Size + 2*qz
+	// ink reports whether the module at (x, y), in quiet-zone coordinates,
+	// is drawn with a glyph rather than left blank. Positions outside the
+	// symbol, including the pad row below an odd-height symbol, are light.
+	ink := func(x, y int) bool {
+		return s.code.Dark(x-qz, y-qz) == opts.LightBackground
+	}
+
+	bw := bufio.NewWriter(w)
+	if opts.Style == TextASCII {
+		for y := 0; y < side; y++ {
+			for x := 0; x < side; x++ {
+				if ink(x, y) {
+					bw.WriteString("##")
+				} else {
+					bw.WriteString("  ")
+				}
+			}
+			bw.WriteByte('\n')
+		}
+		return bw.Flush()
+	}
+
+	for y := 0; y < side; y += 2 {
+		for x := 0; x < side; x++ {
+			switch top, bottom := ink(x, y), ink(x, y+1); {
+			case top && bottom:
+				bw.WriteString("█")
+			case top:
+				bw.WriteString("▀")
+			case bottom:
+				bw.WriteString("▄")
+			default:
+				bw.WriteByte(' ')
+			}
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// Text renders raw as terminal text.
+func Text(w io.Writer, raw string, opts Options, textOpts TextOptions) error {
+	s, err := New(raw, opts)
+	if err != nil {
+		return err
+	}
+	return s.WriteText(w, textOpts)
+}
@@ -0,0 +1,50 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWriteText_HalfBlock(t *testing.T) {
+	s, err := New(staticQR, Options{QuietZone: 1})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := s.WriteText(&buf, TextOptions{}); err != nil {
+		t.Fatalf("WriteText error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	side := s.Size() + 2
+	if len(lines) != (side+1)/2 {
+		t.Fatalf("got %d lines, want %d", len(lines), (side+1)/2)
+	}
+	for i, l := range lines {
+		if n := utf8.RuneCountInString(l); n != side {
+			t.Fatalf("line %d has %d columns, want %d", i, n, side)
+		}
+	}
+	// Row 0 is quiet zone (light, drawn), row 1 the finder's dark top edge.
+	if got := []rune(lines[0])[1]; got != '▀' {
+		t.Errorf("lines[0][1] = %q, want '▀'", got)
+	}
+	if got := []rune(lines[0])[0]; got != '█' {
+		t.Errorf("lines[0][0] = %q, want '█'", got)
+	}
+}
+
+func TestWriteText_ASCIILightBackground(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Text(&buf, staticQR, Options{QuietZone: -1}, TextOptions{Style: TextASCII, LightBackground: true}); err != nil {
+		t.Fatalf("Text error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if want := "##############  "; !strings.HasPrefix(lines[0], want) {
+		t.Errorf("first row = %q, want prefix %q", lines[0], want)
+	}
+	if len(lines[0]) != 2*len(lines) {
+		t.Errorf("rows are %d chars for %d lines, want two chars per module", len(lines[0]), len(lines))
+	}
+}
@@ -0,0 +1,114 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Correction kinds, as reported in Correction.Kind.
+const (
+	RepairWhitespace = "whitespace" // surrounding whitespace removed
+	RepairDigit      = "digit"      // letter misread for a digit, e.g. 'O' for '0'
+	RepairCRCCase    = "crc-case"   // lowercase CRC hex digits upper-cased
+)
+
+// Correction is a change made by Repair.
+type Correction struct {
+	Kind     string
+	Path     string // dotted tag path of the field changed; "" for the payload as a whole
+	Old, New string // the field, or the ID and length, before and after
+}
+
+func (c Correction) String() string {
+	if c.Path == "" {
+		return fmt.Sprintf("%s: %q -> %q", c.Kind, c.Old, c.New)
+	}
+	return fmt.Sprintf("%s at tag %s: %q -> %q", c.Kind, c.Path, c.Old, c.New)
+}
+
+// digitLookalikes maps the characters optical scanners and OCR confuse with
+// digits onto the digit.
+var digitLookalikes = map[byte]byte{
+	'O': '0', 'o': '0', 'Q': '0', 'D': '0',
+	'I': '1', 'l': '1', '|': '1',
+	'Z': '2', 'S': '5', 'B': '8',
+}
+
+// Repair corrects common optical-scan errors in raw, such as those of a worn
+// printed sticker, and returns the corrected string with every correction
+// made:
+//
+//   - whitespace around the payload, e.g. a trailing newline, is removed;
+//   - letters that look like digits, such as 'O' for '0' or 'l' for '1', are
+//     replaced in IDs, lengths and numeric fields (Tags 00, 01, 52–57 and
+//     numeric sub-fields such as 26.02);
+//   - lowercase CRC hex digits are upper-cased.
+//
+// Letters are never replaced in alphanumeric fields, where they may be
+// genuine. Repair stops at the first object whose length cannot be read;
+// the rest is returned unchanged for Decode to report.
+func Repair(raw string) (string, []Correction) {
+	var corrections []Correction
+	trimmed := strings.TrimSpace(raw)
+	if trimmed != raw {
+		corrections = append(corrections, Correction{RepairWhitespace, "", raw, trimmed})
+	}
+	b := []byte(trimmed)
+	repairObjects(b, "", &corrections)
+	return string(b), corrections
+}
+
+// DecodeRepaired repairs raw with Repair and decodes the result with opts.
+// It returns the corrections made even when decoding fails, so that they can
+// be logged.
+func DecodeRepaired(raw string, opts DecodeOptions) (*Payload, []Correction, error) {
+	repaired, corrections := Repair(raw)
+	p, err := DecodeWithOptions(repaired, opts)
+	return p, corrections, err
+}
+
+// repairObjects repairs in place the objects of b, the value of the
+// template at path ("" at the top level).
+func repairObjects(b []byte, path string, corrections *[]Correction) {
+	for len(b) >= 4 {
+		header := string(b[:4])
+		if repairDigits(b[:4]) {
+			*corrections = append(*corrections, Correction{RepairDigit, joinPath(path, string(b[:2])), header, string(b[:4])})
+		}
+		length, ok := parseTLVLength(b[2], b[3])
+		if !ok || !isNumeric(string(b[:2])) || len(b) < 4+length {
+			return
+		}
+		id, value := string(b[:2]), b[4:4+length]
+		b = b[4+length:]
+		field := joinPath(path, id)
+		old := string(value)
+		switch {
+		case path == "" && id == IDCRC:
+			if upper := strings.ToUpper(old); upper != old {
+				copy(value, upper)
+				*corrections = append(*corrections, Correction{RepairCRCCase, field, old, upper})
+			}
+		case path == "" && isTemplateID(id),
+			path == IDAdditionalDataFieldTemplate && isPaymentSystemTemplateID(id):
+			repairObjects(value, field, corrections)
+		default:
+			if f := pathSpec(field).format; (f == formatN || f == formatAmount) && repairDigits(value) {
+				*corrections = append(*corrections, Correction{RepairDigit, field, old, string(value)})
+			}
+		}
+	}
+}
+
+// repairDigits replaces the digit lookalikes in b and reports whether it
+// changed anything.
+func repairDigits(b []byte) bool {
+	changed := false
+	for i, c := range b {
+		if d, ok := digitLookalikes[c]; ok {
+			b[i] = d
+			changed = true
+		}
+	}
+	return changed
+}
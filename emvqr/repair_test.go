@@ -0,0 +1,63 @@
+package emvqr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	// The sample as misread from a worn sticker: 'O' for '0' in the Tag 53
+	// length, 'S' for '5' in the currency, 'O' for '0' in the Tag 54 amount,
+	// a lowercase CRC and a trailing newline.
+	damaged := strings.Replace(realWorldBharatQRPayload, "5303356", "53O33S6", 1)
+	damaged = strings.Replace(damaged, "5406250.00", "5406250.O0", 1)
+	damaged = damaged[:len(damaged)-4] + "51dd\r\n"
+
+	if _, err := Decode(damaged); err == nil {
+		t.Fatal("damaged payload decodes without repair")
+	}
+	p, corrections, err := DecodeRepaired(damaged, DecodeOptions{})
+	if err != nil {
+		t.Fatalf("DecodeRepaired() error: %v", err)
+	}
+	assertEqual(t, "amount", "250.00", p.TransactionAmount)
+	assertEqual(t, "currency", "356", p.TransactionCurrency)
+
+	var got []string
+	for _, c := range corrections {
+		got = append(got, c.Kind+"@"+c.Path)
+	}
+	assertEqual(t, "corrections", "whitespace@ digit@53 digit@53 digit@54 crc-case@63", strings.Join(got, " "))
+}
+
+func TestRepair_LeavesAlphanumericFields(t *testing.T) {
+	// "SBIPMOPAD" in Tag 26.01 and "BOOK" in Tag 59 must keep their letters.
+	p := basePayload()
+	p.MerchantName = "BOOK SHOP"
+	raw := mustEncode(t, p)
+	repaired, corrections := Repair(raw)
+	assertEqual(t, "repaired", raw, repaired)
+	if len(corrections) != 0 {
+		t.Errorf("corrections = %v, want none", corrections)
+	}
+	if repaired, corrections = Repair(realWorldBharatQRPayload); repaired != realWorldBharatQRPayload || len(corrections) != 0 {
+		t.Errorf("Repair changed the sample: %v", corrections)
+	}
+}
+
+func TestRepair_SubField(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.UPIVPAInfo.MinimumAmount = "10.00"
+	p.CRC = ""
+	raw := mustEncode(t, p)
+	damaged := strings.Replace(raw, "020510.00", "0205lO.00", 1)
+	repaired, corrections := Repair(damaged)
+	assertEqual(t, "repaired", raw[:len(raw)-4], repaired[:len(repaired)-4])
+	if len(corrections) != 1 || corrections[0].Path != "26.02" {
+		t.Fatalf("corrections = %v, want one at 26.02", corrections)
+	}
+	assertEqual(t, "String", `digit at tag 26.02: "lO.00" -> "10.00"`, corrections[0].String())
+}
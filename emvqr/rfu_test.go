@@ -0,0 +1,50 @@
+package emvqr
+
+import (
+	"fmt"
+	"testing"
+)
+
+// withCRC appends the CRC to s, which must end before "6304".
+func withCRC(s string) string {
+	s += "6304"
+	return s + crcString(crc16CCITT([]byte(s)))
+}
+
+func TestRFUFields_RoundTripPosition(t *testing.T) {
+	raw := withCRC("000201" + "02164000123456789012520452515303840540510.006502AB" + "6601C" +
+		"5802US" + "6703XYZ" + "5911ABC Hammers6008New York")
+	p, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	assertEqual(t, "RFUAfter", "[54 65 58]", fmt.Sprint(p.RFUAfter))
+
+	assertEqual(t, "Encode", raw, mustEncode(t, p))
+
+	// Without Tag 54 to follow, 65 and the 66 that follows it move to the
+	// end; 67 keeps its place.
+	p.TransactionAmount = ""
+	want := withCRC("000201" + "02164000123456789012520452515303840" + "5802US" + "6703XYZ" +
+		"5911ABC Hammers6008New York" + "6502AB6601C")
+	assertEqual(t, "Encode without 54", want, mustEncode(t, p))
+}
+
+func TestRFUFields_DefaultPosition(t *testing.T) {
+	p := basePayload()
+	p.RFUFields = []DataObject{{ID: "65", Value: "AB"}}
+	p.UnreservedTemplates = []UnreservedTemplate{{ID: "80", SubFields: []DataObject{{ID: "00", Value: "X"}}}}
+	got := mustEncode(t, p)
+	want := withCRC("000201021640001234567890125204525153038405802US5911ABC Hammers6008New York" +
+		"80050001X" + "6502AB")
+	assertEqual(t, "Encode", want, got)
+
+	p.RFUAfter = []string{"58"}
+	canonical, err := EncodeWithOptions(p, EncodeOptions{Canonical: true})
+	if err != nil {
+		t.Fatalf("Encode(Canonical) error: %v", err)
+	}
+	want = withCRC("000201021640001234567890125204525153038405802US5911ABC Hammers6008New York" +
+		"6502AB" + "80050001X")
+	assertEqual(t, "Encode(Canonical)", want, canonical)
+}
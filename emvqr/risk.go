@@ -0,0 +1,114 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Risk flag names, as reported in Warning.Check by RiskFlags.
+const (
+	RiskVPANameMismatch   = "vpa-name-mismatch"
+	RiskCurrencyMismatch  = "currency-mismatch"
+	RiskEditedPayload     = "edited-payload"
+	RiskStaticAmountLimit = "static-amount-limit"
+)
+
+// DefaultStaticAmountMax is the StaticAmountMax used by RiskFlags.
+const DefaultStaticAmountMax = "2000.00"
+
+// RiskOptions configures RiskFlagsWithOptions.
+type RiskOptions struct {
+	// StaticAmountMax is the largest amount a static QR (Tag 01 "11") may
+	// carry before it is flagged; "" disables the check.
+	StaticAmountMax string
+}
+
+// RiskFlags returns RiskFlagsWithOptions with a StaticAmountMax of
+// DefaultStaticAmountMax.
+func RiskFlags(p *Payload) []Warning {
+	return RiskFlagsWithOptions(p, RiskOptions{StaticAmountMax: DefaultStaticAmountMax})
+}
+
+// RiskFlagsWithOptions returns heuristic red flags that wallet teams show
+// before a payment, such as a sticker pasted over a merchant's genuine QR:
+//
+//   - the UPI VPA handle shares no word with the merchant name;
+//   - the currency is not that of the merchant's country;
+//   - signs of an edited payload whose CRC was recomputed: a merchant
+//     identifier that appears twice, or a Tag 27.01 reference differing
+//     from the Tag 62.05 reference label;
+//   - a static QR carrying an amount above opts.StaticAmountMax.
+//
+// The flags are hints, not verdicts: aggregator VPAs, for example, rarely
+// resemble the merchant name.
+func RiskFlagsWithOptions(p *Payload, opts RiskOptions) []Warning {
+	var flags []Warning
+	flag := func(check, path, format string, args ...any) {
+		flags = append(flags, Warning{check, path, fmt.Sprintf(format, args...)})
+	}
+	if vpa := p.GetMerchantVPA(); vpa != "" && p.MerchantName != "" && !vpaMatchesName(vpa, p.MerchantName) {
+		flag(RiskVPANameMismatch, IDUPIVPATemplate+".01",
+			"VPA %q does not resemble merchant name %q", vpa, p.MerchantName)
+	}
+	if want, ok := countryCurrencies[p.CountryCode]; ok && p.TransactionCurrency != "" && p.TransactionCurrency != want {
+		flag(RiskCurrencyMismatch, IDTransactionCurrency,
+			"currency %s is not %s, the currency of country %s", p.TransactionCurrency, want, p.CountryCode)
+	}
+	seen := make(map[string]bool, len(p.MerchantIdentifiers))
+	for _, mi := range p.MerchantIdentifiers {
+		if seen[mi.ID] {
+			flag(RiskEditedPayload, mi.ID, "merchant identifier %s appears more than once", mi.ID)
+		}
+		seen[mi.ID] = true
+	}
+	if !p.ReferencesConsistent() {
+		flag(RiskEditedPayload, IDUPIVPAReference+"."+UPIVPARefTransactionRef,
+			"Tag 27.01 reference %q differs from Tag 62.05 reference label %q",
+			p.UPITransactionRef.TransactionRef, p.AdditionalData.ReferenceLabel)
+	}
+	if limit := opts.StaticAmountMax; limit != "" && p.PointOfInitiationMethod == "11" && p.TransactionAmount != "" {
+		if c, err := compareAmounts(p.TransactionAmount, limit); err == nil && c > 0 {
+			flag(RiskStaticAmountLimit, IDTransactionAmount,
+				"static QR carries amount %s, above %s", p.TransactionAmount, limit)
+		}
+	}
+	return flags
+}
+
+// vpaMatchesName reports whether the handle of vpa (the part before '@')
+// contains a word of name of at least three letters, ignoring case and
+// punctuation, or the initials of its words.
+func vpaMatchesName(vpa, name string) bool {
+	handle, _, _ := strings.Cut(strings.ToLower(vpa), "@")
+	handle = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, handle)
+	words := strings.FieldsFunc(strings.ToLower(Transliterate(name)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	initials := ""
+	for _, w := range words {
+		initials += w[:1]
+		if len(w) >= 3 && strings.Contains(handle, w) {
+			return true
+		}
+	}
+	return len(initials) >= 2 && strings.Contains(handle, initials)
+}
+
+// countryCurrencies maps ISO 3166-1 alpha-2 country codes to the ISO 4217
+// numeric code of their currency.
+var countryCurrencies = map[string]string{
+	"AE": "784", "AT": "978", "AU": "036", "BD": "050", "BE": "978", "BR": "986",
+	"CA": "124", "CH": "756", "CN": "156", "DE": "978", "DK": "208", "ES": "978",
+	"FI": "978", "FR": "978", "GB": "826", "GR": "978", "HK": "344", "ID": "360",
+	"IE": "978", "IN": "356", "IT": "978", "JP": "392", "KE": "404", "KR": "410",
+	"LK": "144", "MX": "484", "MY": "458", "NG": "566", "NL": "978", "NO": "578",
+	"NP": "524", "NZ": "554", "PH": "608", "PK": "586", "PL": "985", "PT": "978",
+	"SA": "682", "SE": "752", "SG": "702", "TH": "764", "TR": "949", "US": "840",
+	"VN": "704", "ZA": "710",
+}
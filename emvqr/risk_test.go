@@ -0,0 +1,31 @@
+package emvqr
+
+import "testing"
+
+func TestRiskFlags(t *testing.T) {
+	p := basePayload()
+	p.CountryCode = "IN"
+	p.TransactionCurrency = "356"
+	p.MerchantName = "Sharma Kirana Store"
+	if err := p.SetUPIVPATemplate(RuPayRIDValue, "sharmakirana@okaxis", ""); err != nil {
+		t.Fatal(err)
+	}
+	if flags := RiskFlags(p); len(flags) != 0 {
+		t.Errorf("genuine payload flagged: %v", flags)
+	}
+	p.UPIVPAInfo.VPA = "SKS.store@ybl" // initials
+	if flags := RiskFlags(p); len(flags) != 0 {
+		t.Errorf("initials flagged: %v", flags)
+	}
+
+	p.UPIVPAInfo.VPA = "quickcash99@ybl"
+	p.TransactionCurrency = "840"
+	p.MerchantIdentifiers = append(p.MerchantIdentifiers, MerchantIdentifier{ID: "02", Value: "4111111111111111"})
+	p.PointOfInitiationMethod = "11"
+	p.TransactionAmount = "25000"
+	want := "vpa-name-mismatch@26.01 currency-mismatch@53 edited-payload@02 static-amount-limit@54"
+	assertEqual(t, "flags", want, lintChecks(RiskFlags(p)))
+
+	flags := RiskFlagsWithOptions(p, RiskOptions{StaticAmountMax: "50000"})
+	assertEqual(t, "raised limit", "vpa-name-mismatch@26.01 currency-mismatch@53 edited-payload@02", lintChecks(flags))
+}
@@ -0,0 +1,52 @@
+package emvqr
+
+import "fmt"
+
+// CheckRoundTrip decodes raw, encodes the payload, then decodes and encodes
+// the result again, and returns an error wrapping ErrRoundTrip if any step
+// panics, the first decode included, or fails, or the two encodings differ. Encode normalises some
+// inputs, such as fields out of order or a missing Payload Format
+// Indicator, so the first encoding need not equal raw; but once encoded, a
+// payload must be stable. CheckRoundTrip is meant for fuzzing, including by
+// code that builds on this package:
+//
+//	func FuzzRoundTrip(f *testing.F) {
+//	    f.Fuzz(func(t *testing.T, raw string) {
+//	        if err := emvqr.CheckRoundTrip(raw); err != nil {
+//	            t.Fatal(err)
+//	        }
+//	    })
+//	}
+//
+// The CRC is not validated, so that mutated inputs reach the parser, and
+// v1.1 fields are recognised. Inputs that do not decode, and payloads that
+// Encode rejects with an error, such as one missing a mandatory field, are
+// not failures: CheckRoundTrip returns nil for them.
+func CheckRoundTrip(raw string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newError(CodeRoundTrip, fmt.Errorf("%w: panic: %v", ErrRoundTrip, r))
+		}
+	}()
+	opts := DecodeOptions{SkipCRCValidation: true, SpecVersion: SpecVersion11, Limits: Limits{MaxPayloadLength: -1}}
+	first, err := DecodeWithOptions(raw, opts)
+	if err != nil {
+		return nil
+	}
+	encoded, err := EncodeWithOptions(first, EncodeOptions{SpecVersion: SpecVersion11})
+	if err != nil {
+		return nil
+	}
+	second, err := DecodeWithOptions(encoded, opts)
+	if err != nil {
+		return newError(CodeRoundTrip, fmt.Errorf("%w: encoded payload %q does not decode: %w", ErrRoundTrip, encoded, err))
+	}
+	reencoded, err := EncodeWithOptions(second, EncodeOptions{SpecVersion: SpecVersion11})
+	if err != nil {
+		return newError(CodeRoundTrip, fmt.Errorf("%w: decoded payload %q does not encode: %w", ErrRoundTrip, encoded, err))
+	}
+	if reencoded != encoded {
+		return newError(CodeRoundTrip, fmt.Errorf("%w: %q re-encodes as %q", ErrRoundTrip, encoded, reencoded))
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package emvqr
+
+import "testing"
+
+func FuzzCheckRoundTrip(f *testing.F) {
+	f.Add(realWorldBharatQRPayload)
+	f.Add(baseRaw)
+	f.Fuzz(func(t *testing.T, raw string) {
+		if err := CheckRoundTrip(raw); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	for _, raw := range []string{
+		realWorldBharatQRPayload,
+		baseRaw,
+		"not a QR code",
+		// No Payload Format Indicator or CRC: Encode adds them.
+		"020105202005303000580200591100000000000600800000000",
+	} {
+		if err := CheckRoundTrip(raw); err != nil {
+			t.Errorf("CheckRoundTrip(%q): %v", raw, err)
+		}
+	}
+}
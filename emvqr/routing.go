@@ -0,0 +1,73 @@
+package emvqr
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Capabilities describes what a consumer's wallet can pay with, for
+// SelectNetwork.
+type Capabilities struct {
+	// Networks are the networks the consumer can pay through, named as in
+	// PaymentOption.Network (e.g. "RuPay", "Visa", "UPI"), most preferred
+	// first.
+	Networks []string
+
+	// UPIHandles are the consumer's UPI handles, such as "okaxis" or
+	// "sbi". A UPI payment to a merchant VPA on one of them stays within
+	// the consumer's bank and is preferred over every other option.
+	UPIHandles []string
+
+	// CardPresent reports whether the consumer is at the merchant's
+	// terminal and can authenticate there, which Aadhaar Pay (Tag 28)
+	// requires.
+	CardPresent bool
+}
+
+// SelectNetwork returns the payment option of p to route a payment
+// through, given what the consumer can pay with. Options of networks not in
+// caps.Networks are skipped, as is Aadhaar Pay without caps.CardPresent.
+// Of the rest, a UPI option whose VPA is on one of caps.UPIHandles wins;
+// otherwise the order of caps.Networks decides, and then payload order. It
+// returns ErrNoPaymentOption if no option qualifies.
+func SelectNetwork(p *Payload, caps Capabilities) (PaymentOption, error) {
+	var best PaymentOption
+	bestRank := -1
+	var offered []string
+	for _, o := range p.PaymentOptions() {
+		if !slices.Contains(offered, o.Network) {
+			offered = append(offered, o.Network)
+		}
+		pref := slices.IndexFunc(caps.Networks, func(n string) bool { return strings.EqualFold(n, o.Network) })
+		if pref < 0 || o.Network == NetworkAadhaarPay && !caps.CardPresent {
+			continue
+		}
+		rank := len(caps.Networks) - pref
+		if o.Network == NetworkUPI && onUPIHandle(o.Identifier, caps.UPIHandles) {
+			rank = len(caps.Networks) + 1
+		}
+		if rank > bestRank {
+			best, bestRank = o, rank
+		}
+	}
+	switch {
+	case len(offered) == 0:
+		return PaymentOption{}, newError(CodeNoPaymentOption,
+			fmt.Errorf("%w: the payload offers no payment options", ErrNoPaymentOption))
+	case bestRank < 0:
+		return PaymentOption{}, newError(CodeNoPaymentOption,
+			fmt.Errorf("%w: the payload offers %s", ErrNoPaymentOption, strings.Join(offered, ", ")),
+			"networks", strings.Join(offered, ","))
+	}
+	return best, nil
+}
+
+// onUPIHandle reports whether vpa, such as "shop@okaxis", is on one of
+// handles, with or without their leading '@'.
+func onUPIHandle(vpa string, handles []string) bool {
+	_, handle, ok := strings.Cut(vpa, "@")
+	return ok && slices.ContainsFunc(handles, func(h string) bool {
+		return strings.EqualFold(strings.TrimPrefix(h, "@"), handle)
+	})
+}
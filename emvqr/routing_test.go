@@ -0,0 +1,56 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSelectNetwork(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	// The fixture's Aadhaar Template has an empty Aadhaar number, so it is
+	// not an option until one is set.
+	got, err := SelectNetwork(p, Capabilities{Networks: []string{"Aadhaar Pay", "Visa"}, CardPresent: true})
+	if err != nil || got.ID != "02" {
+		t.Errorf("SelectNetwork() without Aadhaar number = %s, %v; want 02", got.ID, err)
+	}
+	p.MerchantAadhaar.AadhaarNumber = "123456789012"
+
+	tests := []struct {
+		name string
+		caps Capabilities
+		want string
+	}{
+		{"first preference", Capabilities{Networks: []string{"rupay", "UPI"}}, "06"},
+		{"skips absent networks", Capabilities{Networks: []string{"JCB", "Mastercard", "Visa"}}, "04"},
+		{"on-us UPI handle", Capabilities{Networks: []string{"RuPay", "UPI"}, UPIHandles: []string{"@sbipay"}}, "26"},
+		{"handle without UPI", Capabilities{Networks: []string{"RuPay"}, UPIHandles: []string{"sbipay"}}, "06"},
+		{"Aadhaar Pay in person", Capabilities{Networks: []string{"Aadhaar Pay", "Visa"}, CardPresent: true}, "28"},
+		{"Aadhaar Pay remote", Capabilities{Networks: []string{"Aadhaar Pay", "Visa"}}, "02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectNetwork(p, tt.caps)
+			if err != nil {
+				t.Fatalf("SelectNetwork() error: %v", err)
+			}
+			assertEqual(t, "ID", tt.want, got.ID)
+		})
+	}
+
+	_, err = SelectNetwork(p, Capabilities{Networks: []string{"JCB"}})
+	if !errors.Is(err, ErrNoPaymentOption) {
+		t.Errorf("SelectNetwork(JCB) error = %v, want ErrNoPaymentOption", err)
+	}
+	const offers = "emvqr: no payment option suits the consumer: the payload offers Visa, Mastercard, RuPay, IFSC + account, Amex, UPI, Aadhaar Pay"
+	if err == nil || err.Error() != offers {
+		t.Errorf("SelectNetwork(JCB) error = %v, want %q", err, offers)
+	}
+
+	_, err = SelectNetwork(&Payload{}, Capabilities{Networks: []string{"Visa"}})
+	if !errors.Is(err, ErrNoPaymentOption) || err.Error() != "emvqr: no payment option suits the consumer: the payload offers no payment options" {
+		t.Errorf("SelectNetwork() of empty payload error = %v", err)
+	}
+}
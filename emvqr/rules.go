@@ -0,0 +1,249 @@
+package emvqr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RuleSet is a set of declarative constraints on payload fields, such as
+// those of a scheme circular, kept in configuration rather than code so that
+// a changed limit does not need a new release.
+//
+// Rule sets are usually loaded from JSON with LoadRules:
+//
+//	{
+//	  "name": "acquirer-2024-11",
+//	  "rules": [
+//	    {"id": "R1", "field": "MerchantName", "maxLength": 23},
+//	    {"id": "R2", "field": "62.05", "required": true, "pattern": "^[A-Z0-9-]+$"},
+//	    {"id": "R3", "field": "61", "requiredIf": {"field": "58", "equals": "IN"}}
+//	  ]
+//	}
+//
+// The struct tags also suit YAML decoders that honour `yaml` tags, so a YAML
+// file can be decoded into a RuleSet directly.
+type RuleSet struct {
+	Name  string `json:"name,omitempty" yaml:"name,omitempty"`
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule constrains one field, named as for GetField ("MerchantName",
+// "AdditionalData.BillNumber") or by any dotted tag path of Flatten ("59",
+// "26.01", "80.01"). Length limits count bytes, as the TLV length does. The
+// constraints other than Required and RequiredIf apply only to fields that
+// are present.
+type Rule struct {
+	ID         string         `json:"id,omitempty" yaml:"id,omitempty"`
+	Field      string         `json:"field" yaml:"field"`
+	Required   bool           `json:"required,omitempty" yaml:"required,omitempty"`
+	RequiredIf *RuleCondition `json:"requiredIf,omitempty" yaml:"requiredIf,omitempty"`
+	Pattern    string         `json:"pattern,omitempty" yaml:"pattern,omitempty"` // RE2 syntax, matched against the whole value
+	MinLength  int            `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength  int            `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	OneOf      []string       `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	Message    string         `json:"message,omitempty" yaml:"message,omitempty"` // replaces the generated message
+}
+
+// RuleCondition is the condition of Rule.RequiredIf: it holds when Field is
+// present and, if Equals is set, has that value.
+type RuleCondition struct {
+	Field  string `json:"field" yaml:"field"`
+	Equals string `json:"equals,omitempty" yaml:"equals,omitempty"`
+}
+
+// RuleViolation is a rule that a payload does not satisfy.
+type RuleViolation struct {
+	RuleID  string
+	Field   string // the rule's field
+	Path    string // its dotted tag path
+	Value   string
+	Message string
+}
+
+// RuleReport is the result of ValidateWithRules.
+type RuleReport struct {
+	RuleSet    string
+	Violations []RuleViolation
+}
+
+// OK reports whether the payload satisfies every rule.
+func (r *RuleReport) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// String lists the violations one per line, prefixed by the rule ID.
+func (r *RuleReport) String() string {
+	var sb strings.Builder
+	for _, v := range r.Violations {
+		if v.RuleID != "" {
+			sb.WriteString(v.RuleID + ": ")
+		}
+		sb.WriteString(v.Message + "\n")
+	}
+	return sb.String()
+}
+
+// LoadRules reads a RuleSet in JSON and checks that its fields are known and
+// its patterns compile. Unknown JSON keys are rejected, so that a misspelt
+// constraint is not silently ignored.
+func LoadRules(r io.Reader) (*RuleSet, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var rs RuleSet
+	if err := dec.Decode(&rs); err != nil {
+		return nil, fmt.Errorf("%w: rule set: %v", ErrInvalidFormat, err)
+	}
+	if _, err := rs.compile(); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// ValidateWithRules checks p against every rule of rs and reports each
+// violation. It returns an error wrapping ErrInvalidFormat if a rule is
+// invalid, e.g. names an unknown field or has a bad pattern.
+func ValidateWithRules(p *Payload, rs *RuleSet) (*RuleReport, error) {
+	return ValidateWithRulesContext(context.Background(), p, rs)
+}
+
+// ValidateWithRulesContext is like ValidateWithRules but checks ctx before
+// each rule and gives up with ctx's error once ctx is done, so that large
+// rule sets can be bounded by a request's deadline.
+func ValidateWithRulesContext(ctx context.Context, p *Payload, rs *RuleSet) (*RuleReport, error) {
+	compiled, err := rs.compile()
+	if err != nil {
+		return nil, err
+	}
+	flat := p.Flatten()
+	report := &RuleReport{RuleSet: rs.Name}
+	for _, c := range compiled {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		value, present := flat[c.path]
+		fail := func(format string, args ...any) {
+			msg := c.Message
+			if msg == "" {
+				msg = fmt.Sprintf("%s (tag %s) ", c.Field, c.path) + fmt.Sprintf(format, args...)
+			}
+			report.Violations = append(report.Violations, RuleViolation{
+				RuleID: c.ID, Field: c.Field, Path: c.path, Value: value, Message: msg,
+			})
+		}
+		if !present {
+			switch {
+			case c.Required:
+				fail("is required")
+			case c.condPath != "" && c.conditionHolds(flat):
+				fail("is required when %s is %s", c.RequiredIf.Field, conditionText(c.RequiredIf))
+			}
+			continue
+		}
+		switch n := len(value); {
+		case c.MinLength > 0 && n < c.MinLength:
+			fail("is shorter than %d bytes (got %d)", c.MinLength, n)
+		case c.MaxLength > 0 && n > c.MaxLength:
+			fail("exceeds %d bytes (got %d)", c.MaxLength, n)
+		}
+		if c.re != nil && !c.re.MatchString(value) {
+			fail("%q does not match %s", value, c.Pattern)
+		}
+		if len(c.OneOf) > 0 && !slices.Contains(c.OneOf, value) {
+			fail("%q is not one of %s", value, strings.Join(c.OneOf, ", "))
+		}
+	}
+	return report, nil
+}
+
+// ValidateAll decodes raw with opts and checks the payload against every
+// rule of rs; see ValidateAllContext.
+func ValidateAll(raw string, rs *RuleSet, opts DecodeOptions) (*RuleReport, error) {
+	return ValidateAllContext(context.Background(), raw, rs, opts)
+}
+
+// ValidateAllContext runs every check of a payload in one call: it decodes
+// raw with DecodeContext, passing ctx to the callbacks of opts such as
+// VerifyMerchant, and then checks the payload against every rule of rs with
+// ValidateWithRulesContext, stopping between rules once ctx is done. A nil
+// rs checks no rules. It returns the error of DecodeContext if raw does not
+// decode or its merchant is not verified, and ctx's error once ctx is done.
+func ValidateAllContext(ctx context.Context, raw string, rs *RuleSet, opts DecodeOptions) (*RuleReport, error) {
+	p, err := DecodeContext(ctx, raw, opts)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil {
+		return &RuleReport{}, ctx.Err()
+	}
+	return ValidateWithRulesContext(ctx, p, rs)
+}
+
+// compiledRule is a Rule with its field resolved to a tag path and its
+// pattern compiled.
+type compiledRule struct {
+	Rule
+	path, condPath string
+	re             *regexp.Regexp
+}
+
+// conditionHolds reports whether the RequiredIf condition holds.
+func (c compiledRule) conditionHolds(flat map[string]string) bool {
+	v, ok := flat[c.condPath]
+	return ok && (c.RequiredIf.Equals == "" || v == c.RequiredIf.Equals)
+}
+
+// conditionText describes a condition for messages.
+func conditionText(cond *RuleCondition) string {
+	if cond.Equals == "" {
+		return "present"
+	}
+	return fmt.Sprintf("%q", cond.Equals)
+}
+
+// compile resolves the fields and patterns of every rule.
+func (rs *RuleSet) compile() ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rs.Rules))
+	for i, r := range rs.Rules {
+		fail := func(format string, args ...any) ([]compiledRule, error) {
+			return nil, newError(CodeInvalidFormat,
+				fmt.Errorf("%w: rule %d (%s): "+format, append([]any{ErrInvalidFormat, i + 1, r.ID}, args...)...),
+				"rule", r.ID)
+		}
+		c := compiledRule{Rule: r}
+		var err error
+		if c.path, err = rulePath(r.Field); err != nil {
+			return fail("%v", err)
+		}
+		if r.RequiredIf != nil {
+			if c.condPath, err = rulePath(r.RequiredIf.Field); err != nil {
+				return fail("requiredIf: %v", err)
+			}
+		}
+		if r.Pattern != "" {
+			if c.re, err = regexp.Compile("^(?:" + r.Pattern + ")$"); err != nil {
+				return fail("pattern: %v", err)
+			}
+		}
+		if r.MaxLength > 0 && r.MinLength > r.MaxLength {
+			return fail("minLength %d exceeds maxLength %d", r.MinLength, r.MaxLength)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// rulePath resolves a field name or dotted tag path to a tag path.
+func rulePath(field string) (string, error) {
+	if f, err := lookupField(field); err == nil {
+		return f.path, nil
+	}
+	if err := (&flatNode{}).insert(field, "x"); err != nil || field == "" {
+		return "", fmt.Errorf("unknown field %q", field)
+	}
+	return field, nil
+}
@@ -0,0 +1,109 @@
+package emvqr
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testRules = `{
+  "name": "acquirer-2024-11",
+  "rules": [
+    {"id": "R1", "field": "MerchantName", "maxLength": 10},
+    {"id": "R2", "field": "62.05", "required": true, "pattern": "[A-Z0-9-]+"},
+    {"id": "R3", "field": "PostalCode", "requiredIf": {"field": "58", "equals": "IN"}},
+    {"id": "R4", "field": "53", "oneOf": ["356", "840"]},
+    {"id": "R5", "field": "26.01", "required": true, "message": "a UPI VPA is mandatory"}
+  ]
+}`
+
+func TestValidateWithRules(t *testing.T) {
+	rs, err := LoadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := basePayload()
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.ReferenceLabel = "order 42" })
+	report, err := ValidateWithRules(p, rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, v := range report.Violations {
+		ids = append(ids, v.RuleID)
+	}
+	assertEqual(t, "violated rules", "R1 R2 R5", strings.Join(ids, " "))
+	assertEqual(t, "R1 message", "MerchantName (tag 59) exceeds 10 bytes (got 11)", report.Violations[0].Message)
+	assertEqual(t, "R5 message", "a UPI VPA is mandatory", report.Violations[2].Message)
+	if report.OK() || !strings.HasPrefix(report.String(), "R1: ") {
+		t.Errorf("report = %q", report.String())
+	}
+
+	p, err = Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.PostalCode = ""
+	p.MerchantName = "APRIL MOON"
+	report, err = ValidateWithRules(p, rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].RuleID != "R3" {
+		t.Fatalf("violations = %+v", report.Violations)
+	}
+	assertEqual(t, "R3 message", `PostalCode (tag 61) is required when 58 is "IN"`, report.Violations[0].Message)
+}
+
+func TestLoadRulesErrors(t *testing.T) {
+	for _, doc := range []string{
+		`{"rules": [{"field": "Nickname"}]}`,
+		`{"rules": [{"field": "59", "pattern": "("}]}`,
+		`{"rules": [{"field": "59", "maxLen": 3}]}`,
+		`{"rules": [{"field": "59", "requiredIf": {"field": "1.2"}}]}`,
+		`{"rules": [{"field": "59", "minLength": 5, "maxLength": 3}]}`,
+	} {
+		if _, err := LoadRules(strings.NewReader(doc)); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("%s: want ErrInvalidFormat, got %v", doc, err)
+		}
+	}
+}
+
+func TestValidateAllContext(t *testing.T) {
+	rs, err := LoadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+	var seen any
+	opts := DecodeOptions{VerifyMerchant: func(ctx context.Context, _ *Payload) error {
+		seen = ctx.Value(ctxKey{})
+		return nil
+	}}
+	report, err := ValidateAllContext(ctx, realWorldBharatQRPayload, rs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen != "request-1" {
+		t.Errorf("VerifyMerchant saw context value %v", seen)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].RuleID != "R1" {
+		t.Errorf("violations = %+v", report.Violations)
+	}
+
+	// A context cancelled during verification stops before the rules.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts.VerifyMerchant = func(context.Context, *Payload) error { cancel(); return nil }
+	if _, err := ValidateAllContext(ctx, realWorldBharatQRPayload, rs, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+
+	opts.VerifyMerchant = func(context.Context, *Payload) error { return errors.New("unknown merchant") }
+	if _, err := ValidateAll(realWorldBharatQRPayload, nil, opts); !errors.Is(err, ErrMerchantUnverified) {
+		t.Errorf("err = %v, want ErrMerchantUnverified", err)
+	}
+}
@@ -0,0 +1,118 @@
+package scan
+
+import "image"
+
+// bitmap is a thresholded image; true pixels are dark.
+type bitmap struct {
+	w, h int
+	dark []bool
+}
+
+// inside reports whether (x, y) lies within the bitmap.
+func (b *bitmap) inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.w && y < b.h
+}
+
+// at reports whether the pixel at (x, y) is dark; outside pixels are light.
+func (b *bitmap) at(x, y int) bool {
+	return b.inside(x, y) && b.dark[y*b.w+x]
+}
+
+// grayImage is an 8-bit luminance plane.
+type grayImage struct {
+	w, h int
+	pix  []uint8
+}
+
+// luminance converts img to 8-bit luminance, compositing transparent pixels
+// onto white. Common concrete image types are read directly.
+func luminance(img image.Image) *grayImage {
+	b := img.Bounds()
+	g := &grayImage{w: b.Dx(), h: b.Dy(), pix: make([]uint8, b.Dx()*b.Dy())}
+	switch src := img.(type) {
+	case *image.Gray:
+		for y := 0; y < g.h; y++ {
+			copy(g.pix[y*g.w:(y+1)*g.w], src.Pix[src.PixOffset(b.Min.X, b.Min.Y+y):])
+		}
+	case *image.YCbCr:
+		for y := 0; y < g.h; y++ {
+			copy(g.pix[y*g.w:(y+1)*g.w], src.Y[src.YOffset(b.Min.X, b.Min.Y+y):])
+		}
+	default:
+		for y := 0; y < g.h; y++ {
+			for x := 0; x < g.w; x++ {
+				r, gr, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+				white := 0xFFFF - a // premultiplied: add the white backdrop
+				lum := (19595*(r+white) + 38470*(gr+white) + 7471*(bl+white) + 1<<15) >> 24
+				g.pix[y*g.w+x] = uint8(lum)
+			}
+		}
+	}
+	return g
+}
+
+// globalThreshold binarizes g with Otsu's method, which suits evenly lit
+// images such as screenshots and rendered stickers.
+func globalThreshold(g *grayImage) *bitmap {
+	var hist [256]int
+	for _, p := range g.pix {
+		hist[p]++
+	}
+	total := len(g.pix)
+	sum := 0
+	for i, n := range hist {
+		sum += i * n
+	}
+	best, threshold := -1.0, 128
+	sumB, weightB := 0, 0
+	for t := 0; t < 256; t++ {
+		weightB += hist[t]
+		if weightB == 0 {
+			continue
+		}
+		weightF := total - weightB
+		if weightF == 0 {
+			break
+		}
+		sumB += t * hist[t]
+		meanB := float64(sumB) / float64(weightB)
+		meanF := float64(sum-sumB) / float64(weightF)
+		between := float64(weightB) * float64(weightF) * (meanB - meanF) * (meanB - meanF)
+		if between > best {
+			best, threshold = between, t
+		}
+	}
+	b := &bitmap{w: g.w, h: g.h, dark: make([]bool, len(g.pix))}
+	for i, p := range g.pix {
+		b.dark[i] = int(p) <= threshold
+	}
+	return b
+}
+
+// adaptiveThreshold binarizes g against the mean of a square window around
+// each pixel, which copes with shadows and uneven lighting in photographs.
+func adaptiveThreshold(g *grayImage) *bitmap {
+	// Integral image with a zero row and column.
+	iw := g.w + 1
+	sum := make([]int, iw*(g.h+1))
+	for y := 0; y < g.h; y++ {
+		row := 0
+		for x := 0; x < g.w; x++ {
+			row += int(g.pix[y*g.w+x])
+			sum[(y+1)*iw+x+1] = sum[y*iw+x+1] + row
+		}
+	}
+	r := max(8, max(g.w, g.h)/16)
+	b := &bitmap{w: g.w, h: g.h, dark: make([]bool, len(g.pix))}
+	for y := 0; y < g.h; y++ {
+		y0, y1 := max(0, y-r), min(g.h, y+r+1)
+		for x := 0; x < g.w; x++ {
+			x0, x1 := max(0, x-r), min(g.w, x+r+1)
+			s := sum[y1*iw+x1] - sum[y0*iw+x1] - sum[y1*iw+x0] + sum[y0*iw+x0]
+			n := (x1 - x0) * (y1 - y0)
+			// Dark when at least ~7% below the local mean.
+			b.dark[y*g.w+x] = int(g.pix[y*g.w+x])*n*100 < s*93
+		}
+	}
+	return b
+}
@@ -0,0 +1,330 @@
+package scan
+
+import (
+	"math"
+	"sort"
+)
+
+// point is a position in continuous pixel coordinates; pixel (x, y) covers
+// [x, x+1) × [y, y+1).
+type point struct{ x, y float64 }
+
+func (p point) sub(q point) point      { return point{p.x - q.x, p.y - q.y} }
+func (p point) add(q point) point      { return point{p.x + q.x, p.y + q.y} }
+func (p point) scale(f float64) point  { return point{p.x * f, p.y * f} }
+func (p point) dist(q point) float64   { return math.Hypot(p.x-q.x, p.y-q.y) }
+func (p point) cross(q point) float64  { return p.x*q.y - p.y*q.x }
+func (b *bitmap) atPoint(p point) bool { return b.at(int(math.Floor(p.x)), int(math.Floor(p.y))) }
+func (b *bitmap) insidePoint(p point) bool {
+	return b.inside(int(math.Floor(p.x)), int(math.Floor(p.y)))
+}
+
+// finder is a candidate finder pattern centre.
+type finder struct {
+	center point
+	module float64 // estimated module size in pixels
+	count  int     // number of scan lines that confirmed it
+}
+
+// finderRatio checks five run lengths against the 1:1:3:1:1 finder pattern
+// and returns the implied module size.
+func finderRatio(c [5]int) (float64, bool) {
+	total := 0
+	for _, n := range c {
+		if n == 0 {
+			return 0, false
+		}
+		total += n
+	}
+	if total < 7 {
+		return 0, false
+	}
+	m := float64(total) / 7
+	v := m / 2
+	ok := math.Abs(float64(c[0])-m) < v && math.Abs(float64(c[1])-m) < v &&
+		math.Abs(float64(c[2])-3*m) < 3*v &&
+		math.Abs(float64(c[3])-m) < v && math.Abs(float64(c[4])-m) < v
+	return m, ok
+}
+
+// crossCheck measures the five runs through pixel (x, y) along (dx, dy),
+// with (x, y) inside the centre dark run. It returns the offset of the centre
+// run's midpoint from (x, y) along the line and the module size.
+func (b *bitmap) crossCheck(x, y, dx, dy int) (float64, float64, bool) {
+	if !b.at(x, y) {
+		return 0, 0, false
+	}
+	var c [5]int
+	// walk counts pixels of one colour starting i steps out in direction s.
+	walk := func(i, s int, dark bool) int {
+		n := 0
+		for {
+			px, py := x+s*i*dx, y+s*i*dy
+			if !b.inside(px, py) || b.at(px, py) != dark {
+				return n
+			}
+			n++
+			i++
+		}
+	}
+	back := walk(0, -1, true)
+	fwd := walk(1, 1, true)
+	c[2] = back + fwd
+	c[1] = walk(back, -1, false)
+	c[0] = walk(back+c[1], -1, true)
+	c[3] = walk(1+fwd, 1, false)
+	c[4] = walk(1+fwd+c[3], 1, true)
+	m, ok := finderRatio(c)
+	if !ok {
+		return 0, 0, false
+	}
+	// The centre run covers offsets -(back-1) .. fwd; its midpoint in
+	// continuous coordinates, relative to the pixel's leading edge:
+	mid := float64(fwd+1-(back-1)) / 2
+	return mid, m, true
+}
+
+// findFinders scans every row for 1:1:3:1:1 runs and confirms each hit with
+// vertical and horizontal cross-checks. Nearby confirmations are merged.
+func (b *bitmap) findFinders() []finder {
+	var found []finder
+	type run struct {
+		dark       bool
+		start, len int
+	}
+	runs := make([]run, 0, 64)
+	for y := 0; y < b.h; y++ {
+		runs = runs[:0]
+		for x := 0; x < b.w; x++ {
+			d := b.dark[y*b.w+x]
+			if len(runs) > 0 && runs[len(runs)-1].dark == d {
+				runs[len(runs)-1].len++
+			} else {
+				runs = append(runs, run{d, x, 1})
+			}
+		}
+		for i := 0; i+5 <= len(runs); i++ {
+			if !runs[i].dark {
+				continue
+			}
+			c := [5]int{runs[i].len, runs[i+1].len, runs[i+2].len, runs[i+3].len, runs[i+4].len}
+			if _, ok := finderRatio(c); !ok {
+				continue
+			}
+			cx := runs[i+2].start + runs[i+2].len/2
+			oy, mv, ok := b.crossCheck(cx, y, 0, 1)
+			if !ok {
+				continue
+			}
+			cy := float64(y) + oy
+			ox, mh, ok := b.crossCheck(cx, int(cy), 1, 0)
+			if !ok {
+				continue
+			}
+			found = mergeFinder(found, finder{center: point{float64(cx) + ox, cy}, module: (mv + mh) / 2, count: 1})
+		}
+	}
+	return found
+}
+
+// mergeFinder folds f into an existing candidate at the same position, or
+// appends it.
+func mergeFinder(found []finder, f finder) []finder {
+	for i := range found {
+		g := &found[i]
+		if g.center.dist(f.center) <= 2*g.module && math.Abs(g.module-f.module) <= g.module {
+			n := float64(g.count)
+			g.center = g.center.scale(n).add(f.center).scale(1 / (n + 1))
+			g.module = (g.module*n + f.module) / (n + 1)
+			g.count++
+			return found
+		}
+	}
+	return append(found, f)
+}
+
+// triple is three finder patterns ordered top-left, top-right, bottom-left.
+type triple struct {
+	tl, tr, bl finder
+	cost       float64
+}
+
+// maxFinderCandidates bounds the number of candidates combined into triples.
+const maxFinderCandidates = 10
+
+// finderTriples returns plausible finder arrangements, best first. A good
+// arrangement is a right isosceles triangle of similarly sized patterns.
+func finderTriples(found []finder) []triple {
+	sort.SliceStable(found, func(i, j int) bool { return found[i].count > found[j].count })
+	if len(found) > maxFinderCandidates {
+		found = found[:maxFinderCandidates]
+	}
+	var out []triple
+	for i := 0; i < len(found); i++ {
+		for j := i + 1; j < len(found); j++ {
+			for k := j + 1; k < len(found); k++ {
+				if t, ok := orderFinders(found[i], found[j], found[k]); ok {
+					out = append(out, t)
+				}
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].cost < out[j].cost })
+	return out
+}
+
+// orderFinders identifies the corner pattern (opposite the longest side) and
+// orients the other two so the symbol reads top-left to bottom-right.
+func orderFinders(a, b, c finder) (triple, bool) {
+	ab, ac, bc := a.center.dist(b.center), a.center.dist(c.center), b.center.dist(c.center)
+	var t triple
+	var legA, legB, hyp float64
+	switch {
+	case bc >= ab && bc >= ac:
+		t, legA, legB, hyp = triple{tl: a, tr: b, bl: c}, ab, ac, bc
+	case ac >= ab && ac >= bc:
+		t, legA, legB, hyp = triple{tl: b, tr: a, bl: c}, ab, bc, ac
+	default:
+		t, legA, legB, hyp = triple{tl: c, tr: a, bl: b}, ac, bc, ab
+	}
+	if t.tr.center.sub(t.tl.center).cross(t.bl.center.sub(t.tl.center)) < 0 {
+		t.tr, t.bl = t.bl, t.tr
+	}
+	minM := min(a.module, b.module, c.module)
+	maxM := max(a.module, b.module, c.module)
+	if maxM > 2*minM || min(legA, legB) < 10*minM {
+		return t, false
+	}
+	t.cost = math.Abs(legA-legB)/max(legA, legB) +
+		math.Abs(hyp-math.Hypot(legA, legB))/hyp +
+		(maxM/minM - 1)
+	return t, t.cost < 0.5
+}
+
+// moduleSizeAlong estimates the module size by measuring the finder at from
+// along the line towards to: from the centre to the end of the outer dark
+// ring is 3.5 modules in each direction.
+func (b *bitmap) moduleSizeAlong(from, to point, guess float64) (float64, bool) {
+	dir := to.sub(from)
+	dir = dir.scale(1 / math.Hypot(dir.x, dir.y))
+	total := 0.0
+	for _, s := range []float64{1, -1} {
+		step := dir.scale(s)
+		transitions, last := 0, true
+		d := 1.0
+		for ; transitions < 3; d++ {
+			p := from.add(step.scale(d))
+			if d > 10*guess || !b.insidePoint(p) {
+				return 0, false
+			}
+			if dark := b.atPoint(p); dark != last {
+				transitions++
+				last = dark
+			}
+		}
+		// The ring ends between the last dark sample and the first light one.
+		total += d - 1.5
+	}
+	return total / 7, true
+}
+
+// moduleSize averages moduleSizeAlong over both legs of the triangle,
+// falling back to the cross-check estimates.
+func (b *bitmap) moduleSize(t triple) float64 {
+	guess := (t.tl.module + t.tr.module + t.bl.module) / 3
+	sum, n := 0.0, 0
+	for _, pair := range [][2]finder{{t.tl, t.tr}, {t.tr, t.tl}, {t.tl, t.bl}, {t.bl, t.tl}} {
+		if m, ok := b.moduleSizeAlong(pair[0].center, pair[1].center, pair[0].module); ok {
+			sum += m
+			n++
+		}
+	}
+	if n == 0 {
+		return guess
+	}
+	return sum / float64(n)
+}
+
+// candidateSizes returns symbol sizes to try, nearest the estimate first.
+func candidateSizes(t triple, module float64) []int {
+	legs := (t.tl.center.dist(t.tr.center) + t.tl.center.dist(t.bl.center)) / 2
+	est := legs/module + 7
+	base := int(math.Round((est-17)/4))*4 + 17
+	var out []int
+	for _, d := range []int{0, 4, -4, 8, -8} {
+		if s := base + d; s >= 21 && s <= 177 {
+			out = append(out, s)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return math.Abs(float64(out[i])-est) < math.Abs(float64(out[j])-est)
+	})
+	return out
+}
+
+// findAlignment searches near est for the bottom-right alignment pattern: a
+// dark centre module, a light ring and a dark ring, with module vectors ex
+// and ey. The search widens from ±4 to ±12 modules until a match is found,
+// since perspective moves the pattern away from its affine estimate.
+func (b *bitmap) findAlignment(est, ex, ey point) (point, bool) {
+	score := func(c point) int {
+		n := 0
+		for dy := -2; dy <= 2; dy++ {
+			for dx := -2; dx <= 2; dx++ {
+				p := c.add(ex.scale(float64(dx))).add(ey.scale(float64(dy)))
+				ring := max(abs(dx), abs(dy))
+				if b.atPoint(p) == (ring != 1) {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	const steps = 4 // quarter-module search grid
+	for _, radius := range []int{4, 8, 12} {
+		best := -1
+		var matches []point
+		for i := -radius * steps; i <= radius*steps; i++ {
+			for j := -radius * steps; j <= radius*steps; j++ {
+				c := est.add(ex.scale(float64(j) / steps)).add(ey.scale(float64(i) / steps))
+				switch s := score(c); {
+				case s > best:
+					best, matches = s, append(matches[:0], c)
+				case s == best:
+					matches = append(matches, c)
+				}
+			}
+		}
+		if best < minAlignmentScore {
+			continue
+		}
+		// Average the best matches around the one nearest the estimate, so
+		// a second pattern inside the search area does not skew the result.
+		nearest := matches[0]
+		for _, c := range matches {
+			if c.dist(est) < nearest.dist(est) {
+				nearest = c
+			}
+		}
+		unit := math.Hypot(ex.x, ex.y)
+		sum, n := point{}, 0
+		for _, c := range matches {
+			if c.dist(nearest) <= unit {
+				sum, n = sum.add(c), n+1
+			}
+		}
+		return sum.scale(1 / float64(n)), true
+	}
+	return point{}, false
+}
+
+// minAlignmentScore is the number of the 25 alignment pattern samples that
+// must match.
+const minAlignmentScore = 23
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
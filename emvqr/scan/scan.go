@@ -0,0 +1,131 @@
+// Package scan reads EMV QR Code payloads from images.
+//
+// DecodeImage locates the QR Code symbol in a photo or screenshot of a
+// merchant sticker, decodes it and parses the result as an EMV payload:
+//
+//	f, _ := os.ReadFile("sticker.jpg")
+//	raw, p, err := scan.DecodeImageBytes(f)
+//
+// The detector finds the three finder patterns, refines the perspective with
+// the bottom-right alignment pattern where the version has one, samples the
+// module grid and applies Reed–Solomon error correction. It handles rotation,
+// moderate perspective and uneven lighting; it does not handle mirrored,
+// inverted (light-on-dark) or heavily curved symbols.
+package scan
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF for DecodeImageBytes
+	_ "image/jpeg" // register JPEG for DecodeImageBytes
+	_ "image/png"  // register PNG for DecodeImageBytes
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/internal/qr"
+)
+
+// ErrNotFound is returned when no decodable QR Code symbol is found.
+var ErrNotFound = errors.New("scan: no QR Code found")
+
+// DecodeImage finds and decodes the QR Code in img and parses it as an EMV
+// payload. If the symbol decodes but the payload is invalid, the raw string
+// is returned together with the emvqr error.
+func DecodeImage(img image.Image) (string, *emvqr.Payload, error) {
+	return DecodeImageWithOptions(img, emvqr.DecodeOptions{})
+}
+
+// DecodeImageWithOptions is like DecodeImage but parses the payload with the
+// given decode options.
+func DecodeImageWithOptions(img image.Image, opts emvqr.DecodeOptions) (string, *emvqr.Payload, error) {
+	raw, err := readSymbol(img)
+	if err != nil {
+		return "", nil, err
+	}
+	p, err := emvqr.DecodeWithOptions(raw, opts)
+	if err != nil {
+		return raw, nil, err
+	}
+	return raw, p, nil
+}
+
+// DecodeImageBytes decodes a PNG, JPEG or GIF file and then behaves like
+// DecodeImage.
+func DecodeImageBytes(data []byte) (string, *emvqr.Payload, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("scan: decoding image: %w", err)
+	}
+	return DecodeImage(img)
+}
+
+// maxTriples bounds how many finder arrangements are tried per binarization.
+const maxTriples = 4
+
+// readSymbol returns the text of the first QR Code symbol that decodes. The
+// global threshold is tried first; the adaptive one is the fallback for
+// photographs.
+func readSymbol(img image.Image) (string, error) {
+	if img.Bounds().Empty() {
+		return "", ErrNotFound
+	}
+	g := luminance(img)
+	for _, binarize := range []func(*grayImage) *bitmap{globalThreshold, adaptiveThreshold} {
+		b := binarize(g)
+		triples := finderTriples(b.findFinders())
+		for i, t := range triples {
+			if i == maxTriples {
+				break
+			}
+			if data, ok := b.decodeAt(t); ok {
+				return string(data), nil
+			}
+		}
+	}
+	return "", ErrNotFound
+}
+
+// decodeAt samples and decodes the symbol framed by finder triple t, trying
+// the likely symbol sizes in turn.
+func (b *bitmap) decodeAt(t triple) ([]byte, bool) {
+	module := b.moduleSize(t)
+	for _, size := range candidateSizes(t, module) {
+		grid := b.sample(t, size)
+		dark := func(x, y int) bool { return grid[y*size+x] }
+		if d, err := qr.Decode(size, dark); err == nil {
+			return d.Data, true
+		}
+	}
+	return nil, false
+}
+
+// sample reads a size×size module grid. Finder centres sit 3.5 modules in
+// from their corners; the fourth reference point is the bottom-right
+// alignment pattern (6.5 modules in) when it can be found, and otherwise the
+// parallelogram completion of the three finders.
+func (b *bitmap) sample(t triple, size int) []bool {
+	n := float64(size)
+	far := n - 3.5
+	ex := t.tr.center.sub(t.tl.center).scale(1 / (far - 3.5))
+	ey := t.bl.center.sub(t.tl.center).scale(1 / (far - 3.5))
+
+	src := [4]point{{3.5, 3.5}, {far, 3.5}, {far, far}, {3.5, far}}
+	dst := [4]point{t.tl.center, t.tr.center, t.tr.center.add(t.bl.center).sub(t.tl.center), t.bl.center}
+	if size > 21 {
+		align := n - 6.5
+		est := t.tl.center.add(ex.scale(align - 3.5)).add(ey.scale(align - 3.5))
+		if p, ok := b.findAlignment(est, ex, ey); ok {
+			src[2], dst[2] = point{align, align}, p
+		}
+	}
+	m := quadToQuad(src, dst)
+
+	grid := make([]bool, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			grid[y*size+x] = b.atPoint(m.apply(point{float64(x) + 0.5, float64(y) + 0.5}))
+		}
+	}
+	return grid
+}
@@ -0,0 +1,224 @@
+package scan
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/render"
+)
+
+// staticQR is the EMVCo base example (static QR, single Visa MAI).
+const staticQR = "000201021640001234567890125204525153038405802US5911ABC Hammers6008New York63047222"
+
+// longQR returns a payload large enough to need alignment patterns.
+func longQR(t *testing.T) string {
+	t.Helper()
+	p := emvqr.NewPayload()
+	_ = p.AddMerchantIdentifier("02", "4000123456789012")
+	_ = p.SetUPIVPATemplate("A000000524", "merchant@examplebank", "")
+	p.MerchantCategoryCode = "5411"
+	p.TransactionCurrency = "356"
+	p.TransactionAmount = "1499.00"
+	p.CountryCode = "IN"
+	p.MerchantName = "Sharma General Stores"
+	p.MerchantCity = "Mumbai"
+	p.PostalCode = "400001"
+	p.SetAdditionalData(func(a *emvqr.AdditionalDataField) {
+		a.BillNumber = "INV-2024-000123"
+		a.ReferenceLabel = "REF0987654321"
+		a.TerminalLabel = "T01"
+	})
+	raw, err := emvqr.Encode(p)
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	return raw
+}
+
+func renderImage(t *testing.T, raw string, opts render.Options) image.Image {
+	t.Helper()
+	s, err := render.New(raw, opts)
+	if err != nil {
+		t.Fatalf("render.New error: %v", err)
+	}
+	return s.Image()
+}
+
+// warp maps src through the projective transform taking the corners of src
+// onto quad, on a white canvas of the given size.
+func warp(src image.Image, quad [4]point, w, h int) *image.RGBA {
+	b := src.Bounds()
+	corners := [4]point{{0, 0}, {float64(b.Dx()), 0}, {float64(b.Dx()), float64(b.Dy())}, {0, float64(b.Dy())}}
+	inv := quadToQuad(quad, corners)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := inv.apply(point{float64(x) + 0.5, float64(y) + 0.5})
+			sx, sy := int(math.Floor(p.x)), int(math.Floor(p.y))
+			if sx < 0 || sy < 0 || sx >= b.Dx() || sy >= b.Dy() {
+				dst.Set(x, y, color.White)
+				continue
+			}
+			dst.Set(x, y, src.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
+
+// rotate returns src rotated by deg degrees about its centre.
+func rotate(src image.Image, deg float64) *image.RGBA {
+	b := src.Bounds()
+	side := int(float64(max(b.Dx(), b.Dy())) * 1.5)
+	c := point{float64(side) / 2, float64(side) / 2}
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	hw, hh := float64(b.Dx())/2, float64(b.Dy())/2
+	var quad [4]point
+	for i, p := range [4]point{{-hw, -hh}, {hw, -hh}, {hw, hh}, {-hw, hh}} {
+		quad[i] = point{c.x + p.x*cos - p.y*sin, c.y + p.x*sin + p.y*cos}
+	}
+	return warp(src, quad, side, side)
+}
+
+func TestDecodeImage_Rendered(t *testing.T) {
+	long := longQR(t)
+	for _, tc := range []struct {
+		raw  string
+		opts render.Options
+	}{
+		{staticQR, render.Options{ModuleSize: 2}},
+		{staticQR, render.Options{ModuleSize: 8, ErrorCorrection: render.ECHigh}},
+		{long, render.Options{ModuleSize: 3}},
+		{long, render.Options{ModuleSize: 5, ErrorCorrection: render.ECQuartile, QuietZone: 2}},
+	} {
+		raw, p, err := DecodeImage(renderImage(t, tc.raw, tc.opts))
+		if err != nil {
+			t.Fatalf("DecodeImage error: %v", err)
+		}
+		if raw != tc.raw {
+			t.Errorf("raw = %q, want %q", raw, tc.raw)
+		}
+		if p == nil || p.CountryCode == "" {
+			t.Errorf("payload not parsed: %+v", p)
+		}
+	}
+}
+
+func TestDecodeImage_Rotated(t *testing.T) {
+	long := longQR(t)
+	img := renderImage(t, long, render.Options{ModuleSize: 6})
+	for _, deg := range []float64{90, 180, 270, 17, -33, 135} {
+		raw, _, err := DecodeImage(rotate(img, deg))
+		if err != nil {
+			t.Errorf("rotated %v°: %v", deg, err)
+			continue
+		}
+		if raw != long {
+			t.Errorf("rotated %v°: raw = %q", deg, raw)
+		}
+	}
+}
+
+func TestDecodeImage_Perspective(t *testing.T) {
+	long := longQR(t)
+	img := renderImage(t, long, render.Options{ModuleSize: 6})
+	quad := [4]point{{60, 40}, {520, 90}, {480, 560}, {30, 500}}
+	raw, _, err := DecodeImage(warp(img, quad, 600, 620))
+	if err != nil {
+		t.Fatalf("DecodeImage error: %v", err)
+	}
+	if raw != long {
+		t.Errorf("raw = %q, want %q", raw, long)
+	}
+}
+
+func TestDecodeImage_UnevenLighting(t *testing.T) {
+	src := renderImage(t, staticQR, render.Options{ModuleSize: 6})
+	b := src.Bounds()
+	img := image.NewGray(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// A shadow falling across the sticker: light areas range from
+			// white down to a grey darker than the unshaded dark modules.
+			shade := 1 - 0.75*float64(x)/float64(b.Dx())
+			v := 60.0
+			if r, _, _, _ := src.At(x, y).RGBA(); r > 0x8000 {
+				v = 255
+			}
+			img.SetGray(x, y, color.Gray{Y: uint8(v * shade)})
+		}
+	}
+	raw, _, err := DecodeImage(img)
+	if err != nil {
+		t.Fatalf("DecodeImage error: %v", err)
+	}
+	if raw != staticQR {
+		t.Errorf("raw = %q, want %q", raw, staticQR)
+	}
+}
+
+func TestDecodeImage_Branded(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for i := range logo.Pix {
+		logo.Pix[i] = 0x80
+	}
+	img := renderImage(t, staticQR, render.Options{Logo: logo, Caption: "ABC Hammers", Scheme: render.SchemeUPI})
+	raw, _, err := DecodeImage(img)
+	if err != nil {
+		t.Fatalf("DecodeImage error: %v", err)
+	}
+	if raw != staticQR {
+		t.Errorf("raw = %q, want %q", raw, staticQR)
+	}
+}
+
+func TestDecodeImageBytes(t *testing.T) {
+	img := renderImage(t, staticQR, render.Options{ModuleSize: 4})
+	var pngBuf, jpegBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegBuf, img, &jpeg.Options{Quality: 75}); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"png": pngBuf.Bytes(), "jpeg": jpegBuf.Bytes()} {
+		raw, p, err := DecodeImageBytes(data)
+		if err != nil {
+			t.Errorf("%s: DecodeImageBytes error: %v", name, err)
+			continue
+		}
+		if raw != staticQR || p.MerchantName != "ABC Hammers" {
+			t.Errorf("%s: raw = %q, merchant = %q", name, raw, p.MerchantName)
+		}
+	}
+	if _, _, err := DecodeImageBytes([]byte("not an image")); err == nil {
+		t.Error("expected error for invalid image data, got nil")
+	}
+}
+
+func TestDecodeImage_NotFound(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 200, 200))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	if _, _, err := DecodeImage(img); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDecodeImage_InvalidPayload(t *testing.T) {
+	img := renderImage(t, "HELLO WORLD", render.Options{SkipPayloadValidation: true})
+	raw, p, err := DecodeImage(img)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected payload decode error, got %v", err)
+	}
+	if raw != "HELLO WORLD" || p != nil {
+		t.Errorf("got raw %q, payload %v; want raw text and nil payload", raw, p)
+	}
+}
@@ -0,0 +1,65 @@
+package scan
+
+// transform is a 3×3 projective transform applied to row vectors:
+// [x' y' w'] = [x y 1] · m.
+type transform [3][3]float64
+
+// apply maps p through t.
+func (t transform) apply(p point) point {
+	w := p.x*t[0][2] + p.y*t[1][2] + t[2][2]
+	return point{
+		(p.x*t[0][0] + p.y*t[1][0] + t[2][0]) / w,
+		(p.x*t[0][1] + p.y*t[1][1] + t[2][1]) / w,
+	}
+}
+
+// mul returns t · u.
+func (t transform) mul(u transform) transform {
+	var r transform
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				r[i][j] += t[i][k] * u[k][j]
+			}
+		}
+	}
+	return r
+}
+
+// adjugate returns the adjugate of t, which inverts it up to scale.
+func (t transform) adjugate() transform {
+	return transform{
+		{t[1][1]*t[2][2] - t[1][2]*t[2][1], t[0][2]*t[2][1] - t[0][1]*t[2][2], t[0][1]*t[1][2] - t[0][2]*t[1][1]},
+		{t[1][2]*t[2][0] - t[1][0]*t[2][2], t[0][0]*t[2][2] - t[0][2]*t[2][0], t[0][2]*t[1][0] - t[0][0]*t[1][2]},
+		{t[1][0]*t[2][1] - t[1][1]*t[2][0], t[0][1]*t[2][0] - t[0][0]*t[2][1], t[0][0]*t[1][1] - t[0][1]*t[1][0]},
+	}
+}
+
+// squareToQuad maps the unit square corners (0,0), (1,0), (1,1), (0,1) onto
+// q[0]..q[3].
+func squareToQuad(q [4]point) transform {
+	dx3 := q[0].x - q[1].x + q[2].x - q[3].x
+	dy3 := q[0].y - q[1].y + q[2].y - q[3].y
+	if dx3 == 0 && dy3 == 0 {
+		return transform{
+			{q[1].x - q[0].x, q[1].y - q[0].y, 0},
+			{q[2].x - q[1].x, q[2].y - q[1].y, 0},
+			{q[0].x, q[0].y, 1},
+		}
+	}
+	dx1, dx2 := q[1].x-q[2].x, q[3].x-q[2].x
+	dy1, dy2 := q[1].y-q[2].y, q[3].y-q[2].y
+	den := dx1*dy2 - dx2*dy1
+	a13 := (dx3*dy2 - dx2*dy3) / den
+	a23 := (dx1*dy3 - dx3*dy1) / den
+	return transform{
+		{q[1].x - q[0].x + a13*q[1].x, q[1].y - q[0].y + a13*q[1].y, a13},
+		{q[3].x - q[0].x + a23*q[3].x, q[3].y - q[0].y + a23*q[3].y, a23},
+		{q[0].x, q[0].y, 1},
+	}
+}
+
+// quadToQuad maps the quadrilateral src onto dst.
+func quadToQuad(src, dst [4]point) transform {
+	return squareToQuad(src).adjugate().mul(squareToQuad(dst))
+}
@@ -0,0 +1,168 @@
+package emvqr
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// languageScripts maps ISO 639-1 language codes to the Unicode scripts they
+// are written in, the usual one first.
+var languageScripts = map[string][]string{
+	"ar": {"Arabic"}, "fa": {"Arabic"}, "ur": {"Arabic"}, "ps": {"Arabic"}, "sd": {"Arabic"},
+	"he": {"Hebrew"}, "yi": {"Hebrew"}, "dv": {"Thaana"},
+	"hi": {"Devanagari"}, "mr": {"Devanagari"}, "ne": {"Devanagari"}, "sa": {"Devanagari"},
+	"bn": {"Bengali"}, "as": {"Bengali"}, "pa": {"Gurmukhi"}, "gu": {"Gujarati"},
+	"or": {"Oriya"}, "ta": {"Tamil"}, "te": {"Telugu"}, "kn": {"Kannada"},
+	"ml": {"Malayalam"}, "si": {"Sinhala"}, "th": {"Thai"}, "lo": {"Lao"},
+	"km": {"Khmer"}, "my": {"Myanmar"}, "zh": {"Han"}, "ja": {"Han", "Hiragana", "Katakana"},
+	"ko": {"Hangul", "Han"}, "ru": {"Cyrillic"}, "uk": {"Cyrillic"}, "bg": {"Cyrillic"},
+	"el": {"Greek"}, "ka": {"Georgian"}, "hy": {"Armenian"}, "am": {"Ethiopic"},
+	"en": {"Latin"}, "es": {"Latin"}, "fr": {"Latin"}, "de": {"Latin"}, "pt": {"Latin"},
+	"id": {"Latin"}, "ms": {"Latin"}, "vi": {"Latin"}, "tr": {"Latin"}, "tl": {"Latin"},
+}
+
+// detectableScripts are the scripts detectScript recognises.
+var detectableScripts = []string{
+	"Latin", "Arabic", "Hebrew", "Thaana", "Syriac", "Devanagari", "Bengali",
+	"Gurmukhi", "Gujarati", "Oriya", "Tamil", "Telugu", "Kannada", "Malayalam",
+	"Sinhala", "Thai", "Lao", "Khmer", "Myanmar", "Han", "Hiragana", "Katakana",
+	"Hangul", "Cyrillic", "Greek", "Georgian", "Armenian", "Ethiopic",
+}
+
+// rtlScripts are the scripts written right to left.
+var rtlScripts = map[string]bool{"Arabic": true, "Hebrew": true, "Thaana": true, "Syriac": true}
+
+// Script returns the Unicode script name of the template, such as "Arabic"
+// or "Devanagari": the usual script of LanguagePreference when it is a
+// known language, otherwise the script of most letters in the merchant name
+// and city. It returns "" when neither gives an answer.
+func (lt *LanguageTemplate) Script() string {
+	if scripts, ok := languageScripts[lt.LanguagePreference]; ok {
+		return scripts[0]
+	}
+	return detectScript(lt.MerchantName + lt.MerchantCity)
+}
+
+// RightToLeft reports whether the template's script is written right to
+// left, as for Arabic, Urdu and Hebrew names.
+func (lt *LanguageTemplate) RightToLeft() bool {
+	return rtlScripts[lt.Script()]
+}
+
+// DisplayMerchantName returns the merchant name ready to be embedded in a
+// sentence of another direction, e.g. "Pay ₹500 to %s": control and
+// bidirectional formatting characters are removed, and a right-to-left name
+// is wrapped in a RIGHT-TO-LEFT ISOLATE (U+2067) and POP DIRECTIONAL
+// ISOLATE (U+2069) so that it cannot reorder the surrounding text.
+func (lt *LanguageTemplate) DisplayMerchantName() string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || isBidiControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(lt.MerchantName, ""))
+	if name != "" && lt.RightToLeft() {
+		return "\u2067" + name + "\u2069"
+	}
+	return name
+}
+
+// Check returns a warning for each problem with the template's content
+// that a consumer app could display wrongly: a language preference that is
+// not a two-letter code, a name or city that is invalid UTF-8, too long in
+// bytes, or contains control or bidirectional formatting characters, and
+// text in a script other than that of the declared language.
+func (lt *LanguageTemplate) Check() []string {
+	var warnings []string
+	lang := lt.LanguagePreference
+	if len(lang) != 2 || !isLowerAlpha(lang) {
+		warnings = append(warnings, fmt.Sprintf("Tag 64.00 must be a 2-letter ISO 639-1 code (got %q)", lang))
+	}
+	if lt.MerchantName == "" {
+		warnings = append(warnings, "Tag 64.01 is required")
+	}
+	for _, f := range []struct{ id, value string }{
+		{LangMerchantName, lt.MerchantName},
+		{LangMerchantCity, lt.MerchantCity},
+	} {
+		label := "Tag " + IDMerchantInfoLanguageTemplate + "." + f.id
+		warnings = append(warnings, checkLanguageText(label, f.value,
+			subFieldSpec(IDMerchantInfoLanguageTemplate, f.id).maxLen)...)
+		if scripts, ok := languageScripts[lang]; ok {
+			if got := detectScript(f.value); got != "" && !slices.Contains(scripts, got) {
+				warnings = append(warnings, fmt.Sprintf("%s is in %s script but language %q is written in %s",
+					label, got, lang, scripts[0]))
+			}
+		}
+	}
+	return warnings
+}
+
+// checkLanguageText checks a free-text Language Template sub-field. Its
+// length is counted in bytes, as in the TLV length; the warning gives the
+// character count too, since the two differ for non-Latin scripts.
+func checkLanguageText(label, value string, maxLen int) []string {
+	var warnings []string
+	if !utf8.ValidString(value) {
+		return append(warnings, label+" is not valid UTF-8")
+	}
+	if n := len(value); n > maxLen {
+		if chars := utf8.RuneCountInString(value); chars != n {
+			warnings = append(warnings, fmt.Sprintf("%s exceeds %d bytes (got %d bytes, %d characters)", label, maxLen, n, chars))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s exceeds %d chars (got %d)", label, maxLen, n))
+		}
+	}
+	for _, r := range value {
+		switch {
+		case unicode.IsControl(r):
+			warnings = append(warnings, fmt.Sprintf("%s contains control character U+%04X", label, r))
+		case isBidiControl(r):
+			warnings = append(warnings, fmt.Sprintf("%s contains bidirectional formatting character U+%04X", label, r))
+		}
+	}
+	return warnings
+}
+
+// isBidiControl reports whether r is a bidirectional mark, embedding,
+// override or isolate, which can change how surrounding text is displayed.
+func isBidiControl(r rune) bool {
+	return r == '\u061C' || r == '\u200E' || r == '\u200F' ||
+		r >= '\u202A' && r <= '\u202E' || r >= '\u2066' && r <= '\u2069'
+}
+
+// detectScript returns the script of most letters in s, or "".
+func detectScript(s string) string {
+	counts := make(map[string]int)
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for _, name := range detectableScripts {
+			if unicode.Is(unicode.Scripts[name], r) {
+				counts[name]++
+				break
+			}
+		}
+	}
+	best := ""
+	for _, name := range detectableScripts {
+		if counts[name] > counts[best] {
+			best = name
+		}
+	}
+	return best
+}
+
+// isLowerAlpha reports whether s consists only of ASCII lowercase letters.
+func isLowerAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package emvqr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLanguageTemplate_Script(t *testing.T) {
+	tests := []struct {
+		lt     LanguageTemplate
+		script string
+		rtl    bool
+	}{
+		{LanguageTemplate{LanguagePreference: "ur", MerchantName: "کراچی سویٹس"}, "Arabic", true},
+		{LanguageTemplate{LanguagePreference: "he", MerchantName: "קפה"}, "Hebrew", true},
+		{LanguageTemplate{LanguagePreference: "hi", MerchantName: "राज मेडिकल"}, "Devanagari", false},
+		{LanguageTemplate{LanguagePreference: "xx", MerchantName: "مطعم"}, "Arabic", true},
+		{LanguageTemplate{LanguagePreference: "xx", MerchantName: "123"}, "", false},
+	}
+	for _, tc := range tests {
+		if got := tc.lt.Script(); got != tc.script {
+			t.Errorf("%q Script() = %q, want %q", tc.lt.MerchantName, got, tc.script)
+		}
+		if got := tc.lt.RightToLeft(); got != tc.rtl {
+			t.Errorf("%q RightToLeft() = %v, want %v", tc.lt.MerchantName, got, tc.rtl)
+		}
+	}
+}
+
+func TestLanguageTemplate_DisplayMerchantName(t *testing.T) {
+	lt := LanguageTemplate{LanguagePreference: "ur", MerchantName: "\u202Eکراچی\u0007"}
+	assertEqual(t, "rtl", "\u2067کراچی\u2069", lt.DisplayMerchantName())
+	lt = LanguageTemplate{LanguagePreference: "hi", MerchantName: "राज\u200E"}
+	assertEqual(t, "ltr", "राज", lt.DisplayMerchantName())
+}
+
+func TestLanguageTemplate_Check(t *testing.T) {
+	ok := LanguageTemplate{LanguagePreference: "ur", MerchantName: "کراچی سویٹس", MerchantCity: "لاہور"}
+	if got := ok.Check(); len(got) != 0 {
+		t.Errorf("Check() = %q, want none", got)
+	}
+
+	bad := LanguageTemplate{
+		LanguagePreference: "UR",
+		MerchantName:       "Karachi\u202E Sweets",
+		MerchantCity:       "لاہور\tشہر",
+	}
+	want := []string{
+		`Tag 64.00 must be a 2-letter ISO 639-1 code (got "UR")`,
+		"Tag 64.01 contains bidirectional formatting character U+202E",
+		"Tag 64.02 exceeds 15 bytes (got 17 bytes, 9 characters)",
+		"Tag 64.02 contains control character U+0009",
+	}
+	if diff := cmp.Diff(want, bad.Check()); diff != "" {
+		t.Errorf("Check() mismatch (-want +got):\n%s", diff)
+	}
+
+	mismatch := LanguageTemplate{LanguagePreference: "ur", MerchantName: "Karachi Sweets"}
+	want = []string{`Tag 64.01 is in Latin script but language "ur" is written in Arabic`}
+	if diff := cmp.Diff(want, mismatch.Check()); diff != "" {
+		t.Errorf("Check() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExplain_LanguageTemplateScript(t *testing.T) {
+	p := basePayload()
+	p.SetLanguageTemplate("ar", "مطعم الشرق الأوسط", "")
+	raw, err := Encode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := Explain(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"ar (Arabic, right-to-left)",
+		"WARNING: Tag 64.01 exceeds 25 bytes (got 32 bytes, 17 characters)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Explain output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package emvqr
+
+// RawSegment is the part of a raw payload string that a data object was
+// decoded from.
+type RawSegment struct {
+	Path string // dotted tag path, e.g. "26" or "26.01"
+	// Start and End are the byte offsets of the object, ID and length
+	// included, in the raw string: Raw is raw[Start:End].
+	Start, End int
+	Raw        string
+}
+
+// Value returns the value of the object, without its ID and length.
+func (s RawSegment) Value() string {
+	return s.Raw[4:]
+}
+
+// RawSegment returns the raw segment of the data object at path, e.g. "26"
+// or "62.05", as recorded by DecodeOptions.CaptureRaw. If the payload holds
+// the object more than once, the first is returned. It reports false when
+// the object is absent or the payload was decoded without CaptureRaw.
+func (p *Payload) RawSegment(path string) (RawSegment, bool) {
+	for _, s := range p.RawSegments {
+		if s.Path == path {
+			return s, true
+		}
+	}
+	return RawSegment{}, false
+}
+
+// appendRawSegments appends the segments of the objects in s, the value of
+// the template at path ("" at the top level) starting at offset base of the
+// raw string, and of their sub-fields. It stops at malformed TLV.
+func appendRawSegments(segments []RawSegment, s string, base int, path string) []RawSegment {
+	for off := 0; len(s)-off >= 4; {
+		length, ok := parseTLVLength(s[off+2], s[off+3])
+		if !ok || len(s)-off < 4+length {
+			break
+		}
+		id, end := s[off:off+2], off+4+length
+		field := joinPath(path, id)
+		segments = append(segments, RawSegment{Path: field, Start: base + off, End: base + end, Raw: s[off:end]})
+		if (path == "" && isTemplateID(id)) || (path == IDAdditionalDataFieldTemplate && isPaymentSystemTemplateID(id)) {
+			segments = appendRawSegments(segments, s[off+4:end], base+off+4, field)
+		}
+		off = end
+	}
+	return segments
+}
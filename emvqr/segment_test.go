@@ -0,0 +1,84 @@
+package emvqr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecode_CaptureRaw(t *testing.T) {
+	raw := realWorldBharatQRPayload
+	p, err := DecodeWithOptions(raw, DecodeOptions{CaptureRaw: true})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions: %v", err)
+	}
+	if len(p.RawSegments) == 0 {
+		t.Fatal("RawSegments empty")
+	}
+	for _, s := range p.RawSegments {
+		if raw[s.Start:s.End] != s.Raw {
+			t.Errorf("%s: raw[%d:%d] = %q, Raw = %q", s.Path, s.Start, s.End, raw[s.Start:s.End], s.Raw)
+		}
+		if !strings.HasSuffix(s.Path, s.Raw[:2]) {
+			t.Errorf("%s: segment starts with tag %q", s.Path, s.Raw[:2])
+		}
+	}
+
+	crc, ok := p.RawSegment(IDCRC)
+	if !ok {
+		t.Fatal("RawSegment(63) not found")
+	}
+	assertEqual(t, "CRC segment", raw[len(raw)-8:], crc.Raw)
+	assertEqual(t, "CRC value", p.CRC, crc.Value())
+
+	for _, path := range []string{"00", "62", "62.05"} {
+		s, ok := p.RawSegment(path)
+		if !ok {
+			continue
+		}
+		if got := p.Flatten()[path]; path != "62" && got != s.Value() {
+			t.Errorf("%s: Value() = %q, Flatten = %q", path, s.Value(), got)
+		}
+	}
+}
+
+func TestDecode_CaptureRawSubFields(t *testing.T) {
+	p := basePayload()
+	p.AdditionalData = &AdditionalDataField{BillNumber: "INV-1", ReferenceLabel: "REF"}
+	raw := mustEncode(t, p)
+
+	got, err := DecodeWithOptions(raw, DecodeOptions{CaptureRaw: true})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions: %v", err)
+	}
+	tmpl, ok := got.RawSegment("62")
+	if !ok {
+		t.Fatal("RawSegment(62) not found")
+	}
+	bill, ok := got.RawSegment("62.01")
+	if !ok {
+		t.Fatal("RawSegment(62.01) not found")
+	}
+	assertEqual(t, "bill", "0105INV-1", bill.Raw)
+	if bill.Start != tmpl.Start+4 {
+		t.Errorf("62.01 Start = %d, want %d", bill.Start, tmpl.Start+4)
+	}
+	if _, ok := got.RawSegment("62.99"); ok {
+		t.Error("RawSegment(62.99) found")
+	}
+}
+
+func TestDecode_CaptureRawOff(t *testing.T) {
+	p := mustDecode(t, realWorldBharatQRPayload)
+	if p.RawSegments != nil {
+		t.Errorf("RawSegments = %v, want nil without CaptureRaw", p.RawSegments)
+	}
+	if _, ok := p.RawSegment("00"); ok {
+		t.Error("RawSegment found without CaptureRaw")
+	}
+	// Captured segments must not affect re-encoding.
+	captured, err := DecodeWithOptions(realWorldBharatQRPayload, DecodeOptions{CaptureRaw: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "re-encode", mustEncode(t, p), mustEncode(t, captured))
+}
@@ -0,0 +1,82 @@
+package emvqr
+
+import (
+	"context"
+	"fmt"
+)
+
+// URLShortener shortens a Tag 27 Reference URL to fit the 26 characters
+// Bharat QR allows, typically through the integrator's short-link service.
+// It must be safe for concurrent use when encodes run concurrently.
+type URLShortener interface {
+	Shorten(ctx context.Context, url string) (string, error)
+}
+
+// URLExpander resolves a short Tag 27 Reference URL to the link it stands
+// for, undoing a URLShortener.
+type URLExpander interface {
+	Expand(ctx context.Context, url string) (string, error)
+}
+
+// ShortenerFunc adapts a function to URLShortener.
+type ShortenerFunc func(ctx context.Context, url string) (string, error)
+
+// Shorten calls f(ctx, url).
+func (f ShortenerFunc) Shorten(ctx context.Context, url string) (string, error) { return f(ctx, url) }
+
+// ExpanderFunc adapts a function to URLExpander.
+type ExpanderFunc func(ctx context.Context, url string) (string, error)
+
+// Expand calls f(ctx, url).
+func (f ExpanderFunc) Expand(ctx context.Context, url string) (string, error) { return f(ctx, url) }
+
+// EncodeContext is EncodeWithOptions, passing ctx to opts.Shortener.
+func EncodeContext(ctx context.Context, p *Payload, opts EncodeOptions) (string, error) {
+	if opts.Shortener != nil && p != nil {
+		var err error
+		if p, err = shortenReferenceURL(ctx, p, opts.Shortener); err != nil {
+			return "", err
+		}
+	}
+	return encode(p, opts)
+}
+
+// shortenReferenceURL returns p, or a copy of p whose Reference URL s has
+// shortened if it is longer than Bharat QR allows. p is not modified.
+func shortenReferenceURL(ctx context.Context, p *Payload, s URLShortener) (*Payload, error) {
+	r := p.UPITransactionRef
+	if r == nil || len(r.ReferenceURL) <= maxTypicalReferenceURL {
+		return p, nil
+	}
+	path := IDUPIVPAReference + "." + UPIVPARefURL
+	short, err := s.Shorten(ctx, r.ReferenceURL)
+	if err != nil {
+		return nil, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: shortening reference URL: %w", ErrSchemeViolation, err), "tag", path)
+	}
+	if short == "" || len(short) > maxTypicalReferenceURL {
+		return nil, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: shortened reference URL %q is not 1–%d characters", ErrSchemeViolation, short, maxTypicalReferenceURL),
+			"tag", path, "value", short)
+	}
+	c := *p
+	ref := *r
+	ref.ReferenceURL = short
+	c.UPITransactionRef = &ref
+	return &c, nil
+}
+
+// expandReferenceURL replaces the Reference URL of p with its expansion.
+func expandReferenceURL(ctx context.Context, p *Payload, e URLExpander) error {
+	r := p.UPITransactionRef
+	if r == nil || r.ReferenceURL == "" {
+		return nil
+	}
+	long, err := e.Expand(ctx, r.ReferenceURL)
+	if err != nil {
+		return newError(CodeInvalidFormat, fmt.Errorf("%w: expanding reference URL %q: %w", ErrInvalidFormat, r.ReferenceURL, err),
+			"tag", IDUPIVPAReference+"."+UPIVPARefURL, "value", r.ReferenceURL)
+	}
+	r.ReferenceURL = long
+	return nil
+}
@@ -0,0 +1,84 @@
+package emvqr
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// linkService is a fake short-link service.
+type linkService map[string]string
+
+func (l linkService) Shorten(_ context.Context, url string) (string, error) {
+	short := "https://s.io/" + string(rune('a'+len(l)))
+	l[short] = url
+	return short, nil
+}
+
+func (l linkService) Expand(_ context.Context, url string) (string, error) {
+	if long, ok := l[url]; ok {
+		return long, nil
+	}
+	return "", errors.New("unknown link")
+}
+
+func TestEncodeContext_Shortener(t *testing.T) {
+	const invoice = "https://billing.example.com/invoices/2026/INV-000123"
+	p := basePayload()
+	if err := p.SetUPIVPAReference("INV-000123", ""); err != nil {
+		t.Fatal(err)
+	}
+	p.UPITransactionRef.ReferenceURL = invoice
+
+	links := linkService{}
+	raw, err := EncodeContext(context.Background(), p, EncodeOptions{Shortener: links})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "payload URL", invoice, p.UPITransactionRef.ReferenceURL)
+	if !strings.Contains(raw, "0214https://s.io/a") {
+		t.Errorf("encoded payload %q does not carry the short link", raw)
+	}
+
+	d, err := DecodeWithOptions(raw, DecodeOptions{Expander: links})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "expanded URL", invoice, d.UPITransactionRef.ReferenceURL)
+
+	// Short URLs are left alone.
+	p.UPITransactionRef.ReferenceURL = "https://x.io/1"
+	if _, err := EncodeWithOptions(p, EncodeOptions{Shortener: links}); err != nil || len(links) != 1 {
+		t.Errorf("short URL: err = %v, shortener called %d times", err, len(links))
+	}
+}
+
+func TestEncodeContext_ShortenerErrors(t *testing.T) {
+	p := basePayload()
+	p.UPITransactionRef = &UPIVPAReference{RuPayRID: RuPayRIDValue, TransactionRef: "INV-000123",
+		ReferenceURL: "https://billing.example.com/invoices/INV-000123"}
+	for name, s := range map[string]URLShortener{
+		"failure":  ShortenerFunc(func(context.Context, string) (string, error) { return "", errors.New("down") }),
+		"too long": ShortenerFunc(func(_ context.Context, url string) (string, error) { return url[:30], nil }),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := EncodeWithOptions(p, EncodeOptions{Shortener: s})
+			if !errors.Is(err, ErrSchemeViolation) {
+				t.Fatalf("error = %v, want ErrSchemeViolation", err)
+			}
+			assertEqual(t, "tag", "27.02", ErrorParams(err)["tag"])
+		})
+	}
+}
+
+func TestDecode_ExpanderError(t *testing.T) {
+	p := basePayload()
+	p.UPITransactionRef = &UPIVPAReference{RuPayRID: RuPayRIDValue, TransactionRef: "INV-000123", ReferenceURL: "https://s.io/zz"}
+	raw := mustEncode(t, p)
+	d, err := DecodeWithOptions(raw, DecodeOptions{Expander: linkService{}})
+	if !errors.Is(err, ErrInvalidFormat) || d == nil {
+		t.Fatalf("DecodeWithOptions() = %v, %v; want the payload and ErrInvalidFormat", d, err)
+	}
+	assertEqual(t, "URL", "https://s.io/zz", d.UPITransactionRef.ReferenceURL)
+}
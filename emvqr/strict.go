@@ -0,0 +1,95 @@
+package emvqr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// strictFields are the top-level fields whose type ValidateFieldFormats
+// enforces, in encoding order.
+var strictFields = []string{
+	IDMerchantCategoryCode, IDTransactionCurrency, IDTransactionAmount,
+	IDTipOrConvenienceIndicator, IDValueConvenienceFeeFixed, IDValueConvenienceFeePercent,
+}
+
+// ValidateFieldFormats checks the typed top-level fields of p strictly:
+// the Merchant Category Code (Tag 52) must be 4 digits, the Transaction
+// Currency (Tag 53) 3 digits and the Tip or Convenience Indicator (Tag 55)
+// 2 digits; the amounts of Tags 54, 56 and 57 must be digits with an
+// optional decimal part, such as "10" or "10.50", within their length; and
+// the Postal Code (Tag 61) must match the format of the country, as
+// ValidatePostalCode checks. The Point of Initiation Method (Tag 01) is
+// always checked, by ParsePOI.
+//
+// It returns every violation, joined with errors.Join, each wrapping
+// ErrInvalidFormat with its tag in ErrorParams; nil if there are none.
+// DecodeOptions.StrictFormats and EncodeOptions.StrictFormats apply it.
+func (p *Payload) ValidateFieldFormats() error {
+	return joinErrors(fieldFormatErrors(p))
+}
+
+// fieldFormatErrors returns the violations ValidateFieldFormats reports.
+func fieldFormatErrors(p *Payload) []error {
+	var errs []error
+	for _, id := range strictFields {
+		value := p.topLevelValue(id)
+		if value == "" {
+			continue
+		}
+		s := topLevelSpecs[id]
+		var problem string
+		switch {
+		case s.format == formatN && (!isNumeric(value) || len(value) != s.minLen):
+			problem = fmt.Sprintf("must be %d digits", s.minLen)
+		case s.format == formatAmount && (!isStrictAmount(value) || len(value) > s.maxLen):
+			problem = fmt.Sprintf("must be a decimal amount of at most %d characters, such as \"10.50\"", s.maxLen)
+		default:
+			continue
+		}
+		errs = append(errs, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: %s (Tag %s) %q %s", ErrInvalidFormat, s.name, id, value, problem),
+			"tag", id, "value", value))
+	}
+	if err := p.ValidatePostalCode(); err != nil {
+		errs = append(errs, newError(CodeInvalidFormat, err, "tag", IDPostalCode, "value", p.PostalCode))
+	}
+	return errs
+}
+
+// topLevelValue returns the value of a typed top-level field of p.
+func (p *Payload) topLevelValue(id string) string {
+	switch id {
+	case IDMerchantCategoryCode:
+		return p.MerchantCategoryCode
+	case IDTransactionCurrency:
+		return p.TransactionCurrency
+	case IDTransactionAmount:
+		return p.TransactionAmount
+	case IDTipOrConvenienceIndicator:
+		return p.TipOrConvenienceIndicator
+	case IDValueConvenienceFeeFixed:
+		return p.ValueConvenienceFeeFixed
+	case IDValueConvenienceFeePercent:
+		return p.ValueConvenienceFeePercent
+	}
+	return ""
+}
+
+// isStrictAmount reports whether s is one or more digits, optionally
+// followed by '.' and one or more digits.
+func isStrictAmount(s string) bool {
+	whole, frac, dot := strings.Cut(s, ".")
+	return whole != "" && isNumeric(whole) && (!dot || frac != "" && isNumeric(frac))
+}
+
+// joinErrors is errors.Join, returning a lone error unwrapped.
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
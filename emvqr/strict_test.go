@@ -0,0 +1,75 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateFieldFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(p *Payload)
+		tag  string // "" if valid
+	}{
+		{"valid", func(p *Payload) { p.TransactionAmount = "10.50" }, ""},
+		{"whole amount", func(p *Payload) { p.TransactionAmount = "10" }, ""},
+		{"MCC letters", func(p *Payload) { p.MerchantCategoryCode = "ABCD" }, "52"},
+		{"MCC short", func(p *Payload) { p.MerchantCategoryCode = "541" }, "52"},
+		{"currency alpha", func(p *Payload) { p.TransactionCurrency = "USD" }, "53"},
+		{"amount comma", func(p *Payload) { p.TransactionAmount = "1,50" }, "54"},
+		{"amount trailing dot", func(p *Payload) { p.TransactionAmount = "10." }, "54"},
+		{"amount leading dot", func(p *Payload) { p.TransactionAmount = ".5" }, "54"},
+		{"amount too long", func(p *Payload) { p.TransactionAmount = "12345678901234" }, "54"},
+		{"indicator", func(p *Payload) { p.TipOrConvenienceIndicator = "1" }, "55"},
+		{"fixed fee", func(p *Payload) {
+			p.TipOrConvenienceIndicator = TipIndicatorFixedConvenienceFee
+			p.ValueConvenienceFeeFixed = "2.5.0"
+		}, "56"},
+		{"postal code", func(p *Payload) { p.PostalCode = "ABCDE" }, "61"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := basePayload()
+			tt.edit(p)
+			err := p.ValidateFieldFormats()
+			if tt.tag == "" {
+				if err != nil {
+					t.Fatalf("ValidateFieldFormats() = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidFormat) {
+				t.Fatalf("ValidateFieldFormats() = %v, want ErrInvalidFormat", err)
+			}
+			assertEqual(t, "tag", tt.tag, ErrorParams(err)["tag"])
+		})
+	}
+
+	p := basePayload()
+	p.MerchantCategoryCode = "ABCD"
+	p.TransactionCurrency = "USD"
+	err := p.ValidateFieldFormats()
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 2 {
+		t.Errorf("ValidateFieldFormats() = %v, want both violations", err)
+	}
+}
+
+func TestStrictFormats_DecodeEncode(t *testing.T) {
+	p := basePayload()
+	p.MerchantCategoryCode = "ABCD"
+	raw := mustEncode(t, p)
+	if _, err := Decode(raw); err != nil {
+		t.Fatalf("Decode() without StrictFormats: %v", err)
+	}
+	_, err := DecodeWithOptions(raw, DecodeOptions{StrictFormats: true})
+	if !errors.Is(err, ErrInvalidFormat) || !strings.Contains(err.Error(), `"ABCD" must be 4 digits`) {
+		t.Errorf("DecodeWithOptions(StrictFormats) error = %v", err)
+	}
+	if _, err := EncodeWithOptions(p, EncodeOptions{StrictFormats: true}); ErrorParams(err)["tag"] != "52" {
+		t.Errorf("EncodeWithOptions(StrictFormats) error = %v", err)
+	}
+	if _, err := DecodeWithOptions(realWorldBharatQRPayload, DecodeOptions{StrictFormats: true}); err != nil {
+		t.Errorf("real-world payload rejected: %v", err)
+	}
+}
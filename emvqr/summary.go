@@ -0,0 +1,182 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// PaymentSummary holds display-ready strings describing a payment, for
+// wallet confirmation screens. Amounts are formatted with the currency
+// symbol; fields that do not apply are empty.
+type PaymentSummary struct {
+	// Merchant and City are the merchant name and city, in the requested
+	// language when the Language Template provides it.
+	Merchant string
+	City     string
+
+	// Amount is the total the consumer pays, e.g. "₹550.00". It is empty
+	// when the consumer enters the amount.
+	Amount string
+	// BaseAmount is the transaction amount before fees, e.g. "₹500.00".
+	BaseAmount string
+	// Fee is the convenience fee, e.g. "₹50.00", or "3%" when there is no
+	// transaction amount to apply a percentage to.
+	Fee string
+
+	// Headline is the main line, e.g. "Pay ₹550.00 to Spice Garden, Bangalore".
+	Headline string
+	// Note qualifies the headline, e.g. "includes ₹50.00 convenience fee".
+	Note string
+	// Text is Headline and Note joined with an em dash.
+	Text string
+}
+
+// Summary returns the PaymentSummary of p. lang (a BCP 47 tag such as
+// "hi-IN") selects the merchant name and city from the Language Template
+// (see PreferredMerchantName) and the language of the phrases, which are
+// English unless RegisterSummaryPhrases or the built-in Hindi provides
+// them:
+//
+//	Pay ₹550.00 to Spice Garden, Bangalore — includes ₹50.00 convenience fee
+//	Pay Spice Garden, Bangalore — plus 3% convenience fee
+//	Pay $12.00 to ABC Hammers, New York — you may add a tip
+//
+// Amounts are computed exactly, as by FeeBreakdown. Summary returns an
+// error wrapping ErrInvalidFormat if the transaction amount (Tag 54) or the
+// convenience fee (Tag 56 or 57) is not a decimal amount.
+func (p *Payload) Summary(lang string) (PaymentSummary, error) {
+	s := PaymentSummary{
+		Merchant: p.PreferredMerchantName(lang),
+		City:     p.PreferredMerchantCity(lang),
+	}
+	included := false // whether Amount includes Fee
+	if p.TransactionAmount != "" {
+		b, err := p.FeeBreakdown()
+		if err != nil {
+			return PaymentSummary{}, err
+		}
+		s.BaseAmount = formatMoney(p.TransactionCurrency, b.BaseDisplay)
+		s.Amount = formatMoney(p.TransactionCurrency, b.TotalDisplay)
+		if b.Type == FeeFixed || b.Type == FeePercentage {
+			s.Fee = formatMoney(p.TransactionCurrency, b.FeeDisplay)
+			included = true
+		}
+	} else {
+		switch {
+		case p.TipOrConvenienceIndicator == TipIndicatorFixedConvenienceFee && p.ValueConvenienceFeeFixed != "":
+			fee, err := parseAmount(IDValueConvenienceFeeFixed, p.ValueConvenienceFeeFixed)
+			if err != nil {
+				return PaymentSummary{}, err
+			}
+			s.Fee = formatMoney(p.TransactionCurrency, fee.FloatString(CurrencyExponent(p.TransactionCurrency)))
+		case p.TipOrConvenienceIndicator == TipIndicatorPercentageFee && p.ValueConvenienceFeePercent != "":
+			if _, err := parseAmount(IDValueConvenienceFeePercent, p.ValueConvenienceFeePercent); err != nil {
+				return PaymentSummary{}, err
+			}
+			s.Fee = p.ValueConvenienceFeePercent + "%"
+		}
+	}
+
+	phrases := lookupSummaryPhrases(lang)
+	where := s.Merchant
+	if s.City != "" {
+		where += ", " + s.City
+	}
+	if s.Amount != "" {
+		s.Headline = fmt.Sprintf(phrases.PayAmount, s.Amount, where)
+	} else {
+		s.Headline = fmt.Sprintf(phrases.Pay, where)
+	}
+	switch {
+	case p.TipOrConvenienceIndicator == TipIndicatorPromptConsumer:
+		s.Note = phrases.Tip
+	case included:
+		s.Note = fmt.Sprintf(phrases.IncludesFee, s.Fee)
+	case s.Fee != "":
+		s.Note = fmt.Sprintf(phrases.PlusFee, s.Fee)
+	}
+	s.Text = s.Headline
+	if s.Note != "" {
+		s.Text += " — " + s.Note
+	}
+	return s, nil
+}
+
+// SummaryPhrases are the phrases of a PaymentSummary in one language, as
+// fmt formats. Explicit argument indexes let a language reorder them.
+type SummaryPhrases struct {
+	PayAmount   string // amount, then merchant and city, e.g. "Pay %s to %s"
+	Pay         string // merchant and city, e.g. "Pay %s"
+	IncludesFee string // fee, e.g. "includes %s convenience fee"
+	PlusFee     string // fee, e.g. "plus %s convenience fee"
+	Tip         string // e.g. "you may add a tip"
+}
+
+// summaryPhrases is the phrase catalog of Summary, keyed by language.
+var (
+	summaryPhrasesMu sync.RWMutex
+	summaryPhrases   = map[string]SummaryPhrases{
+		"en": {
+			PayAmount:   "Pay %s to %s",
+			Pay:         "Pay %s",
+			IncludesFee: "includes %s convenience fee",
+			PlusFee:     "plus %s convenience fee",
+			Tip:         "you may add a tip",
+		},
+		"hi": {
+			PayAmount:   "%[2]s को %[1]s का भुगतान करें",
+			Pay:         "%s को भुगतान करें",
+			IncludesFee: "%s सुविधा शुल्क शामिल",
+			PlusFee:     "%s सुविधा शुल्क अतिरिक्त",
+			Tip:         "आप टिप जोड़ सकते हैं",
+		},
+	}
+)
+
+// RegisterSummaryPhrases adds or replaces the Summary phrases for a
+// language, e.g. "ta".
+func RegisterSummaryPhrases(lang string, phrases SummaryPhrases) {
+	summaryPhrasesMu.Lock()
+	defer summaryPhrasesMu.Unlock()
+	summaryPhrases[lang] = phrases
+}
+
+// lookupSummaryPhrases returns the phrases for lang or its fallbacks,
+// ending with English.
+func lookupSummaryPhrases(lang string) SummaryPhrases {
+	summaryPhrasesMu.RLock()
+	defer summaryPhrasesMu.RUnlock()
+	for lang != "" {
+		if phrases, ok := summaryPhrases[lang]; ok {
+			return phrases
+		}
+		i := strings.LastIndexAny(lang, "-_")
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+	return summaryPhrases["en"]
+}
+
+// formatMoney formats an amount, already formatted to the minor unit, with
+// the symbol of an ISO 4217 numeric currency.
+func formatMoney(currency, amount string) string {
+	if sym, found := currencySymbols[currency]; found {
+		return sym + amount
+	}
+	if code, found := currencyCodes[currency]; found {
+		return code + " " + amount
+	}
+	return strings.TrimSpace(currency + " " + amount)
+}
+
+// currencySymbols maps ISO 4217 numeric codes to display symbols. Others
+// are shown with their alphabetic code.
+var currencySymbols = map[string]string{
+	"036": "A$", "124": "C$", "156": "CN¥", "344": "HK$", "356": "₹", "360": "Rp",
+	"392": "¥", "410": "₩", "458": "RM", "554": "NZ$", "566": "₦", "608": "₱",
+	"702": "S$", "704": "₫", "764": "฿", "826": "£", "840": "$", "949": "₺",
+	"978": "€", "986": "R$",
+}
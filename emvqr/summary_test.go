@@ -0,0 +1,108 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	spice := func() *Payload {
+		p := basePayload()
+		p.MerchantName = "Spice Garden"
+		p.MerchantCity = "Bangalore"
+		p.TransactionCurrency = "356"
+		return p
+	}
+
+	tests := []struct {
+		name  string
+		setup func(p *Payload)
+		lang  string
+		want  string
+	}{
+		{"fixed fee", func(p *Payload) {
+			p.TransactionAmount = "500.00"
+			p.SetFixedConvenienceFee("50")
+		}, "", "Pay ₹550.00 to Spice Garden, Bangalore — includes ₹50.00 convenience fee"},
+		{"percentage fee", func(p *Payload) {
+			p.TransactionAmount = "200"
+			p.SetPercentageConvenienceFee("2.5")
+		}, "", "Pay ₹205.00 to Spice Garden, Bangalore — includes ₹5.00 convenience fee"},
+		{"percentage without amount", func(p *Payload) {
+			p.SetPercentageConvenienceFee("3")
+		}, "", "Pay Spice Garden, Bangalore — plus 3% convenience fee"},
+		{"tip", func(p *Payload) {
+			p.TransactionAmount = "12"
+			p.SetPromptForTip()
+		}, "", "Pay ₹12.00 to Spice Garden, Bangalore — you may add a tip"},
+		{"language template", func(p *Payload) {
+			p.SetLanguageTemplate("hi", "स्पाइस गार्डन", "बेंगलुरु")
+		}, "hi", "स्पाइस गार्डन, बेंगलुरु को भुगतान करें"},
+		{"hindi phrases", func(p *Payload) {
+			p.TransactionAmount = "500.00"
+			p.SetFixedConvenienceFee("50")
+		}, "hi-IN", "Spice Garden, Bangalore को ₹550.00 का भुगतान करें — ₹50.00 सुविधा शुल्क शामिल"},
+		{"unregistered language", func(p *Payload) {
+			p.TransactionAmount = "10"
+		}, "ta", "Pay ₹10.00 to Spice Garden, Bangalore"},
+		{"unknown currency", func(p *Payload) {
+			p.TransactionCurrency = "999"
+			p.TransactionAmount = "1.5"
+		}, "", "Pay 999 1.50 to Spice Garden, Bangalore"},
+		{"zero-decimal currency", func(p *Payload) {
+			p.TransactionCurrency = "392"
+			p.TransactionAmount = "1200"
+		}, "", "Pay ¥1200 to Spice Garden, Bangalore"},
+	}
+	for _, tc := range tests {
+		p := spice()
+		tc.setup(p)
+		s, err := p.Summary(tc.lang)
+		if err != nil {
+			t.Errorf("%s: Summary: %v", tc.name, err)
+			continue
+		}
+		if s.Text != tc.want {
+			t.Errorf("%s: Summary().Text = %q, want %q", tc.name, s.Text, tc.want)
+		}
+	}
+
+	p := spice()
+	p.TransactionAmount = "500.00"
+	p.SetFixedConvenienceFee("50")
+	s, err := p.Summary("en")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if s.BaseAmount != "₹500.00" || s.Fee != "₹50.00" || s.Amount != "₹550.00" {
+		t.Errorf("Summary() amounts = %q/%q/%q", s.BaseAmount, s.Fee, s.Amount)
+	}
+}
+
+func TestSummary_MalformedAmounts(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(p *Payload)
+		tag   string
+	}{
+		{"amount", func(p *Payload) { p.TransactionAmount = "abc" }, IDTransactionAmount},
+		{"fixed fee", func(p *Payload) {
+			p.TransactionAmount = "500"
+			p.SetFixedConvenienceFee("5O")
+		}, IDValueConvenienceFeeFixed},
+		{"fixed fee without amount", func(p *Payload) { p.SetFixedConvenienceFee("-") }, IDValueConvenienceFeeFixed},
+		{"percentage without amount", func(p *Payload) { p.SetPercentageConvenienceFee("x") }, IDValueConvenienceFeePercent},
+	}
+	for _, tc := range tests {
+		p := basePayload()
+		tc.setup(p)
+		s, err := p.Summary("")
+		if !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("%s: Summary() = %q, %v; want ErrInvalidFormat", tc.name, s.Text, err)
+			continue
+		}
+		if got := ErrorParams(err)["tag"]; got != tc.tag {
+			t.Errorf("%s: error tag = %q, want %q", tc.name, got, tc.tag)
+		}
+	}
+}
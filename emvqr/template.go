@@ -0,0 +1,100 @@
+package emvqr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// PayloadTemplate is a Payload whose field values may contain text/template
+// placeholders, such as a merchant name of "ABC Hammers {{.Branch}}" or an
+// amount of "{{.Amount}}". It is resolved per store or per transaction, so
+// QR generation can be driven by configuration rather than code:
+//
+//	base := emvqr.NewPayload()
+//	base.MerchantName = "ABC Hammers"
+//	base.TransactionAmount = "{{.Amount}}"
+//	base.SetAdditionalData(func(a *emvqr.AdditionalDataField) {
+//	    a.StoreLabel = "{{.StoreID}}"
+//	})
+//	tmpl, err := emvqr.NewPayloadTemplate(base)
+//	...
+//	raw, err := tmpl.Encode(map[string]string{"StoreID": "S42", "Amount": "9.99"})
+//
+// A PayloadTemplate is safe for concurrent use.
+type PayloadTemplate struct {
+	static  map[string]string
+	dynamic map[string]*template.Template
+}
+
+// NewPayloadTemplate parses the placeholders in every field of p, addressed
+// by the tag paths of Flatten. Fields without "{{" are copied verbatim. The
+// CRC of p is ignored. Missing map keys are reported as errors when the
+// template is executed.
+func NewPayloadTemplate(p *Payload) (*PayloadTemplate, error) {
+	t := &PayloadTemplate{static: make(map[string]string), dynamic: make(map[string]*template.Template)}
+	for path, value := range p.Flatten() {
+		switch {
+		case path == IDCRC:
+		case strings.Contains(value, "{{"):
+			tmpl, err := template.New(path).Option("missingkey=error").Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("emvqr: template field %s: %w", path, err)
+			}
+			t.dynamic[path] = tmpl
+		default:
+			t.static[path] = value
+		}
+	}
+	return t, nil
+}
+
+// Placeholders returns the tag paths of the fields that contain
+// placeholders, in ascending order.
+func (t *PayloadTemplate) Placeholders() []string {
+	paths := make([]string, 0, len(t.dynamic))
+	for path := range t.dynamic {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Execute resolves the placeholders against data and returns the resulting
+// Payload. Each substituted value must satisfy the format and length rules
+// of its field, or ErrInvalidFormat is returned; a value that resolves to
+// "" omits the field.
+func (t *PayloadTemplate) Execute(data any) (*Payload, error) {
+	m := make(map[string]string, len(t.static)+len(t.dynamic))
+	for path, value := range t.static {
+		m[path] = value
+	}
+	var problems []string
+	for _, path := range t.Placeholders() {
+		var sb strings.Builder
+		if err := t.dynamic[path].Execute(&sb, data); err != nil {
+			return nil, fmt.Errorf("emvqr: template field %s: %w", path, err)
+		}
+		value := sb.String()
+		if value == "" {
+			continue
+		}
+		problems = append(problems, pathSpec(path).check("Tag "+path, value)...)
+		m[path] = value
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, strings.Join(problems, "; "))
+	}
+	return FromFlat(m)
+}
+
+// Encode resolves the placeholders against data and encodes the result with
+// Encode, which also checks the mandatory fields.
+func (t *PayloadTemplate) Encode(data any) (string, error) {
+	p, err := t.Execute(data)
+	if err != nil {
+		return "", err
+	}
+	return Encode(p)
+}
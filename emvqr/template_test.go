@@ -0,0 +1,73 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func storeTemplate(t *testing.T) *PayloadTemplate {
+	t.Helper()
+	p := basePayload()
+	p.MerchantName = "ABC Hammers {{.Branch}}"
+	p.TransactionAmount = "{{.Amount}}"
+	p.SetAdditionalData(func(a *AdditionalDataField) {
+		a.StoreLabel = "{{.StoreID}}"
+		a.TerminalLabel = "T1"
+	})
+	tmpl, err := NewPayloadTemplate(p)
+	if err != nil {
+		t.Fatalf("NewPayloadTemplate() error: %v", err)
+	}
+	return tmpl
+}
+
+func TestPayloadTemplate_Execute(t *testing.T) {
+	tmpl := storeTemplate(t)
+	if got := strings.Join(tmpl.Placeholders(), ","); got != "54,59,62.03" {
+		t.Errorf("Placeholders() = %q", got)
+	}
+
+	raw, err := tmpl.Encode(map[string]string{"Branch": "Soho", "StoreID": "S42", "Amount": "9.99"})
+	if err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	p, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if p.MerchantName != "ABC Hammers Soho" || p.TransactionAmount != "9.99" ||
+		p.AdditionalData.StoreLabel != "S42" || p.AdditionalData.TerminalLabel != "T1" {
+		t.Errorf("decoded %+v", p)
+	}
+
+	// Struct data works too, and an empty result omits the field.
+	p, err = tmpl.Execute(struct{ Branch, StoreID, Amount string }{"Soho", "S1", ""})
+	if err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	if p.TransactionAmount != "" {
+		t.Errorf("TransactionAmount = %q, want empty", p.TransactionAmount)
+	}
+}
+
+func TestPayloadTemplate_Errors(t *testing.T) {
+	tmpl := storeTemplate(t)
+	_, err := tmpl.Execute(map[string]string{"Branch": "Soho", "StoreID": "S42", "Amount": "ten"})
+	if !errors.Is(err, ErrInvalidFormat) || !strings.Contains(err.Error(), "Tag 54 is not a valid amount") {
+		t.Errorf("Execute() error = %v, want ErrInvalidFormat for Tag 54", err)
+	}
+	_, err = tmpl.Execute(map[string]string{"Branch": "and Tools Company", "StoreID": "S42", "Amount": "1"})
+	if !errors.Is(err, ErrInvalidFormat) || !strings.Contains(err.Error(), "Tag 59 exceeds 25 chars") {
+		t.Errorf("Execute() error = %v, want ErrInvalidFormat for Tag 59", err)
+	}
+	if _, err := tmpl.Execute(map[string]string{"Branch": "Soho"}); err == nil {
+		t.Error("Execute() expected error for missing key, got nil")
+	}
+
+	p := basePayload()
+	p.MerchantCity = "{{.City"
+	if _, err := NewPayloadTemplate(p); err == nil {
+		t.Error("NewPayloadTemplate() expected parse error, got nil")
+	}
+}
@@ -0,0 +1,23 @@
+package emvqr
+
+// MarshalText implements encoding.TextMarshaler. The text is the raw EMV
+// string produced by Encode, so a Payload can be stored in YAML or TOML
+// configuration, environment variables or command-line flags.
+func (p *Payload) MarshalText() ([]byte, error) {
+	raw, err := Encode(p)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(raw), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The text is parsed with
+// Decode, CRC included, and replaces p entirely.
+func (p *Payload) UnmarshalText(text []byte) error {
+	decoded, err := Decode(string(text))
+	if err != nil {
+		return err
+	}
+	*p = *decoded
+	return nil
+}
@@ -0,0 +1,54 @@
+package emvqr
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"testing"
+)
+
+const baseRaw = "000201021640001234567890125204525153038405802US5911ABC Hammers6008New York63047222"
+
+func TestText_RoundTrip(t *testing.T) {
+	text, err := basePayload().MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() error: %v", err)
+	}
+	if string(text) != baseRaw {
+		t.Errorf("MarshalText() = %q, want %q", text, baseRaw)
+	}
+
+	var p Payload
+	if err := p.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText() error: %v", err)
+	}
+	if p.MerchantName != "ABC Hammers" || p.CRC != "7222" {
+		t.Errorf("UnmarshalText() = %+v", p)
+	}
+}
+
+func TestText_Validates(t *testing.T) {
+	var p Payload
+	if err := p.UnmarshalText([]byte(baseRaw[:len(baseRaw)-4] + "0000")); !errors.Is(err, ErrCRCMismatch) {
+		t.Errorf("UnmarshalText() error = %v, want ErrCRCMismatch", err)
+	}
+	if _, err := (&Payload{}).MarshalText(); err == nil {
+		t.Error("MarshalText() expected error for empty payload, got nil")
+	}
+}
+
+func TestText_Flag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var p Payload
+	fs.TextVar(&p, "qr", basePayload(), "merchant QR payload")
+	if err := fs.Parse([]string{"-qr", baseRaw}); err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if p.MerchantCity != "New York" {
+		t.Errorf("flag value = %+v", p)
+	}
+	if err := fs.Parse([]string{"-qr", "garbage"}); err == nil {
+		t.Error("Parse() expected error for invalid payload, got nil")
+	}
+}
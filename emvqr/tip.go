@@ -0,0 +1,64 @@
+package emvqr
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// TipSuggestionsGUID is the Globally Unique Identifier of the Unreserved
+// Template in which SetTipSuggestions stores suggested tip percentages, one
+// per sub-field from "01", e.g. "10", "15" and "20" for the tip chips of a
+// restaurant bill.
+const TipSuggestionsGUID = "COM.GITHUB.EMVQR.TIP"
+
+// maxTipSuggestions is the most suggested tips SetTipSuggestions stores.
+const maxTipSuggestions = 5
+
+// SetTipSuggestions stores the tip percentages a wallet should offer, such
+// as "10", "12.5" and "15", for a payload that prompts the consumer for a
+// tip (Tag 55 "01", see SetPromptForTip). Each must be a decimal number
+// above 0 and at most 100; up to 5 are stored, in the order given. No
+// percentages removes the suggestions. Errors wrap ErrInvalidFormat and
+// leave the payload unchanged.
+func (p *Payload) SetTipSuggestions(percents ...string) error {
+	if len(percents) > 0 && p.TipOrConvenienceIndicator != TipIndicatorPromptConsumer {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: tip suggestions need Tag 55 %q (prompt for tip), got %q",
+				ErrInvalidFormat, TipIndicatorPromptConsumer, p.TipOrConvenienceIndicator),
+			"tag", IDTipOrConvenienceIndicator)
+	}
+	if len(percents) > maxTipSuggestions {
+		return fmt.Errorf("%w: at most %d tip suggestions, got %d", ErrInvalidFormat, maxTipSuggestions, len(percents))
+	}
+	subs := make([]DataObject, 0, len(percents))
+	for i, pct := range percents {
+		if !validTipPercent(pct) {
+			return fmt.Errorf("%w: tip suggestion %q must be a percentage above 0 and at most 100", ErrInvalidFormat, pct)
+		}
+		subs = append(subs, DataObject{ID: fmt.Sprintf("%02d", i+1), Value: pct})
+	}
+	return p.setExtension(TipSuggestionsGUID, subs)
+}
+
+// TipSuggestions returns the tip percentages stored by SetTipSuggestions,
+// or nil if there are none or the payload does not prompt for a tip.
+// Malformed entries are skipped.
+func (p *Payload) TipSuggestions() []string {
+	if p.TipOrConvenienceIndicator != TipIndicatorPromptConsumer {
+		return nil
+	}
+	var percents []string
+	for _, sf := range p.extensionFields(TipSuggestionsGUID) {
+		if validTipPercent(sf.Value) {
+			percents = append(percents, sf.Value)
+		}
+	}
+	return percents
+}
+
+// validTipPercent reports whether s is a decimal number above 0 and at
+// most 100.
+func validTipPercent(s string) bool {
+	r, ok := new(big.Rat).SetString(s)
+	return ok && isStrictAmount(s) && r.Sign() > 0 && r.Cmp(big.NewRat(100, 1)) <= 0
+}
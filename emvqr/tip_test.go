@@ -0,0 +1,59 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTipSuggestions(t *testing.T) {
+	p := basePayload()
+	if err := p.SetTipSuggestions("10"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("SetTipSuggestions() without a tip prompt error = %v, want ErrInvalidFormat", err)
+	}
+	p.SetPromptForTip()
+	if err := p.SetTipSuggestions("10", "12.5", "15"); err != nil {
+		t.Fatalf("SetTipSuggestions() error: %v", err)
+	}
+
+	raw := mustEncode(t, p)
+	if !strings.Contains(raw, "0020"+TipSuggestionsGUID+"01021002041"+"2.50302"+"15") {
+		t.Errorf("Encode() = %q, want the tip template", raw)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	assertEqual(t, "TipSuggestions", "10 12.5 15", strings.Join(decoded.TipSuggestions(), " "))
+
+	if err := decoded.SetTipSuggestions("18", "20"); err != nil {
+		t.Fatalf("second SetTipSuggestions() error: %v", err)
+	}
+	assertEqual(t, "replaced", "18 20", strings.Join(decoded.TipSuggestions(), " "))
+	assertEqual(t, "template ID", "80", decoded.UnreservedTemplates[0].ID)
+
+	if err := decoded.SetTipSuggestions(); err != nil || len(decoded.UnreservedTemplates) != 0 {
+		t.Errorf("SetTipSuggestions() to clear: error %v, templates %v", err, decoded.UnreservedTemplates)
+	}
+}
+
+func TestSetTipSuggestions_Errors(t *testing.T) {
+	p := basePayload()
+	p.SetPromptForTip()
+	for _, percents := range [][]string{
+		{"0"}, {"101"}, {"-5"}, {"10%"}, {".5"},
+		{"5", "10", "15", "20", "25", "30"},
+	} {
+		if err := p.SetTipSuggestions(percents...); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("SetTipSuggestions(%q) error = %v, want ErrInvalidFormat", percents, err)
+		}
+	}
+	if len(p.UnreservedTemplates) != 0 {
+		t.Errorf("failed SetTipSuggestions() added %v", p.UnreservedTemplates)
+	}
+	p.SetFixedConvenienceFee("1")
+	p.UnreservedTemplates = []UnreservedTemplate{{ID: "80", GloballyUniqueID: TipSuggestionsGUID, SubFields: []DataObject{{ID: "01", Value: "10"}}}}
+	if got := p.TipSuggestions(); got != nil {
+		t.Errorf("TipSuggestions() with a fixed fee = %q, want nil", got)
+	}
+}
@@ -14,55 +14,86 @@ type tlvObject struct {
 // parseTLV splits a raw string into a sequence of TLV data objects.
 // Each object is: 2-char ID + 2-char decimal length + <length> chars value.
 func parseTLV(s string) ([]tlvObject, error) {
-	var objects []tlvObject
+	return appendTLVObjects(nil, s)
+}
+
+// appendTLVObjects parses s like parseTLV and appends its objects to dst.
+// The values are slices of s, not copies. Decoders pass a stack-allocated
+// dst, so that parsing a template does not allocate.
+func appendTLVObjects(dst []tlvObject, s string) ([]tlvObject, error) {
 	for len(s) > 0 {
 		if len(s) < 4 {
 			return nil, fmt.Errorf("%w: expected at least 4 chars, got %d", ErrInvalidTLV, len(s))
 		}
 		id := s[0:2]
-		lenStr := s[2:4]
-		length, err := strconv.Atoi(lenStr)
-		if err != nil {
-			return nil, fmt.Errorf("%w: non-numeric length %q for ID %s", ErrInvalidTLV, lenStr, id)
+		length, ok := parseTLVLength(s[2], s[3])
+		if !ok {
+			return nil, fmt.Errorf("%w: non-numeric length %q for ID %s", ErrInvalidTLV, s[2:4], id)
 		}
 		if len(s) < 4+length {
 			return nil, fmt.Errorf("%w: declared length %d for ID %s exceeds remaining data (%d chars)", ErrInvalidTLV, length, id, len(s)-4)
 		}
-		value := s[4 : 4+length]
-		objects = append(objects, tlvObject{id: id, value: value})
+		dst = append(dst, tlvObject{id: id, value: s[4 : 4+length]})
 		s = s[4+length:]
 	}
-	return objects, nil
+	return dst, nil
+}
+
+// parseTLVLength parses a 2-digit decimal length field. Unlike strconv.Atoi
+// it rejects signs, as a length is always two digits.
+func parseTLVLength(hi, lo byte) (int, bool) {
+	if hi < '0' || hi > '9' || lo < '0' || lo > '9' {
+		return 0, false
+	}
+	return int(hi-'0')*10 + int(lo-'0'), true
 }
 
 // encodeTLV encodes a single ID+value pair into TLV format.
 // Returns an error if the value length exceeds 99 (the maximum representable
 // in a 2-digit decimal length field).
 func encodeTLV(id, value string) (string, error) {
-	if len(value) > 99 {
-		return "", fmt.Errorf("emvqr: value for ID %s is %d chars, exceeds maximum of 99", id, len(value))
+	buf, err := appendTLV(make([]byte, 0, 4+len(value)), id, value)
+	if err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("%s%02d%s", id, len(value), value), nil
+	return string(buf), nil
 }
 
-// mustEncodeTLV is a helper that panics on encoding errors (for use with
-// values that are already validated).
-func mustEncodeTLV(id, value string) string {
-	s, err := encodeTLV(id, value)
-	if err != nil {
-		panic(err)
+// appendTLV appends the TLV encoding of an ID+value pair to buf, failing like
+// encodeTLV.
+func appendTLV(buf []byte, id, value string) ([]byte, error) {
+	if len(value) > 99 {
+		return nil, tlvLengthError(id, len(value))
 	}
-	return s
+	buf = append(buf, id...)
+	buf = append(buf, byte('0'+len(value)/10), byte('0'+len(value)%10))
+	return append(buf, value...), nil
+}
+
+// tlvLengthError reports a value of n bytes, too long for a TLV length field.
+func tlvLengthError(id string, n int) error {
+	return newError(CodeInvalidFormat,
+		fmt.Errorf("%w: value for ID %s is %d chars, exceeds maximum of 99", ErrFieldTooLong, id, n),
+		"tag", id, "length", strconv.Itoa(n), "max", "99")
 }
 
 // convertTLVToDataObjects converts a slice of parsed tlvObject to DataObject.
 // Used to populate SubFields in MerchantIdentifier and other template structures.
-// Empty values are skipped as they are invalid per EMV QRCPS specification.
-func convertTLVToDataObjects(objects []tlvObject) []DataObject {
-	result := make([]DataObject, 0, len(objects))
+// Empty values, which EMV QRCPS does not allow but real-world payloads
+// carry, are kept unless dropEmpty is set.
+func convertTLVToDataObjects(objects []tlvObject, dropEmpty bool) []DataObject {
+	n := 0
+	for _, obj := range objects {
+		if obj.value != "" || !dropEmpty {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	result := make([]DataObject, 0, n)
 	for _, obj := range objects {
-		// Skip empty values as they are not valid per spec
-		if obj.value == "" {
+		if obj.value == "" && dropEmpty {
 			continue
 		}
 		result = append(result, DataObject{ID: obj.id, Value: obj.value})
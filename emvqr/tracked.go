@@ -0,0 +1,54 @@
+package emvqr
+
+// Tracker records which fields of a payload were modified after it was
+// wrapped with Tracked, for minimal-diff re-issuance and for audit logs of
+// what an operator edited before re-printing a QR.
+//
+// Fields are compared by their Flatten paths, so edits made directly
+// through the payload pointer are seen. The CRC is not tracked: it is
+// recomputed on encode.
+type Tracker struct {
+	// Payload is the tracked payload, to be edited in place.
+	Payload *Payload
+
+	base map[string]string
+}
+
+// Tracked starts tracking changes to p from its current state, typically
+// just after Decode.
+func Tracked(p *Payload) *Tracker {
+	t := &Tracker{Payload: p}
+	t.Reset()
+	return t
+}
+
+// Reset makes the payload's current state the baseline, e.g. after the
+// edited payload has been re-issued.
+func (t *Tracker) Reset() {
+	t.base = diffable(t.Payload)
+}
+
+// Modified returns the sorted tag paths, as produced by Flatten, of the
+// fields that were set, changed or cleared since tracking began.
+func (t *Tracker) Modified() []string {
+	changes := t.Changes()
+	paths := make([]string, len(changes))
+	for i, c := range changes {
+		paths[i] = c.Path
+	}
+	return paths
+}
+
+// Changes returns the old and new values of the modified fields, sorted by
+// tag path.
+func (t *Tracker) Changes() []FieldChange {
+	return diffFields(t.base, diffable(t.Payload))
+}
+
+// IsModified reports whether the field at path changed since tracking began.
+func (t *Tracker) IsModified(path string) bool {
+	cur := diffable(t.Payload)
+	old, had := t.base[path]
+	v, has := cur[path]
+	return had != has || old != v
+}
@@ -0,0 +1,50 @@
+package emvqr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTracked(t *testing.T) {
+	p := mustDecode(t, realWorldBharatQRPayload)
+	tr := Tracked(p)
+	if got := tr.Modified(); len(got) != 0 {
+		t.Fatalf("Modified() after Tracked = %v, want none", got)
+	}
+
+	p.MerchantName = "NEW NAME"
+	p.TransactionAmount = "10.00"
+	p.CRC = ""
+	if p.AdditionalData == nil {
+		p.AdditionalData = &AdditionalDataField{}
+	}
+	p.AdditionalData.StoreLabel = "S1"
+
+	want := []string{IDAdditionalDataFieldTemplate + "." + ADFStoreLabel, IDTransactionAmount, IDMerchantName}
+	slices.Sort(want)
+	if got := tr.Modified(); !slices.Equal(got, want) {
+		t.Errorf("Modified() = %v, want %v", got, want)
+	}
+	if !tr.IsModified(IDMerchantName) || tr.IsModified(IDMerchantCity) {
+		t.Error("IsModified: want 59 modified and 60 not")
+	}
+
+	t.Run("cleared field", func(t *testing.T) {
+		q := mustDecode(t, realWorldBharatQRPayload)
+		tr := Tracked(q)
+		city := q.MerchantCity
+		q.MerchantCity = ""
+		if !slices.Equal(tr.Modified(), []string{IDMerchantCity}) {
+			t.Errorf("Modified() = %v, want [60]", tr.Modified())
+		}
+		q.MerchantCity = city
+		if got := tr.Modified(); len(got) != 0 {
+			t.Errorf("Modified() after restoring = %v, want none", got)
+		}
+	})
+
+	tr.Reset()
+	if got := tr.Modified(); len(got) != 0 {
+		t.Errorf("Modified() after Reset = %v, want none", got)
+	}
+}
@@ -0,0 +1,88 @@
+package emvqr
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Merchant Category Codes of transit operators.
+const (
+	MCCCommuterTransport = "4111" // local and suburban commuter transport, such as metro and bus
+	MCCPassengerRailways = "4112" // passenger railways
+)
+
+// transitMCCs are the Merchant Category Codes ValidateTransit accepts.
+var transitMCCs = []string{MCCCommuterTransport, MCCPassengerRailways}
+
+// DefaultTransitMaxLength is the longest encoded transit payload, in
+// characters, ValidateTransit accepts by default: the capacity of a
+// version 8 QR Code symbol (49×49 modules) at error correction level M,
+// which the small camera windows of turnstile scanners read reliably.
+const DefaultTransitMaxLength = 152
+
+// TransitOptions controls ValidateTransit.
+type TransitOptions struct {
+	// MaxLength is the longest encoded payload accepted, in characters.
+	// Zero means DefaultTransitMaxLength.
+	MaxLength int
+}
+
+// NewTransitGatePayload returns a static QR for the fare gate or turnstile
+// gate, such as "GATE-04", built from base, which holds the operator's
+// merchant account, name, MCC and city; base is not modified. The QR
+// carries no amount, since the fare is known only at the exit, and names
+// the gate in the Terminal Label (Tag 62.07). The result passes
+// ValidateTransit with the default options, or an error is returned.
+func NewTransitGatePayload(base *Payload, gate string) (*Payload, error) {
+	p, err := Merge(base, &Payload{})
+	if err != nil {
+		return nil, err
+	}
+	p.PointOfInitiationMethod = POIStaticQR
+	p.TransactionAmount = ""
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.TerminalLabel = gate })
+	if err := ValidateTransit(p, TransitOptions{}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ValidateTransit checks the rules of a transit QR: the payload must
+// carry the Merchant Category Code (Tag 52) of a transit operator,
+// MCCCommuterTransport or MCCPassengerRailways, name its gate or ticket
+// machine in the Terminal Label (Tag 62.07), leave out the Transaction
+// Amount (Tag 54) if it is a static gate QR, and encode to at most
+// opts.MaxLength characters. It returns every violation, joined with
+// errors.Join, each wrapping ErrSchemeViolation with its tag, or the
+// length and limit, in ErrorParams; nil if there are none. A payload that
+// cannot be encoded is reported with the error of Encode.
+func ValidateTransit(p *Payload, opts TransitOptions) error {
+	var errs []error
+	fail := func(params []string, format string, args ...any) {
+		errs = append(errs, newError(CodeInvalidFormat,
+			fmt.Errorf("%w: transit: "+format, append([]any{ErrSchemeViolation}, args...)...), params...))
+	}
+	if !slices.Contains(transitMCCs, p.MerchantCategoryCode) {
+		fail([]string{"tag", IDMerchantCategoryCode}, "Tag 52 MCC must be one of %v, got %q", transitMCCs, p.MerchantCategoryCode)
+	}
+	if a := p.AdditionalData; a == nil || a.TerminalLabel == "" || a.TerminalLabel == PromptValue {
+		fail([]string{"tag", IDAdditionalDataFieldTemplate + "." + ADFTerminalLabel}, "Tag 62.07 Terminal Label must name the gate")
+	}
+	if p.PointOfInitiationMethod == POIStaticQR && p.TransactionAmount != "" {
+		fail([]string{"tag", IDTransactionAmount}, "static gate QR must not carry a Tag 54 amount, got %q", p.TransactionAmount)
+	}
+	maxLength := opts.MaxLength
+	if maxLength == 0 {
+		maxLength = DefaultTransitMaxLength
+	}
+	raw, err := Encode(p)
+	switch {
+	case err != nil:
+		errs = append(errs, err)
+	case len(raw) > maxLength:
+		fail([]string{"length", strconv.Itoa(len(raw)), "max", strconv.Itoa(maxLength)},
+			"payload is %d chars, over the budget of %d for turnstile scanners", len(raw), maxLength)
+	}
+	return joinErrors(errs)
+}
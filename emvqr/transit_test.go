@@ -0,0 +1,76 @@
+package emvqr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNewTransitGatePayload(t *testing.T) {
+	base := basePayload()
+	base.MerchantCategoryCode = MCCCommuterTransport
+	base.TransactionAmount = "2.75"
+	p, err := NewTransitGatePayload(base, "GATE-04")
+	if err != nil {
+		t.Fatalf("NewTransitGatePayload() error: %v", err)
+	}
+	assertEqual(t, "base amount", "2.75", base.TransactionAmount)
+
+	raw := mustEncode(t, p)
+	if len(raw) > DefaultTransitMaxLength {
+		t.Errorf("Encode() is %d chars, want at most %d", len(raw), DefaultTransitMaxLength)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if err := ValidateTransit(decoded, TransitOptions{}); err != nil {
+		t.Errorf("ValidateTransit() error: %v", err)
+	}
+	assertEqual(t, "TerminalLabel", "GATE-04", decoded.AdditionalData.TerminalLabel)
+	assertEqual(t, "TransactionAmount", "", decoded.TransactionAmount)
+
+	if _, err := NewTransitGatePayload(base, ""); !errors.Is(err, ErrSchemeViolation) {
+		t.Errorf("NewTransitGatePayload() without a gate error = %v, want ErrSchemeViolation", err)
+	}
+}
+
+func TestValidateTransit(t *testing.T) {
+	p := basePayload()
+	p.PointOfInitiationMethod = POIStaticQR
+	p.TransactionAmount = "2.75"
+	err := ValidateTransit(p, TransitOptions{})
+	if !errors.Is(err, ErrSchemeViolation) {
+		t.Fatalf("ValidateTransit() error = %v, want ErrSchemeViolation", err)
+	}
+	for _, tag := range []string{IDMerchantCategoryCode, "62.07", IDTransactionAmount} {
+		if !hasErrorTag(err, tag) {
+			t.Errorf("ValidateTransit() error %v has no violation for %s", err, tag)
+		}
+	}
+
+	// A ticket machine's dynamic QR may carry the fare.
+	p.MerchantCategoryCode = MCCPassengerRailways
+	p.PointOfInitiationMethod = POIDynamicQR
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.TerminalLabel = "TVM-12" })
+	if err := ValidateTransit(p, TransitOptions{}); err != nil {
+		t.Errorf("ValidateTransit() of a dynamic QR error: %v", err)
+	}
+}
+
+func TestValidateTransit_Length(t *testing.T) {
+	p := basePayload()
+	p.MerchantCategoryCode = MCCCommuterTransport
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.TerminalLabel = "GATE-04" })
+	raw := mustEncode(t, p)
+
+	err := ValidateTransit(p, TransitOptions{MaxLength: len(raw) - 1})
+	if !errors.Is(err, ErrSchemeViolation) || !strings.Contains(err.Error(), "over the budget") {
+		t.Fatalf("ValidateTransit() error = %v, want a length violation", err)
+	}
+	assertEqual(t, "max", strconv.Itoa(len(raw)-1), ErrorParams(err)["max"])
+	if err := ValidateTransit(p, TransitOptions{MaxLength: len(raw)}); err != nil {
+		t.Errorf("ValidateTransit() at the budget error: %v", err)
+	}
+}
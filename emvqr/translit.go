@@ -0,0 +1,216 @@
+package emvqr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxMerchantNameLen is the length limit of Tag 59 and Tag 64.01.
+const maxMerchantNameLen = 25
+
+// SetNativeMerchantName sets the merchant name from a name in any script.
+// Tag 59 gets an EMV-safe ASCII rendering (see Transliterate), truncated to
+// 25 characters at a word boundary; when that differs from name, the full
+// native name is kept in the Language Template under lang, e.g. "hi". The
+// template's city is kept if it is already in lang. Tag 64 remains subject
+// to its length limits when the payload is encoded.
+//
+// It returns ErrInvalidFormat if name has no characters that can be
+// rendered in ASCII, such as a name written only in Chinese; set
+// MerchantName directly in that case.
+func (p *Payload) SetNativeMerchantName(lang, name string) error {
+	ascii := TruncateName(Transliterate(name), maxMerchantNameLen)
+	if ascii == "" {
+		return newError(CodeInvalidFormat,
+			fmt.Errorf("%w: merchant name %q has no ASCII rendering", ErrInvalidFormat, name),
+			"tag", IDMerchantName)
+	}
+	p.MerchantName = ascii
+	if ascii == name {
+		return nil
+	}
+	lt := p.LanguageTemplate
+	if lt == nil || lt.LanguagePreference != lang {
+		lt = &LanguageTemplate{LanguagePreference: lang}
+		p.LanguageTemplate = lt
+	}
+	lt.MerchantName = name
+	return nil
+}
+
+// Transliterate renders s in printable ASCII for Tag 59 and other fields
+// restricted to the EMV Common Character Set. Latin letters lose their
+// diacritics ("Café Zürich" → "Cafe Zurich"), the Indic scripts (Devanagari,
+// Bengali, Gurmukhi, Gujarati, Odia, Tamil, Telugu, Kannada, Malayalam) are
+// romanised in the informal style used on Indian shop signs ("शर्मा किराना"
+// → "Sharma Kirana"), typographic punctuation is replaced by its ASCII
+// form, and anything else is dropped. Runs of spaces are collapsed.
+func Transliterate(s string) string {
+	var b strings.Builder
+	words := strings.FieldsFunc(s, unicode.IsSpace)
+	for _, w := range words {
+		t := transliterateWord(w)
+		if t == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t)
+	}
+	return b.String()
+}
+
+// transliterateWord transliterates a word without spaces.
+func transliterateWord(w string) string {
+	var out []byte
+	var block rune    // Unicode block of the current Indic run, or 0
+	inherent := false // an Indic consonant awaits its inherent vowel
+	start := 0        // offset in out where the current Indic run began
+	vowels := 0       // vowels written in the current Indic run
+	endRun := func() {
+		if block == 0 {
+			return
+		}
+		// The inherent vowel of a final consonant is silent in some
+		// scripts, unless it is the only vowel of the word.
+		if inherent && !(schwaDeleting[block] && vowels > 0) {
+			out = append(out, 'a')
+		}
+		if start < len(out) {
+			// Capitalise the romanised run like a proper noun.
+			out[start] = byte(unicode.ToUpper(rune(out[start])))
+		}
+		block, inherent = 0, false
+	}
+	for _, r := range w {
+		blk, ok := indicBlock(r)
+		if !ok {
+			endRun()
+			switch {
+			case r < utf8.RuneSelf && unicode.IsPrint(r):
+				out = append(out, byte(r))
+			case latinFold[r] != "":
+				out = append(out, latinFold[r]...)
+			}
+			continue
+		}
+		if blk != block {
+			endRun()
+			block, start, vowels = blk, len(out), 0
+		}
+		off := r - blk
+		switch {
+		case off >= 0x15 && off <= 0x39 || off >= 0x58 && off <= 0x5F || off >= 0x7A:
+			if inherent {
+				out = append(out, 'a')
+				vowels++
+			}
+			out = append(out, indicTable[off]...)
+			inherent = off < 0x7A // chillu letters have no vowel
+		case off >= 0x3E && off <= 0x4C || off == 0x62 || off == 0x63:
+			out = append(out, indicTable[off]...)
+			inherent = false
+			vowels++
+		case off == 0x4D: // virama
+			inherent = false
+		case off == 0x3C || off == 0x71: // nukta, addak
+		default:
+			if inherent {
+				out = append(out, 'a')
+				inherent = false
+				vowels++
+			}
+			if off >= 0x05 && off <= 0x14 {
+				vowels++
+			}
+			out = append(out, indicTable[off]...)
+		}
+	}
+	endRun()
+	return string(out)
+}
+
+// indicBlock returns the start of the Unicode block of an Indic letter.
+func indicBlock(r rune) (rune, bool) {
+	if r < 0x0900 || r > 0x0D7F {
+		return 0, false
+	}
+	return r &^ 0x7F, true
+}
+
+// schwaDeleting lists the scripts whose word-final inherent vowel is not
+// pronounced: "राम" is "Ram", not "Rama".
+var schwaDeleting = map[rune]bool{0x0900: true, 0x0980: true, 0x0A00: true, 0x0A80: true}
+
+// indicTable romanises the Indic blocks by offset within the block; the
+// scripts share the layout of ISCII.
+var indicTable = [0x80]string{
+	0x01: "n", 0x02: "n", 0x03: "h",
+	0x05: "a", 0x06: "a", 0x07: "i", 0x08: "ee", 0x09: "u", 0x0A: "oo", 0x0B: "ri", 0x0C: "l",
+	0x0D: "e", 0x0E: "e", 0x0F: "e", 0x10: "ai", 0x11: "o", 0x12: "o", 0x13: "o", 0x14: "au",
+	0x15: "k", 0x16: "kh", 0x17: "g", 0x18: "gh", 0x19: "n",
+	0x1A: "ch", 0x1B: "chh", 0x1C: "j", 0x1D: "jh", 0x1E: "ny",
+	0x1F: "t", 0x20: "th", 0x21: "d", 0x22: "dh", 0x23: "n",
+	0x24: "t", 0x25: "th", 0x26: "d", 0x27: "dh", 0x28: "n", 0x29: "n",
+	0x2A: "p", 0x2B: "ph", 0x2C: "b", 0x2D: "bh", 0x2E: "m",
+	0x2F: "y", 0x30: "r", 0x31: "r", 0x32: "l", 0x33: "l", 0x34: "zh", 0x35: "v",
+	0x36: "sh", 0x37: "sh", 0x38: "s", 0x39: "h",
+	0x3E: "a", 0x3F: "i", 0x40: "ee", 0x41: "u", 0x42: "oo", 0x43: "ri", 0x44: "ri",
+	0x45: "e", 0x46: "e", 0x47: "e", 0x48: "ai", 0x49: "o", 0x4A: "o", 0x4B: "o", 0x4C: "au",
+	0x4E: "t", 0x50: "om", 0x57: "au",
+	0x58: "q", 0x59: "kh", 0x5A: "gh", 0x5B: "z", 0x5C: "r", 0x5D: "rh", 0x5E: "f", 0x5F: "y",
+	0x60: "ri", 0x61: "l", 0x62: "ri", 0x63: "l", 0x64: ".", 0x65: ".",
+	0x66: "0", 0x67: "1", 0x68: "2", 0x69: "3", 0x6A: "4",
+	0x6B: "5", 0x6C: "6", 0x6D: "7", 0x6E: "8", 0x6F: "9", 0x70: "n",
+	0x7A: "n", 0x7B: "n", 0x7C: "r", 0x7D: "l", 0x7E: "l", 0x7F: "k",
+}
+
+// latinFold maps Latin letters with diacritics, ligatures and typographic
+// punctuation onto ASCII.
+var latinFold = func() map[rune]string {
+	m := make(map[rune]string)
+	for ascii, chars := range map[string]string{
+		"A": "ÀÁÂÃÄÅĀĂĄ", "a": "àáâãäåāăąạ", "AE": "Æ", "ae": "æ",
+		"C": "ÇĆĈĊČ", "c": "çćĉċč", "D": "ÐĎĐḌ", "d": "ðďđḍ",
+		"E": "ÈÉÊËĒĔĖĘĚ", "e": "èéêëēĕėęěẹ", "G": "ĜĞĠĢ", "g": "ĝğġģ",
+		"H": "ĤĦḤ", "h": "ĥħḥ", "I": "ÌÍÎÏĨĪĬĮİ", "i": "ìíîïĩīĭįıị",
+		"J": "Ĵ", "j": "ĵ", "K": "Ķ", "k": "ķ", "L": "ĹĻĽĿŁḶ", "l": "ĺļľŀłḷ",
+		"M": "Ṃ", "m": "ṃṁ", "N": "ÑŃŅŇṄṆ", "n": "ñńņňṅṇ",
+		"O": "ÒÓÔÕÖØŌŎŐ", "o": "òóôõöøōŏőọ", "OE": "Œ", "oe": "œ",
+		"R": "ŔŖŘṚ", "r": "ŕŗřṛ", "S": "ŚŜŞŠṢ", "s": "śŝşšṣ", "ss": "ß",
+		"T": "ŢŤŦṬ", "t": "ţťŧṭ", "TH": "Þ", "th": "þ",
+		"U": "ÙÚÛÜŨŪŬŮŰŲ", "u": "ùúûüũūŭůűųụ", "W": "Ŵ", "w": "ŵ",
+		"Y": "ÝŶŸ", "y": "ýÿŷ", "Z": "ŹŻŽ", "z": "źżž",
+		"'": "‘’‚′", "\"": "“”„″", "-": "‐‑‒–—―", "...": "…", "&": "＆",
+	} {
+		for _, r := range chars {
+			m[r] = ascii
+		}
+	}
+	return m
+}()
+
+// TruncateName shortens s to at most max characters, cutting at the last
+// word boundary when that keeps at least half of the allowed length, and
+// trims trailing spaces and punctuation. It returns "" for a max of zero or
+// less.
+func TruncateName(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	cut := string(runes[:max])
+	if i := strings.LastIndexByte(cut, ' '); i >= 0 && utf8.RuneCountInString(cut[:i]) >= max/2 &&
+		!unicode.IsSpace(runes[max]) {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) && r != ')' && r != '.'
+	})
+}
@@ -0,0 +1,97 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ABC Hammers", "ABC Hammers"},
+		{"Café  Zürich", "Cafe Zurich"},
+		{"Straße & Söhne", "Strasse & Sohne"},
+		{"Joe’s Diner – Downtown", "Joe's Diner - Downtown"},
+		{"शर्मा किराना", "Sharma Kirana"},
+		{"राम मिठाई भंडार", "Ram Mithaee Bhandar"},
+		{"गुप्ता जी", "Gupta Jee"},
+		{"ক", "Ka"},
+		{"ਸਿੰਘ ਢਾਬਾ", "Singh Dhaba"},
+		{"ஆனந்த் ஸ்டோர்", "Anant Stor"},
+		{"Shop २४", "Shop 24"},
+		{"北京饭店", ""},
+	}
+	for _, tc := range tests {
+		if got := Transliterate(tc.in); got != tc.want {
+			t.Errorf("Transliterate(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTruncateName(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"ABC Hammers", 25, "ABC Hammers"},
+		{"Sri Venkateshwara General Stores", 25, "Sri Venkateshwara General"},
+		{"Sri Venkateshwara Stores, Main Road", 25, "Sri Venkateshwara Stores"},
+		{"Supercalifragilisticexpialidocious", 25, "Supercalifragilisticexpia"},
+		{"A Supercalifragilisticexpialidocious", 25, "A Supercalifragilisticexp"},
+		{"ABC Hammers", 0, ""},
+		{"ABC Hammers", -1, ""},
+	}
+	for _, tc := range tests {
+		if got := TruncateName(tc.in, tc.max); got != tc.want {
+			t.Errorf("TruncateName(%q, %d) = %q, want %q", tc.in, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestSetNativeMerchantName(t *testing.T) {
+	p := basePayload()
+	p.SetLanguageTemplate("hi", "पुराना नाम", "दिल्ली")
+	if err := p.SetNativeMerchantName("hi", "शर्मा किराना"); err != nil {
+		t.Fatal(err)
+	}
+	if p.MerchantName != "Sharma Kirana" {
+		t.Errorf("MerchantName = %q", p.MerchantName)
+	}
+	lt := p.LanguageTemplate
+	if lt.LanguagePreference != "hi" || lt.MerchantName != "शर्मा किराना" || lt.MerchantCity != "दिल्ली" {
+		t.Errorf("LanguageTemplate = %+v", lt)
+	}
+	if _, err := Encode(p); err != nil {
+		t.Fatal(err)
+	}
+
+	// A long name is truncated in Tag 59 but kept whole in Tag 64.
+	if err := p.SetNativeMerchantName("mr", "श्री वेंकटेश्वर जनरल स्टोर्स"); err != nil {
+		t.Fatal(err)
+	}
+	if p.MerchantName != "Shree Venkateshvar" {
+		t.Errorf("MerchantName = %q", p.MerchantName)
+	}
+	lt = p.LanguageTemplate
+	if lt.LanguagePreference != "mr" || lt.MerchantName != "श्री वेंकटेश्वर जनरल स्टोर्स" || lt.MerchantCity != "" {
+		t.Errorf("LanguageTemplate = %+v", lt)
+	}
+
+	p = basePayload()
+	if err := p.SetNativeMerchantName("en", "ABC Hammers"); err != nil {
+		t.Fatal(err)
+	}
+	if p.LanguageTemplate != nil {
+		t.Errorf("ASCII name created a language template: %+v", p.LanguageTemplate)
+	}
+
+	err := p.SetNativeMerchantName("zh", "北京饭店")
+	if !errors.Is(err, ErrInvalidFormat) || ErrorCode(err) != CodeInvalidFormat {
+		t.Errorf("err = %v, want ErrInvalidFormat", err)
+	}
+	if p.MerchantName != "ABC Hammers" {
+		t.Errorf("failed call changed MerchantName to %q", p.MerchantName)
+	}
+}
@@ -0,0 +1,240 @@
+package emvqr
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// UPIURI returns the UPI deep link for a payload carrying a UPI VPA in
+// Tag 26, in the form defined by the NPCI UPI Linking Specification:
+//
+//	upi://pay?pa=shop@upi&pn=Sharma%20Stores&mc=5411&tr=ORD-1&am=550.00&cu=INR
+//
+// The parameters are taken from the payload: pa from Tag 26.01, pn from the
+// merchant name, mc from the MCC, tr from the Tag 27 transaction reference
+// (or the Tag 62.05 reference label), am from the transaction amount, mam
+// from the Tag 26.02 minimum amount, cu from the currency and tn from the
+// Tag 62.08 purpose, mode and purpose from SetUPIParams, followed by gstIn,
+// invoiceNo and invoiceDate when the payload has a GSTInvoice. Empty fields
+// are omitted. It returns ErrMissingRequired
+// if the payload has no VPA.
+func (p *Payload) UPIURI() (string, error) {
+	vpa := p.GetMerchantVPA()
+	if vpa == "" {
+		return "", fmt.Errorf("%w: Tag 26.01 (UPI VPA)", ErrMissingRequired)
+	}
+	return "upi://pay?" + encodeUPIParams(p.upiParams()), nil
+}
+
+// FromUPIURI builds a Bharat QR payload from a UPI deep link, the reverse of
+// UPIURI: pa becomes the Tag 26 VPA, tr the Tag 27 transaction reference
+// (or the Tag 62.05 reference label if it is not 4–35 characters), mode and
+// purpose are set with SetUPIParams and the GST parameters with
+// SetGSTInvoice. The Point of Initiation Method follows the mode (NFC, BLE
+// or QR) and is dynamic when the link has an amount; the country is IN.
+//
+// UPI links carry no merchant city, so MerchantCity must be set before the
+// payload is encoded, as must MerchantCategoryCode if mc is absent. It
+// returns ErrMissingRequired without pa and ErrInvalidFormat for invalid
+// mode, purpose or GST parameters.
+func FromUPIURI(uri string) (*Payload, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+	if !strings.EqualFold(u.Scheme, "upi") || !strings.EqualFold(u.Host, "pay") {
+		return nil, fmt.Errorf("%w: %q is not a upi://pay link", ErrInvalidFormat, uri)
+	}
+	q := u.Query()
+	vpa := q.Get("pa")
+	if vpa == "" {
+		return nil, fmt.Errorf("%w: UPI link parameter pa", ErrMissingRequired)
+	}
+
+	p := &Payload{
+		MerchantName:         q.Get("pn"),
+		MerchantCategoryCode: q.Get("mc"),
+		TransactionAmount:    q.Get("am"),
+		CountryCode:          "IN",
+	}
+	p.TransactionCurrency = currencyNumeric(q.Get("cu"))
+	if p.TransactionCurrency == "" {
+		p.TransactionCurrency = "356"
+	}
+	mode := UPIMode(q.Get("mode"))
+	method, kind := "1", "1"
+	switch mode {
+	case UPIModeNFC:
+		method = "3"
+	case UPIModeBLE:
+		method = "2"
+	}
+	if p.TransactionAmount != "" {
+		kind = "2"
+	}
+	p.PointOfInitiationMethod = POI(method + kind)
+
+	if err := p.SetUPIVPATemplate(RuPayRIDValue, vpa, q.Get("mam")); err != nil {
+		return nil, err
+	}
+	p.MerchantIdentifiers = append(p.MerchantIdentifiers, MerchantIdentifier{
+		ID:        IDUPIVPATemplate,
+		SubFields: []DataObject{{ID: MAIGloballyUniqueID, Value: RuPayRIDValue}, {ID: "01", Value: vpa}},
+	})
+	if ref := q.Get("tr"); ref != "" {
+		if err := p.SetUPIVPAReference(ref, ""); err != nil {
+			p.SetAdditionalData(func(a *AdditionalDataField) { a.ReferenceLabel = ref })
+		}
+	}
+	if tn := q.Get("tn"); tn != "" {
+		p.SetAdditionalData(func(a *AdditionalDataField) { a.PurposeOfTransaction = tn })
+	}
+	if err := p.SetUPIParams(mode, UPIPurpose(q.Get("purpose"))); err != nil {
+		return nil, err
+	}
+	if gstin := q.Get("gstIn"); gstin != "" {
+		inv := GSTInvoice{Number: q.Get("invoiceNo"), GSTIN: gstin}
+		if d := q.Get("invoiceDate"); d != "" {
+			if inv.Date, err = time.Parse(time.RFC3339, d); err != nil {
+				return nil, fmt.Errorf("%w: invoiceDate %q: %v", ErrInvalidFormat, d, err)
+			}
+		}
+		if err := p.SetGSTInvoice(inv); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// currencyNumeric returns the ISO 4217 numeric code of an alphabetic code
+// in currencyCodes, or "".
+func currencyNumeric(alpha string) string {
+	for numeric, a := range currencyCodes {
+		if strings.EqualFold(a, alpha) {
+			return numeric
+		}
+	}
+	return ""
+}
+
+// Wallet identifies a UPI app for DeepLink.
+type Wallet string
+
+// Wallets supported by DeepLink.
+const (
+	WalletUPI       Wallet = "upi"       // any UPI app: upi://pay?...
+	WalletPhonePe   Wallet = "phonepe"   // phonepe://pay?...
+	WalletPaytm     Wallet = "paytm"     // paytmmp://pay?...
+	WalletGooglePay Wallet = "googlepay" // Android intent URL for Google Pay
+	WalletBHIM      Wallet = "bhim"      // Android intent URL for BHIM
+)
+
+// Wallets lists the wallets supported by DeepLink, generic first.
+var Wallets = []Wallet{WalletUPI, WalletPhonePe, WalletPaytm, WalletGooglePay, WalletBHIM}
+
+// walletPackages are the Android package names used in intent URLs.
+var walletPackages = map[Wallet]string{
+	WalletGooglePay: "com.google.android.apps.nbu.paisa.user",
+	WalletBHIM:      "in.org.npci.upiapp",
+}
+
+// DeepLink returns a link that opens the payment in wallet w, for "open in
+// app" buttons next to a QR Code on checkout pages. The parameters are
+// those of UPIURI; PhonePe and Paytm take them on their own URL schemes,
+// while Google Pay and BHIM are addressed with an Android intent URL:
+//
+//	intent://pay?pa=shop@upi&...#Intent;scheme=upi;package=com.google.android.apps.nbu.paisa.user;end
+//
+// It returns ErrMissingRequired if the payload has no VPA.
+func (p *Payload) DeepLink(w Wallet) (string, error) {
+	uri, err := p.UPIURI()
+	if err != nil {
+		return "", err
+	}
+	query := strings.TrimPrefix(uri, "upi://pay?")
+	switch w {
+	case WalletUPI:
+		return uri, nil
+	case WalletPhonePe:
+		return "phonepe://pay?" + query, nil
+	case WalletPaytm:
+		return "paytmmp://pay?" + query, nil
+	case WalletGooglePay, WalletBHIM:
+		return "intent://pay?" + query + "#Intent;scheme=upi;package=" + walletPackages[w] + ";end", nil
+	}
+	return "", fmt.Errorf("emvqr: unknown wallet %q", w)
+}
+
+// DeepLinks returns the DeepLink of every wallet in Wallets.
+func (p *Payload) DeepLinks() (map[Wallet]string, error) {
+	links := make(map[Wallet]string, len(Wallets))
+	for _, w := range Wallets {
+		link, err := p.DeepLink(w)
+		if err != nil {
+			return nil, err
+		}
+		links[w] = link
+	}
+	return links, nil
+}
+
+// upiParam is a UPI deep link query parameter.
+type upiParam struct{ key, value string }
+
+// upiParams returns the UPI Linking Specification parameters of p in their
+// conventional order. Empty values are included.
+func (p *Payload) upiParams() []upiParam {
+	ref := p.GetTransactionReference()
+	var purpose string
+	if a := p.AdditionalData; a != nil {
+		if ref == "" && a.ReferenceLabel != PromptValue {
+			ref = a.ReferenceLabel
+		}
+		purpose = a.PurposeOfTransaction
+	}
+	currency := currencyCodes[p.TransactionCurrency]
+	if currency == "" {
+		currency = p.TransactionCurrency
+	}
+	params := []upiParam{
+		{"pa", p.GetMerchantVPA()},
+		{"pn", p.MerchantName},
+		{"mc", p.MerchantCategoryCode},
+		{"tr", ref},
+		{"am", p.TransactionAmount},
+		{"mam", p.GetMinimumAmount()},
+		{"cu", currency},
+		{"tn", purpose},
+		{"mode", string(p.GetUPIMode())},
+		{"purpose", string(p.GetUPIPurpose())},
+	}
+	if inv := p.GetGSTInvoice(); inv != nil {
+		var date string
+		if !inv.Date.IsZero() {
+			date = inv.Date.Format(time.RFC3339)
+		}
+		params = append(params,
+			upiParam{"gstIn", inv.GSTIN},
+			upiParam{"invoiceNo", inv.Number},
+			upiParam{"invoiceDate", date})
+	}
+	return params
+}
+
+// encodeUPIParams builds a query string from the non-empty params. Spaces
+// are encoded as %20 and '@' is left as is, since some UPI apps do not
+// decode "+" or "%40".
+func encodeUPIParams(params []upiParam) string {
+	var parts []string
+	for _, kv := range params {
+		if kv.value == "" {
+			continue
+		}
+		v := url.QueryEscape(kv.value)
+		v = strings.NewReplacer("+", "%20", "%40", "@").Replace(v)
+		parts = append(parts, kv.key+"="+v)
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,93 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUPIURI(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	got, err := p.UPIURI()
+	if err != nil {
+		t.Fatalf("UPIURI() error: %v", err)
+	}
+	want := "upi://pay?pa=SBIPMOPAD.02PL00000644432-21503961@SBIPAY&pn=APRIL%20MOON%20RETAIL%20PRIVA" +
+		"&mc=5441&tr=52602091445452087569609&am=250.00&cu=INR"
+	if got != want {
+		t.Errorf("UPIURI() =\n  %s\nwant\n  %s", got, want)
+	}
+
+	if _, err := basePayload().UPIURI(); !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("UPIURI() without VPA error = %v, want ErrMissingRequired", err)
+	}
+}
+
+func TestDeepLink(t *testing.T) {
+	p := basePayload()
+	_ = p.SetUPIVPATemplate(RuPayRIDValue, "shop@upi", "")
+	p.TransactionAmount = "550.00"
+	p.TransactionCurrency = "356"
+	p.SetAdditionalData(func(a *AdditionalDataField) { a.PurposeOfTransaction = "Order #7 & co" })
+	const query = "pa=shop@upi&pn=ABC%20Hammers&mc=5251&am=550.00&cu=INR&tn=Order%20%237%20%26%20co"
+
+	links, err := p.DeepLinks()
+	if err != nil {
+		t.Fatalf("DeepLinks() error: %v", err)
+	}
+	want := map[Wallet]string{
+		WalletUPI:       "upi://pay?" + query,
+		WalletPhonePe:   "phonepe://pay?" + query,
+		WalletPaytm:     "paytmmp://pay?" + query,
+		WalletGooglePay: "intent://pay?" + query + "#Intent;scheme=upi;package=com.google.android.apps.nbu.paisa.user;end",
+		WalletBHIM:      "intent://pay?" + query + "#Intent;scheme=upi;package=in.org.npci.upiapp;end",
+	}
+	for w, link := range want {
+		if links[w] != link {
+			t.Errorf("DeepLink(%s) =\n  %s\nwant\n  %s", w, links[w], link)
+		}
+	}
+	if _, err := p.DeepLink("venmo"); err == nil {
+		t.Error("DeepLink(venmo) expected error, got nil")
+	}
+	if _, err := basePayload().DeepLinks(); !errors.Is(err, ErrMissingRequired) {
+		t.Errorf("DeepLinks() without VPA error = %v, want ErrMissingRequired", err)
+	}
+}
+
+func TestFromUPIURI(t *testing.T) {
+	const uri = "upi://pay?pa=shop@upi&pn=Sharma%20Stores&mc=5411&tr=ORD-1001&am=550.00&cu=INR" +
+		"&mode=03&purpose=00&gstIn=27AAPFU0939F1ZV&invoiceNo=INV-7" +
+		"&invoiceDate=2024-03-15T14%3A30%3A00%2B05%3A30"
+	p, err := FromUPIURI(uri)
+	if err != nil {
+		t.Fatalf("FromUPIURI() error: %v", err)
+	}
+	assertEqual(t, "VPA", "shop@upi", p.GetMerchantVPA())
+	assertEqual(t, "name", "Sharma Stores", p.MerchantName)
+	assertEqual(t, "POI", string(POIDynamicQR), string(p.PointOfInitiationMethod))
+	assertEqual(t, "currency", "356", p.TransactionCurrency)
+	assertEqual(t, "reference", "ORD-1001", p.GetTransactionReference())
+	assertEqual(t, "mode", "03", string(p.GetUPIMode()))
+	assertEqual(t, "invoice", "INV-7", p.GetGSTInvoice().Number)
+
+	p.MerchantCity = "Mumbai"
+	if _, err := EncodeWithOptions(p, EncodeOptions{SpecVersion: SpecVersion11}); err != nil {
+		t.Fatalf("Encode() error: %v", err)
+	}
+	back, err := p.UPIURI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back != uri {
+		t.Errorf("UPIURI() round trip =\n  %s\nwant\n  %s", back, uri)
+	}
+
+	for _, bad := range []string{"https://pay?pa=x@y", "upi://pay?pn=Shop", "upi://pay?pa=x@y&mode=11&purpose=00", "upi://pay?pa=x@y&gstIn=27AAPFU0939F1Z1&invoiceNo=1"} {
+		if _, err := FromUPIURI(bad); err == nil {
+			t.Errorf("FromUPIURI(%q): want error", bad)
+		}
+	}
+}
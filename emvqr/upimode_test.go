@@ -0,0 +1,84 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateUPIParams(t *testing.T) {
+	tests := []struct {
+		mode    UPIMode
+		purpose UPIPurpose
+		poi     POI
+		ok      bool
+	}{
+		{"", "", "", true},
+		{UPIModeBharatQR, UPIPurposeDefault, POIDynamicQR, true},
+		{UPIModeNFC, UPIPurposeTravel, POIStaticNFC, true},
+		{UPIModeQRMandate, UPIPurposeSEBI, POIDynamicQR, true},
+		{UPIModeIntent, UPIPurposeEducation, POIStaticQR, true},
+		{"99", "", "", false},
+		{"", "98", "", false},
+		{UPIModeNFC, "", POIStaticQR, false},
+		{UPIModeQR, "", POIDynamicBLE, false},
+		{UPIModeMandate, "", "", false},
+		{UPIModeQRMandate, UPIPurposeDefault, POIDynamicQR, false},
+	}
+	for _, tc := range tests {
+		err := ValidateUPIParams(tc.mode, tc.purpose, tc.poi)
+		if tc.ok && err != nil || !tc.ok && !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("ValidateUPIParams(%q, %q, %q) = %v, want ok=%v", tc.mode, tc.purpose, tc.poi, err, tc.ok)
+		}
+	}
+}
+
+func TestRegisterUPIPurpose(t *testing.T) {
+	const gold UPIPurpose = "97"
+	if err := ValidateUPIParams("", gold, ""); err == nil {
+		t.Fatal("unregistered purpose accepted")
+	}
+	if err := RegisterUPIPurpose(gold, "gold"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		upiMu.Lock()
+		delete(upiPurposes, gold)
+		upiMu.Unlock()
+	}()
+	if err := ValidateUPIParams("", gold, ""); err != nil {
+		t.Errorf("registered purpose rejected: %v", err)
+	}
+	assertEqual(t, "String", "gold", gold.String())
+	if err := RegisterUPIPurpose("G1", "bad"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("RegisterUPIPurpose(G1) = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestSetUPIParams(t *testing.T) {
+	p := basePayload()
+	p.PointOfInitiationMethod = POIDynamicQR
+	if err := p.SetUPIParams(UPIModeBharatQR, UPIPurposeInsurance); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := EncodeWithOptions(p, EncodeOptions{SpecVersion: SpecVersion11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, "mode", "03", string(decoded.GetUPIMode()))
+	assertEqual(t, "purpose", "07", string(decoded.GetUPIPurpose()))
+	assertEqual(t, "mode name", "Bharat QR", decoded.GetUPIMode().String())
+
+	if err := p.SetUPIParams(UPIModeNFC, ""); err == nil {
+		t.Error("NFC mode with a QR Point of Initiation accepted")
+	}
+	if err := p.SetUPIParams("", ""); err != nil {
+		t.Fatal(err)
+	}
+	if p.GetUPIMode() != "" || p.GetUPIPurpose() != "" {
+		t.Errorf("SetUPIParams(\"\", \"\") left mode %q, purpose %q", p.GetUPIMode(), p.GetUPIPurpose())
+	}
+}
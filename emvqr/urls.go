@@ -0,0 +1,148 @@
+package emvqr
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URLSanitized is the Correction kind reported by SanitizeURLs.
+const URLSanitized = "url-sanitized"
+
+// unsafeURLChars are the printable characters RFC 1738 calls unsafe in a
+// URL, which some scanner apps and link handlers mangle.
+const unsafeURLChars = ` <>"{}|\^` + "`"
+
+// isCommonChar reports whether c is in the EMV Common Character Set, the
+// printable ASCII characters 0x20–0x7E.
+func isCommonChar(c byte) bool {
+	return c >= 0x20 && c <= 0x7e
+}
+
+// ValidateURL checks that s, a URL for Tag 27.02 or an Unreserved Template,
+// uses only characters of the EMV Common Character Set and none that are
+// unsafe in a URL, such as spaces or '<', and that each '%' starts an
+// escape such as "%20". The error wraps ErrInvalidFormat; SanitizeURL fixes
+// most such URLs.
+func ValidateURL(s string) error {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isCommonChar(c) || strings.IndexByte(unsafeURLChars, c) >= 0 {
+			return fmt.Errorf("%w: URL %q contains %q at byte %d", ErrInvalidFormat, s, c, i)
+		}
+		if c == '%' && (i+2 >= len(s) || !isHexDigit(s[i+1]) || !isHexDigit(s[i+2])) {
+			return fmt.Errorf("%w: URL %q has an invalid escape at byte %d", ErrInvalidFormat, s, i)
+		}
+	}
+	return nil
+}
+
+// isHexDigit reports whether c is a hexadecimal digit.
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// SanitizeURL returns s fit for a QR payload: surrounding whitespace is
+// trimmed, and control characters, non-ASCII characters and characters
+// unsafe in a URL are removed, or percent-encoded as UTF-8 if percentEncode
+// is set, e.g. "https://shop.in/café menu" becomes
+// "https://shop.in/caf%C3%A9%20menu". Existing escapes such as "%20" are
+// kept. It returns an error wrapping ErrInvalidFormat when nothing remains
+// or the result does not parse as a URL.
+func SanitizeURL(s string, percentEncode bool) (string, error) {
+	const hex = "0123456789ABCDEF"
+	s = strings.TrimSpace(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isCommonChar(c) && strings.IndexByte(unsafeURLChars, c) < 0:
+			b.WriteByte(c)
+		case percentEncode:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0f])
+		}
+	}
+	out := b.String()
+	if out == "" {
+		return "", fmt.Errorf("%w: URL %q is empty once sanitized", ErrInvalidFormat, s)
+	}
+	if _, err := url.Parse(out); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidFormat, err)
+	}
+	return out, nil
+}
+
+// looksLikeURL reports whether s has a URL scheme, such as "https://".
+func looksLikeURL(s string) bool {
+	i := strings.Index(s, "://")
+	return i > 0 && !strings.ContainsAny(s[:i], " /")
+}
+
+// eachURL calls fn with the tag and sub-field ID of the Tag 27.02 Reference
+// URL and of each Unreserved Template sub-field that holds a URL.
+func (p *Payload) eachURL(fn func(parent, id string, value *string)) {
+	if r := p.UPITransactionRef; r != nil && r.ReferenceURL != "" {
+		fn(IDUPIVPAReference, UPIVPARefURL, &r.ReferenceURL)
+	}
+	for i := range p.UnreservedTemplates {
+		ut := &p.UnreservedTemplates[i]
+		for j := range ut.SubFields {
+			if sf := &ut.SubFields[j]; looksLikeURL(sf.Value) {
+				fn(ut.ID, sf.ID, &sf.Value)
+			}
+		}
+	}
+}
+
+// urlErrors returns an error for each URL of p that ValidateURL rejects.
+func urlErrors(p *Payload) []error {
+	var errs []error
+	p.eachURL(func(parent, id string, value *string) {
+		if err := ValidateURL(*value); err != nil {
+			path := parent + "." + id
+			errs = append(errs, newError(CodeInvalidFormat, fmt.Errorf("emvqr: tag %s: %w", path, err),
+				"tag", path, "value", *value))
+		}
+	})
+	return errs
+}
+
+// SanitizeURLs applies SanitizeURL to the Tag 27.02 Reference URL and to
+// the Unreserved Template sub-fields holding URLs, such as links handed over
+// by marketing, and returns the changes made. Encode rejects these URLs
+// unless ValidateURL accepts them. If a URL cannot be sanitized, the error
+// names its tag and the payload is left unchanged.
+func (p *Payload) SanitizeURLs(percentEncode bool) ([]Correction, error) {
+	type change struct {
+		path  string
+		value *string
+		clean string
+	}
+	var changes []change
+	var err error
+	p.eachURL(func(parent, id string, value *string) {
+		if err != nil {
+			return
+		}
+		path := parent + "." + id
+		clean, serr := SanitizeURL(*value, percentEncode)
+		if serr != nil {
+			err = newError(CodeInvalidFormat, fmt.Errorf("emvqr: tag %s: %w", path, serr), "tag", path, "value", *value)
+		}
+		changes = append(changes, change{path, value, clean})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var corrections []Correction
+	for _, c := range changes {
+		if c.clean != *c.value {
+			corrections = append(corrections, Correction{URLSanitized, c.path, *c.value, c.clean})
+			*c.value = c.clean
+		}
+	}
+	return corrections, nil
+}
@@ -0,0 +1,76 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSanitizeURL(t *testing.T) {
+	tests := []struct {
+		in            string
+		percentEncode bool
+		want          string
+	}{
+		{" https://shop.in/menu\n", false, "https://shop.in/menu"},
+		{"https://shop.in/café menu", false, "https://shop.in/cafmenu"},
+		{"https://shop.in/café menu", true, "https://shop.in/caf%C3%A9%20menu"},
+		{"https://shop.in/a%20b?q=<x>", false, "https://shop.in/a%20b?q=x"},
+		{"https://shop.in/\x00\x1b🎉offer", true, "https://shop.in/%00%1B%F0%9F%8E%89offer"},
+	}
+	for _, tt := range tests {
+		got, err := SanitizeURL(tt.in, tt.percentEncode)
+		if err != nil {
+			t.Errorf("SanitizeURL(%q) error: %v", tt.in, err)
+			continue
+		}
+		assertEqual(t, tt.in, tt.want, got)
+		if err := ValidateURL(got); err != nil {
+			t.Errorf("ValidateURL(%q) = %v", got, err)
+		}
+	}
+	for _, in := range []string{" \t", "🎉", "https://shop.in/%zz"} {
+		if _, err := SanitizeURL(in, false); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("SanitizeURL(%q) error = %v, want ErrInvalidFormat", in, err)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, bad := range []string{"https://shop.in/a b", "https://shop.in/é", "https://shop.in/\t", "https://shop.in/%2", "https://shop.in/{x}"} {
+		if err := ValidateURL(bad); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("ValidateURL(%q) = %v, want ErrInvalidFormat", bad, err)
+		}
+	}
+	if err := ValidateURL("https://shop.in/a%20b?x=1&y=2#top"); err != nil {
+		t.Errorf("ValidateURL() = %v", err)
+	}
+}
+
+func TestPayload_SanitizeURLs(t *testing.T) {
+	p := basePayload()
+	p.UPITransactionRef = &UPIVPAReference{RuPayRID: RuPayRIDValue, TransactionRef: "INV-1", ReferenceURL: "https://x.io/é"}
+	p.UnreservedTemplates = []UnreservedTemplate{{
+		ID: "80", GloballyUniqueID: "com.example",
+		SubFields: []DataObject{{ID: "01", Value: "not a url é"}, {ID: "02", Value: "https://example.com/sale now"}},
+	}}
+
+	_, err := Encode(p)
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("Encode() error = %v, want ErrInvalidFormat", err)
+	}
+	assertEqual(t, "tag", "27.02", ErrorParams(err)["tag"])
+
+	changes, err := p.SanitizeURLs(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Path != "27.02" || changes[1].Path != "80.02" || changes[0].Kind != URLSanitized {
+		t.Fatalf("SanitizeURLs() = %v", changes)
+	}
+	assertEqual(t, "27.02", "https://x.io/%C3%A9", p.UPITransactionRef.ReferenceURL)
+	assertEqual(t, "80.02", "https://example.com/sale%20now", p.UnreservedTemplates[0].SubFields[1].Value)
+	assertEqual(t, "80.01", "not a url é", p.UnreservedTemplates[0].SubFields[0].Value)
+	if _, err := Encode(p); err != nil {
+		t.Errorf("Encode() after SanitizeURLs: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package emvqr
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SpecVersion selects the EMV QRCPS Merchant-Presented Mode revision used by
+// the encoder and decoder. The zero value is SpecVersion10.
+type SpecVersion int
+
+const (
+	// SpecVersion10 is EMV QRCPS MPM v1.0 (July 2017).
+	SpecVersion10 SpecVersion = iota
+	// SpecVersion11 is EMV QRCPS MPM v1.1. It adds the Merchant Tax ID and
+	// Merchant Channel sub-fields of the Additional Data Field Template and
+	// assigns sub-IDs "50"–"99" to payment system specific templates.
+	SpecVersion11
+)
+
+// String returns the dotted version number, e.g. "1.1".
+func (v SpecVersion) String() string {
+	switch v {
+	case SpecVersion10:
+		return "1.0"
+	case SpecVersion11:
+		return "1.1"
+	default:
+		return fmt.Sprintf("SpecVersion(%d)", int(v))
+	}
+}
+
+// SupportsFormatIndicator reports whether the Payload Format Indicator
+// (ID "00") value is defined by this spec version. Both v1.0 and v1.1 use "01".
+func (v SpecVersion) SupportsFormatIndicator(pfi string) bool {
+	switch v {
+	case SpecVersion10, SpecVersion11:
+		return pfi == PayloadFormatIndicatorValue
+	default:
+		return false
+	}
+}
+
+// valid reports whether v is a known spec version.
+func (v SpecVersion) valid() bool {
+	return v == SpecVersion10 || v == SpecVersion11
+}
+
+// isPaymentSystemTemplateID reports whether an Additional Data Field sub-ID
+// falls in "50"–"99", reserved for payment system specific templates in v1.1.
+func isPaymentSystemTemplateID(id string) bool {
+	return len(id) == 2 && id >= "50" && id <= "99"
+}
+
+// checkFormatIndicator validates the decoded Payload Format Indicator against
+// the selected spec version. Tag 00 is mandatory; values listed in accept
+// are allowed in addition to those the version defines.
+func checkFormatIndicator(p *Payload, v SpecVersion, accept []string) error {
+	if !v.valid() {
+		return unknownSpecVersion(v)
+	}
+	if p.PayloadFormatIndicator == "" {
+		return newError(CodeMissingField,
+			fmt.Errorf("%w: payload format indicator (ID %q)", ErrMissingRequired, IDPayloadFormatIndicator),
+			"tag", IDPayloadFormatIndicator)
+	}
+	if !v.SupportsFormatIndicator(p.PayloadFormatIndicator) && !slices.Contains(accept, p.PayloadFormatIndicator) {
+		return newError(CodeUnsupportedVersion,
+			fmt.Errorf("%w: payload format indicator %q is not defined by EMV QRCPS MPM v%s",
+				ErrUnsupportedVersion, p.PayloadFormatIndicator, v),
+			"tag", IDPayloadFormatIndicator, "value", p.PayloadFormatIndicator)
+	}
+	return nil
+}
+
+// unknownSpecVersion returns the error for a SpecVersion this package does
+// not define.
+func unknownSpecVersion(v SpecVersion) error {
+	return newError(CodeUnsupportedVersion,
+		fmt.Errorf("%w: unknown spec version %s", ErrUnsupportedVersion, v), "version", v.String())
+}
+
+// encodeFormatIndicator returns the Payload Format Indicator Encode writes:
+// opts.PayloadFormatIndicator, else p's, else "01". A value the spec version
+// does not define is only written when opts.AllowFutureFormatIndicator is
+// set, and it must be two digits either way.
+func encodeFormatIndicator(p *Payload, opts EncodeOptions) (string, error) {
+	pfi := PayloadFormatIndicatorValue
+	if opts.PayloadFormatIndicator != "" {
+		pfi = opts.PayloadFormatIndicator
+	} else if p.PayloadFormatIndicator != "" {
+		pfi = p.PayloadFormatIndicator
+	}
+	if len(pfi) != 2 || !isNumeric(pfi) {
+		return "", newError(CodeInvalidFormat,
+			fmt.Errorf("%w: payload format indicator %q must be two digits", ErrInvalidFormat, pfi),
+			"tag", IDPayloadFormatIndicator, "value", pfi)
+	}
+	if !opts.SpecVersion.SupportsFormatIndicator(pfi) && !opts.AllowFutureFormatIndicator {
+		return "", newError(CodeUnsupportedVersion,
+			fmt.Errorf("%w: payload format indicator %q is not defined by EMV QRCPS MPM v%s",
+				ErrUnsupportedVersion, pfi, opts.SpecVersion),
+			"tag", IDPayloadFormatIndicator, "value", pfi)
+	}
+	return pfi, nil
+}
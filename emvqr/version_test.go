@@ -0,0 +1,132 @@
+package emvqr
+
+import (
+	"errors"
+	"testing"
+)
+
+// -------------------------------------------------------------------------
+// Spec Version Tests
+// -------------------------------------------------------------------------
+
+func TestDecode_UnsupportedFormatIndicator(t *testing.T) {
+	p := basePayload()
+	p.PayloadFormatIndicator = "02"
+	encoded, err := EncodeWithOptions(p, EncodeOptions{AllowFutureFormatIndicator: true})
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error: %v", err)
+	}
+	if _, err := Decode(encoded); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+
+	decoded, err := DecodeWithOptions(encoded, DecodeOptions{AcceptFormatIndicators: []string{"02"}})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions() error: %v", err)
+	}
+	assertEqual(t, "PayloadFormatIndicator", "02", decoded.PayloadFormatIndicator)
+}
+
+func TestDecode_MissingFormatIndicator(t *testing.T) {
+	raw := baseRaw[len("000201"):]
+	raw = raw[:len(raw)-4] + ComputeCRC(raw[:len(raw)-4])
+	if _, err := Decode(raw); !errors.Is(err, ErrMissingRequired) {
+		t.Fatalf("expected ErrMissingRequired, got %v", err)
+	}
+}
+
+func TestEncode_FormatIndicatorPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		pfi     string
+		opts    EncodeOptions
+		wantErr error
+	}{
+		{"default", "", EncodeOptions{}, nil},
+		{"supported", "01", EncodeOptions{}, nil},
+		{"future on payload", "02", EncodeOptions{}, ErrUnsupportedVersion},
+		{"future in options", "", EncodeOptions{PayloadFormatIndicator: "02"}, ErrUnsupportedVersion},
+		{"future allowed", "", EncodeOptions{PayloadFormatIndicator: "02", AllowFutureFormatIndicator: true}, nil},
+		{"not digits", "0A", EncodeOptions{AllowFutureFormatIndicator: true}, ErrInvalidFormat},
+		{"wrong length", "1", EncodeOptions{AllowFutureFormatIndicator: true}, ErrInvalidFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := basePayload()
+			p.PayloadFormatIndicator = tt.pfi
+			_, err := EncodeWithOptions(p, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("EncodeWithOptions() error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EncodeWithOptions() error = %v, want %v", err, tt.wantErr)
+			}
+			assertEqual(t, "tag", IDPayloadFormatIndicator, ErrorParams(err)["tag"])
+		})
+	}
+}
+
+func TestRoundTrip_SpecVersion11_AdditionalData(t *testing.T) {
+	p := basePayload()
+	p.SetAdditionalData(func(adf *AdditionalDataField) {
+		adf.BillNumber = "INV001"
+		adf.MerchantTaxID = "27AAPFU0939F1ZV"
+		adf.MerchantChannel = "111"
+		adf.PaymentSystemTemplates = []UnreservedTemplate{
+			{ID: "50", GloballyUniqueID: "COM.EXAMPLE", SubFields: []DataObject{{ID: "01", Value: "X1"}}},
+		}
+	})
+
+	if _, err := Encode(p); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion encoding v1.1 fields as v1.0, got %v", err)
+	}
+	encoded, err := EncodeWithOptions(p, EncodeOptions{SpecVersion: SpecVersion11})
+	if err != nil {
+		t.Fatalf("EncodeWithOptions() error: %v", err)
+	}
+
+	decoded, err := DecodeWithOptions(encoded, DecodeOptions{SpecVersion: SpecVersion11})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions() error: %v", err)
+	}
+	adf := decoded.AdditionalData
+	assertEqual(t, "MerchantTaxID", "27AAPFU0939F1ZV", adf.MerchantTaxID)
+	assertEqual(t, "MerchantChannel", "111", adf.MerchantChannel)
+	if len(adf.PaymentSystemTemplates) != 1 {
+		t.Fatalf("expected 1 payment system template, got %d", len(adf.PaymentSystemTemplates))
+	}
+	assertEqual(t, "PST.GUID", "COM.EXAMPLE", adf.PaymentSystemTemplates[0].GloballyUniqueID)
+
+	// Under v1.0 the same sub-fields are preserved as RFU fields.
+	legacy, err := Decode(encoded)
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	if legacy.AdditionalData.MerchantTaxID != "" {
+		t.Error("expected MerchantTaxID to be empty under v1.0")
+	}
+	if len(legacy.AdditionalData.RFUFields) != 3 {
+		t.Errorf("expected 3 RFU fields under v1.0, got %d", len(legacy.AdditionalData.RFUFields))
+	}
+	reencoded, err := Encode(legacy)
+	if err != nil {
+		t.Fatalf("Encode() legacy error: %v", err)
+	}
+	assertEqual(t, "v1.0 re-encode", encoded, reencoded)
+}
+
+func TestEncode_InvalidMerchantChannel(t *testing.T) {
+	p := basePayload()
+	p.SetAdditionalData(func(adf *AdditionalDataField) { adf.MerchantChannel = "1" })
+	if _, err := EncodeWithOptions(p, EncodeOptions{SpecVersion: SpecVersion11}); err == nil {
+		t.Fatal("expected error for 1-char merchant channel, got nil")
+	}
+}
+
+func TestSpecVersion_String(t *testing.T) {
+	assertEqual(t, "v1.0", "1.0", SpecVersion10.String())
+	assertEqual(t, "v1.1", "1.1", SpecVersion11.String())
+}
@@ -0,0 +1,192 @@
+package emvqr
+
+import (
+	"errors"
+	"strconv"
+)
+
+// SkipTemplate is returned by a Walk callback, when visiting a template, to
+// skip its sub-fields. It is not returned as an error by Walk.
+var SkipTemplate = errors.New("emvqr: skip this template")
+
+// Walk visits every data object of p in the order Encode writes them,
+// descending into templates. fn receives the dotted tag path (as in
+// Flatten, e.g. "62.05"), the object's own ID ("05") and its value; a
+// template is visited with its encoded contents before its sub-fields.
+// Merchant account information templates ("29"–"51") are descended into
+// when their values parse as TLV. The CRC is visited last if p.CRC is set.
+//
+// Walk stops at the first error returned by fn and returns it, except for
+// SkipTemplate. Unlike Encode it does not require the mandatory fields, so
+// it can be used to inspect incomplete payloads.
+func Walk(p *Payload, fn func(path, id, value string) error) error {
+	return walkNodes("", p.tlvNodes(), fn)
+}
+
+// walkNodes calls fn for each node and, unless fn returns SkipTemplate, for
+// the children of templates.
+func walkNodes(prefix string, nodes []TLVNode, fn func(path, id, value string) error) error {
+	for _, n := range nodes {
+		value := n.Value
+		if n.IsTemplate() {
+			inner, err := EncodeTLVTree(n.Children)
+			if err != nil {
+				return &ParseError{ID: prefix + n.ID, Err: err}
+			}
+			value = inner
+		}
+		err := fn(prefix+n.ID, n.ID, value)
+		switch {
+		case errors.Is(err, SkipTemplate) && n.IsTemplate():
+			continue
+		case err != nil:
+			return err
+		}
+		if n.IsTemplate() {
+			if err := walkNodes(prefix+n.ID+".", n.Children, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tlvNodes returns the populated data objects of p as a tree in encoding
+// order. Empty fields are omitted, as Encode omits them.
+func (p *Payload) tlvNodes() []TLVNode {
+	var nodes []TLVNode
+	leaf := func(list *[]TLVNode, id, value string) {
+		if value != "" {
+			*list = append(*list, TLVNode{ID: id, Value: value})
+		}
+	}
+	template := func(id string, children []TLVNode) {
+		if len(children) > 0 {
+			nodes = append(nodes, TLVNode{ID: id, Children: children})
+		}
+	}
+	objects := func(list []DataObject) []TLVNode {
+		var out []TLVNode
+		for _, d := range list {
+			leaf(&out, d.ID, d.Value)
+		}
+		return out
+	}
+	unreserved := func(ut UnreservedTemplate) TLVNode {
+		var children []TLVNode
+		leaf(&children, MAIGloballyUniqueID, ut.GloballyUniqueID)
+		return TLVNode{ID: ut.ID, Children: append(children, objects(ut.SubFields)...)}
+	}
+
+	pfi := p.PayloadFormatIndicator
+	if pfi == "" {
+		pfi = "01"
+	}
+	leaf(&nodes, IDPayloadFormatIndicator, pfi)
+	leaf(&nodes, IDPointOfInitiationMethod, string(p.PointOfInitiationMethod))
+	for _, mi := range p.MerchantIdentifiers {
+		switch mi.ID {
+		case IDUPIVPATemplate, IDUPIVPAReference, IDAadhaarTemplate:
+			continue // encoded from the typed fields below
+		}
+		if n, err := strconv.Atoi(mi.ID); err == nil && n >= 29 {
+			if subs, err := parseTLV(mi.Value); err == nil && len(subs) > 0 {
+				template(mi.ID, objects(convertTLVToDataObjects(subs, false)))
+				continue
+			}
+		}
+		leaf(&nodes, mi.ID, mi.Value)
+	}
+	leaf(&nodes, IDMerchantCategoryCode, p.MerchantCategoryCode)
+	leaf(&nodes, IDTransactionCurrency, p.TransactionCurrency)
+	leaf(&nodes, IDTransactionAmount, p.TransactionAmount)
+	leaf(&nodes, IDTipOrConvenienceIndicator, p.TipOrConvenienceIndicator)
+	switch p.TipOrConvenienceIndicator {
+	case TipIndicatorFixedConvenienceFee:
+		leaf(&nodes, IDValueConvenienceFeeFixed, p.ValueConvenienceFeeFixed)
+	case TipIndicatorPercentageFee:
+		leaf(&nodes, IDValueConvenienceFeePercent, p.ValueConvenienceFeePercent)
+	}
+	leaf(&nodes, IDCountryCode, p.CountryCode)
+	leaf(&nodes, IDMerchantName, p.MerchantName)
+	leaf(&nodes, IDMerchantCity, p.MerchantCity)
+	leaf(&nodes, IDPostalCode, p.PostalCode)
+
+	// typed appends a template encoded from typed fields, with the empty
+	// sub-fields kept from decoding, as appendTypedTemplate does.
+	typed := func(id string, fields ...typedSubField) {
+		var children []TLVNode
+		decoded := p.decodedSubFields(id)
+		j := 0
+		for _, f := range fields {
+			for ; j < len(decoded) && decoded[j].ID <= f.id; j++ {
+				if decoded[j].Value == "" && (decoded[j].ID < f.id || f.value == "") {
+					children = append(children, TLVNode{ID: decoded[j].ID})
+				}
+			}
+			leaf(&children, f.id, f.value)
+		}
+		for ; j < len(decoded); j++ {
+			if decoded[j].Value == "" {
+				children = append(children, TLVNode{ID: decoded[j].ID})
+			}
+		}
+		template(id, children)
+	}
+	if v := p.UPIVPAInfo; v != nil {
+		typed(IDUPIVPATemplate,
+			typedSubField{MAIGloballyUniqueID, "", v.RuPayRID},
+			typedSubField{"01", "", v.VPA},
+			typedSubField{"02", "", v.MinimumAmount})
+	}
+	if r := p.UPITransactionRef; r != nil {
+		typed(IDUPIVPAReference,
+			typedSubField{UPIVPARefRuPayRID, "", r.RuPayRID},
+			typedSubField{UPIVPARefTransactionRef, "", r.TransactionRef},
+			typedSubField{UPIVPARefURL, "", r.ReferenceURL})
+	}
+	if a := p.MerchantAadhaar; a != nil {
+		typed(IDAadhaarTemplate,
+			typedSubField{AadhaarRuPayRID, "", a.RuPayRID},
+			typedSubField{AadhaarAadhaarNum, "", a.AadhaarNumber})
+	}
+	if a := p.AdditionalData; a != nil {
+		var children []TLVNode
+		for _, pair := range []struct{ id, val string }{
+			{ADFBillNumber, a.BillNumber},
+			{ADFMobileNumber, a.MobileNumber},
+			{ADFStoreLabel, a.StoreLabel},
+			{ADFLoyaltyNumber, a.LoyaltyNumber},
+			{ADFReferenceLabel, a.ReferenceLabel},
+			{ADFCustomerLabel, a.CustomerLabel},
+			{ADFTerminalLabel, a.TerminalLabel},
+			{ADFPurposeOfTransaction, a.PurposeOfTransaction},
+			{ADFAdditionalConsumerDataRequest, a.AdditionalConsumerDataRequest},
+			{ADFMerchantTaxID, a.MerchantTaxID},
+			{ADFMerchantChannel, a.MerchantChannel},
+		} {
+			leaf(&children, pair.id, pair.val)
+		}
+		for _, pst := range a.PaymentSystemTemplates {
+			if n := unreserved(pst); len(n.Children) > 0 {
+				children = append(children, n)
+			}
+		}
+		template(IDAdditionalDataFieldTemplate, append(children, objects(a.RFUFields)...))
+	}
+	if lt := p.LanguageTemplate; lt != nil {
+		var children []TLVNode
+		leaf(&children, LangPreference, lt.LanguagePreference)
+		leaf(&children, LangMerchantName, lt.MerchantName)
+		leaf(&children, LangMerchantCity, lt.MerchantCity)
+		template(IDMerchantInfoLanguageTemplate, append(children, objects(lt.RFUFields)...))
+	}
+	for _, ut := range p.UnreservedTemplates {
+		if n := unreserved(ut); len(n.Children) > 0 {
+			nodes = append(nodes, n)
+		}
+	}
+	nodes = append(nodes, objects(p.RFUFields)...)
+	leaf(&nodes, IDCRC, p.CRC)
+	return nodes
+}
@@ -0,0 +1,91 @@
+package emvqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWalkEncodingOrder(t *testing.T) {
+	full := basePayload()
+	full.SetPercentageConvenienceFee("3.00")
+	full.SetAdditionalData(func(a *AdditionalDataField) { a.BillNumber = "INV-1"; a.TerminalLabel = "T1" })
+	full.SetLanguageTemplate("es", "ABC Martillos", "Nueva York")
+	full.UnreservedTemplates = []UnreservedTemplate{{ID: "80", GloballyUniqueID: "com.example", SubFields: []DataObject{{ID: "01", Value: "X"}}}}
+	raw, err := Encode(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, raw := range []string{realWorldBharatQRPayload, raw} {
+		p, err := Decode(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.CRC = ""
+		want, err := Encode(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sb strings.Builder
+		err = Walk(p, func(path, id, value string) error {
+			if strings.Contains(path, ".") {
+				return nil
+			}
+			tlv, err := encodeTLV(id, value)
+			sb.WriteString(tlv)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEqual(t, "walked payload", want[:len(want)-8], sb.String())
+	}
+}
+
+func TestWalkPaths(t *testing.T) {
+	p, err := Decode(realWorldBharatQRPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	err = Walk(p, func(path, id, value string) error {
+		if !strings.HasSuffix(path, id) {
+			t.Errorf("path %q does not end in ID %q", path, id)
+		}
+		paths = append(paths, path)
+		if path == "26" {
+			return SkipTemplate
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(paths, " ")
+	for _, want := range []string{"00 01 02 04", "26 27 27.00 27.01 27.02 28", "62 62.03 62.05 62.07", "63"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("paths %q lack %q", got, want)
+		}
+	}
+	if strings.Contains(got, "26.01") {
+		t.Error("SkipTemplate did not skip Tag 26")
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = Walk(p, func(path, id, value string) error {
+		if n++; path == "59" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("want stop error, got %v", err)
+	}
+
+	// Incomplete payloads can be walked.
+	if err := Walk(&Payload{MerchantName: "X"}, func(string, string, string) error { return nil }); err != nil {
+		t.Error(err)
+	}
+}
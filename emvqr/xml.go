@@ -0,0 +1,131 @@
+package emvqr
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// XML element names used by MarshalXML and UnmarshalXML.
+const (
+	xmlField    = "field"
+	xmlTemplate = "template"
+)
+
+// xmlNode is a data object as read by UnmarshalXML.
+type xmlNode struct {
+	XMLName  xml.Name
+	Tag      string    `xml:"tag,attr"`
+	Value    string    `xml:",chardata"`
+	Children []xmlNode `xml:",any"`
+}
+
+// MarshalXML implements xml.Marshaler. Each data object becomes a field
+// element, and each template a template element holding its sub-fields, in
+// ascending tag order. The tag attribute carries the two-digit ID and the
+// name attribute the spec name, for readability only:
+//
+//	<Payload>
+//	  <field tag="00" name="Payload Format Indicator">01</field>
+//	  <field tag="59" name="Merchant Name">ABC Hammers</field>
+//	  <template tag="62" name="Additional Data Field Template">
+//	    <field tag="05" name="Reference Label">R555</field>
+//	  </template>
+//	</Payload>
+//
+// The paths are those of Flatten.
+func (p *Payload) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	root, err := p.flatTree()
+	if err != nil {
+		return err
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := root.marshalXML(e, ""); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// marshalXML writes the children of n. parent is the tag of the enclosing
+// top-level template, or "" at the top level.
+func (n *flatNode) marshalXML(e *xml.Encoder, parent string) error {
+	for _, id := range n.ids() {
+		child := n.children[id]
+		name := topLevelSpec(id).name
+		if parent != "" {
+			name = subFieldSpec(parent, id).name
+		}
+		start := xml.StartElement{
+			Name: xml.Name{Local: xmlField},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "tag"}, Value: id},
+				{Name: xml.Name{Local: "name"}, Value: name},
+			},
+		}
+		if child.children == nil {
+			if err := e.EncodeElement(child.value, start); err != nil {
+				return err
+			}
+			continue
+		}
+		start.Name.Local = xmlTemplate
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		template := parent
+		if template == "" {
+			template = id
+		}
+		if err := child.marshalXML(e, template); err != nil {
+			return err
+		}
+		if err := e.EncodeToken(start.End()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler for the form written by
+// MarshalXML and replaces p entirely. Name attributes are ignored. The
+// payload is interpreted as DecodeWithOptions would with zero options; the
+// CRC is not validated.
+func (p *Payload) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var doc xmlNode
+	if err := d.DecodeElement(&doc, &start); err != nil {
+		return err
+	}
+	flat := make(map[string]string)
+	if err := flattenXML(doc.Children, "", flat); err != nil {
+		return err
+	}
+	decoded, err := FromFlat(flat)
+	if err != nil {
+		return err
+	}
+	*p = *decoded
+	return nil
+}
+
+// flattenXML adds the field values under nodes to flat, keyed by dotted tag
+// path.
+func flattenXML(nodes []xmlNode, prefix string, flat map[string]string) error {
+	for _, n := range nodes {
+		path := prefix + n.Tag
+		switch n.XMLName.Local {
+		case xmlField:
+			if len(n.Children) > 0 {
+				return fmt.Errorf("%w: XML field %q has child elements", ErrInvalidTLV, path)
+			}
+			flat[path] = n.Value
+		case xmlTemplate:
+			if err := flattenXML(n.Children, path+".", flat); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: unexpected XML element <%s> at %q", ErrInvalidTLV, n.XMLName.Local, path)
+		}
+	}
+	return nil
+}
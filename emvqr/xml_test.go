@@ -0,0 +1,72 @@
+package emvqr
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestXML_RoundTrip(t *testing.T) {
+	// Like Flatten, the schema cannot hold the empty Tag 28.01.
+	p, err := DecodeWithOptions(realWorldBharatQRPayload, DecodeOptions{DropEmptySubFields: true})
+	if err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	data, err := xml.MarshalIndent(p, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent() error: %v", err)
+	}
+	for _, want := range []string{
+		"<Payload>\n  <field tag=\"00\" name=\"Payload Format Indicator\">01</field>",
+		`<template tag="62" name="Additional Data Field Template">`,
+		`<field tag="05" name="Reference Label">52602091445452087569609</field>`,
+		`<field tag="63" name="CRC">51DD</field>`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("MarshalIndent() missing %q:\n%s", want, data)
+		}
+	}
+
+	var back Payload
+	if err := xml.Unmarshal(data, &back); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if diff := cmp.Diff(p, &back); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestXML_Embedded(t *testing.T) {
+	type merchant struct {
+		XMLName xml.Name `xml:"Merchant"`
+		ID      string   `xml:"id,attr"`
+		QR      *Payload
+	}
+	in := merchant{ID: "M-1", QR: basePayload()}
+	data, err := xml.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	var out merchant
+	if err := xml.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if diff := cmp.Diff(in.QR, out.QR); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestXML_Errors(t *testing.T) {
+	for _, input := range []string{
+		`<Payload><row tag="59">x</row></Payload>`,
+		`<Payload><field tag="5">x</field></Payload>`,
+		`<Payload><field tag="62"><field tag="05">x</field></field></Payload>`,
+	} {
+		var p Payload
+		if err := xml.Unmarshal([]byte(input), &p); err == nil {
+			t.Errorf("Unmarshal(%s) expected error, got nil", input)
+		}
+	}
+}
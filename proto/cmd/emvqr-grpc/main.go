@@ -0,0 +1,40 @@
+// Command emvqr-grpc serves the EmvQR gRPC service with the reference
+// server:
+//
+//	emvqr-grpc -addr :9090 -profile bharatqr
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/profile"
+	emvqrv1 "github.com/hussainpithawala/emv-merchant-qr-lib/proto/emvqr/v1"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/proto/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "TCP `address` to listen on")
+	profileName := flag.String("profile", profile.Default, "scheme `profile` Validate uses when a request names none: "+strings.Join(profile.Names(), ", "))
+	flag.Parse()
+	if _, ok := profile.Lookup(*profileName); !ok {
+		fmt.Fprintf(os.Stderr, "emvqr-grpc: unknown profile %q\n", *profileName)
+		os.Exit(2)
+	}
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("emvqr-grpc: %v", err)
+	}
+	s := grpc.NewServer()
+	emvqrv1.RegisterEmvQRServer(s, server.New(server.Options{Profile: *profileName}))
+	log.Printf("emvqr-grpc: serving on %s", lis.Addr())
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("emvqr-grpc: %v", err)
+	}
+}
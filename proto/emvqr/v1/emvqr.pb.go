@@ -0,0 +1,1024 @@
+// Protocol Buffers schema and gRPC service for EMV merchant-presented QR
+// codes, for payment switches that standardise on gRPC between services.
+//
+// Payloads are exchanged as a map of dotted tag paths to values, the
+// representation of Payload.Flatten and FromFlat (e.g. "59" → merchant name,
+// "62.05" → reference label), so servers and clients in any language can
+// build them from the EMV specification alone.
+//
+// Go stubs are generated into this directory, which belongs to the nested
+// module github.com/hussainpithawala/emv-merchant-qr-lib/proto so that the
+// emvqr module itself keeps no dependencies outside the standard library.
+// Regenerate them from the proto directory with:
+//
+//   buf generate
+//
+// The server package of that module is a reference implementation of
+// EmvQRServer backed by emvqr, profile and render.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: emvqr/v1/emvqr.proto
+
+package emvqrv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SpecVersion is the EMV QRCPS MPM revision.
+type SpecVersion int32
+
+const (
+	SpecVersion_SPEC_VERSION_UNSPECIFIED SpecVersion = 0 // v1.0
+	SpecVersion_SPEC_VERSION_1_0         SpecVersion = 1
+	SpecVersion_SPEC_VERSION_1_1         SpecVersion = 2
+)
+
+// Enum value maps for SpecVersion.
+var (
+	SpecVersion_name = map[int32]string{
+		0: "SPEC_VERSION_UNSPECIFIED",
+		1: "SPEC_VERSION_1_0",
+		2: "SPEC_VERSION_1_1",
+	}
+	SpecVersion_value = map[string]int32{
+		"SPEC_VERSION_UNSPECIFIED": 0,
+		"SPEC_VERSION_1_0":         1,
+		"SPEC_VERSION_1_1":         2,
+	}
+)
+
+func (x SpecVersion) Enum() *SpecVersion {
+	p := new(SpecVersion)
+	*p = x
+	return p
+}
+
+func (x SpecVersion) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SpecVersion) Descriptor() protoreflect.EnumDescriptor {
+	return file_emvqr_v1_emvqr_proto_enumTypes[0].Descriptor()
+}
+
+func (SpecVersion) Type() protoreflect.EnumType {
+	return &file_emvqr_v1_emvqr_proto_enumTypes[0]
+}
+
+func (x SpecVersion) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SpecVersion.Descriptor instead.
+func (SpecVersion) EnumDescriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{0}
+}
+
+// ErrorCorrection is the QR Code error correction level.
+type ErrorCorrection int32
+
+const (
+	ErrorCorrection_ERROR_CORRECTION_UNSPECIFIED ErrorCorrection = 0 // medium
+	ErrorCorrection_ERROR_CORRECTION_LOW         ErrorCorrection = 1
+	ErrorCorrection_ERROR_CORRECTION_MEDIUM      ErrorCorrection = 2
+	ErrorCorrection_ERROR_CORRECTION_QUARTILE    ErrorCorrection = 3
+	ErrorCorrection_ERROR_CORRECTION_HIGH        ErrorCorrection = 4
+)
+
+// Enum value maps for ErrorCorrection.
+var (
+	ErrorCorrection_name = map[int32]string{
+		0: "ERROR_CORRECTION_UNSPECIFIED",
+		1: "ERROR_CORRECTION_LOW",
+		2: "ERROR_CORRECTION_MEDIUM",
+		3: "ERROR_CORRECTION_QUARTILE",
+		4: "ERROR_CORRECTION_HIGH",
+	}
+	ErrorCorrection_value = map[string]int32{
+		"ERROR_CORRECTION_UNSPECIFIED": 0,
+		"ERROR_CORRECTION_LOW":         1,
+		"ERROR_CORRECTION_MEDIUM":      2,
+		"ERROR_CORRECTION_QUARTILE":    3,
+		"ERROR_CORRECTION_HIGH":        4,
+	}
+)
+
+func (x ErrorCorrection) Enum() *ErrorCorrection {
+	p := new(ErrorCorrection)
+	*p = x
+	return p
+}
+
+func (x ErrorCorrection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCorrection) Descriptor() protoreflect.EnumDescriptor {
+	return file_emvqr_v1_emvqr_proto_enumTypes[1].Descriptor()
+}
+
+func (ErrorCorrection) Type() protoreflect.EnumType {
+	return &file_emvqr_v1_emvqr_proto_enumTypes[1]
+}
+
+func (x ErrorCorrection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCorrection.Descriptor instead.
+func (ErrorCorrection) EnumDescriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{1}
+}
+
+type ImageFormat int32
+
+const (
+	ImageFormat_IMAGE_FORMAT_UNSPECIFIED ImageFormat = 0 // PNG
+	ImageFormat_IMAGE_FORMAT_PNG         ImageFormat = 1
+	ImageFormat_IMAGE_FORMAT_SVG         ImageFormat = 2
+	ImageFormat_IMAGE_FORMAT_PDF         ImageFormat = 3
+)
+
+// Enum value maps for ImageFormat.
+var (
+	ImageFormat_name = map[int32]string{
+		0: "IMAGE_FORMAT_UNSPECIFIED",
+		1: "IMAGE_FORMAT_PNG",
+		2: "IMAGE_FORMAT_SVG",
+		3: "IMAGE_FORMAT_PDF",
+	}
+	ImageFormat_value = map[string]int32{
+		"IMAGE_FORMAT_UNSPECIFIED": 0,
+		"IMAGE_FORMAT_PNG":         1,
+		"IMAGE_FORMAT_SVG":         2,
+		"IMAGE_FORMAT_PDF":         3,
+	}
+)
+
+func (x ImageFormat) Enum() *ImageFormat {
+	p := new(ImageFormat)
+	*p = x
+	return p
+}
+
+func (x ImageFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImageFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_emvqr_v1_emvqr_proto_enumTypes[2].Descriptor()
+}
+
+func (ImageFormat) Type() protoreflect.EnumType {
+	return &file_emvqr_v1_emvqr_proto_enumTypes[2]
+}
+
+func (x ImageFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImageFormat.Descriptor instead.
+func (ImageFormat) EnumDescriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{2}
+}
+
+// Payload holds the fields of a payload by dotted tag path. The CRC ("63")
+// is ignored on input.
+type Payload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fields        map[string]string      `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Payload) Reset() {
+	*x = Payload{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Payload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payload) ProtoMessage() {}
+
+func (x *Payload) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payload.ProtoReflect.Descriptor instead.
+func (*Payload) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Payload) GetFields() map[string]string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+// Error describes a failed Encode or Decode, or an invalid payload in a
+// ValidateResponse. Code is that of emvqr.ErrorCode, e.g. "crc_mismatch";
+// RPCs that fail return it in the status details.
+type Error struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Code    string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Consumer-facing message in the request's language, if one was given.
+	Localized     string            `protobuf:"bytes,3,opt,name=localized,proto3" json:"localized,omitempty"`
+	Params        map[string]string `protobuf:"bytes,4,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Error) GetLocalized() string {
+	if x != nil {
+		return x.Localized
+	}
+	return ""
+}
+
+func (x *Error) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type EncodeRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Payload     *Payload               `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	SpecVersion SpecVersion            `protobuf:"varint,2,opt,name=spec_version,json=specVersion,proto3,enum=emvqr.v1.SpecVersion" json:"spec_version,omitempty"`
+	// Canonical selects EncodeOptions.Canonical.
+	Canonical     bool `protobuf:"varint,3,opt,name=canonical,proto3" json:"canonical,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EncodeRequest) Reset() {
+	*x = EncodeRequest{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EncodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EncodeRequest) ProtoMessage() {}
+
+func (x *EncodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EncodeRequest.ProtoReflect.Descriptor instead.
+func (*EncodeRequest) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EncodeRequest) GetPayload() *Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *EncodeRequest) GetSpecVersion() SpecVersion {
+	if x != nil {
+		return x.SpecVersion
+	}
+	return SpecVersion_SPEC_VERSION_UNSPECIFIED
+}
+
+func (x *EncodeRequest) GetCanonical() bool {
+	if x != nil {
+		return x.Canonical
+	}
+	return false
+}
+
+type EncodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Qr            string                 `protobuf:"bytes,1,opt,name=qr,proto3" json:"qr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EncodeResponse) Reset() {
+	*x = EncodeResponse{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EncodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EncodeResponse) ProtoMessage() {}
+
+func (x *EncodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EncodeResponse.ProtoReflect.Descriptor instead.
+func (*EncodeResponse) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EncodeResponse) GetQr() string {
+	if x != nil {
+		return x.Qr
+	}
+	return ""
+}
+
+type DecodeRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Qr          string                 `protobuf:"bytes,1,opt,name=qr,proto3" json:"qr,omitempty"`
+	SpecVersion SpecVersion            `protobuf:"varint,2,opt,name=spec_version,json=specVersion,proto3,enum=emvqr.v1.SpecVersion" json:"spec_version,omitempty"`
+	// Language is a BCP 47 tag for Error.localized, e.g. "hi-IN".
+	Language      string `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecodeRequest) Reset() {
+	*x = DecodeRequest{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecodeRequest) ProtoMessage() {}
+
+func (x *DecodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecodeRequest.ProtoReflect.Descriptor instead.
+func (*DecodeRequest) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DecodeRequest) GetQr() string {
+	if x != nil {
+		return x.Qr
+	}
+	return ""
+}
+
+func (x *DecodeRequest) GetSpecVersion() SpecVersion {
+	if x != nil {
+		return x.SpecVersion
+	}
+	return SpecVersion_SPEC_VERSION_UNSPECIFIED
+}
+
+func (x *DecodeRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type DecodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payload       *Payload               `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecodeResponse) Reset() {
+	*x = DecodeResponse{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecodeResponse) ProtoMessage() {}
+
+func (x *DecodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecodeResponse.ProtoReflect.Descriptor instead.
+func (*DecodeResponse) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DecodeResponse) GetPayload() *Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type ValidateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Qr    string                 `protobuf:"bytes,1,opt,name=qr,proto3" json:"qr,omitempty"`
+	// Profile names the scheme profile, e.g. "bharatqr"; "" means "emvco".
+	Profile       string      `protobuf:"bytes,2,opt,name=profile,proto3" json:"profile,omitempty"`
+	SpecVersion   SpecVersion `protobuf:"varint,3,opt,name=spec_version,json=specVersion,proto3,enum=emvqr.v1.SpecVersion" json:"spec_version,omitempty"`
+	Language      string      `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateRequest) Reset() {
+	*x = ValidateRequest{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRequest) ProtoMessage() {}
+
+func (x *ValidateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRequest.ProtoReflect.Descriptor instead.
+func (*ValidateRequest) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ValidateRequest) GetQr() string {
+	if x != nil {
+		return x.Qr
+	}
+	return ""
+}
+
+func (x *ValidateRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *ValidateRequest) GetSpecVersion() SpecVersion {
+	if x != nil {
+		return x.SpecVersion
+	}
+	return SpecVersion_SPEC_VERSION_UNSPECIFIED
+}
+
+func (x *ValidateRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+// Warning is a lint finding, as returned by emvqr.Lint.
+type Warning struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Check         string                 `protobuf:"bytes,1,opt,name=check,proto3" json:"check,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Warning) Reset() {
+	*x = Warning{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Warning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Warning) ProtoMessage() {}
+
+func (x *Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Warning.ProtoReflect.Descriptor instead.
+func (*Warning) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Warning) GetCheck() string {
+	if x != nil {
+		return x.Check
+	}
+	return ""
+}
+
+func (x *Warning) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Warning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ValidateResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Valid   bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Profile string                 `protobuf:"bytes,2,opt,name=profile,proto3" json:"profile,omitempty"`
+	// Error is set when the payload does not decode.
+	Error         *Error     `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Findings      []string   `protobuf:"bytes,4,rep,name=findings,proto3" json:"findings,omitempty"`
+	Warnings      []*Warning `protobuf:"bytes,5,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateResponse) Reset() {
+	*x = ValidateResponse{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateResponse) ProtoMessage() {}
+
+func (x *ValidateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateResponse.ProtoReflect.Descriptor instead.
+func (*ValidateResponse) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ValidateResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateResponse) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *ValidateResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *ValidateResponse) GetFindings() []string {
+	if x != nil {
+		return x.Findings
+	}
+	return nil
+}
+
+func (x *ValidateResponse) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type RenderRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Qr              string                 `protobuf:"bytes,1,opt,name=qr,proto3" json:"qr,omitempty"`
+	Format          ImageFormat            `protobuf:"varint,2,opt,name=format,proto3,enum=emvqr.v1.ImageFormat" json:"format,omitempty"`
+	ErrorCorrection ErrorCorrection        `protobuf:"varint,3,opt,name=error_correction,json=errorCorrection,proto3,enum=emvqr.v1.ErrorCorrection" json:"error_correction,omitempty"`
+	// Module size in pixels (PNG) or user units (SVG); 0 means 8.
+	ModuleSize int32 `protobuf:"varint,4,opt,name=module_size,json=moduleSize,proto3" json:"module_size,omitempty"`
+	// Caption printed below the symbol, typically the merchant name.
+	Caption       string `protobuf:"bytes,5,opt,name=caption,proto3" json:"caption,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderRequest) Reset() {
+	*x = RenderRequest{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderRequest) ProtoMessage() {}
+
+func (x *RenderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderRequest.ProtoReflect.Descriptor instead.
+func (*RenderRequest) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RenderRequest) GetQr() string {
+	if x != nil {
+		return x.Qr
+	}
+	return ""
+}
+
+func (x *RenderRequest) GetFormat() ImageFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ImageFormat_IMAGE_FORMAT_UNSPECIFIED
+}
+
+func (x *RenderRequest) GetErrorCorrection() ErrorCorrection {
+	if x != nil {
+		return x.ErrorCorrection
+	}
+	return ErrorCorrection_ERROR_CORRECTION_UNSPECIFIED
+}
+
+func (x *RenderRequest) GetModuleSize() int32 {
+	if x != nil {
+		return x.ModuleSize
+	}
+	return 0
+}
+
+func (x *RenderRequest) GetCaption() string {
+	if x != nil {
+		return x.Caption
+	}
+	return ""
+}
+
+type RenderResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Image []byte                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	// Content type of image, e.g. "image/png".
+	ContentType   string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderResponse) Reset() {
+	*x = RenderResponse{}
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderResponse) ProtoMessage() {}
+
+func (x *RenderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_emvqr_v1_emvqr_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderResponse.ProtoReflect.Descriptor instead.
+func (*RenderResponse) Descriptor() ([]byte, []int) {
+	return file_emvqr_v1_emvqr_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RenderResponse) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *RenderResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+var File_emvqr_v1_emvqr_proto protoreflect.FileDescriptor
+
+const file_emvqr_v1_emvqr_proto_rawDesc = "" +
+	"\n" +
+	"\x14emvqr/v1/emvqr.proto\x12\bemvqr.v1\"{\n" +
+	"\aPayload\x125\n" +
+	"\x06fields\x18\x01 \x03(\v2\x1d.emvqr.v1.Payload.FieldsEntryR\x06fields\x1a9\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc3\x01\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
+	"\tlocalized\x18\x03 \x01(\tR\tlocalized\x123\n" +
+	"\x06params\x18\x04 \x03(\v2\x1b.emvqr.v1.Error.ParamsEntryR\x06params\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x94\x01\n" +
+	"\rEncodeRequest\x12+\n" +
+	"\apayload\x18\x01 \x01(\v2\x11.emvqr.v1.PayloadR\apayload\x128\n" +
+	"\fspec_version\x18\x02 \x01(\x0e2\x15.emvqr.v1.SpecVersionR\vspecVersion\x12\x1c\n" +
+	"\tcanonical\x18\x03 \x01(\bR\tcanonical\" \n" +
+	"\x0eEncodeResponse\x12\x0e\n" +
+	"\x02qr\x18\x01 \x01(\tR\x02qr\"u\n" +
+	"\rDecodeRequest\x12\x0e\n" +
+	"\x02qr\x18\x01 \x01(\tR\x02qr\x128\n" +
+	"\fspec_version\x18\x02 \x01(\x0e2\x15.emvqr.v1.SpecVersionR\vspecVersion\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\"=\n" +
+	"\x0eDecodeResponse\x12+\n" +
+	"\apayload\x18\x01 \x01(\v2\x11.emvqr.v1.PayloadR\apayload\"\x91\x01\n" +
+	"\x0fValidateRequest\x12\x0e\n" +
+	"\x02qr\x18\x01 \x01(\tR\x02qr\x12\x18\n" +
+	"\aprofile\x18\x02 \x01(\tR\aprofile\x128\n" +
+	"\fspec_version\x18\x03 \x01(\x0e2\x15.emvqr.v1.SpecVersionR\vspecVersion\x12\x1a\n" +
+	"\blanguage\x18\x04 \x01(\tR\blanguage\"M\n" +
+	"\aWarning\x12\x14\n" +
+	"\x05check\x18\x01 \x01(\tR\x05check\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xb4\x01\n" +
+	"\x10ValidateResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x18\n" +
+	"\aprofile\x18\x02 \x01(\tR\aprofile\x12%\n" +
+	"\x05error\x18\x03 \x01(\v2\x0f.emvqr.v1.ErrorR\x05error\x12\x1a\n" +
+	"\bfindings\x18\x04 \x03(\tR\bfindings\x12-\n" +
+	"\bwarnings\x18\x05 \x03(\v2\x11.emvqr.v1.WarningR\bwarnings\"\xcf\x01\n" +
+	"\rRenderRequest\x12\x0e\n" +
+	"\x02qr\x18\x01 \x01(\tR\x02qr\x12-\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x15.emvqr.v1.ImageFormatR\x06format\x12D\n" +
+	"\x10error_correction\x18\x03 \x01(\x0e2\x19.emvqr.v1.ErrorCorrectionR\x0ferrorCorrection\x12\x1f\n" +
+	"\vmodule_size\x18\x04 \x01(\x05R\n" +
+	"moduleSize\x12\x18\n" +
+	"\acaption\x18\x05 \x01(\tR\acaption\"I\n" +
+	"\x0eRenderResponse\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\fR\x05image\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType*W\n" +
+	"\vSpecVersion\x12\x1c\n" +
+	"\x18SPEC_VERSION_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10SPEC_VERSION_1_0\x10\x01\x12\x14\n" +
+	"\x10SPEC_VERSION_1_1\x10\x02*\xa4\x01\n" +
+	"\x0fErrorCorrection\x12 \n" +
+	"\x1cERROR_CORRECTION_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14ERROR_CORRECTION_LOW\x10\x01\x12\x1b\n" +
+	"\x17ERROR_CORRECTION_MEDIUM\x10\x02\x12\x1d\n" +
+	"\x19ERROR_CORRECTION_QUARTILE\x10\x03\x12\x19\n" +
+	"\x15ERROR_CORRECTION_HIGH\x10\x04*m\n" +
+	"\vImageFormat\x12\x1c\n" +
+	"\x18IMAGE_FORMAT_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10IMAGE_FORMAT_PNG\x10\x01\x12\x14\n" +
+	"\x10IMAGE_FORMAT_SVG\x10\x02\x12\x14\n" +
+	"\x10IMAGE_FORMAT_PDF\x10\x032\x81\x02\n" +
+	"\x05EmvQR\x12;\n" +
+	"\x06Encode\x12\x17.emvqr.v1.EncodeRequest\x1a\x18.emvqr.v1.EncodeResponse\x12;\n" +
+	"\x06Decode\x12\x17.emvqr.v1.DecodeRequest\x1a\x18.emvqr.v1.DecodeResponse\x12A\n" +
+	"\bValidate\x12\x19.emvqr.v1.ValidateRequest\x1a\x1a.emvqr.v1.ValidateResponse\x12;\n" +
+	"\x06Render\x12\x17.emvqr.v1.RenderRequest\x1a\x18.emvqr.v1.RenderResponseBHZFgithub.com/hussainpithawala/emv-merchant-qr-lib/proto/emvqr/v1;emvqrv1b\x06proto3"
+
+var (
+	file_emvqr_v1_emvqr_proto_rawDescOnce sync.Once
+	file_emvqr_v1_emvqr_proto_rawDescData []byte
+)
+
+func file_emvqr_v1_emvqr_proto_rawDescGZIP() []byte {
+	file_emvqr_v1_emvqr_proto_rawDescOnce.Do(func() {
+		file_emvqr_v1_emvqr_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_emvqr_v1_emvqr_proto_rawDesc), len(file_emvqr_v1_emvqr_proto_rawDesc)))
+	})
+	return file_emvqr_v1_emvqr_proto_rawDescData
+}
+
+var file_emvqr_v1_emvqr_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_emvqr_v1_emvqr_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_emvqr_v1_emvqr_proto_goTypes = []any{
+	(SpecVersion)(0),         // 0: emvqr.v1.SpecVersion
+	(ErrorCorrection)(0),     // 1: emvqr.v1.ErrorCorrection
+	(ImageFormat)(0),         // 2: emvqr.v1.ImageFormat
+	(*Payload)(nil),          // 3: emvqr.v1.Payload
+	(*Error)(nil),            // 4: emvqr.v1.Error
+	(*EncodeRequest)(nil),    // 5: emvqr.v1.EncodeRequest
+	(*EncodeResponse)(nil),   // 6: emvqr.v1.EncodeResponse
+	(*DecodeRequest)(nil),    // 7: emvqr.v1.DecodeRequest
+	(*DecodeResponse)(nil),   // 8: emvqr.v1.DecodeResponse
+	(*ValidateRequest)(nil),  // 9: emvqr.v1.ValidateRequest
+	(*Warning)(nil),          // 10: emvqr.v1.Warning
+	(*ValidateResponse)(nil), // 11: emvqr.v1.ValidateResponse
+	(*RenderRequest)(nil),    // 12: emvqr.v1.RenderRequest
+	(*RenderResponse)(nil),   // 13: emvqr.v1.RenderResponse
+	nil,                      // 14: emvqr.v1.Payload.FieldsEntry
+	nil,                      // 15: emvqr.v1.Error.ParamsEntry
+}
+var file_emvqr_v1_emvqr_proto_depIdxs = []int32{
+	14, // 0: emvqr.v1.Payload.fields:type_name -> emvqr.v1.Payload.FieldsEntry
+	15, // 1: emvqr.v1.Error.params:type_name -> emvqr.v1.Error.ParamsEntry
+	3,  // 2: emvqr.v1.EncodeRequest.payload:type_name -> emvqr.v1.Payload
+	0,  // 3: emvqr.v1.EncodeRequest.spec_version:type_name -> emvqr.v1.SpecVersion
+	0,  // 4: emvqr.v1.DecodeRequest.spec_version:type_name -> emvqr.v1.SpecVersion
+	3,  // 5: emvqr.v1.DecodeResponse.payload:type_name -> emvqr.v1.Payload
+	0,  // 6: emvqr.v1.ValidateRequest.spec_version:type_name -> emvqr.v1.SpecVersion
+	4,  // 7: emvqr.v1.ValidateResponse.error:type_name -> emvqr.v1.Error
+	10, // 8: emvqr.v1.ValidateResponse.warnings:type_name -> emvqr.v1.Warning
+	2,  // 9: emvqr.v1.RenderRequest.format:type_name -> emvqr.v1.ImageFormat
+	1,  // 10: emvqr.v1.RenderRequest.error_correction:type_name -> emvqr.v1.ErrorCorrection
+	5,  // 11: emvqr.v1.EmvQR.Encode:input_type -> emvqr.v1.EncodeRequest
+	7,  // 12: emvqr.v1.EmvQR.Decode:input_type -> emvqr.v1.DecodeRequest
+	9,  // 13: emvqr.v1.EmvQR.Validate:input_type -> emvqr.v1.ValidateRequest
+	12, // 14: emvqr.v1.EmvQR.Render:input_type -> emvqr.v1.RenderRequest
+	6,  // 15: emvqr.v1.EmvQR.Encode:output_type -> emvqr.v1.EncodeResponse
+	8,  // 16: emvqr.v1.EmvQR.Decode:output_type -> emvqr.v1.DecodeResponse
+	11, // 17: emvqr.v1.EmvQR.Validate:output_type -> emvqr.v1.ValidateResponse
+	13, // 18: emvqr.v1.EmvQR.Render:output_type -> emvqr.v1.RenderResponse
+	15, // [15:19] is the sub-list for method output_type
+	11, // [11:15] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_emvqr_v1_emvqr_proto_init() }
+func file_emvqr_v1_emvqr_proto_init() {
+	if File_emvqr_v1_emvqr_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_emvqr_v1_emvqr_proto_rawDesc), len(file_emvqr_v1_emvqr_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_emvqr_v1_emvqr_proto_goTypes,
+		DependencyIndexes: file_emvqr_v1_emvqr_proto_depIdxs,
+		EnumInfos:         file_emvqr_v1_emvqr_proto_enumTypes,
+		MessageInfos:      file_emvqr_v1_emvqr_proto_msgTypes,
+	}.Build()
+	File_emvqr_v1_emvqr_proto = out.File
+	file_emvqr_v1_emvqr_proto_goTypes = nil
+	file_emvqr_v1_emvqr_proto_depIdxs = nil
+}
@@ -0,0 +1,263 @@
+// Protocol Buffers schema and gRPC service for EMV merchant-presented QR
+// codes, for payment switches that standardise on gRPC between services.
+//
+// Payloads are exchanged as a map of dotted tag paths to values, the
+// representation of Payload.Flatten and FromFlat (e.g. "59" → merchant name,
+// "62.05" → reference label), so servers and clients in any language can
+// build them from the EMV specification alone.
+//
+// Go stubs are generated into this directory, which belongs to the nested
+// module github.com/hussainpithawala/emv-merchant-qr-lib/proto so that the
+// emvqr module itself keeps no dependencies outside the standard library.
+// Regenerate them from the proto directory with:
+//
+//   buf generate
+//
+// The server package of that module is a reference implementation of
+// EmvQRServer backed by emvqr, profile and render.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: emvqr/v1/emvqr.proto
+
+package emvqrv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	EmvQR_Encode_FullMethodName   = "/emvqr.v1.EmvQR/Encode"
+	EmvQR_Decode_FullMethodName   = "/emvqr.v1.EmvQR/Decode"
+	EmvQR_Validate_FullMethodName = "/emvqr.v1.EmvQR/Validate"
+	EmvQR_Render_FullMethodName   = "/emvqr.v1.EmvQR/Render"
+)
+
+// EmvQRClient is the client API for EmvQR service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EmvQRClient interface {
+	// Encode serialises a payload, computing its CRC.
+	Encode(ctx context.Context, in *EncodeRequest, opts ...grpc.CallOption) (*EncodeResponse, error)
+	// Decode parses and checks a raw QR string.
+	Decode(ctx context.Context, in *DecodeRequest, opts ...grpc.CallOption) (*DecodeResponse, error)
+	// Validate checks a raw QR string against a scheme profile. Invalid
+	// payloads are reported in the response, not as an RPC error.
+	Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error)
+	// Render draws a raw QR string as an image.
+	Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (*RenderResponse, error)
+}
+
+type emvQRClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEmvQRClient(cc grpc.ClientConnInterface) EmvQRClient {
+	return &emvQRClient{cc}
+}
+
+func (c *emvQRClient) Encode(ctx context.Context, in *EncodeRequest, opts ...grpc.CallOption) (*EncodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EncodeResponse)
+	err := c.cc.Invoke(ctx, EmvQR_Encode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emvQRClient) Decode(ctx context.Context, in *DecodeRequest, opts ...grpc.CallOption) (*DecodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DecodeResponse)
+	err := c.cc.Invoke(ctx, EmvQR_Decode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emvQRClient) Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateResponse)
+	err := c.cc.Invoke(ctx, EmvQR_Validate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *emvQRClient) Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (*RenderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenderResponse)
+	err := c.cc.Invoke(ctx, EmvQR_Render_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EmvQRServer is the server API for EmvQR service.
+// All implementations must embed UnimplementedEmvQRServer
+// for forward compatibility.
+type EmvQRServer interface {
+	// Encode serialises a payload, computing its CRC.
+	Encode(context.Context, *EncodeRequest) (*EncodeResponse, error)
+	// Decode parses and checks a raw QR string.
+	Decode(context.Context, *DecodeRequest) (*DecodeResponse, error)
+	// Validate checks a raw QR string against a scheme profile. Invalid
+	// payloads are reported in the response, not as an RPC error.
+	Validate(context.Context, *ValidateRequest) (*ValidateResponse, error)
+	// Render draws a raw QR string as an image.
+	Render(context.Context, *RenderRequest) (*RenderResponse, error)
+	mustEmbedUnimplementedEmvQRServer()
+}
+
+// UnimplementedEmvQRServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEmvQRServer struct{}
+
+func (UnimplementedEmvQRServer) Encode(context.Context, *EncodeRequest) (*EncodeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Encode not implemented")
+}
+func (UnimplementedEmvQRServer) Decode(context.Context, *DecodeRequest) (*DecodeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Decode not implemented")
+}
+func (UnimplementedEmvQRServer) Validate(context.Context, *ValidateRequest) (*ValidateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Validate not implemented")
+}
+func (UnimplementedEmvQRServer) Render(context.Context, *RenderRequest) (*RenderResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Render not implemented")
+}
+func (UnimplementedEmvQRServer) mustEmbedUnimplementedEmvQRServer() {}
+func (UnimplementedEmvQRServer) testEmbeddedByValue()               {}
+
+// UnsafeEmvQRServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EmvQRServer will
+// result in compilation errors.
+type UnsafeEmvQRServer interface {
+	mustEmbedUnimplementedEmvQRServer()
+}
+
+func RegisterEmvQRServer(s grpc.ServiceRegistrar, srv EmvQRServer) {
+	// If the following call panics, it indicates UnimplementedEmvQRServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&EmvQR_ServiceDesc, srv)
+}
+
+func _EmvQR_Encode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EncodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmvQRServer).Encode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmvQR_Encode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmvQRServer).Encode(ctx, req.(*EncodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmvQR_Decode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmvQRServer).Decode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmvQR_Decode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmvQRServer).Decode(ctx, req.(*DecodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmvQR_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmvQRServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmvQR_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmvQRServer).Validate(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EmvQR_Render_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EmvQRServer).Render(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EmvQR_Render_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EmvQRServer).Render(ctx, req.(*RenderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EmvQR_ServiceDesc is the grpc.ServiceDesc for EmvQR service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EmvQR_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "emvqr.v1.EmvQR",
+	HandlerType: (*EmvQRServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Encode",
+			Handler:    _EmvQR_Encode_Handler,
+		},
+		{
+			MethodName: "Decode",
+			Handler:    _EmvQR_Decode_Handler,
+		},
+		{
+			MethodName: "Validate",
+			Handler:    _EmvQR_Validate_Handler,
+		},
+		{
+			MethodName: "Render",
+			Handler:    _EmvQR_Render_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "emvqr/v1/emvqr.proto",
+}
@@ -0,0 +1,222 @@
+// Package server is a reference implementation of the EmvQR gRPC service
+// defined in emvqr/v1/emvqr.proto, backed by the emvqr, profile and render
+// packages. It answers like the emvqr/httpapi handlers: payloads that do not
+// encode or decode fail with codes.InvalidArgument and an emvqrv1.Error in
+// the status details, whose code is that of emvqr.ErrorCode, while Validate
+// reports invalid payloads in its response.
+//
+// A service needs only:
+//
+//	s := grpc.NewServer()
+//	emvqrv1.RegisterEmvQRServer(s, server.New(server.Options{}))
+//	s.Serve(lis)
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/profile"
+	"github.com/hussainpithawala/emv-merchant-qr-lib/emvqr/render"
+	emvqrv1 "github.com/hussainpithawala/emv-merchant-qr-lib/proto/emvqr/v1"
+)
+
+// Codes of the failures detected by the server rather than by emvqr.
+const (
+	CodeBadRequest     emvqr.Code = "bad_request"
+	CodeUnknownProfile emvqr.Code = "unknown_profile"
+)
+
+// Options configures the server.
+type Options struct {
+	// Decode is used by Decode, Validate and Render. Its SpecVersion is
+	// replaced by that of the request, if any.
+	Decode emvqr.DecodeOptions
+
+	// Encode is used by Encode. Its SpecVersion and Canonical fields are
+	// replaced by those of the request, if set.
+	Encode emvqr.EncodeOptions
+
+	// Profile is the scheme profile used by Validate when the request names
+	// none; "" means profile.Default.
+	Profile string
+
+	// Render is used by Render, with the error correction level, module
+	// size and caption of the request.
+	Render render.Options
+}
+
+// Server implements emvqrv1.EmvQRServer.
+type Server struct {
+	emvqrv1.UnimplementedEmvQRServer
+	opts Options
+}
+
+// New returns a Server using opts.
+func New(opts Options) *Server {
+	return &Server{opts: opts}
+}
+
+// Encode serialises the request's payload, computing its CRC.
+func (s *Server) Encode(ctx context.Context, req *emvqrv1.EncodeRequest) (*emvqrv1.EncodeResponse, error) {
+	if len(req.GetPayload().GetFields()) == 0 {
+		return nil, statusError(codes.InvalidArgument, &emvqr.Error{Code: CodeBadRequest, Err: fmt.Errorf("server: request has no payload")}, "")
+	}
+	version, err := specVersion(req.GetSpecVersion())
+	if err != nil {
+		return nil, err
+	}
+	p, err := emvqr.FromFlatWithOptions(req.GetPayload().GetFields(), emvqr.DecodeOptions{SpecVersion: version})
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, err, "")
+	}
+	opts := s.opts.Encode
+	if req.GetSpecVersion() != emvqrv1.SpecVersion_SPEC_VERSION_UNSPECIFIED {
+		opts.SpecVersion = version
+	}
+	opts.Canonical = opts.Canonical || req.GetCanonical()
+	qr, err := emvqr.EncodeWithOptions(p, opts)
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, err, "")
+	}
+	return &emvqrv1.EncodeResponse{Qr: qr}, nil
+}
+
+// Decode parses and checks the request's QR string.
+func (s *Server) Decode(ctx context.Context, req *emvqrv1.DecodeRequest) (*emvqrv1.DecodeResponse, error) {
+	opts, err := s.decodeOptions(req.GetSpecVersion())
+	if err != nil {
+		return nil, err
+	}
+	p, err := emvqr.DecodeContext(ctx, req.GetQr(), opts)
+	if err != nil {
+		return nil, statusError(contextCode(ctx), err, req.GetLanguage())
+	}
+	return &emvqrv1.DecodeResponse{Payload: &emvqrv1.Payload{Fields: p.Flatten()}}, nil
+}
+
+// Validate checks the request's QR string against a scheme profile. A
+// payload that does not decode is reported in the response's Error.
+func (s *Server) Validate(ctx context.Context, req *emvqrv1.ValidateRequest) (*emvqrv1.ValidateResponse, error) {
+	name := req.GetProfile()
+	if name == "" {
+		name = s.opts.Profile
+	}
+	if name == "" {
+		name = profile.Default
+	}
+	pr, ok := profile.Lookup(name)
+	if !ok {
+		return nil, statusError(codes.InvalidArgument, &emvqr.Error{
+			Code:   CodeUnknownProfile,
+			Params: map[string]string{"profile": name},
+			Err:    fmt.Errorf("server: unknown profile %q (want one of %s)", name, strings.Join(profile.Names(), ", ")),
+		}, req.GetLanguage())
+	}
+	opts, err := s.decodeOptions(req.GetSpecVersion())
+	if err != nil {
+		return nil, err
+	}
+	resp := &emvqrv1.ValidateResponse{Profile: pr.Name}
+	p, err := emvqr.DecodeContext(ctx, req.GetQr(), opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, statusError(contextCode(ctx), err, req.GetLanguage())
+		}
+		resp.Error = errorMessage(err, req.GetLanguage())
+		return resp, nil
+	}
+	resp.Findings = pr.Check(p, req.GetQr())
+	for _, w := range emvqr.Lint(p) {
+		resp.Warnings = append(resp.Warnings, &emvqrv1.Warning{Check: w.Check, Path: w.Path, Message: w.Message})
+	}
+	resp.Valid = len(resp.Findings) == 0
+	return resp, nil
+}
+
+// contentTypes are the content types of the image formats.
+var contentTypes = map[emvqrv1.ImageFormat]string{
+	emvqrv1.ImageFormat_IMAGE_FORMAT_UNSPECIFIED: "image/png",
+	emvqrv1.ImageFormat_IMAGE_FORMAT_PNG:         "image/png",
+	emvqrv1.ImageFormat_IMAGE_FORMAT_SVG:         "image/svg+xml",
+	emvqrv1.ImageFormat_IMAGE_FORMAT_PDF:         "application/pdf",
+}
+
+// Render draws the request's QR string as an image.
+func (s *Server) Render(ctx context.Context, req *emvqrv1.RenderRequest) (*emvqrv1.RenderResponse, error) {
+	contentType, ok := contentTypes[req.GetFormat()]
+	if !ok {
+		return nil, badRequest("server: unknown image format %d", req.GetFormat())
+	}
+	opts := s.opts.Render
+	opts.Decode = s.opts.Decode
+	// The enum values of emvqrv1.ErrorCorrection are those of
+	// render.ErrorCorrection; render rejects any other.
+	opts.ErrorCorrection = render.ErrorCorrection(req.GetErrorCorrection())
+	if req.GetModuleSize() != 0 {
+		opts.ModuleSize = int(req.GetModuleSize())
+	}
+	if req.GetCaption() != "" {
+		opts.Caption = req.GetCaption()
+	}
+	sym, err := render.NewContext(ctx, req.GetQr(), opts)
+	if err != nil {
+		return nil, statusError(contextCode(ctx), err, "")
+	}
+	var buf bytes.Buffer
+	switch req.GetFormat() {
+	case emvqrv1.ImageFormat_IMAGE_FORMAT_SVG:
+		err = sym.WriteSVG(&buf)
+	case emvqrv1.ImageFormat_IMAGE_FORMAT_PDF:
+		err = sym.WritePDF(&buf)
+	default:
+		err = sym.WritePNG(&buf)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &emvqrv1.RenderResponse{Image: buf.Bytes(), ContentType: contentType}, nil
+}
+
+// decodeOptions returns the server's decode options for a request
+// naming version.
+func (s *Server) decodeOptions(version emvqrv1.SpecVersion) (emvqr.DecodeOptions, error) {
+	opts := s.opts.Decode
+	if version != emvqrv1.SpecVersion_SPEC_VERSION_UNSPECIFIED {
+		v, err := specVersion(version)
+		if err != nil {
+			return opts, err
+		}
+		opts.SpecVersion = v
+	}
+	return opts, nil
+}
+
+// specVersion maps a request's spec version onto emvqr's.
+func specVersion(v emvqrv1.SpecVersion) (emvqr.SpecVersion, error) {
+	switch v {
+	case emvqrv1.SpecVersion_SPEC_VERSION_UNSPECIFIED, emvqrv1.SpecVersion_SPEC_VERSION_1_0:
+		return emvqr.SpecVersion10, nil
+	case emvqrv1.SpecVersion_SPEC_VERSION_1_1:
+		return emvqr.SpecVersion11, nil
+	}
+	return 0, badRequest("server: unknown spec version %d", v)
+}
+
+// contextCode returns the status code of a failure under ctx: that of its
+// error once ctx is done, codes.InvalidArgument otherwise.
+func contextCode(ctx context.Context) codes.Code {
+	switch ctx.Err() {
+	case context.Canceled:
+		return codes.Canceled
+	case context.DeadlineExceeded:
+		return codes.DeadlineExceeded
+	}
+	return codes.InvalidArgument
+}
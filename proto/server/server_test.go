@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	emvqrv1 "github.com/hussainpithawala/emv-merchant-qr-lib/proto/emvqr/v1"
+)
+
+const staticQR = "000201021640001234567890125204525153038405802US5911ABC Hammers6008New York63047222"
+
+// newClient serves New(opts) over an in-memory connection.
+func newClient(t *testing.T, opts Options) emvqrv1.EmvQRClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	emvqrv1.RegisterEmvQRServer(s, New(opts))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return emvqrv1.NewEmvQRClient(conn)
+}
+
+// errorDetail returns the emvqrv1.Error in the details of err's status.
+func errorDetail(t *testing.T, err error, want codes.Code) *emvqrv1.Error {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != want {
+		t.Fatalf("status = %v, want %v", st, want)
+	}
+	for _, d := range st.Details() {
+		if e, ok := d.(*emvqrv1.Error); ok {
+			return e
+		}
+	}
+	t.Fatalf("status %v has no emvqrv1.Error detail", st)
+	return nil
+}
+
+func TestDecodeEncode(t *testing.T) {
+	c := newClient(t, Options{})
+	ctx := context.Background()
+	dec, err := c.Decode(ctx, &emvqrv1.DecodeRequest{Qr: staticQR})
+	if err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+	if got := dec.GetPayload().GetFields()["59"]; got != "ABC Hammers" {
+		t.Errorf("field 59 = %q", got)
+	}
+	enc, err := c.Encode(ctx, &emvqrv1.EncodeRequest{Payload: dec.GetPayload()})
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if enc.GetQr() != staticQR {
+		t.Errorf("Encode = %q, want %q", enc.GetQr(), staticQR)
+	}
+}
+
+func TestDecode_Error(t *testing.T) {
+	c := newClient(t, Options{})
+	_, err := c.Decode(context.Background(), &emvqrv1.DecodeRequest{Qr: staticQR[:len(staticQR)-4] + "0000"})
+	if e := errorDetail(t, err, codes.InvalidArgument); e.GetCode() != "crc_mismatch" {
+		t.Errorf("code = %q, want crc_mismatch", e.GetCode())
+	}
+}
+
+func TestEncode_Errors(t *testing.T) {
+	c := newClient(t, Options{})
+	ctx := context.Background()
+	_, err := c.Encode(ctx, &emvqrv1.EncodeRequest{})
+	if e := errorDetail(t, err, codes.InvalidArgument); e.GetCode() != string(CodeBadRequest) {
+		t.Errorf("code = %q, want %q", e.GetCode(), CodeBadRequest)
+	}
+	_, err = c.Encode(ctx, &emvqrv1.EncodeRequest{Payload: &emvqrv1.Payload{Fields: map[string]string{"59": "ABC Hammers"}}})
+	if e := errorDetail(t, err, codes.InvalidArgument); e.GetCode() != "missing_field" {
+		t.Errorf("code = %q, want missing_field", e.GetCode())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := newClient(t, Options{})
+	ctx := context.Background()
+	resp, err := c.Validate(ctx, &emvqrv1.ValidateRequest{Qr: staticQR})
+	if err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if !resp.GetValid() || resp.GetProfile() != "emvco" {
+		t.Errorf("Validate = %v, want valid under emvco", resp)
+	}
+
+	resp, err = c.Validate(ctx, &emvqrv1.ValidateRequest{Qr: "000201"})
+	if err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	if resp.GetValid() || resp.GetError().GetCode() == "" {
+		t.Errorf("Validate = %v, want an invalid payload with its error", resp)
+	}
+
+	_, err = c.Validate(ctx, &emvqrv1.ValidateRequest{Qr: staticQR, Profile: "nope"})
+	if e := errorDetail(t, err, codes.InvalidArgument); e.GetParams()["profile"] != "nope" {
+		t.Errorf("params = %v", e.GetParams())
+	}
+}
+
+func TestRender(t *testing.T) {
+	c := newClient(t, Options{})
+	ctx := context.Background()
+	for format, prefix := range map[emvqrv1.ImageFormat]string{
+		emvqrv1.ImageFormat_IMAGE_FORMAT_UNSPECIFIED: "\x89PNG",
+		emvqrv1.ImageFormat_IMAGE_FORMAT_SVG:         "<svg",
+		emvqrv1.ImageFormat_IMAGE_FORMAT_PDF:         "%PDF-",
+	} {
+		resp, err := c.Render(ctx, &emvqrv1.RenderRequest{Qr: staticQR, Format: format, Caption: "ABC Hammers"})
+		if err != nil {
+			t.Fatalf("Render(%v) error: %v", format, err)
+		}
+		if !bytes.HasPrefix(bytes.TrimSpace(resp.GetImage()), []byte(prefix)) || resp.GetContentType() != contentTypes[format] {
+			t.Errorf("Render(%v) = %s %.10q", format, resp.GetContentType(), resp.GetImage())
+		}
+	}
+	_, err := c.Render(ctx, &emvqrv1.RenderRequest{Qr: staticQR, ErrorCorrection: 9})
+	errorDetail(t, err, codes.InvalidArgument)
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	emvqr "github.com/hussainpithawala/emv-merchant-qr-lib/emvqr"
+	emvqrv1 "github.com/hussainpithawala/emv-merchant-qr-lib/proto/emvqr/v1"
+)
+
+// errorMessage describes err for a client asking in lang.
+func errorMessage(err error, lang string) *emvqrv1.Error {
+	return &emvqrv1.Error{
+		Code:      string(emvqr.ErrorCode(err)),
+		Message:   err.Error(),
+		Localized: emvqr.LocalizedMessage(err, lang),
+		Params:    emvqr.ErrorParams(err),
+	}
+}
+
+// statusError returns a status error with code and err's description in
+// its details.
+func statusError(code codes.Code, err error, lang string) error {
+	st, detailErr := status.New(code, err.Error()).WithDetails(errorMessage(err, lang))
+	if detailErr != nil {
+		return status.Error(code, err.Error())
+	}
+	return st.Err()
+}
+
+// badRequest returns an InvalidArgument status error with code
+// CodeBadRequest.
+func badRequest(format string, args ...any) error {
+	return statusError(codes.InvalidArgument, &emvqr.Error{Code: CodeBadRequest, Err: fmt.Errorf(format, args...)}, "")
+}